@@ -0,0 +1,230 @@
+/*
+Package analytics emits structured usage events of handled requests (route, tenant, latency, status, bytes) to
+exporters. Events are buffered and exported in batches by background goroutine so request handling never waits for
+exporters. When buffer is full (exporters are slower than traffic) new events are dropped and counted in `Stats`.
+
+Example:
+
+	pipeline := analytics.New(analytics.Config{
+		Exporters: []analytics.Exporter{analytics.NewHTTPExporter("https://collector.example.com/events")},
+	})
+	defer pipeline.Close(context.Background())
+
+	e.Use(pipeline.Middleware(analytics.MiddlewareConfig{
+		Tenant: func(c echo.Context) string { return c.Request().Header.Get("X-API-Key") },
+	}))
+*/
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Config defines the config for analytics Pipeline.
+	Config struct {
+		// Exporters receive batches of events. Exporters are called sequentially from single goroutine.
+		// Required.
+		Exporters []Exporter
+
+		// BufferSize is number of events waiting for export. Events emitted when buffer is full are dropped.
+		// Optional. Default value 10000.
+		BufferSize int
+
+		// BatchSize is maximum number of events passed to exporters at once.
+		// Optional. Default value 100.
+		BatchSize int
+
+		// FlushInterval is maximum time event waits for batch to fill up.
+		// Optional. Default value 1 second.
+		FlushInterval time.Duration
+
+		// ErrorHandler is called with errors returned by exporters.
+		// Optional. Default value ignores errors (they are counted in Stats).
+		ErrorHandler func(err error)
+	}
+
+	// Event is usage event of one handled request.
+	Event struct {
+		Time      time.Time     `json:"time"`
+		Method    string        `json:"method"`
+		Route     string        `json:"route"`
+		Tenant    string        `json:"tenant,omitempty"`
+		Status    int           `json:"status"`
+		Latency   time.Duration `json:"latency"`
+		BytesIn   int64         `json:"bytes_in"`
+		BytesOut  int64         `json:"bytes_out"`
+		RequestID string        `json:"request_id,omitempty"`
+	}
+
+	// Exporter sends batch of events to analytics backend.
+	Exporter interface {
+		Export(ctx context.Context, events []Event) error
+	}
+
+	// Stats contains event counters of Pipeline since it was created.
+	Stats struct {
+		// Emitted is number of events accepted to buffer.
+		Emitted uint64 `json:"emitted"`
+		// Dropped is number of events dropped because buffer was full or pipeline was closed.
+		Dropped uint64 `json:"dropped"`
+		// Exported is number of events successfully passed to all exporters.
+		Exported uint64 `json:"exported"`
+		// Failed is number of events of batches some exporter failed to export.
+		Failed uint64 `json:"failed"`
+	}
+
+	// Pipeline buffers events and exports them in batches.
+	Pipeline struct {
+		// counters are first to keep them 64-bit aligned for atomic access on 32-bit platforms
+		emitted  uint64
+		dropped  uint64
+		exported uint64
+		failed   uint64
+
+		config  Config
+		events  chan Event
+		done    chan struct{}
+		mutex   sync.RWMutex
+		closed  bool
+		closing sync.Once
+	}
+)
+
+// DefaultConfig is the default analytics Pipeline config.
+var DefaultConfig = Config{
+	BufferSize:    10000,
+	BatchSize:     100,
+	FlushInterval: time.Second,
+}
+
+// New creates new Pipeline with config and starts its export goroutine.
+func New(config Config) *Pipeline {
+	if len(config.Exporters) == 0 {
+		panic("echo: analytics requires at least one exporter")
+	}
+	// Defaults
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultConfig.BufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultConfig.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultConfig.FlushInterval
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(err error) {}
+	}
+
+	p := &Pipeline{
+		config: config,
+		events: make(chan Event, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Emit adds event to buffer without blocking. It returns false when event was dropped.
+func (p *Pipeline) Emit(event Event) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if p.closed {
+		atomic.AddUint64(&p.dropped, 1)
+		return false
+	}
+	select {
+	case p.events <- event:
+		atomic.AddUint64(&p.emitted, 1)
+		return true
+	default:
+		atomic.AddUint64(&p.dropped, 1)
+		return false
+	}
+}
+
+// Stats returns event counters of pipeline.
+func (p *Pipeline) Stats() Stats {
+	return Stats{
+		Emitted:  atomic.LoadUint64(&p.emitted),
+		Dropped:  atomic.LoadUint64(&p.dropped),
+		Exported: atomic.LoadUint64(&p.exported),
+		Failed:   atomic.LoadUint64(&p.failed),
+	}
+}
+
+// Close stops accepting events and waits until buffered events are exported or ctx is done.
+func (p *Pipeline) Close(ctx context.Context) error {
+	p.closing.Do(func() {
+		p.mutex.Lock()
+		p.closed = true
+		close(p.events)
+		p.mutex.Unlock()
+	})
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pipeline) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, p.config.BatchSize)
+	for {
+		select {
+		case event, ok := <-p.events:
+			if !ok {
+				p.export(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= p.config.BatchSize {
+				p.export(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			p.export(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (p *Pipeline) export(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+	var failed bool
+	for _, exporter := range p.config.Exporters {
+		if err := p.exportTo(exporter, batch); err != nil {
+			failed = true
+			p.config.ErrorHandler(err)
+		}
+	}
+	if failed {
+		atomic.AddUint64(&p.failed, uint64(len(batch)))
+	} else {
+		atomic.AddUint64(&p.exported, uint64(len(batch)))
+	}
+}
+
+// exportTo calls exporter recovering from panic so one broken exporter does not stop the pipeline.
+func (p *Pipeline) exportTo(exporter Exporter, batch []Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("analytics: exporter panicked")
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.FlushInterval*10)
+	defer cancel()
+	return exporter.Export(ctx, batch)
+}
@@ -0,0 +1,136 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingExporter struct {
+	mutex   sync.Mutex
+	batches [][]Event
+	err     error
+	block   chan struct{}
+}
+
+func (e *recordingExporter) Export(ctx context.Context, events []Event) error {
+	if e.block != nil {
+		<-e.block
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.batches = append(e.batches, append([]Event(nil), events...))
+	return e.err
+}
+
+func (e *recordingExporter) events() []Event {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	result := []Event{}
+	for _, b := range e.batches {
+		result = append(result, b...)
+	}
+	return result
+}
+
+func TestPipeline_Middleware(t *testing.T) {
+	exporter := &recordingExporter{}
+	pipeline := New(Config{Exporters: []Exporter{exporter}})
+
+	e := echo.New()
+	e.Use(pipeline.Middleware(MiddlewareConfig{
+		Tenant: func(c echo.Context) string { return c.Request().Header.Get("X-API-Key") },
+	}))
+	e.POST("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusCreated, "created")
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.ErrForbidden
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader("name=jon"))
+	req.Header.Set("X-API-Key", "tenant-1")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	require.NoError(t, pipeline.Close(context.Background()))
+	events := exporter.events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "/users/:id", events[0].Route)
+	assert.Equal(t, http.MethodPost, events[0].Method)
+	assert.Equal(t, "tenant-1", events[0].Tenant)
+	assert.Equal(t, http.StatusCreated, events[0].Status)
+	assert.Equal(t, int64(8), events[0].BytesIn)
+	assert.Equal(t, int64(7), events[0].BytesOut)
+	assert.Equal(t, http.StatusForbidden, events[1].Status)
+	assert.Equal(t, Stats{Emitted: 2, Exported: 2}, pipeline.Stats())
+}
+
+func TestPipeline_batches(t *testing.T) {
+	exporter := &recordingExporter{}
+	pipeline := New(Config{Exporters: []Exporter{exporter}, BatchSize: 2, FlushInterval: time.Hour})
+	for i := 0; i < 5; i++ {
+		assert.True(t, pipeline.Emit(Event{Status: i}))
+	}
+	require.NoError(t, pipeline.Close(context.Background()))
+
+	assert.Len(t, exporter.batches, 3)
+	assert.Len(t, exporter.events(), 5)
+	assert.False(t, pipeline.Emit(Event{}), "closed pipeline drops events")
+	assert.Equal(t, Stats{Emitted: 5, Dropped: 1, Exported: 5}, pipeline.Stats())
+}
+
+func TestPipeline_flushInterval(t *testing.T) {
+	exporter := &recordingExporter{}
+	pipeline := New(Config{Exporters: []Exporter{exporter}, FlushInterval: 10 * time.Millisecond})
+	defer pipeline.Close(context.Background())
+
+	pipeline.Emit(Event{})
+	assert.Eventually(t, func() bool { return len(exporter.events()) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestPipeline_backpressure(t *testing.T) {
+	exporter := &recordingExporter{block: make(chan struct{})}
+	pipeline := New(Config{Exporters: []Exporter{exporter}, BufferSize: 2, BatchSize: 1})
+
+	// first event is taken by blocked exporter, next two fill the buffer
+	assert.True(t, pipeline.Emit(Event{}))
+	assert.Eventually(t, func() bool { return len(pipeline.events) == 0 }, time.Second, time.Millisecond)
+	assert.True(t, pipeline.Emit(Event{}))
+	assert.True(t, pipeline.Emit(Event{}))
+	assert.False(t, pipeline.Emit(Event{}), "full buffer drops event without blocking")
+
+	close(exporter.block)
+	require.NoError(t, pipeline.Close(context.Background()))
+	assert.Equal(t, Stats{Emitted: 3, Dropped: 1, Exported: 3}, pipeline.Stats())
+}
+
+func TestPipeline_exporterErrors(t *testing.T) {
+	errs := make(chan error, 2)
+	pipeline := New(Config{
+		Exporters: []Exporter{
+			&recordingExporter{err: errors.New("unavailable")},
+			ExporterFunc(func(ctx context.Context, events []Event) error { panic("broken") }),
+		},
+		ErrorHandler: func(err error) { errs <- err },
+	})
+	pipeline.Emit(Event{})
+	require.NoError(t, pipeline.Close(context.Background()))
+
+	assert.EqualError(t, <-errs, "unavailable")
+	assert.EqualError(t, <-errs, "analytics: exporter panicked")
+	assert.Equal(t, Stats{Emitted: 1, Failed: 1}, pipeline.Stats())
+}
+
+func TestNew_panics(t *testing.T) {
+	assert.Panics(t, func() { New(Config{}) })
+}
@@ -0,0 +1,151 @@
+package analytics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// ExporterFunc is adapter to use ordinary function as Exporter.
+	ExporterFunc func(ctx context.Context, events []Event) error
+
+	// HTTPExporter posts batches of events as JSON array to collector URL.
+	HTTPExporter struct {
+		// URL of the collector.
+		URL string
+		// Header is added to every request, i.e. authorization of the collector.
+		Header http.Header
+		// Client sends requests. Default value http.DefaultClient.
+		Client *http.Client
+	}
+
+	// KafkaWriter is the interface to be implemented by Kafka producer clients. It matches `Writer.WriteMessages`
+	// of github.com/segmentio/kafka-go when wrapped with conversion of messages.
+	KafkaWriter interface {
+		WriteMessages(ctx context.Context, messages ...KafkaMessage) error
+	}
+
+	// KafkaMessage is message written to Kafka topic.
+	KafkaMessage struct {
+		Key   []byte
+		Value []byte
+	}
+
+	// KafkaExporter writes every event as JSON message to Kafka. Messages are keyed by tenant so events of one
+	// tenant end up in the same partition.
+	KafkaExporter struct {
+		Writer KafkaWriter
+	}
+
+	// FileExporter writes events as JSON lines to a file or any io.Writer.
+	FileExporter struct {
+		mutex  sync.Mutex
+		writer io.Writer
+		closer io.Closer
+	}
+)
+
+// Export calls f(ctx, events).
+func (f ExporterFunc) Export(ctx context.Context, events []Event) error {
+	return f(ctx, events)
+}
+
+// NewHTTPExporter creates new HTTPExporter posting events to url.
+func NewHTTPExporter(url string) *HTTPExporter {
+	return &HTTPExporter{URL: url}
+}
+
+// Export implements Exporter.Export.
+func (e *HTTPExporter) Export(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range e.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("analytics: collector responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// NewKafkaExporter creates new KafkaExporter writing events with writer.
+func NewKafkaExporter(writer KafkaWriter) *KafkaExporter {
+	return &KafkaExporter{Writer: writer}
+}
+
+// Export implements Exporter.Export.
+func (e *KafkaExporter) Export(ctx context.Context, events []Event) error {
+	messages := make([]KafkaMessage, 0, len(events))
+	for _, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, KafkaMessage{Key: []byte(event.Tenant), Value: value})
+	}
+	return e.Writer.WriteMessages(ctx, messages...)
+}
+
+// NewFileExporter creates new FileExporter appending events to file at path.
+func NewFileExporter(path string) (*FileExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{writer: f, closer: f}, nil
+}
+
+// NewWriterExporter creates new FileExporter writing events to w.
+func NewWriterExporter(w io.Writer) *FileExporter {
+	return &FileExporter{writer: w}
+}
+
+// Export implements Exporter.Export.
+func (e *FileExporter) Export(ctx context.Context, events []Event) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	w := bufio.NewWriter(e.writer)
+	enc := json.NewEncoder(w)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Close closes file opened by NewFileExporter.
+func (e *FileExporter) Close() error {
+	if e.closer == nil {
+		return nil
+	}
+	return e.closer.Close()
+}
@@ -0,0 +1,82 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPExporter(t *testing.T) {
+	var received []Event
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get(echo.HeaderAuthorization))
+		assert.Equal(t, echo.MIMEApplicationJSON, r.Header.Get(echo.HeaderContentType))
+		received = nil
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	exporter := NewHTTPExporter(server.URL)
+	exporter.Header = http.Header{echo.HeaderAuthorization: {"Bearer token"}}
+	events := []Event{{Route: "/a", Status: 200}, {Route: "/b", Status: 404}}
+
+	assert.NoError(t, exporter.Export(context.Background(), events))
+	assert.Equal(t, events[0].Route, received[0].Route)
+	assert.Len(t, received, 2)
+
+	status = http.StatusServiceUnavailable
+	assert.EqualError(t, exporter.Export(context.Background(), events), "analytics: collector responded with status 503")
+}
+
+type kafkaWriterFunc func(ctx context.Context, messages ...KafkaMessage) error
+
+func (f kafkaWriterFunc) WriteMessages(ctx context.Context, messages ...KafkaMessage) error {
+	return f(ctx, messages...)
+}
+
+func TestKafkaExporter(t *testing.T) {
+	var written []KafkaMessage
+	exporter := NewKafkaExporter(kafkaWriterFunc(func(ctx context.Context, messages ...KafkaMessage) error {
+		written = messages
+		return nil
+	}))
+
+	require.NoError(t, exporter.Export(context.Background(), []Event{{Tenant: "t1", Route: "/a"}, {Tenant: "t2"}}))
+	require.Len(t, written, 2)
+	assert.Equal(t, []byte("t1"), written[0].Key)
+	event := Event{}
+	require.NoError(t, json.Unmarshal(written[0].Value, &event))
+	assert.Equal(t, "/a", event.Route)
+}
+
+func TestFileExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "analytics")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.log")
+
+	exporter, err := NewFileExporter(path)
+	require.NoError(t, err)
+	require.NoError(t, exporter.Export(context.Background(), []Event{{Route: "/a"}, {Route: "/b"}}))
+	require.NoError(t, exporter.Close())
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, bytes.Split(bytes.TrimSpace(b), []byte("\n")), 2)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, NewWriterExporter(buf).Export(context.Background(), []Event{{Route: "/c"}}))
+	assert.Contains(t, buf.String(), `"route":"/c"`)
+}
@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type (
+	// MiddlewareConfig defines the config for analytics middleware.
+	MiddlewareConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper middleware.Skipper
+
+		// Tenant returns tenant of the request, i.e. API key or ID of customer account.
+		// Optional.
+		Tenant func(c echo.Context) string
+	}
+)
+
+// DefaultMiddlewareConfig is the default analytics middleware config.
+var DefaultMiddlewareConfig = MiddlewareConfig{
+	Skipper: middleware.DefaultSkipper,
+}
+
+// Middleware returns a middleware which emits usage event of every handled request to the pipeline. Emitting never
+// blocks the request, see `Pipeline.Emit()`.
+func (p *Pipeline) Middleware(config MiddlewareConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultMiddlewareConfig.Skipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+			latency := time.Since(start)
+
+			req := c.Request()
+			res := c.Response()
+			status := res.Status
+			if err != nil && !res.Committed {
+				status = http.StatusInternalServerError
+				if httpErr, ok := err.(*echo.HTTPError); ok {
+					status = httpErr.Code
+				}
+			}
+			event := Event{
+				Time:      start,
+				Method:    req.Method,
+				Route:     c.Path(),
+				Status:    status,
+				Latency:   latency,
+				BytesOut:  res.Size,
+				RequestID: res.Header().Get(echo.HeaderXRequestID),
+			}
+			if req.ContentLength > 0 {
+				event.BytesIn = req.ContentLength
+			}
+			if config.Tenant != nil {
+				event.Tenant = config.Tenant(c)
+			}
+			p.Emit(event)
+			return err
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package echo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// ArchiveFormat is format of archive sent by `NewArchive()`.
+	ArchiveFormat int
+
+	// ArchiveEntry is a file of archive.
+	ArchiveEntry struct {
+		// Name is path of the file in archive, i.e. "photos/2021/01.jpg".
+		Name string
+		// ModTime is modification time of the file. Zero value is time the archive is sent.
+		ModTime time.Time
+		// Size is size of the file content, -1 when it is not known. Size is required by tar archives.
+		Size int64
+		// Open opens the file content when the entry is written, so files are not kept open while archive is
+		// being built and sent.
+		Open func() (io.ReadCloser, error)
+	}
+
+	// Archive builds archive sent as response. Entries are read and compressed while the archive is streamed to
+	// the client, no temporary file is created.
+	//
+	// Example:
+	//
+	//	return echo.NewArchive(c, echo.ArchiveZip, "photos.zip").
+	//		AddFile("2021/01.jpg", "/data/photos/01.jpg").
+	//		AddReader("README.txt", strings.NewReader(readme), int64(len(readme)), time.Now()).
+	//		Send(http.StatusOK)
+	Archive struct {
+		context Context
+		format  ArchiveFormat
+		name    string
+		store   bool
+		entries []ArchiveEntry
+		err     error
+	}
+)
+
+// Archive formats
+const (
+	// ArchiveZip is ZIP archive, entries are compressed with deflate unless `Archive#Store()` is used.
+	ArchiveZip ArchiveFormat = iota
+	// ArchiveTarGz is gzip compressed tar archive. Sizes of all entries must be known.
+	ArchiveTarGz
+)
+
+// zip record sizes used to compute size of stored archive, see `Archive#contentLength()`.
+const (
+	zipLocalHeaderLen    = 30
+	zipDataDescriptorLen = 16
+	zipCentralHeaderLen  = 46
+	zipEndRecordLen      = 22
+	zipExtTimeExtraLen   = 9
+	zipMaxSize           = 1<<32 - 1
+	zipMaxEntries        = 1<<16 - 1
+)
+
+// NewArchive returns builder of ZIP or tar.gz archive response streamed from files and readers. With file name
+// the archive is sent as attachment. See `Archive`.
+func NewArchive(c Context, format ArchiveFormat, name string) *Archive {
+	return &Archive{context: c, format: format, name: name}
+}
+
+// Store disables compression of ZIP entries. Use it for already compressed files (images, videos), stored archive
+// of entries with known sizes is sent with `Content-Length` header.
+func (a *Archive) Store() *Archive {
+	a.store = true
+	return a
+}
+
+// Add adds entry to the archive.
+func (a *Archive) Add(entry ArchiveEntry) *Archive {
+	a.entries = append(a.entries, entry)
+	return a
+}
+
+// AddFile adds file from disk with its size and modification time. Errors (i.e. file does not exist) are returned
+// by `Archive#Send()` before the response is sent.
+func (a *Archive) AddFile(name, file string) *Archive {
+	fi, err := os.Stat(file)
+	if err != nil {
+		if a.err == nil {
+			a.err = err
+		}
+		return a
+	}
+	if fi.IsDir() {
+		if a.err == nil {
+			a.err = fmt.Errorf("echo: archive file %q is a directory", file)
+		}
+		return a
+	}
+	return a.Add(ArchiveEntry{
+		Name:    name,
+		ModTime: fi.ModTime(),
+		Size:    fi.Size(),
+		Open: func() (io.ReadCloser, error) {
+			return os.Open(file)
+		},
+	})
+}
+
+// AddReader adds entry with content read from r. Size -1 means size is not known.
+func (a *Archive) AddReader(name string, r io.Reader, size int64, modTime time.Time) *Archive {
+	return a.Add(ArchiveEntry{
+		Name:    name,
+		ModTime: modTime,
+		Size:    size,
+		Open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(r), nil
+		},
+	})
+}
+
+// Send streams the archive with status code and content type of the format. With file name the archive is sent as
+// attachment. Invalid entries are reported before the response is committed, errors of reading entries
+// afterwards abort the response.
+func (a *Archive) Send(code int) (err error) {
+	c := a.context
+	defer trackRender(c)()
+	if a.err != nil {
+		return a.err
+	}
+	now := Now(c)
+	for i := range a.entries {
+		e := &a.entries[i]
+		name := strings.TrimPrefix(path.Clean("/"+e.Name), "/")
+		if e.Name == "" || name == "" || e.Open == nil {
+			return fmt.Errorf("echo: invalid archive entry %q", e.Name)
+		}
+		e.Name = name
+		if e.ModTime.IsZero() {
+			e.ModTime = now
+		}
+		if a.format == ArchiveTarGz && e.Size < 0 {
+			return fmt.Errorf("echo: tar archive entry %q requires size", e.Name)
+		}
+	}
+
+	contentType := MIMEApplicationZip
+	if a.format == ArchiveTarGz {
+		contentType = MIMEApplicationGzip
+	}
+	header := c.Response().Header()
+	if a.name != "" {
+		header.Set(HeaderContentDisposition, ContentDisposition("attachment", a.name))
+	}
+	if size := a.contentLength(); size >= 0 {
+		header.Set(HeaderContentLength, strconv.FormatInt(size, 10))
+	}
+	writeContentType(c, contentType)
+	c.Response().WriteHeader(code)
+
+	if a.format == ArchiveTarGz {
+		return a.writeTarGz()
+	}
+	return a.writeZip()
+}
+
+func (a *Archive) writeZip() error {
+	w := zip.NewWriter(a.context.Response())
+	method := zip.Deflate
+	if a.store {
+		method = zip.Store
+	}
+	for _, e := range a.entries {
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: e.Name, Method: method, Modified: e.ModTime})
+		if err != nil {
+			return err
+		}
+		if err := a.copyEntry(fw, e); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		flush(a.context)
+	}
+	return w.Close()
+}
+
+func (a *Archive) writeTarGz() error {
+	gw := gzip.NewWriter(a.context.Response())
+	w := tar.NewWriter(gw)
+	for _, e := range a.entries {
+		err := w.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     e.Name,
+			Mode:     0644,
+			Size:     e.Size,
+			ModTime:  e.ModTime,
+		})
+		if err != nil {
+			return err
+		}
+		if err := a.copyEntry(w, e); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if err := gw.Flush(); err != nil {
+			return err
+		}
+		flush(a.context)
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// copyEntry copies content of the entry to w. Content must have the size of the entry when it is known.
+func (a *Archive) copyEntry(w io.Writer, e ArchiveEntry) error {
+	r, err := e.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if e.Size < 0 {
+		_, err = io.Copy(w, r)
+		return err
+	}
+	n, err := io.Copy(w, io.LimitReader(r, e.Size))
+	if err != nil {
+		return err
+	}
+	if n != e.Size {
+		return fmt.Errorf("echo: archive entry %q has %d bytes, expected %d: %w", e.Name, n, e.Size, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// contentLength returns exact size of stored ZIP archive with known entry sizes or -1. Records are written by
+// `archive/zip` with data descriptor and extended timestamp extra field.
+func (a *Archive) contentLength() int64 {
+	if a.format != ArchiveZip || !a.store || len(a.entries) >= zipMaxEntries {
+		return -1
+	}
+	size := int64(zipEndRecordLen)
+	for _, e := range a.entries {
+		if e.Size < 0 || e.Size >= zipMaxSize {
+			return -1
+		}
+		n := int64(len(e.Name))
+		size += zipLocalHeaderLen + n + zipExtTimeExtraLen + e.Size + zipDataDescriptorLen
+		size += zipCentralHeaderLen + n + zipExtTimeExtraLen
+	}
+	if size >= zipMaxSize {
+		// ZIP64 records would be needed
+		return -1
+	}
+	return size
+}
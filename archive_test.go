@@ -0,0 +1,193 @@
+package echo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Archive_zip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.txt")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(strings.Repeat("a", 1000)), 0644))
+	fileTime := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(file, fileTime, fileTime))
+
+	now := time.Date(2021, 1, 2, 3, 4, 6, 0, time.UTC)
+	e := New()
+	e.Clock = &testClock{now: now}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/download", nil), rec)
+
+	readerTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = NewArchive(c, ArchiveZip, "files.zip").
+		AddFile("docs/a.txt", file).
+		AddReader("/b.txt", strings.NewReader("bbb"), 3, readerTime).
+		AddReader("c.txt", strings.NewReader("ccc"), -1, time.Time{}).
+		Send(http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MIMEApplicationZip, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `attachment; filename="files.zip"`, rec.Header().Get(HeaderContentDisposition))
+	assert.Empty(t, rec.Header().Get(HeaderContentLength))
+	assert.True(t, rec.Flushed)
+
+	b := rec.Body.Bytes()
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, r.File, 3) {
+		assert.Equal(t, "docs/a.txt", r.File[0].Name)
+		assert.Equal(t, zip.Deflate, r.File[0].Method)
+		assert.True(t, fileTime.Equal(r.File[0].Modified))
+		assert.Equal(t, "b.txt", r.File[1].Name)
+		assert.True(t, readerTime.Equal(r.File[1].Modified))
+		assert.True(t, now.Equal(r.File[2].Modified))
+	}
+	files := readZip(t, b)
+	assert.Equal(t, strings.Repeat("a", 1000), files["docs/a.txt"])
+	assert.Equal(t, "bbb", files["b.txt"])
+	assert.Equal(t, "ccc", files["c.txt"])
+}
+
+func TestContext_Archive_zipStoreContentLength(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/download", nil), rec)
+
+	err := NewArchive(c, ArchiveZip, "").
+		Store().
+		AddReader("photo.jpg", strings.NewReader(strings.Repeat("x", 5000)), 5000, time.Now()).
+		AddReader("dir/notes.txt", strings.NewReader("notes"), 5, time.Now()).
+		Send(http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.Empty(t, rec.Header().Get(HeaderContentDisposition))
+	assert.Equal(t, strconv.Itoa(rec.Body.Len()), rec.Header().Get(HeaderContentLength))
+	files := readZip(t, rec.Body.Bytes())
+	assert.Equal(t, "notes", files["dir/notes.txt"])
+	assert.Len(t, files["photo.jpg"], 5000)
+}
+
+func TestContext_Archive_tarGz(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/download", nil), rec)
+
+	modTime := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	err := NewArchive(c, ArchiveTarGz, "backup.tar.gz").
+		AddReader("a/one.txt", strings.NewReader("one"), 3, modTime).
+		Add(ArchiveEntry{
+			Name: "two.txt",
+			Size: 3,
+			Open: func() (io.ReadCloser, error) { return ioutil.NopCloser(strings.NewReader("two")), nil },
+		}).
+		Send(http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MIMEApplicationGzip, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `attachment; filename="backup.tar.gz"`, rec.Header().Get(HeaderContentDisposition))
+	assert.Empty(t, rec.Header().Get(HeaderContentLength))
+
+	gr, err := gzip.NewReader(rec.Body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	tr := tar.NewReader(gr)
+	hdr, err := tr.Next()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "a/one.txt", hdr.Name)
+		assert.True(t, modTime.Equal(hdr.ModTime))
+		content, _ := ioutil.ReadAll(tr)
+		assert.Equal(t, "one", string(content))
+	}
+	hdr, err = tr.Next()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "two.txt", hdr.Name)
+		assert.False(t, hdr.ModTime.IsZero())
+		content, _ := ioutil.ReadAll(tr)
+		assert.Equal(t, "two", string(content))
+	}
+	_, err = tr.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestContext_Archive_errors(t *testing.T) {
+	var testCases = []struct {
+		name            string
+		whenArchive     func(c Context) *Archive
+		expectErr       string
+		expectCommitted bool
+	}{
+		{
+			name: "missing file",
+			whenArchive: func(c Context) *Archive {
+				return NewArchive(c, ArchiveZip, "a.zip").AddFile("a.txt", "_fixture/missing.txt")
+			},
+			expectErr: "stat _fixture/missing.txt: no such file or directory",
+		},
+		{
+			name: "directory",
+			whenArchive: func(c Context) *Archive {
+				return NewArchive(c, ArchiveZip, "a.zip").AddFile("a", "_fixture")
+			},
+			expectErr: `echo: archive file "_fixture" is a directory`,
+		},
+		{
+			name: "invalid name",
+			whenArchive: func(c Context) *Archive {
+				return NewArchive(c, ArchiveZip, "a.zip").AddReader("/", strings.NewReader(""), 0, time.Time{})
+			},
+			expectErr: `echo: invalid archive entry "/"`,
+		},
+		{
+			name: "tar entry without size",
+			whenArchive: func(c Context) *Archive {
+				return NewArchive(c, ArchiveTarGz, "a.tar.gz").AddReader("a.txt", strings.NewReader("a"), -1, time.Time{})
+			},
+			expectErr: `echo: tar archive entry "a.txt" requires size`,
+		},
+		{
+			name: "size mismatch",
+			whenArchive: func(c Context) *Archive {
+				return NewArchive(c, ArchiveZip, "a.zip").AddReader("a.txt", strings.NewReader("a"), 10, time.Time{})
+			},
+			expectErr:       `echo: archive entry "a.txt" has 1 bytes, expected 10: unexpected EOF`,
+			expectCommitted: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/download", nil), rec)
+
+			err := tc.whenArchive(c).Send(http.StatusOK)
+
+			assert.EqualError(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectCommitted, c.Response().Committed)
+			if !tc.expectCommitted {
+				assert.Empty(t, rec.Header().Get(HeaderContentDisposition))
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+/*
+Package asyncop implements asynchronous request pattern: handler starts long running operation and responds with
+202 Accepted and `Location` of status endpoint, client polls the status endpoint until the operation is finished and
+fetches its result. Manager runs the operations in background, stores their state, progress and result and removes
+finished operations after `Config.TTL`.
+
+Example:
+
+	ops := asyncop.New(e, asyncop.Config{})
+
+	e.POST("/reports", func(c echo.Context) error {
+		var req ReportRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		op, err := ops.Start(c, func(ctx context.Context, p *asyncop.Progress) (interface{}, error) {
+			return generateReport(ctx, req, p)
+		})
+		if err != nil {
+			return err
+		}
+		return asyncop.Accepted(c, op)
+	})
+
+Status of the operation is served at `GET /operations/:id` and its result at `GET /operations/:id/result`.
+*/
+package asyncop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/random"
+)
+
+type (
+	// Status is status of the operation.
+	Status string
+
+	// Operation is state of asynchronous operation.
+	Operation struct {
+		// ID is ID of the operation.
+		ID string `json:"id"`
+		// Status is status of the operation.
+		Status Status `json:"status"`
+		// Progress is progress of the operation between 0 and 1.
+		Progress float64 `json:"progress"`
+		// Message is last progress message.
+		Message string `json:"message,omitempty"`
+		// Location is URL of status endpoint of the operation.
+		Location string `json:"location"`
+		// ResultLocation is URL of result endpoint of succeeded operation.
+		ResultLocation string `json:"result_location,omitempty"`
+		// Result is JSON encoded result of succeeded operation.
+		Result json.RawMessage `json:"-"`
+		// Error is error message of failed operation.
+		Error string `json:"error,omitempty"`
+		// CreatedAt is time the operation was started.
+		CreatedAt time.Time `json:"created_at"`
+		// UpdatedAt is time of the last change of the operation.
+		UpdatedAt time.Time `json:"updated_at"`
+		// ExpiresAt is time finished operation is removed, nil for unfinished operations.
+		ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	}
+
+	// Func is the operation run in background. Context is canceled when Echo is shut down and the operation does not
+	// finish in time. Returned value is encoded as JSON and served as result of the operation.
+	Func func(ctx context.Context, p *Progress) (interface{}, error)
+
+	// Progress reports progress of running operation.
+	Progress struct {
+		manager *Manager
+		mu      sync.Mutex
+		op      Operation
+	}
+
+	// Config defines the config for asynchronous operations.
+	Config struct {
+		// Store stores operations.
+		// Optional. Default value `NewMemoryStore()`.
+		Store Store
+
+		// BasePath is path prefix of status endpoints.
+		// Optional. Default value "/operations".
+		BasePath string
+
+		// TTL is duration finished operations are kept for.
+		// Optional. Default value 1 hour.
+		TTL time.Duration
+
+		// CleanupInterval is interval of removing expired operations from the store.
+		// Optional. Default value 1 minute.
+		CleanupInterval time.Duration
+
+		// RetryAfter is sent as `Retry-After` header of responses for unfinished operations.
+		// Optional. Default value 1 second.
+		RetryAfter time.Duration
+
+		// IDGenerator generates IDs of operations.
+		// Optional. Default value random.String(32).
+		IDGenerator func() string
+
+		// Middleware is middleware of status endpoints (i.e. authentication).
+		// Optional.
+		Middleware []echo.MiddlewareFunc
+	}
+
+	// Manager starts operations and serves their status.
+	Manager struct {
+		config  Config
+		echo    *echo.Echo
+		ctx     context.Context
+		cancel  context.CancelFunc
+		running sync.WaitGroup
+		mu      sync.Mutex
+		stop    chan struct{}
+		done    chan struct{}
+	}
+)
+
+// Statuses of operations.
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// DefaultConfig is the default asynchronous operations config.
+var DefaultConfig = Config{
+	BasePath:        "/operations",
+	TTL:             time.Hour,
+	CleanupInterval: time.Minute,
+	RetryAfter:      time.Second,
+	IDGenerator:     generator,
+}
+
+// Finished returns true for succeeded and failed operations.
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed
+}
+
+// New creates manager of asynchronous operations and registers its status endpoints. Expired operations are removed
+// while Echo is running, Echo shutdown waits for running operations.
+func New(e *echo.Echo, config Config) *Manager {
+	// Defaults
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.BasePath == "" {
+		config.BasePath = DefaultConfig.BasePath
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfig.TTL
+	}
+	if config.CleanupInterval <= 0 {
+		config.CleanupInterval = DefaultConfig.CleanupInterval
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = DefaultConfig.RetryAfter
+	}
+	if config.IDGenerator == nil {
+		config.IDGenerator = DefaultConfig.IDGenerator
+	}
+
+	m := &Manager{
+		config: config,
+		echo:   e,
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	e.GET(config.BasePath+"/:id", m.status, config.Middleware...)
+	e.GET(config.BasePath+"/:id/result", m.result, config.Middleware...)
+	e.OnStart(m.start)
+	e.OnShutdown(m.Shutdown)
+	return m
+}
+
+// start starts removing expired operations. It is called by `Echo#Start()`.
+func (m *Manager) start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		return nil
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.cleanup(m.stop, m.done)
+	return nil
+}
+
+// Shutdown stops removing expired operations and waits until running operations are finished. When context is done
+// first, running operations are canceled. It is called by `Echo#Shutdown()`.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		return ctx.Err()
+	}
+}
+
+// Run starts the operation in background and returns its initial state.
+func (m *Manager) Run(ctx context.Context, fn Func) (*Operation, error) {
+	now := m.echo.Now()
+	id := m.config.IDGenerator()
+	op := Operation{
+		ID:        id,
+		Status:    StatusPending,
+		Location:  m.config.BasePath + "/" + id,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := m.config.Store.Save(ctx, op); err != nil {
+		return nil, err
+	}
+	m.running.Add(1)
+	go m.run(&Progress{manager: m, op: op}, fn)
+	return &op, nil
+}
+
+// Start starts the operation of the request in background and returns its initial state. Use `Accepted()` to respond
+// with the operation.
+func (m *Manager) Start(c echo.Context, fn Func) (*Operation, error) {
+	return m.Run(c.Request().Context(), fn)
+}
+
+// Get returns the operation or `ErrNotFound` when it does not exist or is expired.
+func (m *Manager) Get(ctx context.Context, id string) (*Operation, error) {
+	op, err := m.config.Store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if op.ExpiresAt != nil && !m.echo.Now().Before(*op.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return &op, nil
+}
+
+// Accepted responds with 202 Accepted, `Location` of status endpoint of the operation and the operation as JSON.
+func Accepted(c echo.Context, op *Operation) error {
+	c.Response().Header().Set(echo.HeaderLocation, op.Location)
+	return c.JSON(http.StatusAccepted, op)
+}
+
+// Update updates progress (between 0 and 1) and message of the operation.
+func (p *Progress) Update(progress float64, message string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+	p.op.Progress = progress
+	p.op.Message = message
+	return p.save()
+}
+
+// save saves state of the operation, p.mu must be locked.
+func (p *Progress) save() error {
+	p.op.UpdatedAt = p.manager.echo.Now()
+	return p.manager.config.Store.Save(p.manager.ctx, p.op)
+}
+
+func (m *Manager) run(p *Progress, fn Func) {
+	defer m.running.Done()
+
+	p.mu.Lock()
+	p.op.Status = StatusRunning
+	if err := p.save(); err != nil {
+		m.echo.Logger.Errorf("asyncop: failed to save operation %s: %v", p.op.ID, err)
+	}
+	p.mu.Unlock()
+
+	result, err := call(m.ctx, p, fn)
+	var data []byte
+	if err == nil {
+		data, err = json.Marshal(result)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.op.Status = StatusFailed
+		p.op.Error = m.errorMessage(p.op.ID, err)
+	} else {
+		p.op.Status = StatusSucceeded
+		p.op.Progress = 1
+		p.op.Result = data
+		p.op.ResultLocation = p.op.Location + "/result"
+	}
+	now := m.echo.Now()
+	expiresAt := now.Add(m.config.TTL)
+	p.op.UpdatedAt, p.op.ExpiresAt = now, &expiresAt
+	// Finished state is saved even when operations were canceled by shutdown.
+	if err := m.config.Store.Save(context.Background(), p.op); err != nil {
+		m.echo.Logger.Errorf("asyncop: failed to save operation %s: %v", p.op.ID, err)
+	}
+}
+
+// call calls fn recovering from panic.
+func call(ctx context.Context, p *Progress, fn Func) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+	return fn(ctx, p)
+}
+
+// errorMessage returns error message of failed operation exposed to clients. Messages of `*echo.HTTPError` are
+// exposed as is, other errors are logged and reported as internal server error.
+func (m *Manager) errorMessage(id string, err error) string {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		if msg, ok := he.Message.(string); ok {
+			return msg
+		}
+	}
+	m.echo.Logger.Errorf("asyncop: operation %s failed: %s", id, m.echo.Redactor.Text(err.Error()))
+	return http.StatusText(http.StatusInternalServerError)
+}
+
+// status serves status of the operation.
+func (m *Manager) status(c echo.Context) error {
+	op, err := m.get(c)
+	if err != nil {
+		return err
+	}
+	if !op.Status.Finished() {
+		c.Response().Header().Set(echo.HeaderRetryAfter, m.retryAfter())
+	}
+	return c.JSON(http.StatusOK, op)
+}
+
+// result serves result of succeeded operation.
+func (m *Manager) result(c echo.Context) error {
+	op, err := m.get(c)
+	if err != nil {
+		return err
+	}
+	switch op.Status {
+	case StatusSucceeded:
+		return c.JSONBlob(http.StatusOK, op.Result)
+	case StatusFailed:
+		return echo.NewHTTPError(http.StatusConflict, "operation failed")
+	}
+	c.Response().Header().Set(echo.HeaderRetryAfter, m.retryAfter())
+	return echo.NewHTTPError(http.StatusConflict, "operation is not finished")
+}
+
+func (m *Manager) get(c echo.Context) (*Operation, error) {
+	op, err := m.Get(c.Request().Context(), c.Param("id"))
+	if err == ErrNotFound {
+		return nil, echo.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (m *Manager) retryAfter() string {
+	seconds := int(m.config.RetryAfter / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
+
+func (m *Manager) cleanup(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(m.config.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if _, err := m.config.Store.DeleteExpired(context.Background(), m.echo.Now()); err != nil {
+			m.echo.Logger.Errorf("asyncop: failed to delete expired operations: %v", err)
+		}
+	}
+}
+
+func generator() string {
+	return random.String(32)
+}
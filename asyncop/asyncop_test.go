@@ -0,0 +1,223 @@
+package asyncop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func request(e *echo.Echo, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) Operation {
+	var op Operation
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &op))
+	return op
+}
+
+func TestAccepted(t *testing.T) {
+	e := echo.New()
+	m := New(e, Config{IDGenerator: func() string { return "op1" }})
+	step := make(chan struct{})
+	e.POST("/reports", func(c echo.Context) error {
+		op, err := m.Start(c, func(ctx context.Context, p *Progress) (interface{}, error) {
+			<-step
+			assert.NoError(t, p.Update(0.5, "half way"))
+			<-step
+			return map[string]int{"rows": 42}, nil
+		})
+		if err != nil {
+			return err
+		}
+		return Accepted(c, op)
+	})
+
+	rec := request(e, http.MethodPost, "/reports")
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "/operations/op1", rec.Header().Get(echo.HeaderLocation))
+	op := decode(t, rec)
+	assert.Equal(t, "op1", op.ID)
+	assert.Equal(t, StatusPending, op.Status)
+	assert.Nil(t, op.ExpiresAt)
+
+	step <- struct{}{}
+	assert.Eventually(t, func() bool {
+		op, _ := m.Get(context.Background(), "op1")
+		return op.Progress == 0.5
+	}, time.Second, time.Millisecond)
+	rec = request(e, http.MethodGet, "/operations/op1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get(echo.HeaderRetryAfter))
+	op = decode(t, rec)
+	assert.Equal(t, StatusRunning, op.Status)
+	assert.Equal(t, "half way", op.Message)
+
+	rec = request(e, http.MethodGet, "/operations/op1/result")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	step <- struct{}{}
+	assert.NoError(t, m.Shutdown(context.Background()))
+	rec = request(e, http.MethodGet, "/operations/op1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderRetryAfter))
+	op = decode(t, rec)
+	assert.Equal(t, StatusSucceeded, op.Status)
+	assert.Equal(t, 1.0, op.Progress)
+	assert.Equal(t, "/operations/op1/result", op.ResultLocation)
+	assert.NotNil(t, op.ExpiresAt)
+
+	rec = request(e, http.MethodGet, "/operations/op1/result")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"rows":42}`, rec.Body.String())
+}
+
+func TestManager_failed(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      Func
+		message string
+	}{
+		{
+			name: "http error",
+			fn: func(ctx context.Context, p *Progress) (interface{}, error) {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid report")
+			},
+			message: "invalid report",
+		},
+		{
+			name: "error",
+			fn: func(ctx context.Context, p *Progress) (interface{}, error) {
+				return nil, errors.New("database is down")
+			},
+			message: http.StatusText(http.StatusInternalServerError),
+		},
+		{
+			name: "panic",
+			fn: func(ctx context.Context, p *Progress) (interface{}, error) {
+				panic("boom")
+			},
+			message: http.StatusText(http.StatusInternalServerError),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			m := New(e, Config{})
+			op, err := m.Run(context.Background(), tc.fn)
+			assert.NoError(t, err)
+			assert.NoError(t, m.Shutdown(context.Background()))
+
+			rec := request(e, http.MethodGet, op.Location)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			got := decode(t, rec)
+			assert.Equal(t, StatusFailed, got.Status)
+			assert.Equal(t, tc.message, got.Error)
+
+			rec = request(e, http.MethodGet, op.Location+"/result")
+			assert.Equal(t, http.StatusConflict, rec.Code)
+		})
+	}
+}
+
+func TestManager_notFound(t *testing.T) {
+	e := echo.New()
+	New(e, Config{})
+	rec := request(e, http.MethodGet, "/operations/unknown")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = request(e, http.MethodGet, "/operations/unknown/result")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestManager_TTL(t *testing.T) {
+	clock := &testClock{now: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	e := echo.New()
+	e.Clock = clock
+	store := NewMemoryStore()
+	m := New(e, Config{Store: store, TTL: time.Minute, CleanupInterval: 10 * time.Millisecond})
+	op, err := m.Run(context.Background(), func(ctx context.Context, p *Progress) (interface{}, error) {
+		return "done", nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, m.Shutdown(context.Background()))
+
+	got, err := m.Get(context.Background(), op.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(time.Minute), *got.ExpiresAt)
+
+	clock.add(time.Minute)
+	_, err = m.Get(context.Background(), op.ID)
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.NoError(t, m.start())
+	defer m.Shutdown(context.Background())
+	clock.add(time.Second)
+	assert.Eventually(t, func() bool {
+		_, err := store.Get(context.Background(), op.ID)
+		return err == ErrNotFound
+	}, time.Second, time.Millisecond)
+}
+
+func TestManager_ShutdownCancelsOperations(t *testing.T) {
+	e := echo.New()
+	m := New(e, Config{})
+	op, err := m.Run(context.Background(), func(ctx context.Context, p *Progress) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, m.Shutdown(ctx))
+	assert.Eventually(t, func() bool {
+		got, _ := m.Get(context.Background(), op.ID)
+		return got.Status == StatusFailed
+	}, time.Second, time.Millisecond)
+}
+
+func TestManager_Middleware(t *testing.T) {
+	e := echo.New()
+	New(e, Config{
+		BasePath: "/jobs",
+		Middleware: []echo.MiddlewareFunc{func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ") {
+					return echo.ErrUnauthorized
+				}
+				return next(c)
+			}
+		}},
+	})
+	rec := request(e, http.MethodGet, "/jobs/unknown")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
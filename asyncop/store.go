@@ -0,0 +1,66 @@
+package asyncop
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Store stores operations. Operations are passed by value so stores may keep them in memory or serialize them
+// (`Operation.Result` is encoded JSON).
+type Store interface {
+	// Save creates or replaces the operation.
+	Save(ctx context.Context, op Operation) error
+
+	// Get returns the operation or `ErrNotFound`.
+	Get(ctx context.Context, id string) (Operation, error)
+
+	// DeleteExpired deletes operations expired before the time and returns number of deleted operations.
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// ErrNotFound is returned by `Store#Get()` for unknown (or expired) operations.
+var ErrNotFound = errors.New("asyncop: operation not found")
+
+// MemoryStore keeps operations in memory of the instance. Use it with single instance or with sticky routing of
+// status requests, other instances do not see the operations.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	operations map[string]Operation
+}
+
+// NewMemoryStore creates empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{operations: map[string]Operation{}}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, op Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operations[op.ID] = op
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	op, ok := s.operations[id]
+	if !ok {
+		return Operation{}, ErrNotFound
+	}
+	return op, nil
+}
+
+func (s *MemoryStore) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, op := range s.operations {
+		if op.ExpiresAt != nil && op.ExpiresAt.Before(before) {
+			delete(s.operations, id)
+			n++
+		}
+	}
+	return n, nil
+}
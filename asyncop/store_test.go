@@ -0,0 +1,33 @@
+package asyncop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Second)
+	s := NewMemoryStore()
+
+	_, err := s.Get(ctx, "a")
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.NoError(t, s.Save(ctx, Operation{ID: "a", Status: StatusRunning}))
+	assert.NoError(t, s.Save(ctx, Operation{ID: "b", Status: StatusSucceeded, ExpiresAt: &expired}))
+	op, err := s.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRunning, op.Status)
+
+	n, err := s.DeleteExpired(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = s.Get(ctx, "b")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = s.Get(ctx, "a")
+	assert.NoError(t, err)
+}
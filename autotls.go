@@ -0,0 +1,175 @@
+package echo
+
+import (
+	stdContext "context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type (
+	// AutoTLSConfig defines the config for `Echo#AutoTLSManager` used by StartAutoTLS and StartWithRedirect for
+	// serving multiple (customer) domains.
+	AutoTLSConfig struct {
+		// HostPolicy decides for which hosts certificates are requested. Use `AutoTLSHostPolicy` for static
+		// allow-list or custom callback checking domains of tenants.
+		// Optional. Default value nil (certificates are requested for any host, not recommended).
+		HostPolicy autocert.HostPolicy
+
+		// Cache stores issued certificates and ACME account key. Use `autocert.DirCache` for directory,
+		// `HostCache` for different backends per host or implement `autocert.Cache` for shared storage
+		// (DynamoDB, Redis etc.) when multiple instances serve same domains.
+		// Optional. Default value nil (certificates are not persisted).
+		Cache autocert.Cache
+
+		// Email is contact address of ACME account.
+		// Optional.
+		Email string
+
+		// RenewBefore is how early certificates are renewed before they expire.
+		// Optional. Default value 30 days.
+		RenewBefore time.Duration
+
+		// Certificates are served for hosts they are valid for (including wildcard and SAN names) instead of
+		// requesting certificate from ACME provider. ACME http-01 and tls-alpn-01 challenges can not issue
+		// wildcard certificates so they need to be obtained separately.
+		// Optional.
+		Certificates []tls.Certificate
+	}
+
+	// HostCache is `autocert.Cache` that stores certificates of hosts in different cache backends. Keys not
+	// belonging to any host (ACME account key, http-01 tokens) are stored in Default cache.
+	HostCache struct {
+		// Default is cache for hosts not found in Hosts.
+		// Optional. Default value nil (not cached).
+		Default autocert.Cache
+
+		// Hosts maps host names to their caches. Key can be wildcard pattern `*.example.com` matching
+		// subdomains (single label) of example.com.
+		Hosts map[string]autocert.Cache
+	}
+)
+
+// ConfigureAutoTLS configures `Echo#AutoTLSManager` for serving multiple domains. Must be called before server is
+// started.
+func (e *Echo) ConfigureAutoTLS(config AutoTLSConfig) error {
+	certs := make([]tls.Certificate, len(config.Certificates))
+	for i, c := range config.Certificates {
+		if c.Leaf == nil {
+			if len(c.Certificate) == 0 {
+				return fmt.Errorf("echo: auto tls certificate at index=%d is empty", i)
+			}
+			leaf, err := x509.ParseCertificate(c.Certificate[0])
+			if err != nil {
+				return fmt.Errorf("echo: invalid auto tls certificate at index=%d: %w", i, err)
+			}
+			c.Leaf = leaf
+		}
+		certs[i] = c
+	}
+
+	e.startupMutex.Lock()
+	defer e.startupMutex.Unlock()
+	e.AutoTLSManager.HostPolicy = config.HostPolicy
+	e.AutoTLSManager.Cache = config.Cache
+	e.AutoTLSManager.Email = config.Email
+	e.AutoTLSManager.RenewBefore = config.RenewBefore
+	e.autoTLSCerts = certs
+	return nil
+}
+
+// getAutoTLSCertificate returns certificate configured for the host with ConfigureAutoTLS or certificate from
+// `Echo#AutoTLSManager`.
+func (e *Echo) getAutoTLSCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+	if name != "" {
+		for i := range e.autoTLSCerts {
+			if e.autoTLSCerts[i].Leaf.VerifyHostname(name) == nil {
+				return &e.autoTLSCerts[i], nil
+			}
+		}
+	}
+	return e.AutoTLSManager.GetCertificate(hello)
+}
+
+// AutoTLSHostPolicy returns host policy allowing only given hosts. Host can be wildcard pattern `*.example.com`
+// matching subdomains (single label) of example.com.
+func AutoTLSHostPolicy(hosts ...string) autocert.HostPolicy {
+	patterns := make([]string, len(hosts))
+	for i, h := range hosts {
+		patterns[i] = strings.ToLower(h)
+	}
+	return func(_ stdContext.Context, host string) error {
+		if matchHostPattern(patterns, strings.ToLower(host)) == "" {
+			return fmt.Errorf("echo: host %q is not allowed by auto tls host policy", host)
+		}
+		return nil
+	}
+}
+
+// matchHostPattern returns pattern matching the host. Exact patterns are preferred over wildcards.
+func matchHostPattern(patterns []string, host string) string {
+	wildcard := ""
+	for _, p := range patterns {
+		if p == host {
+			return p
+		}
+		if wildcard == "" && strings.HasPrefix(p, "*.") {
+			i := strings.IndexByte(host, '.')
+			if i > 0 && host[i:] == p[1:] {
+				wildcard = p
+			}
+		}
+	}
+	return wildcard
+}
+
+// cache returns cache for given cache key. Certificate keys are host name optionally followed by `+rsa` or
+// `+token` suffix.
+func (c *HostCache) cache(key string) autocert.Cache {
+	host := key
+	if i := strings.IndexByte(key, '+'); i >= 0 {
+		host = key[:i]
+	}
+	if len(c.Hosts) > 0 && strings.Contains(host, ".") {
+		patterns := make([]string, 0, len(c.Hosts))
+		for p := range c.Hosts {
+			patterns = append(patterns, p)
+		}
+		if p := matchHostPattern(patterns, strings.ToLower(host)); p != "" {
+			return c.Hosts[p]
+		}
+	}
+	return c.Default
+}
+
+// Get returns data stored for the key or `autocert.ErrCacheMiss`.
+func (c *HostCache) Get(ctx stdContext.Context, key string) ([]byte, error) {
+	cache := c.cache(key)
+	if cache == nil {
+		return nil, autocert.ErrCacheMiss
+	}
+	return cache.Get(ctx, key)
+}
+
+// Put stores data for the key.
+func (c *HostCache) Put(ctx stdContext.Context, key string, data []byte) error {
+	cache := c.cache(key)
+	if cache == nil {
+		return nil
+	}
+	return cache.Put(ctx, key, data)
+}
+
+// Delete removes data stored for the key.
+func (c *HostCache) Delete(ctx stdContext.Context, key string) error {
+	cache := c.cache(key)
+	if cache == nil {
+		return nil
+	}
+	return cache.Delete(ctx, key)
+}
@@ -0,0 +1,163 @@
+package echo
+
+import (
+	stdContext "context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type memoryCache map[string][]byte
+
+func (c memoryCache) Get(_ stdContext.Context, key string) ([]byte, error) {
+	if b, ok := c[key]; ok {
+		return b, nil
+	}
+	return nil, autocert.ErrCacheMiss
+}
+
+func (c memoryCache) Put(_ stdContext.Context, key string, data []byte) error {
+	c[key] = data
+	return nil
+}
+
+func (c memoryCache) Delete(_ stdContext.Context, key string) error {
+	delete(c, key)
+	return nil
+}
+
+func selfSignedCertificate(t *testing.T, names ...string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestEcho_ConfigureAutoTLS(t *testing.T) {
+	wildcard := selfSignedCertificate(t, "*.example.com", "example.com")
+	san := selfSignedCertificate(t, "shop.customer.org", "www.customer.org")
+
+	e := New()
+	err := e.ConfigureAutoTLS(AutoTLSConfig{
+		HostPolicy:   AutoTLSHostPolicy("allowed.org"),
+		Cache:        memoryCache{},
+		Email:        "admin@example.com",
+		RenewBefore:  7 * 24 * time.Hour,
+		Certificates: []tls.Certificate{wildcard, san},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "admin@example.com", e.AutoTLSManager.Email)
+	assert.Equal(t, 7*24*time.Hour, e.AutoTLSManager.RenewBefore)
+
+	var testCases = []struct {
+		name        string
+		serverName  string
+		expectCert  *tls.Certificate
+		expectError string
+	}{
+		{name: "ok, wildcard", serverName: "api.example.com", expectCert: &wildcard},
+		{name: "ok, wildcard base domain", serverName: "Example.COM.", expectCert: &wildcard},
+		{name: "ok, SAN", serverName: "www.customer.org", expectCert: &san},
+		{name: "nok, host not allowed by policy", serverName: "unknown.org", expectError: `echo: host "unknown.org" is not allowed by auto tls host policy`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cert, err := e.getAutoTLSCertificate(&tls.ClientHelloInfo{ServerName: tc.serverName})
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectCert.Certificate, cert.Certificate)
+		})
+	}
+}
+
+func TestEcho_ConfigureAutoTLS_invalidCertificate(t *testing.T) {
+	e := New()
+	err := e.ConfigureAutoTLS(AutoTLSConfig{Certificates: []tls.Certificate{{Certificate: [][]byte{[]byte("nope")}}}})
+	assert.Error(t, err)
+
+	err = e.ConfigureAutoTLS(AutoTLSConfig{Certificates: []tls.Certificate{{}}})
+	assert.EqualError(t, err, "echo: auto tls certificate at index=0 is empty")
+}
+
+func TestAutoTLSHostPolicy(t *testing.T) {
+	policy := AutoTLSHostPolicy("example.com", "*.customer.org")
+
+	var testCases = []struct {
+		host        string
+		expectAllow bool
+	}{
+		{host: "example.com", expectAllow: true},
+		{host: "EXAMPLE.com", expectAllow: true},
+		{host: "www.example.com", expectAllow: false},
+		{host: "shop.customer.org", expectAllow: true},
+		{host: "customer.org", expectAllow: false},
+		{host: "a.b.customer.org", expectAllow: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			err := policy(stdContext.Background(), tc.host)
+			assert.Equal(t, tc.expectAllow, err == nil)
+		})
+	}
+}
+
+func TestHostCache(t *testing.T) {
+	defaultCache := memoryCache{}
+	tenantCache := memoryCache{}
+	shopCache := memoryCache{}
+	cache := &HostCache{
+		Default: defaultCache,
+		Hosts: map[string]autocert.Cache{
+			"*.tenant.io":    tenantCache,
+			"shop.tenant.io": shopCache,
+		},
+	}
+	ctx := stdContext.Background()
+
+	assert.NoError(t, cache.Put(ctx, "acme_account+key", []byte("account")))
+	assert.NoError(t, cache.Put(ctx, "a.tenant.io", []byte("a")))
+	assert.NoError(t, cache.Put(ctx, "a.tenant.io+rsa", []byte("a-rsa")))
+	assert.NoError(t, cache.Put(ctx, "shop.tenant.io", []byte("shop")))
+	assert.NoError(t, cache.Put(ctx, "other.org", []byte("other")))
+
+	assert.Equal(t, memoryCache{"acme_account+key": []byte("account"), "other.org": []byte("other")}, defaultCache)
+	assert.Equal(t, memoryCache{"a.tenant.io": []byte("a"), "a.tenant.io+rsa": []byte("a-rsa")}, tenantCache)
+	assert.Equal(t, memoryCache{"shop.tenant.io": []byte("shop")}, shopCache)
+
+	b, err := cache.Get(ctx, "a.tenant.io+rsa")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a-rsa"), b)
+
+	assert.NoError(t, cache.Delete(ctx, "a.tenant.io"))
+	_, err = cache.Get(ctx, "a.tenant.io")
+	assert.Equal(t, autocert.ErrCacheMiss, err)
+
+	empty := &HostCache{}
+	_, err = empty.Get(ctx, "a.tenant.io")
+	assert.Equal(t, autocert.ErrCacheMiss, err)
+	assert.NoError(t, empty.Put(ctx, "a.tenant.io", []byte("a")))
+	assert.NoError(t, empty.Delete(ctx, "a.tenant.io"))
+}
@@ -2,13 +2,16 @@ package echo
 
 import (
 	"encoding"
-	"encoding/xml"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type (
@@ -18,7 +21,42 @@ type (
 	}
 
 	// DefaultBinder is the default implementation of the Binder interface.
-	DefaultBinder struct{}
+	DefaultBinder struct {
+		// Strict enables strict binding mode. Instead of failing on first field that could not be converted, all
+		// conversion failures are collected together with query and form parameters that do not match any field,
+		// and returned as `BindErrors` inside an HTTPError with status 422.
+		// NB: in strict mode JSON body is decoded with `encoding/json` (disallowing unknown fields) instead of
+		// `Echo#JSONSerializer`. Limits of `DefaultJSONSerializer` are still enforced.
+		Strict bool
+
+		// Charsets are decoders of request body charsets (`charset` parameter of `Content-Type`) in addition to
+		// built in UTF-8, US-ASCII, ISO-8859-1 (latin1) and UTF-16. Keys are lower case charset names. Bodies in
+		// other charsets are rejected with 415.
+		Charsets map[string]CharsetDecoder
+	}
+
+	// BindFieldError describes a single field that could not be bound in strict binding mode or that failed
+	// validation.
+	BindFieldError struct {
+		// Source is where the value came from: "param", "query", "form", "header" or "json".
+		Source string `json:"source"`
+		Field  string `json:"field"`
+		Value  string `json:"value,omitempty"`
+		// Code is stable machine-readable reason of the error (i.e. FieldErrorInvalidValue), clients should use it
+		// instead of Message which may be localized.
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+
+	// BindErrors is the list of field errors returned by strict binding mode. Validators may return it too,
+	// `Context#Validate()` responds with it as 422 Unprocessable Entity.
+	BindErrors []*BindFieldError
+
+	strictBinding struct {
+		source string
+		used   map[string]bool
+		errors BindErrors
+	}
 
 	// BindUnmarshaler is the interface used to wrap the UnmarshalParam method.
 	// Types that don't implement this, but do implement encoding.TextUnmarshaler
@@ -29,6 +67,13 @@ type (
 	}
 )
 
+// Codes of field errors reported by `DefaultBinder`. Validators returning `BindErrors` define their own codes (i.e.
+// "required", "min").
+const (
+	FieldErrorInvalidValue = "invalid_value"
+	FieldErrorUnknownField = "unknown_field"
+)
+
 // BindPathParams binds path params to bindable object
 func (b *DefaultBinder) BindPathParams(c Context, i interface{}) error {
 	names := c.ParamNames()
@@ -37,25 +82,21 @@ func (b *DefaultBinder) BindPathParams(c Context, i interface{}) error {
 	for i, name := range names {
 		params[name] = []string{values[i]}
 	}
-	if err := b.bindData(i, params, "param"); err != nil {
-		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
-	}
-	return nil
+	return b.bindValues(i, params, "param")
 }
 
 // BindQueryParams binds query params to bindable object
 func (b *DefaultBinder) BindQueryParams(c Context, i interface{}) error {
-	if err := b.bindData(i, c.QueryParams(), "query"); err != nil {
-		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
-	}
-	return nil
+	return b.bindValues(i, c.QueryParams(), "query")
 }
 
-// BindBody binds request body contents to bindable object
+// BindBody binds request body contents to bindable object. Bodies other than forms are deserialized by codec
+// registered for the content type, see `Echo#RegisterCodec()`.
 // NB: then binding forms take note that this implementation uses standard library form parsing
 // which parses form data from BOTH URL and BODY if content type is not MIMEMultipartForm
 // See non-MIMEMultipartForm: https://golang.org/pkg/net/http/#Request.ParseForm
 // See MIMEMultipartForm: https://golang.org/pkg/net/http/#Request.ParseMultipartForm
+// Other bodies are decoded to UTF-8 from charset of the content type and UTF-8 byte order mark is removed.
 func (b *DefaultBinder) BindBody(c Context, i interface{}) (err error) {
 	req := c.Request()
 	if req.ContentLength == 0 {
@@ -63,52 +104,46 @@ func (b *DefaultBinder) BindBody(c Context, i interface{}) (err error) {
 	}
 
 	ctype := req.Header.Get(HeaderContentType)
-	switch {
-	case strings.HasPrefix(ctype, MIMEApplicationJSON):
-		if err = c.Echo().JSONSerializer.Deserialize(c, i); err != nil {
-			switch err.(type) {
-			case *HTTPError:
-				return err
-			default:
-				return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
-			}
-		}
-	case strings.HasPrefix(ctype, MIMEApplicationXML), strings.HasPrefix(ctype, MIMETextXML):
-		if err = xml.NewDecoder(req.Body).Decode(i); err != nil {
-			if ute, ok := err.(*xml.UnsupportedTypeError); ok {
-				return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported type error: type=%v, error=%v", ute.Type, ute.Error())).SetInternal(err)
-			} else if se, ok := err.(*xml.SyntaxError); ok {
-				return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Syntax error: line=%v, error=%v", se.Line, se.Error())).SetInternal(err)
-			}
-			return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
-		}
-	case strings.HasPrefix(ctype, MIMEApplicationForm), strings.HasPrefix(ctype, MIMEMultipartForm):
+	if strings.HasPrefix(ctype, MIMEApplicationForm) || strings.HasPrefix(ctype, MIMEMultipartForm) {
 		params, err := c.FormParams()
 		if err != nil {
 			return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
 		}
-		if err = b.bindData(i, params, "form"); err != nil {
+		return b.bindValues(i, params, "form")
+	}
+
+	codec := c.Echo().Codec(ctype)
+	if codec == nil {
+		return ErrUnsupportedMediaType
+	}
+	if err = b.decodeBody(req); err != nil {
+		return err
+	}
+	if _, ok := codec.(jsonCodec); ok && b.Strict {
+		return bindJSONStrict(c, i)
+	}
+	if err = codec.Deserialize(c, i); err != nil {
+		switch err.(type) {
+		case *HTTPError:
+			return err
+		default:
 			return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
 		}
-	default:
-		return ErrUnsupportedMediaType
 	}
 	return nil
 }
 
 // BindHeaders binds HTTP headers to a bindable object
 func (b *DefaultBinder) BindHeaders(c Context, i interface{}) error {
-	if err := b.bindData(i, c.Request().Header, "header"); err != nil {
-		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
-	}
-	return nil
+	return b.bindValues(i, c.Request().Header, "header")
 }
 
 // Bind implements the `Binder#Bind` function.
 // Binding is done in following order: 1) path params; 2) query params; 3) request body. Each step COULD override previous
 // step binded values. For single source binding use their own methods BindBody, BindQueryParams, BindPathParams.
 func (b *DefaultBinder) Bind(i interface{}, c Context) (err error) {
-	if err := b.BindPathParams(c, i); err != nil {
+	var bindErrs BindErrors // collected over all sources in strict mode
+	if bindErrs, err = appendBindErrors(bindErrs, b.BindPathParams(c, i)); err != nil {
 		return err
 	}
 	// Issue #1670 - Query params are binded only for GET/DELETE and NOT for usual request with body (POST/PUT/PATCH)
@@ -116,15 +151,45 @@ func (b *DefaultBinder) Bind(i interface{}, c Context) (err error) {
 	// i.e. is `&id=1&lang=en` from URL same as `{"id":100,"lang":"de"}` request body and which one should have priority when binding.
 	// This HTTP method check restores pre v4.1.11 behavior and avoids different problems when query is mixed with body
 	if c.Request().Method == http.MethodGet || c.Request().Method == http.MethodDelete {
-		if err = b.BindQueryParams(c, i); err != nil {
+		if bindErrs, err = appendBindErrors(bindErrs, b.BindQueryParams(c, i)); err != nil {
 			return err
 		}
 	}
-	return b.BindBody(c, i)
+	if bindErrs, err = appendBindErrors(bindErrs, b.BindBody(c, i)); err != nil {
+		return err
+	}
+	if len(bindErrs) > 0 {
+		return bindErrs.httpError()
+	}
+	return nil
+}
+
+// bindValues binds data from given source (tag) and converts errors to HTTPError
+func (b *DefaultBinder) bindValues(i interface{}, data map[string][]string, tag string) error {
+	if !b.Strict {
+		if err := b.bindData(i, data, tag); err != nil {
+			return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+		return nil
+	}
+	s := &strictBinding{source: tag, used: map[string]bool{}}
+	if err := b.bindDataWithState(i, data, tag, s); err != nil {
+		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	if len(s.errors) > 0 {
+		return s.errors.httpError()
+	}
+	return nil
 }
 
 // bindData will bind data ONLY fields in destination struct that have EXPLICIT tag
 func (b *DefaultBinder) bindData(destination interface{}, data map[string][]string, tag string) error {
+	return b.bindDataWithState(destination, data, tag, nil)
+}
+
+// bindDataWithState binds data like bindData. When strict binding state is given conversion errors are collected
+// into it instead of being returned.
+func (b *DefaultBinder) bindDataWithState(destination interface{}, data map[string][]string, tag string, s *strictBinding) error {
 	if destination == nil || len(data) == 0 {
 		return nil
 	}
@@ -133,10 +198,7 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 
 	// Map
 	if typ.Kind() == reflect.Map {
-		for k, v := range data {
-			val.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v[0]))
-		}
-		return nil
+		return bindMap(val, data)
 	}
 
 	// !struct
@@ -147,7 +209,17 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 		}
 		return errors.New("binding element must be a struct")
 	}
+	if err := b.bindStruct(val, data, tag, s); err != nil {
+		return err
+	}
+	if s != nil && (tag == "query" || tag == "form") {
+		s.addUnknown(data)
+	}
+	return nil
+}
 
+func (b *DefaultBinder) bindStruct(val reflect.Value, data map[string][]string, tag string, s *strictBinding) error {
+	typ := val.Type()
 	for i := 0; i < typ.NumField(); i++ {
 		typeField := typ.Field(i)
 		structField := val.Field(i)
@@ -156,6 +228,13 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 				structField = structField.Elem()
 			}
 		}
+		if typeField.Anonymous && structField.Kind() == reflect.Struct && !structField.CanSet() && typeField.Tag.Get(tag) == "" {
+			// embedded struct of unexported type can not be set as whole but its exported fields still can
+			if err := b.bindStruct(structField, data, tag, s); err != nil {
+				return err
+			}
+			continue
+		}
 		if !structField.CanSet() {
 			continue
 		}
@@ -170,7 +249,7 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 			// If tag is nil, we inspect if the field is a not BindUnmarshaler struct and try to bind data into it (might contains fields with tags).
 			// structs that implement BindUnmarshaler are binded only when they have explicit tag
 			if _, ok := structField.Addr().Interface().(BindUnmarshaler); !ok && structFieldKind == reflect.Struct {
-				if err := b.bindData(structField.Addr().Interface(), data, tag); err != nil {
+				if err := b.bindStruct(structField, data, tag, s); err != nil {
 					return err
 				}
 			}
@@ -178,6 +257,7 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 			continue
 		}
 
+		inputKey := inputFieldName
 		inputValue, exists := data[inputFieldName]
 		if !exists {
 			// Go json.Unmarshal supports case insensitive binding.  However the
@@ -186,6 +266,7 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 			// case-insensitive search.
 			for k, v := range data {
 				if strings.EqualFold(k, inputFieldName) {
+					inputKey = k
 					inputValue = v
 					exists = true
 					break
@@ -196,33 +277,223 @@ func (b *DefaultBinder) bindData(destination interface{}, data map[string][]stri
 		if !exists {
 			continue
 		}
+		if s != nil {
+			s.used[inputKey] = true
+		}
+
+		// Custom time layout, i.e. `format:"2006-01-02"`, has precedence over time.Time own unmarshalling
+		if layout := typeField.Tag.Get("format"); layout != "" {
+			if ok, err := setTimeWithLayout(layout, inputValue, structField); ok {
+				if err != nil {
+					if s == nil {
+						return err
+					}
+					s.add(inputKey, inputValue, FieldErrorInvalidValue, err)
+				}
+				continue
+			}
+		}
 
 		// Call this first, in case we're dealing with an alias to an array type
 		if ok, err := unmarshalField(typeField.Type.Kind(), inputValue[0], structField); ok {
 			if err != nil {
-				return err
+				if s == nil {
+					return err
+				}
+				s.add(inputKey, inputValue, FieldErrorInvalidValue, err)
 			}
 			continue
 		}
 
+		// Pointer to slice, i.e. `*[]int`, is bound as slice
+		if structFieldKind == reflect.Ptr && structField.Type().Elem().Kind() == reflect.Slice {
+			if structField.IsNil() {
+				structField.Set(reflect.New(structField.Type().Elem()))
+			}
+			structField = structField.Elem()
+			structFieldKind = reflect.Slice
+		}
+
+		var err error
 		numElems := len(inputValue)
 		if structFieldKind == reflect.Slice && numElems > 0 {
 			sliceOf := structField.Type().Elem().Kind()
 			slice := reflect.MakeSlice(structField.Type(), numElems, numElems)
-			for j := 0; j < numElems; j++ {
-				if err := setWithProperType(sliceOf, inputValue[j], slice.Index(j)); err != nil {
-					return err
-				}
+			for j := 0; j < numElems && err == nil; j++ {
+				err = setWithProperType(sliceOf, inputValue[j], slice.Index(j))
 			}
-			val.Field(i).Set(slice)
-		} else if err := setWithProperType(typeField.Type.Kind(), inputValue[0], structField); err != nil {
-			return err
+			if err == nil {
+				structField.Set(slice)
+			}
+		} else {
+			err = setWithProperType(typeField.Type.Kind(), inputValue[0], structField)
+		}
+		if err != nil {
+			if s == nil {
+				return err
+			}
+			s.add(inputKey, inputValue, FieldErrorInvalidValue, err)
+		}
+	}
+	return nil
+}
+
+func (s *strictBinding) add(field string, values []string, code string, err error) {
+	s.errors = append(s.errors, &BindFieldError{
+		Source:  s.source,
+		Field:   field,
+		Value:   strings.Join(values, ","),
+		Code:    code,
+		Message: err.Error(),
+	})
+}
+
+func (s *strictBinding) addUnknown(data map[string][]string) {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		if !s.used[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s.add(k, data[k], FieldErrorUnknownField, errors.New("unknown field"))
+	}
+}
+
+// bindJSONStrict decodes JSON body disallowing unknown fields and reports decoding failures as BindErrors.
+func bindJSONStrict(c Context, i interface{}) error {
+	var r io.Reader = c.Request().Body
+	if s, ok := c.Echo().JSONSerializer.(*DefaultJSONSerializer); ok {
+		r = s.limitReader(r)
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(i)
+	if err == nil {
+		return nil
+	}
+	if he := jsonLimitError(err); he != nil {
+		return he
+	}
+	const unknownFieldPrefix = "json: unknown field "
+	if ute, ok := err.(*json.UnmarshalTypeError); ok {
+		return BindErrors{{Source: "json", Field: ute.Field, Code: FieldErrorInvalidValue, Message: ute.Error()}}.httpError()
+	} else if strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+		return BindErrors{{Source: "json", Field: field, Code: FieldErrorUnknownField, Message: "unknown field"}}.httpError()
+	} else if se, ok := err.(*json.SyntaxError); ok {
+		return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Syntax error: offset=%v, error=%v", se.Offset, se.Error())).SetInternal(err)
+	}
+	return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+}
+
+// appendBindErrors appends field errors reported by strict binding to errs. Other errors are returned as is.
+func appendBindErrors(errs BindErrors, err error) (BindErrors, error) {
+	if he, ok := err.(*HTTPError); ok {
+		if be, ok := he.Internal.(BindErrors); ok {
+			return append(errs, be...), nil
+		}
+	}
+	return errs, err
+}
+
+// Error returns error message for the field.
+func (e *BindFieldError) Error() string {
+	return fmt.Sprintf("%s field=%s: %s", e.Source, e.Field, e.Message)
+}
+
+// Error returns error messages of all fields.
+func (e BindErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e BindErrors) httpError() *HTTPError {
+	return NewHTTPError(http.StatusUnprocessableEntity, Map{
+		"message": http.StatusText(http.StatusUnprocessableEntity),
+		"errors":  e,
+	}).SetInternal(e)
+}
 
+func bindMap(val reflect.Value, data map[string][]string) error {
+	typ := val.Type()
+	if typ.Key().Kind() != reflect.String {
+		return errors.New("binding element must be a map with string keys")
+	}
+	elemType := typ.Elem()
+	isSlice := elemType == reflect.TypeOf([]string(nil))
+	if !isSlice && !reflect.TypeOf("").AssignableTo(elemType) {
+		return errors.New("binding element must be a map of string or []string values")
+	}
+	if val.IsNil() {
+		val.Set(reflect.MakeMap(typ))
+	}
+	for k, v := range data {
+		key := reflect.ValueOf(k).Convert(typ.Key())
+		if isSlice {
+			val.SetMapIndex(key, reflect.ValueOf(v))
+		} else {
+			val.SetMapIndex(key, reflect.ValueOf(v[0]))
 		}
 	}
 	return nil
 }
 
+var timeType = reflect.TypeOf(time.Time{})
+
+// setTimeWithLayout parses values with given time layout into time.Time, *time.Time, []time.Time or *[]time.Time
+// field. Returns false when field is not of any of these types.
+func setTimeWithLayout(layout string, values []string, field reflect.Value) (bool, error) {
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr && typ.Elem() != timeType {
+		if typ.Elem().Kind() != reflect.Slice || typ.Elem().Elem() != timeType {
+			return false, nil
+		}
+		if field.IsNil() {
+			field.Set(reflect.New(typ.Elem()))
+		}
+		field = field.Elem()
+		typ = field.Type()
+	}
+
+	switch {
+	case typ == timeType || (typ.Kind() == reflect.Ptr && typ.Elem() == timeType):
+		return true, setTimeField(layout, values[0], field)
+	case typ.Kind() == reflect.Slice && typ.Elem() == timeType:
+		slice := reflect.MakeSlice(typ, len(values), len(values))
+		for i, v := range values {
+			if err := setTimeField(layout, v, slice.Index(i)); err != nil {
+				return true, err
+			}
+		}
+		field.Set(slice)
+		return true, nil
+	}
+	return false, nil
+}
+
+func setTimeField(layout string, value string, field reflect.Value) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(timeType))
+		}
+		field = field.Elem()
+	}
+	if value == "" {
+		field.Set(reflect.Zero(timeType))
+		return nil
+	}
+	t, err := time.Parse(layout, value)
+	if err == nil {
+		field.Set(reflect.ValueOf(t))
+	}
+	return err
+}
+
 func setWithProperType(valueKind reflect.Kind, val string, structField reflect.Value) error {
 	// But also call it here, in case we're dealing with an array of BindUnmarshalers
 	if ok, err := unmarshalField(valueKind, val, structField); ok {
@@ -1043,3 +1043,198 @@ func TestDefaultBinder_BindBody(t *testing.T) {
 		})
 	}
 }
+
+type bindEmbeddedBase struct {
+	ID int `query:"id"`
+}
+
+func TestDefaultBinder_bindDataFormats(t *testing.T) {
+	type Embedded struct {
+		Lang string `query:"lang"`
+	}
+	type target struct {
+		bindEmbeddedBase
+		Embedded
+		Date     time.Time    `query:"date" format:"2006-01-02"`
+		DatePtr  *time.Time   `query:"date" format:"2006-01-02"`
+		Dates    []time.Time  `query:"dates" format:"02.01.2006"`
+		DatesPtr *[]time.Time `query:"dates" format:"02.01.2006"`
+		IDs      *[]int       `query:"ids"`
+		NoIDs    *[]int       `query:"no_ids"`
+	}
+
+	e := New()
+	req := httptest.NewRequest(http.MethodGet, "/?id=1&lang=et&date=2021-03-04&dates=01.02.2021&dates=02.02.2021&ids=1&ids=2", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	result := target{}
+	err := c.Bind(&result)
+	if assert.NoError(t, err) {
+		date := time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)
+		dates := []time.Time{time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 2, 2, 0, 0, 0, 0, time.UTC)}
+		assert.Equal(t, 1, result.ID)
+		assert.Equal(t, "et", result.Lang)
+		assert.Equal(t, date, result.Date)
+		assert.Equal(t, &date, result.DatePtr)
+		assert.Equal(t, dates, result.Dates)
+		assert.Equal(t, &dates, result.DatesPtr)
+		assert.Equal(t, &[]int{1, 2}, result.IDs)
+		assert.Nil(t, result.NoIDs)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/?date=04.03.2021", nil)
+	c = e.NewContext(req, httptest.NewRecorder())
+	err = c.Bind(&target{})
+	assert.EqualError(t, err, `code=400, message=parsing time "04.03.2021" as "2006-01-02": cannot parse "04.03.2021" as "2006", internal=parsing time "04.03.2021" as "2006-01-02": cannot parse "04.03.2021" as "2006"`)
+}
+
+func TestDefaultBinder_bindDataToMap(t *testing.T) {
+	b := new(DefaultBinder)
+	values := map[string][]string{"a": {"1", "2"}, "b": {"3"}}
+
+	var strMap map[string]string
+	if assert.NoError(t, b.bindData(&strMap, values, "query")) {
+		assert.Equal(t, map[string]string{"a": "1", "b": "3"}, strMap)
+	}
+
+	sliceMap := map[string][]string{}
+	if assert.NoError(t, b.bindData(&sliceMap, values, "query")) {
+		assert.Equal(t, values, sliceMap)
+	}
+
+	ifaceMap := map[string]interface{}{}
+	if assert.NoError(t, b.bindData(&ifaceMap, values, "query")) {
+		assert.Equal(t, map[string]interface{}{"a": "1", "b": "3"}, ifaceMap)
+	}
+
+	intMap := map[string]int{}
+	assert.EqualError(t, b.bindData(&intMap, values, "query"), "binding element must be a map of string or []string values")
+}
+
+func TestDefaultBinder_Strict(t *testing.T) {
+	type target struct {
+		ID    int       `param:"id" query:"id" json:"id"`
+		Count int       `query:"count" form:"count" json:"count"`
+		Date  time.Time `query:"date" format:"2006-01-02"`
+		Name  string    `query:"name" form:"name" json:"name"`
+	}
+
+	var testCases = []struct {
+		name             string
+		givenMethod      string
+		givenURL         string
+		givenContentType string
+		givenContent     string
+		whenParamID      string
+		expect           target
+		expectErrors     BindErrors
+		expectError      string
+	}{
+		{
+			name:        "ok",
+			givenMethod: http.MethodGet,
+			givenURL:    "/?count=2&name=jon",
+			whenParamID: "1",
+			expect:      target{ID: 1, Count: 2, Name: "jon"},
+		},
+		{
+			name:        "nok, all sources collected",
+			givenMethod: http.MethodGet,
+			givenURL:    "/?count=x&date=2021&name=jon&unknown=1",
+			whenParamID: "nope",
+			expect:      target{Name: "jon"},
+			expectErrors: BindErrors{
+				{Source: "param", Field: "id", Value: "nope", Code: FieldErrorInvalidValue, Message: `strconv.ParseInt: parsing "nope": invalid syntax`},
+				{Source: "query", Field: "count", Value: "x", Code: FieldErrorInvalidValue, Message: `strconv.ParseInt: parsing "x": invalid syntax`},
+				{Source: "query", Field: "date", Value: "2021", Code: FieldErrorInvalidValue, Message: `parsing time "2021" as "2006-01-02": cannot parse "" as "-"`},
+				{Source: "query", Field: "unknown", Value: "1", Code: FieldErrorUnknownField, Message: "unknown field"},
+			},
+		},
+		{
+			name:             "nok, form",
+			givenMethod:      http.MethodPost,
+			givenURL:         "/",
+			givenContentType: MIMEApplicationForm,
+			givenContent:     "count=x&extra=a&extra=b",
+			whenParamID:      "1",
+			expect:           target{ID: 1},
+			expectErrors: BindErrors{
+				{Source: "form", Field: "count", Value: "x", Code: FieldErrorInvalidValue, Message: `strconv.ParseInt: parsing "x": invalid syntax`},
+				{Source: "form", Field: "extra", Value: "a,b", Code: FieldErrorUnknownField, Message: "unknown field"},
+			},
+		},
+		{
+			name:             "nok, json type error",
+			givenMethod:      http.MethodPost,
+			givenURL:         "/",
+			givenContentType: MIMEApplicationJSON,
+			givenContent:     `{"count":"1"}`,
+			whenParamID:      "1",
+			expect:           target{ID: 1},
+			expectErrors: BindErrors{
+				{Source: "json", Field: "count", Code: FieldErrorInvalidValue, Message: "json: cannot unmarshal string into Go struct field target.count of type int"},
+			},
+		},
+		{
+			name:             "nok, json unknown field",
+			givenMethod:      http.MethodPost,
+			givenURL:         "/",
+			givenContentType: MIMEApplicationJSON,
+			givenContent:     `{"name":"jon","age":30}`,
+			whenParamID:      "1",
+			expect:           target{ID: 1, Name: "jon"},
+			expectErrors: BindErrors{
+				{Source: "json", Field: "age", Code: FieldErrorUnknownField, Message: "unknown field"},
+			},
+		},
+		{
+			name:             "nok, json syntax error is not field error",
+			givenMethod:      http.MethodPost,
+			givenURL:         "/",
+			givenContentType: MIMEApplicationJSON,
+			givenContent:     `{"name":`,
+			whenParamID:      "1",
+			expect:           target{ID: 1},
+			expectError:      "code=400, message=unexpected EOF, internal=unexpected EOF",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			e.Binder = &DefaultBinder{Strict: true}
+			req := httptest.NewRequest(tc.givenMethod, tc.givenURL, strings.NewReader(tc.givenContent))
+			if tc.givenContentType != "" {
+				req.Header.Set(HeaderContentType, tc.givenContentType)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+			c.SetParamNames("id")
+			c.SetParamValues(tc.whenParamID)
+
+			result := target{}
+			err := c.Bind(&result)
+			assert.Equal(t, tc.expect, result)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			if tc.expectErrors == nil {
+				assert.NoError(t, err)
+				return
+			}
+			he, ok := err.(*HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, http.StatusUnprocessableEntity, he.Code)
+				assert.Equal(t, tc.expectErrors, he.Internal)
+			}
+		})
+	}
+}
+
+func TestBindErrors_Error(t *testing.T) {
+	errs := BindErrors{
+		{Source: "query", Field: "a", Message: "unknown field"},
+		{Source: "json", Field: "b", Message: "invalid"},
+	}
+	assert.Equal(t, "query field=a: unknown field; json field=b: invalid", errs.Error())
+}
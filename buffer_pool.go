@@ -0,0 +1,133 @@
+package echo
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// BufferPool is `sync.Pool` backed pool of byte buffers used for rendering responses (templates, feeds and JSON
+	// in zero allocation mode). Buffers are grouped into size classes by their capacity so small responses do not
+	// hold on to large buffers and large responses do not repeatedly grow small ones. Buffers that grew beyond
+	// the largest size class are not returned to the pool.
+	BufferPool struct {
+		// counters are first to keep them 64-bit aligned for atomic access on 32-bit platforms
+		gets  uint64
+		hits  uint64
+		puts  uint64
+		drops uint64
+		sizes []int
+		pools []sync.Pool
+	}
+
+	// BufferPoolStats contains usage counters of BufferPool.
+	BufferPoolStats struct {
+		// Gets is number of buffers taken from the pool.
+		Gets uint64 `json:"gets"`
+		// Hits is number of Gets served with pooled buffer. Rest of Gets allocated new buffer.
+		Hits uint64 `json:"hits"`
+		// Puts is number of buffers returned to the pool.
+		Puts uint64 `json:"puts"`
+		// Drops is number of returned buffers discarded for being smaller than the smallest or larger than the
+		// largest size class.
+		Drops uint64 `json:"drops"`
+	}
+)
+
+// DefaultBufferPoolSizes are size classes of buffer pool created by `NewBufferPool()` without sizes.
+var DefaultBufferPoolSizes = []int{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10}
+
+// defaultBufferPool is used when `Echo#BufferPool` is not set.
+var defaultBufferPool = NewBufferPool()
+
+func (e *Echo) bufferPool() *BufferPool {
+	if e.BufferPool == nil {
+		return defaultBufferPool
+	}
+	return e.BufferPool
+}
+
+// NewBufferPool returns buffer pool with given size classes (in bytes). Without sizes DefaultBufferPoolSizes are
+// used.
+func NewBufferPool(sizes ...int) *BufferPool {
+	if len(sizes) == 0 {
+		sizes = DefaultBufferPoolSizes
+	}
+	classes := make([]int, 0, len(sizes))
+	for _, s := range sizes {
+		if s > 0 {
+			classes = append(classes, s)
+		}
+	}
+	if len(classes) == 0 {
+		panic("echo: buffer pool requires at least one positive size class")
+	}
+	sort.Ints(classes)
+	return &BufferPool{
+		sizes: classes,
+		pools: make([]sync.Pool, len(classes)),
+	}
+}
+
+// Get returns empty buffer from the smallest size class.
+func (p *BufferPool) Get() *bytes.Buffer {
+	return p.GetSize(0)
+}
+
+// GetSize returns empty buffer with capacity of at least size bytes when size fits into largest size class.
+func (p *BufferPool) GetSize(size int) *bytes.Buffer {
+	atomic.AddUint64(&p.gets, 1)
+	class := sort.SearchInts(p.sizes, size)
+	if class == len(p.sizes) {
+		return bytes.NewBuffer(make([]byte, 0, size))
+	}
+	for i := class; i < len(p.pools); i++ {
+		if b, ok := p.pools[i].Get().(*bytes.Buffer); ok {
+			atomic.AddUint64(&p.hits, 1)
+			return b
+		}
+	}
+	return bytes.NewBuffer(make([]byte, 0, p.sizes[class]))
+}
+
+// Put resets buffer and returns it to the pool. Buffer must not be used after it is returned.
+func (p *BufferPool) Put(b *bytes.Buffer) {
+	if b == nil {
+		return
+	}
+	c := b.Cap()
+	// largest size class that buffer capacity can serve
+	class := sort.SearchInts(p.sizes, c+1) - 1
+	if class < 0 || c > p.sizes[len(p.sizes)-1] {
+		atomic.AddUint64(&p.drops, 1)
+		return
+	}
+	atomic.AddUint64(&p.puts, 1)
+	b.Reset()
+	p.pools[class].Put(b)
+}
+
+// Sizes returns size classes of the pool.
+func (p *BufferPool) Sizes() []int {
+	return append([]int(nil), p.sizes...)
+}
+
+// Stats returns usage counters of the pool.
+func (p *BufferPool) Stats() BufferPoolStats {
+	return BufferPoolStats{
+		Gets:  atomic.LoadUint64(&p.gets),
+		Hits:  atomic.LoadUint64(&p.hits),
+		Puts:  atomic.LoadUint64(&p.puts),
+		Drops: atomic.LoadUint64(&p.drops),
+	}
+}
+
+// HitRate returns ratio of Gets served from the pool. Returns 0 when pool has not been used.
+func (s BufferPoolStats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
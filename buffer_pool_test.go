@@ -0,0 +1,77 @@
+package echo
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBufferPool(t *testing.T) {
+	assert.Equal(t, DefaultBufferPoolSizes, NewBufferPool().Sizes())
+	assert.Equal(t, []int{16, 64}, NewBufferPool(64, 0, 16).Sizes())
+	assert.Panics(t, func() {
+		NewBufferPool(-1)
+	})
+}
+
+func TestBufferPool(t *testing.T) {
+	p := NewBufferPool(16, 64)
+
+	b := p.Get()
+	assert.Equal(t, 16, b.Cap())
+	b.WriteString("hello")
+	p.Put(b)
+
+	b = p.Get()
+	assert.Equal(t, 0, b.Len(), "returned buffer is reset")
+	assert.Equal(t, 16, b.Cap())
+
+	assert.Equal(t, 64, p.GetSize(32).Cap())
+	assert.Equal(t, 1000, p.GetSize(1000).Cap(), "larger than largest class is allocated")
+
+	p.Put(bytes.NewBuffer(make([]byte, 0, 8)))   // smaller than smallest class
+	p.Put(bytes.NewBuffer(make([]byte, 0, 128))) // larger than largest class
+	p.Put(bytes.NewBuffer(make([]byte, 0, 48)))  // serves only 16 byte class
+	p.Put(nil)
+
+	assert.True(t, p.GetSize(16).Cap() >= 16)
+
+	stats := p.Stats()
+	assert.Equal(t, uint64(5), stats.Gets)
+	assert.Equal(t, uint64(2), stats.Puts)
+	assert.Equal(t, uint64(2), stats.Drops)
+	assert.True(t, stats.Hits <= stats.Gets)
+}
+
+func TestBufferPoolStats_HitRate(t *testing.T) {
+	assert.Equal(t, float64(0), BufferPoolStats{}.HitRate())
+	assert.Equal(t, 0.75, BufferPoolStats{Gets: 4, Hits: 3}.HitRate())
+}
+
+type bufferPoolTemplate struct{}
+
+func (bufferPoolTemplate) Render(w io.Writer, name string, data interface{}, c Context) error {
+	_, err := io.WriteString(w, name+":"+data.(string))
+	return err
+}
+
+func TestContext_Render_usesBufferPool(t *testing.T) {
+	e := New()
+	e.Renderer = bufferPoolTemplate{}
+	e.BufferPool = NewBufferPool(1024)
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		assert.NoError(t, c.Render(http.StatusOK, "hello", "world"))
+		assert.Equal(t, "hello:world", rec.Body.String())
+	}
+
+	stats := e.BufferPool.Stats()
+	assert.Equal(t, uint64(2), stats.Gets)
+	assert.Equal(t, uint64(2), stats.Puts)
+}
@@ -0,0 +1,143 @@
+package echo
+
+import (
+	"errors"
+	"net/http"
+)
+
+type (
+	// BulkResult holds per-item results of bulk operation (i.e. creating or updating list of resources in one
+	// request) sent by `WriteBulk()`. Items are addressed by index of the item in the request so they can be
+	// processed concurrently, each item must be set once with `Succeed()` or `Fail()`.
+	//
+	// Example:
+	//
+	//	func createUsers(c echo.Context) error {
+	//		var users []User
+	//		if err := c.Bind(&users); err != nil {
+	//			return err
+	//		}
+	//		result := echo.NewBulkResult(len(users))
+	//		for i, u := range users {
+	//			if err := store.Create(&u); err != nil {
+	//				result.Fail(i, err)
+	//				continue
+	//			}
+	//			result.Succeed(i, http.StatusCreated, u)
+	//		}
+	//		return echo.WriteBulk(c, result)
+	//	}
+	BulkResult struct {
+		// Items are results of items in order of the request.
+		Items []BulkItem `json:"items"`
+		// Succeeded is number of items with 2xx status.
+		Succeeded int `json:"succeeded"`
+		// Failed is number of items with other status.
+		Failed int `json:"failed"`
+	}
+
+	// BulkItem is result of single item of bulk operation.
+	BulkItem struct {
+		// Index is index of the item in the request.
+		Index int `json:"index"`
+		// Status is HTTP status code of the item as if it was processed by single request.
+		Status int `json:"status"`
+		// Data is response of succeeded item.
+		Data interface{} `json:"data,omitempty"`
+		// Error is error response of failed item, serialized same way as by `Echo#DefaultHTTPErrorHandler`.
+		Error interface{} `json:"error,omitempty"`
+
+		err error
+	}
+)
+
+// errBulkItemNotProcessed is error of items not set with `BulkResult#Succeed()` or `BulkResult#Fail()`.
+var errBulkItemNotProcessed = NewHTTPError(http.StatusInternalServerError, "item was not processed")
+
+// NewBulkResult creates result of bulk operation with n items.
+func NewBulkResult(n int) *BulkResult {
+	r := &BulkResult{Items: make([]BulkItem, n)}
+	for i := range r.Items {
+		r.Items[i].Index = i
+	}
+	return r
+}
+
+// Succeed sets result of i-th item to success with status code and response data.
+func (r *BulkResult) Succeed(i int, code int, data interface{}) {
+	r.Items[i] = BulkItem{Index: i, Status: code, Data: data}
+}
+
+// Fail sets result of i-th item to failure. Status code and error response are taken from `*HTTPError`, other errors
+// are reported as 500 Internal Server Error.
+func (r *BulkResult) Fail(i int, err error) {
+	code, _ := bulkItemError(err, false)
+	r.Items[i] = BulkItem{Index: i, Status: code, err: err}
+}
+
+// Status returns status code of the response: status of items when all items have the same status, 207 Multi-Status
+// when they differ (partial failure) and 200 when there are no items.
+func (r *BulkResult) Status() int {
+	if len(r.Items) == 0 {
+		return http.StatusOK
+	}
+	status := r.Items[0].status()
+	for _, item := range r.Items[1:] {
+		if item.status() != status {
+			return http.StatusMultiStatus
+		}
+	}
+	return status
+}
+
+// status returns status of the item, 500 for items not processed.
+func (item *BulkItem) status() int {
+	if item.Status == 0 {
+		return errBulkItemNotProcessed.Code
+	}
+	return item.Status
+}
+
+// finish sets status and error response of failed items and counts.
+func (r *BulkResult) finish(debug bool) {
+	r.Succeeded, r.Failed = 0, 0
+	for i := range r.Items {
+		item := &r.Items[i]
+		if item.Status == 0 && item.err == nil {
+			item.err = errBulkItemNotProcessed
+		}
+		if item.err != nil {
+			item.Status, item.Error = bulkItemError(item.err, debug)
+		}
+		if item.Status >= 200 && item.Status < 300 {
+			r.Succeeded++
+		} else {
+			r.Failed++
+		}
+	}
+}
+
+func bulkItemError(err error, debug bool) (int, interface{}) {
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		he = &HTTPError{Code: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError)}
+	}
+	if herr, ok := he.Internal.(*HTTPError); ok {
+		he = herr
+	}
+	m, ok := he.Message.(string)
+	if !ok {
+		return he.Code, he.Message
+	}
+	if debug {
+		return he.Code, Map{"message": m, "error": err.Error()}
+	}
+	return he.Code, Map{"message": m}
+}
+
+// WriteBulk sends per-item results of bulk operation as JSON. Response status is status of items when all items
+// have the same status and 207 Multi-Status otherwise (partial failure), see `BulkResult#Status()`.
+func WriteBulk(c Context, r *BulkResult) error {
+	r.finish(c.Echo().Debug)
+	return c.JSON(r.Status(), r)
+}
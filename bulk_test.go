@@ -0,0 +1,91 @@
+package echo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Bulk(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		debug        bool
+		fill         func(r *BulkResult)
+		expectStatus int
+		expectBody   string
+	}{
+		{
+			name: "all created",
+			fill: func(r *BulkResult) {
+				r.Succeed(0, http.StatusCreated, Map{"id": 1})
+				r.Succeed(1, http.StatusCreated, Map{"id": 2})
+			},
+			expectStatus: http.StatusCreated,
+			expectBody: `{"items":[{"index":0,"status":201,"data":{"id":1}},{"index":1,"status":201,"data":{"id":2}}],` +
+				`"succeeded":2,"failed":0}`,
+		},
+		{
+			name: "partial failure",
+			fill: func(r *BulkResult) {
+				r.Succeed(0, http.StatusCreated, Map{"id": 1})
+				r.Fail(1, NewHTTPError(http.StatusConflict, "user exists"))
+			},
+			expectStatus: http.StatusMultiStatus,
+			expectBody: `{"items":[{"index":0,"status":201,"data":{"id":1}},` +
+				`{"index":1,"status":409,"error":{"message":"user exists"}}],"succeeded":1,"failed":1}`,
+		},
+		{
+			name: "all failed",
+			fill: func(r *BulkResult) {
+				r.Fail(0, ErrBadRequest)
+				r.Fail(1, NewHTTPError(http.StatusBadRequest, Map{"field": "name"}))
+			},
+			expectStatus: http.StatusBadRequest,
+			expectBody: `{"items":[{"index":0,"status":400,"error":{"message":"Bad Request"}},` +
+				`{"index":1,"status":400,"error":{"field":"name"}}],"succeeded":0,"failed":2}`,
+		},
+		{
+			name:  "internal error and not processed item",
+			debug: true,
+			fill: func(r *BulkResult) {
+				r.Fail(0, errors.New("db down"))
+			},
+			expectStatus: http.StatusInternalServerError,
+			expectBody: `{"items":[{"index":0,"status":500,"error":{"error":"db down","message":"Internal Server Error"}},` +
+				`{"index":1,"status":500,"error":{"error":"code=500, message=item was not processed","message":"item was not processed"}}],` +
+				`"succeeded":0,"failed":2}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			e.Debug = tc.debug
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodPost, "/users/bulk", nil), rec)
+
+			r := NewBulkResult(2)
+			tc.fill(r)
+			assert.NoError(t, WriteBulk(c, r))
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			assert.JSONEq(t, tc.expectBody, rec.Body.String())
+		})
+	}
+}
+
+func TestBulkResult_Status(t *testing.T) {
+	assert.Equal(t, http.StatusOK, NewBulkResult(0).Status())
+
+	r := NewBulkResult(2)
+	r.Succeed(0, http.StatusOK, nil)
+	r.Succeed(1, http.StatusCreated, nil)
+	assert.Equal(t, http.StatusMultiStatus, r.Status())
+
+	r = NewBulkResult(2)
+	r.Fail(0, errors.New("db down"))
+	assert.Equal(t, http.StatusInternalServerError, r.Status())
+	r.Fail(1, ErrNotFound)
+	assert.Equal(t, http.StatusMultiStatus, r.Status())
+}
@@ -0,0 +1,150 @@
+package echo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl is builder of `Cache-Control` header value. Builder returned by `CacheControlOf()` updates
+// the response header on every change. Builder created with `NewCacheControl()` is not bound to any response and
+// is used as cache policy (i.e. with `Route#CachePolicy()`) applied by `CacheControl#Apply()`.
+type CacheControl struct {
+	header     http.Header
+	directives []cacheDirective
+}
+
+type cacheDirective struct {
+	name  string
+	value string
+}
+
+// NewCacheControl creates new empty `Cache-Control` builder.
+func NewCacheControl() *CacheControl {
+	return &CacheControl{}
+}
+
+// CacheControlOf returns builder of the `Cache-Control` response header. Every call of builder method updates the
+// header so `echo.CacheControlOf(c).Public().MaxAge(5 * time.Minute)` replaces any previously set value.
+func CacheControlOf(c Context) *CacheControl {
+	return &CacheControl{header: c.Response().Header()}
+}
+
+// Public sets `public` directive and removes `private` directive.
+func (cc *CacheControl) Public() *CacheControl {
+	cc.remove("private")
+	return cc.set("public", "")
+}
+
+// Private sets `private` directive and removes `public` directive.
+func (cc *CacheControl) Private() *CacheControl {
+	cc.remove("public")
+	return cc.set("private", "")
+}
+
+// NoCache sets `no-cache` directive.
+func (cc *CacheControl) NoCache() *CacheControl {
+	return cc.set("no-cache", "")
+}
+
+// NoStore sets `no-store` directive.
+func (cc *CacheControl) NoStore() *CacheControl {
+	return cc.set("no-store", "")
+}
+
+// NoTransform sets `no-transform` directive.
+func (cc *CacheControl) NoTransform() *CacheControl {
+	return cc.set("no-transform", "")
+}
+
+// MustRevalidate sets `must-revalidate` directive.
+func (cc *CacheControl) MustRevalidate() *CacheControl {
+	return cc.set("must-revalidate", "")
+}
+
+// ProxyRevalidate sets `proxy-revalidate` directive.
+func (cc *CacheControl) ProxyRevalidate() *CacheControl {
+	return cc.set("proxy-revalidate", "")
+}
+
+// Immutable sets `immutable` directive.
+func (cc *CacheControl) Immutable() *CacheControl {
+	return cc.set("immutable", "")
+}
+
+// MaxAge sets `max-age` directive. Duration is truncated to seconds.
+func (cc *CacheControl) MaxAge(d time.Duration) *CacheControl {
+	return cc.set("max-age", durationSeconds(d))
+}
+
+// SMaxAge sets `s-maxage` directive used by shared caches. Duration is truncated to seconds.
+func (cc *CacheControl) SMaxAge(d time.Duration) *CacheControl {
+	return cc.set("s-maxage", durationSeconds(d))
+}
+
+// StaleWhileRevalidate sets `stale-while-revalidate` directive. Duration is truncated to seconds.
+func (cc *CacheControl) StaleWhileRevalidate(d time.Duration) *CacheControl {
+	return cc.set("stale-while-revalidate", durationSeconds(d))
+}
+
+// StaleIfError sets `stale-if-error` directive. Duration is truncated to seconds.
+func (cc *CacheControl) StaleIfError(d time.Duration) *CacheControl {
+	return cc.set("stale-if-error", durationSeconds(d))
+}
+
+// String returns `Cache-Control` header value. Directives are in order they were first set.
+func (cc *CacheControl) String() string {
+	parts := make([]string, len(cc.directives))
+	for i, d := range cc.directives {
+		if d.value == "" {
+			parts[i] = d.name
+		} else {
+			parts[i] = d.name + "=" + d.value
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Apply sets `Cache-Control` header to the header value built by cc. Header is not changed when no directive is
+// set.
+func (cc *CacheControl) Apply(h http.Header) {
+	if len(cc.directives) == 0 {
+		return
+	}
+	h.Set(HeaderCacheControl, cc.String())
+}
+
+func (cc *CacheControl) set(name, value string) *CacheControl {
+	found := false
+	for i := range cc.directives {
+		if cc.directives[i].name == name {
+			cc.directives[i].value = value
+			found = true
+			break
+		}
+	}
+	if !found {
+		cc.directives = append(cc.directives, cacheDirective{name: name, value: value})
+	}
+	if cc.header != nil {
+		cc.Apply(cc.header)
+	}
+	return cc
+}
+
+func (cc *CacheControl) remove(name string) {
+	for i := range cc.directives {
+		if cc.directives[i].name == name {
+			cc.directives = append(cc.directives[:i], cc.directives[i+1:]...)
+			return
+		}
+	}
+}
+
+func durationSeconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
@@ -0,0 +1,68 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheControl(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		build  func(cc *CacheControl)
+		expect string
+	}{
+		{
+			name:   "empty",
+			build:  func(cc *CacheControl) {},
+			expect: "",
+		},
+		{
+			name: "public with max age and stale while revalidate",
+			build: func(cc *CacheControl) {
+				cc.Public().MaxAge(5 * time.Minute).StaleWhileRevalidate(30 * time.Second)
+			},
+			expect: "public, max-age=300, stale-while-revalidate=30",
+		},
+		{
+			name: "private replaces public, repeated directive is updated",
+			build: func(cc *CacheControl) {
+				cc.Public().MaxAge(time.Minute).Private().MaxAge(1500 * time.Millisecond)
+			},
+			expect: "max-age=1, private",
+		},
+		{
+			name: "all flags",
+			build: func(cc *CacheControl) {
+				cc.NoCache().NoStore().NoTransform().MustRevalidate().ProxyRevalidate().Immutable().
+					SMaxAge(time.Hour).StaleIfError(-time.Second)
+			},
+			expect: "no-cache, no-store, no-transform, must-revalidate, proxy-revalidate, immutable, s-maxage=3600, stale-if-error=0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cc := NewCacheControl()
+			tc.build(cc)
+			assert.Equal(t, tc.expect, cc.String())
+
+			h := http.Header{}
+			cc.Apply(h)
+			assert.Equal(t, tc.expect, h.Get(HeaderCacheControl))
+		})
+	}
+}
+
+func TestContext_CacheControl(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	c.Response().Header().Set(HeaderCacheControl, "no-store")
+
+	CacheControlOf(c).Public().MaxAge(5 * time.Minute)
+	assert.Equal(t, "public, max-age=300", rec.Header().Get(HeaderCacheControl))
+}
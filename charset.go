@@ -0,0 +1,175 @@
+package echo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type (
+	// CharsetDecoder returns reader decoding content in a charset to UTF-8.
+	CharsetDecoder func(r io.Reader) io.Reader
+
+	// decodedBody is request body decoded to UTF-8 that closes the original body.
+	decodedBody struct {
+		io.Reader
+		io.Closer
+	}
+
+	latin1Reader struct {
+		r       io.Reader
+		buf     []byte
+		pending []byte
+	}
+
+	utf16Reader struct {
+		r         *bufio.Reader
+		bigEndian bool
+		bom       bool
+		pending   []byte
+	}
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// charsetDecoders are built in decoders of request body charsets. Nil decoder means content is already UTF-8.
+var charsetDecoders = map[string]CharsetDecoder{
+	"utf-8":      nil,
+	"utf8":       nil,
+	"us-ascii":   nil,
+	"iso-8859-1": Latin1Decoder,
+	"latin1":     Latin1Decoder,
+	"utf-16":     UTF16Decoder(true, true),
+	"utf-16be":   UTF16Decoder(true, false),
+	"utf-16le":   UTF16Decoder(false, false),
+}
+
+// Latin1Decoder decodes ISO-8859-1 content to UTF-8.
+func Latin1Decoder(r io.Reader) io.Reader {
+	return &latin1Reader{r: r}
+}
+
+// UTF16Decoder returns decoder of UTF-16 content with given byte order. With bom, byte order mark at the start of
+// the content takes precedence over the byte order and is removed.
+func UTF16Decoder(bigEndian, bom bool) CharsetDecoder {
+	return func(r io.Reader) io.Reader {
+		return &utf16Reader{r: bufio.NewReader(r), bigEndian: bigEndian, bom: bom}
+	}
+}
+
+// decodeBody replaces request body with reader decoding its charset (`charset` parameter of `Content-Type`) to UTF-8
+// without byte order mark. Unknown charsets are rejected with 415 Unsupported Media Type.
+func (b *DefaultBinder) decodeBody(req *http.Request) error {
+	charset := ""
+	if _, params, err := mime.ParseMediaType(req.Header.Get(HeaderContentType)); err == nil {
+		charset = strings.ToLower(params["charset"])
+	}
+	var decoder CharsetDecoder
+	if charset != "" {
+		var ok bool
+		if decoder, ok = b.Charsets[charset]; !ok {
+			if decoder, ok = charsetDecoders[charset]; !ok {
+				return NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported charset %q", charset))
+			}
+		}
+	}
+	var r io.Reader = req.Body
+	if decoder != nil {
+		r = decoder(r)
+	}
+	br := bufio.NewReader(r)
+	if prefix, _ := br.Peek(len(utf8BOM)); bytes.Equal(prefix, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	req.Body = decodedBody{Reader: br, Closer: req.Body}
+	return nil
+}
+
+func (r *latin1Reader) Read(p []byte) (int, error) {
+	if len(r.pending) > 0 {
+		n := copy(p, r.pending)
+		r.pending = r.pending[n:]
+		return n, nil
+	}
+	// every byte is encoded to at most 2 bytes
+	n := (len(p) + 1) / 2
+	if cap(r.buf) < n {
+		r.buf = make([]byte, n)
+	}
+	n, err := r.r.Read(r.buf[:n])
+	w := 0
+	var enc [2]byte
+	for _, c := range r.buf[:n] {
+		size := utf8.EncodeRune(enc[:], rune(c))
+		copied := copy(p[w:], enc[:size])
+		w += copied
+		if copied < size {
+			// only the last character may not fit
+			r.pending = append(r.pending[:0], enc[copied:size]...)
+		}
+	}
+	if len(r.pending) > 0 && err == io.EOF {
+		// EOF is returned by next read after pending bytes
+		err = nil
+	}
+	return w, err
+}
+
+func (r *utf16Reader) Read(p []byte) (int, error) {
+	if r.bom {
+		r.bom = false
+		if prefix, _ := r.r.Peek(2); len(prefix) == 2 {
+			switch {
+			case prefix[0] == 0xFE && prefix[1] == 0xFF:
+				r.bigEndian = true
+				r.r.Discard(2)
+			case prefix[0] == 0xFF && prefix[1] == 0xFE:
+				r.bigEndian = false
+				r.r.Discard(2)
+			}
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	var buf [utf8.UTFMax]byte
+	for n < len(p) {
+		c, err := r.unit()
+		if err != nil {
+			return n, err
+		}
+		ch := rune(c)
+		if utf16.IsSurrogate(ch) {
+			c2, err := r.unit()
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return n, err
+			}
+			ch = utf16.DecodeRune(ch, rune(c2))
+		}
+		size := utf8.EncodeRune(buf[:], ch)
+		copied := copy(p[n:], buf[:size])
+		r.pending = append(r.pending[:0], buf[copied:size]...)
+		n += copied
+	}
+	return n, nil
+}
+
+// unit reads next UTF-16 code unit.
+func (r *utf16Reader) unit() (uint16, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(r.r, b[:]); err != nil {
+		return 0, err
+	}
+	if r.bigEndian {
+		return uint16(b[0])<<8 | uint16(b[1]), nil
+	}
+	return uint16(b[1])<<8 | uint16(b[0]), nil
+}
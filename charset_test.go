@@ -0,0 +1,138 @@
+package echo
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultBinder_BindBodyCharset(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		contentType string
+		body        string
+		binder      *DefaultBinder
+		expectName  string
+		expectCode  int
+	}{
+		{
+			name:        "ok, UTF-8 with BOM",
+			contentType: MIMEApplicationJSON,
+			body:        "\xEF\xBB\xBF" + `{"name":"Zoë"}`,
+			expectName:  "Zoë",
+		},
+		{
+			name:        "ok, UTF-8 with BOM in strict mode",
+			contentType: MIMEApplicationJSONCharsetUTF8,
+			body:        "\xEF\xBB\xBF" + `{"name":"Zoë"}`,
+			binder:      &DefaultBinder{Strict: true},
+			expectName:  "Zoë",
+		},
+		{
+			name:        "ok, latin-1",
+			contentType: MIMEApplicationJSON + "; charset=ISO-8859-1",
+			body:        "{\"name\":\"Zo\xEB\"}",
+			expectName:  "Zoë",
+		},
+		{
+			name:        "ok, UTF-16 with little endian BOM",
+			contentType: MIMEApplicationJSON + "; charset=utf-16",
+			body:        "\xFF\xFE" + utf16LE(`{"name":"Zoë 😀"}`),
+			expectName:  "Zoë 😀",
+		},
+		{
+			name:        "ok, UTF-16BE",
+			contentType: MIMEApplicationJSON + "; charset=UTF-16BE",
+			body:        utf16BE(`{"name":"Zoë"}`),
+			expectName:  "Zoë",
+		},
+		{
+			name:        "ok, custom decoder",
+			contentType: MIMEApplicationJSON + "; charset=x-upper",
+			body:        `{"name":"zoe"}`,
+			binder: &DefaultBinder{Charsets: map[string]CharsetDecoder{
+				"x-upper": func(r io.Reader) io.Reader {
+					b, _ := ioutil.ReadAll(r)
+					return strings.NewReader(strings.Replace(string(b), "zoe", "ZOE", 1))
+				},
+			}},
+			expectName: "ZOE",
+		},
+		{
+			name:        "nok, unsupported charset",
+			contentType: MIMEApplicationJSON + "; charset=koi8-r",
+			body:        `{"name":"Zoë"}`,
+			expectCode:  http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			req.Header.Set(HeaderContentType, tc.contentType)
+			c := e.NewContext(req, httptest.NewRecorder())
+			b := tc.binder
+			if b == nil {
+				b = &DefaultBinder{}
+			}
+
+			var target struct {
+				Name string `json:"name"`
+			}
+			err := b.BindBody(c, &target)
+			if tc.expectCode != 0 {
+				if assert.IsType(t, &HTTPError{}, err) {
+					assert.Equal(t, tc.expectCode, err.(*HTTPError).Code)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectName, target.Name)
+		})
+	}
+}
+
+func TestLatin1Decoder(t *testing.T) {
+	b, err := ioutil.ReadAll(iotest.OneByteReader(Latin1Decoder(strings.NewReader("Gr\xFC\xDFe"))))
+	assert.NoError(t, err)
+	assert.Equal(t, "Grüße", string(b))
+}
+
+func TestUTF16Decoder(t *testing.T) {
+	// small reads split multi-byte characters
+	r := iotest.OneByteReader(UTF16Decoder(true, true)(strings.NewReader("\xFE\xFF" + utf16BE("añ😀"))))
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "añ😀", string(b))
+
+	_, err = ioutil.ReadAll(UTF16Decoder(true, false)(strings.NewReader("\x00a\x00")))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func utf16BE(s string) string {
+	var b []byte
+	for _, r := range s {
+		if r >= 0x10000 {
+			r -= 0x10000
+			hi, lo := 0xD800+(r>>10), 0xDC00+(r&0x3FF)
+			b = append(b, byte(hi>>8), byte(hi), byte(lo>>8), byte(lo))
+			continue
+		}
+		b = append(b, byte(r>>8), byte(r))
+	}
+	return string(b)
+}
+
+func utf16LE(s string) string {
+	b := []byte(utf16BE(s))
+	for i := 0; i+1 < len(b); i += 2 {
+		b[i], b[i+1] = b[i+1], b[i]
+	}
+	return string(b)
+}
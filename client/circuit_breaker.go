@@ -0,0 +1,106 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// CircuitBreakerConfig defines circuit breaker of the client. Circuit is tracked separately for every host.
+	// After FailureThreshold consecutive failures circuit opens and requests to the host fail with ErrCircuitOpen
+	// without being sent. After OpenTimeout single trial request is let through, success closes the circuit,
+	// failure opens it again.
+	CircuitBreakerConfig struct {
+		// FailureThreshold is number of consecutive failures that opens the circuit.
+		// Optional. Default value 5.
+		FailureThreshold int
+
+		// OpenTimeout is how long circuit stays open before trial request is sent.
+		// Optional. Default value 30s.
+		OpenTimeout time.Duration
+
+		// IsFailure decides if request failed.
+		// Optional. Default value treats transport errors and 5xx responses as failures.
+		IsFailure func(res *http.Response, err error) bool
+	}
+
+	breakerTransport struct {
+		next     http.RoundTripper
+		config   CircuitBreakerConfig
+		mutex    sync.Mutex
+		breakers map[string]*breaker
+	}
+
+	breaker struct {
+		failures int
+		openedAt time.Time
+		trial    bool
+	}
+)
+
+// ErrCircuitOpen is returned for requests to host with open circuit.
+var ErrCircuitOpen = errors.New("client: circuit breaker is open")
+
+func (config CircuitBreakerConfig) withDefaults() CircuitBreakerConfig {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(res *http.Response, err error) bool {
+			return err != nil || res.StatusCode >= http.StatusInternalServerError
+		}
+	}
+	return config
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if !t.allow(host, time.Now()) {
+		return nil, ErrCircuitOpen
+	}
+	res, err := t.next.RoundTrip(req)
+	t.done(host, t.config.IsFailure(res, err), time.Now())
+	return res, err
+}
+
+// allow reports whether request to host can be sent.
+func (t *breakerTransport) allow(host string, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	b, ok := t.breakers[host]
+	if !ok || b.failures < t.config.FailureThreshold {
+		return true
+	}
+	// circuit is open, let single trial request through after timeout
+	if b.trial || now.Sub(b.openedAt) < t.config.OpenTimeout {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (t *breakerTransport) done(host string, failed bool, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	b, ok := t.breakers[host]
+	if !failed {
+		if ok {
+			delete(t.breakers, host)
+		}
+		return
+	}
+	if !ok {
+		b = &breaker{}
+		t.breakers[host] = b
+	}
+	b.trial = false
+	b.failures++
+	if b.failures >= t.config.FailureThreshold {
+		b.openedAt = now
+	}
+}
@@ -0,0 +1,307 @@
+/*
+Package client creates outbound HTTP clients with the same concerns as Echo server middlewares: request ID and trace
+context propagation, retries with backoff, circuit breaking and metrics.
+
+Example:
+
+	httpClient := client.New(client.Config{
+		Timeout:        5 * time.Second,
+		Retry:          client.RetryConfig{MaxAttempts: 3},
+		CircuitBreaker: &client.CircuitBreakerConfig{FailureThreshold: 5, OpenTimeout: 10 * time.Second},
+		Metrics: func(m client.Metric) {
+			log.Printf("%s %s status=%d attempts=%d latency=%v", m.Method, m.Host, m.Status, m.Attempts, m.Latency)
+		},
+	})
+
+	e.GET("/users/:id", func(c echo.Context) error {
+		// request ID and trace headers of incoming request are sent to upstream
+		req, err := http.NewRequestWithContext(client.FromContext(c), http.MethodGet, "http://users/"+c.Param("id"), nil)
+		if err != nil {
+			return err
+		}
+		res, err := httpClient.Do(req)
+		...
+	})
+*/
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Config defines the config for outbound HTTP client.
+	Config struct {
+		// Timeout is total time limit of request including retries.
+		// Optional. Default value 0 (no timeout).
+		Timeout time.Duration
+
+		// Transport is used to send requests.
+		// Optional. Default value http.DefaultTransport.
+		Transport http.RoundTripper
+
+		// Retry defines retries of failed requests.
+		// Optional. Default value is no retries.
+		Retry RetryConfig
+
+		// CircuitBreaker stops sending requests to host after consecutive failures.
+		// Optional. Default value nil (disabled).
+		CircuitBreaker *CircuitBreakerConfig
+
+		// Metrics is called after every request (after all attempts).
+		// Optional.
+		Metrics func(m Metric)
+
+		// PropagateHeaders are headers copied from context created by FromContext to outgoing requests.
+		// Optional. Default value DefaultPropagateHeaders.
+		PropagateHeaders []string
+	}
+
+	// RetryConfig defines retries of failed requests. Requests are retried only when their body can be sent again
+	// (body is nil or `Request.GetBody` is set).
+	RetryConfig struct {
+		// MaxAttempts is maximum number of attempts including the first one.
+		// Optional. Default value 1 (no retries).
+		MaxAttempts int
+
+		// Backoff is delay before the first retry, delay is doubled with every next retry. Random jitter (up to
+		// the delay) is added to spread retries of multiple clients.
+		// Optional. Default value 100ms.
+		Backoff time.Duration
+
+		// MaxBackoff caps delay between retries.
+		// Optional. Default value 2s.
+		MaxBackoff time.Duration
+
+		// Methods are methods of requests that can be retried.
+		// Optional. Default value idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE).
+		Methods []string
+
+		// RetryIf decides if attempt is retried.
+		// Optional. Default value retries on transport errors and 502, 503 and 504 responses.
+		RetryIf func(res *http.Response, err error) bool
+	}
+
+	// Metric contains result of request sent by the client.
+	Metric struct {
+		Method   string
+		Host     string
+		Path     string
+		Status   int
+		Attempts int
+		Latency  time.Duration
+		Error    error
+	}
+
+	propagationContextKey struct{}
+
+	propagationTransport struct {
+		next    http.RoundTripper
+		headers []string
+	}
+
+	metricsTransport struct {
+		next    http.RoundTripper
+		metrics func(m Metric)
+	}
+
+	retryTransport struct {
+		next   http.RoundTripper
+		config RetryConfig
+		rnd    *rand.Rand
+		mutex  sync.Mutex
+	}
+
+	attemptsContextKey struct{}
+)
+
+// DefaultPropagateHeaders are headers of incoming request sent with outgoing requests. Request ID is taken from
+// response header when it was generated by RequestID middleware.
+var DefaultPropagateHeaders = []string{echo.HeaderXRequestID, "Traceparent", "Tracestate"}
+
+// New returns HTTP client configured with config.
+func New(config Config) *http.Client {
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: NewTransport(config),
+	}
+}
+
+// NewTransport returns round tripper configured with config. Can be used to wrap transport of existing client.
+func NewTransport(config Config) http.RoundTripper {
+	t := config.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	if config.CircuitBreaker != nil {
+		t = &breakerTransport{next: t, config: config.CircuitBreaker.withDefaults(), breakers: map[string]*breaker{}}
+	}
+	if config.Retry.MaxAttempts > 1 {
+		t = &retryTransport{next: t, config: config.Retry.withDefaults(), rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	}
+	if config.Metrics != nil {
+		t = &metricsTransport{next: t, metrics: config.Metrics}
+	}
+	headers := config.PropagateHeaders
+	if len(headers) == 0 {
+		headers = DefaultPropagateHeaders
+	}
+	return &propagationTransport{next: t, headers: headers}
+}
+
+// FromContext returns context of the request handled by Echo with values of propagated headers. Requests created
+// with returned context send these headers to upstream and are canceled when incoming request is canceled.
+func FromContext(c echo.Context) context.Context {
+	req := c.Request()
+	values := req.Header.Clone()
+	if values == nil {
+		values = http.Header{}
+	}
+	for _, name := range DefaultPropagateHeaders {
+		if values.Get(name) != "" {
+			continue
+		}
+		if v := c.Response().Header().Get(name); v != "" {
+			values.Set(name, v)
+		}
+	}
+	return context.WithValue(req.Context(), propagationContextKey{}, values)
+}
+
+func (t *propagationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	values, ok := req.Context().Value(propagationContextKey{}).(http.Header)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+	var header http.Header
+	for _, name := range t.headers {
+		v := values.Get(name)
+		if v == "" || req.Header.Get(name) != "" {
+			continue
+		}
+		if header == nil {
+			// RoundTripper must not modify the request
+			req = req.Clone(req.Context())
+			header = req.Header
+		}
+		header.Set(name, v)
+	}
+	return t.next.RoundTrip(req)
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := new(int)
+	start := time.Now()
+	res, err := t.next.RoundTrip(req.WithContext(context.WithValue(req.Context(), attemptsContextKey{}, attempts)))
+	m := Metric{
+		Method:   req.Method,
+		Host:     req.URL.Host,
+		Path:     req.URL.Path,
+		Attempts: *attempts,
+		Latency:  time.Since(start),
+		Error:    err,
+	}
+	if m.Attempts == 0 {
+		m.Attempts = 1
+	}
+	if res != nil {
+		m.Status = res.StatusCode
+	}
+	t.metrics(m)
+	return res, err
+}
+
+func (config RetryConfig) withDefaults() RetryConfig {
+	if config.Backoff <= 0 {
+		config.Backoff = 100 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 2 * time.Second
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete}
+	}
+	if config.RetryIf == nil {
+		config.RetryIf = defaultRetryIf
+	}
+	return config
+}
+
+func defaultRetryIf(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrCircuitOpen)
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (t *retryTransport) retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	for _, m := range t.config.Methods {
+		if m == req.Method {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts, _ := req.Context().Value(attemptsContextKey{}).(*int)
+	if attempts == nil {
+		attempts = new(int)
+	}
+	if !t.retryable(req) {
+		*attempts = 1
+		return t.next.RoundTrip(req)
+	}
+
+	backoff := t.config.Backoff
+	for {
+		*attempts++
+		res, err := t.next.RoundTrip(req)
+		if *attempts >= t.config.MaxAttempts || !t.config.RetryIf(res, err) {
+			return res, err
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff + t.jitter(backoff))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > t.config.MaxBackoff {
+			backoff = t.config.MaxBackoff
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func (t *retryTransport) jitter(d time.Duration) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return time.Duration(t.rnd.Int63n(int64(d) + 1))
+}
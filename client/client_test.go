@@ -0,0 +1,156 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get(echo.HeaderXRequestID) + "|" + r.Header.Get("Traceparent") + "|" + r.Header.Get("Cookie")))
+	}))
+	defer upstream.Close()
+	httpClient := New(Config{})
+
+	e := echo.New()
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{Generator: func() string { return "generated" }}))
+	e.GET("/", func(c echo.Context) error {
+		req, err := http.NewRequestWithContext(FromContext(c), http.MethodGet, upstream.URL, nil)
+		if err != nil {
+			return err
+		}
+		res, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		return c.Stream(http.StatusOK, echo.MIMETextPlain, res.Body)
+	})
+
+	var testCases = []struct {
+		name       string
+		header     map[string]string
+		expectBody string
+	}{
+		{
+			name:       "ok, request ID generated by middleware",
+			header:     map[string]string{"Traceparent": "00-trace-span-01", "Cookie": "secret=1"},
+			expectBody: "generated|00-trace-span-01|",
+		},
+		{
+			name:       "ok, request ID of incoming request",
+			header:     map[string]string{echo.HeaderXRequestID: "incoming"},
+			expectBody: "incoming||",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+		})
+	}
+}
+
+func TestNew_retry(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	var metrics []Metric
+	httpClient := New(Config{
+		Retry:   RetryConfig{MaxAttempts: 3, Backoff: time.Millisecond},
+		Metrics: func(m Metric) { metrics = append(metrics, m) },
+	})
+
+	res, err := httpClient.Get(upstream.URL + "/users")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// request with body that can be sent again is retried
+	atomic.StoreInt32(&calls, 0)
+	req, _ := http.NewRequest(http.MethodPut, upstream.URL, strings.NewReader("data"))
+	res, err = httpClient.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// non idempotent methods are not retried
+	atomic.StoreInt32(&calls, 0)
+	res, err = httpClient.Post(upstream.URL, echo.MIMETextPlain, strings.NewReader("data"))
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+	require.Len(t, metrics, 3)
+	assert.Equal(t, "/users", metrics[0].Path)
+	assert.Equal(t, http.StatusOK, metrics[0].Status)
+	assert.Equal(t, 3, metrics[0].Attempts)
+	assert.Equal(t, 3, metrics[1].Attempts)
+	assert.Equal(t, 1, metrics[2].Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, metrics[2].Status)
+}
+
+func TestNew_circuitBreaker(t *testing.T) {
+	var failing int32 = 1
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer upstream.Close()
+
+	httpClient := New(Config{CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond}})
+	get := func() error {
+		res, err := httpClient.Get(upstream.URL)
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	assert.NoError(t, get())
+	assert.NoError(t, get())
+	err := get()
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// trial request after timeout fails and opens circuit again
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, get())
+	assert.True(t, errors.Is(get(), ErrCircuitOpen))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// successful trial request closes circuit
+	atomic.StoreInt32(&failing, 0)
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, get())
+	assert.NoError(t, get())
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+}
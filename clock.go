@@ -0,0 +1,41 @@
+package echo
+
+import "time"
+
+type (
+	// Clock is the source of current time used by Echo and its middlewares. Replace `Echo#Clock` with a fake
+	// implementation to test time dependent code deterministically.
+	Clock interface {
+		Now() time.Time
+	}
+
+	systemClock struct{}
+)
+
+// SystemClock is the Clock returning current system time. It is the default `Echo#Clock`.
+var SystemClock Clock = systemClock{}
+
+// Now returns current system time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Now returns current time from `Echo#Clock` of the Echo instance handling the request. SystemClock is used when
+// context is nil or Echo has no clock. Middlewares use it so tests can control time with a fake `Echo#Clock`.
+func Now(c Context) time.Time {
+	if c != nil {
+		if e := c.Echo(); e != nil {
+			return e.Now()
+		}
+	}
+	return SystemClock.Now()
+}
+
+// Now returns current time from `Echo#Clock` or from SystemClock when it is not set. It is meant for code running
+// outside of requests, i.e. background workers started with `Echo#OnStart()`.
+func (e *Echo) Now() time.Time {
+	if e.Clock == nil {
+		return SystemClock.Now()
+	}
+	return e.Clock.Now()
+}
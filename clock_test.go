@@ -0,0 +1,24 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNow(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := New()
+	e.Clock = &testClock{now: now}
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	assert.Equal(t, now, Now(c))
+	assert.Equal(t, now, e.Now())
+
+	e.Clock = nil
+	assert.WithinDuration(t, time.Now(), Now(c), time.Minute)
+	assert.WithinDuration(t, time.Now(), Now(nil), time.Minute)
+}
@@ -0,0 +1,97 @@
+/*
+Package cloudrun adapts Echo applications to Google Cloud Run and Cloud Functions: server listens on port set by the
+platform and shuts down gracefully on SIGTERM, logs are written as structured JSON understood by Cloud Logging and
+log entries of a request are correlated with its Cloud Trace trace.
+
+Example (Cloud Run):
+
+	func main() {
+		e := echo.New()
+		e.Logger = cloudrun.NewLogger(os.Stdout)
+		e.Use(cloudrun.Middleware(cloudrun.Config{AccessLog: true}))
+		e.GET("/", func(c echo.Context) error {
+			c.Logger().Info("handling request") // correlated with the request trace
+			return c.String(http.StatusOK, "Hello")
+		})
+		if err := cloudrun.Start(e); err != nil {
+			e.Logger.Fatal(err)
+		}
+	}
+
+Example (Cloud Functions):
+
+	var handler = cloudrun.Function(newServer())
+
+	// Handle is entry point of the function.
+	func Handle(w http.ResponseWriter, r *http.Request) {
+		handler(w, r)
+	}
+*/
+package cloudrun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ShutdownTimeout is time given to in-flight requests to finish after SIGTERM. Cloud Run kills the instance 10
+// seconds after SIGTERM.
+const ShutdownTimeout = 9 * time.Second
+
+// Address returns listen address of the server: port from `PORT` environment variable set by the platform or 8080.
+func Address() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
+}
+
+// ProjectID returns ID of Google Cloud project from `GOOGLE_CLOUD_PROJECT` (or `GCP_PROJECT` set in older
+// Cloud Functions runtimes) environment variable.
+func ProjectID() string {
+	if id := os.Getenv("GOOGLE_CLOUD_PROJECT"); id != "" {
+		return id
+	}
+	return os.Getenv("GCP_PROJECT")
+}
+
+// Start starts the server on `Address()` and blocks until SIGTERM or SIGINT is received, then shuts the server
+// down gracefully within `ShutdownTimeout`.
+func Start(e *echo.Echo) error {
+	e.HideBanner = true
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.Start(Address())
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(quit)
+	select {
+	case err := <-errs:
+		return err
+	case <-quit:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Function returns HTTP function serving requests with Echo, for Cloud Functions entry point.
+func Function(e *echo.Echo) func(http.ResponseWriter, *http.Request) {
+	return e.ServeHTTP
+}
@@ -0,0 +1,49 @@
+package cloudrun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func setEnv(t *testing.T, key, value string) {
+	old, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestAddress(t *testing.T) {
+	setEnv(t, "PORT", "")
+	assert.Equal(t, ":8080", Address())
+	setEnv(t, "PORT", "9000")
+	assert.Equal(t, ":9000", Address())
+}
+
+func TestProjectID(t *testing.T) {
+	setEnv(t, "GOOGLE_CLOUD_PROJECT", "")
+	setEnv(t, "GCP_PROJECT", "legacy")
+	assert.Equal(t, "legacy", ProjectID())
+	setEnv(t, "GOOGLE_CLOUD_PROJECT", "my-project")
+	assert.Equal(t, "my-project", ProjectID())
+}
+
+func TestFunction(t *testing.T) {
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "hello")
+	})
+
+	rec := httptest.NewRecorder()
+	Function(e)(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "hello", rec.Body.String())
+}
@@ -0,0 +1,216 @@
+package cloudrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// Logger is `echo.Logger` writing entries as single line JSON objects in Cloud Logging structured logging format
+// (`severity`, `message` and special fields like `logging.googleapis.com/trace`). Cloud Run and Cloud Functions
+// collect them from stdout and stderr.
+type Logger struct {
+	mu     *sync.Mutex
+	out    *io.Writer
+	level  *log.Lvl
+	prefix string
+	fields log.JSON
+}
+
+// Special fields of Cloud Logging structured log entries.
+const (
+	FieldTrace        = "logging.googleapis.com/trace"
+	FieldSpanID       = "logging.googleapis.com/spanId"
+	FieldTraceSampled = "logging.googleapis.com/trace_sampled"
+	FieldHTTPRequest  = "httpRequest"
+)
+
+var _ echo.Logger = (*Logger)(nil)
+
+// NewLogger creates logger writing to w with INFO level.
+func NewLogger(w io.Writer) *Logger {
+	level := log.INFO
+	return &Logger{mu: new(sync.Mutex), out: &w, level: &level}
+}
+
+// With returns logger adding fields to every entry. Returned logger shares output and level with l.
+func (l *Logger) With(fields log.JSON) *Logger {
+	merged := make(log.JSON, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	child := *l
+	child.fields = merged
+	return &child
+}
+
+// WithTrace returns logger correlating entries with the trace of Google Cloud project.
+func (l *Logger) WithTrace(projectID string, t Trace) *Logger {
+	fields := log.JSON{FieldTrace: "projects/" + projectID + "/traces/" + t.TraceID, FieldTraceSampled: t.Sampled}
+	if t.SpanID != "" {
+		fields[FieldSpanID] = t.SpanID
+	}
+	return l.With(fields)
+}
+
+func (l *Logger) Output() io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return *l.out
+}
+
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.out = w
+}
+
+func (l *Logger) Prefix() string {
+	return l.prefix
+}
+
+// SetPrefix sets prefix of messages.
+func (l *Logger) SetPrefix(p string) {
+	l.prefix = p
+}
+
+func (l *Logger) Level() log.Lvl {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return *l.level
+}
+
+func (l *Logger) SetLevel(v log.Lvl) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.level = v
+}
+
+// SetHeader is no-op, format of entries is fixed.
+func (l *Logger) SetHeader(h string) {}
+
+func (l *Logger) Print(i ...interface{}) {
+	l.log(0, "DEFAULT", fmt.Sprint(i...), nil)
+}
+
+func (l *Logger) Printf(format string, args ...interface{}) {
+	l.log(0, "DEFAULT", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Printj(j log.JSON) {
+	l.log(0, "DEFAULT", "", j)
+}
+
+func (l *Logger) Debug(i ...interface{}) {
+	l.log(log.DEBUG, "DEBUG", fmt.Sprint(i...), nil)
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log(log.DEBUG, "DEBUG", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Debugj(j log.JSON) {
+	l.log(log.DEBUG, "DEBUG", "", j)
+}
+
+func (l *Logger) Info(i ...interface{}) {
+	l.log(log.INFO, "INFO", fmt.Sprint(i...), nil)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(log.INFO, "INFO", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Infoj(j log.JSON) {
+	l.log(log.INFO, "INFO", "", j)
+}
+
+func (l *Logger) Warn(i ...interface{}) {
+	l.log(log.WARN, "WARNING", fmt.Sprint(i...), nil)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.log(log.WARN, "WARNING", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Warnj(j log.JSON) {
+	l.log(log.WARN, "WARNING", "", j)
+}
+
+func (l *Logger) Error(i ...interface{}) {
+	l.log(log.ERROR, "ERROR", fmt.Sprint(i...), nil)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(log.ERROR, "ERROR", fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) Errorj(j log.JSON) {
+	l.log(log.ERROR, "ERROR", "", j)
+}
+
+func (l *Logger) Fatal(i ...interface{}) {
+	l.log(0, "CRITICAL", fmt.Sprint(i...), nil)
+	os.Exit(1)
+}
+
+func (l *Logger) Fatalj(j log.JSON) {
+	l.log(0, "CRITICAL", "", j)
+	os.Exit(1)
+}
+
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.log(0, "CRITICAL", fmt.Sprintf(format, args...), nil)
+	os.Exit(1)
+}
+
+func (l *Logger) Panic(i ...interface{}) {
+	msg := fmt.Sprint(i...)
+	l.log(0, "ALERT", msg, nil)
+	panic(msg)
+}
+
+func (l *Logger) Panicj(j log.JSON) {
+	l.log(0, "ALERT", "", j)
+	panic(j)
+}
+
+func (l *Logger) Panicf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	l.log(0, "ALERT", msg, nil)
+	panic(msg)
+}
+
+// log writes entry when level is enabled. Level 0 is always written.
+func (l *Logger) log(level log.Lvl, severity, message string, j log.JSON) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level != 0 && level < *l.level {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(l.fields)+len(j)+2)
+	for k, v := range l.fields {
+		entry[k] = v
+	}
+	for k, v := range j {
+		entry[k] = v
+	}
+	entry["severity"] = severity
+	if message != "" || j == nil {
+		entry["message"] = l.prefix + message
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"severity": "ERROR", "message": "cloudrun: " + err.Error()})
+	}
+	(*l.out).Write(append(b, '\n'))
+}
@@ -0,0 +1,75 @@
+package cloudrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/labstack/gommon/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(buf)
+
+	l.Debug("hidden")
+	l.Infof("hello %s", "jon")
+	l.Warnj(log.JSON{"user": "jon"})
+	l.Print("always")
+	l.SetLevel(log.ERROR)
+	l.Warn("hidden")
+	l.Error("failed")
+
+	assert.Equal(t, []map[string]interface{}{
+		{"severity": "INFO", "message": "hello jon"},
+		{"severity": "WARNING", "user": "jon"},
+		{"severity": "DEFAULT", "message": "always"},
+		{"severity": "ERROR", "message": "failed"},
+	}, decodeEntries(t, buf))
+}
+
+func TestLogger_WithTrace(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(buf)
+	child := l.WithTrace("my-project", Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true})
+
+	// level and output are shared
+	l.SetLevel(log.WARN)
+	child.Info("hidden")
+	child.Warn("slow")
+	l.Warn("other")
+
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"severity":                             "WARNING",
+			"message":                              "slow",
+			"logging.googleapis.com/trace":         "projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736",
+			"logging.googleapis.com/spanId":        "00f067aa0ba902b7",
+			"logging.googleapis.com/trace_sampled": true,
+		},
+		{"severity": "WARNING", "message": "other"},
+	}, decodeEntries(t, buf))
+}
+
+func TestLogger_Panic(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(buf)
+	assert.PanicsWithValue(t, "boom 1", func() { l.Panicf("boom %d", 1) })
+	assert.Equal(t, []map[string]interface{}{{"severity": "ALERT", "message": "boom 1"}}, decodeEntries(t, buf))
+}
@@ -0,0 +1,116 @@
+package cloudrun
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
+)
+
+type (
+	// Config defines the config for cloudrun middleware.
+	Config struct {
+		// Skipper defines a function to skip middleware.
+		Skipper middleware.Skipper
+
+		// ProjectID is ID of Google Cloud project traces belong to.
+		// Optional. Default value `ProjectID()`.
+		ProjectID string
+
+		// AccessLog enables logging of every request as entry with `httpRequest` field shown by Cloud Logging as
+		// request log. Cloud Run already logs requests itself, enable it for Cloud Functions or when request logs
+		// need to be correlated with application logs by trace. Values of context keys registered with
+		// `Echo#RegisterLogKey()` are added as `context` field.
+		// Optional. Default value false.
+		AccessLog bool
+	}
+)
+
+// DefaultConfig is the default cloudrun middleware config.
+var DefaultConfig = Config{
+	Skipper: middleware.DefaultSkipper,
+}
+
+const contextKeyTrace = "cloudrun_trace"
+
+// Middleware returns a middleware which reads trace context of the request (see `ParseTrace()`) and, when
+// `Echo#Logger` is `*Logger`, replaces logger of the context with logger correlating entries with the trace.
+func Middleware(config Config) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.ProjectID == "" {
+		config.ProjectID = ProjectID()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			logger, ok := c.Logger().(*Logger)
+			if t, found := ParseTrace(c.Request().Header); found {
+				c.Set(contextKeyTrace, t)
+				if ok && config.ProjectID != "" {
+					logger = logger.WithTrace(config.ProjectID, t)
+					c.SetLogger(logger)
+				}
+			}
+			if !config.AccessLog || !ok {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			logAccess(c, logger, time.Since(start))
+			return nil
+		}
+	}
+}
+
+// TraceFromContext returns trace context of the request read by the middleware.
+func TraceFromContext(c echo.Context) (Trace, bool) {
+	t, ok := c.Get(contextKeyTrace).(Trace)
+	return t, ok
+}
+
+// logAccess writes request log entry in `HttpRequest` format of Cloud Logging.
+func logAccess(c echo.Context, logger *Logger, latency time.Duration) {
+	req := c.Request()
+	res := c.Response()
+	request := log.JSON{
+		"requestMethod": req.Method,
+		"requestUrl":    req.RequestURI,
+		"status":        res.Status,
+		"responseSize":  fmt.Sprint(res.Size),
+		"userAgent":     req.UserAgent(),
+		"remoteIp":      c.RealIP(),
+		"referer":       req.Referer(),
+		"latency":       fmt.Sprintf("%.9fs", latency.Seconds()),
+		"protocol":      req.Proto,
+	}
+	if req.ContentLength > 0 {
+		request["requestSize"] = fmt.Sprint(req.ContentLength)
+	}
+
+	message := fmt.Sprintf("%s %s %d", req.Method, req.RequestURI, res.Status)
+	entry := log.JSON{FieldHTTPRequest: request, "message": message}
+	if values := echo.LogValues(c); values != nil {
+		entry["context"] = values
+	}
+	switch {
+	case res.Status >= 500:
+		logger.Errorj(entry)
+	case res.Status >= 400:
+		logger.Warnj(entry)
+	default:
+		logger.Infoj(entry)
+	}
+}
@@ -0,0 +1,80 @@
+package cloudrun
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := echo.New()
+	e.Logger = NewLogger(buf)
+	e.Use(Middleware(Config{ProjectID: "my-project", AccessLog: true}))
+	e.GET("/users/:id", func(c echo.Context) error {
+		trace, ok := TraceFromContext(c)
+		assert.True(t, ok)
+		assert.Equal(t, "105445aa7843bc8bf206b12000100000", trace.TraceID)
+		c.Logger().Info("loading user")
+		return echo.ErrNotFound
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(HeaderCloudTraceContext, "105445aa7843bc8bf206b12000100000/1;o=1")
+	req.Header.Set(echo.HeaderUserAgent, "test")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	entries := decodeEntries(t, buf)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "loading user", entries[0]["message"])
+		assert.Equal(t, "projects/my-project/traces/105445aa7843bc8bf206b12000100000", entries[0][FieldTrace])
+
+		assert.Equal(t, "WARNING", entries[1]["severity"])
+		assert.Equal(t, "GET /users/1 404", entries[1]["message"])
+		assert.Equal(t, "projects/my-project/traces/105445aa7843bc8bf206b12000100000", entries[1][FieldTrace])
+		request := entries[1][FieldHTTPRequest].(map[string]interface{})
+		assert.Equal(t, "GET", request["requestMethod"])
+		assert.Equal(t, "/users/1", request["requestUrl"])
+		assert.Equal(t, float64(404), request["status"])
+		assert.Equal(t, "test", request["userAgent"])
+		assert.Regexp(t, `^\d+\.\d{9}s$`, request["latency"])
+	}
+}
+
+func TestMiddleware_AccessLogContextValues(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := echo.New()
+	e.Logger = NewLogger(buf)
+	e.RegisterLogKey("tenant", nil)
+	e.Use(Middleware(Config{ProjectID: "my-project", AccessLog: true}))
+	e.GET("/", func(c echo.Context) error {
+		c.Set("tenant", "acme")
+		return c.NoContent(http.StatusOK)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	entries := decodeEntries(t, buf)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, map[string]interface{}{"tenant": "acme"}, entries[0]["context"])
+	}
+}
+
+func TestMiddleware_WithoutCloudLogger(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(Config{ProjectID: "my-project", AccessLog: true}))
+	e.GET("/", func(c echo.Context) error {
+		_, ok := TraceFromContext(c)
+		assert.False(t, ok)
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
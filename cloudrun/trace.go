@@ -0,0 +1,85 @@
+package cloudrun
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Trace is trace context of request propagated by Google front end and other Google Cloud services.
+type Trace struct {
+	// TraceID is 32 hex digits ID of the trace.
+	TraceID string
+	// SpanID is 16 hex digits ID of the span, empty when not known.
+	SpanID string
+	// Sampled is true when the trace is recorded.
+	Sampled bool
+}
+
+// Trace context request headers.
+const (
+	HeaderCloudTraceContext = "X-Cloud-Trace-Context"
+	HeaderTraceparent       = "Traceparent"
+)
+
+// ParseTrace returns trace context of the request from W3C `traceparent` header or, when missing or invalid, from
+// `X-Cloud-Trace-Context` header (`TRACE_ID/SPAN_ID;o=OPTIONS` with decimal span ID).
+func ParseTrace(h http.Header) (Trace, bool) {
+	if t, ok := parseTraceparent(h.Get(HeaderTraceparent)); ok {
+		return t, true
+	}
+	return parseCloudTraceContext(h.Get(HeaderCloudTraceContext))
+}
+
+// parseTraceparent parses `version-traceid-spanid-flags`.
+func parseTraceparent(v string) (Trace, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isHex(parts[1], 32) || !isHex(parts[2], 16) ||
+		!isHex(parts[3], 2) {
+		return Trace{}, false
+	}
+	if parts[1] == strings.Repeat("0", 32) || parts[2] == strings.Repeat("0", 16) {
+		return Trace{}, false
+	}
+	flags, _ := hex.DecodeString(parts[3])
+	return Trace{TraceID: parts[1], SpanID: parts[2], Sampled: flags[0]&1 == 1}, true
+}
+
+func parseCloudTraceContext(v string) (Trace, bool) {
+	v = strings.TrimSpace(v)
+	options := ""
+	if i := strings.IndexByte(v, ';'); i >= 0 {
+		v, options = v[:i], v[i+1:]
+	}
+	span := ""
+	if i := strings.IndexByte(v, '/'); i >= 0 {
+		v, span = v[:i], v[i+1:]
+	}
+	if !isHex(v, 32) {
+		return Trace{}, false
+	}
+	t := Trace{TraceID: strings.ToLower(v), Sampled: options == "o=1"}
+	if id, err := strconv.ParseUint(span, 10, 64); err == nil && id != 0 {
+		t.SpanID = hexSpanID(id)
+	}
+	return t, true
+}
+
+func hexSpanID(id uint64) string {
+	s := strconv.FormatUint(id, 16)
+	return strings.Repeat("0", 16-len(s)) + s
+}
+
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
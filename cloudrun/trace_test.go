@@ -0,0 +1,67 @@
+package cloudrun
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrace(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		whenHeader  map[string]string
+		expect      Trace
+		expectFound bool
+	}{
+		{
+			name:        "ok, X-Cloud-Trace-Context",
+			whenHeader:  map[string]string{HeaderCloudTraceContext: "105445AA7843BC8BF206B12000100000/1;o=1"},
+			expect:      Trace{TraceID: "105445aa7843bc8bf206b12000100000", SpanID: "0000000000000001", Sampled: true},
+			expectFound: true,
+		},
+		{
+			name:        "ok, X-Cloud-Trace-Context without span and options",
+			whenHeader:  map[string]string{HeaderCloudTraceContext: "105445aa7843bc8bf206b12000100000"},
+			expect:      Trace{TraceID: "105445aa7843bc8bf206b12000100000"},
+			expectFound: true,
+		},
+		{
+			name: "ok, traceparent preferred",
+			whenHeader: map[string]string{
+				HeaderTraceparent:       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				HeaderCloudTraceContext: "105445aa7843bc8bf206b12000100000/1;o=1",
+			},
+			expect:      Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+			expectFound: true,
+		},
+		{
+			name: "ok, invalid traceparent falls back",
+			whenHeader: map[string]string{
+				HeaderTraceparent:       "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+				HeaderCloudTraceContext: "105445aa7843bc8bf206b12000100000/255;o=0",
+			},
+			expect:      Trace{TraceID: "105445aa7843bc8bf206b12000100000", SpanID: "00000000000000ff"},
+			expectFound: true,
+		},
+		{
+			name:       "nok, invalid trace ID",
+			whenHeader: map[string]string{HeaderCloudTraceContext: "abc/1;o=1"},
+		},
+		{
+			name: "nok, no headers",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tc.whenHeader {
+				h.Set(k, v)
+			}
+			trace, found := ParseTrace(h)
+			assert.Equal(t, tc.expectFound, found)
+			assert.Equal(t, tc.expect, trace)
+		})
+	}
+}
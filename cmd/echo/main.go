@@ -0,0 +1,50 @@
+/*
+Command echo scaffolds new Echo projects.
+
+Usage:
+
+	echo new [-module path] [-force] <directory>
+
+Command new creates project with production ready setup:
+  - middleware stack with recover, request ID, access logging and metrics,
+  - health check (`/healthz`) and metrics (`/metrics`, expvar format) endpoints,
+  - graceful shutdown on SIGINT and SIGTERM,
+  - config loaded from defaults, optional JSON file and environment variables,
+  - Dockerfile and systemd unit.
+
+Shells have builtin `echo` command shadowing the binary, run it with full path or with `go run`:
+
+	go run github.com/labstack/echo/v4/cmd/echo new -module example.com/orders orders
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "new" {
+		fmt.Fprintln(os.Stderr, "usage: echo new [-module path] [-force] <directory>")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("new", flag.ExitOnError)
+	module := flags.String("module", "", "module path of the project (default directory name)")
+	force := flags.Bool("force", false, "overwrite existing files")
+	flags.Parse(os.Args[2:])
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: echo new [-module path] [-force] <directory>")
+		os.Exit(2)
+	}
+
+	files, err := scaffold(flags.Arg(0), project{Module: *module}, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "echo: %v\n", err)
+		os.Exit(1)
+	}
+	for _, f := range files {
+		fmt.Println("created", f)
+	}
+}
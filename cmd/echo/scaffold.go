@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/labstack/echo/v4"
+)
+
+// project holds values used in project templates.
+type project struct {
+	// Module is module path, i.e. "example.com/orders".
+	Module string
+	// Name is name of the binary, docker image and systemd unit, last element of module path.
+	Name string
+	// EnvPrefix is prefix of environment variables of config, i.e. "ORDERS_".
+	EnvPrefix string
+	// EchoVersion is version of Echo required by the project.
+	EchoVersion string
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// scaffold writes project files to directory and returns their names. Existing files are overwritten only with
+// force.
+func scaffold(dir string, p project, force bool) ([]string, error) {
+	if p.Module == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		p.Module = filepath.Base(abs)
+	}
+	p.Name = path.Base(p.Module)
+	p.EnvPrefix = strings.ToUpper(strings.Trim(nonAlphanumeric.ReplaceAllString(p.Name, "_"), "_")) + "_"
+	p.EchoVersion = echo.Version
+
+	names := make([]string, 0, len(projectTemplates))
+	for name := range projectTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := map[string][]byte{}
+	for _, name := range names {
+		t, err := template.New(name).Parse(projectTemplates[name])
+		if err != nil {
+			return nil, err
+		}
+		buf := new(bytes.Buffer)
+		if err := t.Execute(buf, p); err != nil {
+			return nil, err
+		}
+		content := buf.Bytes()
+		if strings.HasSuffix(name, ".go") {
+			if content, err = format.Source(content); err != nil {
+				return nil, fmt.Errorf("invalid template %s: %v", name, err)
+			}
+		}
+		name = strings.Replace(name, "{{name}}", p.Name, -1)
+		files[filepath.Join(dir, filepath.FromSlash(name))] = content
+	}
+
+	created := make([]string, 0, len(files))
+	for name := range files {
+		created = append(created, name)
+	}
+	sort.Strings(created)
+	for _, name := range created {
+		if _, err := os.Stat(name); err == nil && !force {
+			return nil, fmt.Errorf("file %s already exists, use -force to overwrite", name)
+		}
+	}
+	for _, name := range created {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(name, files[name], 0644); err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
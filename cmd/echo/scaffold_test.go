@@ -0,0 +1,73 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffold(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scaffold")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files, err := scaffold(dir, project{Module: "example.com/order-service"}, false)
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range files {
+		rel, err := filepath.Rel(dir, f)
+		require.NoError(t, err)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	assert.Equal(t, []string{
+		".dockerignore", ".gitignore", "Dockerfile", "README.md", "config.go", "deploy/order-service.service",
+		"go.mod", "main.go", "metrics.go", "server.go", "server_test.go",
+	}, names)
+
+	read := func(name string) string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return string(b)
+	}
+	assert.Contains(t, read("go.mod"), "module example.com/order-service\n")
+	assert.Contains(t, read("go.mod"), "github.com/labstack/echo/v4 v"+echo.Version)
+	assert.Contains(t, read("config.go"), `os.LookupEnv("ORDER_SERVICE_ADDRESS")`)
+	assert.Contains(t, read("Dockerfile"), `ENTRYPOINT ["/order-service"]`)
+	assert.Contains(t, read("deploy/order-service.service"), "ExecStart=/usr/local/bin/order-service")
+
+	for _, name := range names {
+		if strings.HasSuffix(name, ".go") {
+			_, err := parser.ParseFile(token.NewFileSet(), name, read(name), 0)
+			assert.NoError(t, err, name)
+		}
+	}
+
+	_, err = scaffold(dir, project{Module: "example.com/order-service"}, false)
+	assert.EqualError(t, err, "file "+filepath.Join(dir, ".dockerignore")+" already exists, use -force to overwrite")
+
+	_, err = scaffold(dir, project{Module: "example.com/order-service"}, true)
+	assert.NoError(t, err)
+}
+
+func TestScaffold_ModuleFromDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scaffold")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	dir = filepath.Join(dir, "billing")
+
+	_, err = scaffold(dir, project{}, false)
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(b), "module billing\n"))
+}
@@ -0,0 +1,337 @@
+package main
+
+// projectTemplates are templates of project files by file name. `{{name}}` in file name is replaced by project
+// name.
+var projectTemplates = map[string]string{
+	"go.mod": `module {{.Module}}
+
+go 1.16
+
+require (
+	github.com/labstack/echo/v4 v{{.EchoVersion}}
+	github.com/labstack/gommon v0.3.1
+)
+`,
+
+	"main.go": `package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	config, err := LoadConfig(os.Getenv("{{.EnvPrefix}}CONFIG_FILE"))
+	if err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+	e := NewServer(config)
+
+	go func() {
+		if err := e.Start(config.Address); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.Logger.Fatal(err)
+		}
+	}()
+
+	// Wait for SIGINT (Ctrl+C) or SIGTERM (docker stop, systemctl stop) and give in-flight requests
+	// ShutdownTimeout to finish.
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+	e.Logger.Info("shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		e.Logger.Fatal(err)
+	}
+}
+`,
+
+	"config.go": `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config is configuration of the server. Values are loaded from defaults, JSON config file (path is in
+// {{.EnvPrefix}}CONFIG_FILE environment variable) and environment variables, later sources override earlier.
+type Config struct {
+	// Address is address the server listens on. Env {{.EnvPrefix}}ADDRESS.
+	Address string ` + "`json:\"address\"`" + `
+
+	// ReadTimeout is maximum duration of reading the request. Env {{.EnvPrefix}}READ_TIMEOUT.
+	ReadTimeout Duration ` + "`json:\"read_timeout\"`" + `
+
+	// WriteTimeout is maximum duration of writing the response. Env {{.EnvPrefix}}WRITE_TIMEOUT.
+	WriteTimeout Duration ` + "`json:\"write_timeout\"`" + `
+
+	// ShutdownTimeout is time given to in-flight requests to finish on shutdown. Env {{.EnvPrefix}}SHUTDOWN_TIMEOUT.
+	ShutdownTimeout Duration ` + "`json:\"shutdown_timeout\"`" + `
+
+	// LogLevel is one of "debug", "info", "warn", "error" or "off". Env {{.EnvPrefix}}LOG_LEVEL.
+	LogLevel string ` + "`json:\"log_level\"`" + `
+}
+
+// Duration is time.Duration written as string in config, i.e. "15s".
+type Duration struct {
+	time.Duration
+}
+
+// DefaultConfig returns config used when no other source sets a value.
+func DefaultConfig() Config {
+	return Config{
+		Address:         ":8080",
+		ReadTimeout:     Duration{30 * time.Second},
+		WriteTimeout:    Duration{30 * time.Second},
+		ShutdownTimeout: Duration{15 * time.Second},
+		LogLevel:        "info",
+	}
+}
+
+// LoadConfig loads config from defaults, optional JSON file and environment variables.
+func LoadConfig(file string) (Config, error) {
+	config := DefaultConfig()
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return config, err
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return config, fmt.Errorf("%s: %v", file, err)
+		}
+	}
+
+	if v, ok := os.LookupEnv("{{.EnvPrefix}}ADDRESS"); ok {
+		config.Address = v
+	}
+	if v, ok := os.LookupEnv("{{.EnvPrefix}}LOG_LEVEL"); ok {
+		config.LogLevel = v
+	}
+	for env, d := range map[string]*Duration{
+		"{{.EnvPrefix}}READ_TIMEOUT":     &config.ReadTimeout,
+		"{{.EnvPrefix}}WRITE_TIMEOUT":    &config.WriteTimeout,
+		"{{.EnvPrefix}}SHUTDOWN_TIMEOUT": &config.ShutdownTimeout,
+	} {
+		if v, ok := os.LookupEnv(env); ok {
+			if err := d.UnmarshalText([]byte(v)); err != nil {
+				return config, fmt.Errorf("%s: %v", env, err)
+			}
+		}
+	}
+
+	if _, ok := logLevels[config.LogLevel]; !ok {
+		return config, fmt.Errorf("unknown log level %q", config.LogLevel)
+	}
+	return config, nil
+}
+
+// UnmarshalText parses duration like "15s" or "1m30s".
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// MarshalText writes duration like "15s".
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+`,
+
+	"server.go": `package main
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
+)
+
+var logLevels = map[string]log.Lvl{
+	"debug": log.DEBUG,
+	"info":  log.INFO,
+	"warn":  log.WARN,
+	"error": log.ERROR,
+	"off":   log.OFF,
+}
+
+// NewServer creates Echo instance with middleware stack and routes of the service.
+func NewServer(config Config) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+	e.Logger.SetLevel(logLevels[config.LogLevel])
+	e.Server.ReadTimeout = config.ReadTimeout.Duration
+	e.Server.WriteTimeout = config.WriteTimeout.Duration
+
+	e.Use(middleware.Recover())
+	e.Use(middleware.RequestID())
+	e.Use(middleware.Logger())
+	e.Use(Metrics)
+
+	e.GET("/healthz", health)
+	e.GET("/metrics", echo.WrapHandler(expvar.Handler()))
+
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "Hello from {{.Name}}!")
+	})
+	return e
+}
+
+// health answers health checks of load balancers, orchestrators and monitoring.
+func health(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+}
+`,
+
+	"metrics.go": `package main
+
+import (
+	"expvar"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics are published in expvar format at /metrics.
+var (
+	requestsTotal    = expvar.NewMap("http_requests_total")
+	requestsInFlight = expvar.NewInt("http_requests_in_flight")
+	requestSeconds   = expvar.NewMap("http_request_duration_seconds_sum")
+)
+
+// Metrics is middleware counting requests by status code and summing their duration by route.
+func Metrics(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		requestsInFlight.Add(1)
+		defer requestsInFlight.Add(-1)
+
+		start := time.Now()
+		err := next(c)
+		if err != nil {
+			// let error handler write the response so its status is counted
+			c.Error(err)
+		}
+		requestsTotal.Add(strconv.Itoa(c.Response().Status), 1)
+		requestSeconds.AddFloat(c.Request().Method+" "+c.Path(), time.Since(start).Seconds())
+		return nil
+	}
+}
+`,
+
+	"server_test.go": `package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	e := NewServer(DefaultConfig())
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), ` + "`\"status\":\"ok\"`" + `) {
+		t.Fatalf("unexpected body %q", rec.Body.String())
+	}
+}
+`,
+
+	"Dockerfile": `FROM golang:1.17-alpine AS build
+WORKDIR /src
+COPY go.mod go.sum ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -trimpath -ldflags="-s -w" -o /out/{{.Name}} .
+
+FROM gcr.io/distroless/static:nonroot
+COPY --from=build /out/{{.Name}} /{{.Name}}
+ENV {{.EnvPrefix}}ADDRESS=:8080
+EXPOSE 8080
+USER nonroot:nonroot
+# docker stop sends SIGTERM, the server shuts down gracefully
+ENTRYPOINT ["/{{.Name}}"]
+`,
+
+	".dockerignore": `.git
+Dockerfile
+deploy
+`,
+
+	".gitignore": `/{{.Name}}
+`,
+
+	"deploy/{{name}}.service": `[Unit]
+Description={{.Name}}
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+ExecStart=/usr/local/bin/{{.Name}}
+Environment={{.EnvPrefix}}ADDRESS=:8080
+# optional overrides, i.e. {{.EnvPrefix}}LOG_LEVEL=debug
+EnvironmentFile=-/etc/{{.Name}}/env
+Restart=on-failure
+RestartSec=2
+KillSignal=SIGTERM
+TimeoutStopSec=30
+DynamicUser=yes
+NoNewPrivileges=yes
+ProtectSystem=strict
+ProtectHome=yes
+PrivateTmp=yes
+
+[Install]
+WantedBy=multi-user.target
+`,
+
+	"README.md": `# {{.Name}}
+
+Service built with [Echo](https://echo.labstack.com).
+
+## Run
+
+    go mod tidy
+    go run .
+
+Configuration is read from JSON file set in ` + "`{{.EnvPrefix}}CONFIG_FILE`" + ` and from environment variables
+` + "`{{.EnvPrefix}}ADDRESS`, `{{.EnvPrefix}}LOG_LEVEL`, `{{.EnvPrefix}}READ_TIMEOUT`, `{{.EnvPrefix}}WRITE_TIMEOUT`" + ` and
+` + "`{{.EnvPrefix}}SHUTDOWN_TIMEOUT`" + `, see ` + "`config.go`" + `.
+
+## Endpoints
+
+- ` + "`GET /healthz`" + ` health check
+- ` + "`GET /metrics`" + ` request metrics in expvar format
+
+## Deploy
+
+    docker build -t {{.Name}} .
+    docker run -p 8080:8080 {{.Name}}
+
+or build the binary to ` + "`/usr/local/bin/{{.Name}}`" + ` and install systemd unit:
+
+    cp deploy/{{.Name}}.service /etc/systemd/system/
+    systemctl enable --now {{.Name}}
+`,
+}
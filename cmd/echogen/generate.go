@@ -0,0 +1,777 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	document struct {
+		Info struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]*schema `json:"schemas"`
+		} `json:"components"`
+	}
+
+	operation struct {
+		OperationID string      `json:"operationId"`
+		Summary     string      `json:"summary"`
+		Description string      `json:"description"`
+		Tags        []string    `json:"tags"`
+		Parameters  []parameter `json:"parameters"`
+		RequestBody *struct {
+			Required bool                 `json:"required"`
+			Content  map[string]mediaType `json:"content"`
+		} `json:"requestBody"`
+		Responses map[string]struct {
+			Content map[string]mediaType `json:"content"`
+		} `json:"responses"`
+	}
+
+	parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Required    bool    `json:"required"`
+		Description string  `json:"description"`
+		Schema      *schema `json:"schema"`
+	}
+
+	mediaType struct {
+		Schema *schema `json:"schema"`
+	}
+
+	schema struct {
+		Ref         string             `json:"$ref"`
+		Type        string             `json:"type"`
+		Format      string             `json:"format"`
+		Description string             `json:"description"`
+		Properties  map[string]*schema `json:"properties"`
+		Required    []string           `json:"required"`
+		Items       *schema            `json:"items"`
+		Enum        []interface{}      `json:"enum"`
+		Minimum     *float64           `json:"minimum"`
+		Maximum     *float64           `json:"maximum"`
+		MinLength   *int               `json:"minLength"`
+		MaxLength   *int               `json:"maxLength"`
+		MinItems    *int               `json:"minItems"`
+		MaxItems    *int               `json:"maxItems"`
+	}
+
+	// endpoint is operation of the document with names of its generated types.
+	endpoint struct {
+		name   string
+		method string
+		path   string
+		op     *operation
+		params []parameter
+		body   string // Go type of request body, empty without JSON body
+	}
+
+	// decl is named type generated for a schema.
+	decl struct {
+		name   string
+		schema *schema
+	}
+
+	generator struct {
+		doc      *document
+		out      bytes.Buffer
+		imports  map[string]bool
+		decls    []decl
+		declared map[string]bool
+	}
+)
+
+var errNoPaths = errors.New("document has no paths")
+
+// methods are operation keys of path item in order routes are registered.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// initialisms are words written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true,
+	"TLS": true, "UID": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// generate returns formatted Go source of package pkg for the OpenAPI document.
+func generate(data []byte, pkg string) ([]byte, error) {
+	doc := &document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %v", err)
+	}
+	if len(doc.Paths) == 0 {
+		return nil, errNoPaths
+	}
+	g := &generator{doc: doc, imports: map[string]bool{}, declared: map[string]bool{}}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.declare(goName(name), doc.Components.Schemas[name])
+	}
+
+	endpoints, err := g.endpoints()
+	if err != nil {
+		return nil, err
+	}
+	for _, ep := range endpoints {
+		g.writeParams(ep)
+	}
+	g.writeServer(endpoints)
+	// inline schemas are declared while writing other types, write them last
+	for i := 0; i < len(g.decls); i++ {
+		g.writeDecl(g.decls[i])
+	}
+
+	src := new(bytes.Buffer)
+	fmt.Fprintf(src, "// Code generated by echogen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	var std, thirdParty []string
+	for imp := range g.imports {
+		if strings.Contains(imp, ".") {
+			thirdParty = append(thirdParty, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(thirdParty)
+	for _, imp := range std {
+		fmt.Fprintf(src, "\t%q\n", imp)
+	}
+	src.WriteString("\n")
+	for _, imp := range thirdParty {
+		fmt.Fprintf(src, "\t%q\n", imp)
+	}
+	src.WriteString(")\n")
+	src.Write(g.out.Bytes())
+
+	code, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %v", err)
+	}
+	return code, nil
+}
+
+// endpoints returns operations of the document sorted by path and method.
+func (g *generator) endpoints() ([]*endpoint, error) {
+	paths := make([]string, 0, len(g.doc.Paths))
+	for p := range g.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var endpoints []*endpoint
+	names := map[string]string{}
+	for _, p := range paths {
+		item := g.doc.Paths[p]
+		var common []parameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &common); err != nil {
+				return nil, fmt.Errorf("invalid parameters of path %s: %v", p, err)
+			}
+		}
+		for _, m := range methods {
+			raw, ok := item[m]
+			if !ok {
+				continue
+			}
+			op := &operation{}
+			if err := json.Unmarshal(raw, op); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %v", strings.ToUpper(m), p, err)
+			}
+			ep := &endpoint{method: strings.ToUpper(m), path: p, op: op, params: mergeParams(common, op.Parameters)}
+			if op.OperationID != "" {
+				ep.name = goName(op.OperationID)
+			} else {
+				ep.name = goName(m + " " + p)
+			}
+			if other, ok := names[ep.name]; ok {
+				return nil, fmt.Errorf("operations %s and %s %s have same name %s", other, ep.method, p, ep.name)
+			}
+			names[ep.name] = ep.method + " " + p
+			for _, param := range ep.params {
+				if param.In == "path" && !strings.Contains(p, "{"+param.Name+"}") {
+					return nil, fmt.Errorf("path parameter %q is not in path of operation %s", param.Name, ep.name)
+				}
+			}
+			if op.RequestBody != nil {
+				if s := jsonSchema(op.RequestBody.Content); s != nil {
+					ep.body = g.goType(s, ep.name+"Request")
+				}
+			}
+			for _, status := range sortedStatuses(op) {
+				if s := jsonSchema(op.Responses[status].Content); s != nil {
+					if _, err := strconv.Atoi(status); err != nil {
+						status = goName(status) // "default", "2XX"
+					}
+					g.goType(s, ep.name+status+"Response")
+				}
+			}
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, nil
+}
+
+// mergeParams returns parameters of operation including path item parameters not overridden by operation.
+func mergeParams(common, params []parameter) []parameter {
+	result := append([]parameter{}, params...)
+	for _, c := range common {
+		found := false
+		for _, p := range params {
+			if p.Name == c.Name && p.In == c.In {
+				found = true
+			}
+		}
+		if !found {
+			result = append(result, c)
+		}
+	}
+	var supported []parameter
+	for _, p := range result {
+		switch p.In {
+		case "path":
+			p.Required = true
+			supported = append(supported, p)
+		case "query", "header":
+			supported = append(supported, p)
+		}
+	}
+	return supported
+}
+
+// jsonSchema returns schema of JSON media type of the content.
+func jsonSchema(content map[string]mediaType) *schema {
+	for ct, mt := range content {
+		if ct == "application/json" || strings.HasSuffix(ct, "+json") {
+			if mt.Schema == nil {
+				return &schema{}
+			}
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// resolve returns schema referenced by s or s itself.
+func (g *generator) resolve(s *schema) *schema {
+	for i := 0; s != nil && s.Ref != "" && i < 16; i++ {
+		s = g.doc.Components.Schemas[refName(s.Ref)]
+	}
+	if s == nil {
+		return &schema{}
+	}
+	return s
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndexByte(ref, '/')+1:]
+}
+
+// goType returns Go type of the schema. Inline objects are declared as named types.
+func (g *generator) goType(s *schema, name string) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		return goName(refName(s.Ref))
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items, name+"Item")
+	case "object", "":
+		if len(s.Properties) > 0 {
+			g.declare(name, s)
+			return name
+		}
+		if s.Type == "object" {
+			return "map[string]interface{}"
+		}
+	}
+	return "interface{}"
+}
+
+// named reports whether Go type is type generated for a schema.
+func (g *generator) named(goType string) bool {
+	return g.declared[goType]
+}
+
+func (g *generator) declare(name string, s *schema) {
+	if g.declared[name] {
+		return
+	}
+	g.declared[name] = true
+	g.decls = append(g.decls, decl{name: name, schema: s})
+}
+
+func (g *generator) writeDecl(d decl) {
+	s := d.schema
+	if s.Description != "" {
+		writeComment(&g.out, "", s.Description)
+	} else {
+		fmt.Fprintf(&g.out, "// %s is generated from OpenAPI schema.\n", d.name)
+	}
+	if s.Ref != "" || len(s.Properties) == 0 {
+		// named non-object type, i.e. string enum
+		underlying := g.goType(&schema{Type: s.Type, Format: s.Format, Items: s.Items, Ref: s.Ref}, d.name+"Value")
+		if underlying == d.name {
+			underlying = "interface{}"
+		}
+		fmt.Fprintf(&g.out, "type %s %s\n\n", d.name, underlying)
+		fmt.Fprintf(&g.out, "// Validate checks constraints of %s declared in OpenAPI document.\n", d.name)
+		fmt.Fprintf(&g.out, "func (v %s) Validate() error {\n", d.name)
+		expr := "v"
+		if g.named(underlying) || underlying == "string" {
+			// methods of underlying named type are not inherited
+			expr = underlying + "(v)"
+		}
+		g.writeChecks(expr, d.name, s, underlying, false)
+		g.out.WriteString("\treturn nil\n}\n\n")
+		return
+	}
+
+	props := sortedKeys(s.Properties)
+	type field struct {
+		name, goType, json string
+		schema             *schema
+		pointer            bool
+	}
+	fields := make([]field, 0, len(props))
+	fmt.Fprintf(&g.out, "type %s struct {\n", d.name)
+	for _, p := range props {
+		ps := s.Properties[p]
+		f := field{name: goName(p), json: p, schema: ps}
+		f.goType = g.goType(ps, d.name+f.name)
+		required := contains(s.Required, p)
+		if !required && !isReference(f.goType) {
+			f.pointer = true
+		}
+		fields = append(fields, f)
+
+		if ps.Description != "" {
+			writeComment(&g.out, "", ps.Description)
+		}
+		tag := p
+		if !required {
+			tag += ",omitempty"
+		}
+		typ := f.goType
+		if f.pointer {
+			typ = "*" + typ
+		}
+		fmt.Fprintf(&g.out, "\t%s %s `json:%q`\n", f.name, typ, tag)
+	}
+	g.out.WriteString("}\n\n")
+
+	fmt.Fprintf(&g.out, "// Validate checks constraints of %s declared in OpenAPI document.\n", d.name)
+	fmt.Fprintf(&g.out, "func (v %s) Validate() error {\n", d.name)
+	for _, f := range fields {
+		g.writeChecks("v."+f.name, f.json, f.schema, f.goType, f.pointer)
+	}
+	g.out.WriteString("\treturn nil\n}\n\n")
+}
+
+// isReference reports whether zero value of Go type (slice, map, interface) already means value is missing.
+func isReference(goType string) bool {
+	return strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || goType == "interface{}"
+}
+
+// writeChecks writes validation of value expr (pointer to value when pointer is true) of the schema. Failed checks
+// return error prefixed with label.
+func (g *generator) writeChecks(expr, label string, s *schema, goType string, pointer bool) {
+	checks := new(bytes.Buffer)
+	value := expr
+	if pointer && !g.named(goType) {
+		value = "*" + expr
+	}
+	fail := func(format string, args ...interface{}) {
+		g.imports["errors"] = true
+		fmt.Fprintf(checks, "\t\treturn errors.New(%q)\n\t}\n", label+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case g.named(goType):
+		g.imports["fmt"] = true
+		fmt.Fprintf(checks, "\tif err := %s.Validate(); err != nil {\n\t\treturn fmt.Errorf(\"%s: %%w\", err)\n\t}\n", value, label)
+	case strings.HasPrefix(goType, "[]"):
+		rs := g.resolve(s)
+		if rs.MinItems != nil {
+			fmt.Fprintf(checks, "\tif len(%s) < %d {\n", value, *rs.MinItems)
+			fail("must have at least %d items", *rs.MinItems)
+		}
+		if rs.MaxItems != nil {
+			fmt.Fprintf(checks, "\tif len(%s) > %d {\n", value, *rs.MaxItems)
+			fail("must have at most %d items", *rs.MaxItems)
+		}
+		if item := strings.TrimPrefix(goType, "[]"); g.named(item) {
+			g.imports["fmt"] = true
+			fmt.Fprintf(checks, "\tfor i := range %s {\n\t\tif err := %s[i].Validate(); err != nil {\n", value, value)
+			fmt.Fprintf(checks, "\t\t\treturn fmt.Errorf(\"%s[%%d]: %%w\", i, err)\n\t\t}\n\t}\n", label)
+		}
+	case goType == "string":
+		if len(s.Enum) > 0 {
+			values := make([]string, 0, len(s.Enum))
+			quoted := make([]string, 0, len(s.Enum))
+			for _, e := range s.Enum {
+				if str, ok := e.(string); ok {
+					values = append(values, str)
+					quoted = append(quoted, strconv.Quote(str))
+				}
+			}
+			fmt.Fprintf(checks, "\tswitch %s {\n\tcase %s:\n\tdefault:\n", value, strings.Join(quoted, ", "))
+			g.imports["errors"] = true
+			fmt.Fprintf(checks, "\t\treturn errors.New(%q)\n\t}\n", label+": must be one of "+strings.Join(values, ", "))
+		}
+		if s.MinLength != nil {
+			g.imports["unicode/utf8"] = true
+			fmt.Fprintf(checks, "\tif utf8.RuneCountInString(%s) < %d {\n", value, *s.MinLength)
+			fail("must be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil {
+			g.imports["unicode/utf8"] = true
+			fmt.Fprintf(checks, "\tif utf8.RuneCountInString(%s) > %d {\n", value, *s.MaxLength)
+			fail("must be at most %d characters long", *s.MaxLength)
+		}
+	case strings.HasPrefix(goType, "int"), strings.HasPrefix(goType, "float"):
+		if s.Minimum != nil {
+			fmt.Fprintf(checks, "\tif %s < %s {\n", value, formatNumber(*s.Minimum))
+			fail("must be at least %s", formatNumber(*s.Minimum))
+		}
+		if s.Maximum != nil {
+			fmt.Fprintf(checks, "\tif %s > %s {\n", value, formatNumber(*s.Maximum))
+			fail("must be at most %s", formatNumber(*s.Maximum))
+		}
+	}
+
+	if checks.Len() == 0 {
+		return
+	}
+	if pointer {
+		fmt.Fprintf(&g.out, "\tif %s != nil {\n", expr)
+		g.out.Write(indent(checks.Bytes()))
+		g.out.WriteString("\t}\n")
+		return
+	}
+	g.out.Write(checks.Bytes())
+}
+
+func (g *generator) writeParams(ep *endpoint) {
+	if len(ep.params) == 0 {
+		return
+	}
+	name := ep.name + "Params"
+	fmt.Fprintf(&g.out, "// %s are parameters of %s %s.\n", name, ep.method, ep.path)
+	fmt.Fprintf(&g.out, "type %s struct {\n", name)
+	for _, p := range ep.params {
+		typ := g.goType(p.Schema, name+goName(p.Name))
+		if !p.Required && !isReference(typ) {
+			typ = "*" + typ
+		}
+		tag := map[string]string{"path": "param", "query": "query", "header": "header"}[p.In]
+		key := p.Name
+		if p.In == "header" {
+			key = http.CanonicalHeaderKey(key)
+		}
+		if p.Description != "" {
+			writeComment(&g.out, "", p.Description)
+		}
+		fmt.Fprintf(&g.out, "\t%s %s `%s:%q`\n", goName(p.Name), typ, tag, key)
+	}
+	g.out.WriteString("}\n\n")
+
+	fmt.Fprintf(&g.out, "// Validate checks constraints of %s declared in OpenAPI document.\n", name)
+	fmt.Fprintf(&g.out, "func (v %s) Validate() error {\n", name)
+	for _, p := range ep.params {
+		typ := g.goType(p.Schema, name+goName(p.Name))
+		pointer := !p.Required && !isReference(typ)
+		s := p.Schema
+		if s == nil {
+			s = &schema{}
+		}
+		g.writeChecks("v."+goName(p.Name), p.In+" parameter "+p.Name, s, typ, pointer)
+	}
+	g.out.WriteString("\treturn nil\n}\n\n")
+}
+
+func (g *generator) writeServer(endpoints []*endpoint) {
+	g.imports["github.com/labstack/echo/v4"] = true
+	g.imports["net/http"] = true
+
+	title := g.doc.Info.Title
+	if title == "" {
+		title = "the API"
+	}
+	fmt.Fprintf(&g.out, "// ServerInterface is implemented by handlers of operations of %s.\n", title)
+	g.out.WriteString("type ServerInterface interface {\n")
+	for _, ep := range endpoints {
+		summary := ep.op.Summary
+		if summary == "" {
+			summary = "handles " + ep.method + " " + ep.path
+		}
+		writeComment(&g.out, ep.name, summary)
+		fmt.Fprintf(&g.out, "\t//\n\t// %s %s\n", ep.method, ep.path)
+		fmt.Fprintf(&g.out, "\t%s(%s) error\n", ep.name, strings.Join(handlerArgs(ep), ", "))
+	}
+	g.out.WriteString("}\n\n")
+
+	g.out.WriteString(`// EchoRouter registers routes, it is implemented by *echo.Echo and *echo.Group.
+type EchoRouter interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers registers routes of all operations to the router. Route handlers bind and validate parameters
+// and request body and call the ServerInterface method of the operation. Middleware is applied to all routes.
+func RegisterHandlers(router EchoRouter, si ServerInterface, m ...echo.MiddlewareFunc) {
+	w := &serverWrapper{handler: si}
+`)
+	for _, ep := range endpoints {
+		fmt.Fprintf(&g.out, "\trouter.Add(%s, %q, w.%s, m...)", methodConst(ep.method), echoPath(ep.path), ep.name)
+		if ep.op.Summary != "" || ep.op.Description != "" || len(ep.op.Tags) > 0 {
+			g.out.WriteString(".Doc(echo.RouteDoc{")
+			var fields []string
+			if ep.op.Summary != "" {
+				fields = append(fields, "Summary: "+strconv.Quote(ep.op.Summary))
+			}
+			if ep.op.Description != "" {
+				fields = append(fields, "Description: "+strconv.Quote(ep.op.Description))
+			}
+			if len(ep.op.Tags) > 0 {
+				tags := make([]string, len(ep.op.Tags))
+				for i, t := range ep.op.Tags {
+					tags[i] = strconv.Quote(t)
+				}
+				fields = append(fields, "Tags: []string{"+strings.Join(tags, ", ")+"}")
+			}
+			g.out.WriteString(strings.Join(fields, ", ") + "})")
+		}
+		g.out.WriteString("\n")
+	}
+	g.out.WriteString("}\n\n")
+
+	g.out.WriteString(`// serverWrapper adapts ServerInterface methods to echo.HandlerFunc.
+type serverWrapper struct {
+	handler ServerInterface
+}
+
+`)
+	for _, ep := range endpoints {
+		g.writeWrapper(ep)
+	}
+}
+
+func (g *generator) writeWrapper(ep *endpoint) {
+	fmt.Fprintf(&g.out, "func (w *serverWrapper) %s(c echo.Context) error {\n", ep.name)
+	args := []string{"c"}
+	if len(ep.params) > 0 || ep.body != "" {
+		g.out.WriteString("\tb := &echo.DefaultBinder{}\n")
+	}
+	if len(ep.params) > 0 {
+		in := map[string]bool{}
+		for _, p := range ep.params {
+			in[p.In] = true
+			if p.In == "query" && p.Required {
+				fmt.Fprintf(&g.out, "\tif _, ok := c.QueryParams()[%q]; !ok {\n", p.Name)
+				fmt.Fprintf(&g.out, "\t\treturn echo.NewHTTPError(http.StatusBadRequest, %q)\n\t}\n", `missing query parameter "`+p.Name+`"`)
+			}
+			if p.In == "header" && p.Required {
+				fmt.Fprintf(&g.out, "\tif c.Request().Header.Get(%q) == \"\" {\n", p.Name)
+				fmt.Fprintf(&g.out, "\t\treturn echo.NewHTTPError(http.StatusBadRequest, %q)\n\t}\n", `missing header "`+p.Name+`"`)
+			}
+		}
+		fmt.Fprintf(&g.out, "\tvar params %sParams\n", ep.name)
+		for _, bind := range []struct{ in, method string }{
+			{"path", "BindPathParams"}, {"query", "BindQueryParams"}, {"header", "BindHeaders"},
+		} {
+			if in[bind.in] {
+				fmt.Fprintf(&g.out, "\tif err := b.%s(c, &params); err != nil {\n\t\treturn err\n\t}\n", bind.method)
+			}
+		}
+		g.out.WriteString("\tif err := params.Validate(); err != nil {\n")
+		g.out.WriteString("\t\treturn echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)\n\t}\n")
+		args = append(args, "params")
+	}
+	if ep.body != "" {
+		if ep.op.RequestBody.Required {
+			g.out.WriteString("\tif c.Request().ContentLength == 0 {\n")
+			g.out.WriteString("\t\treturn echo.NewHTTPError(http.StatusBadRequest, \"missing request body\")\n\t}\n")
+		}
+		fmt.Fprintf(&g.out, "\tvar body %s\n", ep.body)
+		g.out.WriteString("\tif err := b.BindBody(c, &body); err != nil {\n\t\treturn err\n\t}\n")
+		if g.named(ep.body) {
+			g.out.WriteString("\tif err := body.Validate(); err != nil {\n")
+			g.out.WriteString("\t\treturn echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)\n\t}\n")
+		}
+		args = append(args, "body")
+	}
+	fmt.Fprintf(&g.out, "\treturn w.handler.%s(%s)\n}\n\n", ep.name, strings.Join(args, ", "))
+}
+
+func handlerArgs(ep *endpoint) []string {
+	args := []string{"c echo.Context"}
+	if len(ep.params) > 0 {
+		args = append(args, "params "+ep.name+"Params")
+	}
+	if ep.body != "" {
+		args = append(args, "body "+ep.body)
+	}
+	return args
+}
+
+// echoPath converts OpenAPI path template to Echo route path: `/users/{id}` -> `/users/:id`.
+func echoPath(p string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(p, '{')
+		j := strings.IndexByte(p, '}')
+		if i < 0 || j < i {
+			b.WriteString(p)
+			return b.String()
+		}
+		b.WriteString(p[:i])
+		b.WriteString(":" + p[i+1:j])
+		p = p[j+1:]
+	}
+}
+
+func methodConst(method string) string {
+	return "http.Method" + method[:1] + strings.ToLower(method[1:])
+}
+
+// goName converts name to exported Go identifier: `user_id` -> `UserID`, `listUsers` -> `ListUsers`.
+func goName(name string) string {
+	var words []string
+	start := -1
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		} else if unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	var b strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit([]rune(s)[0]) {
+		s = "N" + s
+	}
+	return s
+}
+
+func writeComment(w *bytes.Buffer, name, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	prefix := "// "
+	if name != "" {
+		text = name + " " + lowerFirst(text)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(w, "\t%s%s\n", prefix, strings.TrimSpace(line))
+	}
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	if len(r) > 1 && unicode.IsUpper(r[1]) {
+		return s // acronym
+	}
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func indent(b []byte) []byte {
+	lines := bytes.SplitAfter(b, []byte("\n"))
+	out := new(bytes.Buffer)
+	for _, l := range lines {
+		if len(l) > 0 {
+			out.WriteByte('\t')
+			out.Write(l)
+		}
+	}
+	return out.Bytes()
+}
+
+func sortedStatuses(op *operation) []string {
+	statuses := make([]string, 0, len(op.Responses))
+	for status := range op.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+func sortedKeys(m map[string]*schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_Golden checks that generated example package (tested in internal/usersapi) is up to date.
+func TestGenerate_Golden(t *testing.T) {
+	spec, err := ioutil.ReadFile("testdata/users.json")
+	require.NoError(t, err)
+	expect, err := ioutil.ReadFile("internal/usersapi/api.gen.go")
+	require.NoError(t, err)
+
+	code, err := generate(spec, "usersapi")
+	require.NoError(t, err)
+	assert.Equal(t, string(expect), string(code), "regenerate with: go run . -spec testdata/users.json -package usersapi -o internal/usersapi/api.gen.go")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		whenSpec    string
+		expectError string
+	}{
+		{
+			name:        "nok, invalid JSON",
+			whenSpec:    `openapi: 3.0.0`,
+			expectError: "invalid OpenAPI document",
+		},
+		{
+			name:        "nok, no paths",
+			whenSpec:    `{"openapi": "3.0.0"}`,
+			expectError: "document has no paths",
+		},
+		{
+			name:        "nok, duplicate operation name",
+			whenSpec:    `{"paths": {"/a": {"get": {"operationId": "op"}}, "/b": {"get": {"operationId": "op"}}}}`,
+			expectError: "operations GET /a and GET /b have same name Op",
+		},
+		{
+			name:        "nok, path parameter not in path",
+			whenSpec:    `{"paths": {"/a": {"get": {"parameters": [{"name": "id", "in": "path"}]}}}}`,
+			expectError: `path parameter "id" is not in path of operation GetA`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := generate([]byte(tc.whenSpec), "api")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectError)
+		})
+	}
+}
+
+func TestGoName(t *testing.T) {
+	var testCases = []struct {
+		when   string
+		expect string
+	}{
+		{when: "listUsers", expect: "ListUsers"},
+		{when: "user_id", expect: "UserID"},
+		{when: "X-Request-Id", expect: "XRequestID"},
+		{when: "get /users/{id}/api-keys", expect: "GetUsersIDAPIKeys"},
+		{when: "2fa", expect: "N2fa"},
+		{when: "URLs", expect: "URLs"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.when, func(t *testing.T) {
+			assert.Equal(t, tc.expect, goName(tc.when))
+		})
+	}
+}
+
+func TestEchoPath(t *testing.T) {
+	assert.Equal(t, "/users/:id/keys/:key_id", echoPath("/users/{id}/keys/{key_id}"))
+	assert.Equal(t, "/users", echoPath("/users"))
+}
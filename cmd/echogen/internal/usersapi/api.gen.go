@@ -0,0 +1,245 @@
+// Code generated by echogen. DO NOT EDIT.
+
+package usersapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListUsersParams are parameters of GET /users.
+type ListUsersParams struct {
+	Limit     *int32 `query:"limit"`
+	Role      Role   `query:"role"`
+	XTenantID string `header:"X-Tenant-Id"`
+}
+
+// Validate checks constraints of ListUsersParams declared in OpenAPI document.
+func (v ListUsersParams) Validate() error {
+	if v.Limit != nil {
+		if *v.Limit < 1 {
+			return errors.New("query parameter limit: must be at least 1")
+		}
+		if *v.Limit > 100 {
+			return errors.New("query parameter limit: must be at most 100")
+		}
+	}
+	if err := v.Role.Validate(); err != nil {
+		return fmt.Errorf("query parameter role: %w", err)
+	}
+	return nil
+}
+
+// GetUsersUserIDParams are parameters of GET /users/{user_id}.
+type GetUsersUserIDParams struct {
+	UserID int64 `param:"user_id"`
+}
+
+// Validate checks constraints of GetUsersUserIDParams declared in OpenAPI document.
+func (v GetUsersUserIDParams) Validate() error {
+	return nil
+}
+
+// DeleteUserParams are parameters of DELETE /users/{user_id}.
+type DeleteUserParams struct {
+	UserID int64 `param:"user_id"`
+}
+
+// Validate checks constraints of DeleteUserParams declared in OpenAPI document.
+func (v DeleteUserParams) Validate() error {
+	return nil
+}
+
+// ServerInterface is implemented by handlers of operations of Users API.
+type ServerInterface interface {
+	// ListUsers lists users
+	//
+	// GET /users
+	ListUsers(c echo.Context, params ListUsersParams) error
+	// CreateUser creates user
+	//
+	// POST /users
+	CreateUser(c echo.Context, body CreateUserRequest) error
+	// GetUsersUserID handles GET /users/{user_id}
+	//
+	// GET /users/{user_id}
+	GetUsersUserID(c echo.Context, params GetUsersUserIDParams) error
+	// DeleteUser handles DELETE /users/{user_id}
+	//
+	// DELETE /users/{user_id}
+	DeleteUser(c echo.Context, params DeleteUserParams) error
+}
+
+// EchoRouter registers routes, it is implemented by *echo.Echo and *echo.Group.
+type EchoRouter interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers registers routes of all operations to the router. Route handlers bind and validate parameters
+// and request body and call the ServerInterface method of the operation. Middleware is applied to all routes.
+func RegisterHandlers(router EchoRouter, si ServerInterface, m ...echo.MiddlewareFunc) {
+	w := &serverWrapper{handler: si}
+	router.Add(http.MethodGet, "/users", w.ListUsers, m...).Doc(echo.RouteDoc{Summary: "Lists users", Tags: []string{"users"}})
+	router.Add(http.MethodPost, "/users", w.CreateUser, m...).Doc(echo.RouteDoc{Summary: "Creates user"})
+	router.Add(http.MethodGet, "/users/:user_id", w.GetUsersUserID, m...)
+	router.Add(http.MethodDelete, "/users/:user_id", w.DeleteUser, m...)
+}
+
+// serverWrapper adapts ServerInterface methods to echo.HandlerFunc.
+type serverWrapper struct {
+	handler ServerInterface
+}
+
+func (w *serverWrapper) ListUsers(c echo.Context) error {
+	b := &echo.DefaultBinder{}
+	if _, ok := c.QueryParams()["role"]; !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing query parameter \"role\"")
+	}
+	if c.Request().Header.Get("X-Tenant-ID") == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing header \"X-Tenant-ID\"")
+	}
+	var params ListUsersParams
+	if err := b.BindQueryParams(c, &params); err != nil {
+		return err
+	}
+	if err := b.BindHeaders(c, &params); err != nil {
+		return err
+	}
+	if err := params.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return w.handler.ListUsers(c, params)
+}
+
+func (w *serverWrapper) CreateUser(c echo.Context) error {
+	b := &echo.DefaultBinder{}
+	if c.Request().ContentLength == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing request body")
+	}
+	var body CreateUserRequest
+	if err := b.BindBody(c, &body); err != nil {
+		return err
+	}
+	if err := body.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return w.handler.CreateUser(c, body)
+}
+
+func (w *serverWrapper) GetUsersUserID(c echo.Context) error {
+	b := &echo.DefaultBinder{}
+	var params GetUsersUserIDParams
+	if err := b.BindPathParams(c, &params); err != nil {
+		return err
+	}
+	if err := params.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return w.handler.GetUsersUserID(c, params)
+}
+
+func (w *serverWrapper) DeleteUser(c echo.Context) error {
+	b := &echo.DefaultBinder{}
+	var params DeleteUserParams
+	if err := b.BindPathParams(c, &params); err != nil {
+		return err
+	}
+	if err := params.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return w.handler.DeleteUser(c, params)
+}
+
+// Role is generated from OpenAPI schema.
+type Role string
+
+// Validate checks constraints of Role declared in OpenAPI document.
+func (v Role) Validate() error {
+	switch string(v) {
+	case "admin", "member":
+	default:
+		return errors.New("Role: must be one of admin, member")
+	}
+	return nil
+}
+
+// User of the application.
+type User struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Role      *Role      `json:"role,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+}
+
+// Validate checks constraints of User declared in OpenAPI document.
+func (v User) Validate() error {
+	if v.Role != nil {
+		if err := v.Role.Validate(); err != nil {
+			return fmt.Errorf("role: %w", err)
+		}
+	}
+	if len(v.Tags) > 3 {
+		return errors.New("tags: must have at most 3 items")
+	}
+	return nil
+}
+
+// CreateUserRequest is generated from OpenAPI schema.
+type CreateUserRequest struct {
+	Address *CreateUserRequestAddress `json:"address,omitempty"`
+	Name    string                    `json:"name"`
+	Role    *Role                     `json:"role,omitempty"`
+}
+
+// Validate checks constraints of CreateUserRequest declared in OpenAPI document.
+func (v CreateUserRequest) Validate() error {
+	if v.Address != nil {
+		if err := v.Address.Validate(); err != nil {
+			return fmt.Errorf("address: %w", err)
+		}
+	}
+	if utf8.RuneCountInString(v.Name) < 1 {
+		return errors.New("name: must be at least 1 characters long")
+	}
+	if utf8.RuneCountInString(v.Name) > 20 {
+		return errors.New("name: must be at most 20 characters long")
+	}
+	if v.Role != nil {
+		if err := v.Role.Validate(); err != nil {
+			return fmt.Errorf("role: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateUserDefaultResponse is generated from OpenAPI schema.
+type CreateUserDefaultResponse struct {
+	Message *string `json:"message,omitempty"`
+}
+
+// Validate checks constraints of CreateUserDefaultResponse declared in OpenAPI document.
+func (v CreateUserDefaultResponse) Validate() error {
+	return nil
+}
+
+// CreateUserRequestAddress is generated from OpenAPI schema.
+type CreateUserRequestAddress struct {
+	City *string `json:"city,omitempty"`
+	Zip  *string `json:"zip,omitempty"`
+}
+
+// Validate checks constraints of CreateUserRequestAddress declared in OpenAPI document.
+func (v CreateUserRequestAddress) Validate() error {
+	if v.Zip != nil {
+		if utf8.RuneCountInString(*v.Zip) > 5 {
+			return errors.New("zip: must be at most 5 characters long")
+		}
+	}
+	return nil
+}
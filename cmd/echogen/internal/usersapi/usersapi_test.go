@@ -0,0 +1,165 @@
+package usersapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type server struct {
+	listParams ListUsersParams
+	created    CreateUserRequest
+	userID     int64
+}
+
+func (s *server) ListUsers(c echo.Context, params ListUsersParams) error {
+	s.listParams = params
+	return c.JSON(http.StatusOK, []User{})
+}
+
+func (s *server) CreateUser(c echo.Context, body CreateUserRequest) error {
+	s.created = body
+	return c.JSON(http.StatusCreated, User{ID: 1, Name: body.Name, Role: body.Role})
+}
+
+func (s *server) GetUsersUserID(c echo.Context, params GetUsersUserIDParams) error {
+	s.userID = params.UserID
+	return c.JSON(http.StatusOK, User{ID: params.UserID})
+}
+
+func (s *server) DeleteUser(c echo.Context, params DeleteUserParams) error {
+	return c.NoContent(http.StatusNoContent)
+}
+
+func TestRegisterHandlers(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		whenMethod  string
+		whenURL     string
+		whenHeader  map[string]string
+		whenBody    string
+		expectCode  int
+		expectError string
+	}{
+		{
+			name:       "ok, query and header params",
+			whenMethod: http.MethodGet,
+			whenURL:    "/users?limit=10&role=admin",
+			whenHeader: map[string]string{"X-Tenant-ID": "acme"},
+			expectCode: http.StatusOK,
+		},
+		{
+			name:        "nok, missing required query param",
+			whenMethod:  http.MethodGet,
+			whenURL:     "/users",
+			whenHeader:  map[string]string{"X-Tenant-ID": "acme"},
+			expectCode:  http.StatusBadRequest,
+			expectError: `missing query parameter \"role\"`,
+		},
+		{
+			name:        "nok, enum",
+			whenMethod:  http.MethodGet,
+			whenURL:     "/users?role=root",
+			whenHeader:  map[string]string{"X-Tenant-ID": "acme"},
+			expectCode:  http.StatusBadRequest,
+			expectError: "query parameter role: Role: must be one of admin, member",
+		},
+		{
+			name:        "nok, maximum",
+			whenMethod:  http.MethodGet,
+			whenURL:     "/users?role=admin&limit=1000",
+			whenHeader:  map[string]string{"X-Tenant-ID": "acme"},
+			expectCode:  http.StatusBadRequest,
+			expectError: "query parameter limit: must be at most 100",
+		},
+		{
+			name:        "nok, missing header",
+			whenMethod:  http.MethodGet,
+			whenURL:     "/users?role=admin",
+			expectCode:  http.StatusBadRequest,
+			expectError: `missing header \"X-Tenant-ID\"`,
+		},
+		{
+			name:       "ok, body",
+			whenMethod: http.MethodPost,
+			whenURL:    "/users",
+			whenBody:   `{"name":"Jon","role":"member","address":{"zip":"12345"}}`,
+			expectCode: http.StatusCreated,
+		},
+		{
+			name:        "nok, nested body validation",
+			whenMethod:  http.MethodPost,
+			whenURL:     "/users",
+			whenBody:    `{"name":"Jon","address":{"zip":"123456"}}`,
+			expectCode:  http.StatusBadRequest,
+			expectError: "address: zip: must be at most 5 characters long",
+		},
+		{
+			name:        "nok, missing body",
+			whenMethod:  http.MethodPost,
+			whenURL:     "/users",
+			expectCode:  http.StatusBadRequest,
+			expectError: "missing request body",
+		},
+		{
+			name:       "ok, path param",
+			whenMethod: http.MethodGet,
+			whenURL:    "/users/42",
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "nok, path param type",
+			whenMethod: http.MethodGet,
+			whenURL:    "/users/jon",
+			expectCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			s := &server{}
+			RegisterHandlers(e, s)
+
+			req := httptest.NewRequest(tc.whenMethod, tc.whenURL, strings.NewReader(tc.whenBody))
+			if tc.whenBody != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			for k, v := range tc.whenHeader {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectError != "" {
+				assert.Contains(t, rec.Body.String(), tc.expectError)
+			}
+		})
+	}
+}
+
+func TestRegisterHandlers_Values(t *testing.T) {
+	e := echo.New()
+	s := &server{}
+	RegisterHandlers(e.Group("/api"), s)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users?limit=10&role=admin", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if assert.NotNil(t, s.listParams.Limit) {
+		assert.Equal(t, int32(10), *s.listParams.Limit)
+	}
+	assert.Equal(t, Role("admin"), s.listParams.Role)
+	assert.Equal(t, "acme", s.listParams.XTenantID)
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+	assert.Equal(t, int64(42), s.userID)
+
+	doc := echo.RouteDocs(e)
+	assert.Equal(t, "Lists users", doc[0].Summary)
+}
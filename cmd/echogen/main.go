@@ -0,0 +1,66 @@
+/*
+Command echogen generates Echo server code from OpenAPI 3 document in JSON format.
+
+Generated file contains:
+  - structs of schemas of `components.schemas` and of inline request and response bodies,
+  - `<Operation>Params` structs of path, query and header parameters of operations,
+  - `Validate()` methods checking enum, length and range constraints of the schemas,
+  - `ServerInterface` with typed method for every operation,
+  - `RegisterHandlers()` registering routes which bind and validate parameters and request body before calling
+    `ServerInterface` methods.
+
+Usage:
+
+	echogen -spec openapi.json -package api -o api.gen.go
+
+or with `go:generate` directive:
+
+	//go:generate go run github.com/labstack/echo/v4/cmd/echogen -spec openapi.json -package api -o api.gen.go
+
+Generated handlers are registered with:
+
+	api.RegisterHandlers(e, &server{})
+
+Operations are named by `operationId` or, when missing, by method and path. Only JSON request and response bodies
+are supported. YAML documents have to be converted to JSON first.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	spec := flag.String("spec", "", "path of OpenAPI 3 document in JSON format")
+	pkg := flag.String("package", "api", "package name of generated code")
+	out := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	if *spec == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*spec, *pkg, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "echogen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(spec, pkg, out string) error {
+	data, err := ioutil.ReadFile(spec)
+	if err != nil {
+		return err
+	}
+	code, err := generate(data, pkg)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return ioutil.WriteFile(out, code, 0644)
+}
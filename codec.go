@@ -0,0 +1,108 @@
+package echo
+
+import (
+	"errors"
+	"strings"
+)
+
+type (
+	// Codec serializes and deserializes request and response bodies of a media type. Every JSONSerializer and
+	// XMLSerializer is also a Codec.
+	Codec interface {
+		Serialize(c Context, i interface{}, indent string) error
+		Deserialize(c Context, i interface{}) error
+	}
+
+	// jsonCodec delegates to `Echo#JSONSerializer`.
+	jsonCodec struct{}
+
+	// xmlCodec delegates to `Echo#XMLSerializer`.
+	xmlCodec struct{}
+)
+
+// ErrCodecNotRegistered denotes an error raised when no codec is registered for the media type.
+var ErrCodecNotRegistered = errors.New("codec not registered")
+
+// RegisterCodec registers codec used by `DefaultBinder` to bind request bodies and by `Encode()` to render
+// responses of the media type. Media type is either full type (`application/vnd.foo+json`) or structured syntax
+// suffix (`+cbor`) matching all media types with the suffix. Codecs for JSON (`application/json`, `+json`) and
+// XML (`application/xml`, `text/xml`, `+xml`) using `Echo#JSONSerializer` and `Echo#XMLSerializer` are built in
+// and can be replaced.
+//
+// Example:
+//
+//	e.RegisterCodec("application/vnd.api+json", jsonAPICodec{})
+//	e.RegisterCodec("+cbor", cborCodec{})
+func (e *Echo) RegisterCodec(mediaType string, codec Codec) {
+	if e.codecs == nil {
+		e.codecs = map[string]Codec{}
+	}
+	e.codecs[strings.ToLower(mediaType)] = codec
+}
+
+// Codec returns codec for the media type (parameters like `charset` are ignored) or nil when no codec is
+// registered. Registered codecs are matched by full media type first and then by structured syntax suffix.
+func (e *Echo) Codec(mediaType string) Codec {
+	mediaType = baseMediaType(mediaType)
+	if mediaType == "" {
+		return nil
+	}
+	if codec, ok := e.codecs[mediaType]; ok {
+		return codec
+	}
+	suffix := ""
+	if i := strings.LastIndexByte(mediaType, '+'); i > 0 {
+		suffix = mediaType[i:]
+		if codec, ok := e.codecs[suffix]; ok {
+			return codec
+		}
+	}
+	switch {
+	case mediaType == MIMEApplicationJSON, suffix == "+json":
+		return jsonCodec{}
+	case mediaType == MIMEApplicationXML, mediaType == MIMETextXML, suffix == "+xml":
+		return xmlCodec{}
+	}
+	return nil
+}
+
+// Encode sends a response with status code and content type serialized by codec registered for the content
+// type (see `Echo#RegisterCodec()`). Returns ErrCodecNotRegistered when there is no such codec.
+func Encode(c Context, code int, contentType string, i interface{}) error {
+	codec := c.Echo().Codec(contentType)
+	if codec == nil {
+		return ErrCodecNotRegistered
+	}
+	defer trackRender(c)()
+	c.Response().Header().Set(HeaderContentType, contentType)
+	c.Response().Status = code
+	indent := ""
+	if c.Echo().Debug || prettyQueryParam(c) {
+		indent = defaultIndent
+	}
+	return codec.Serialize(c, i, indent)
+}
+
+// baseMediaType returns lower case media type without parameters.
+func baseMediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func (jsonCodec) Serialize(c Context, i interface{}, indent string) error {
+	return c.Echo().JSONSerializer.Serialize(c, i, indent)
+}
+
+func (jsonCodec) Deserialize(c Context, i interface{}) error {
+	return c.Echo().JSONSerializer.Deserialize(c, i)
+}
+
+func (xmlCodec) Serialize(c Context, i interface{}, indent string) error {
+	return c.Echo().XMLSerializer.Serialize(c, i, indent)
+}
+
+func (xmlCodec) Deserialize(c Context, i interface{}) error {
+	return c.Echo().XMLSerializer.Deserialize(c, i)
+}
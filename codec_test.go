@@ -0,0 +1,98 @@
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// upperCodec is test codec serializing strings in upper case.
+type upperCodec struct{}
+
+func (upperCodec) Serialize(c Context, i interface{}, indent string) error {
+	_, err := c.Response().Write([]byte(strings.ToUpper(i.(string))))
+	return err
+}
+
+func (upperCodec) Deserialize(c Context, i interface{}) error {
+	return json.NewDecoder(c.Request().Body).Decode(i)
+}
+
+func TestEcho_Codec(t *testing.T) {
+	e := New()
+	e.RegisterCodec("application/vnd.upper+json", upperCodec{})
+	e.RegisterCodec("+upper", upperCodec{})
+
+	assert.Equal(t, upperCodec{}, e.Codec("application/vnd.upper+json; charset=utf-8"))
+	assert.Equal(t, upperCodec{}, e.Codec("Application/Vnd.Upper+JSON"))
+	assert.Equal(t, upperCodec{}, e.Codec("text/plain+upper"))
+	assert.Equal(t, jsonCodec{}, e.Codec(MIMEApplicationJSONCharsetUTF8))
+	assert.Equal(t, jsonCodec{}, e.Codec("application/problem+json"))
+	assert.Equal(t, xmlCodec{}, e.Codec(MIMETextXML))
+	assert.Equal(t, xmlCodec{}, e.Codec("application/atom+xml"))
+	assert.Nil(t, e.Codec("text/plain"))
+	assert.Nil(t, e.Codec(""))
+
+	e.RegisterCodec(MIMEApplicationJSON, upperCodec{})
+	assert.Equal(t, upperCodec{}, e.Codec(MIMEApplicationJSON))
+}
+
+func TestDefaultBinder_BindBodyWithCodec(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id" xml:"id"`
+		Name string `json:"name" xml:"name"`
+	}
+	e := New()
+	e.RegisterCodec("+upper", upperCodec{})
+
+	var testCases = []struct {
+		name        string
+		contentType string
+		body        string
+		expectUser  user
+		expectError error
+	}{
+		{name: "registered suffix", contentType: "application/vnd.user+upper", body: `{"id":1,"name":"Jon"}`, expectUser: user{ID: 1, Name: "Jon"}},
+		{name: "json suffix", contentType: "application/vnd.user+json", body: `{"id":2,"name":"Arya"}`, expectUser: user{ID: 2, Name: "Arya"}},
+		{name: "xml suffix", contentType: "application/vnd.user+xml", body: `<user><id>3</id><name>Sansa</name></user>`, expectUser: user{ID: 3, Name: "Sansa"}},
+		{name: "unsupported", contentType: "application/vnd.user+yaml", body: `id: 1`, expectError: ErrUnsupportedMediaType},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			req.Header.Set(HeaderContentType, tc.contentType)
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			u := user{}
+			err := new(DefaultBinder).BindBody(c, &u)
+
+			assert.Equal(t, tc.expectError, err)
+			assert.Equal(t, tc.expectUser, u)
+		})
+	}
+}
+
+func TestContext_Encode(t *testing.T) {
+	e := New()
+	e.RegisterCodec("text/vnd.upper", upperCodec{})
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	assert.NoError(t, Encode(c, http.StatusCreated, "text/vnd.upper", "hello"))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "text/vnd.upper", rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "HELLO", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	assert.NoError(t, Encode(c, http.StatusOK, "application/problem+json", Map{"title": "Not found"}))
+	assert.Equal(t, "application/problem+json", rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `{"title":"Not found"}`+"\n", rec.Body.String())
+
+	c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Equal(t, ErrCodecNotRegistered, Encode(c, http.StatusOK, "text/plain", "x"))
+}
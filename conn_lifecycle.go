@@ -0,0 +1,63 @@
+package echo
+
+import (
+	stdContext "context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// ConnLifecycleConfig defines when Echo asks clients to close their connections. Closing is requested with
+	// `Connection: close` response header, which HTTP/2 server turns into GOAWAY frame. Useful behind load
+	// balancers that need connections to be cycled between instances.
+	ConnLifecycleConfig struct {
+		// DisableKeepAlivesOnShutdown disables keep-alives when `Echo#Shutdown()` is called so connections are
+		// closed after their in-flight requests complete.
+		DisableKeepAlivesOnShutdown bool
+
+		// MaxRequestsPerConn is number of requests after which connection is closed.
+		// Zero value means no limit.
+		MaxRequestsPerConn int64
+
+		// MaxConnAge is age after which connection is closed once its next request is served. Age is measured
+		// with `Echo#Clock`.
+		// Zero value means no limit.
+		MaxConnAge time.Duration
+	}
+
+	connInfo struct {
+		requests int64
+		created  time.Time
+	}
+
+	connInfoContextKey struct{}
+)
+
+func (c ConnLifecycleConfig) enabled() bool {
+	return c.MaxRequestsPerConn > 0 || c.MaxConnAge > 0
+}
+
+func (e *Echo) withConnInfo(ctx stdContext.Context) stdContext.Context {
+	return stdContext.WithValue(ctx, connInfoContextKey{}, &connInfo{created: e.Now()})
+}
+
+// shouldCloseConn reports whether connection of the request should be closed after the response.
+func (e *Echo) shouldCloseConn(r *http.Request) bool {
+	if atomic.LoadInt32(&e.shuttingDown) == 1 {
+		return true
+	}
+	config := e.ConnLifecycle
+	if !config.enabled() {
+		return false
+	}
+	info, ok := r.Context().Value(connInfoContextKey{}).(*connInfo)
+	if !ok {
+		return false
+	}
+	n := atomic.AddInt64(&info.requests, 1)
+	if config.MaxRequestsPerConn > 0 && n >= config.MaxRequestsPerConn {
+		return true
+	}
+	return config.MaxConnAge > 0 && e.Now().Sub(info.created) >= config.MaxConnAge
+}
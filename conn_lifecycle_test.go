@@ -0,0 +1,99 @@
+package echo
+
+import (
+	stdContext "context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func startLifecycleTestServer(t *testing.T, e *Echo) string {
+	e.HideBanner = true
+	e.HidePort = true
+	e.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.Start(":0")
+	}()
+	assert.NoError(t, waitForServerStart(e, errCh, false))
+	return "http://" + e.ListenerAddr().String() + "/"
+}
+
+func TestEcho_ConnLifecycle_MaxRequestsPerConn(t *testing.T) {
+	e := New()
+	e.ConnLifecycle.MaxRequestsPerConn = 2
+	url := startLifecycleTestServer(t, e)
+	defer e.Close()
+
+	client := &http.Client{Transport: &http.Transport{}}
+	closed := []bool{}
+	for i := 0; i < 3; i++ {
+		res, err := client.Get(url)
+		if assert.NoError(t, err) {
+			ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			closed = append(closed, res.Close)
+		}
+	}
+	assert.Equal(t, []bool{false, true, false}, closed)
+	assert.Equal(t, uint64(2), e.ConnStats().Total)
+}
+
+func TestEcho_ConnLifecycle_MaxConnAge(t *testing.T) {
+	e := New()
+	clock := &testClock{now: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	e.Clock = clock
+	e.ConnLifecycle.MaxConnAge = time.Minute
+	url := startLifecycleTestServer(t, e)
+	defer e.Close()
+
+	client := &http.Client{Transport: &http.Transport{}}
+	get := func() bool {
+		res, err := client.Get(url)
+		if !assert.NoError(t, err) {
+			return false
+		}
+		ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return res.Close
+	}
+
+	assert.False(t, get())
+	clock.now = clock.now.Add(30 * time.Second)
+	assert.False(t, get())
+	clock.now = clock.now.Add(30 * time.Second)
+	assert.True(t, get())
+}
+
+func TestEcho_ConnLifecycle_DisableKeepAlivesOnShutdown(t *testing.T) {
+	e := New()
+	e.ConnLifecycle.DisableKeepAlivesOnShutdown = true
+	e.GET("/", func(c Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get(HeaderConnection))
+
+	assert.NoError(t, e.Shutdown(stdContext.Background()))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, "close", rec.Header().Get(HeaderConnection))
+}
@@ -0,0 +1,139 @@
+package echo
+
+import (
+	stdContext "context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// ConnStats contains connection counters of servers started by Echo. New, Active and Idle are current
+	// number of connections in given `http.ConnState`, other fields are totals since start.
+	ConnStats struct {
+		New      int64  `json:"new"`
+		Active   int64  `json:"active"`
+		Idle     int64  `json:"idle"`
+		Hijacked uint64 `json:"hijacked"`
+		Total    uint64 `json:"total"`
+		Rejected uint64 `json:"rejected"`
+	}
+
+	connTracker struct {
+		// counters are first to keep them 64-bit aligned for atomic access on 32-bit platforms
+		hijacked uint64
+		total    uint64
+		rejected uint64
+		new      int64
+		active   int64
+		idle     int64
+		states   sync.Map // net.Conn => http.ConnState
+		servers  map[*http.Server]bool
+	}
+
+	connRejectedContextKey struct{}
+)
+
+// Open returns number of connections that are currently open (not closed nor hijacked).
+func (s ConnStats) Open() int64 {
+	return s.New + s.Active + s.Idle
+}
+
+// ConnStats returns connection counters collected from `http.Server.ConnState` of servers started by Echo.
+func (e *Echo) ConnStats() ConnStats {
+	t := &e.conns
+	return ConnStats{
+		New:      atomic.LoadInt64(&t.new),
+		Active:   atomic.LoadInt64(&t.active),
+		Idle:     atomic.LoadInt64(&t.idle),
+		Hijacked: atomic.LoadUint64(&t.hijacked),
+		Total:    atomic.LoadUint64(&t.total),
+		Rejected: atomic.LoadUint64(&t.rejected),
+	}
+}
+
+// trackConnections installs connection tracking hooks to the server. Hooks already set on the server are still
+// called. Must be called with startupMutex locked.
+func (e *Echo) trackConnections(s *http.Server) {
+	t := &e.conns
+	if t.servers == nil {
+		t.servers = map[*http.Server]bool{}
+	}
+	if t.servers[s] {
+		return
+	}
+	t.servers[s] = true
+
+	connState := s.ConnState
+	s.ConnState = func(c net.Conn, state http.ConnState) {
+		t.connState(c, state)
+		if connState != nil {
+			connState(c, state)
+		}
+	}
+	connContext := s.ConnContext
+	s.ConnContext = func(ctx stdContext.Context, c net.Conn) stdContext.Context {
+		if connContext != nil {
+			ctx = connContext(ctx, c)
+		}
+		if e.ConnLifecycle.enabled() {
+			ctx = e.withConnInfo(ctx)
+		}
+		// connection is not counted yet as ConnContext is called before ConnState with http.StateNew
+		if e.MaxConnections > 0 && t.open() >= int64(e.MaxConnections) {
+			atomic.AddUint64(&t.rejected, 1)
+			return stdContext.WithValue(ctx, connRejectedContextKey{}, true)
+		}
+		return ctx
+	}
+}
+
+func (t *connTracker) open() int64 {
+	return atomic.LoadInt64(&t.new) + atomic.LoadInt64(&t.active) + atomic.LoadInt64(&t.idle)
+}
+
+func (t *connTracker) connState(c net.Conn, state http.ConnState) {
+	if prev, ok := t.states.Load(c); ok {
+		t.add(prev.(http.ConnState), -1)
+	}
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		t.states.Delete(c)
+		if state == http.StateHijacked {
+			atomic.AddUint64(&t.hijacked, 1)
+		}
+	default:
+		if state == http.StateNew {
+			atomic.AddUint64(&t.total, 1)
+		}
+		t.states.Store(c, state)
+		t.add(state, 1)
+	}
+}
+
+func (t *connTracker) add(state http.ConnState, delta int64) {
+	switch state {
+	case http.StateNew:
+		atomic.AddInt64(&t.new, delta)
+	case http.StateActive:
+		atomic.AddInt64(&t.active, delta)
+	case http.StateIdle:
+		atomic.AddInt64(&t.idle, delta)
+	}
+}
+
+// isConnRejected reports whether request came over connection accepted over `Echo#MaxConnections` limit.
+func isConnRejected(r *http.Request) bool {
+	rejected, _ := r.Context().Value(connRejectedContextKey{}).(bool)
+	return rejected
+}
+
+// serveConnRejected responds with 503 to request over connection that exceeded connection limit and asks
+// client to close the connection.
+func (e *Echo) serveConnRejected(c *context) {
+	if c.request.ProtoMajor == 1 {
+		c.response.Header().Set(HeaderConnection, "close")
+	}
+	e.HTTPErrorHandler(ErrServiceUnavailable, c)
+}
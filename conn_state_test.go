@@ -0,0 +1,86 @@
+package echo
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnTracker_connState(t *testing.T) {
+	tracker := &connTracker{}
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	tracker.connState(c1, http.StateNew)
+	tracker.connState(c2, http.StateNew)
+	tracker.connState(c1, http.StateActive)
+	tracker.connState(c1, http.StateIdle)
+	tracker.connState(c2, http.StateActive)
+	tracker.connState(c2, http.StateHijacked)
+
+	assert.Equal(t, int64(1), tracker.open())
+	assert.Equal(t, int64(0), tracker.new)
+	assert.Equal(t, int64(1), tracker.idle)
+	assert.Equal(t, uint64(1), tracker.hijacked)
+	assert.Equal(t, uint64(2), tracker.total)
+
+	tracker.connState(c1, http.StateClosed)
+	assert.Equal(t, int64(0), tracker.open())
+}
+
+func TestEcho_MaxConnections(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.MaxConnections = 1
+	e.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.Start(":0")
+	}()
+	assert.NoError(t, waitForServerStart(e, errCh, false))
+	defer e.Close()
+	url := "http://" + e.ListenerAddr().String() + "/"
+
+	get := func(client *http.Client) (int, string, bool) {
+		res, err := client.Get(url)
+		if !assert.NoError(t, err) {
+			return 0, "", false
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		return res.StatusCode, string(body), res.Close
+	}
+
+	// first client keeps its connection open (idle)
+	client1 := &http.Client{Transport: &http.Transport{}}
+	code, body, _ := get(client1)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "OK", body)
+
+	client2 := &http.Client{Transport: &http.Transport{}}
+	code, body, closed := get(client2)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "{\"message\":\"Service Unavailable\"}\n", body)
+	assert.True(t, closed)
+
+	stats := e.ConnStats()
+	assert.Equal(t, uint64(1), stats.Rejected)
+	assert.Equal(t, uint64(2), stats.Total)
+
+	// after first connection is closed new connections are accepted again
+	client1.Transport.(*http.Transport).CloseIdleConnections()
+	assert.Eventually(t, func() bool {
+		return e.ConnStats().Open() == 0
+	}, time.Second, 5*time.Millisecond)
+	code, _, _ = get(client2)
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,338 @@
+/*
+Package consent implements cookie consent (GDPR / ePrivacy) helpers. Purposes the user agreed to are stored in a
+compact cookie, Manager middleware makes them available to handlers and templates and drops cookies of purposes
+without consent from responses. Middlewares setting non-essential cookies or tracking users (analytics) are gated
+with `Manager#Require()`.
+
+Example:
+
+	consents := consent.New(consent.Config{
+		Purposes: []consent.Purpose{consent.Preferences, consent.Analytics},
+		Cookies:  map[string]consent.Purpose{"lang": consent.Preferences, "_ga": consent.Analytics},
+	})
+	e.Use(consents.Middleware())
+	e.Use(consents.Require(consent.Analytics, analyticsMiddleware))
+
+	e.POST("/consent", func(c echo.Context) error {
+		return consents.Save(c, consent.Purpose(c.FormValue("purpose")))
+	})
+	e.GET("/", func(c echo.Context) error {
+		return c.Render(http.StatusOK, "index", consent.FromContext(c)) // {{if not .Given}} banner {{end}}
+	})
+*/
+package consent
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Purpose is a purpose of processing user may consent to.
+	Purpose string
+
+	// Config defines the config for consent Manager.
+	Config struct {
+		// Purposes user may consent to, in stable order, cookie stores them as bits by index. Add new purposes to
+		// the end, changing order or removing purposes requires new Version. Necessary is always granted and must
+		// not be listed.
+		// Required.
+		Purposes []Purpose
+
+		// Version of the consent policy. Consent given for another version is not valid so user is asked again.
+		// Optional. Default value 1.
+		Version int
+
+		// Cookies maps names of non-essential cookies set by the application to their purposes. Cookies of
+		// purposes without consent are removed from responses. Cookies not listed are considered necessary.
+		// Optional.
+		Cookies map[string]Purpose
+
+		// CookieName is name of the consent cookie.
+		// Optional. Default value "consent".
+		CookieName string `yaml:"cookie_name"`
+
+		// CookieDomain is domain of the consent cookie.
+		// Optional. Default value none.
+		CookieDomain string `yaml:"cookie_domain"`
+
+		// CookiePath is path of the consent cookie.
+		// Optional. Default value "/".
+		CookiePath string `yaml:"cookie_path"`
+
+		// CookieMaxAge is how long consent is remembered, user is asked again after it.
+		// Optional. Default value 180 days.
+		CookieMaxAge time.Duration `yaml:"cookie_max_age"`
+
+		// CookieSecure indicates if consent cookie is sent only over HTTPS.
+		// Optional. Default value false.
+		CookieSecure bool `yaml:"cookie_secure"`
+
+		// ContextKey is key under which `*State` is stored in context.
+		// Optional. Default value "consent".
+		ContextKey string
+	}
+
+	// State is consent of the request's user.
+	State struct {
+		// Version is policy version the consent was given for.
+		Version int
+		// Purposes granted by the user, without Necessary.
+		Purposes []Purpose
+		// GivenAt is time consent was given, zero when user has not decided yet.
+		GivenAt time.Time
+	}
+
+	// Manager reads and stores consent and gates cookies and middlewares.
+	Manager struct {
+		config Config
+	}
+)
+
+// Purposes
+const (
+	// Necessary are cookies required for the site to work (session, CSRF, consent itself). They do not require
+	// consent.
+	Necessary   Purpose = "necessary"
+	Preferences Purpose = "preferences"
+	Analytics   Purpose = "analytics"
+	Marketing   Purpose = "marketing"
+)
+
+// DefaultConfig is the default consent Manager config.
+var DefaultConfig = Config{
+	Version:      1,
+	CookieName:   "consent",
+	CookiePath:   "/",
+	CookieMaxAge: 180 * 24 * time.Hour,
+	ContextKey:   "consent",
+}
+
+// New creates new consent Manager with config.
+func New(config Config) *Manager {
+	// Defaults
+	if len(config.Purposes) == 0 {
+		panic("echo: consent requires purposes")
+	}
+	if len(config.Purposes) > 62 {
+		panic("echo: consent supports at most 62 purposes")
+	}
+	for _, p := range config.Purposes {
+		if p == Necessary || p == "" {
+			panic("echo: consent purposes must not contain necessary or empty purpose")
+		}
+	}
+	if config.Version <= 0 {
+		config.Version = DefaultConfig.Version
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultConfig.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultConfig.CookiePath
+	}
+	if config.CookieMaxAge <= 0 {
+		config.CookieMaxAge = DefaultConfig.CookieMaxAge
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultConfig.ContextKey
+	}
+	return &Manager{config: config}
+}
+
+// Middleware returns a middleware which stores consent of the request as `*State` in context and removes cookies
+// of purposes without consent from the response.
+func (m *Manager) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(m.config.ContextKey, m.read(c))
+			if len(m.config.Cookies) > 0 {
+				c.Response().Before(func() {
+					m.filterCookies(c)
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// Require returns a middleware which runs mw only for requests with consent to the purpose, other requests skip
+// mw and continue to the next handler.
+func (m *Manager) Require(purpose Purpose, mw echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		gated := mw(next)
+		return func(c echo.Context) error {
+			if m.State(c).Granted(purpose) {
+				return gated(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// State returns consent of the request. It is read from the cookie when Middleware was not used.
+func (m *Manager) State(c echo.Context) *State {
+	if s, ok := c.Get(m.config.ContextKey).(*State); ok {
+		return s
+	}
+	return m.read(c)
+}
+
+// Save stores consent to the purposes (all other purposes are denied) in the consent cookie and updates state of
+// the request. Unknown purposes are ignored.
+func (m *Manager) Save(c echo.Context, purposes ...Purpose) error {
+	var mask uint64
+	for _, p := range purposes {
+		if i := m.index(p); i >= 0 {
+			mask |= 1 << uint(i)
+		}
+	}
+	givenAt := echo.Now(c)
+	state := m.state(mask, givenAt)
+	value := strconv.Itoa(m.config.Version) + "." + strconv.FormatUint(mask, 36) + "." +
+		strconv.FormatInt(givenAt.Unix(), 36)
+	c.SetCookie(&http.Cookie{
+		Name:     m.config.CookieName,
+		Value:    value,
+		Path:     m.config.CookiePath,
+		Domain:   m.config.CookieDomain,
+		MaxAge:   int(m.config.CookieMaxAge / time.Second),
+		Secure:   m.config.CookieSecure || c.IsTLS(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.Set(m.config.ContextKey, state)
+	return nil
+}
+
+// SaveAll stores consent to all purposes.
+func (m *Manager) SaveAll(c echo.Context) error {
+	return m.Save(c, m.config.Purposes...)
+}
+
+// Withdraw removes the consent cookie and expires cookies of all purposes, user is asked again.
+func (m *Manager) Withdraw(c echo.Context) error {
+	for _, name := range m.cookieNames() {
+		if _, err := c.Cookie(name); err == nil {
+			c.SetCookie(&http.Cookie{Name: name, Path: m.config.CookiePath, MaxAge: -1})
+		}
+	}
+	c.SetCookie(&http.Cookie{
+		Name:   m.config.CookieName,
+		Path:   m.config.CookiePath,
+		Domain: m.config.CookieDomain,
+		MaxAge: -1,
+	})
+	c.Set(m.config.ContextKey, &State{})
+	return nil
+}
+
+// FromContext returns consent stored by Middleware under default context key. State without consent is returned
+// when there is none.
+func FromContext(c echo.Context) *State {
+	if s, ok := c.Get(DefaultConfig.ContextKey).(*State); ok {
+		return s
+	}
+	return &State{}
+}
+
+// Given reports whether user has already decided (consented to some or no purposes), banner should be shown
+// otherwise.
+func (s *State) Given() bool {
+	return !s.GivenAt.IsZero()
+}
+
+// Granted reports whether user consented to the purpose. Necessary is always granted.
+func (s *State) Granted(purpose Purpose) bool {
+	if purpose == Necessary {
+		return true
+	}
+	for _, p := range s.Purposes {
+		if p == purpose {
+			return true
+		}
+	}
+	return false
+}
+
+// read parses consent cookie "<version>.<purposes bit mask>.<unix time>" (numbers in base 36). Malformed, expired
+// and outdated consent is treated as not given.
+func (m *Manager) read(c echo.Context) *State {
+	cookie, err := c.Cookie(m.config.CookieName)
+	if err != nil {
+		return &State{}
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 || parts[0] != strconv.Itoa(m.config.Version) {
+		return &State{}
+	}
+	mask, err := strconv.ParseUint(parts[1], 36, 64)
+	if err != nil {
+		return &State{}
+	}
+	sec, err := strconv.ParseInt(parts[2], 36, 64)
+	if err != nil {
+		return &State{}
+	}
+	givenAt := time.Unix(sec, 0)
+	if !echo.Now(c).Before(givenAt.Add(m.config.CookieMaxAge)) {
+		return &State{}
+	}
+	return m.state(mask, givenAt)
+}
+
+func (m *Manager) state(mask uint64, givenAt time.Time) *State {
+	s := &State{Version: m.config.Version, Purposes: []Purpose{}, GivenAt: givenAt}
+	for i, p := range m.config.Purposes {
+		if mask&(1<<uint(i)) != 0 {
+			s.Purposes = append(s.Purposes, p)
+		}
+	}
+	return s
+}
+
+func (m *Manager) index(purpose Purpose) int {
+	for i, p := range m.config.Purposes {
+		if p == purpose {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterCookies removes `Set-Cookie` headers of cookies whose purpose is not granted. Cookies being deleted are
+// kept.
+func (m *Manager) filterCookies(c echo.Context) {
+	header := c.Response().Header()
+	values := header[echo.HeaderSetCookie]
+	if len(values) == 0 {
+		return
+	}
+	state := m.State(c)
+	kept := values[:0]
+	for _, v := range values {
+		name := strings.TrimSpace(strings.SplitN(v, "=", 2)[0])
+		purpose, ok := m.config.Cookies[name]
+		if !ok || state.Granted(purpose) || strings.Contains(v, "Max-Age=0") {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == 0 {
+		header.Del(echo.HeaderSetCookie)
+		return
+	}
+	header[echo.HeaderSetCookie] = kept
+}
+
+func (m *Manager) cookieNames() []string {
+	names := make([]string, 0, len(m.config.Cookies))
+	for name := range m.config.Cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,155 @@
+package consent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestManager() *Manager {
+	return New(Config{
+		Purposes: []Purpose{Preferences, Analytics, Marketing},
+		Cookies:  map[string]Purpose{"lang": Preferences, "_ga": Analytics},
+	})
+}
+
+func TestManager_SaveAndRead(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := echo.New()
+	e.Clock = clock
+	m := newTestManager()
+
+	req := httptest.NewRequest(http.MethodPost, "/consent", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	assert.False(t, m.State(c).Given())
+	assert.True(t, m.State(c).Granted(Necessary))
+	assert.False(t, m.State(c).Granted(Analytics))
+
+	assert.NoError(t, m.Save(c, Analytics, Marketing, "unknown"))
+	assert.Equal(t, []Purpose{Analytics, Marketing}, m.State(c).Purposes)
+	cookie := rec.Result().Cookies()[0]
+	assert.Equal(t, "consent", cookie.Name)
+	assert.Equal(t, "1.6.qm8ao0", cookie.Value)
+	assert.Equal(t, 180*24*3600, cookie.MaxAge)
+
+	var testCases = []struct {
+		name          string
+		cookie        string
+		advance       time.Duration
+		expectGiven   bool
+		expectGranted []Purpose
+	}{
+		{name: "valid", cookie: cookie.Value, expectGiven: true, expectGranted: []Purpose{Analytics, Marketing}},
+		{name: "no purposes", cookie: "1.0.qm8ao0", expectGiven: true, expectGranted: []Purpose{}},
+		{name: "other version", cookie: "2.6.qm8ao0"},
+		{name: "malformed", cookie: "1.x!.qm8ao0"},
+		{name: "expired", cookie: cookie.Value, advance: 180 * 24 * time.Hour},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock.Advance(tc.advance)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: "consent", Value: tc.cookie})
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			state := m.State(c)
+			assert.Equal(t, tc.expectGiven, state.Given())
+			if tc.expectGiven {
+				assert.Equal(t, tc.expectGranted, state.Purposes)
+			}
+		})
+	}
+}
+
+func TestManager_Middleware(t *testing.T) {
+	e := echo.New()
+	e.Clock = echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := newTestManager()
+	tracked := false
+	e.Use(m.Middleware())
+	e.Use(m.Require(Analytics, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tracked = true
+			return next(c)
+		}
+	}))
+	e.GET("/", func(c echo.Context) error {
+		c.SetCookie(&http.Cookie{Name: "session", Value: "s"})
+		c.SetCookie(&http.Cookie{Name: "lang", Value: "en"})
+		c.SetCookie(&http.Cookie{Name: "_ga", Value: "ga"})
+		return c.String(http.StatusOK, "given="+strconv.FormatBool(FromContext(c).Given()))
+	})
+	e.POST("/consent", func(c echo.Context) error {
+		if err := m.Save(c, Preferences); err != nil {
+			return err
+		}
+		c.SetCookie(&http.Cookie{Name: "lang", Value: "en"})
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.DELETE("/consent", func(c echo.Context) error {
+		return m.Withdraw(c)
+	})
+
+	request := func(method, consent string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		if method != http.MethodGet {
+			req = httptest.NewRequest(method, "/consent", nil)
+		}
+		if consent != "" {
+			req.AddCookie(&http.Cookie{Name: "consent", Value: consent})
+		}
+		req.AddCookie(&http.Cookie{Name: "lang", Value: "en"})
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	cookieNames := func(rec *httptest.ResponseRecorder) []string {
+		names := []string{}
+		for _, c := range rec.Result().Cookies() {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	rec := request(http.MethodGet, "")
+	assert.Equal(t, "given=false", rec.Body.String())
+	assert.Equal(t, []string{"session"}, cookieNames(rec))
+	assert.False(t, tracked)
+
+	// cookie of purpose consented to in the same request is kept
+	rec = request(http.MethodPost, "")
+	assert.Equal(t, []string{"consent", "lang"}, cookieNames(rec))
+	value := rec.Result().Cookies()[0].Value
+
+	rec = request(http.MethodGet, value)
+	assert.Equal(t, "given=true", rec.Body.String())
+	assert.Equal(t, []string{"session", "lang"}, cookieNames(rec))
+	assert.False(t, tracked)
+
+	rec = request(http.MethodGet, "1.7.qm8ao0")
+	assert.Equal(t, []string{"session", "lang", "_ga"}, cookieNames(rec))
+	assert.True(t, tracked)
+
+	// withdrawal deletes non-essential cookies sent by client
+	rec = request(http.MethodDelete, value)
+	assert.Equal(t, []string{"lang", "consent"}, cookieNames(rec))
+	for _, c := range rec.Result().Cookies() {
+		assert.Equal(t, -1, c.MaxAge)
+	}
+}
+
+func TestNew_panics(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: consent requires purposes", func() {
+		New(Config{})
+	})
+	assert.PanicsWithValue(t, "echo: consent purposes must not contain necessary or empty purpose", func() {
+		New(Config{Purposes: []Purpose{Analytics, Necessary}})
+	})
+}
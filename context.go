@@ -2,15 +2,17 @@ package echo
 
 import (
 	"bytes"
+	stdContext "context"
 	"encoding/xml"
-	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"sync"
 )
@@ -104,14 +106,16 @@ type (
 
 		// Bind binds the request body into provided type `i`. The default binder
 		// does it based on Content-Type header.
+		// Bind can be called multiple times when request body has been read with `BodyBytes()` before.
 		Bind(i interface{}) error
 
 		// Validate validates provided `i`. It is usually called after `Context#Bind()`.
-		// Validator must be registered using `Echo#Validator`.
+		// Validator must be registered using `Echo#Validator`. `BindErrors` returned by validator are sent as 422
+		// Unprocessable Entity with messages localized by `Echo#Localizer`.
 		Validate(i interface{}) error
 
 		// Render renders a template with data and sends a text/html response with status
-		// code. Renderer must be registered using `Echo.Renderer`.
+		// code. Renderer must be registered using `Echo.Renderer` or `Group.Renderer`.
 		Render(code int, name string, data interface{}) error
 
 		// HTML sends an HTTP response with status code.
@@ -171,7 +175,8 @@ type (
 		// Redirect redirects the request to a provided URL with status code.
 		Redirect(code int, url string) error
 
-		// Error invokes the registered HTTP error handler. Generally used by middleware.
+		// Error invokes the registered HTTP error handler (of the group of matched route or `Echo#HTTPErrorHandler`).
+		// Generally used by middleware.
 		Error(err error)
 
 		// Handler returns the matched handler by router.
@@ -201,11 +206,19 @@ type (
 		path     string
 		pnames   []string
 		pvalues  []string
+		typed    Map
 		query    url.Values
 		handler  HandlerFunc
+		allowed  *methodHandler
+		router   *Router
 		store    Map
 		echo     *Echo
 		logger   Logger
+		body     []byte
+		timings  PhaseTimings
+		inPhase  bool
+		variants []variant
+		connCtx  stdContext.Context
 		lock     sync.RWMutex
 	}
 )
@@ -216,10 +229,47 @@ const (
 	defaultIndent = "  "
 )
 
+// contextOf returns Echo's implementation of the context or nil for custom `Context` implementations. Custom
+// contexts embedding `Context` are unwrapped to the embedded context.
+func contextOf(c Context) *context {
+	for {
+		if cc, ok := c.(*context); ok {
+			return cc
+		}
+		v := reflect.ValueOf(c)
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		f := v.FieldByName("Context")
+		if !f.IsValid() || !f.CanInterface() {
+			return nil
+		}
+		embedded, ok := f.Interface().(Context)
+		if !ok || embedded == nil {
+			return nil
+		}
+		c = embedded
+	}
+}
+
+// writeContentType sets `Content-Type` response header unless it is already set.
+func writeContentType(c Context, value string) {
+	if cc := contextOf(c); cc != nil {
+		cc.writeContentType(value)
+		return
+	}
+	if header := c.Response().Header(); header.Get(HeaderContentType) == "" {
+		header.Set(HeaderContentType, value)
+	}
+}
+
 func (c *context) writeContentType(value string) {
 	header := c.Response().Header()
 	if header.Get(HeaderContentType) == "" {
-		header.Set(HeaderContentType, value)
+		c.setContentType(header, value)
 	}
 }
 
@@ -422,22 +472,85 @@ func (c *context) Set(key string, val interface{}) {
 }
 
 func (c *context) Bind(i interface{}) error {
-	return c.echo.Binder.Bind(i, c)
+	defer c.trackPhase(&c.timings.Bind)()
+	if c.body != nil {
+		// replay cached body so it could be bound more than once
+		c.request.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	}
+	return c.localize(c.echo.Binder.Bind(i, c))
+}
+
+// BodyBytes reads and returns the request body. Body is read only once and cached so subsequent calls (i.e. in
+// middleware verifying signature and then in handler) return same content, request body is replaced with a reader
+// over cached content. Use `BodyLimit` middleware to limit size of buffered bodies.
+func BodyBytes(c Context) ([]byte, error) {
+	if cc := contextOf(c); cc != nil {
+		return cc.bodyBytes()
+	}
+	r := c.Request()
+	if r.Body == nil || r.Body == http.NoBody {
+		return []byte{}, nil
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// RawBody returns the exact request body bytes buffered by `BodyBytes()` (i.e. by `BodyBuffer` middleware) or nil
+// when body has not been buffered.
+func RawBody(c Context) []byte {
+	if cc := contextOf(c); cc != nil {
+		return cc.body
+	}
+	return nil
+}
+
+func (c *context) bodyBytes() ([]byte, error) {
+	if c.body == nil {
+		if c.request.Body == nil || c.request.Body == http.NoBody {
+			c.body = []byte{}
+			return c.body, nil
+		}
+		b, err := ioutil.ReadAll(c.request.Body)
+		if err != nil {
+			return nil, err
+		}
+		c.request.Body.Close()
+		c.body = b
+	}
+	c.request.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	return c.body, nil
 }
 
 func (c *context) Validate(i interface{}) error {
 	if c.echo.Validator == nil {
 		return ErrValidatorNotRegistered
 	}
-	return c.echo.Validator.Validate(i)
+	defer c.trackPhase(&c.timings.Validate)()
+	err := c.echo.Validator.Validate(i)
+	if be, ok := err.(BindErrors); ok {
+		err = be.httpError()
+	}
+	return c.localize(err)
 }
 
 func (c *context) Render(code int, name string, data interface{}) (err error) {
-	if c.echo.Renderer == nil {
+	defer c.trackPhase(&c.timings.Render)()
+	renderer := groupOf(c).renderer()
+	if renderer == nil {
+		renderer = c.echo.Renderer
+	}
+	if renderer == nil {
 		return ErrRendererNotRegistered
 	}
-	buf := new(bytes.Buffer)
-	if err = c.echo.Renderer.Render(buf, name, data, c); err != nil {
+	pool := c.echo.bufferPool()
+	buf := pool.Get()
+	defer pool.Put(buf)
+	if err = renderer.Render(buf, name, data, c); err != nil {
 		return
 	}
 	return c.HTMLBlob(code, buf.Bytes())
@@ -456,8 +569,9 @@ func (c *context) String(code int, s string) (err error) {
 }
 
 func (c *context) jsonPBlob(code int, callback string, i interface{}) (err error) {
+	defer c.trackPhase(&c.timings.Render)()
 	indent := ""
-	if _, pretty := c.QueryParams()["pretty"]; c.echo.Debug || pretty {
+	if c.echo.Debug || c.prettyQueryParam() {
 		indent = defaultIndent
 	}
 	c.writeContentType(MIMEApplicationJavaScriptCharsetUTF8)
@@ -474,7 +588,27 @@ func (c *context) jsonPBlob(code int, callback string, i interface{}) (err error
 	return
 }
 
+// prettyQueryParam reports whether request has `pretty` query param. Query string is not parsed (allocated) for
+// requests without it.
+// prettyQueryParam returns true when request has `pretty` query parameter.
+func prettyQueryParam(c Context) bool {
+	if cc := contextOf(c); cc != nil {
+		return cc.prettyQueryParam()
+	}
+	_, pretty := c.QueryParams()["pretty"]
+	return pretty
+}
+
+func (c *context) prettyQueryParam() bool {
+	if c.query == nil && c.request.URL.RawQuery == "" {
+		return false
+	}
+	_, pretty := c.QueryParams()["pretty"]
+	return pretty
+}
+
 func (c *context) json(code int, i interface{}, indent string) error {
+	defer c.trackPhase(&c.timings.Render)()
 	c.writeContentType(MIMEApplicationJSONCharsetUTF8)
 	c.response.Status = code
 	return c.echo.JSONSerializer.Serialize(c, i, indent)
@@ -482,7 +616,7 @@ func (c *context) json(code int, i interface{}, indent string) error {
 
 func (c *context) JSON(code int, i interface{}) (err error) {
 	indent := ""
-	if _, pretty := c.QueryParams()["pretty"]; c.echo.Debug || pretty {
+	if c.echo.Debug || c.prettyQueryParam() {
 		indent = defaultIndent
 	}
 	return c.json(code, i, indent)
@@ -501,6 +635,7 @@ func (c *context) JSONP(code int, callback string, i interface{}) (err error) {
 }
 
 func (c *context) JSONPBlob(code int, callback string, b []byte) (err error) {
+	defer c.trackPhase(&c.timings.Render)()
 	c.writeContentType(MIMEApplicationJavaScriptCharsetUTF8)
 	c.response.WriteHeader(code)
 	if _, err = c.response.Write([]byte(callback + "(")); err != nil {
@@ -514,21 +649,18 @@ func (c *context) JSONPBlob(code int, callback string, b []byte) (err error) {
 }
 
 func (c *context) xml(code int, i interface{}, indent string) (err error) {
+	defer c.trackPhase(&c.timings.Render)()
 	c.writeContentType(MIMEApplicationXMLCharsetUTF8)
 	c.response.WriteHeader(code)
-	enc := xml.NewEncoder(c.response)
-	if indent != "" {
-		enc.Indent("", indent)
-	}
 	if _, err = c.response.Write([]byte(xml.Header)); err != nil {
 		return
 	}
-	return enc.Encode(i)
+	return c.echo.XMLSerializer.Serialize(c, i, indent)
 }
 
 func (c *context) XML(code int, i interface{}) (err error) {
 	indent := ""
-	if _, pretty := c.QueryParams()["pretty"]; c.echo.Debug || pretty {
+	if c.echo.Debug || c.prettyQueryParam() {
 		indent = defaultIndent
 	}
 	return c.xml(code, i, indent)
@@ -539,6 +671,7 @@ func (c *context) XMLPretty(code int, i interface{}, indent string) (err error)
 }
 
 func (c *context) XMLBlob(code int, b []byte) (err error) {
+	defer c.trackPhase(&c.timings.Render)()
 	c.writeContentType(MIMEApplicationXMLCharsetUTF8)
 	c.response.WriteHeader(code)
 	if _, err = c.response.Write([]byte(xml.Header)); err != nil {
@@ -549,6 +682,7 @@ func (c *context) XMLBlob(code int, b []byte) (err error) {
 }
 
 func (c *context) Blob(code int, contentType string, b []byte) (err error) {
+	defer c.trackPhase(&c.timings.Render)()
 	c.writeContentType(contentType)
 	c.response.WriteHeader(code)
 	_, err = c.response.Write(b)
@@ -556,6 +690,7 @@ func (c *context) Blob(code int, contentType string, b []byte) (err error) {
 }
 
 func (c *context) Stream(code int, contentType string, r io.Reader) (err error) {
+	defer c.trackPhase(&c.timings.Render)()
 	c.writeContentType(contentType)
 	c.response.WriteHeader(code)
 	_, err = io.Copy(c.response, r)
@@ -594,7 +729,7 @@ func (c *context) Inline(file, name string) error {
 }
 
 func (c *context) contentDisposition(file, name, dispositionType string) error {
-	c.response.Header().Set(HeaderContentDisposition, fmt.Sprintf("%s; filename=%q", dispositionType, name))
+	c.response.Header().Set(HeaderContentDisposition, ContentDisposition(dispositionType, name))
 	return c.File(file)
 }
 
@@ -613,6 +748,10 @@ func (c *context) Redirect(code int, url string) error {
 }
 
 func (c *context) Error(err error) {
+	if h := groupOf(c).errorHandler(); h != nil {
+		h(err, c)
+		return
+	}
 	c.echo.HTTPErrorHandler(err, c)
 }
 
@@ -645,10 +784,27 @@ func (c *context) Reset(r *http.Request, w http.ResponseWriter) {
 	c.response.reset(w)
 	c.query = nil
 	c.handler = NotFoundHandler
-	c.store = nil
+	c.allowed = nil
+	c.router = nil
+	if c.echo.zeroAlloc && c.store != nil {
+		for k := range c.store {
+			delete(c.store, k)
+		}
+	} else {
+		c.store = nil
+	}
 	c.path = ""
 	c.pnames = nil
+	c.typed = nil
 	c.logger = nil
+	c.body = nil
+	c.timings = PhaseTimings{}
+	c.inPhase = false
+	c.variants = c.variants[:0]
+	c.connCtx = nil
+	if r != nil {
+		c.connCtx = r.Context()
+	}
 	// NOTE: Don't reset because it has to have length c.echo.maxParam at all times
 	for i := 0; i < *c.echo.maxParam; i++ {
 		c.pvalues[i] = ""
@@ -859,6 +859,59 @@ func TestContext_Bind(t *testing.T) {
 	testify.Equal(t, &user{1, "Jon Snow"}, u)
 }
 
+func TestContext_BodyBytes(t *testing.T) {
+	e := New()
+	req := httptest.NewRequest(POST, "/", strings.NewReader(userJSON))
+	req.Header.Add(HeaderContentType, MIMEApplicationJSON)
+	c := e.NewContext(req, nil)
+
+	body, err := BodyBytes(c)
+	testify.NoError(t, err)
+	testify.Equal(t, []byte(userJSON), body)
+
+	body, err = BodyBytes(c)
+	testify.NoError(t, err)
+	testify.Equal(t, []byte(userJSON), body)
+
+	for i := 0; i < 2; i++ {
+		u := new(user)
+		err = c.Bind(u)
+		testify.NoError(t, err)
+		testify.Equal(t, &user{1, "Jon Snow"}, u)
+	}
+
+	c.Reset(httptest.NewRequest(GET, "/", nil), nil)
+	body, err = BodyBytes(c)
+	testify.NoError(t, err)
+	testify.Equal(t, []byte{}, body)
+}
+
+type customContext struct {
+	Context
+}
+
+func TestBodyBytes_customContext(t *testing.T) {
+	e := New()
+	req := httptest.NewRequest(POST, "/", strings.NewReader(userJSON))
+	c := e.NewContext(req, nil)
+	cc := &customContext{c}
+
+	body, err := BodyBytes(cc)
+	testify.NoError(t, err)
+	testify.Equal(t, []byte(userJSON), body)
+	testify.Equal(t, []byte(userJSON), RawBody(c))
+}
+
+func TestContextOf(t *testing.T) {
+	e := New()
+	c := e.NewContext(nil, nil)
+
+	testify.Equal(t, c, contextOf(c))
+	testify.Equal(t, c, contextOf(&customContext{c}))
+	testify.Equal(t, c, contextOf(customContext{&customContext{c}}))
+	testify.Nil(t, contextOf(&customContext{}))
+}
+
 func TestContext_Logger(t *testing.T) {
 	e := New()
 	c := e.NewContext(nil, nil)
@@ -0,0 +1,64 @@
+package echo
+
+import (
+	"html/template"
+	"time"
+)
+
+type (
+	// DevModeConfig defines the config of development mode. See `Echo#DevMode()`.
+	DevModeConfig struct {
+		// Templates is glob pattern of HTML templates rendered by `Echo#Renderer` set by development mode.
+		// Templates are parsed again on render when any of them changed.
+		// Optional. Default value "" (Renderer is not changed).
+		Templates string
+
+		// Funcs are functions available in Templates, i.e. `AssetManifest#FuncMap()` of middleware package.
+		// Optional.
+		Funcs template.FuncMap
+
+		// Watch are directories (templates, static assets) watched for changes. Pages served with live-reload
+		// script are reloaded in browser when any file in them changes.
+		// Optional. Default value is directory of Templates.
+		Watch []string
+
+		// PollInterval is interval of checking watched directories for changes.
+		// Optional. Default value 500ms.
+		PollInterval time.Duration
+
+		// ReloadPath is URL path of event stream notifying live-reload script about changes.
+		// Optional. Default value "/_echo/livereload".
+		ReloadPath string
+	}
+)
+
+// DefaultDevModeConfig is the default development mode config.
+var DefaultDevModeConfig = DevModeConfig{
+	PollInterval: 500 * time.Millisecond,
+	ReloadPath:   "/_echo/livereload",
+}
+
+// DevMode enables development mode with default config. See `Echo#DevModeWithConfig()`.
+func (e *Echo) DevMode() {
+	e.DevModeWithConfig(DefaultDevModeConfig)
+}
+
+// DevModeWithConfig enables development mode:
+//   - `Echo#Debug` is enabled and logger level is set to DEBUG,
+//   - templates (see `DevModeConfig.Templates`) are parsed again when changed,
+//   - live-reload script is injected to HTML responses and reloads the page when watched files change,
+//   - registered routes and middleware are logged on first request and every request is logged with matched route,
+//   - responses are sent with `Cache-Control: no-store` so changed assets are never served from cache.
+//
+// Development mode is compiled out from binaries built with `production` build tag, where this method only logs a
+// warning.
+//
+// Example:
+//
+//	e.DevModeWithConfig(echo.DevModeConfig{
+//		Templates: "views/*.html",
+//		Watch:     []string{"views", "public"},
+//	})
+func (e *Echo) DevModeWithConfig(config DevModeConfig) {
+	e.devMode(config)
+}
@@ -0,0 +1,262 @@
+// +build !production
+
+package echo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+type (
+	// devRenderer renders templates parsed again when any of them changed.
+	devRenderer struct {
+		pattern string
+		funcs   template.FuncMap
+
+		mu    sync.Mutex
+		stamp uint64
+		tmpl  *template.Template
+	}
+
+	// liveReloadWriter buffers HTML responses to inject live-reload script before `</body>`.
+	liveReloadWriter struct {
+		http.ResponseWriter
+		script []byte
+		code   int
+		buf    *bytes.Buffer
+	}
+)
+
+func (e *Echo) devMode(config DevModeConfig) {
+	// Defaults
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultDevModeConfig.PollInterval
+	}
+	if config.ReloadPath == "" {
+		config.ReloadPath = DefaultDevModeConfig.ReloadPath
+	}
+	if len(config.Watch) == 0 && config.Templates != "" {
+		config.Watch = []string{filepath.Dir(config.Templates)}
+	}
+
+	e.Debug = true
+	e.Logger.SetLevel(log.DEBUG)
+	if config.Templates != "" {
+		e.Renderer = &devRenderer{pattern: config.Templates, funcs: config.Funcs}
+	}
+	script := []byte(`<script>(function(){var s=new EventSource(` + strconv.Quote(config.ReloadPath) +
+		`);s.onmessage=function(){s.close();location.reload()}})()</script>`)
+
+	var once sync.Once
+	e.Pre(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			once.Do(e.logRoutes)
+
+			req := c.Request()
+			if req.Method == http.MethodGet && req.URL.Path == config.ReloadPath {
+				return liveReloadStream(c, config.Watch, config.PollInterval)
+			}
+
+			res := c.Response()
+			res.Before(func() {
+				res.Header().Set(HeaderCacheControl, "no-store")
+			})
+			w := &liveReloadWriter{ResponseWriter: res.Writer, script: script}
+			if req.Method != http.MethodHead {
+				res.Writer = w
+			}
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// error handler runs after middleware, its response must be buffered too
+				c.Error(err)
+			}
+			w.finish()
+			res.Writer = w.ResponseWriter
+
+			e.Logger.Debugf("%s %s -> route %q handler %s: %d in %s", req.Method, req.RequestURI, c.Path(),
+				handlerName(c.Handler()), res.Status, time.Since(start))
+			if err != nil {
+				e.Logger.Debugf("%s %s -> error: %v", req.Method, req.RequestURI, err)
+			}
+			return nil
+		}
+	})
+}
+
+// logRoutes logs registered middleware and routes.
+func (e *Echo) logRoutes() {
+	for _, m := range e.premiddleware {
+		e.Logger.Debugf("pre middleware %s", funcName(m))
+	}
+	for _, m := range e.middleware {
+		e.Logger.Debugf("middleware %s", funcName(m))
+	}
+	routes := e.Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	for _, r := range routes {
+		e.Logger.Debugf("route %-7s %s -> %s", r.Method, r.Path, r.Name)
+	}
+}
+
+func funcName(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
+// liveReloadStream sends server-sent event `reload` once any file in watched directories changes.
+func liveReloadStream(c Context, watch []string, interval time.Duration) error {
+	initial := filesStamp(watchedFiles(watch))
+
+	res := c.Response()
+	res.Header().Set(HeaderContentType, "text/event-stream")
+	res.Header().Set(HeaderCacheControl, "no-cache")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-ticker.C:
+			if filesStamp(watchedFiles(watch)) != initial {
+				if _, err := io.WriteString(res, "data: reload\n\n"); err != nil {
+					return err
+				}
+				res.Flush()
+				return nil
+			}
+		}
+	}
+}
+
+// watchedFiles returns all files in the directories.
+func watchedFiles(dirs []string) []string {
+	var files []string
+	for _, dir := range dirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// filesStamp returns hash of names, sizes and modification times of the files. Stamp changes when any file is
+// added, removed or modified.
+func filesStamp(files []string) uint64 {
+	h := fnv.New64a()
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(h, "%s|%d|%d\n", name, info.Size(), info.ModTime().UnixNano())
+	}
+	return h.Sum64()
+}
+
+func (r *devRenderer) Render(w io.Writer, name string, data interface{}, c Context) error {
+	t, err := r.templates()
+	if err != nil {
+		return err
+	}
+	return t.ExecuteTemplate(w, name, data)
+}
+
+func (r *devRenderer) templates() (*template.Template, error) {
+	files, err := filepath.Glob(r.pattern)
+	if err != nil {
+		return nil, err
+	}
+	stamp := filesStamp(files)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tmpl != nil && stamp == r.stamp {
+		return r.tmpl, nil
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("echo: no templates match pattern %q", r.pattern)
+	}
+	t, err := template.New("").Funcs(r.funcs).ParseFiles(files...)
+	if err != nil {
+		return nil, err
+	}
+	r.tmpl, r.stamp = t, stamp
+	return t, nil
+}
+
+func (w *liveReloadWriter) WriteHeader(code int) {
+	if code != http.StatusNoContent && code != http.StatusNotModified &&
+		strings.HasPrefix(w.Header().Get(HeaderContentType), MIMETextHTML) {
+		w.code = code
+		w.buf = new(bytes.Buffer)
+		w.Header().Del(HeaderContentLength)
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *liveReloadWriter) Write(b []byte) (int, error) {
+	if w.buf != nil {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *liveReloadWriter) Flush() {
+	if w.buf != nil {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *liveReloadWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// finish writes buffered HTML response with live-reload script.
+func (w *liveReloadWriter) finish() {
+	if w.buf == nil {
+		return
+	}
+	body := w.buf.Bytes()
+	w.buf = nil
+	i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>"))
+	if i < 0 {
+		i = len(body)
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+	w.ResponseWriter.Write(body[:i])
+	w.ResponseWriter.Write(w.script)
+	w.ResponseWriter.Write(body[i:])
+}
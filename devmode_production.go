@@ -0,0 +1,7 @@
+// +build production
+
+package echo
+
+func (e *Echo) devMode(config DevModeConfig) {
+	e.Logger.Warn("echo: development mode is not available in production build")
+}
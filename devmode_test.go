@@ -0,0 +1,102 @@
+// +build !production
+
+package echo
+
+import (
+	"bytes"
+	stdContext "context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEcho_DevMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "views")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	page := filepath.Join(dir, "page.html")
+	require.NoError(t, ioutil.WriteFile(page, []byte(`{{define "page"}}<html><body>v1 {{.}}</body></html>{{end}}`), 0644))
+
+	e := New()
+	logs := new(bytes.Buffer)
+	e.Logger.SetOutput(logs)
+	e.DevModeWithConfig(DevModeConfig{Templates: filepath.Join(dir, "*.html")})
+	e.GET("/page", func(c Context) error {
+		c.Response().Header().Set(HeaderCacheControl, "public, max-age=3600")
+		return c.Render(http.StatusOK, "page", "jon")
+	})
+	e.GET("/api", func(c Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"a": "b"})
+	})
+	e.GET("/fail", func(c Context) error {
+		return ErrForbidden
+	})
+	assert.True(t, e.Debug)
+
+	req := httptest.NewRequest(http.MethodGet, "/page", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get(HeaderCacheControl))
+	body := rec.Body.String()
+	assert.True(t, strings.HasPrefix(body, "<html><body>v1 jon<script>"))
+	assert.True(t, strings.HasSuffix(body, `</script></body></html>`))
+	assert.Contains(t, body, `new EventSource("/_echo/livereload")`)
+	assert.Contains(t, logs.String(), `route GET     /page`)
+	assert.Contains(t, logs.String(), `GET /page -> route \"/page\"`)
+
+	// changed template is parsed again
+	require.NoError(t, ioutil.WriteFile(page, []byte(`{{define "page"}}<html><body>v2 {{.}}</body></html>{{end}}`), 0644))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(page, future, future))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/page", nil))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "<html><body>v2 jon<script>"))
+
+	// non HTML responses are not changed
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api", nil))
+	assert.Equal(t, "{\n  \"a\": \"b\"\n}\n", rec.Body.String()) // pretty printed in debug mode
+	assert.Equal(t, "no-store", rec.Header().Get(HeaderCacheControl))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, logs.String(), "GET /fail -> error: code=403")
+}
+
+func TestEcho_DevModeLiveReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "public")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.css"), []byte("body{}"), 0644))
+
+	e := New()
+	e.Logger.SetOutput(ioutil.Discard)
+	e.DevModeWithConfig(DevModeConfig{Watch: []string{dir}, PollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), 5*time.Second)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/_echo/livereload", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		e.ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.js"), []byte("alert(1)"), 0644))
+	<-done
+
+	assert.Equal(t, "text/event-stream", rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "data: reload\n\n", rec.Body.String())
+}
@@ -0,0 +1,26 @@
+package echo
+
+import stdContext "context"
+
+// Done returns channel closed when the request is canceled: client disconnected, request timed out or context of the
+// request was canceled by middleware.
+func Done(c Context) <-chan struct{} {
+	return c.Request().Context().Done()
+}
+
+// Disconnected returns true when client closed the connection (HTTP/1) or reset the stream (HTTP/2). Unlike `Done()`
+// it is not affected by deadlines and cancellation of context set with `Context#SetRequest()`.
+//
+// It checks context of the request created by the server which is canceled only when the connection is closed (or
+// the stream is reset) while request is being served. Go server notices closed HTTP/1 connection only after request
+// body was read, handlers should read body before long running work they want to skip.
+func Disconnected(c Context) bool {
+	var ctx stdContext.Context
+	if cc := contextOf(c); cc != nil {
+		ctx = cc.connCtx
+	}
+	if ctx == nil {
+		ctx = c.Request().Context()
+	}
+	return ctx.Err() == stdContext.Canceled
+}
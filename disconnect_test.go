@@ -0,0 +1,61 @@
+package echo
+
+import (
+	stdContext "context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Disconnected(t *testing.T) {
+	e := New()
+	var done, disconnected bool
+	e.GET("/", func(c Context) error {
+		select {
+		case <-Done(c):
+			done = true
+		default:
+		}
+		disconnected = Disconnected(c)
+		return nil
+	})
+
+	ctx, cancel := stdContext.WithCancel(stdContext.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	assert.False(t, done)
+	assert.False(t, disconnected)
+
+	cancel()
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	assert.True(t, done)
+	assert.True(t, disconnected)
+}
+
+func TestContext_DisconnectedIgnoresRequestContextOfMiddleware(t *testing.T) {
+	e := New()
+	e.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			ctx, cancel := stdContext.WithCancel(c.Request().Context())
+			cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+	var done, disconnected bool
+	e.GET("/", func(c Context) error {
+		select {
+		case <-Done(c):
+			done = true
+		default:
+		}
+		disconnected = Disconnected(c)
+		return nil
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, done)
+	assert.False(t, disconnected)
+}
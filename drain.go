@@ -0,0 +1,279 @@
+package echo
+
+import (
+	stdContext "context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// DrainStatus describes work Echo is still doing, i.e. while it is being shut down during rollout.
+	DrainStatus struct {
+		// ShuttingDown is true once `Echo#Shutdown()` was called.
+		ShuttingDown bool `json:"shutting_down"`
+		// ShutdownStarted is time `Echo#Shutdown()` was called.
+		ShutdownStarted *time.Time `json:"shutdown_started,omitempty"`
+		// InFlight is number of requests being handled. Requests are counted only when `Echo#TrackInFlight` is
+		// enabled.
+		InFlight int64 `json:"in_flight"`
+		// Routes lists routes with requests being handled, sorted by host, path and method.
+		Routes []RouteInFlight `json:"routes"`
+		// LongLived is number of open long-lived connections registered with `Echo#LongLived()`.
+		LongLived int `json:"long_lived"`
+		// Connections are connection counters of servers started by Echo.
+		Connections ConnStats `json:"connections"`
+	}
+
+	// RouteInFlight is number of requests being handled by the route.
+	RouteInFlight struct {
+		Host     string `json:"host,omitempty"`
+		Method   string `json:"method"`
+		Path     string `json:"path"`
+		InFlight int64  `json:"in_flight"`
+	}
+
+	drainTracker struct {
+		mutex           sync.Mutex
+		routes          map[string]*int64 // host+method+path => in-flight counter
+		longLived       map[chan string]struct{}
+		shutdownStarted time.Time
+	}
+)
+
+const (
+	// DefaultDrainMessage is grace message sent to long-lived connections closed on shutdown or by
+	// `DrainHandler()` without message.
+	DefaultDrainMessage = "server is shutting down"
+
+	// SSEShutdownEvent is event type of final server-sent event of streams closed because of shutdown.
+	SSEShutdownEvent = "shutdown"
+
+	// WebSocketCloseGoingAway is WebSocket close status (1001 Going Away) of connections closed because of
+	// shutdown.
+	WebSocketCloseGoingAway = 1001
+)
+
+// DrainStatus returns in-flight requests per route, open long-lived connections and connection counters.
+func (e *Echo) DrainStatus() DrainStatus {
+	t := &e.drain
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	status := DrainStatus{
+		ShuttingDown: !t.shutdownStarted.IsZero(),
+		Routes:       []RouteInFlight{},
+		LongLived:    len(t.longLived),
+		Connections:  e.ConnStats(),
+	}
+	if status.ShuttingDown {
+		started := t.shutdownStarted
+		status.ShutdownStarted = &started
+	}
+	add := func(host string, router *Router) {
+		for _, r := range router.routes {
+			counter, ok := t.routes[inFlightKey(host, r.Method, r.Path)]
+			if !ok {
+				continue
+			}
+			if n := atomic.LoadInt64(counter); n > 0 {
+				status.InFlight += n
+				status.Routes = append(status.Routes, RouteInFlight{Host: host, Method: r.Method, Path: r.Path, InFlight: n})
+			}
+		}
+	}
+	add("", e.router)
+	for host, router := range e.routers {
+		add(host, router)
+	}
+	sort.Slice(status.Routes, func(i, j int) bool {
+		if status.Routes[i].Host != status.Routes[j].Host {
+			return status.Routes[i].Host < status.Routes[j].Host
+		}
+		if status.Routes[i].Path != status.Routes[j].Path {
+			return status.Routes[i].Path < status.Routes[j].Path
+		}
+		return status.Routes[i].Method < status.Routes[j].Method
+	})
+	return status
+}
+
+// LongLived registers long-lived connection (server-sent events stream, WebSocket) which is asked to
+// close by `Echo#CloseLongLived()` and when `Echo#Shutdown()` is called. Grace message to send to the client before
+// closing the connection is received from drain channel, send it with `WriteSSEShutdown()` or in close frame
+// `WebSocketShutdownClose()`. Handler must call done when the connection is closed, `Echo#Shutdown()` waits for it.
+//
+// Example:
+//
+//	drain, done := c.Echo().LongLived()
+//	defer done()
+//	for {
+//		select {
+//		case msg := <-drain:
+//			return echo.WriteSSEShutdown(c, msg)
+//		case ev := <-events:
+//			...
+//		}
+//	}
+func (e *Echo) LongLived() (drain <-chan string, done func()) {
+	t := &e.drain
+	ch := make(chan string, 1)
+	t.mutex.Lock()
+	if t.longLived == nil {
+		t.longLived = map[chan string]struct{}{}
+	}
+	t.longLived[ch] = struct{}{}
+	t.mutex.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			t.mutex.Lock()
+			delete(t.longLived, ch)
+			t.mutex.Unlock()
+		})
+	}
+}
+
+// CloseLongLived asks all long-lived connections to close with the grace message and returns number of notified
+// connections. Connections are notified only once.
+func (e *Echo) CloseLongLived(message string) int {
+	t := &e.drain
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	n := 0
+	for ch := range t.longLived {
+		select {
+		case ch <- message:
+			n++
+		default: // already notified
+		}
+	}
+	return n
+}
+
+// DrainHandler returns a handler serving `Echo#DrainStatus()` as JSON. POST request additionally closes long-lived
+// connections with grace message from form value `message` (or `DefaultDrainMessage`). Listeners of Echo are closed
+// on shutdown so the handler is meant to be served by separate admin server and protected by authentication.
+//
+// Example:
+//
+//	admin := echo.New()
+//	admin.Use(middleware.BasicAuth(validator))
+//	admin.Match([]string{http.MethodGet, http.MethodPost}, "/drain", echo.DrainHandler(e))
+//	go admin.Start("127.0.0.1:9090")
+func DrainHandler(e *Echo) HandlerFunc {
+	return func(c Context) error {
+		if c.Request().Method == http.MethodPost {
+			message := c.FormValue("message")
+			if message == "" {
+				message = DefaultDrainMessage
+			}
+			e.CloseLongLived(message)
+		}
+		return c.JSON(http.StatusOK, e.DrainStatus())
+	}
+}
+
+// inFlightCounter returns in-flight counter of the route, routes registered again with the same host, method and
+// path share the counter.
+func (t *drainTracker) inFlightCounter(host, method, path string) *int64 {
+	key := inFlightKey(host, method, path)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.routes == nil {
+		t.routes = map[string]*int64{}
+	}
+	counter, ok := t.routes[key]
+	if !ok {
+		counter = new(int64)
+		t.routes[key] = counter
+	}
+	return counter
+}
+
+func inFlightKey(host, method, path string) string {
+	return host + " " + method + path
+}
+
+// startShutdown records start of shutdown and asks long-lived connections to close.
+func (e *Echo) startShutdown() {
+	t := &e.drain
+	t.mutex.Lock()
+	if t.shutdownStarted.IsZero() {
+		t.shutdownStarted = e.Now()
+	}
+	t.mutex.Unlock()
+	e.CloseLongLived(DefaultDrainMessage)
+}
+
+// waitLongLived waits until all long-lived connections are done or context is done. Hijacked connections
+// (WebSocket) are not waited for by `http.Server#Shutdown()`.
+func (e *Echo) waitLongLived(ctx stdContext.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		e.drain.mutex.Lock()
+		n := len(e.drain.longLived)
+		e.drain.mutex.Unlock()
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WriteSSEShutdown writes final server-sent event of stream closed because of shutdown: event type
+// `SSEShutdownEvent` with the grace message as data. Clients (`EventSource`) reconnect when the stream ends, load
+// balancer routes them to another instance.
+func WriteSSEShutdown(c Context, message string) error {
+	var b strings.Builder
+	b.WriteString("event: " + SSEShutdownEvent + "\n")
+	for _, line := range strings.Split(message, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	if _, err := io.WriteString(c.Response(), b.String()); err != nil {
+		return err
+	}
+	c.Response().Flush()
+	return nil
+}
+
+// WebSocketShutdownClose returns payload of WebSocket close frame sent to connections closed because of shutdown:
+// status `WebSocketCloseGoingAway` and the grace message as reason (truncated to 123 bytes allowed by the
+// protocol).
+//
+// Example (gorilla/websocket):
+//
+//	conn.WriteControl(websocket.CloseMessage, echo.WebSocketShutdownClose(msg), time.Now().Add(time.Second))
+func WebSocketShutdownClose(message string) []byte {
+	if len(message) > 123 {
+		message = message[:123]
+		for !utf8.ValidString(message) {
+			message = message[:len(message)-1]
+		}
+	}
+	payload := make([]byte, 2, 2+len(message))
+	binary.BigEndian.PutUint16(payload, WebSocketCloseGoingAway)
+	return append(payload, message...)
+}
+
+// trackInFlight wraps route handler to count its in-flight requests.
+func trackInFlight(counter *int64, h HandlerFunc) HandlerFunc {
+	return func(c Context) error {
+		atomic.AddInt64(counter, 1)
+		defer atomic.AddInt64(counter, -1)
+		return h(c)
+	}
+}
@@ -0,0 +1,241 @@
+package echo
+
+import (
+	stdContext "context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEcho_DrainStatus(t *testing.T) {
+	e := New()
+	e.TrackInFlight = true
+	release := make(chan struct{})
+	entered := make(chan struct{}, 2)
+	slow := func(c Context) error {
+		entered <- struct{}{}
+		<-release
+		return c.NoContent(http.StatusOK)
+	}
+	e.GET("/reports/:id", slow)
+	e.POST("/uploads", slow)
+	e.GET("/fast", func(c Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+	done := make(chan struct{})
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/reports/1", nil),
+		httptest.NewRequest(http.MethodPost, "/uploads", nil),
+	} {
+		go func(req *http.Request) {
+			e.ServeHTTP(httptest.NewRecorder(), req)
+			done <- struct{}{}
+		}(req)
+	}
+	<-entered
+	<-entered
+
+	status := e.DrainStatus()
+	assert.False(t, status.ShuttingDown)
+	assert.Nil(t, status.ShutdownStarted)
+	assert.Equal(t, int64(2), status.InFlight)
+	assert.Equal(t, []RouteInFlight{
+		{Method: http.MethodGet, Path: "/reports/:id", InFlight: 1},
+		{Method: http.MethodPost, Path: "/uploads", InFlight: 1},
+	}, status.Routes)
+
+	close(release)
+	<-done
+	<-done
+	status = e.DrainStatus()
+	assert.Equal(t, int64(0), status.InFlight)
+	assert.Equal(t, []RouteInFlight{}, status.Routes)
+}
+
+func TestEcho_DrainStatusNotTracked(t *testing.T) {
+	e := New()
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	e.GET("/", func(c Context) error {
+		close(entered)
+		<-release
+		return nil
+	})
+	go e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	<-entered
+	assert.Equal(t, int64(0), e.DrainStatus().InFlight)
+	close(release)
+}
+
+func TestEcho_DrainStatusHosts(t *testing.T) {
+	e := New()
+	e.TrackInFlight = true
+	release := make(chan struct{})
+	entered := make(chan struct{}, 3)
+	slow := func(c Context) error {
+		entered <- struct{}{}
+		<-release
+		return c.NoContent(http.StatusOK)
+	}
+	e.GET("/reports", slow)
+	e.Host("api.example.com").GET("/reports", slow)
+	e.Host("admin.example.com").GET("/reports", slow)
+
+	done := make(chan struct{})
+	for _, host := range []string{"example.com", "api.example.com", "api.example.com"} {
+		req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+		req.Host = host
+		go func(req *http.Request) {
+			e.ServeHTTP(httptest.NewRecorder(), req)
+			done <- struct{}{}
+		}(req)
+	}
+	for i := 0; i < 3; i++ {
+		<-entered
+	}
+
+	status := e.DrainStatus()
+	assert.Equal(t, int64(3), status.InFlight)
+	assert.Equal(t, []RouteInFlight{
+		{Method: http.MethodGet, Path: "/reports", InFlight: 1},
+		{Host: "api.example.com", Method: http.MethodGet, Path: "/reports", InFlight: 2},
+	}, status.Routes)
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+}
+
+func TestEcho_DrainStatusTrackedAfterRegistration(t *testing.T) {
+	e := New()
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	e.GET("/", func(c Context) error {
+		close(entered)
+		<-release
+		return nil
+	})
+	e.TrackInFlight = true
+	go e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	<-entered
+	assert.Equal(t, int64(0), e.DrainStatus().InFlight)
+	close(release)
+}
+
+func TestEcho_LongLived(t *testing.T) {
+	e := New()
+	drain1, done1 := e.LongLived()
+	drain2, done2 := e.LongLived()
+	assert.Equal(t, 2, e.DrainStatus().LongLived)
+
+	assert.Equal(t, 2, e.CloseLongLived("bye"))
+	assert.Equal(t, "bye", <-drain1)
+	assert.Equal(t, "bye", <-drain2)
+
+	done1()
+	done1() // second call is no-op
+	assert.Equal(t, 1, e.DrainStatus().LongLived)
+	assert.Equal(t, 1, e.CloseLongLived("again"))
+	done2()
+	assert.Equal(t, 0, e.DrainStatus().LongLived)
+}
+
+func TestEcho_ShutdownClosesLongLived(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.GET("/events", func(c Context) error {
+		drain, done := c.Echo().LongLived()
+		defer done()
+		c.Response().WriteHeader(http.StatusOK)
+		c.Response().Flush()
+		return WriteSSEShutdown(c, <-drain)
+	})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.Start("127.0.0.1:0")
+	}()
+	require.NoError(t, waitForServerStart(e, errs, false))
+
+	res, err := http.Get("http://" + e.ListenerAddr().String() + "/events")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Eventually(t, func() bool {
+		return e.DrainStatus().LongLived == 1
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, e.Shutdown(ctx))
+	assert.Equal(t, http.ErrServerClosed, <-errs)
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "event: shutdown\ndata: "+DefaultDrainMessage+"\n\n", string(body))
+	status := e.DrainStatus()
+	assert.True(t, status.ShuttingDown)
+	assert.NotNil(t, status.ShutdownStarted)
+}
+
+func TestDrainHandler(t *testing.T) {
+	e := New()
+	drain, done := e.LongLived()
+	defer done()
+	e.Match([]string{http.MethodGet, http.MethodPost}, "/drain", DrainHandler(e))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/drain", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var status DrainStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, 1, status.LongLived)
+	assert.Len(t, drain, 0)
+
+	req := httptest.NewRequest(http.MethodPost, "/drain", strings.NewReader(url.Values{"message": {"redeploy"}}.Encode()))
+	req.Header.Set(HeaderContentType, MIMEApplicationForm)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "redeploy", <-drain)
+}
+
+func TestEcho_ShutdownWaitsForLongLived(t *testing.T) {
+	e := New()
+	_, done := e.LongLived()
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, stdContext.DeadlineExceeded, e.Shutdown(ctx))
+
+	done()
+	assert.NoError(t, e.Shutdown(stdContext.Background()))
+}
+
+func TestWriteSSEShutdown(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	require.NoError(t, WriteSSEShutdown(c, "bye\nsee you"))
+	assert.Equal(t, "event: shutdown\ndata: bye\ndata: see you\n\n", rec.Body.String())
+	assert.True(t, rec.Flushed)
+}
+
+func TestWebSocketShutdownClose(t *testing.T) {
+	assert.Equal(t, []byte{0x03, 0xe9, 'b', 'y', 'e'}, WebSocketShutdownClose("bye"))
+
+	payload := WebSocketShutdownClose(strings.Repeat("a", 122) + "é")
+	assert.Len(t, payload, 2+122)
+
+	payload = WebSocketShutdownClose(strings.Repeat("a", 200))
+	assert.Len(t, payload, 125)
+}
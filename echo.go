@@ -53,6 +53,7 @@ import (
 	"reflect"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/labstack/gommon/color"
@@ -77,6 +78,19 @@ type (
 		maxParam         *int
 		router           *Router
 		routers          map[string]*Router
+		routerEngine     RouterEngine
+		codecs           map[string]Codec
+		conns            connTracker
+		shuttingDown     int32
+		drain            drainTracker
+		logKeys          map[string]LogRedactFunc
+		startHooks       []func() error
+		shutdownHooks    []func(stdContext.Context) error
+		hooksStarted     bool
+		autoTLSCerts     []tls.Certificate
+		zeroAlloc        bool
+		engine           ServerEngine
+		chain            HandlerFunc
 		notFoundHandler  HandlerFunc
 		pool             sync.Pool
 		Server           *http.Server
@@ -91,11 +105,68 @@ type (
 		HTTPErrorHandler HTTPErrorHandler
 		Binder           Binder
 		JSONSerializer   JSONSerializer
+		XMLSerializer    XMLSerializer
 		Validator        Validator
 		Renderer         Renderer
 		Logger           Logger
 		IPExtractor      IPExtractor
 		ListenerNetwork  string
+		// ListenerWrapper wraps listeners created by Echo start methods, e.g. `ProxyProtocolListener`. For TLS
+		// servers listener is wrapped before TLS layer.
+		ListenerWrapper func(net.Listener) net.Listener
+
+		// RecordPhaseTimings enables recording of request processing phase durations. See `PhaseTimingsOf()`.
+		RecordPhaseTimings bool
+
+		// TrackInFlight enables counting of requests being handled per route. It applies to routes registered after
+		// it was enabled. See `Echo#DrainStatus()`.
+		TrackInFlight bool
+
+		// Clock is the source of current time for time dependent middlewares (rate limiter, JWT, caches, loggers).
+		// Timeout middleware relies on runtime timers and is not affected by it.
+		Clock Clock
+
+		// Redactor removes personal data and secrets from values written to logs, body dumps, audit events and
+		// error reports by Echo middlewares.
+		// Optional. Default value copy of DefaultRedactor, nil disables redaction.
+		Redactor *Redactor
+
+		// Localizer translates messages of field errors returned by `Context#Bind()` and `Context#Validate()` to
+		// language negotiated with `Accept-Language` request header.
+		Localizer Localizer
+
+		// BufferPool provides buffers for rendering templates, feeds and JSON (in zero allocation mode).
+		BufferPool *BufferPool
+
+		// MaxConnections limits number of open connections of servers started by Echo. Requests over connections
+		// accepted above the limit are answered with 503 and connection is closed. See `Echo#ConnStats()`.
+		// Zero value means no limit.
+		MaxConnections int
+
+		// ConnLifecycle configures keep-alive and connection cycling of servers started by Echo.
+		ConnLifecycle ConnLifecycleConfig
+
+		// TLSConfig is base TLS config used by StartTLS and StartAutoTLS. Config is cloned and certificates (and
+		// ALPN protocols needed by Echo) are added to the clone, all other settings (curves, cipher suites,
+		// session tickets, client authentication etc.) are used as is.
+		// Optional. Default value nil (Go defaults).
+		TLSConfig *tls.Config
+
+		// AutoOptions enables automatic answering of OPTIONS requests to paths without OPTIONS route with 204 and
+		// `Allow` header listing methods registered for the path. Without it such requests get 405 as any other
+		// method without route.
+		// Optional. Default value false.
+		AutoOptions bool
+
+		// PathNormalization defines treatment of encoded slashes, double slashes and dot segments in request paths
+		// before routing. Routes can override it with `Route#PathNormalization()`.
+		// Optional. Default value passes paths through unchanged.
+		PathNormalization PathNormalizationConfig
+
+		// RequestLimits limits URL length, number of query parameters and size of request headers. Limits are
+		// checked before pre-middlewares and routing.
+		// Optional. Default value no limits.
+		RequestLimits RequestLimitsConfig
 	}
 
 	// Route contains a handler and information for matching against requests.
@@ -103,6 +174,7 @@ type (
 		Method string `json:"method"`
 		Path   string `json:"path"`
 		Name   string `json:"name"`
+		meta   Map
 	}
 
 	// HTTPError represents an error that occurred while handling a request.
@@ -132,6 +204,12 @@ type (
 		Deserialize(c Context, i interface{}) error
 	}
 
+	// XMLSerializer is the interface that encodes and decodes XML to and from interfaces.
+	XMLSerializer interface {
+		Serialize(c Context, i interface{}, indent string) error
+		Deserialize(c Context, i interface{}) error
+	}
+
 	// Renderer is the interface that wraps the Render function.
 	Renderer interface {
 		Render(io.Writer, string, interface{}, Context) error
@@ -163,12 +241,19 @@ const (
 const (
 	MIMEApplicationJSON                  = "application/json"
 	MIMEApplicationJSONCharsetUTF8       = MIMEApplicationJSON + "; " + charsetUTF8
+	MIMEApplicationProblemJSON           = "application/problem+json"
+	MIMEApplicationJSONPatch             = "application/json-patch+json"
+	MIMEApplicationMergePatch            = "application/merge-patch+json"
 	MIMEApplicationJavaScript            = "application/javascript"
 	MIMEApplicationJavaScriptCharsetUTF8 = MIMEApplicationJavaScript + "; " + charsetUTF8
 	MIMEApplicationXML                   = "application/xml"
 	MIMEApplicationXMLCharsetUTF8        = MIMEApplicationXML + "; " + charsetUTF8
 	MIMETextXML                          = "text/xml"
 	MIMETextXMLCharsetUTF8               = MIMETextXML + "; " + charsetUTF8
+	MIMEApplicationRSSXML                = "application/rss+xml"
+	MIMEApplicationRSSXMLCharsetUTF8     = MIMEApplicationRSSXML + "; " + charsetUTF8
+	MIMEApplicationAtomXML               = "application/atom+xml"
+	MIMEApplicationAtomXMLCharsetUTF8    = MIMEApplicationAtomXML + "; " + charsetUTF8
 	MIMEApplicationForm                  = "application/x-www-form-urlencoded"
 	MIMEApplicationProtobuf              = "application/protobuf"
 	MIMEApplicationMsgpack               = "application/msgpack"
@@ -176,6 +261,12 @@ const (
 	MIMETextHTMLCharsetUTF8              = MIMETextHTML + "; " + charsetUTF8
 	MIMETextPlain                        = "text/plain"
 	MIMETextPlainCharsetUTF8             = MIMETextPlain + "; " + charsetUTF8
+	MIMETextCSV                          = "text/csv"
+	MIMETextCSVCharsetUTF8               = MIMETextCSV + "; " + charsetUTF8
+	MIMEApplicationXLSX                  = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MIMEApplicationPDF                   = "application/pdf"
+	MIMEApplicationZip                   = "application/zip"
+	MIMEApplicationGzip                  = "application/gzip"
 	MIMEMultipartForm                    = "multipart/form-data"
 	MIMEOctetStream                      = "application/octet-stream"
 )
@@ -192,17 +283,29 @@ const (
 const (
 	HeaderAccept              = "Accept"
 	HeaderAcceptEncoding      = "Accept-Encoding"
+	HeaderAcceptLanguage      = "Accept-Language"
+	HeaderAcceptRanges        = "Accept-Ranges"
 	HeaderAllow               = "Allow"
 	HeaderAuthorization       = "Authorization"
+	HeaderCacheControl        = "Cache-Control"
+	HeaderConnection          = "Connection"
 	HeaderContentDisposition  = "Content-Disposition"
 	HeaderContentEncoding     = "Content-Encoding"
+	HeaderContentLanguage     = "Content-Language"
 	HeaderContentLength       = "Content-Length"
+	HeaderContentRange        = "Content-Range"
 	HeaderContentType         = "Content-Type"
 	HeaderCookie              = "Cookie"
 	HeaderSetCookie           = "Set-Cookie"
+	HeaderETag                = "ETag"
+	HeaderIfMatch             = "If-Match"
 	HeaderIfModifiedSince     = "If-Modified-Since"
+	HeaderIfNoneMatch         = "If-None-Match"
 	HeaderLastModified        = "Last-Modified"
+	HeaderLink                = "Link"
 	HeaderLocation            = "Location"
+	HeaderRange               = "Range"
+	HeaderRetryAfter          = "Retry-After"
 	HeaderUpgrade             = "Upgrade"
 	HeaderVary                = "Vary"
 	HeaderWWWAuthenticate     = "WWW-Authenticate"
@@ -215,8 +318,15 @@ const (
 	HeaderXRealIP             = "X-Real-IP"
 	HeaderXRequestID          = "X-Request-ID"
 	HeaderXCorrelationID      = "X-Correlation-ID"
+	HeaderXRequestTimeout     = "X-Request-Timeout"
+	HeaderGrpcTimeout         = "Grpc-Timeout"
 	HeaderXRequestedWith      = "X-Requested-With"
 	HeaderServer              = "Server"
+	HeaderServerTiming        = "Server-Timing"
+	HeaderUserAgent           = "User-Agent"
+	HeaderDeprecation         = "Deprecation"
+	HeaderExpect              = "Expect"
+	HeaderSunset              = "Sunset"
 	HeaderOrigin              = "Origin"
 
 	// Access control
@@ -280,13 +390,17 @@ var (
 	ErrUnauthorized                = NewHTTPError(http.StatusUnauthorized)
 	ErrForbidden                   = NewHTTPError(http.StatusForbidden)
 	ErrMethodNotAllowed            = NewHTTPError(http.StatusMethodNotAllowed)
+	ErrNotAcceptable               = NewHTTPError(http.StatusNotAcceptable)
 	ErrStatusRequestEntityTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge)
+	ErrExpectationFailed           = NewHTTPError(http.StatusExpectationFailed)
 	ErrTooManyRequests             = NewHTTPError(http.StatusTooManyRequests)
 	ErrBadRequest                  = NewHTTPError(http.StatusBadRequest)
 	ErrBadGateway                  = NewHTTPError(http.StatusBadGateway)
 	ErrInternalServerError         = NewHTTPError(http.StatusInternalServerError)
 	ErrRequestTimeout              = NewHTTPError(http.StatusRequestTimeout)
 	ErrServiceUnavailable          = NewHTTPError(http.StatusServiceUnavailable)
+	ErrPreconditionFailed          = NewHTTPError(http.StatusPreconditionFailed)
+	ErrPreconditionRequired        = NewHTTPError(http.StatusPreconditionRequired)
 	ErrValidatorNotRegistered      = errors.New("validator not registered")
 	ErrRendererNotRegistered       = errors.New("renderer not registered")
 	ErrInvalidRedirectCode         = errors.New("invalid redirect status code")
@@ -306,6 +420,23 @@ var (
 	}
 )
 
+// methodNotAllowedHandler sets `Allow` header of the response when it is written and calls MethodNotAllowedHandler.
+func methodNotAllowedHandler(c Context) error {
+	res := c.Response()
+	res.Before(func() {
+		if allow := AllowedMethods(c); allow != "" {
+			res.Header().Set(HeaderAllow, allow)
+		}
+	})
+	return MethodNotAllowedHandler(c)
+}
+
+// optionsMethodHandler answers OPTIONS request to path without OPTIONS route.
+func optionsMethodHandler(c Context) error {
+	c.Response().Header().Set(HeaderAllow, AllowedMethods(c))
+	return c.NoContent(http.StatusNoContent)
+}
+
 // New creates an instance of Echo.
 func New() (e *Echo) {
 	e = &Echo{
@@ -324,6 +455,11 @@ func New() (e *Echo) {
 	e.HTTPErrorHandler = e.DefaultHTTPErrorHandler
 	e.Binder = &DefaultBinder{}
 	e.JSONSerializer = &DefaultJSONSerializer{}
+	e.XMLSerializer = &DefaultXMLSerializer{}
+	e.Clock = SystemClock
+	redactor := DefaultRedactor
+	e.Redactor = &redactor
+	e.BufferPool = NewBufferPool()
 	e.Logger.SetLevel(log.ERROR)
 	e.StdLogger = stdLog.New(e.Logger.Output(), e.Logger.Prefix()+": ", 0)
 	e.pool.New = func() interface{} {
@@ -351,6 +487,16 @@ func (e *Echo) Router() *Router {
 	return e.router
 }
 
+// UseRouter selects router engine used to match requests for default and all host routers. Routes that are
+// already registered are kept.
+func (e *Echo) UseRouter(engine RouterEngine) {
+	e.routerEngine = engine
+	e.router.useEngine(engine)
+	for _, r := range e.routers {
+		r.useEngine(engine)
+	}
+}
+
 // Routers returns the map of host => router.
 func (e *Echo) Routers() map[string]*Router {
 	return e.routers
@@ -408,11 +554,13 @@ func (e *Echo) DefaultHTTPErrorHandler(err error, c Context) {
 // Pre adds middleware to the chain which is run before router.
 func (e *Echo) Pre(middleware ...MiddlewareFunc) {
 	e.premiddleware = append(e.premiddleware, middleware...)
+	e.buildChain()
 }
 
 // Use adds middleware to the chain which is run after router.
 func (e *Echo) Use(middleware ...MiddlewareFunc) {
 	e.middleware = append(e.middleware, middleware...)
+	e.buildChain()
 }
 
 // CONNECT registers a new CONNECT route for a path with matching handler in the
@@ -548,16 +696,24 @@ func (e *Echo) File(path, file string, m ...MiddlewareFunc) *Route {
 func (e *Echo) add(host, method, path string, handler HandlerFunc, middleware ...MiddlewareFunc) *Route {
 	name := handlerName(handler)
 	router := e.findRouter(host)
-	router.Add(method, path, func(c Context) error {
-		h := applyMiddleware(handler, middleware...)
-		return h(c)
-	})
+	timedHandler := timeHandler(handler)
+	if e.TrackInFlight {
+		timedHandler = trackInFlight(e.drain.inFlightCounter(host, method, path), timedHandler)
+	}
+	if e.zeroAlloc {
+		router.Add(method, path, applyMiddleware(timedHandler, middleware...))
+	} else {
+		router.Add(method, path, func(c Context) error {
+			h := applyMiddleware(timedHandler, middleware...)
+			return h(c)
+		})
+	}
 	r := &Route{
 		Method: method,
 		Path:   path,
 		Name:   name,
 	}
-	e.router.routes[method+path] = r
+	router.routes[method+path] = r
 	return r
 }
 
@@ -569,7 +725,9 @@ func (e *Echo) Add(method, path string, handler HandlerFunc, middleware ...Middl
 
 // Host creates a new router group for the provided host and optional host-level middleware.
 func (e *Echo) Host(name string, m ...MiddlewareFunc) (g *Group) {
-	e.routers[name] = NewRouter(e)
+	router := NewRouter(e)
+	router.useEngine(e.routerEngine)
+	e.routers[name] = router
 	g = &Group{host: name, echo: e}
 	g.Use(m...)
 	return
@@ -598,7 +756,7 @@ func (e *Echo) Reverse(name string, params ...interface{}) string {
 	uri := new(bytes.Buffer)
 	ln := len(params)
 	n := 0
-	for _, r := range e.router.routes {
+	for _, r := range e.Routes() {
 		if r.Name == name {
 			for i, l := 0, len(r.Path); i < l; i++ {
 				if (r.Path[i] == ':' || r.Path[i] == '*') && n < ln {
@@ -623,6 +781,11 @@ func (e *Echo) Routes() []*Route {
 	for _, v := range e.router.routes {
 		routes = append(routes, v)
 	}
+	for _, router := range e.routers {
+		for _, v := range router.routes {
+			routes = append(routes, v)
+		}
+	}
 	return routes
 }
 
@@ -643,15 +806,30 @@ func (e *Echo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Acquire context
 	c := e.pool.Get().(*context)
 	c.Reset(r, w)
+	if e.MaxConnections > 0 && isConnRejected(r) {
+		e.serveConnRejected(c)
+		e.pool.Put(c)
+		return
+	}
+	if err := e.RequestLimits.check(r); err != nil {
+		e.HTTPErrorHandler(err, c)
+		e.pool.Put(c)
+		return
+	}
+	if e.shouldCloseConn(r) {
+		c.response.Header().Set(HeaderConnection, "close")
+	}
 	h := NotFoundHandler
 
-	if e.premiddleware == nil {
-		e.findRouter(r.Host).Find(r.Method, GetPath(r), c)
+	if e.chain != nil {
+		h = e.chain
+	} else if e.premiddleware == nil {
+		e.find(r, c)
 		h = c.Handler()
 		h = applyMiddleware(h, e.middleware...)
 	} else {
 		h = func(c Context) error {
-			e.findRouter(r.Host).Find(r.Method, GetPath(r), c)
+			e.find(r, c)
 			h := c.Handler()
 			h = applyMiddleware(h, e.middleware...)
 			return h(c)
@@ -661,7 +839,7 @@ func (e *Echo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Execute chain
 	if err := h(c); err != nil {
-		e.HTTPErrorHandler(err, c)
+		c.Error(err)
 	}
 
 	// Release context
@@ -698,12 +876,13 @@ func (e *Echo) StartTLS(address string, certFile, keyFile interface{}) (err erro
 	}
 
 	s := e.TLSServer
-	s.TLSConfig = new(tls.Config)
-	s.TLSConfig.Certificates = make([]tls.Certificate, 1)
-	if s.TLSConfig.Certificates[0], err = tls.X509KeyPair(cert, key); err != nil {
+	s.TLSConfig = e.newTLSConfig()
+	var certificate tls.Certificate
+	if certificate, err = tls.X509KeyPair(cert, key); err != nil {
 		e.startupMutex.Unlock()
 		return
 	}
+	s.TLSConfig.Certificates = append([]tls.Certificate{certificate}, s.TLSConfig.Certificates...)
 
 	e.configureTLS(address)
 	if err := e.configureServer(s); err != nil {
@@ -729,9 +908,9 @@ func filepathOrContent(fileOrContent interface{}) (content []byte, err error) {
 func (e *Echo) StartAutoTLS(address string) error {
 	e.startupMutex.Lock()
 	s := e.TLSServer
-	s.TLSConfig = new(tls.Config)
-	s.TLSConfig.GetCertificate = e.AutoTLSManager.GetCertificate
-	s.TLSConfig.NextProtos = append(s.TLSConfig.NextProtos, acme.ALPNProto)
+	s.TLSConfig = e.newTLSConfig()
+	s.TLSConfig.GetCertificate = e.getAutoTLSCertificate
+	s.TLSConfig.NextProtos = appendNextProto(s.TLSConfig.NextProtos, acme.ALPNProto)
 
 	e.configureTLS(address)
 	if err := e.configureServer(s); err != nil {
@@ -746,10 +925,27 @@ func (e *Echo) configureTLS(address string) {
 	s := e.TLSServer
 	s.Addr = address
 	if !e.DisableHTTP2 {
-		s.TLSConfig.NextProtos = append(s.TLSConfig.NextProtos, "h2")
+		s.TLSConfig.NextProtos = appendNextProto(s.TLSConfig.NextProtos, "h2")
 	}
 }
 
+// newTLSConfig returns clone of `Echo#TLSConfig` or empty config when it is not set.
+func (e *Echo) newTLSConfig() *tls.Config {
+	if e.TLSConfig == nil {
+		return new(tls.Config)
+	}
+	return e.TLSConfig.Clone()
+}
+
+func appendNextProto(protos []string, proto string) []string {
+	for _, p := range protos {
+		if p == proto {
+			return protos
+		}
+	}
+	return append(protos, proto)
+}
+
 // StartServer starts a custom http server.
 func (e *Echo) StartServer(s *http.Server) (err error) {
 	e.startupMutex.Lock()
@@ -770,6 +966,10 @@ func (e *Echo) configureServer(s *http.Server) (err error) {
 	e.colorer.SetOutput(e.Logger.Output())
 	s.ErrorLog = e.StdLogger
 	s.Handler = e
+	if s.MaxHeaderBytes == 0 {
+		s.MaxHeaderBytes = e.RequestLimits.serverMaxHeaderBytes()
+	}
+	e.trackConnections(s)
 	if e.Debug {
 		e.Logger.SetLevel(log.DEBUG)
 	}
@@ -780,7 +980,7 @@ func (e *Echo) configureServer(s *http.Server) (err error) {
 
 	if s.TLSConfig == nil {
 		if e.Listener == nil {
-			e.Listener, err = newListener(s.Addr, e.ListenerNetwork)
+			e.Listener, err = e.listen(s.Addr)
 			if err != nil {
 				return err
 			}
@@ -788,10 +988,10 @@ func (e *Echo) configureServer(s *http.Server) (err error) {
 		if !e.HidePort {
 			e.colorer.Printf("⇨ http server started on %s\n", e.colorer.Green(e.Listener.Addr()))
 		}
-		return nil
+		return e.runStartHooks()
 	}
 	if e.TLSListener == nil {
-		l, err := newListener(s.Addr, e.ListenerNetwork)
+		l, err := e.listen(s.Addr)
 		if err != nil {
 			return err
 		}
@@ -800,7 +1000,7 @@ func (e *Echo) configureServer(s *http.Server) (err error) {
 	if !e.HidePort {
 		e.colorer.Printf("⇨ https server started on %s\n", e.colorer.Green(e.TLSListener.Addr()))
 	}
-	return nil
+	return e.runStartHooks()
 }
 
 // ListenerAddr returns net.Addr for Listener
@@ -832,6 +1032,7 @@ func (e *Echo) StartH2CServer(address string, h2s *http2.Server) (err error) {
 	e.colorer.SetOutput(e.Logger.Output())
 	s.ErrorLog = e.StdLogger
 	s.Handler = h2c.NewHandler(e, h2s)
+	e.trackConnections(s)
 	if e.Debug {
 		e.Logger.SetLevel(log.DEBUG)
 	}
@@ -841,7 +1042,7 @@ func (e *Echo) StartH2CServer(address string, h2s *http2.Server) (err error) {
 	}
 
 	if e.Listener == nil {
-		e.Listener, err = newListener(s.Addr, e.ListenerNetwork)
+		e.Listener, err = e.listen(s.Addr)
 		if err != nil {
 			e.startupMutex.Unlock()
 			return err
@@ -850,6 +1051,10 @@ func (e *Echo) StartH2CServer(address string, h2s *http2.Server) (err error) {
 	if !e.HidePort {
 		e.colorer.Printf("⇨ http server started on %s\n", e.colorer.Green(e.Listener.Addr()))
 	}
+	if err := e.runStartHooks(); err != nil {
+		e.startupMutex.Unlock()
+		return err
+	}
 	e.startupMutex.Unlock()
 	return s.Serve(e.Listener)
 }
@@ -859,6 +1064,9 @@ func (e *Echo) StartH2CServer(address string, h2s *http2.Server) (err error) {
 func (e *Echo) Close() error {
 	e.startupMutex.Lock()
 	defer e.startupMutex.Unlock()
+	if e.engine != nil {
+		return e.engine.Close()
+	}
 	if err := e.TLSServer.Close(); err != nil {
 		return err
 	}
@@ -867,9 +1075,30 @@ func (e *Echo) Close() error {
 
 // Shutdown stops the server gracefully.
 // It internally calls `http.Server#Shutdown()`.
+// Long-lived connections registered with `Echo#LongLived()` are asked to close first and waited for.
+// Hooks registered with `Echo#OnShutdown()` are run after the server is shut down.
 func (e *Echo) Shutdown(ctx stdContext.Context) error {
+	e.startShutdown()
 	e.startupMutex.Lock()
 	defer e.startupMutex.Unlock()
+	if err := e.shutdownServers(ctx); err != nil {
+		return err
+	}
+	if err := e.waitLongLived(ctx); err != nil {
+		return err
+	}
+	return e.runShutdownHooks(ctx)
+}
+
+func (e *Echo) shutdownServers(ctx stdContext.Context) error {
+	if e.engine != nil {
+		return e.engine.Shutdown(ctx)
+	}
+	if e.ConnLifecycle.DisableKeepAlivesOnShutdown {
+		atomic.StoreInt32(&e.shuttingDown, 1)
+		e.TLSServer.SetKeepAlivesEnabled(false)
+		e.Server.SetKeepAlivesEnabled(false)
+	}
 	if err := e.TLSServer.Shutdown(ctx); err != nil {
 		return err
 	}
@@ -991,6 +1220,35 @@ func newListener(address, network string) (*tcpKeepAliveListener, error) {
 	return &tcpKeepAliveListener{l.(*net.TCPListener)}, nil
 }
 
+// listen creates listener for the address and wraps it with `Echo#ListenerWrapper`.
+func (e *Echo) listen(address string) (net.Listener, error) {
+	l, err := newListener(address, e.ListenerNetwork)
+	if err != nil {
+		return nil, err
+	}
+	var ln net.Listener = l
+	if e.ListenerWrapper != nil {
+		ln = e.ListenerWrapper(ln)
+	}
+	return ln, nil
+}
+
+// ListenerConfigurator chains function that configures (wraps) listeners created by Echo start methods to
+// `Echo#ListenerWrapper`. Configurator is applied to the listener returned by the current wrapper, so configurators
+// are applied in order of registration.
+func (e *Echo) ListenerConfigurator(configurator func(net.Listener) net.Listener) {
+	e.startupMutex.Lock()
+	defer e.startupMutex.Unlock()
+	wrapper := e.ListenerWrapper
+	if wrapper == nil {
+		e.ListenerWrapper = configurator
+		return
+	}
+	e.ListenerWrapper = func(l net.Listener) net.Listener {
+		return configurator(wrapper(l))
+	}
+}
+
 func applyMiddleware(h HandlerFunc, middleware ...MiddlewareFunc) HandlerFunc {
 	for i := len(middleware) - 1; i >= 0; i-- {
 		h = middleware[i](h)
@@ -14,6 +14,7 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -444,7 +445,7 @@ func TestEchoURL(t *testing.T) {
 
 func TestEchoRoutes(t *testing.T) {
 	e := New()
-	routes := []*Route{
+	routes := []*testRoute{
 		{http.MethodGet, "/users/:user/events", ""},
 		{http.MethodGet, "/users/:user/events/public", ""},
 		{http.MethodPost, "/repos/:owner/:repo/git/refs", ""},
@@ -475,7 +476,7 @@ func TestEchoRoutes(t *testing.T) {
 func TestEchoRoutesHandleHostsProperly(t *testing.T) {
 	e := New()
 	h := e.Host("route.com")
-	routes := []*Route{
+	routes := []*testRoute{
 		{http.MethodGet, "/users/:user/events", ""},
 		{http.MethodGet, "/users/:user/events/public", ""},
 		{http.MethodPost, "/repos/:owner/:repo/git/refs", ""},
@@ -1292,6 +1293,96 @@ func TestEchoListenerNetworkInvalid(t *testing.T) {
 	assert.Equal(t, ErrInvalidListenerNetwork, e.Start(":1323"))
 }
 
+type countingListener struct {
+	net.Listener
+	accepted *int32
+}
+
+func (l countingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err == nil {
+		atomic.AddInt32(l.accepted, 1)
+	}
+	return c, err
+}
+
+func TestEchoListenerConfigurator(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+
+	order := []string{}
+	var accepted int32
+	e.ListenerWrapper = func(l net.Listener) net.Listener {
+		order = append(order, "wrapper")
+		return l
+	}
+	e.ListenerConfigurator(func(l net.Listener) net.Listener {
+		order = append(order, "first")
+		return l
+	})
+	e.ListenerConfigurator(func(l net.Listener) net.Listener {
+		order = append(order, "second")
+		return countingListener{Listener: l, accepted: &accepted}
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.Start("127.0.0.1:0")
+	}()
+	require.NoError(t, waitForServerStart(e, errCh, false))
+	defer e.Close()
+
+	res, err := http.Get("http://" + e.ListenerAddr().String())
+	require.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, []string{"wrapper", "first", "second"}, order)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&accepted))
+}
+
+func TestEchoStartTLS_TLSConfig(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.TLSConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		MaxVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		ClientAuth: tls.RequestClientCert,
+	}
+	e.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.StartTLS("127.0.0.1:0", "_fixture/certs/cert.pem", "_fixture/certs/key.pem")
+	}()
+	require.NoError(t, waitForServerStart(e, errCh, true))
+	defer e.Close()
+
+	// user config is cloned, not modified
+	assert.Len(t, e.TLSConfig.Certificates, 0)
+	assert.Equal(t, []string{"h2", "http/1.1"}, e.TLSConfig.NextProtos)
+	assert.Equal(t, []string{"h2", "http/1.1"}, e.TLSServer.TLSConfig.NextProtos)
+	assert.Equal(t, tls.RequestClientCert, e.TLSServer.TLSConfig.ClientAuth)
+	assert.Len(t, e.TLSServer.TLSConfig.Certificates, 1)
+
+	conn, err := tls.Dial("tcp", e.TLSListenerAddr().String(), &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, uint16(tls.VersionTLS12), conn.ConnectionState().Version)
+
+	_, err = tls.Dial("tcp", e.TLSListenerAddr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS13,
+	})
+	assert.Error(t, err)
+}
+
 func TestEchoReverse(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1431,7 +1522,7 @@ func TestEcho_StartServer(t *testing.T) {
 	}
 }
 
-func benchmarkEchoRoutes(b *testing.B, routes []*Route) {
+func benchmarkEchoRoutes(b *testing.B, routes []*testRoute) {
 	e := New()
 	req := httptest.NewRequest("GET", "/", nil)
 	u := req.URL
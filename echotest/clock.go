@@ -0,0 +1,46 @@
+package echotest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a fake `echo.Clock` implementation. Time stands still until it is moved with Advance or Set.
+//
+// Example:
+//
+//	e := echo.New()
+//	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+//	e.Clock = clock
+//	...
+//	clock.Advance(time.Hour) // expire cached entries, tokens, rate limiter visitors etc.
+type Clock struct {
+	mutex sync.RWMutex
+	now   time.Time
+}
+
+// NewClock returns fake clock set to given time.
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now returns current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.now
+}
+
+// Advance moves clock forward by given duration.
+func (c *Clock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+	c.mutex.Unlock()
+}
+
+// Set sets clock to given time.
+func (c *Clock) Set(t time.Time) {
+	c.mutex.Lock()
+	c.now = t
+	c.mutex.Unlock()
+}
@@ -0,0 +1,261 @@
+/*
+Package echotest provides a test client driving requests through the full middleware stack of an Echo instance.
+
+Example:
+
+	func TestGetUser(t *testing.T) {
+		e := echo.New()
+		e.Use(middleware.JWT([]byte("secret")))
+		e.GET("/users/:id", getUser)
+
+		client := echotest.New(e)
+		client.JWTSigningKey = []byte("secret")
+
+		client.GET("/users/1").
+			WithJWT(jwt.MapClaims{"name": "John"}).
+			Expect(t).
+			Status(http.StatusOK).
+			JSONPath("$.name", "John")
+	}
+
+Exchanges made by the client are recorded and can be asserted to conform to an OpenAPI document with
+`AssertContract`.
+*/
+package echotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Client sends requests to an Echo instance without starting a server.
+	Client struct {
+		echo *echo.Echo
+
+		// Header is added to every request sent by the client.
+		Header http.Header
+
+		// JWTSigningKey is the key used to sign tokens created by `Request.WithJWT`.
+		JWTSigningKey interface{}
+
+		// JWTSigningMethod is the method used to sign tokens created by `Request.WithJWT`.
+		// Default value jwt.SigningMethodHS256.
+		JWTSigningMethod jwt.SigningMethod
+
+		mutex     sync.Mutex
+		exchanges []*Exchange
+	}
+
+	// Exchange is a request sent by the client together with the recorded response.
+	Exchange struct {
+		Request     *http.Request
+		RequestBody []byte
+		Response    *httptest.ResponseRecorder
+	}
+
+	// Request is a request being built. Methods return the request itself so calls could be chained.
+	Request struct {
+		client *Client
+		method string
+		path   string
+		header http.Header
+		query  url.Values
+		body   io.Reader
+		err    error
+	}
+
+	// TestingT is the interface of `*testing.T` used by response assertions.
+	TestingT interface {
+		Helper()
+		Errorf(format string, args ...interface{})
+	}
+)
+
+// New creates a new test client for given Echo instance.
+func New(e *echo.Echo) *Client {
+	return &Client{
+		echo:             e,
+		Header:           http.Header{},
+		JWTSigningMethod: jwt.SigningMethodHS256,
+	}
+}
+
+// GET starts building a GET request to path.
+func (c *Client) GET(path string) *Request {
+	return c.NewRequest(http.MethodGet, path)
+}
+
+// POST starts building a POST request to path.
+func (c *Client) POST(path string) *Request {
+	return c.NewRequest(http.MethodPost, path)
+}
+
+// PUT starts building a PUT request to path.
+func (c *Client) PUT(path string) *Request {
+	return c.NewRequest(http.MethodPut, path)
+}
+
+// PATCH starts building a PATCH request to path.
+func (c *Client) PATCH(path string) *Request {
+	return c.NewRequest(http.MethodPatch, path)
+}
+
+// DELETE starts building a DELETE request to path.
+func (c *Client) DELETE(path string) *Request {
+	return c.NewRequest(http.MethodDelete, path)
+}
+
+// HEAD starts building a HEAD request to path.
+func (c *Client) HEAD(path string) *Request {
+	return c.NewRequest(http.MethodHead, path)
+}
+
+// OPTIONS starts building an OPTIONS request to path.
+func (c *Client) OPTIONS(path string) *Request {
+	return c.NewRequest(http.MethodOptions, path)
+}
+
+// NewRequest starts building a request with given method to path. Path may contain query string.
+func (c *Client) NewRequest(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: http.Header{},
+		query:  url.Values{},
+	}
+}
+
+// WithHeader sets request header.
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithQuery adds query parameter to request URL.
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie adds cookie to request.
+func (r *Request) WithCookie(cookie *http.Cookie) *Request {
+	r.header.Add("Cookie", cookie.String())
+	return r
+}
+
+// WithBody sets request body with given content type.
+func (r *Request) WithBody(contentType string, body io.Reader) *Request {
+	r.header.Set(echo.HeaderContentType, contentType)
+	r.body = body
+	return r
+}
+
+// WithJSON sets request body to JSON encoding of v.
+func (r *Request) WithJSON(v interface{}) *Request {
+	b, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	return r.WithBody(echo.MIMEApplicationJSON, bytes.NewReader(b))
+}
+
+// WithForm sets request body to URL encoded form values.
+func (r *Request) WithForm(values url.Values) *Request {
+	return r.WithBody(echo.MIMEApplicationForm, strings.NewReader(values.Encode()))
+}
+
+// WithJWT sets Authorization header to bearer token with given claims signed with `Client.JWTSigningKey`.
+func (r *Request) WithJWT(claims jwt.Claims) *Request {
+	token, err := jwt.NewWithClaims(r.client.JWTSigningMethod, claims).SignedString(r.client.JWTSigningKey)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	return r.WithHeader(echo.HeaderAuthorization, "Bearer "+token)
+}
+
+// HTTPRequest returns the built `*http.Request` or an error when building request failed.
+func (r *Request) HTTPRequest() (*http.Request, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var body io.Reader
+	if r.body != nil {
+		b, err := ioutil.ReadAll(r.body)
+		if err != nil {
+			return nil, err
+		}
+		r.body = bytes.NewReader(b) // request could be built again
+		body = bytes.NewReader(b)
+	}
+	req := httptest.NewRequest(r.method, r.path, body)
+	if len(r.query) > 0 {
+		q := req.URL.Query()
+		for k, v := range r.query {
+			q[k] = append(q[k], v...)
+		}
+		req.URL.RawQuery = q.Encode()
+		req.RequestURI = req.URL.RequestURI()
+	}
+	for k, v := range r.client.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	for k, v := range r.header {
+		req.Header[k] = append(req.Header[k], v...)
+	}
+	return req, nil
+}
+
+// Do sends the request through Echo instance and returns recorded response. Exchange is recorded and can be
+// retrieved with `Client.Exchanges()`.
+func (r *Request) Do() (*httptest.ResponseRecorder, error) {
+	req, err := r.HTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+	var body []byte
+	if req.Body != nil {
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	rec := httptest.NewRecorder()
+	r.client.echo.ServeHTTP(rec, req)
+
+	r.client.mutex.Lock()
+	r.client.exchanges = append(r.client.exchanges, &Exchange{Request: req, RequestBody: body, Response: rec})
+	r.client.mutex.Unlock()
+	return rec, nil
+}
+
+// Exchanges returns all exchanges made by the client so far.
+func (c *Client) Exchanges() []*Exchange {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]*Exchange(nil), c.exchanges...)
+}
+
+// Expect sends the request and returns response for making assertions with t.
+func (r *Request) Expect(t TestingT) *Response {
+	t.Helper()
+	rec, err := r.Do()
+	if err != nil {
+		t.Errorf("echotest: failed to build request: %v", err)
+		rec = httptest.NewRecorder()
+	}
+	return &Response{t: t, Recorder: rec}
+}
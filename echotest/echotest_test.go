@@ -0,0 +1,136 @@
+package echotest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func newTestEcho() *echo.Echo {
+	e := echo.New()
+	api := e.Group("/api", middleware.JWT([]byte("secret")))
+	api.GET("/users/:id", func(c echo.Context) error {
+		claims := c.Get("user").(*jwt.Token).Claims.(jwt.MapClaims)
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"id":    c.Param("id"),
+			"name":  claims["name"],
+			"lang":  c.QueryParam("lang"),
+			"roles": []string{"admin", "user"},
+		})
+	})
+	e.POST("/users", func(c echo.Context) error {
+		u := struct {
+			Name string `json:"name" form:"name"`
+		}{}
+		if err := c.Bind(&u); err != nil {
+			return err
+		}
+		c.Response().Header().Set("X-Agent", c.Request().Header.Get("X-Agent"))
+		return c.String(http.StatusCreated, "created "+u.Name)
+	})
+	return e
+}
+
+func TestClient_GET(t *testing.T) {
+	client := New(newTestEcho())
+	client.JWTSigningKey = []byte("secret")
+
+	client.GET("/api/users/1").
+		WithJWT(jwt.MapClaims{"name": "John"}).
+		WithQuery("lang", "en").
+		Expect(t).
+		Status(http.StatusOK).
+		Header(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8).
+		JSONPath("$.name", "John").
+		JSONPath("$.id", "1").
+		JSONPath("$.lang", "en").
+		JSONPath("$.roles[1]", "user").
+		JSONPath("$['roles']", []string{"admin", "user"})
+
+	client.GET("/api/users/1").Expect(t).Status(http.StatusBadRequest)
+}
+
+func TestClient_POST(t *testing.T) {
+	client := New(newTestEcho())
+	client.Header.Set("X-Agent", "echotest")
+
+	client.POST("/users").
+		WithJSON(map[string]string{"name": "John"}).
+		Expect(t).
+		Status(http.StatusCreated).
+		Header("X-Agent", "echotest").
+		Body("created John")
+
+	client.POST("/users").
+		WithForm(url.Values{"name": {"Jane"}}).
+		Expect(t).
+		Status(http.StatusCreated).
+		BodyContains("Jane")
+}
+
+func TestResponse_failedAssertions(t *testing.T) {
+	client := New(newTestEcho())
+	client.JWTSigningKey = []byte("secret")
+	rt := &recordingT{}
+
+	client.GET("/api/users/1").
+		WithJWT(jwt.MapClaims{"name": "John"}).
+		Expect(rt).
+		Status(http.StatusTeapot).
+		Header("X-Missing", "value").
+		BodyContains("Jane").
+		JSONPath("$.name", "Jane").
+		JSONPath("$.roles[5]", "user").
+		JSON(map[string]string{})
+
+	assert.Equal(t, []string{
+		"echotest: expected status 418, got 200",
+		`echotest: expected header X-Missing to be "value", got ""`,
+		`echotest: expected body to contain "Jane", got "{\"id\":\"1\",\"lang\":\"\",\"name\":\"John\",\"roles\":[\"admin\",\"user\"]}\n"`,
+		`echotest: path $.name: expected "Jane", got "John"`,
+		"echotest: json path $.roles[5]: index 5 not found",
+		`echotest: expected {}, got {"id":"1","lang":"","name":"John","roles":["admin","user"]}`,
+	}, rt.errors)
+}
+
+func TestRequest_WithJWTWithoutKey(t *testing.T) {
+	rt := &recordingT{}
+	New(newTestEcho()).GET("/api/users/1").WithJWT(jwt.MapClaims{}).Expect(rt)
+	assert.Equal(t, []string{"echotest: failed to build request: key is of invalid type"}, rt.errors)
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": []interface{}{map[string]interface{}{"b.c": 1.0}},
+	}
+	v, err := LookupJSONPath(doc, `$.a[0]["b.c"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, v)
+
+	v, err = LookupJSONPath(doc, "$")
+	assert.NoError(t, err)
+	assert.Equal(t, doc, v)
+
+	_, err = LookupJSONPath(doc, "a")
+	assert.EqualError(t, err, "json path must start with $: a")
+	_, err = LookupJSONPath(doc, "$.a[x]")
+	assert.EqualError(t, err, `invalid json path index "x": $.a[x]`)
+	_, err = LookupJSONPath(doc, "$.x")
+	assert.EqualError(t, err, `json path $.x: key "x" not found`)
+}
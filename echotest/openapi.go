@@ -0,0 +1,466 @@
+package echotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// OpenAPI is an OpenAPI 3 document exchanges recorded by the test client are validated against. Only subset of
+	// the specification needed for contract assertions is supported: paths, operations, parameters, request bodies,
+	// responses and JSON schemas (with local `$ref` references).
+	OpenAPI struct {
+		basePath string
+		paths    []*openAPIPath
+		schemas  map[string]*openAPISchema
+	}
+
+	openAPIDocument struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]*openAPISchema `json:"schemas"`
+		} `json:"components"`
+	}
+
+	openAPIPath struct {
+		template   string
+		segments   []string
+		operations map[string]*openAPIOperation
+	}
+
+	openAPIOperation struct {
+		Parameters  []openAPIParameter `json:"parameters"`
+		RequestBody *struct {
+			Required bool                        `json:"required"`
+			Content  map[string]openAPIMediaType `json:"content"`
+		} `json:"requestBody"`
+		Responses map[string]struct {
+			Content map[string]openAPIMediaType `json:"content"`
+		} `json:"responses"`
+	}
+
+	openAPIParameter struct {
+		Name     string         `json:"name"`
+		In       string         `json:"in"`
+		Required bool           `json:"required"`
+		Schema   *openAPISchema `json:"schema"`
+	}
+
+	openAPIMediaType struct {
+		Schema *openAPISchema `json:"schema"`
+	}
+
+	openAPISchema struct {
+		Ref                  string                    `json:"$ref"`
+		Type                 string                    `json:"type"`
+		Nullable             bool                      `json:"nullable"`
+		Properties           map[string]*openAPISchema `json:"properties"`
+		Required             []string                  `json:"required"`
+		AdditionalProperties json.RawMessage           `json:"additionalProperties"`
+		Items                *openAPISchema            `json:"items"`
+		Enum                 []interface{}             `json:"enum"`
+		AllOf                []*openAPISchema          `json:"allOf"`
+		AnyOf                []*openAPISchema          `json:"anyOf"`
+		OneOf                []*openAPISchema          `json:"oneOf"`
+		Minimum              *float64                  `json:"minimum"`
+		Maximum              *float64                  `json:"maximum"`
+		MinLength            *int                      `json:"minLength"`
+		MaxLength            *int                      `json:"maxLength"`
+		MinItems             *int                      `json:"minItems"`
+		MaxItems             *int                      `json:"maxItems"`
+		Pattern              string                    `json:"pattern"`
+	}
+)
+
+var openAPIMethods = map[string]string{
+	"get":     http.MethodGet,
+	"put":     http.MethodPut,
+	"post":    http.MethodPost,
+	"delete":  http.MethodDelete,
+	"options": http.MethodOptions,
+	"head":    http.MethodHead,
+	"patch":   http.MethodPatch,
+	"trace":   http.MethodTrace,
+}
+
+// ParseOpenAPI parses OpenAPI 3 document in JSON format.
+func ParseOpenAPI(data []byte) (*OpenAPI, error) {
+	raw := openAPIDocument{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("echotest: invalid OpenAPI document: %v", err)
+	}
+	doc := &OpenAPI{schemas: raw.Components.Schemas}
+	if len(raw.Servers) > 0 {
+		if u, err := url.Parse(raw.Servers[0].URL); err == nil {
+			doc.basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+	for template, item := range raw.Paths {
+		p := &openAPIPath{
+			template:   template,
+			segments:   strings.Split(strings.Trim(template, "/"), "/"),
+			operations: map[string]*openAPIOperation{},
+		}
+		var common []openAPIParameter
+		if params, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(params, &common); err != nil {
+				return nil, fmt.Errorf("echotest: invalid parameters of path %s: %v", template, err)
+			}
+		}
+		for name, operation := range item {
+			method, ok := openAPIMethods[name]
+			if !ok {
+				continue
+			}
+			op := new(openAPIOperation)
+			if err := json.Unmarshal(operation, op); err != nil {
+				return nil, fmt.Errorf("echotest: invalid operation %s %s: %v", method, template, err)
+			}
+			op.Parameters = append(append([]openAPIParameter(nil), common...), op.Parameters...)
+			p.operations[method] = op
+		}
+		doc.paths = append(doc.paths, p)
+	}
+	// static paths take precedence over templated ones, i.e. `/users/me` over `/users/{id}`
+	sort.Slice(doc.paths, func(i, j int) bool {
+		ci, cj := strings.Count(doc.paths[i].template, "{"), strings.Count(doc.paths[j].template, "{")
+		if ci != cj {
+			return ci < cj
+		}
+		return doc.paths[i].template < doc.paths[j].template
+	})
+	return doc, nil
+}
+
+// Validate checks that exchange conforms to the document and returns list of found violations.
+func (o *OpenAPI) Validate(x *Exchange) []string {
+	req := x.Request
+	reqPath := strings.TrimPrefix(req.URL.Path, o.basePath)
+	path, pathParams := o.findPath(reqPath)
+	if path == nil {
+		return []string{fmt.Sprintf("%s %s: path is not documented", req.Method, req.URL.Path)}
+	}
+	prefix := fmt.Sprintf("%s %s", req.Method, path.template)
+	op, ok := path.operations[req.Method]
+	if !ok {
+		return []string{prefix + ": operation is not documented"}
+	}
+
+	var errs []string
+	for _, p := range op.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathParams[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = req.URL.Query()[p.Name]
+		case "header":
+			values = req.Header.Values(p.Name)
+		case "cookie":
+			if c, err := req.Cookie(p.Name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+		if len(values) == 0 {
+			if p.Required {
+				errs = append(errs, fmt.Sprintf("%s: missing required %s parameter %s", prefix, p.In, p.Name))
+			}
+			continue
+		}
+		if p.Schema != nil {
+			for _, err := range o.validateSchema(p.Schema, parameterValue(p.Schema, values), p.Name) {
+				errs = append(errs, fmt.Sprintf("%s: %s parameter %s", prefix, p.In, err))
+			}
+		}
+	}
+
+	if op.RequestBody != nil {
+		switch {
+		case len(x.RequestBody) == 0:
+			if op.RequestBody.Required {
+				errs = append(errs, prefix+": missing required request body")
+			}
+		default:
+			errs = append(errs, o.validateContent(prefix+": request body", op.RequestBody.Content, req.Header.Get("Content-Type"), x.RequestBody)...)
+		}
+	}
+
+	code := x.Response.Code
+	res, ok := op.Responses[strconv.Itoa(code)]
+	if !ok {
+		res, ok = op.Responses[strconv.Itoa(code/100)+"XX"]
+	}
+	if !ok {
+		res, ok = op.Responses["default"]
+	}
+	if !ok {
+		return append(errs, fmt.Sprintf("%s: response status %d is not documented", prefix, code))
+	}
+	if len(res.Content) > 0 && req.Method != http.MethodHead && code != http.StatusNoContent && code != http.StatusNotModified {
+		errs = append(errs, o.validateContent(fmt.Sprintf("%s: response %d body", prefix, code), res.Content,
+			x.Response.Header().Get("Content-Type"), x.Response.Body.Bytes())...)
+	}
+	return errs
+}
+
+func (o *OpenAPI) findPath(p string) (*openAPIPath, map[string]string) {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	for _, path := range o.paths {
+		if len(path.segments) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		matched := true
+		for i, s := range path.segments {
+			if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+				v, _ := url.PathUnescape(segments[i])
+				params[s[1:len(s)-1]] = v
+				continue
+			}
+			if s != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return path, params
+		}
+	}
+	return nil, nil
+}
+
+func (o *OpenAPI) validateContent(prefix string, content map[string]openAPIMediaType, contentType string, body []byte) []string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := content[mediaType]
+	if !ok {
+		if media, ok = content["*/*"]; !ok {
+			return []string{fmt.Sprintf("%s: content type %q is not documented", prefix, contentType)}
+		}
+	}
+	if media.Schema == nil || !(mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []string{fmt.Sprintf("%s: invalid JSON: %v", prefix, err)}
+	}
+	var errs []string
+	for _, err := range o.validateSchema(media.Schema, v, "$") {
+		errs = append(errs, prefix+": "+err)
+	}
+	return errs
+}
+
+// parameterValue converts string parameter values to JSON value described by the schema so they could be validated.
+func parameterValue(s *openAPISchema, values []string) interface{} {
+	switch s.Type {
+	case "array":
+		items := make([]interface{}, len(values))
+		for i, v := range values {
+			items[i] = v
+			if s.Items != nil {
+				items[i] = parameterValue(s.Items, []string{v})
+			}
+		}
+		return items
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(values[0], 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(values[0]); err == nil {
+			return b
+		}
+	}
+	return values[0]
+}
+
+func (o *OpenAPI) resolve(s *openAPISchema) (*openAPISchema, error) {
+	for i := 0; s.Ref != ""; i++ {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		ref, ok := o.schemas[name]
+		if !ok || name == s.Ref || i > 32 {
+			return nil, fmt.Errorf("unresolvable schema reference %s", s.Ref)
+		}
+		s = ref
+	}
+	return s, nil
+}
+
+func (o *OpenAPI) validateSchema(s *openAPISchema, v interface{}, at string) []string {
+	s, err := o.resolve(s)
+	if err != nil {
+		return []string{at + ": " + err.Error()}
+	}
+	if v == nil {
+		if s.Nullable || s.Type == "" || s.Type == "null" {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: expected %s, got null", at, s.Type)}
+	}
+
+	var errs []string
+	for _, sub := range s.AllOf {
+		errs = append(errs, o.validateSchema(sub, v, at)...)
+	}
+	if len(s.AnyOf) > 0 && o.countMatching(s.AnyOf, v, at) == 0 {
+		errs = append(errs, at+": value does not match any of anyOf schemas")
+	}
+	if len(s.OneOf) > 0 && o.countMatching(s.OneOf, v, at) != 1 {
+		errs = append(errs, at+": value does not match exactly one of oneOf schemas")
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: value %v is not one of enum values", at, v))
+		}
+	}
+
+	switch s.Type {
+	case "":
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: expected object, got %s", at, jsonType(v)))
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing required property %s", at, name))
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if ps, ok := s.Properties[name]; ok {
+				errs = append(errs, o.validateSchema(ps, obj[name], at+"."+name)...)
+				continue
+			}
+			switch ap := strings.TrimSpace(string(s.AdditionalProperties)); {
+			case ap == "false":
+				errs = append(errs, fmt.Sprintf("%s: additional property %s is not allowed", at, name))
+			case strings.HasPrefix(ap, "{"):
+				aps := new(openAPISchema)
+				if err := json.Unmarshal(s.AdditionalProperties, aps); err == nil {
+					errs = append(errs, o.validateSchema(aps, obj[name], at+"."+name)...)
+				}
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: expected array, got %s", at, jsonType(v)))
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			errs = append(errs, fmt.Sprintf("%s: expected at least %d items, got %d", at, *s.MinItems, len(arr)))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			errs = append(errs, fmt.Sprintf("%s: expected at most %d items, got %d", at, *s.MaxItems, len(arr)))
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				errs = append(errs, o.validateSchema(s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: expected string, got %s", at, jsonType(v)))
+		}
+		length := len([]rune(str))
+		if s.MinLength != nil && length < *s.MinLength {
+			errs = append(errs, fmt.Sprintf("%s: expected at least %d characters, got %d", at, *s.MinLength, length))
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			errs = append(errs, fmt.Sprintf("%s: expected at most %d characters, got %d", at, *s.MaxLength, length))
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: invalid pattern %q: %v", at, s.Pattern, err))
+			} else if !re.MatchString(str) {
+				errs = append(errs, fmt.Sprintf("%s: value %q does not match pattern %q", at, str, s.Pattern))
+			}
+		}
+	case "integer", "number":
+		f, ok := v.(float64)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s: expected %s, got %s", at, s.Type, jsonType(v)))
+		}
+		if s.Type == "integer" && f != math.Trunc(f) {
+			errs = append(errs, fmt.Sprintf("%s: expected integer, got %v", at, f))
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			errs = append(errs, fmt.Sprintf("%s: value %v is less than minimum %v", at, f, *s.Minimum))
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			errs = append(errs, fmt.Sprintf("%s: value %v is greater than maximum %v", at, f, *s.Maximum))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			errs = append(errs, fmt.Sprintf("%s: expected boolean, got %s", at, jsonType(v)))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("%s: unsupported schema type %s", at, s.Type))
+	}
+	return errs
+}
+
+func (o *OpenAPI) countMatching(schemas []*openAPISchema, v interface{}, at string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(o.validateSchema(sub, v, at)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// AssertContract asserts that all exchanges recorded by the client conform to the OpenAPI document.
+func AssertContract(t TestingT, doc *OpenAPI, client *Client) bool {
+	t.Helper()
+	ok := true
+	for _, x := range client.Exchanges() {
+		for _, err := range doc.Validate(x) {
+			t.Errorf("echotest: contract violation: %s", err)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -0,0 +1,123 @@
+package echotest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOpenAPI = `{
+  "openapi": "3.0.0",
+  "servers": [{"url": "https://api.example.com/v1"}],
+  "paths": {
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+      "get": {
+        "parameters": [{"name": "fields", "in": "query", "schema": {"type": "string", "enum": ["all", "name"]}}],
+        "responses": {
+          "200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+          "4XX": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/users": {
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/NewUser"}}}
+        },
+        "responses": {"201": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "NewUser": {
+        "type": "object",
+        "required": ["name"],
+        "additionalProperties": false,
+        "properties": {"name": {"type": "string", "minLength": 1}}
+      },
+      "User": {
+        "type": "object",
+        "required": ["id", "name"],
+        "additionalProperties": false,
+        "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}
+      },
+      "Error": {
+        "allOf": [
+          {"type": "object", "required": ["message"]},
+          {"properties": {"message": {"type": "string"}}}
+        ]
+      }
+    }
+  }
+}`
+
+func newContractEcho() *echo.Echo {
+	e := echo.New()
+	v1 := e.Group("/v1")
+	v1.GET("/users/:id", func(c echo.Context) error {
+		switch c.Param("id") {
+		case "1":
+			return c.JSON(http.StatusOK, map[string]interface{}{"id": 1, "name": "John"})
+		case "2":
+			return c.JSON(http.StatusOK, map[string]interface{}{"id": "2", "age": 30})
+		case "3":
+			return c.String(http.StatusInternalServerError, "oops")
+		}
+		return echo.ErrNotFound
+	})
+	v1.POST("/users", func(c echo.Context) error {
+		return c.JSON(http.StatusCreated, map[string]interface{}{"id": 3, "name": "Jane"})
+	})
+	v1.GET("/health", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	return e
+}
+
+func TestAssertContract(t *testing.T) {
+	doc, err := ParseOpenAPI([]byte(testOpenAPI))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	client := New(newContractEcho())
+	client.GET("/v1/users/1").WithQuery("fields", "all").Expect(t).Status(http.StatusOK)
+	client.GET("/v1/users/404").Expect(t).Status(http.StatusNotFound)
+	client.POST("/v1/users").WithJSON(map[string]string{"name": "Jane"}).Expect(t).Status(http.StatusCreated)
+	assert.True(t, AssertContract(t, doc, client))
+
+	client = New(newContractEcho())
+	client.GET("/v1/users/2").WithQuery("fields", "none").Do()
+	client.GET("/v1/users/x").Do()
+	client.GET("/v1/users/3").Do()
+	client.POST("/v1/users").WithJSON(map[string]interface{}{"name": "", "admin": true}).Do()
+	client.POST("/v1/users").Do()
+	client.GET("/v1/health").Do()
+	client.DELETE("/v1/users").Do()
+
+	rt := &recordingT{}
+	assert.False(t, AssertContract(rt, doc, client))
+	assert.Equal(t, []string{
+		"echotest: contract violation: GET /users/{id}: query parameter fields: value none is not one of enum values",
+		"echotest: contract violation: GET /users/{id}: response 200 body: $: missing required property name",
+		"echotest: contract violation: GET /users/{id}: response 200 body: $: additional property age is not allowed",
+		"echotest: contract violation: GET /users/{id}: response 200 body: $.id: expected integer, got string",
+		"echotest: contract violation: GET /users/{id}: path parameter id: expected integer, got string",
+		"echotest: contract violation: GET /users/{id}: response status 500 is not documented",
+		"echotest: contract violation: POST /users: request body: $: additional property admin is not allowed",
+		"echotest: contract violation: POST /users: request body: $.name: expected at least 1 characters, got 0",
+		"echotest: contract violation: POST /users: missing required request body",
+		"echotest: contract violation: GET /v1/health: path is not documented",
+		"echotest: contract violation: DELETE /users: operation is not documented",
+	}, rt.errors)
+}
+
+func TestParseOpenAPI_invalid(t *testing.T) {
+	_, err := ParseOpenAPI([]byte(`{"paths": []}`))
+	assert.Error(t, err)
+}
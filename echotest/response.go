@@ -0,0 +1,201 @@
+package echotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Response is a recorded response with assertion methods. Failed assertions are reported to TestingT and do not
+// stop the test so assertions could be chained.
+type Response struct {
+	t TestingT
+
+	// Recorder holds the recorded response.
+	Recorder *httptest.ResponseRecorder
+}
+
+// Status asserts response status code.
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Recorder.Code != code {
+		r.t.Errorf("echotest: expected status %d, got %d", code, r.Recorder.Code)
+	}
+	return r
+}
+
+// Header asserts value of response header.
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+	if v := r.Recorder.Header().Get(key); v != value {
+		r.t.Errorf("echotest: expected header %s to be %q, got %q", key, value, v)
+	}
+	return r
+}
+
+// Body asserts response body.
+func (r *Response) Body(body string) *Response {
+	r.t.Helper()
+	if b := r.Recorder.Body.String(); b != body {
+		r.t.Errorf("echotest: expected body %q, got %q", body, b)
+	}
+	return r
+}
+
+// BodyContains asserts that response body contains s.
+func (r *Response) BodyContains(s string) *Response {
+	r.t.Helper()
+	if b := r.Recorder.Body.String(); !strings.Contains(b, s) {
+		r.t.Errorf("echotest: expected body to contain %q, got %q", s, b)
+	}
+	return r
+}
+
+// JSON asserts that response body is JSON equal to JSON encoding of expected.
+func (r *Response) JSON(expected interface{}) *Response {
+	r.t.Helper()
+	actual, err := r.decodeJSON()
+	if err != nil {
+		r.t.Errorf("echotest: %v", err)
+		return r
+	}
+	if err := assertJSONEqual(expected, actual); err != nil {
+		r.t.Errorf("echotest: %v", err)
+	}
+	return r
+}
+
+// JSONPath asserts that value found at path in the JSON response body is equal to expected. Path supports a subset
+// of JSONPath syntax: root `$`, child `.name` or `['name']` and array index `[0]`, i.e. `$.users[0].name`.
+func (r *Response) JSONPath(path string, expected interface{}) *Response {
+	r.t.Helper()
+	doc, err := r.decodeJSON()
+	if err != nil {
+		r.t.Errorf("echotest: %v", err)
+		return r
+	}
+	actual, err := LookupJSONPath(doc, path)
+	if err != nil {
+		r.t.Errorf("echotest: %v", err)
+		return r
+	}
+	if err := assertJSONEqual(expected, actual); err != nil {
+		r.t.Errorf("echotest: path %s: %v", path, err)
+	}
+	return r
+}
+
+// DecodeJSON decodes JSON response body into v.
+func (r *Response) DecodeJSON(v interface{}) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), v); err != nil {
+		r.t.Errorf("echotest: invalid JSON response body: %v", err)
+	}
+	return r
+}
+
+func (r *Response) decodeJSON() (interface{}, error) {
+	var doc interface{}
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON response body: %v", err)
+	}
+	return doc, nil
+}
+
+func assertJSONEqual(expected, actual interface{}) error {
+	// expected value is normalized through JSON encoding so i.e. ints compare equal to decoded float64 values
+	b, err := json.Marshal(expected)
+	if err != nil {
+		return err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(normalized, actual) {
+		a, _ := json.Marshal(actual)
+		return fmt.Errorf("expected %s, got %s", b, a)
+	}
+	return nil
+}
+
+type jsonPathSegment struct {
+	key   string
+	index int // -1 for object key, jsonPathWildcard for all array elements
+}
+
+const jsonPathWildcard = -2
+
+// LookupJSONPath returns value at path in decoded JSON document. See `Response.JSONPath` for supported syntax.
+func LookupJSONPath(doc interface{}, path string) (interface{}, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, s := range segments {
+		switch {
+		case s.index == jsonPathWildcard:
+			return nil, fmt.Errorf("json path %s: wildcard is not supported for lookup", path)
+		case s.index >= 0:
+			arr, ok := current.([]interface{})
+			if !ok || s.index >= len(arr) {
+				return nil, fmt.Errorf("json path %s: index %d not found", path, s.index)
+			}
+			current = arr[s.index]
+		default:
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("json path %s: key %q not found", path, s.key)
+			}
+			if current, ok = obj[s.key]; !ok {
+				return nil, fmt.Errorf("json path %s: key %q not found", path, s.key)
+			}
+		}
+	}
+	return current, nil
+}
+
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path must start with $: %s", path)
+	}
+	var segments []jsonPathSegment
+	rest := path[1:]
+	for rest != "" {
+		s := jsonPathSegment{index: -1}
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			s.key, rest = rest[1:end+1], rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid json path: %s", path)
+			}
+			segment := rest[1:end]
+			rest = rest[end+1:]
+			if len(segment) >= 2 && (segment[0] == '\'' || segment[0] == '"') && segment[len(segment)-1] == segment[0] {
+				s.key = segment[1 : len(segment)-1]
+			} else if segment == "*" {
+				s.index = jsonPathWildcard
+			} else {
+				i, err := strconv.Atoi(segment)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid json path index %q: %s", segment, path)
+				}
+				s.index = i
+			}
+		default:
+			return nil, fmt.Errorf("invalid json path: %s", path)
+		}
+		segments = append(segments, s)
+	}
+	return segments, nil
+}
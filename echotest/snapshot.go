@@ -0,0 +1,220 @@
+package echotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// SnapshotConfig defines the config for snapshot assertions.
+	SnapshotConfig struct {
+		// Dir is the directory golden files are stored in.
+		// Optional. Default value "testdata/snapshots".
+		Dir string
+
+		// Name is appended to the test name to form golden file name. Needed when
+		// test takes more than one snapshot.
+		// Optional. Default value "".
+		Name string
+
+		// Headers are response headers included in the snapshot.
+		// Optional. Default value []string{"Content-Type"}.
+		Headers []string
+
+		// Redactions are applied to header values and body before comparing, so values
+		// changing between runs (ids, timestamps) do not break snapshots.
+		Redactions []Redaction
+
+		// Update writes snapshots instead of comparing them. Snapshots are updated also
+		// when environment variable ECHOTEST_UPDATE_SNAPSHOTS is set to "1" or "true".
+		// Optional. Default value false.
+		Update bool
+	}
+
+	// Redaction replaces matching parts of a response. Either JSONPath or Pattern
+	// must be set.
+	Redaction struct {
+		// JSONPath of value in JSON body, i.e. `$.createdAt` or `$.items[*].id`.
+		JSONPath string
+
+		// Pattern is matched against header values and body.
+		Pattern *regexp.Regexp
+
+		// Replacement is the value redacted parts are replaced with. With Pattern it may
+		// reference submatches, i.e. `${1}`.
+		// Optional. Default value "[REDACTED]".
+		Replacement string
+	}
+
+	// SnapshotT is the interface of `*testing.T` used by snapshot assertions.
+	SnapshotT interface {
+		TestingT
+		Name() string
+	}
+)
+
+const (
+	defaultRedaction   = "[REDACTED]"
+	updateSnapshotsEnv = "ECHOTEST_UPDATE_SNAPSHOTS"
+)
+
+var (
+	// DefaultSnapshotConfig is the default snapshot config.
+	DefaultSnapshotConfig = SnapshotConfig{
+		Dir:     filepath.Join("testdata", "snapshots"),
+		Headers: []string{echo.HeaderContentType},
+	}
+
+	snapshotNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_\-.]+`)
+)
+
+// Snapshot asserts that serialized status, headers and body of the response match golden file stored for the test.
+func Snapshot(t SnapshotT, rec *httptest.ResponseRecorder) bool {
+	t.Helper()
+	return SnapshotWithConfig(t, rec, DefaultSnapshotConfig)
+}
+
+// SnapshotWithConfig asserts response snapshot with config.
+// See `Snapshot()`.
+func SnapshotWithConfig(t SnapshotT, rec *httptest.ResponseRecorder, config SnapshotConfig) bool {
+	t.Helper()
+	if config.Dir == "" {
+		config.Dir = DefaultSnapshotConfig.Dir
+	}
+	if config.Headers == nil {
+		config.Headers = DefaultSnapshotConfig.Headers
+	}
+	if env := os.Getenv(updateSnapshotsEnv); env == "1" || env == "true" {
+		config.Update = true
+	}
+
+	actual, err := serializeSnapshot(rec, config)
+	if err != nil {
+		t.Errorf("echotest: failed to serialize snapshot: %v", err)
+		return false
+	}
+
+	name := t.Name()
+	if config.Name != "" {
+		name += "_" + config.Name
+	}
+	file := filepath.Join(config.Dir, snapshotNameReplacer.ReplaceAllString(name, "_")+".snap")
+
+	if config.Update {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Errorf("echotest: failed to create snapshot directory: %v", err)
+			return false
+		}
+		if err := ioutil.WriteFile(file, actual, 0644); err != nil {
+			t.Errorf("echotest: failed to write snapshot: %v", err)
+			return false
+		}
+		return true
+	}
+
+	expected, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		t.Errorf("echotest: snapshot %s does not exist, run tests with %s=1 to create it", file, updateSnapshotsEnv)
+		return false
+	} else if err != nil {
+		t.Errorf("echotest: failed to read snapshot: %v", err)
+		return false
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("echotest: response does not match snapshot %s\n--- expected\n%s\n--- actual\n%s", file, expected, actual)
+		return false
+	}
+	return true
+}
+
+func serializeSnapshot(rec *httptest.ResponseRecorder, config SnapshotConfig) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "HTTP %d %s\n", rec.Code, http.StatusText(rec.Code))
+	for _, h := range config.Headers {
+		for _, v := range rec.Header().Values(h) {
+			fmt.Fprintf(buf, "%s: %s\n", http.CanonicalHeaderKey(h), redactText(v, config.Redactions))
+		}
+	}
+	buf.WriteString("\n")
+
+	body := rec.Body.Bytes()
+	mediaType, _, _ := mime.ParseMediaType(rec.Header().Get(echo.HeaderContentType))
+	if len(body) > 0 && (mediaType == echo.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json")) {
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, err
+		}
+		for _, r := range config.Redactions {
+			if r.JSONPath == "" {
+				continue
+			}
+			segments, err := parseJSONPath(r.JSONPath)
+			if err != nil {
+				return nil, err
+			}
+			doc = redactJSON(doc, segments, replacement(r))
+		}
+		// pretty printed JSON makes snapshot diffs readable
+		pretty := new(bytes.Buffer)
+		enc := json.NewEncoder(pretty)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		body = pretty.Bytes()
+	}
+	buf.WriteString(redactText(string(body), config.Redactions))
+	return buf.Bytes(), nil
+}
+
+func redactText(s string, redactions []Redaction) string {
+	for _, r := range redactions {
+		if r.Pattern != nil {
+			s = r.Pattern.ReplaceAllString(s, replacement(r))
+		}
+	}
+	return s
+}
+
+func redactJSON(v interface{}, segments []jsonPathSegment, replacement string) interface{} {
+	if len(segments) == 0 {
+		return replacement
+	}
+	s := segments[0]
+	switch {
+	case s.index == -1:
+		if obj, ok := v.(map[string]interface{}); ok {
+			if child, ok := obj[s.key]; ok {
+				obj[s.key] = redactJSON(child, segments[1:], replacement)
+			}
+		}
+	default:
+		if arr, ok := v.([]interface{}); ok {
+			for i := range arr {
+				if s.index == jsonPathWildcard || s.index == i {
+					arr[i] = redactJSON(arr[i], segments[1:], replacement)
+				}
+			}
+		}
+	}
+	return v
+}
+
+func replacement(r Redaction) string {
+	if r.Replacement == "" {
+		return defaultRedaction
+	}
+	return r.Replacement
+}
@@ -0,0 +1,106 @@
+package echotest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSnapshotT struct {
+	recordingT
+	name string
+}
+
+func (t *recordingSnapshotT) Name() string {
+	return t.name
+}
+
+func newSnapshotEcho(id string) *echo.Echo {
+	e := echo.New()
+	e.GET("/orders", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderXRequestID, "req-"+id)
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"id": id + "1", "name": "<book>"},
+				{"id": id + "2", "name": "pen"},
+			},
+			"createdAt": "2021-10-0" + id + "T10:00:00Z",
+		})
+	})
+	e.GET("/page", func(c echo.Context) error {
+		return c.HTML(http.StatusOK, "<p>token="+id+"</p>")
+	})
+	return e
+}
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "echotest")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	config := SnapshotConfig{
+		Dir:     dir,
+		Headers: []string{echo.HeaderContentType, echo.HeaderXRequestID},
+		Redactions: []Redaction{
+			{JSONPath: "$.items[*].id"},
+			{JSONPath: "$.createdAt", Replacement: "<time>"},
+			{Pattern: regexp.MustCompile(`(req-|token=)\d+`), Replacement: "${1}X"},
+		},
+	}
+
+	rt := &recordingSnapshotT{name: "TestOrders/list"}
+	rec, _ := New(newSnapshotEcho("1")).GET("/orders").Do()
+	assert.False(t, SnapshotWithConfig(rt, rec, config))
+	assert.Len(t, rt.errors, 1)
+	assert.Contains(t, rt.errors[0], "does not exist, run tests with ECHOTEST_UPDATE_SNAPSHOTS=1 to create it")
+
+	update := config
+	update.Update = true
+	assert.True(t, SnapshotWithConfig(rt, rec, update))
+	content, err := ioutil.ReadFile(filepath.Join(dir, "TestOrders_list.snap"))
+	assert.NoError(t, err)
+	assert.Equal(t, `HTTP 200 OK
+Content-Type: application/json; charset=UTF-8
+X-Request-Id: req-X
+
+{
+  "createdAt": "<time>",
+  "items": [
+    {
+      "id": "[REDACTED]",
+      "name": "<book>"
+    },
+    {
+      "id": "[REDACTED]",
+      "name": "pen"
+    }
+  ]
+}
+`, string(content))
+
+	// redacted values differ between runs
+	rt = &recordingSnapshotT{name: "TestOrders/list"}
+	rec, _ = New(newSnapshotEcho("2")).GET("/orders").Do()
+	assert.True(t, SnapshotWithConfig(rt, rec, config))
+	assert.Empty(t, rt.errors)
+
+	config.Name = "page"
+	rec, _ = New(newSnapshotEcho("1")).GET("/page").Do()
+	update.Name = "page"
+	assert.True(t, SnapshotWithConfig(rt, rec, update))
+	rec, _ = New(newSnapshotEcho("2")).GET("/page").Do()
+	assert.True(t, SnapshotWithConfig(rt, rec, config))
+
+	rec.Code = http.StatusTeapot
+	assert.False(t, SnapshotWithConfig(rt, rec, config))
+	assert.Len(t, rt.errors, 1)
+	assert.Contains(t, rt.errors[0], "response does not match snapshot")
+}
@@ -0,0 +1,60 @@
+package echo
+
+import (
+	stdContext "context"
+	"net"
+	"net/http"
+
+	"github.com/labstack/gommon/log"
+)
+
+// ServerEngine is transport serving requests to Echo instead of `net/http` server, i.e. FastCGI engine used by
+// `Echo#ServeFCGI()`. Engine converts requests of its transport to `*http.Request` and `http.ResponseWriter` so
+// Context, middleware and handlers work unchanged.
+type ServerEngine interface {
+	// Serve accepts connections on the listener and serves requests with the handler. It blocks until engine is
+	// shut down or closed and then returns `http.ErrServerClosed`.
+	Serve(l net.Listener, h http.Handler) error
+
+	// Shutdown stops accepting connections and waits for active requests to finish or for context to be done.
+	Shutdown(ctx stdContext.Context) error
+
+	// Close immediately stops the engine.
+	Close() error
+}
+
+// StartEngine starts the server engine on the address. Listener is created as for `Echo#Start()` (wrapped
+// with `Echo#ListenerWrapper`). `Echo#Shutdown()` and `Echo#Close()` stop the engine.
+// Connection tracking of the net/http server (`Echo#MaxConnections`, `Echo#ConnStats()`, `Echo#ConnLifecycle`) is
+// not available with engines.
+//
+// Example:
+//
+//	e.StartEngine(":8080", myEngine)
+func (e *Echo) StartEngine(address string, engine ServerEngine) (err error) {
+	e.startupMutex.Lock()
+	e.colorer.SetOutput(e.Logger.Output())
+	if e.Debug {
+		e.Logger.SetLevel(log.DEBUG)
+	}
+	if !e.HideBanner {
+		e.colorer.Printf(banner, e.colorer.Red("v"+Version), e.colorer.Blue(website))
+	}
+	if e.Listener == nil {
+		e.Listener, err = e.listen(address)
+		if err != nil {
+			e.startupMutex.Unlock()
+			return err
+		}
+	}
+	if !e.HidePort {
+		e.colorer.Printf("⇨ http server started on %s\n", e.colorer.Green(e.Listener.Addr()))
+	}
+	e.engine = engine
+	if err := e.runStartHooks(); err != nil {
+		e.startupMutex.Unlock()
+		return err
+	}
+	e.startupMutex.Unlock()
+	return engine.Serve(e.Listener, e)
+}
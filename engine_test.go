@@ -0,0 +1,83 @@
+package echo
+
+import (
+	stdContext "context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEngine struct {
+	server   http.Server
+	shutdown bool
+	closed   bool
+}
+
+func (t *testEngine) Serve(l net.Listener, h http.Handler) error {
+	t.server.Handler = h
+	return t.server.Serve(l)
+}
+
+func (t *testEngine) Shutdown(ctx stdContext.Context) error {
+	t.shutdown = true
+	return t.server.Shutdown(ctx)
+}
+
+func (t *testEngine) Close() error {
+	t.closed = true
+	return t.server.Close()
+}
+
+func startTestEngine(t *testing.T, e *Echo, engine ServerEngine) chan error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.StartEngine("127.0.0.1:0", engine)
+	}()
+	require.NoError(t, waitForServerStart(e, errs, false))
+	return errs
+}
+
+func TestEcho_StartEngine(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+	engine := new(testEngine)
+	errs := startTestEngine(t, e, engine)
+
+	res, err := http.Get("http://" + e.ListenerAddr().String())
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "OK", string(body))
+
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, e.Shutdown(ctx))
+	assert.True(t, engine.shutdown)
+	assert.Equal(t, http.ErrServerClosed, <-errs)
+}
+
+func TestEcho_StartEngineClose(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	engine := new(testEngine)
+	errs := startTestEngine(t, e, engine)
+
+	require.NoError(t, e.Close())
+	assert.True(t, engine.closed)
+	assert.Equal(t, http.ErrServerClosed, <-errs)
+}
+
+func TestEcho_StartEngineInvalidAddress(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	err := e.StartEngine("127.0.0.1:-1", new(testEngine))
+	assert.Error(t, err)
+}
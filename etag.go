@@ -0,0 +1,85 @@
+package echo
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Versioned is resource with version changing on every modification (revision number, update timestamp, hash of the
+// content). Version is sent as `ETag` and compared with `If-Match` of requests modifying the resource so clients
+// can not overwrite changes they have not seen (optimistic locking).
+type Versioned interface {
+	Version() string
+}
+
+// VersionETag returns strong ETag of the version. Version is quoted as is when it consists of characters allowed in
+// ETag, otherwise ETag is SHA-1 hash of the version.
+func VersionETag(version string) string {
+	for i := 0; i < len(version); i++ {
+		if b := version[i]; b == '"' || b < 0x21 || b == 0x7f {
+			sum := sha1.Sum([]byte(version))
+			return `"` + hex.EncodeToString(sum[:]) + `"`
+		}
+	}
+	return `"` + version + `"`
+}
+
+// SetVersion sets `ETag` response header to version of the resource.
+//
+// Example:
+//
+//	func getUser(c echo.Context) error {
+//		user, err := users.Find(c.Param("id"))
+//		if err != nil {
+//			return err
+//		}
+//		echo.SetVersion(c, user)
+//		return c.JSON(http.StatusOK, user)
+//	}
+func SetVersion(c Context, v Versioned) {
+	c.Response().Header().Set(HeaderETag, VersionETag(v.Version()))
+}
+
+// CheckVersion checks `If-Match` request header against version of the resource. Requests modifying resources (PUT,
+// PATCH, DELETE) must send `If-Match`, `ErrPreconditionRequired` (428) is returned when it is missing. When header is
+// sent and does not match the version (weak ETags never match), `ErrPreconditionFailed` (412) is returned. `If-Match:
+// *` matches any version, nil v means the resource does not exist and matches nothing.
+//
+// Example:
+//
+//	func updateUser(c echo.Context) error {
+//		user, err := users.Find(c.Param("id"))
+//		if err != nil {
+//			return err
+//		}
+//		if err := echo.CheckVersion(c, user); err != nil {
+//			return err
+//		}
+//		... // update user, failing when version in database changed meanwhile
+//		echo.SetVersion(c, user)
+//		return c.JSON(http.StatusOK, user)
+//	}
+func CheckVersion(c Context, v Versioned) error {
+	req := c.Request()
+	ifMatch := req.Header.Get(HeaderIfMatch)
+	if ifMatch == "" {
+		switch req.Method {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			return ErrPreconditionRequired
+		}
+		return nil
+	}
+	if v == nil {
+		return ErrPreconditionFailed
+	}
+	etag := VersionETag(v.Version())
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return nil
+		}
+	}
+	return ErrPreconditionFailed
+}
@@ -0,0 +1,90 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testVersioned string
+
+func (v testVersioned) Version() string {
+	return string(v)
+}
+
+func TestVersionETag(t *testing.T) {
+	assert.Equal(t, `"42"`, VersionETag("42"))
+	assert.Equal(t, `"2021-01-01T00:00:00Z"`, VersionETag("2021-01-01T00:00:00Z"))
+	assert.Equal(t, `"454b98bc00a263aafb2942a294a94c93623756be"`, VersionETag(`with "quotes"`))
+}
+
+func TestSetVersion(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	SetVersion(c, testVersioned("7"))
+	assert.Equal(t, `"7"`, rec.Header().Get(HeaderETag))
+}
+
+func TestCheckVersion(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		method      string
+		ifMatch     string
+		resource    Versioned
+		expectError error
+	}{
+		{name: "ok, matching version", method: http.MethodPut, ifMatch: `"7"`, resource: testVersioned("7")},
+		{name: "ok, one of versions", method: http.MethodPatch, ifMatch: `"6", "7"`, resource: testVersioned("7")},
+		{name: "ok, any version", method: http.MethodDelete, ifMatch: "*", resource: testVersioned("7")},
+		{name: "ok, safe method without If-Match", method: http.MethodGet, resource: testVersioned("7")},
+		{name: "ok, POST without If-Match", method: http.MethodPost, resource: testVersioned("7")},
+		{
+			name:        "nok, missing If-Match",
+			method:      http.MethodPut,
+			resource:    testVersioned("7"),
+			expectError: ErrPreconditionRequired,
+		},
+		{
+			name:        "nok, other version",
+			method:      http.MethodPut,
+			ifMatch:     `"6"`,
+			resource:    testVersioned("7"),
+			expectError: ErrPreconditionFailed,
+		},
+		{
+			name:        "nok, weak ETag",
+			method:      http.MethodDelete,
+			ifMatch:     `W/"7"`,
+			resource:    testVersioned("7"),
+			expectError: ErrPreconditionFailed,
+		},
+		{
+			name:        "nok, any version of missing resource",
+			method:      http.MethodPut,
+			ifMatch:     "*",
+			expectError: ErrPreconditionFailed,
+		},
+		{
+			name:        "nok, safe method with other version",
+			method:      http.MethodGet,
+			ifMatch:     `"6"`,
+			resource:    testVersioned("7"),
+			expectError: ErrPreconditionFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			req := httptest.NewRequest(tc.method, "/", nil)
+			if tc.ifMatch != "" {
+				req.Header.Set(HeaderIfMatch, tc.ifMatch)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+			assert.Equal(t, tc.expectError, CheckVersion(c, tc.resource))
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package echo
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// XLSXWriter writes single sheet Excel workbook (Office Open XML) row by row. Strings are written inline (there is
+// no shared strings table) so memory use does not grow with number of rows.
+//
+// Supported cell values are strings, `fmt.Stringer`, booleans, integers, floats and `time.Time` (formatted as date
+// and time), nil is empty cell and other values are written as `fmt.Sprint()` string.
+//
+// Example:
+//
+//	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationXLSX)
+//	c.Response().Header().Set(echo.HeaderContentDisposition, echo.ContentDisposition("attachment", "orders.xlsx"))
+//	w := echo.NewXLSXWriter(c.Response(), "Orders")
+//	for _, o := range orders {
+//		if err := w.WriteRow(o.ID, o.Customer, o.Total, o.CreatedAt); err != nil {
+//			return err
+//		}
+//	}
+//	return w.Close()
+type XLSXWriter struct {
+	zip       *zip.Writer
+	sheet     io.Writer
+	sheetName string
+	err       error
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+	// style 1 is date and time format used for `time.Time` values
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>` +
+		`</styleSheet>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+
+	// xlsxMaxSheetName is maximum length of sheet name accepted by Excel.
+	xlsxMaxSheetName = 31
+)
+
+// xlsxEpoch is day zero of Excel date serial numbers (1900 date system including its leap year bug).
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// NewXLSXWriter creates XLSXWriter writing workbook with sheet of given name to w. Characters not allowed in sheet
+// names are replaced and name is truncated to 31 characters, empty name is "Sheet1".
+func NewXLSXWriter(w io.Writer, sheetName string) *XLSXWriter {
+	return &XLSXWriter{zip: zip.NewWriter(w), sheetName: xlsxSheetName(sheetName)}
+}
+
+// WriteRow writes row of cells.
+func (x *XLSXWriter) WriteRow(cells ...interface{}) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("<row>")
+	for _, cell := range cells {
+		writeXLSXCell(&b, cell)
+	}
+	b.WriteString("</row>")
+	_, x.err = io.WriteString(x.sheet, b.String())
+	return x.err
+}
+
+// Flush writes buffered data to the underlying writer.
+func (x *XLSXWriter) Flush() error {
+	if x.err != nil {
+		return x.err
+	}
+	x.err = x.zip.Flush()
+	return x.err
+}
+
+// Close finishes the workbook. It does not close the underlying writer.
+func (x *XLSXWriter) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	if _, x.err = io.WriteString(x.sheet, xlsxSheetEnd); x.err != nil {
+		return x.err
+	}
+	x.err = x.zip.Close()
+	return x.err
+}
+
+// start writes static parts of the workbook and starts the sheet on first write.
+func (x *XLSXWriter) start() error {
+	if x.err != nil || x.sheet != nil {
+		return x.err
+	}
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(x.sheetName))
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, name.String())},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", xlsxSheetStart},
+	}
+	for _, p := range parts {
+		var w io.Writer
+		if w, x.err = x.zip.Create(p.name); x.err != nil {
+			return x.err
+		}
+		if _, x.err = io.WriteString(w, p.content); x.err != nil {
+			return x.err
+		}
+		x.sheet = w
+	}
+	return nil
+}
+
+func writeXLSXCell(b *strings.Builder, cell interface{}) {
+	switch v := cell.(type) {
+	case nil:
+		b.WriteString("<c/>")
+	case string:
+		writeXLSXString(b, v)
+	case time.Time:
+		if v.IsZero() {
+			b.WriteString("<c/>")
+			return
+		}
+		// Excel dates have no time zone, wall clock time of the value is shown
+		wall := time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), time.UTC)
+		serial := float64(wall.Unix()-xlsxEpoch.Unix())/86400 + float64(wall.Nanosecond())/(86400*1e9)
+		fmt.Fprintf(b, `<c s="1"><v>%s</v></c>`, strconv.FormatFloat(serial, 'f', -1, 64))
+	case fmt.Stringer:
+		writeXLSXString(b, v.String())
+	case bool:
+		if v {
+			b.WriteString(`<c t="b"><v>1</v></c>`)
+		} else {
+			b.WriteString(`<c t="b"><v>0</v></c>`)
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		fmt.Fprintf(b, "<c><v>%d</v></c>", v)
+	case float32:
+		writeXLSXNumber(b, float64(v))
+	case float64:
+		writeXLSXNumber(b, v)
+	default:
+		writeXLSXString(b, fmt.Sprint(v))
+	}
+}
+
+func writeXLSXNumber(b *strings.Builder, f float64) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		writeXLSXString(b, strconv.FormatFloat(f, 'f', -1, 64))
+		return
+	}
+	fmt.Fprintf(b, "<c><v>%s</v></c>", strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+func writeXLSXString(b *strings.Builder, s string) {
+	b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(b, []byte(s))
+	b.WriteString("</t></is></c>")
+}
+
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if utf8.RuneCountInString(name) > xlsxMaxSheetName {
+		name = string([]rune(name)[:xlsxMaxSheetName])
+	}
+	if name == "" {
+		return "Sheet1"
+	}
+	return name
+}
+
+// ContentDisposition returns value of `Content-Disposition` header (RFC 6266) of given type ("attachment" or
+// "inline") with file name. Names with non-ASCII characters are sent as UTF-8 `filename*` parameter with ASCII
+// fallback for old clients.
+//
+// Example:
+//
+//	c.Response().Header().Set(echo.HeaderContentDisposition, echo.ContentDisposition("attachment", "Umsätze.csv"))
+//	return echo.CSVStream(c, http.StatusOK, header, rows)
+func ContentDisposition(dispositionType, filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r >= 0x7f {
+			return '_'
+		}
+		return r
+	}, filename)
+	quoted := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(fallback)
+	if fallback == filename {
+		return dispositionType + `; filename="` + quoted + `"`
+	}
+	return dispositionType + `; filename="` + quoted + `"; filename*=UTF-8''` + encodeRFC5987(filename)
+}
+
+// encodeRFC5987 percent-encodes all bytes except attr-char of RFC 5987.
+func encodeRFC5987(s string) string {
+	const attrChars = "!#$&+-.^_`|~"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(attrChars, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// CSVStream sends rows received from the channel as CSV with status code, header row is sent first (nil
+// header is not sent). Rows are written as they come so exports of any size are streamed with constant
+// memory. Sending stops when the channel is closed or the client disconnects, producer should stop on
+// `Done()`.
+func CSVStream(c Context, code int, header []string, rows <-chan []string) (err error) {
+	defer trackRender(c)()
+	writeContentType(c, MIMETextCSVCharsetUTF8)
+	c.Response().WriteHeader(code)
+	w := csv.NewWriter(c.Response())
+	if header != nil {
+		if err = w.Write(header); err != nil {
+			return
+		}
+	}
+	for {
+		var row []string
+		var ok bool
+		select {
+		case row, ok = <-rows:
+		default:
+			// producer is not ready, send rows written so far
+			w.Flush()
+			if err = w.Error(); err != nil {
+				return
+			}
+			flush(c)
+			select {
+			case row, ok = <-rows:
+			case <-Done(c):
+				return c.Request().Context().Err()
+			}
+		}
+		if !ok {
+			break
+		}
+		if err = w.Write(row); err != nil {
+			return
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// XLSXStream sends rows received from the channel as single sheet Excel workbook with status code, header
+// row is sent first (nil header is not sent). See `XLSXWriter` for supported cell values and
+// `CSVStream()` for streaming.
+func XLSXStream(c Context, code int, header []string, rows <-chan []interface{}) (err error) {
+	defer trackRender(c)()
+	writeContentType(c, MIMEApplicationXLSX)
+	c.Response().WriteHeader(code)
+	w := NewXLSXWriter(c.Response(), "")
+	if header != nil {
+		cells := make([]interface{}, len(header))
+		for i, h := range header {
+			cells[i] = h
+		}
+		if err = w.WriteRow(cells...); err != nil {
+			return
+		}
+	}
+	for {
+		var row []interface{}
+		var ok bool
+		select {
+		case row, ok = <-rows:
+		default:
+			// producer is not ready, send rows written so far
+			if err = w.Flush(); err != nil {
+				return
+			}
+			flush(c)
+			select {
+			case row, ok = <-rows:
+			case <-Done(c):
+				return c.Request().Context().Err()
+			}
+		}
+		if !ok {
+			break
+		}
+		if err = w.WriteRow(row...); err != nil {
+			return
+		}
+	}
+	return w.Close()
+}
+
+// flush flushes response when underlying writer supports it.
+// flush sends response written so far to the client when the writer supports it.
+func flush(c Context) {
+	if f, ok := c.Response().Writer.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,152 @@
+package echo
+
+import (
+	"archive/zip"
+	"bytes"
+	stdContext "context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_CSVStream(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/export", nil), rec)
+
+	rows := make(chan []string)
+	go func() {
+		defer close(rows)
+		rows <- []string{"1", "Jon, Jr.", `say "hi"`}
+		rows <- []string{"2", "Ann", "multi\nline"}
+	}()
+	c.Response().Header().Set(HeaderContentDisposition, ContentDisposition("attachment", "users.csv"))
+	err := CSVStream(c, http.StatusOK, []string{"id", "name", "note"}, rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MIMETextCSVCharsetUTF8, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `attachment; filename="users.csv"`, rec.Header().Get(HeaderContentDisposition))
+	assert.Equal(t, "id,name,note\n1,\"Jon, Jr.\",\"say \"\"hi\"\"\"\n2,Ann,\"multi\nline\"\n", rec.Body.String())
+	assert.True(t, rec.Flushed)
+}
+
+func TestContext_CSVStream_noHeader(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/export", nil), rec)
+
+	rows := make(chan []string, 1)
+	rows <- []string{"a", "b"}
+	close(rows)
+
+	assert.NoError(t, CSVStream(c, http.StatusOK, nil, rows))
+	assert.Equal(t, "a,b\n", rec.Body.String())
+}
+
+func TestContext_CSVStream_clientDisconnected(t *testing.T) {
+	e := New()
+	ctx, cancel := stdContext.WithCancel(stdContext.Background())
+	req := httptest.NewRequest(http.MethodGet, "/export", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	rows := make(chan []string)
+	go func() {
+		rows <- []string{"1"}
+		cancel()
+	}()
+
+	err := CSVStream(c, http.StatusOK, []string{"id"}, rows)
+	assert.Equal(t, stdContext.Canceled, err)
+	assert.Equal(t, "id\n1\n", rec.Body.String())
+}
+
+func TestContext_XLSXStream(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/export", nil), rec)
+
+	rows := make(chan []interface{})
+	go func() {
+		defer close(rows)
+		rows <- []interface{}{1, "Jon <jr> & co", 12.5, true, time.Date(2021, 1, 2, 12, 0, 0, 0, time.UTC)}
+		rows <- []interface{}{uint8(2), nil, math.NaN(), false, time.Time{}, http.StatusText(404), []int{1}}
+	}()
+	err := XLSXStream(c, http.StatusOK, []string{"id", "name"}, rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MIMEApplicationXLSX, rec.Header().Get(HeaderContentType))
+	files := readZip(t, rec.Body.Bytes())
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Sheet1" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files, "xl/styles.xml")
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`+
+		`<row><c t="inlineStr"><is><t xml:space="preserve">id</t></is></c><c t="inlineStr"><is><t xml:space="preserve">name</t></is></c></row>`+
+		`<row><c><v>1</v></c><c t="inlineStr"><is><t xml:space="preserve">Jon &lt;jr&gt; &amp; co</t></is></c>`+
+		`<c><v>12.5</v></c><c t="b"><v>1</v></c><c s="1"><v>44198.5</v></c></row>`+
+		`<row><c><v>2</v></c><c/><c t="inlineStr"><is><t xml:space="preserve">NaN</t></is></c><c t="b"><v>0</v></c><c/>`+
+		`<c t="inlineStr"><is><t xml:space="preserve">Not Found</t></is></c><c t="inlineStr"><is><t xml:space="preserve">[1]</t></is></c></row>`+
+		`</sheetData></worksheet>`, files["xl/worksheets/sheet1.xml"])
+}
+
+func TestXLSXWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewXLSXWriter(buf, `Q1/2021: "Sales" & [returns] of the whole company`)
+	assert.NoError(t, w.Close())
+
+	files := readZip(t, buf.Bytes())
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Q1_2021_ &#34;Sales&#34; &amp; _returns_ of" sheetId="1"`)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData></sheetData></worksheet>`,
+		files["xl/worksheets/sheet1.xml"])
+}
+
+func TestContentDisposition(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		typ    string
+		file   string
+		expect string
+	}{
+		{name: "ascii", typ: "attachment", file: "report.csv", expect: `attachment; filename="report.csv"`},
+		{name: "quotes", typ: "inline", file: `a "b"\c.txt`, expect: `inline; filename="a \"b\"\\c.txt"`},
+		{
+			name:   "non-ascii",
+			typ:    "attachment",
+			file:   "Umsätze 2021 (€).xlsx",
+			expect: `attachment; filename="Ums_tze 2021 (_).xlsx"; filename*=UTF-8''Ums%C3%A4tze%202021%20%28%E2%82%AC%29.xlsx`,
+		},
+		{name: "control characters", typ: "attachment", file: "a\r\nb", expect: `attachment; filename="a__b"; filename*=UTF-8''a%0D%0Ab`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, ContentDisposition(tc.typ, tc.file))
+		})
+	}
+}
+
+func readZip(t *testing.T, b []byte) map[string]string {
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		content, err := ioutil.ReadAll(rc)
+		rc.Close()
+		assert.NoError(t, err)
+		files[f.Name] = string(content)
+	}
+	return files
+}
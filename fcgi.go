@@ -0,0 +1,81 @@
+package echo
+
+import (
+	stdContext "context"
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/fcgi"
+	"os"
+	"sync"
+)
+
+// fcgiEngine is server engine serving FastCGI connections with `net/http/fcgi`.
+type fcgiEngine struct {
+	mu       sync.Mutex
+	listener net.Listener
+	closed   bool
+}
+
+// ServeFCGI serves FastCGI requests (i.e. from nginx `fastcgi_pass` or Apache `mod_fcgid`) accepted on the listener
+// with full router and middleware stack. When the listener is nil, connections are accepted on standard input as
+// done by web servers spawning FastCGI applications. `Echo#Shutdown()` and `Echo#Close()` stop accepting
+// connections, requests being served are not waited for.
+//
+// Example:
+//
+//	l, err := net.Listen("unix", "/run/app.sock")
+//	if err != nil {
+//		e.Logger.Fatal(err)
+//	}
+//	e.Logger.Fatal(e.ServeFCGI(l))
+func (e *Echo) ServeFCGI(l net.Listener) (err error) {
+	if l == nil {
+		if l, err = net.FileListener(os.Stdin); err != nil {
+			return err
+		}
+	}
+	e.startupMutex.Lock()
+	e.Listener = l
+	e.startupMutex.Unlock()
+	return e.StartEngine("", new(fcgiEngine))
+}
+
+// ServeCGI serves the single request of current CGI process, as invoked by web server for classic CGI scripts.
+// Response is written to standard output so banner is never printed.
+func (e *Echo) ServeCGI() error {
+	return cgi.Serve(e)
+}
+
+func (f *fcgiEngine) Serve(l net.Listener, h http.Handler) error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		l.Close()
+		return http.ErrServerClosed
+	}
+	f.listener = l
+	f.mu.Unlock()
+
+	err := fcgi.Serve(l, h)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return http.ErrServerClosed
+	}
+	return err
+}
+
+func (f *fcgiEngine) Shutdown(ctx stdContext.Context) error {
+	return f.Close()
+}
+
+func (f *fcgiEngine) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	if f.listener == nil {
+		return nil
+	}
+	return f.listener.Close()
+}
@@ -0,0 +1,131 @@
+package echo
+
+import (
+	"bufio"
+	"bytes"
+	stdContext "context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fcgiRequest sends request over FastCGI connection and returns raw CGI response (headers and body).
+func fcgiRequest(t *testing.T, addr string, params map[string]string, body string) string {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	write := func(recType uint8, content []byte) {
+		header := []byte{1, recType, 0, 1, 0, 0, 0, 0}
+		binary.BigEndian.PutUint16(header[4:], uint16(len(content)))
+		_, err := conn.Write(append(header, content...))
+		require.NoError(t, err)
+	}
+	write(1, []byte{0, 1, 0, 0, 0, 0, 0, 0}) // begin request, responder role
+	p := new(bytes.Buffer)
+	for k, v := range params {
+		p.WriteByte(byte(len(k)))
+		p.WriteByte(byte(len(v)))
+		p.WriteString(k)
+		p.WriteString(v)
+	}
+	write(4, p.Bytes())
+	write(4, nil)
+	if body != "" {
+		write(5, []byte(body))
+	}
+	write(5, nil)
+
+	out := new(strings.Builder)
+	for {
+		header := make([]byte, 8)
+		_, err := io.ReadFull(conn, header)
+		require.NoError(t, err)
+		content := make([]byte, int(binary.BigEndian.Uint16(header[4:]))+int(header[6]))
+		_, err = io.ReadFull(conn, content)
+		require.NoError(t, err)
+		switch header[1] {
+		case 3: // end request
+			return out.String()
+		case 6: // stdout
+			out.Write(content[:binary.BigEndian.Uint16(header[4:])])
+		}
+	}
+}
+
+func TestEcho_ServeFCGI(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			c.Response().Header().Set("X-Middleware", "true")
+			return next(c)
+		}
+	})
+	e.POST("/users/:id", func(c Context) error {
+		b, _ := ioutil.ReadAll(c.Request().Body)
+		return c.String(http.StatusCreated, c.Param("id")+":"+c.QueryParam("q")+":"+string(b))
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.ServeFCGI(l)
+	}()
+
+	res := fcgiRequest(t, l.Addr().String(), map[string]string{
+		"REQUEST_METHOD":  "POST",
+		"REQUEST_URI":     "/users/1?q=x",
+		"SERVER_PROTOCOL": "HTTP/1.1",
+		"CONTENT_LENGTH":  "4",
+	}, "body")
+	assert.Contains(t, res, "Status: 201 Created\r\n")
+	assert.Contains(t, res, "X-Middleware: true\r\n")
+	assert.True(t, strings.HasSuffix(res, "\r\n\r\n1:x:body"), res)
+
+	ctx, cancel := stdContext.WithTimeout(stdContext.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, e.Shutdown(ctx))
+	assert.Equal(t, http.ErrServerClosed, <-errs)
+}
+
+func TestEcho_ServeCGI(t *testing.T) {
+	e := New()
+	e.GET("/hello", func(c Context) error {
+		return c.String(http.StatusOK, "Hello "+c.QueryParam("name"))
+	})
+
+	for k, v := range map[string]string{
+		"REQUEST_METHOD":  "GET",
+		"REQUEST_URI":     "/hello?name=cgi",
+		"SERVER_PROTOCOL": "HTTP/1.1",
+	} {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	err = e.ServeCGI()
+	os.Stdout = stdout
+	w.Close()
+	require.NoError(t, err)
+
+	res, err := http.ReadResponse(bufio.NewReader(io.MultiReader(strings.NewReader("HTTP/1.1 200 OK\r\n"), r)), nil)
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "200 OK", res.Header.Get("Status"))
+	assert.Equal(t, "Hello cgi", string(body))
+}
@@ -0,0 +1,291 @@
+package echo
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Feed is a syndication feed rendered by `WriteFeed()` either as RSS 2.0 or Atom document.
+	Feed struct {
+		// Format selects feed document format. Defaults to FeedRSS.
+		Format      FeedFormat
+		Title       string
+		Link        string
+		Description string
+		// ID is unique and permanent identifier of the feed. Used by Atom and defaults to Link.
+		ID      string
+		Author  string
+		Updated time.Time
+		Items   []*FeedItem
+	}
+
+	// FeedItem is a single entry of the feed.
+	FeedItem struct {
+		Title       string
+		Link        string
+		Description string
+		// Content is full (HTML) content of the item. Rendered by Atom only.
+		Content string
+		// ID is unique and permanent identifier of the item (RSS `guid`). Defaults to Link.
+		ID        string
+		Author    string
+		Published time.Time
+		Updated   time.Time
+		Enclosure *FeedEnclosure
+	}
+
+	// FeedEnclosure is the media object (e.g. podcast episode) attached to the item.
+	FeedEnclosure struct {
+		URL    string
+		Length int64
+		Type   string
+	}
+
+	// FeedFormat is the document format of the feed.
+	FeedFormat string
+
+	rssDocument struct {
+		XMLName xml.Name   `xml:"rss"`
+		Version string     `xml:"version,attr"`
+		Channel rssChannel `xml:"channel"`
+	}
+
+	rssChannel struct {
+		Title          string    `xml:"title"`
+		Link           string    `xml:"link"`
+		Description    string    `xml:"description"`
+		ManagingEditor string    `xml:"managingEditor,omitempty"`
+		LastBuildDate  string    `xml:"lastBuildDate,omitempty"`
+		Items          []rssItem `xml:"item"`
+	}
+
+	rssItem struct {
+		Title       string        `xml:"title,omitempty"`
+		Link        string        `xml:"link,omitempty"`
+		Description string        `xml:"description,omitempty"`
+		Author      string        `xml:"author,omitempty"`
+		GUID        *rssGUID      `xml:"guid,omitempty"`
+		PubDate     string        `xml:"pubDate,omitempty"`
+		Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+	}
+
+	rssGUID struct {
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	}
+
+	rssEnclosure struct {
+		URL    string `xml:"url,attr"`
+		Length int64  `xml:"length,attr"`
+		Type   string `xml:"type,attr"`
+	}
+
+	atomDocument struct {
+		XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+		Title    string      `xml:"title"`
+		ID       string      `xml:"id"`
+		Updated  string      `xml:"updated"`
+		Subtitle string      `xml:"subtitle,omitempty"`
+		Links    []atomLink  `xml:"link"`
+		Author   *atomAuthor `xml:"author,omitempty"`
+		Entries  []atomEntry `xml:"entry"`
+	}
+
+	atomEntry struct {
+		Title     string       `xml:"title"`
+		ID        string       `xml:"id"`
+		Updated   string       `xml:"updated"`
+		Published string       `xml:"published,omitempty"`
+		Links     []atomLink   `xml:"link"`
+		Author    *atomAuthor  `xml:"author,omitempty"`
+		Summary   string       `xml:"summary,omitempty"`
+		Content   *atomContent `xml:"content,omitempty"`
+	}
+
+	atomLink struct {
+		Href   string `xml:"href,attr"`
+		Rel    string `xml:"rel,attr,omitempty"`
+		Type   string `xml:"type,attr,omitempty"`
+		Length string `xml:"length,attr,omitempty"`
+	}
+
+	atomAuthor struct {
+		Name string `xml:"name"`
+	}
+
+	atomContent struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	}
+)
+
+// Feed formats
+const (
+	FeedRSS  FeedFormat = "rss"
+	FeedAtom FeedFormat = "atom"
+)
+
+// LastModified returns the latest update or publish time of the feed and its items.
+func (f *Feed) LastModified() time.Time {
+	t := f.Updated
+	for _, item := range f.Items {
+		if it := item.lastModified(); it.After(t) {
+			t = it
+		}
+	}
+	return t
+}
+
+func (i *FeedItem) lastModified() time.Time {
+	if i.Updated.After(i.Published) {
+		return i.Updated
+	}
+	return i.Published
+}
+
+func (i *FeedItem) id() string {
+	if i.ID != "" {
+		return i.ID
+	}
+	return i.Link
+}
+
+func (f *Feed) rss() *rssDocument {
+	doc := &rssDocument{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:          f.Title,
+			Link:           f.Link,
+			Description:    f.Description,
+			ManagingEditor: f.Author,
+			Items:          make([]rssItem, len(f.Items)),
+		},
+	}
+	if lm := f.LastModified(); !lm.IsZero() {
+		doc.Channel.LastBuildDate = lm.UTC().Format(time.RFC1123Z)
+	}
+	for i, item := range f.Items {
+		ri := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Author:      item.Author,
+		}
+		if id := item.id(); id != "" {
+			ri.GUID = &rssGUID{IsPermaLink: item.ID == "", Value: id}
+		}
+		if !item.Published.IsZero() {
+			ri.PubDate = item.Published.UTC().Format(time.RFC1123Z)
+		}
+		if e := item.Enclosure; e != nil {
+			ri.Enclosure = &rssEnclosure{URL: e.URL, Length: e.Length, Type: e.Type}
+		}
+		doc.Channel.Items[i] = ri
+	}
+	return doc
+}
+
+func (f *Feed) atom() *atomDocument {
+	id := f.ID
+	if id == "" {
+		id = f.Link
+	}
+	doc := &atomDocument{
+		Title:    f.Title,
+		ID:       id,
+		Updated:  f.LastModified().UTC().Format(time.RFC3339),
+		Subtitle: f.Description,
+		Entries:  make([]atomEntry, len(f.Items)),
+	}
+	if f.Link != "" {
+		doc.Links = []atomLink{{Href: f.Link, Rel: "alternate"}}
+	}
+	if f.Author != "" {
+		doc.Author = &atomAuthor{Name: f.Author}
+	}
+	for i, item := range f.Items {
+		e := atomEntry{
+			Title:   item.Title,
+			ID:      item.id(),
+			Updated: item.lastModified().UTC().Format(time.RFC3339),
+			Summary: item.Description,
+		}
+		if !item.Published.IsZero() {
+			e.Published = item.Published.UTC().Format(time.RFC3339)
+		}
+		if item.Link != "" {
+			e.Links = append(e.Links, atomLink{Href: item.Link, Rel: "alternate"})
+		}
+		if enc := item.Enclosure; enc != nil {
+			e.Links = append(e.Links, atomLink{Href: enc.URL, Rel: "enclosure", Type: enc.Type, Length: strconv.FormatInt(enc.Length, 10)})
+		}
+		if item.Author != "" {
+			e.Author = &atomAuthor{Name: item.Author}
+		}
+		if item.Content != "" {
+			e.Content = &atomContent{Type: "html", Value: item.Content}
+		}
+		doc.Entries[i] = e
+	}
+	return doc
+}
+
+// WriteFeed sends a RSS 2.0 or Atom feed response with status code. For status code 200 response contains `ETag`
+// and `Last-Modified` (latest item) headers and conditional requests are answered with 304.
+func WriteFeed(c Context, code int, f *Feed) (err error) {
+	var doc interface{}
+	contentType := MIMEApplicationRSSXMLCharsetUTF8
+	if f.Format == FeedAtom {
+		doc = f.atom()
+		contentType = MIMEApplicationAtomXMLCharsetUTF8
+	} else {
+		doc = f.rss()
+	}
+
+	pool := c.Echo().bufferPool()
+	buf := pool.Get()
+	defer pool.Put(buf)
+	buf.WriteString(xml.Header)
+	if err = xml.NewEncoder(buf).Encode(doc); err != nil {
+		return
+	}
+
+	if code == http.StatusOK {
+		sum := sha1.Sum(buf.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+		lastModified := f.LastModified()
+
+		header := c.Response().Header()
+		header.Set(HeaderETag, etag)
+		if !lastModified.IsZero() {
+			header.Set(HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+		}
+		if feedNotModified(c.Request(), etag, lastModified) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
+	return c.Blob(code, contentType, buf.Bytes())
+}
+
+func feedNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get(HeaderIfNoneMatch); inm != "" {
+		for _, v := range strings.Split(inm, ",") {
+			v = strings.TrimSpace(v)
+			if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get(HeaderIfModifiedSince); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFeed() *Feed {
+	return &Feed{
+		Title:       "Echo blog",
+		Link:        "https://example.com/blog",
+		Description: "News",
+		Author:      "jon@example.com (Jon Snow)",
+		Items: []*FeedItem{
+			{
+				Title:       "Episode 1",
+				Link:        "https://example.com/blog/1",
+				Description: "First",
+				Content:     "<p>First</p>",
+				Published:   time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC),
+				Enclosure:   &FeedEnclosure{URL: "https://example.com/1.mp3", Length: 1024, Type: "audio/mpeg"},
+			},
+			{
+				Title:     "Episode 2",
+				ID:        "urn:episode:2",
+				Published: time.Date(2021, 12, 2, 10, 0, 0, 0, time.UTC),
+				Updated:   time.Date(2021, 12, 3, 10, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func TestContextFeed_RSS(t *testing.T) {
+	e := New()
+	req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := WriteFeed(c, http.StatusOK, testFeed())
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, MIMEApplicationRSSXMLCharsetUTF8, rec.Header().Get(HeaderContentType))
+		assert.Equal(t, "Fri, 03 Dec 2021 10:00:00 GMT", rec.Header().Get(HeaderLastModified))
+		assert.NotEmpty(t, rec.Header().Get(HeaderETag))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<rss version="2.0"><channel><title>Echo blog</title><link>https://example.com/blog</link><description>News</description>`+
+			`<managingEditor>jon@example.com (Jon Snow)</managingEditor><lastBuildDate>Fri, 03 Dec 2021 10:00:00 +0000</lastBuildDate>`+
+			`<item><title>Episode 1</title><link>https://example.com/blog/1</link><description>First</description>`+
+			`<guid isPermaLink="true">https://example.com/blog/1</guid><pubDate>Wed, 01 Dec 2021 10:00:00 +0000</pubDate>`+
+			`<enclosure url="https://example.com/1.mp3" length="1024" type="audio/mpeg"></enclosure></item>`+
+			`<item><title>Episode 2</title><guid isPermaLink="false">urn:episode:2</guid><pubDate>Thu, 02 Dec 2021 10:00:00 +0000</pubDate></item>`+
+			`</channel></rss>`, rec.Body.String())
+	}
+}
+
+func TestContextFeed_Atom(t *testing.T) {
+	e := New()
+	req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	f := testFeed()
+	f.Format = FeedAtom
+	err := WriteFeed(c, http.StatusOK, f)
+	if assert.NoError(t, err) {
+		assert.Equal(t, MIMEApplicationAtomXMLCharsetUTF8, rec.Header().Get(HeaderContentType))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<feed xmlns="http://www.w3.org/2005/Atom"><title>Echo blog</title><id>https://example.com/blog</id>`+
+			`<updated>2021-12-03T10:00:00Z</updated><subtitle>News</subtitle><link href="https://example.com/blog" rel="alternate"></link>`+
+			`<author><name>jon@example.com (Jon Snow)</name></author>`+
+			`<entry><title>Episode 1</title><id>https://example.com/blog/1</id><updated>2021-12-01T10:00:00Z</updated>`+
+			`<published>2021-12-01T10:00:00Z</published><link href="https://example.com/blog/1" rel="alternate"></link>`+
+			`<link href="https://example.com/1.mp3" rel="enclosure" type="audio/mpeg" length="1024"></link>`+
+			`<summary>First</summary><content type="html">&lt;p&gt;First&lt;/p&gt;</content></entry>`+
+			`<entry><title>Episode 2</title><id>urn:episode:2</id><updated>2021-12-03T10:00:00Z</updated>`+
+			`<published>2021-12-02T10:00:00Z</published></entry>`+
+			`</feed>`, rec.Body.String())
+	}
+}
+
+func TestContextFeed_conditional(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/feed", nil), rec)
+	assert.NoError(t, WriteFeed(c, http.StatusOK, testFeed()))
+	etag := rec.Header().Get(HeaderETag)
+
+	var testCases = []struct {
+		name       string
+		whenHeader string
+		whenValue  string
+		expectCode int
+	}{
+		{name: "ok, etag matches", whenHeader: HeaderIfNoneMatch, whenValue: etag, expectCode: http.StatusNotModified},
+		{name: "ok, etag differs", whenHeader: HeaderIfNoneMatch, whenValue: `"other"`, expectCode: http.StatusOK},
+		{name: "ok, not modified since", whenHeader: HeaderIfModifiedSince, whenValue: "Fri, 03 Dec 2021 10:00:00 GMT", expectCode: http.StatusNotModified},
+		{name: "ok, modified since", whenHeader: HeaderIfModifiedSince, whenValue: "Thu, 02 Dec 2021 10:00:00 GMT", expectCode: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+			req.Header.Set(tc.whenHeader, tc.whenValue)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			assert.NoError(t, WriteFeed(c, http.StatusOK, testFeed()))
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectCode == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
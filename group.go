@@ -14,9 +14,33 @@ type (
 		prefix     string
 		middleware []MiddlewareFunc
 		echo       *Echo
+		parent     *Group
+
+		// HTTPErrorHandler handles errors of requests matching routes of the group (and its sub-groups) instead of
+		// `Echo#HTTPErrorHandler`, i.e. problem+json errors for `/api` group and HTML error pages for `/web`
+		// group. Errors returned by pre-middleware are handled by `Echo#HTTPErrorHandler`.
+		// Optional.
+		HTTPErrorHandler HTTPErrorHandler
+
+		// Renderer renders templates of routes of the group (and its sub-groups) instead of `Echo#Renderer`.
+		// Optional.
+		Renderer Renderer
+
+		// RecoveryPolicy defines how `middleware.Recover` handles panics of routes of the group (and its
+		// sub-groups) unless route sets its own policy with `Route#RecoveryPolicy()`.
+		// Optional. Default value RecoveryDefault (policy of the middleware config).
+		RecoveryPolicy RecoveryPolicy
+
+		// ContentTypes are request content types accepted by `middleware.ContentType` for routes of the group (and
+		// its sub-groups) unless route sets its own with `Route#ContentTypes()`.
+		// Optional. Default value nil (content types of the middleware).
+		ContentTypes []string
 	}
 )
 
+// metaGroup is metadata key of group the route was added with.
+const metaGroup = "echo_group"
+
 // Use implements `Echo#Use()` for sub-routes within the Group.
 func (g *Group) Use(middleware ...MiddlewareFunc) {
 	g.middleware = append(g.middleware, middleware...)
@@ -97,8 +121,8 @@ func (g *Group) Group(prefix string, middleware ...MiddlewareFunc) (sg *Group) {
 	m := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
 	m = append(m, g.middleware...)
 	m = append(m, middleware...)
-	sg = g.echo.Group(g.prefix+prefix, m...)
-	sg.host = g.host
+	sg = &Group{host: g.host, prefix: g.prefix + prefix, echo: g.echo, parent: g}
+	sg.Use(m...)
 	return
 }
 
@@ -120,5 +144,55 @@ func (g *Group) Add(method, path string, handler HandlerFunc, middleware ...Midd
 	m := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
 	m = append(m, g.middleware...)
 	m = append(m, middleware...)
-	return g.echo.add(g.host, method, g.prefix+path, handler, m...)
+	return g.echo.add(g.host, method, g.prefix+path, handler, m...).SetMeta(metaGroup, g)
+}
+
+// groupOf returns group of the matched route or nil.
+func groupOf(c Context) *Group {
+	r := RouteOf(c)
+	if r == nil {
+		return nil
+	}
+	g, _ := r.GetMeta(metaGroup).(*Group)
+	return g
+}
+
+// errorHandler returns error handler of the group or its closest parent group with error handler.
+func (g *Group) errorHandler() HTTPErrorHandler {
+	for ; g != nil; g = g.parent {
+		if g.HTTPErrorHandler != nil {
+			return g.HTTPErrorHandler
+		}
+	}
+	return nil
+}
+
+// renderer returns renderer of the group or its closest parent group with renderer.
+func (g *Group) renderer() Renderer {
+	for ; g != nil; g = g.parent {
+		if g.Renderer != nil {
+			return g.Renderer
+		}
+	}
+	return nil
+}
+
+// recoveryPolicy returns recovery policy of the group or its closest parent group with policy.
+func (g *Group) recoveryPolicy() RecoveryPolicy {
+	for ; g != nil; g = g.parent {
+		if g.RecoveryPolicy != RecoveryDefault {
+			return g.RecoveryPolicy
+		}
+	}
+	return RecoveryDefault
+}
+
+// contentTypes returns accepted content types of the group or its closest parent group with content types.
+func (g *Group) contentTypes() []string {
+	for ; g != nil; g = g.parent {
+		if g.ContentTypes != nil {
+			return g.ContentTypes
+		}
+	}
+	return nil
 }
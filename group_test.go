@@ -1,6 +1,8 @@
 package echo
 
 import (
+	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -119,3 +121,65 @@ func TestGroupRouteMiddlewareWithMatchAny(t *testing.T) {
 	assert.Equal(t, "/*", m)
 
 }
+
+func TestGroupHTTPErrorHandlerAndRenderer(t *testing.T) {
+	e := New()
+	e.Renderer = rendererFunc(func(w io.Writer, name string, data interface{}, c Context) error {
+		_, err := io.WriteString(w, "global "+name)
+		return err
+	})
+
+	api := e.Group("/api")
+	api.HTTPErrorHandler = func(err error, c Context) {
+		c.JSONBlob(http.StatusTeapot, []byte(`{"title":"`+err.Error()+`"}`))
+	}
+	api.GET("/fail", func(c Context) error {
+		return errors.New("api failure")
+	})
+	v1 := api.Group("/v1")
+	v1.GET("/fail", func(c Context) error {
+		return errors.New("v1 failure")
+	})
+
+	web := e.Group("/web")
+	web.Renderer = rendererFunc(func(w io.Writer, name string, data interface{}, c Context) error {
+		_, err := io.WriteString(w, "web "+name)
+		return err
+	})
+	web.GET("/page", func(c Context) error {
+		return c.Render(http.StatusOK, "page", nil)
+	})
+	e.GET("/page", func(c Context) error {
+		return c.Render(http.StatusOK, "page", nil)
+	})
+	e.GET("/fail", func(c Context) error {
+		return errors.New("failure")
+	})
+
+	var testCases = []struct {
+		whenURL    string
+		expectCode int
+		expectBody string
+	}{
+		{whenURL: "/api/fail", expectCode: http.StatusTeapot, expectBody: `{"title":"api failure"}`},
+		{whenURL: "/api/v1/fail", expectCode: http.StatusTeapot, expectBody: `{"title":"v1 failure"}`},
+		{whenURL: "/fail", expectCode: http.StatusInternalServerError, expectBody: `{"message":"Internal Server Error"}` + "\n"},
+		{whenURL: "/web/page", expectCode: http.StatusOK, expectBody: "web page"},
+		{whenURL: "/page", expectCode: http.StatusOK, expectBody: "global page"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.whenURL, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.whenURL, nil))
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+		})
+	}
+}
+
+type rendererFunc func(w io.Writer, name string, data interface{}, c Context) error
+
+func (f rendererFunc) Render(w io.Writer, name string, data interface{}, c Context) error {
+	return f(w, name, data, c)
+}
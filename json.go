@@ -2,16 +2,60 @@ package echo
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 )
 
 // DefaultJSONSerializer implements JSON encoding using encoding/json.
-type DefaultJSONSerializer struct{}
+//
+// Limits are enforced on the request body while it is being read by the decoder, before values are allocated, to
+// protect against small (i.e. compressed) payloads expanding to huge structures. Byte size of the body is limited
+// by `middleware.BodyLimit`. Zero value of a limit means no limit.
+type DefaultJSONSerializer struct {
+	// MaxDepth limits nesting depth of objects and arrays of deserialized documents. Exceeding it is responded
+	// with 400.
+	MaxDepth int
+
+	// MaxArrayLength limits number of elements of a single array. Exceeding it is responded with 413.
+	MaxArrayLength int
+
+	// MaxTokenSize limits size in bytes (as encoded in the document) of a single string, number or literal.
+	// Exceeding it is responded with 413.
+	MaxTokenSize int
+}
+
+// Errors
+var (
+	ErrJSONMaxDepthExceeded       = errors.New("json: maximum nesting depth exceeded")
+	ErrJSONMaxArrayLengthExceeded = errors.New("json: maximum array length exceeded")
+	ErrJSONMaxTokenSizeExceeded   = errors.New("json: maximum token size exceeded")
+)
+
+// jsonLimitReader scans JSON document passing through it and fails as soon as a limit is exceeded. Syntax is not
+// validated, that is left to the decoder.
+type jsonLimitReader struct {
+	r              io.Reader
+	maxDepth       int
+	maxArrayLength int
+	maxTokenSize   int
+
+	stack      []byte // '{' or '[' of open containers
+	counts     []int  // elements of open arrays, parallel to stack
+	firstValue bool   // array was just opened, next value is its first element
+	inString   bool
+	escaped    bool
+	tokenSize  int
+	err        error
+}
 
 // Serialize converts an interface into a json and writes it to the response.
 // You can optionally use the indent parameter to produce pretty JSONs.
 func (d DefaultJSONSerializer) Serialize(c Context, i interface{}, indent string) error {
+	if e := c.Echo(); e != nil && e.zeroAlloc {
+		return serializePooledJSON(c, i, indent)
+	}
 	enc := json.NewEncoder(c.Response())
 	if indent != "" {
 		enc.SetIndent("", indent)
@@ -21,7 +65,10 @@ func (d DefaultJSONSerializer) Serialize(c Context, i interface{}, indent string
 
 // Deserialize reads a JSON from a request body and converts it into an interface.
 func (d DefaultJSONSerializer) Deserialize(c Context, i interface{}) error {
-	err := json.NewDecoder(c.Request().Body).Decode(i)
+	err := json.NewDecoder(d.limitReader(c.Request().Body)).Decode(i)
+	if he := jsonLimitError(err); he != nil {
+		return he
+	}
 	if ute, ok := err.(*json.UnmarshalTypeError); ok {
 		return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unmarshal type error: expected=%v, got=%v, field=%v, offset=%v", ute.Type, ute.Value, ute.Field, ute.Offset)).SetInternal(err)
 	} else if se, ok := err.(*json.SyntaxError); ok {
@@ -29,3 +76,116 @@ func (d DefaultJSONSerializer) Deserialize(c Context, i interface{}) error {
 	}
 	return err
 }
+
+// limitReader returns r wrapped to enforce limits of the serializer.
+func (d DefaultJSONSerializer) limitReader(r io.Reader) io.Reader {
+	if d.MaxDepth <= 0 && d.MaxArrayLength <= 0 && d.MaxTokenSize <= 0 {
+		return r
+	}
+	return &jsonLimitReader{r: r, maxDepth: d.MaxDepth, maxArrayLength: d.MaxArrayLength, maxTokenSize: d.MaxTokenSize}
+}
+
+// jsonLimitError converts errors of exceeded limits to HTTPError or returns nil.
+func jsonLimitError(err error) *HTTPError {
+	switch err {
+	case ErrJSONMaxDepthExceeded:
+		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	case ErrJSONMaxArrayLengthExceeded, ErrJSONMaxTokenSizeExceeded:
+		return NewHTTPError(http.StatusRequestEntityTooLarge, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+// Read reads from the underlying reader and returns only bytes preceding the byte exceeding a limit, so the
+// decoder never sees complete document violating the limits.
+func (r *jsonLimitReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.r.Read(p)
+	for i, b := range p[:n] {
+		if r.err = r.scan(b); r.err != nil {
+			return i, r.err
+		}
+	}
+	return n, err
+}
+
+func (r *jsonLimitReader) scan(b byte) error {
+	if r.inString {
+		switch {
+		case r.escaped:
+			r.escaped = false
+		case b == '\\':
+			r.escaped = true
+		case b == '"':
+			r.inString = false
+			return nil
+		}
+		return r.grow()
+	}
+
+	switch b {
+	case ' ', '\t', '\r', '\n':
+		r.tokenSize = 0
+		return nil
+	case ']', '}':
+		r.firstValue = false
+		r.tokenSize = 0
+		if len(r.stack) > 0 {
+			r.stack = r.stack[:len(r.stack)-1]
+			r.counts = r.counts[:len(r.counts)-1]
+		}
+		return nil
+	case ',':
+		r.tokenSize = 0
+		if len(r.stack) > 0 && r.stack[len(r.stack)-1] == '[' {
+			return r.addElement()
+		}
+		return nil
+	case ':':
+		r.tokenSize = 0
+		return nil
+	}
+
+	if r.firstValue {
+		r.firstValue = false
+		if err := r.addElement(); err != nil {
+			return err
+		}
+	}
+	switch b {
+	case '{', '[':
+		r.tokenSize = 0
+		if r.maxDepth > 0 && len(r.stack) >= r.maxDepth {
+			return ErrJSONMaxDepthExceeded
+		}
+		r.stack = append(r.stack, b)
+		r.counts = append(r.counts, 0)
+		r.firstValue = b == '['
+		return nil
+	case '"':
+		r.inString = true
+		r.tokenSize = 0
+		return nil
+	}
+	// number or literal
+	return r.grow()
+}
+
+func (r *jsonLimitReader) grow() error {
+	r.tokenSize++
+	if r.maxTokenSize > 0 && r.tokenSize > r.maxTokenSize {
+		return ErrJSONMaxTokenSizeExceeded
+	}
+	return nil
+}
+
+func (r *jsonLimitReader) addElement() error {
+	i := len(r.counts) - 1
+	r.counts[i]++
+	if r.maxArrayLength > 0 && r.counts[i] > r.maxArrayLength {
+		return ErrJSONMaxArrayLengthExceeded
+	}
+	return nil
+}
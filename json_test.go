@@ -99,3 +99,83 @@ func TestDefaultJSONCodec_Decode(t *testing.T) {
 	assert.EqualError(err, "code=400, message=Unmarshal type error: expected=string, got=number, field=id, offset=7, internal=json: cannot unmarshal number into Go struct field .id of type string")
 
 }
+
+func TestDefaultJSONSerializer_Deserialize_limits(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		givenConfig DefaultJSONSerializer
+		whenBody    string
+		expectError string
+	}{
+		{
+			name:        "ok, within limits",
+			givenConfig: DefaultJSONSerializer{MaxDepth: 4, MaxArrayLength: 3, MaxTokenSize: 10},
+			whenBody:    `{"a": [1, "b\"c", {"d": [true]}], "e": [], "f": {}}`,
+		},
+		{
+			name:        "nok, max depth exceeded",
+			givenConfig: DefaultJSONSerializer{MaxDepth: 3},
+			whenBody:    `{"a": [{"b": [1]}]}`,
+			expectError: "code=400, message=json: maximum nesting depth exceeded, internal=json: maximum nesting depth exceeded",
+		},
+		{
+			name:        "nok, max array length exceeded",
+			givenConfig: DefaultJSONSerializer{MaxArrayLength: 3},
+			whenBody:    `{"a": [1, 2, 3], "b": [[], [], [], []]}`,
+			expectError: "code=413, message=json: maximum array length exceeded, internal=json: maximum array length exceeded",
+		},
+		{
+			name:        "nok, string too large",
+			givenConfig: DefaultJSONSerializer{MaxTokenSize: 10},
+			whenBody:    `{"a": "0123456789", "b": "0123456789A"}`,
+			expectError: "code=413, message=json: maximum token size exceeded, internal=json: maximum token size exceeded",
+		},
+		{
+			name:        "nok, escaped quote does not end string",
+			givenConfig: DefaultJSONSerializer{MaxTokenSize: 4},
+			whenBody:    `{"a": "ab\"cd"}`,
+			expectError: "code=413, message=json: maximum token size exceeded, internal=json: maximum token size exceeded",
+		},
+		{
+			name:        "nok, number too large",
+			givenConfig: DefaultJSONSerializer{MaxTokenSize: 10},
+			whenBody:    `{"a": 12345678901}`,
+			expectError: "code=413, message=json: maximum token size exceeded, internal=json: maximum token size exceeded",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.whenBody))
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			var v map[string]interface{}
+			err := tc.givenConfig.Deserialize(c, &v)
+			if tc.expectError != "" {
+				testify.EqualError(t, err, tc.expectError)
+				return
+			}
+			testify.NoError(t, err)
+		})
+	}
+}
+
+func TestDefaultBinder_BindBody_jsonLimits(t *testing.T) {
+	e := New()
+	e.JSONSerializer = &DefaultJSONSerializer{MaxArrayLength: 2}
+	e.Binder = &DefaultBinder{Strict: true}
+	body := `{"ids": [1, 2, 3]}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	var v struct {
+		IDs []int `json:"ids"`
+	}
+	err := c.Bind(&v)
+	if testify.Error(t, err) {
+		he, ok := err.(*HTTPError)
+		testify.True(t, ok)
+		testify.Equal(t, http.StatusRequestEntityTooLarge, he.Code)
+	}
+}
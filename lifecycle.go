@@ -0,0 +1,50 @@
+package echo
+
+import (
+	stdContext "context"
+)
+
+// OnStart registers hook run once when the first server of Echo is started, after its listener is created and
+// before requests are served. Start method returns the error of failed hook without serving. Hooks are meant to
+// start components living alongside the server (message consumers, background workers) and must not block.
+func (e *Echo) OnStart(hook func() error) {
+	e.startupMutex.Lock()
+	defer e.startupMutex.Unlock()
+	e.startHooks = append(e.startHooks, hook)
+}
+
+// OnShutdown registers hook run by `Echo#Shutdown()` after servers are shut down. Hooks are run in reverse order of
+// registration with the context of `Echo#Shutdown()` and only when start hooks were run.
+func (e *Echo) OnShutdown(hook func(ctx stdContext.Context) error) {
+	e.startupMutex.Lock()
+	defer e.startupMutex.Unlock()
+	e.shutdownHooks = append(e.shutdownHooks, hook)
+}
+
+// runStartHooks runs start hooks unless they were already run by other start method. Caller must hold startupMutex.
+func (e *Echo) runStartHooks() error {
+	if e.hooksStarted {
+		return nil
+	}
+	e.hooksStarted = true
+	for _, hook := range e.startHooks {
+		if err := hook(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runShutdownHooks runs all shutdown hooks and returns the first error. Caller must hold startupMutex.
+func (e *Echo) runShutdownHooks(ctx stdContext.Context) (err error) {
+	if !e.hooksStarted {
+		return nil
+	}
+	e.hooksStarted = false
+	for i := len(e.shutdownHooks) - 1; i >= 0; i-- {
+		if hErr := e.shutdownHooks[i](ctx); hErr != nil && err == nil {
+			err = hErr
+		}
+	}
+	return err
+}
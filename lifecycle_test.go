@@ -0,0 +1,58 @@
+package echo
+
+import (
+	stdContext "context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEcho_OnStartOnShutdown(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	var calls []string
+	e.OnStart(func() error {
+		calls = append(calls, "start 1")
+		return nil
+	})
+	e.OnStart(func() error {
+		calls = append(calls, "start 2")
+		return nil
+	})
+	e.OnShutdown(func(ctx stdContext.Context) error {
+		calls = append(calls, "shutdown 1")
+		return nil
+	})
+	e.OnShutdown(func(ctx stdContext.Context) error {
+		calls = append(calls, "shutdown 2")
+		return errors.New("shutdown error")
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.Start("127.0.0.1:0")
+	}()
+	require.NoError(t, waitForServerStart(e, errs, false))
+
+	err := e.Shutdown(stdContext.Background())
+	assert.EqualError(t, err, "shutdown error")
+	assert.Equal(t, http.ErrServerClosed, <-errs)
+	assert.Equal(t, []string{"start 1", "start 2", "shutdown 2", "shutdown 1"}, calls)
+
+	// hooks are not run again without start
+	assert.NoError(t, e.Shutdown(stdContext.Background()))
+	assert.Len(t, calls, 4)
+}
+
+func TestEcho_OnStartError(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.OnStart(func() error {
+		return errors.New("start error")
+	})
+	err := e.StartEngine("127.0.0.1:0", new(testEngine))
+	assert.EqualError(t, err, "start error")
+}
@@ -0,0 +1,401 @@
+/*
+Package loadtest replays recorded or generated requests against an in-process Echo instance and reports latency
+percentiles per route. It is meant for pre-merge performance regression checks where network and external
+services must not influence the results.
+
+Example:
+
+	func TestPerformance(t *testing.T) {
+		e := newServer()
+
+		requests := loadtest.Generate(loadtest.Profile{
+			{Method: http.MethodGet, Path: "/users/1", Weight: 3},
+			{Method: http.MethodPost, Path: "/users", Body: []byte(`{"name":"Jon"}`), Weight: 1},
+		}, 10000, 1)
+
+		report, err := loadtest.Run(e, loadtest.Config{Requests: requests, Concurrency: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(report)
+
+		baseline, _ := loadtest.ReadReport("testdata/baseline.json")
+		if regressions := report.Regressions(baseline, 0.2); len(regressions) > 0 {
+			t.Errorf("performance regressions: %v", regressions)
+		}
+	}
+*/
+package loadtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Request is a single request replayed against Echo instance.
+	Request struct {
+		Method string      `json:"method"`
+		Path   string      `json:"path"`
+		Header http.Header `json:"header,omitempty"`
+		Body   []byte      `json:"body,omitempty"`
+	}
+
+	// ProfileEntry describes request generated with relative frequency of Weight.
+	ProfileEntry struct {
+		Method string
+		Path   string
+		Header http.Header
+		Body   []byte
+		// Weight is relative frequency of the entry. Entries with weight <= 0 are generated with weight 1.
+		Weight int
+	}
+
+	// Profile is set of requests used to generate load.
+	Profile []ProfileEntry
+
+	// Config defines the config for load test run.
+	Config struct {
+		// Requests are replayed in given order. Required.
+		Requests []Request
+
+		// Concurrency is number of workers sending requests in parallel.
+		// Optional. Default value 1.
+		Concurrency int
+
+		// Iterations is how many times Requests are replayed.
+		// Optional. Default value 1.
+		Iterations int
+
+		// Warmup is number of requests from the beginning of Requests sent before measuring starts.
+		// Optional. Default value 0.
+		Warmup int
+	}
+
+	// Report contains results of load test run.
+	Report struct {
+		Total    int           `json:"total"`
+		Errors   int           `json:"errors"`
+		Duration time.Duration `json:"duration"`
+		Routes   []RouteStats  `json:"routes"`
+	}
+
+	// RouteStats contains latency statistics for single route. Requests are grouped by method and route path
+	// (e.g. `/users/:id`). Requests not matching any route are grouped by request path.
+	RouteStats struct {
+		Method string        `json:"method"`
+		Path   string        `json:"path"`
+		Count  int           `json:"count"`
+		Errors int           `json:"errors"`
+		Min    time.Duration `json:"min"`
+		Max    time.Duration `json:"max"`
+		Mean   time.Duration `json:"mean"`
+		P50    time.Duration `json:"p50"`
+		P90    time.Duration `json:"p90"`
+		P95    time.Duration `json:"p95"`
+		P99    time.Duration `json:"p99"`
+	}
+
+	logEntry struct {
+		Method string      `json:"method"`
+		URI    string      `json:"uri"`
+		Path   string      `json:"path"`
+		Header http.Header `json:"header"`
+		Body   string      `json:"body"`
+	}
+
+	sample struct {
+		route   string
+		method  string
+		latency time.Duration
+		failed  bool
+	}
+)
+
+// ErrNoRequests is returned when load test is run without requests.
+var ErrNoRequests = errors.New("loadtest: no requests to replay")
+
+// ParseLog parses recorded requests from JSON lines log, for example output of Logger middleware with default
+// format. Each line must contain `method` and `uri` (or `path`) fields and can contain `header` and `body`
+// fields. Empty lines and lines that are not JSON objects are skipped.
+func ParseLog(r io.Reader) ([]Request, error) {
+	requests := []Request{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 || b[0] != '{' {
+			continue
+		}
+		entry := logEntry{}
+		if err := json.Unmarshal(b, &entry); err != nil {
+			return nil, fmt.Errorf("loadtest: invalid log entry at line=%d: %v", line, err)
+		}
+		path := entry.URI
+		if path == "" {
+			path = entry.Path
+		}
+		if entry.Method == "" || path == "" {
+			return nil, fmt.Errorf("loadtest: log entry at line=%d is missing method or uri", line)
+		}
+		req := Request{Method: strings.ToUpper(entry.Method), Path: path, Header: entry.Header}
+		if entry.Body != "" {
+			req.Body = []byte(entry.Body)
+		}
+		requests = append(requests, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// Generate returns n requests picked from profile according to entry weights. Same seed always produces
+// same sequence of requests.
+func Generate(profile Profile, n int, seed int64) []Request {
+	if len(profile) == 0 || n <= 0 {
+		return []Request{}
+	}
+	total := 0
+	for _, e := range profile {
+		total += weight(e)
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	requests := make([]Request, n)
+	for i := range requests {
+		pick := rnd.Intn(total)
+		for _, e := range profile {
+			if pick -= weight(e); pick < 0 {
+				requests[i] = Request{Method: e.Method, Path: e.Path, Header: e.Header, Body: e.Body}
+				break
+			}
+		}
+	}
+	return requests
+}
+
+func weight(e ProfileEntry) int {
+	if e.Weight <= 0 {
+		return 1
+	}
+	return e.Weight
+}
+
+// Run replays requests from config against Echo instance and returns report. Responses with status 5xx are
+// counted as errors.
+func Run(e *echo.Echo, config Config) (*Report, error) {
+	if len(config.Requests) == 0 {
+		return nil, ErrNoRequests
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.Iterations <= 0 {
+		config.Iterations = 1
+	}
+
+	for i := 0; i < config.Warmup && i < len(config.Requests); i++ {
+		serve(e, config.Requests[i])
+	}
+
+	total := len(config.Requests) * config.Iterations
+	samples := make([]sample, total)
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	start := time.Now()
+	for w := 0; w < config.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				samples[i] = serve(e, config.Requests[i%len(config.Requests)])
+			}
+		}()
+	}
+	for i := 0; i < total; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := newReport(samples)
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+func serve(e *echo.Echo, r Request) sample {
+	var body io.Reader
+	if len(r.Body) > 0 {
+		body = bytes.NewReader(r.Body)
+	}
+	req := httptest.NewRequest(r.Method, r.Path, body)
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	e.ServeHTTP(rec, req)
+	latency := time.Since(start)
+
+	return sample{
+		route:   routePath(e, req),
+		method:  r.Method,
+		latency: latency,
+		failed:  rec.Code >= http.StatusInternalServerError,
+	}
+}
+
+// routePath returns path of the route request is matched to or request path when no route matches.
+func routePath(e *echo.Echo, req *http.Request) string {
+	router := e.Router()
+	if r, ok := e.Routers()[req.Host]; ok {
+		router = r
+	}
+	c := e.NewContext(req, nil)
+	router.Find(req.Method, echo.GetPath(req), c)
+	if p := c.Path(); p != "" {
+		return p
+	}
+	return req.URL.Path
+}
+
+func newReport(samples []sample) *Report {
+	type key struct{ method, route string }
+	groups := map[key][]sample{}
+	keys := []key{}
+	report := &Report{Total: len(samples)}
+	for _, s := range samples {
+		k := key{s.method, s.route}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], s)
+		if s.failed {
+			report.Errors++
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route == keys[j].route {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].route < keys[j].route
+	})
+
+	report.Routes = make([]RouteStats, 0, len(keys))
+	for _, k := range keys {
+		group := groups[k]
+		latencies := make([]time.Duration, len(group))
+		stats := RouteStats{Method: k.method, Path: k.route, Count: len(group)}
+		var sum time.Duration
+		for i, s := range group {
+			latencies[i] = s.latency
+			sum += s.latency
+			if s.failed {
+				stats.Errors++
+			}
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stats.Min = latencies[0]
+		stats.Max = latencies[len(latencies)-1]
+		stats.Mean = sum / time.Duration(len(latencies))
+		stats.P50 = percentile(latencies, 50)
+		stats.P90 = percentile(latencies, 90)
+		stats.P95 = percentile(latencies, 95)
+		stats.P99 = percentile(latencies, 99)
+		report.Routes = append(report.Routes, stats)
+	}
+	return report
+}
+
+// percentile returns p-th percentile of sorted latencies using nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Route returns statistics for given method and route path or nil when route was not requested.
+func (r *Report) Route(method, path string) *RouteStats {
+	for i := range r.Routes {
+		if r.Routes[i].Method == method && r.Routes[i].Path == path {
+			return &r.Routes[i]
+		}
+	}
+	return nil
+}
+
+// Regressions compares 95th percentile latency of each route to baseline report and returns description for
+// every route that is slower than baseline by more than maxIncrease (0.2 = 20%). Routes missing from baseline
+// are ignored.
+func (r *Report) Regressions(baseline *Report, maxIncrease float64) []string {
+	regressions := []string{}
+	if baseline == nil {
+		return regressions
+	}
+	for _, s := range r.Routes {
+		b := baseline.Route(s.Method, s.Path)
+		if b == nil || b.P95 <= 0 {
+			continue
+		}
+		increase := float64(s.P95-b.P95) / float64(b.P95)
+		if increase > maxIncrease {
+			regressions = append(regressions, fmt.Sprintf("%s %s: p95=%v, baseline p95=%v (+%.0f%%)",
+				s.Method, s.Path, s.P95, b.P95, increase*100))
+		}
+	}
+	return regressions
+}
+
+// String returns report formatted as text table.
+func (r *Report) String() string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "requests=%d errors=%d duration=%v\n", r.Total, r.Errors, r.Duration)
+	fmt.Fprintf(b, "%-8s %-30s %8s %8s %10s %10s %10s %10s %10s\n",
+		"METHOD", "ROUTE", "COUNT", "ERRORS", "MEAN", "P50", "P90", "P95", "P99")
+	for _, s := range r.Routes {
+		fmt.Fprintf(b, "%-8s %-30s %8d %8d %10v %10v %10v %10v %10v\n",
+			s.Method, s.Path, s.Count, s.Errors, s.Mean, s.P50, s.P90, s.P95, s.P99)
+	}
+	return b.String()
+}
+
+// WriteReport stores report as JSON file, for example to be used as baseline for later runs.
+func WriteReport(filename string, r *Report) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(b, '\n'), 0644)
+}
+
+// ReadReport reads report stored with WriteReport.
+func ReadReport(filename string) (*Report, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	r := new(Report)
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
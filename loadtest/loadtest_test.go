@@ -0,0 +1,134 @@
+package loadtest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEcho() *echo.Echo {
+	e := echo.New()
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Param("id"))
+	})
+	e.POST("/users", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.ErrInternalServerError
+	})
+	return e
+}
+
+func TestParseLog(t *testing.T) {
+	log := `{"time":"2021-01-01T00:00:00Z","method":"GET","uri":"/users/1?x=1","status":200}
+
+not json
+{"method":"post","path":"/users","header":{"Content-Type":["application/json"]},"body":"{}"}
+`
+	requests, err := ParseLog(strings.NewReader(log))
+	assert.NoError(t, err)
+	assert.Equal(t, []Request{
+		{Method: http.MethodGet, Path: "/users/1?x=1"},
+		{Method: http.MethodPost, Path: "/users", Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte("{}")},
+	}, requests)
+
+	_, err = ParseLog(strings.NewReader(`{"status":200}`))
+	assert.EqualError(t, err, "loadtest: log entry at line=1 is missing method or uri")
+
+	_, err = ParseLog(strings.NewReader(`{"method":`))
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	profile := Profile{
+		{Method: http.MethodGet, Path: "/users/1", Weight: 3},
+		{Method: http.MethodPost, Path: "/users"},
+	}
+	requests := Generate(profile, 1000, 42)
+	assert.Len(t, requests, 1000)
+	assert.Equal(t, requests, Generate(profile, 1000, 42))
+
+	gets := 0
+	for _, r := range requests {
+		if r.Method == http.MethodGet {
+			gets++
+		}
+	}
+	assert.InDelta(t, 750, gets, 50)
+
+	assert.Empty(t, Generate(nil, 10, 1))
+}
+
+func TestRun(t *testing.T) {
+	requests := []Request{
+		{Method: http.MethodGet, Path: "/users/1"},
+		{Method: http.MethodGet, Path: "/users/2"},
+		{Method: http.MethodPost, Path: "/users", Body: []byte(`{"name":"Jon"}`)},
+		{Method: http.MethodGet, Path: "/fail"},
+		{Method: http.MethodGet, Path: "/missing"},
+	}
+	report, err := Run(newTestEcho(), Config{Requests: requests, Concurrency: 4, Iterations: 10, Warmup: 2})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 50, report.Total)
+	assert.Equal(t, 10, report.Errors)
+	assert.Len(t, report.Routes, 4)
+
+	users := report.Route(http.MethodGet, "/users/:id")
+	if assert.NotNil(t, users) {
+		assert.Equal(t, 20, users.Count)
+		assert.Equal(t, 0, users.Errors)
+		assert.True(t, users.Min <= users.P50 && users.P50 <= users.P99 && users.P99 <= users.Max)
+	}
+	assert.Equal(t, 10, report.Route(http.MethodPost, "/users").Count)
+	assert.Equal(t, 10, report.Route(http.MethodGet, "/fail").Errors)
+	assert.Equal(t, 10, report.Route(http.MethodGet, "/missing").Count)
+	assert.Contains(t, report.String(), "GET      /users/:id")
+
+	_, err = Run(newTestEcho(), Config{})
+	assert.Equal(t, ErrNoRequests, err)
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(latencies, 50))
+	assert.Equal(t, 95*time.Millisecond, percentile(latencies, 95))
+	assert.Equal(t, 99*time.Millisecond, percentile(latencies, 99))
+	assert.Equal(t, time.Millisecond, percentile(latencies[:1], 99))
+}
+
+func TestReport_Regressions(t *testing.T) {
+	baseline := &Report{Routes: []RouteStats{
+		{Method: http.MethodGet, Path: "/users/:id", P95: 10 * time.Millisecond},
+		{Method: http.MethodPost, Path: "/users", P95: 10 * time.Millisecond},
+	}}
+	report := &Report{Routes: []RouteStats{
+		{Method: http.MethodGet, Path: "/users/:id", P95: 11 * time.Millisecond},
+		{Method: http.MethodPost, Path: "/users", P95: 15 * time.Millisecond},
+		{Method: http.MethodGet, Path: "/new", P95: 15 * time.Millisecond},
+	}}
+	assert.Equal(t, []string{"POST /users: p95=15ms, baseline p95=10ms (+50%)"}, report.Regressions(baseline, 0.2))
+	assert.Empty(t, report.Regressions(nil, 0.2))
+
+	dir, err := ioutil.TempDir("", "loadtest")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "baseline.json")
+	assert.NoError(t, WriteReport(filename, baseline))
+	read, err := ReadReport(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, baseline, read)
+}
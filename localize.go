@@ -0,0 +1,97 @@
+package echo
+
+import "strings"
+
+type (
+	// Localizer translates messages of field errors (`BindFieldError`) by their codes.
+	Localizer interface {
+		// Languages returns language tags of available translations, the first one is used when none matches
+		// `Accept-Language` of the request.
+		Languages() []string
+
+		// Localize returns message of the field error in the language or false when there is no translation.
+		Localize(lang string, fe *BindFieldError) (string, bool)
+	}
+
+	// MessageCatalog is `Localizer` with message templates by language tag and error code. Placeholders `{field}`
+	// and `{value}` in templates are replaced with field name and value of the error.
+	//
+	// Example:
+	//
+	//	e.Localizer = echo.NewMessageCatalog().
+	//		Add("en", map[string]string{
+	//			echo.FieldErrorInvalidValue: "{field} has invalid value",
+	//			"required":                   "{field} is required",
+	//		}).
+	//		Add("de", map[string]string{
+	//			echo.FieldErrorInvalidValue: "{field} hat einen ungültigen Wert",
+	//			"required":                   "{field} ist erforderlich",
+	//		})
+	MessageCatalog struct {
+		languages []string
+		messages  map[string]map[string]string
+	}
+)
+
+// NewMessageCatalog creates empty message catalog.
+func NewMessageCatalog() *MessageCatalog {
+	return &MessageCatalog{messages: map[string]map[string]string{}}
+}
+
+// Add adds message templates by error code for the language. The first added language is the default.
+func (m *MessageCatalog) Add(lang string, messages map[string]string) *MessageCatalog {
+	existing, ok := m.messages[lang]
+	if !ok {
+		m.languages = append(m.languages, lang)
+		existing = map[string]string{}
+		m.messages[lang] = existing
+	}
+	for code, msg := range messages {
+		existing[code] = msg
+	}
+	return m
+}
+
+func (m *MessageCatalog) Languages() []string {
+	return m.languages
+}
+
+func (m *MessageCatalog) Localize(lang string, fe *BindFieldError) (string, bool) {
+	msg, ok := m.messages[lang][fe.Code]
+	if !ok {
+		return "", false
+	}
+	return strings.NewReplacer("{field}", fe.Field, "{value}", fe.Value).Replace(msg), true
+}
+
+// localize translates messages of field errors in 422 error returned by binder or validator to language of the
+// request and sets `Content-Language` response header. Other errors are returned as is.
+func (c *context) localize(err error) error {
+	l := c.echo.Localizer
+	if l == nil || err == nil {
+		return err
+	}
+	he, ok := err.(*HTTPError)
+	if !ok {
+		return err
+	}
+	be, ok := he.Internal.(BindErrors)
+	if !ok {
+		return err
+	}
+	languages := l.Languages()
+	if len(languages) == 0 {
+		return err
+	}
+	lang := NegotiateLanguage(c, languages...)
+	if lang == "" {
+		lang = languages[0]
+	}
+	for _, fe := range be {
+		if msg, ok := l.Localize(lang, fe); ok {
+			fe.Message = msg
+		}
+	}
+	c.response.Header().Set(HeaderContentLanguage, lang)
+	return err
+}
@@ -0,0 +1,130 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type localizeTestValidator struct{}
+
+func (localizeTestValidator) Validate(i interface{}) error {
+	return BindErrors{
+		{Source: "json", Field: "name", Code: "required", Message: "name is required"},
+		{Source: "json", Field: "age", Code: "min", Message: "age must be at least 18"},
+	}
+}
+
+func newLocalizeTestCatalog() *MessageCatalog {
+	return NewMessageCatalog().
+		Add("en", map[string]string{
+			FieldErrorInvalidValue: "{field} has invalid value {value}",
+			"required":             "{field} is required",
+		}).
+		Add("de", map[string]string{
+			FieldErrorInvalidValue: "{field} hat ungültigen Wert {value}",
+			"required":             "{field} ist erforderlich",
+		})
+}
+
+func TestContext_BindLocalized(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		expectLang     string
+		expectMessage  string
+	}{
+		{
+			name:           "negotiated language",
+			acceptLanguage: "de-DE, en;q=0.5",
+			expectLang:     "de",
+			expectMessage:  "count hat ungültigen Wert x",
+		},
+		{
+			name:          "default language",
+			expectLang:    "en",
+			expectMessage: "count has invalid value x",
+		},
+		{
+			name:           "unknown language",
+			acceptLanguage: "fr",
+			expectLang:     "en",
+			expectMessage:  "count has invalid value x",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			e.Binder = &DefaultBinder{Strict: true}
+			e.Localizer = newLocalizeTestCatalog()
+			req := httptest.NewRequest(http.MethodGet, "/?count=x", nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set(HeaderAcceptLanguage, tc.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var target struct {
+				Count int `query:"count"`
+			}
+			err := c.Bind(&target)
+			he, ok := err.(*HTTPError)
+			if assert.True(t, ok) {
+				assert.Equal(t, http.StatusUnprocessableEntity, he.Code)
+				be := he.Internal.(BindErrors)
+				assert.Equal(t, FieldErrorInvalidValue, be[0].Code)
+				assert.Equal(t, tc.expectMessage, be[0].Message)
+			}
+			assert.Equal(t, tc.expectLang, rec.Header().Get(HeaderContentLanguage))
+		})
+	}
+}
+
+func TestContext_ValidateLocalized(t *testing.T) {
+	e := New()
+	e.Validator = localizeTestValidator{}
+	e.Localizer = newLocalizeTestCatalog()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(HeaderAcceptLanguage, "de")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := c.Validate(nil)
+	e.HTTPErrorHandler(err, c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "de", rec.Header().Get(HeaderContentLanguage))
+	assert.JSONEq(t, `{"message":"Unprocessable Entity","errors":[
+		{"source":"json","field":"name","code":"required","message":"name ist erforderlich"},
+		{"source":"json","field":"age","code":"min","message":"age must be at least 18"}
+	]}`, rec.Body.String())
+}
+
+func TestContext_ValidateWithoutLocalizer(t *testing.T) {
+	e := New()
+	e.Validator = localizeTestValidator{}
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+
+	err := c.Validate(nil)
+	he, ok := err.(*HTTPError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusUnprocessableEntity, he.Code)
+		assert.Equal(t, "name is required", he.Internal.(BindErrors)[0].Message)
+	}
+}
+
+func TestMessageCatalog(t *testing.T) {
+	m := NewMessageCatalog().
+		Add("en", map[string]string{"required": "{field} is required"}).
+		Add("en", map[string]string{"min": "{field} is too small"})
+	assert.Equal(t, []string{"en"}, m.Languages())
+
+	msg, ok := m.Localize("en", &BindFieldError{Field: "age", Code: "min"})
+	assert.True(t, ok)
+	assert.Equal(t, "age is too small", msg)
+
+	_, ok = m.Localize("de", &BindFieldError{Field: "age", Code: "min"})
+	assert.False(t, ok)
+}
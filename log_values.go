@@ -0,0 +1,83 @@
+package echo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LogRedactFunc returns value of context key as it should appear in logs.
+type LogRedactFunc func(value interface{}) interface{}
+
+// RegisterLogKey adds context key (set with `Context#Set()`) to allowlist of keys whose values are included in
+// access logs and error reports (`middleware.Logger` tag `context:<key>`, `middleware.RequestLogger` with
+// `LogContextValues`, panics logged by `middleware.Recover`). Values are passed through redact function first, nil
+// function redacts values with `Echo#Redactor`. Keys must be registered before the server is started.
+//
+// Example:
+//
+//	e.RegisterLogKey("tenant", nil)
+//	e.RegisterLogKey("card_number", echo.RedactMask(4))
+func (e *Echo) RegisterLogKey(key string, redact LogRedactFunc) {
+	if e.logKeys == nil {
+		e.logKeys = map[string]LogRedactFunc{}
+	}
+	e.logKeys[key] = redact
+}
+
+// LogValue returns redacted value of registered log key from the context. False is returned when key is not
+// registered or value is not set.
+func LogValue(c Context, key string) (interface{}, bool) {
+	redact, ok := c.Echo().logKeys[key]
+	if !ok {
+		return nil, false
+	}
+	value := c.Get(key)
+	if value == nil {
+		return nil, false
+	}
+	return redactLogValue(c, key, value, redact), true
+}
+
+// LogValues returns redacted values of all registered log keys set in the context or nil when none is set.
+func LogValues(c Context) map[string]interface{} {
+	var values map[string]interface{}
+	for key, redact := range c.Echo().logKeys {
+		value := c.Get(key)
+		if value == nil {
+			continue
+		}
+		if values == nil {
+			values = map[string]interface{}{}
+		}
+		values[key] = redactLogValue(c, key, value, redact)
+	}
+	return values
+}
+
+func redactLogValue(c Context, key string, value interface{}, redact LogRedactFunc) interface{} {
+	if redact != nil {
+		return redact(value)
+	}
+	r := c.Echo().Redactor
+	if r.Sensitive(key) {
+		return r.replacement()
+	}
+	return r.Value(value)
+}
+
+// RedactAll replaces any value with "[REDACTED]", logs then show that value was set without revealing it.
+func RedactAll(value interface{}) interface{} {
+	return "[REDACTED]"
+}
+
+// RedactMask returns redact function masking all but last visible characters of value formatted as string, i.e.
+// "************1234".
+func RedactMask(visible int) LogRedactFunc {
+	return func(value interface{}) interface{} {
+		s := []rune(fmt.Sprint(value))
+		if len(s) <= visible {
+			return strings.Repeat("*", len(s))
+		}
+		return strings.Repeat("*", len(s)-visible) + string(s[len(s)-visible:])
+	}
+}
@@ -0,0 +1,69 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogValues(t *testing.T) {
+	e := New()
+	e.RegisterLogKey("tenant", nil)
+	e.RegisterLogKey("card", RedactMask(4))
+	e.RegisterLogKey("token", RedactAll)
+	e.RegisterLogKey("order_id", nil)
+
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Nil(t, LogValues(c))
+
+	c.Set("tenant", "acme")
+	c.Set("card", "4111111111111111")
+	c.Set("token", "secret")
+	c.Set("password", "not registered")
+
+	assert.Equal(t, map[string]interface{}{
+		"tenant": "acme",
+		"card":   "************1111",
+		"token":  "[REDACTED]",
+	}, LogValues(c))
+
+	value, ok := LogValue(c, "card")
+	assert.True(t, ok)
+	assert.Equal(t, "************1111", value)
+
+	_, ok = LogValue(c, "order_id")
+	assert.False(t, ok, "registered key without value")
+	_, ok = LogValue(c, "password")
+	assert.False(t, ok, "value of key not registered")
+}
+
+func TestLogValues_redactor(t *testing.T) {
+	e := New()
+	e.RegisterLogKey("customer_email", nil)
+	e.RegisterLogKey("note", nil)
+	e.RegisterLogKey("user", RedactMask(2))
+
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	c.Set("customer_email", "jon")
+	c.Set("note", "call jon@example.com")
+	c.Set("user", "jon@example.com")
+
+	assert.Equal(t, map[string]interface{}{
+		"customer_email": "[REDACTED]",
+		"note":           "call [REDACTED]",
+		"user":           "*************om",
+	}, LogValues(c))
+
+	e.Redactor = nil
+	value, _ := LogValue(c, "note")
+	assert.Equal(t, "call jon@example.com", value)
+}
+
+func TestRedactMask(t *testing.T) {
+	assert.Equal(t, "*****6789", RedactMask(4)(123456789))
+	assert.Equal(t, "***", RedactMask(4)("abc"))
+	assert.Equal(t, "**", RedactMask(0)("ab"))
+	assert.Equal(t, "**ü", RedactMask(1)("äöü"))
+}
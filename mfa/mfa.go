@@ -0,0 +1,151 @@
+/*
+Package mfa provides second-factor authentication helpers: TOTP (RFC 6238) enrollment and verification handlers and
+WebAuthn registration and assertion ceremonies.
+
+Both factors keep short lived state (pending TOTP secret, WebAuthn challenge) between two requests of a ceremony in
+a `ChallengeStore`. `MemoryChallengeStore` is suitable for single instance deployments, `SessionChallengeStore` keeps state in the
+user session and should be used when requests can reach different instances.
+
+Example with gorilla/sessions based session middleware:
+
+	store := &mfa.SessionChallengeStore{
+		Get: func(c echo.Context, key string) (string, error) {
+			sess, err := session.Get("session", c)
+			if err != nil {
+				return "", err
+			}
+			v, _ := sess.Values[key].(string)
+			return v, nil
+		},
+		Set: func(c echo.Context, key, value string) error {
+			sess, err := session.Get("session", c)
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				delete(sess.Values, key)
+			} else {
+				sess.Values[key] = value
+			}
+			return sess.Save(c.Request(), c.Response())
+		},
+	}
+	totp := mfa.NewTOTP(mfa.TOTPConfig{Issuer: "Example", Challenges: store})
+*/
+package mfa
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// ChallengeStore keeps values needed to finish two step ceremonies (registration, login, enrollment).
+	ChallengeStore interface {
+		// Put stores value under key for ttl.
+		Put(c echo.Context, key string, value []byte, ttl time.Duration) error
+		// Take removes value stored under key and returns it. It returns ErrChallengeNotFound when value does not
+		// exist or expired.
+		Take(c echo.Context, key string) ([]byte, error)
+	}
+
+	// MemoryChallengeStore is in-memory ChallengeStore.
+	MemoryChallengeStore struct {
+		mutex  sync.Mutex
+		values map[string]challenge
+	}
+
+	// SessionChallengeStore is ChallengeStore keeping values in the user session. Values are stored as strings
+	// with their expiration so any session implementation can be used.
+	SessionChallengeStore struct {
+		// Get returns value stored in session under key or empty string when there is none.
+		// Required.
+		Get func(c echo.Context, key string) (string, error)
+
+		// Set stores value in session under key. Empty value means that key must be removed from session.
+		// Required.
+		Set func(c echo.Context, key, value string) error
+	}
+
+	challenge struct {
+		value     []byte
+		expiresAt time.Time
+	}
+)
+
+// ErrChallengeNotFound is returned by ChallengeStore when challenge does not exist or expired.
+var ErrChallengeNotFound = errors.New("mfa: challenge not found")
+
+// NewMemoryChallengeStore creates new in-memory challenge store.
+func NewMemoryChallengeStore() *MemoryChallengeStore {
+	return &MemoryChallengeStore{values: map[string]challenge{}}
+}
+
+// Put stores value under key for ttl. Expired values are removed from store.
+func (s *MemoryChallengeStore) Put(c echo.Context, key string, value []byte, ttl time.Duration) error {
+	now := echo.Now(c)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for k, v := range s.values {
+		if !now.Before(v.expiresAt) {
+			delete(s.values, k)
+		}
+	}
+	s.values[key] = challenge{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Take removes value stored under key and returns it.
+func (s *MemoryChallengeStore) Take(c echo.Context, key string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	v, ok := s.values[key]
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+	delete(s.values, key)
+	if !echo.Now(c).Before(v.expiresAt) {
+		return nil, ErrChallengeNotFound
+	}
+	return v.value, nil
+}
+
+// Put stores value under key in session for ttl.
+func (s *SessionChallengeStore) Put(c echo.Context, key string, value []byte, ttl time.Duration) error {
+	expiresAt := echo.Now(c).Add(ttl).Unix()
+	return s.Set(c, key, strconv.FormatInt(expiresAt, 10)+":"+base64.RawURLEncoding.EncodeToString(value))
+}
+
+// Take removes value stored under key from session and returns it.
+func (s *SessionChallengeStore) Take(c echo.Context, key string) ([]byte, error) {
+	stored, err := s.Get(c, key)
+	if err != nil {
+		return nil, err
+	}
+	if stored == "" {
+		return nil, ErrChallengeNotFound
+	}
+	if err := s.Set(c, key, ""); err != nil {
+		return nil, err
+	}
+
+	i := strings.IndexByte(stored, ':')
+	if i < 0 {
+		return nil, ErrChallengeNotFound
+	}
+	expiresAt, err := strconv.ParseInt(stored[:i], 10, 64)
+	if err != nil || echo.Now(c).Unix() >= expiresAt {
+		return nil, ErrChallengeNotFound
+	}
+	value, err := base64.RawURLEncoding.DecodeString(stored[i+1:])
+	if err != nil {
+		return nil, ErrChallengeNotFound
+	}
+	return value, nil
+}
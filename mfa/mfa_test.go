@@ -0,0 +1,59 @@
+package mfa
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChallengeStores(t *testing.T) {
+	session := map[string]string{}
+	stores := map[string]ChallengeStore{
+		"memory": NewMemoryChallengeStore(),
+		"session": &SessionChallengeStore{
+			Get: func(c echo.Context, key string) (string, error) {
+				return session[key], nil
+			},
+			Set: func(c echo.Context, key, value string) error {
+				if value == "" {
+					delete(session, key)
+				} else {
+					session[key] = value
+				}
+				return nil
+			},
+		},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+			e := echo.New()
+			e.Clock = clock
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+			_, err := store.Take(c, "key")
+			assert.Equal(t, ErrChallengeNotFound, err)
+
+			require.NoError(t, store.Put(c, "key", []byte("value"), time.Minute))
+			v, err := store.Take(c, "key")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("value"), v)
+
+			_, err = store.Take(c, "key")
+			assert.Equal(t, ErrChallengeNotFound, err, "value can be taken only once")
+
+			require.NoError(t, store.Put(c, "key", []byte("value"), time.Minute))
+			clock.Advance(time.Minute)
+			_, err = store.Take(c, "key")
+			assert.Equal(t, ErrChallengeNotFound, err, "expired value")
+		})
+	}
+	assert.Empty(t, session)
+}
@@ -0,0 +1,472 @@
+package mfa
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// TOTPConfig defines the config for TOTP.
+	TOTPConfig struct {
+		// Issuer is name of the service shown by authenticator apps.
+		// Required.
+		Issuer string
+
+		// Digits is number of digits of the code. Most authenticator apps support only 6.
+		// Optional. Default value 6.
+		Digits int
+
+		// Period is how long one code is valid, it must be whole number of seconds.
+		// Optional. Default value 30 seconds.
+		Period time.Duration
+
+		// Skew is number of periods before and after current one which codes are also accepted to tolerate clock
+		// differences and delays in entering the code.
+		// Optional. Default value 1.
+		Skew int
+
+		// Algorithm is HMAC hash function: "SHA1", "SHA256" or "SHA512". Most authenticator apps support only SHA1.
+		// Optional. Default value "SHA1".
+		Algorithm string
+
+		// EnrollmentTTL is how long pending secret of started enrollment is valid.
+		// Optional. Default value 10 minutes.
+		EnrollmentTTL time.Duration
+
+		// Challenges stores pending secrets of started enrollments.
+		// Optional. Default value NewMemoryChallengeStore().
+		Challenges ChallengeStore
+
+		// MaxAttempts is number of codes account can enter within AttemptWindow without any of them being
+		// accepted. Further codes are rejected with ErrTooManyAttempts so 6 digit codes can not be brute-forced.
+		// Optional. Default value 5.
+		MaxAttempts int
+
+		// AttemptWindow is length of the sliding window attempts are counted in.
+		// Optional. Default value 15 minutes.
+		AttemptWindow time.Duration
+
+		// Attempts stores attempts and last accepted code of accounts.
+		// Optional. Default value NewMemoryAttemptStore().
+		Attempts AttemptStore
+	}
+
+	// AttemptStore keeps state of code verification of accounts: attempts to enter a code and counter (time step)
+	// of the last accepted code, so accepted codes can not be replayed (RFC 6238 section 5.2).
+	AttemptStore interface {
+		// Attempt records attempt of account when it made fewer than max attempts within window since its last
+		// accepted code and reports whether the attempt was recorded.
+		Attempt(c echo.Context, account string, max int, window time.Duration) (bool, error)
+		// Accept records counter of accepted code of account and resets its attempts. It reports false when
+		// counter is not greater than counter of the last accepted code.
+		Accept(c echo.Context, account string, counter int64) (bool, error)
+	}
+
+	// MemoryAttemptStore is in-memory AttemptStore.
+	MemoryAttemptStore struct {
+		mutex       sync.Mutex
+		accounts    map[string]*accountAttempts
+		lastCleanup time.Time
+	}
+
+	accountAttempts struct {
+		attempts   []time.Time
+		counter    int64
+		acceptedAt time.Time
+	}
+
+	// TOTP generates and verifies time-based one-time passwords.
+	TOTP struct {
+		config TOTPConfig
+		hash   func() hash.Hash
+	}
+
+	// TOTPHandlerConfig defines callbacks connecting TOTP handlers to the application.
+	TOTPHandlerConfig struct {
+		// Account returns account name (i.e. email) of the authenticated user enrolling or verifying second factor.
+		// Required.
+		Account func(c echo.Context) (string, error)
+
+		// SaveSecret persists secret of confirmed enrollment for account.
+		// Required by ConfirmHandler.
+		SaveSecret func(c echo.Context, account, secret string) error
+
+		// LoadSecret returns secret of account.
+		// Required by VerifyHandler.
+		LoadSecret func(c echo.Context, account string) (string, error)
+
+		// Success sends response after successful confirmation or verification.
+		// Optional. Default value responds with 204 No Content.
+		Success func(c echo.Context, account string) error
+
+		// CodeField is name of form field with the code.
+		// Optional. Default value "code".
+		CodeField string
+	}
+
+	// TOTPEnrollment is response of enrollment handler.
+	TOTPEnrollment struct {
+		// Secret is base32 secret user can type into authenticator app.
+		Secret string `json:"secret"`
+		// URI is `otpauth://` URI usually shown as QR code.
+		URI string `json:"uri"`
+	}
+)
+
+// Errors
+var (
+	// ErrInvalidCode is returned when TOTP code is not valid or was already used.
+	ErrInvalidCode = echo.NewHTTPError(http.StatusUnauthorized, "invalid code")
+	// ErrTooManyAttempts is returned when account entered too many codes without success.
+	ErrTooManyAttempts = echo.NewHTTPError(http.StatusTooManyRequests, "too many code attempts")
+)
+
+// DefaultTOTPConfig is the default TOTP config.
+var DefaultTOTPConfig = TOTPConfig{
+	Digits:        6,
+	Period:        30 * time.Second,
+	Skew:          1,
+	Algorithm:     "SHA1",
+	EnrollmentTTL: 10 * time.Minute,
+	MaxAttempts:   5,
+	AttemptWindow: 15 * time.Minute,
+}
+
+// NewTOTP creates new TOTP with config.
+func NewTOTP(config TOTPConfig) *TOTP {
+	if config.Issuer == "" {
+		panic("echo: totp requires issuer")
+	}
+	// Defaults
+	if config.Digits <= 0 {
+		config.Digits = DefaultTOTPConfig.Digits
+	}
+	if config.Period == 0 {
+		config.Period = DefaultTOTPConfig.Period
+	}
+	if config.Period < time.Second || config.Period%time.Second != 0 {
+		panic(fmt.Sprintf("echo: invalid totp period=%s", config.Period))
+	}
+	if config.Skew < 0 {
+		config.Skew = 0
+	} else if config.Skew == 0 {
+		config.Skew = DefaultTOTPConfig.Skew
+	}
+	if config.Algorithm == "" {
+		config.Algorithm = DefaultTOTPConfig.Algorithm
+	}
+	if config.EnrollmentTTL <= 0 {
+		config.EnrollmentTTL = DefaultTOTPConfig.EnrollmentTTL
+	}
+	if config.Challenges == nil {
+		config.Challenges = NewMemoryChallengeStore()
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultTOTPConfig.MaxAttempts
+	}
+	if config.AttemptWindow <= 0 {
+		config.AttemptWindow = DefaultTOTPConfig.AttemptWindow
+	}
+	// accepted codes are remembered at least as long as they are valid
+	if valid := time.Duration(2*config.Skew+1) * config.Period; config.AttemptWindow < valid {
+		config.AttemptWindow = valid
+	}
+	if config.Attempts == nil {
+		config.Attempts = NewMemoryAttemptStore()
+	}
+
+	t := &TOTP{config: config}
+	switch strings.ToUpper(config.Algorithm) {
+	case "SHA1":
+		t.hash = sha1.New
+	case "SHA256":
+		t.hash = sha256.New
+	case "SHA512":
+		t.hash = sha512.New
+	default:
+		panic(fmt.Sprintf("echo: unsupported totp algorithm %q", config.Algorithm))
+	}
+	return t
+}
+
+// GenerateSecret returns new random base32 encoded secret.
+func (t *TOTP) GenerateSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// URI returns `otpauth://` key URI of secret for account.
+func (t *TOTP) URI(account, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", t.config.Issuer)
+	q.Set("algorithm", strings.ToUpper(t.config.Algorithm))
+	q.Set("digits", strconv.Itoa(t.config.Digits))
+	q.Set("period", strconv.Itoa(int(t.config.Period/time.Second)))
+	label := url.PathEscape(t.config.Issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// Code returns code of secret valid at time at.
+func (t *TOTP) Code(secret string, at time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return t.code(key, t.counter(at)), nil
+}
+
+// Validate checks code of secret at time at. Codes of `Skew` periods around at are accepted. Validate neither
+// limits attempts nor prevents replay of accepted codes, use `TOTP#Verify()` to check codes entered by users.
+func (t *TOTP) Validate(secret, code string, at time.Time) bool {
+	_, ok := t.validate(secret, code, at)
+	return ok
+}
+
+// Verify checks code entered by account. It returns ErrTooManyAttempts when account made too many attempts
+// without success and ErrInvalidCode when code is not valid or code of the same or later period was already
+// accepted for account.
+func (t *TOTP) Verify(c echo.Context, account, secret, code string) error {
+	ok, err := t.config.Attempts.Attempt(c, account, t.config.MaxAttempts, t.config.AttemptWindow)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTooManyAttempts
+	}
+	counter, ok := t.validate(secret, code, echo.Now(c))
+	if !ok {
+		return ErrInvalidCode
+	}
+	if ok, err = t.config.Attempts.Accept(c, account, counter); err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// validate returns counter of period the code is valid in.
+func (t *TOTP) validate(secret, code string, at time.Time) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(code) != t.config.Digits {
+		return 0, false
+	}
+	counter := t.counter(at)
+	matched, valid := int64(0), false
+	for i := -int64(t.config.Skew); i <= int64(t.config.Skew); i++ {
+		if subtle.ConstantTimeCompare([]byte(t.code(key, counter+i)), []byte(code)) == 1 && !valid {
+			matched, valid = counter+i, true
+		}
+	}
+	return matched, valid
+}
+
+func (t *TOTP) counter(at time.Time) int64 {
+	return at.Unix() / int64(t.config.Period/time.Second)
+}
+
+// code computes HOTP value (RFC 4226) for counter.
+func (t *TOTP) code(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+	mac := hmac.New(t.hash, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := int64(binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff)
+
+	mod := int64(1)
+	for i := 0; i < t.config.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", t.config.Digits, value%mod)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimRight(secret, "="), " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, errors.New("mfa: invalid totp secret")
+	}
+	return key, nil
+}
+
+// EnrollHandler returns handler starting enrollment of authenticated user. It generates new secret, keeps it as
+// pending until enrollment is confirmed and responds with `TOTPEnrollment` JSON.
+func (t *TOTP) EnrollHandler(config TOTPHandlerConfig) echo.HandlerFunc {
+	config = t.handlerDefaults(config)
+	return func(c echo.Context) error {
+		account, err := config.Account(c)
+		if err != nil {
+			return err
+		}
+		secret, err := t.GenerateSecret()
+		if err != nil {
+			return err
+		}
+		if err := t.config.Challenges.Put(c, enrollmentKey(account), []byte(secret), t.config.EnrollmentTTL); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, TOTPEnrollment{Secret: secret, URI: t.URI(account, secret)})
+	}
+}
+
+// ConfirmHandler returns handler finishing enrollment. Code from authenticator app proves that user stored the
+// pending secret, which is then persisted with `SaveSecret`. Pending secret is kept when the code is not valid so
+// user can retry, attempts are limited as for `TOTP#Verify()`.
+func (t *TOTP) ConfirmHandler(config TOTPHandlerConfig) echo.HandlerFunc {
+	config = t.handlerDefaults(config)
+	if config.SaveSecret == nil {
+		panic("echo: totp confirm handler requires save secret function")
+	}
+	return func(c echo.Context) error {
+		account, err := config.Account(c)
+		if err != nil {
+			return err
+		}
+		secret, err := t.config.Challenges.Take(c, enrollmentKey(account))
+		if errors.Is(err, ErrChallengeNotFound) {
+			return echo.NewHTTPError(http.StatusBadRequest, "enrollment not started or expired")
+		}
+		if err != nil {
+			return err
+		}
+		if err := t.Verify(c, account, string(secret), c.FormValue(config.CodeField)); err != nil {
+			// secret is put back for the next attempt, its lifetime starts again
+			if pErr := t.config.Challenges.Put(c, enrollmentKey(account), secret, t.config.EnrollmentTTL); pErr != nil {
+				return pErr
+			}
+			return err
+		}
+		if err := config.SaveSecret(c, account, string(secret)); err != nil {
+			return err
+		}
+		return config.Success(c, account)
+	}
+}
+
+// VerifyHandler returns handler verifying code of authenticated user against secret returned by `LoadSecret`.
+// See: `TOTP#Verify()`.
+func (t *TOTP) VerifyHandler(config TOTPHandlerConfig) echo.HandlerFunc {
+	config = t.handlerDefaults(config)
+	if config.LoadSecret == nil {
+		panic("echo: totp verify handler requires load secret function")
+	}
+	return func(c echo.Context) error {
+		account, err := config.Account(c)
+		if err != nil {
+			return err
+		}
+		secret, err := config.LoadSecret(c, account)
+		if err != nil {
+			return err
+		}
+		if err := t.Verify(c, account, secret, c.FormValue(config.CodeField)); err != nil {
+			return err
+		}
+		return config.Success(c, account)
+	}
+}
+
+func (t *TOTP) handlerDefaults(config TOTPHandlerConfig) TOTPHandlerConfig {
+	if config.Account == nil {
+		panic("echo: totp handler requires account function")
+	}
+	if config.Success == nil {
+		config.Success = func(c echo.Context, account string) error {
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+	if config.CodeField == "" {
+		config.CodeField = "code"
+	}
+	return config
+}
+
+func enrollmentKey(account string) string {
+	return "totp-enrollment:" + account
+}
+
+// NewMemoryAttemptStore creates new in-memory attempt store.
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{accounts: map[string]*accountAttempts{}}
+}
+
+// Attempt records attempt of account when it made fewer than max attempts within window. Accounts without recent
+// attempts and accepted codes are removed from store once per window.
+func (s *MemoryAttemptStore) Attempt(c echo.Context, account string, max int, window time.Duration) (bool, error) {
+	now := echo.Now(c)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// clock moving backwards (e.g. fake `Echo#Clock` set to the past) also resets cleanup time
+	if now.Sub(s.lastCleanup) > window || now.Before(s.lastCleanup) {
+		for k, a := range s.accounts {
+			if a.count(now, window) == 0 && now.Sub(a.acceptedAt) > window {
+				delete(s.accounts, k)
+			}
+		}
+		s.lastCleanup = now
+	}
+	a, ok := s.accounts[account]
+	if !ok {
+		a = &accountAttempts{}
+		s.accounts[account] = a
+	}
+	if a.count(now, window) >= max {
+		return false, nil
+	}
+	a.attempts = append(a.attempts, now)
+	return true, nil
+}
+
+// Accept records counter of accepted code of account and resets its attempts.
+func (s *MemoryAttemptStore) Accept(c echo.Context, account string, counter int64) (bool, error) {
+	now := echo.Now(c)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	a, ok := s.accounts[account]
+	if !ok {
+		a = &accountAttempts{}
+		s.accounts[account] = a
+	} else if !a.acceptedAt.IsZero() && counter <= a.counter {
+		return false, nil
+	}
+	a.counter = counter
+	a.acceptedAt = now
+	a.attempts = a.attempts[:0]
+	return true, nil
+}
+
+// count removes attempts older than window and returns number of remaining ones.
+func (a *accountAttempts) count(now time.Time, window time.Duration) int {
+	i := 0
+	for i < len(a.attempts) && now.Sub(a.attempts[i]) >= window {
+		i++
+	}
+	a.attempts = a.attempts[i:]
+	return len(a.attempts)
+}
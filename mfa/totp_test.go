@@ -0,0 +1,195 @@
+package mfa
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP_Code(t *testing.T) {
+	// test vectors of RFC 6238 appendix B
+	var testCases = []struct {
+		algorithm string
+		key       string
+		unix      int64
+		expect    string
+	}{
+		{algorithm: "SHA1", key: "12345678901234567890", unix: 59, expect: "94287082"},
+		{algorithm: "SHA1", key: "12345678901234567890", unix: 1111111109, expect: "07081804"},
+		{algorithm: "SHA1", key: "12345678901234567890", unix: 20000000000, expect: "65353130"},
+		{algorithm: "SHA256", key: "12345678901234567890123456789012", unix: 59, expect: "46119246"},
+		{algorithm: "SHA512", key: "1234567890123456789012345678901234567890123456789012345678901234", unix: 59, expect: "90693936"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.algorithm+"/"+tc.expect, func(t *testing.T) {
+			totp := NewTOTP(TOTPConfig{Issuer: "Echo", Digits: 8, Algorithm: tc.algorithm})
+			secret := base32.StdEncoding.EncodeToString([]byte(tc.key))
+
+			code, err := totp.Code(secret, time.Unix(tc.unix, 0))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, code)
+		})
+	}
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	totp := NewTOTP(TOTPConfig{Issuer: "Echo"})
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	code, err := totp.Code(secret, now)
+	require.NoError(t, err)
+	assert.Len(t, code, 6)
+
+	assert.True(t, totp.Validate(secret, code, now))
+	assert.True(t, totp.Validate(strings.ToLower(secret), code, now.Add(30*time.Second)), "skew")
+	assert.False(t, totp.Validate(secret, code, now.Add(60*time.Second)))
+	assert.False(t, totp.Validate(secret, "12345", now))
+	assert.False(t, totp.Validate("not base32!", code, now))
+}
+
+func TestTOTP_URI(t *testing.T) {
+	totp := NewTOTP(TOTPConfig{Issuer: "Echo App"})
+	u, err := url.Parse(totp.URI("jon@example.com", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Echo App:jon@example.com", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "Echo App", u.Query().Get("issuer"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
+
+func TestTOTP_handlers(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := echo.New()
+	e.Clock = clock
+	totp := NewTOTP(TOTPConfig{Issuer: "Echo"})
+
+	secrets := map[string]string{}
+	config := TOTPHandlerConfig{
+		Account: func(c echo.Context) (string, error) {
+			return c.Request().Header.Get("X-User"), nil
+		},
+		SaveSecret: func(c echo.Context, account, secret string) error {
+			secrets[account] = secret
+			return nil
+		},
+		LoadSecret: func(c echo.Context, account string) (string, error) {
+			s, ok := secrets[account]
+			if !ok {
+				return "", errors.New("not enrolled")
+			}
+			return s, nil
+		},
+	}
+	e.POST("/mfa/totp", totp.EnrollHandler(config))
+	e.POST("/mfa/totp/confirm", totp.ConfirmHandler(config))
+	e.POST("/mfa/totp/verify", totp.VerifyHandler(config))
+
+	request := func(path, code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(url.Values{"code": {code}}.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.Header.Set("X-User", "jon")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusBadRequest, request("/mfa/totp/confirm", "123456").Code)
+
+	rec := request("/mfa/totp", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	enrollment := TOTPEnrollment{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &enrollment))
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	assert.Equal(t, http.StatusUnauthorized, request("/mfa/totp/confirm", "000000").Code)
+	assert.Empty(t, secrets)
+
+	// pending secret is kept after failed confirmation
+	code, err := totp.Code(enrollment.Secret, clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, request("/mfa/totp/confirm", code).Code)
+	assert.Equal(t, enrollment.Secret, secrets["jon"])
+
+	clock.Advance(time.Hour)
+	code, err = totp.Code(enrollment.Secret, clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, request("/mfa/totp/verify", code).Code)
+	assert.Equal(t, http.StatusUnauthorized, request("/mfa/totp/verify", code).Code, "code can not be replayed")
+	assert.Equal(t, http.StatusUnauthorized, request("/mfa/totp/verify", "000000").Code)
+
+	clock.Advance(30 * time.Second)
+	code, err = totp.Code(enrollment.Secret, clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, request("/mfa/totp/verify", code).Code)
+}
+
+func TestTOTP_Verify(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := echo.New()
+	e.Clock = clock
+	c := e.NewContext(nil, nil)
+	totp := NewTOTP(TOTPConfig{Issuer: "Echo", MaxAttempts: 3, AttemptWindow: 10 * time.Minute})
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.Code(secret, clock.Now())
+	require.NoError(t, err)
+	previous, err := totp.Code(secret, clock.Now().Add(-30*time.Second))
+	require.NoError(t, err)
+
+	assert.NoError(t, totp.Verify(c, "jon", secret, code))
+	assert.Equal(t, ErrInvalidCode, totp.Verify(c, "jon", secret, code), "replay")
+	assert.Equal(t, ErrInvalidCode, totp.Verify(c, "jon", secret, previous), "code of earlier period")
+	assert.NoError(t, totp.Verify(c, "ann", secret, code), "accounts are independent")
+
+	assert.Equal(t, ErrInvalidCode, totp.Verify(c, "jon", secret, "000000"))
+	assert.Equal(t, ErrTooManyAttempts, totp.Verify(c, "jon", secret, code), "attempts since last accepted code")
+
+	clock.Advance(10 * time.Minute)
+	code, err = totp.Code(secret, clock.Now())
+	require.NoError(t, err)
+	assert.NoError(t, totp.Verify(c, "jon", secret, code))
+}
+
+func TestMemoryAttemptStore_cleanup(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := echo.New()
+	e.Clock = clock
+	c := e.NewContext(nil, nil)
+	store := NewMemoryAttemptStore()
+
+	ok, err := store.Attempt(c, "jon", 1, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = store.Attempt(c, "jon", 1, time.Minute)
+	assert.False(t, ok)
+	ok, _ = store.Accept(c, "jon", 10)
+	assert.True(t, ok)
+
+	clock.Advance(2 * time.Minute)
+	ok, _ = store.Attempt(c, "ann", 1, time.Minute)
+	assert.True(t, ok)
+	assert.Len(t, store.accounts, 1)
+}
+
+func TestNewTOTP_panics(t *testing.T) {
+	assert.Panics(t, func() { NewTOTP(TOTPConfig{}) })
+	assert.Panics(t, func() { NewTOTP(TOTPConfig{Issuer: "Echo", Algorithm: "MD5"}) })
+	assert.PanicsWithValue(t, "echo: invalid totp period=500ms", func() {
+		NewTOTP(TOTPConfig{Issuer: "Echo", Period: 500 * time.Millisecond})
+	})
+}
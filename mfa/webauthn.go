@@ -0,0 +1,489 @@
+package mfa
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// WebAuthnConfig defines the config for WebAuthn.
+	WebAuthnConfig struct {
+		// RPID is relying party ID, the domain of the site (i.e. "example.com").
+		// Required.
+		RPID string
+
+		// RPName is relying party name shown by authenticators.
+		// Optional. Default value RPID.
+		RPName string
+
+		// Origins are accepted origins of client data (i.e. "https://example.com").
+		// Optional. Default value "https://" + RPID.
+		Origins []string
+
+		// Timeout is time user has to finish the ceremony.
+		// Optional. Default value 2 minutes.
+		Timeout time.Duration
+
+		// UserVerification is requested user verification: "required", "preferred" or "discouraged". When
+		// "required", ceremonies without user verification (PIN, biometrics) are rejected.
+		// Optional. Default value "preferred".
+		UserVerification string
+
+		// Challenges stores challenges of started ceremonies.
+		// Optional. Default value NewMemoryChallengeStore().
+		Challenges ChallengeStore
+	}
+
+	// WebAuthn performs WebAuthn registration and assertion ceremonies. Only "ES256" (ECDSA P-256) credentials
+	// are supported and attestation statements are not verified (credentials are accepted as self attested).
+	WebAuthn struct {
+		config WebAuthnConfig
+	}
+
+	// WebAuthnUser is user registering or using credentials.
+	WebAuthnUser struct {
+		// ID is opaque user handle, must not contain personal information.
+		ID []byte
+		// Name is account name, i.e. email.
+		Name string
+		// DisplayName is user friendly name.
+		DisplayName string
+	}
+
+	// Credential is registered public key credential. Applications persist credentials of user and pass them to
+	// login ceremony.
+	Credential struct {
+		ID        Base64URL `json:"id"`
+		PublicKey Base64URL `json:"public_key"`
+		SignCount uint32    `json:"sign_count"`
+		AAGUID    Base64URL `json:"aaguid"`
+	}
+
+	// Base64URL is byte slice encoded in JSON as base64 URL encoding without padding as used by WebAuthn.
+	Base64URL []byte
+
+	// CredentialCreationOptions is JSON for `navigator.credentials.create({publicKey: options})`. Binary values
+	// are base64 URL encoded and must be decoded to ArrayBuffer by the client.
+	CredentialCreationOptions struct {
+		Challenge              Base64URL              `json:"challenge"`
+		RP                     rpEntity               `json:"rp"`
+		User                   userEntity             `json:"user"`
+		PubKeyCredParams       []credentialParameter  `json:"pubKeyCredParams"`
+		Timeout                int64                  `json:"timeout"`
+		ExcludeCredentials     []credentialDescriptor `json:"excludeCredentials,omitempty"`
+		AuthenticatorSelection authenticatorSelection `json:"authenticatorSelection"`
+		Attestation            string                 `json:"attestation"`
+	}
+
+	// CredentialRequestOptions is JSON for `navigator.credentials.get({publicKey: options})`.
+	CredentialRequestOptions struct {
+		Challenge        Base64URL              `json:"challenge"`
+		RPID             string                 `json:"rpId"`
+		Timeout          int64                  `json:"timeout"`
+		AllowCredentials []credentialDescriptor `json:"allowCredentials"`
+		UserVerification string                 `json:"userVerification"`
+	}
+
+	rpEntity struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	userEntity struct {
+		ID          Base64URL `json:"id"`
+		Name        string    `json:"name"`
+		DisplayName string    `json:"displayName"`
+	}
+
+	credentialParameter struct {
+		Type string `json:"type"`
+		Alg  int    `json:"alg"`
+	}
+
+	credentialDescriptor struct {
+		Type string    `json:"type"`
+		ID   Base64URL `json:"id"`
+	}
+
+	authenticatorSelection struct {
+		UserVerification string `json:"userVerification"`
+	}
+
+	// credentialResponse is PublicKeyCredential sent by the client with binary values base64 URL encoded.
+	credentialResponse struct {
+		RawID    Base64URL `json:"rawId"`
+		Type     string    `json:"type"`
+		Response struct {
+			ClientDataJSON    Base64URL `json:"clientDataJSON"`
+			AttestationObject Base64URL `json:"attestationObject"`
+			AuthenticatorData Base64URL `json:"authenticatorData"`
+			Signature         Base64URL `json:"signature"`
+		} `json:"response"`
+	}
+
+	clientData struct {
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+		Origin    string `json:"origin"`
+	}
+
+	authenticatorData struct {
+		rpIDHash  []byte
+		flags     byte
+		signCount uint32
+		aaguid    []byte
+		credID    []byte
+		publicKey []byte
+	}
+)
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+
+	coseAlgES256 = -7
+)
+
+// DefaultWebAuthnConfig is the default WebAuthn config.
+var DefaultWebAuthnConfig = WebAuthnConfig{
+	Timeout:          2 * time.Minute,
+	UserVerification: "preferred",
+}
+
+// NewWebAuthn creates new WebAuthn with config.
+func NewWebAuthn(config WebAuthnConfig) *WebAuthn {
+	if config.RPID == "" {
+		panic("echo: webauthn requires relying party id")
+	}
+	// Defaults
+	if config.RPName == "" {
+		config.RPName = config.RPID
+	}
+	if len(config.Origins) == 0 {
+		config.Origins = []string{"https://" + config.RPID}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultWebAuthnConfig.Timeout
+	}
+	if config.UserVerification == "" {
+		config.UserVerification = DefaultWebAuthnConfig.UserVerification
+	}
+	if config.Challenges == nil {
+		config.Challenges = NewMemoryChallengeStore()
+	}
+	return &WebAuthn{config: config}
+}
+
+// BeginRegistration starts registration of new credential for user. Existing credentials of user are excluded
+// so the same authenticator is not registered twice. Returned options are sent to the client.
+func (w *WebAuthn) BeginRegistration(c echo.Context, user WebAuthnUser, existing []Credential) (*CredentialCreationOptions, error) {
+	ch, err := w.newChallenge(c, "register", user.ID)
+	if err != nil {
+		return nil, err
+	}
+	displayName := user.DisplayName
+	if displayName == "" {
+		displayName = user.Name
+	}
+	return &CredentialCreationOptions{
+		Challenge:              ch,
+		RP:                     rpEntity{ID: w.config.RPID, Name: w.config.RPName},
+		User:                   userEntity{ID: user.ID, Name: user.Name, DisplayName: displayName},
+		PubKeyCredParams:       []credentialParameter{{Type: "public-key", Alg: coseAlgES256}},
+		Timeout:                int64(w.config.Timeout / time.Millisecond),
+		ExcludeCredentials:     descriptors(existing),
+		AuthenticatorSelection: authenticatorSelection{UserVerification: w.config.UserVerification},
+		Attestation:            "none",
+	}, nil
+}
+
+// FinishRegistration verifies credential created by the client (JSON request body) for user and returns it to be
+// persisted by the application.
+func (w *WebAuthn) FinishRegistration(c echo.Context, user WebAuthnUser) (*Credential, error) {
+	res, err := w.bindResponse(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.verifyClientData(c, res.Response.ClientDataJSON, "webauthn.create", "register", user.ID); err != nil {
+		return nil, err
+	}
+
+	obj, _, err := decodeCBOR(res.Response.AttestationObject)
+	if err != nil {
+		return nil, invalidCredential(err)
+	}
+	m, _ := obj.(map[interface{}]interface{})
+	rawAuthData, _ := m["authData"].([]byte)
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, invalidCredential(err)
+	}
+	if err := w.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttested == 0 || !bytes.Equal(authData.credID, res.RawID) {
+		return nil, invalidCredential(errors.New("missing attested credential"))
+	}
+	if _, err := parseCOSEKey(authData.publicKey); err != nil {
+		return nil, invalidCredential(err)
+	}
+	return &Credential{
+		ID:        authData.credID,
+		PublicKey: authData.publicKey,
+		SignCount: authData.signCount,
+		AAGUID:    authData.aaguid,
+	}, nil
+}
+
+// BeginLogin starts assertion ceremony with credentials of user. Returned options are sent to the client.
+func (w *WebAuthn) BeginLogin(c echo.Context, user WebAuthnUser, credentials []Credential) (*CredentialRequestOptions, error) {
+	if len(credentials) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "no registered credentials")
+	}
+	ch, err := w.newChallenge(c, "login", user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &CredentialRequestOptions{
+		Challenge:        ch,
+		RPID:             w.config.RPID,
+		Timeout:          int64(w.config.Timeout / time.Millisecond),
+		AllowCredentials: descriptors(credentials),
+		UserVerification: w.config.UserVerification,
+	}, nil
+}
+
+// FinishLogin verifies assertion sent by the client (JSON request body) against credentials of user and returns
+// used credential with updated sign count to be persisted by the application.
+func (w *WebAuthn) FinishLogin(c echo.Context, user WebAuthnUser, credentials []Credential) (*Credential, error) {
+	res, err := w.bindResponse(c)
+	if err != nil {
+		return nil, err
+	}
+	var credential *Credential
+	for i := range credentials {
+		if bytes.Equal(credentials[i].ID, res.RawID) {
+			cred := credentials[i]
+			credential = &cred
+			break
+		}
+	}
+	if credential == nil {
+		return nil, invalidCredential(errors.New("unknown credential"))
+	}
+	if err := w.verifyClientData(c, res.Response.ClientDataJSON, "webauthn.get", "login", user.ID); err != nil {
+		return nil, err
+	}
+
+	authData, err := parseAuthenticatorData(res.Response.AuthenticatorData)
+	if err != nil {
+		return nil, invalidCredential(err)
+	}
+	if err := w.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+
+	key, err := parseCOSEKey(credential.PublicKey)
+	if err != nil {
+		return nil, invalidCredential(err)
+	}
+	clientDataHash := sha256.Sum256(res.Response.ClientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), res.Response.AuthenticatorData...), clientDataHash[:]...))
+	if !verifySignature(key, digest[:], res.Response.Signature) {
+		return nil, invalidCredential(errors.New("invalid signature"))
+	}
+
+	// sign count not increasing indicates cloned authenticator, authenticators not supporting counter send 0
+	if (authData.signCount != 0 || credential.SignCount != 0) && authData.signCount <= credential.SignCount {
+		return nil, invalidCredential(errors.New("sign count did not increase"))
+	}
+	credential.SignCount = authData.signCount
+	return credential, nil
+}
+
+func (w *WebAuthn) newChallenge(c echo.Context, ceremony string, userID []byte) (Base64URL, error) {
+	ch := make([]byte, 32)
+	if _, err := rand.Read(ch); err != nil {
+		return nil, err
+	}
+	if err := w.config.Challenges.Put(c, challengeKey(ceremony, userID), ch, w.config.Timeout); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+func (w *WebAuthn) bindResponse(c echo.Context) (*credentialResponse, error) {
+	res := new(credentialResponse)
+	if err := json.NewDecoder(c.Request().Body).Decode(res); err != nil {
+		return nil, invalidCredential(err)
+	}
+	if res.Type != "public-key" || len(res.RawID) == 0 {
+		return nil, invalidCredential(errors.New("invalid credential type"))
+	}
+	return res, nil
+}
+
+func (w *WebAuthn) verifyClientData(c echo.Context, raw []byte, typ, ceremony string, userID []byte) error {
+	expected, err := w.config.Challenges.Take(c, challengeKey(ceremony, userID))
+	if errors.Is(err, ErrChallengeNotFound) {
+		return echo.NewHTTPError(http.StatusBadRequest, "ceremony not started or expired")
+	}
+	if err != nil {
+		return err
+	}
+
+	data := clientData{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return invalidCredential(err)
+	}
+	if data.Type != typ {
+		return invalidCredential(errors.New("invalid client data type"))
+	}
+	challenge, err := decodeBase64URL(data.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(challenge, expected) != 1 {
+		return invalidCredential(errors.New("invalid challenge"))
+	}
+	for _, o := range w.config.Origins {
+		if o == data.Origin {
+			return nil
+		}
+	}
+	return invalidCredential(errors.New("invalid origin"))
+}
+
+func (w *WebAuthn) verifyAuthenticatorData(authData *authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(w.config.RPID))
+	if !bytes.Equal(authData.rpIDHash, rpIDHash[:]) {
+		return invalidCredential(errors.New("invalid relying party id"))
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return invalidCredential(errors.New("user not present"))
+	}
+	if w.config.UserVerification == "required" && authData.flags&flagUserVerified == 0 {
+		return invalidCredential(errors.New("user not verified"))
+	}
+	return nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+	a := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if a.flags&flagAttested == 0 {
+		return a, nil
+	}
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data too short")
+	}
+	a.aaguid = rest[:16]
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLen {
+		return nil, errors.New("credential id too short")
+	}
+	a.credID = rest[:idLen]
+	rest = rest[idLen:]
+	_, n, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, err
+	}
+	a.publicKey = rest[:n]
+	return a, nil
+}
+
+// parseCOSEKey parses COSE_Key (RFC 8152) of ES256 credential.
+func parseCOSEKey(data []byte) (*ecdsa.PublicKey, error) {
+	v, _, err := decodeCBOR(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("invalid public key")
+	}
+	// kty: EC2 (2), alg: ES256 (-7), crv: P-256 (1)
+	if m[int64(1)] != int64(2) || m[int64(3)] != int64(coseAlgES256) || m[int64(-1)] != int64(1) {
+		return nil, errors.New("unsupported public key algorithm")
+	}
+	x, _ := m[int64(-2)].([]byte)
+	y, _ := m[int64(-3)].([]byte)
+	key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if len(x) != 32 || len(y) != 32 || !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("invalid public key")
+	}
+	return key, nil
+}
+
+func descriptors(credentials []Credential) []credentialDescriptor {
+	result := make([]credentialDescriptor, 0, len(credentials))
+	for _, c := range credentials {
+		result = append(result, credentialDescriptor{Type: "public-key", ID: c.ID})
+	}
+	return result
+}
+
+// invalidCredential returns error for invalid credential response with reason as internal error.
+func invalidCredential(reason error) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusBadRequest, "invalid webauthn credential").SetInternal(reason)
+}
+
+func challengeKey(ceremony string, userID []byte) string {
+	return "webauthn-" + ceremony + ":" + base64.RawURLEncoding.EncodeToString(userID)
+}
+
+// MarshalJSON encodes bytes as base64 URL encoding without padding.
+func (b Base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes base64 URL encoded bytes with or without padding.
+func (b *Base64URL) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := decodeBase64URL(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// verifySignature verifies ASN.1 DER encoded ECDSA signature of hash.
+func verifySignature(key *ecdsa.PublicKey, hash, sig []byte) bool {
+	var esig struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(sig, &esig)
+	if err != nil || len(rest) != 0 {
+		return false
+	}
+	return ecdsa.Verify(key, hash, esig.R, esig.S)
+}
@@ -0,0 +1,231 @@
+package mfa
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeCBOR encodes subset of CBOR needed to simulate authenticator in tests.
+func encodeCBOR(v interface{}) []byte {
+	header := func(major byte, n int) []byte {
+		if n < 24 {
+			return []byte{major<<5 | byte(n)}
+		}
+		if n < 256 {
+			return []byte{major<<5 | 24, byte(n)}
+		}
+		b := []byte{major<<5 | 25, 0, 0}
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		return b
+	}
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return header(1, -1-v)
+		}
+		return header(0, v)
+	case []byte:
+		return append(header(2, len(v)), v...)
+	case string:
+		return append(header(3, len(v)), v...)
+	case map[interface{}]interface{}:
+		keys := make([][]byte, 0, len(v))
+		values := map[string][]byte{}
+		for k, val := range v {
+			kb := encodeCBOR(k)
+			keys = append(keys, kb)
+			values[string(kb)] = encodeCBOR(val)
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+		b := header(5, len(v))
+		for _, k := range keys {
+			b = append(append(b, k...), values[string(k)]...)
+		}
+		return b
+	}
+	panic("unsupported type")
+}
+
+type testAuthenticator struct {
+	key       *ecdsa.PrivateKey
+	credID    []byte
+	signCount uint32
+	rpID      string
+	origin    string
+}
+
+func newTestAuthenticator(t *testing.T, rpID, origin string) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &testAuthenticator{key: key, credID: []byte("credential-1"), rpID: rpID, origin: origin}
+}
+
+func (a *testAuthenticator) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	b := append([]byte(nil), rpIDHash[:]...)
+	flags := byte(flagUserPresent | flagUserVerified)
+	if attested {
+		flags |= flagAttested
+	}
+	b = append(b, flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[33:], a.signCount)
+	if attested {
+		b = append(b, make([]byte, 16)...)
+		b = append(b, byte(len(a.credID)>>8), byte(len(a.credID)))
+		b = append(b, a.credID...)
+		x := a.key.X.FillBytes(make([]byte, 32))
+		y := a.key.Y.FillBytes(make([]byte, 32))
+		b = append(b, encodeCBOR(map[interface{}]interface{}{1: 2, 3: -7, -1: 1, -2: x, -3: y})...)
+	}
+	return b
+}
+
+func (a *testAuthenticator) clientData(typ string, challenge []byte) []byte {
+	b, _ := json.Marshal(clientData{Type: typ, Challenge: base64.RawURLEncoding.EncodeToString(challenge), Origin: a.origin})
+	return b
+}
+
+func (a *testAuthenticator) create(challenge []byte) []byte {
+	res := map[string]interface{}{
+		"rawId": Base64URL(a.credID),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON": Base64URL(a.clientData("webauthn.create", challenge)),
+			"attestationObject": Base64URL(encodeCBOR(map[interface{}]interface{}{
+				"fmt":      "none",
+				"attStmt":  map[interface{}]interface{}{},
+				"authData": a.authData(true),
+			})),
+		},
+	}
+	b, _ := json.Marshal(res)
+	return b
+}
+
+func (a *testAuthenticator) get(t *testing.T, challenge []byte) []byte {
+	a.signCount++
+	authData := a.authData(false)
+	clientDataJSON := a.clientData("webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	r, ss, err := ecdsa.Sign(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, ss})
+	require.NoError(t, err)
+
+	res := map[string]interface{}{
+		"rawId": Base64URL(a.credID),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    Base64URL(clientDataJSON),
+			"authenticatorData": Base64URL(authData),
+			"signature":         Base64URL(sig),
+		},
+	}
+	b, _ := json.Marshal(res)
+	return b
+}
+
+func webAuthnContext(e *echo.Echo, body []byte) echo.Context {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	return e.NewContext(req, httptest.NewRecorder())
+}
+
+func TestWebAuthn(t *testing.T) {
+	e := echo.New()
+	w := NewWebAuthn(WebAuthnConfig{RPID: "example.com", UserVerification: "required"})
+	user := WebAuthnUser{ID: []byte("user-1"), Name: "jon@example.com"}
+	authenticator := newTestAuthenticator(t, "example.com", "https://example.com")
+
+	// registration
+	creation, err := w.BeginRegistration(webAuthnContext(e, nil), user, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", creation.RP.ID)
+	assert.Equal(t, "jon@example.com", creation.User.DisplayName)
+	assert.Len(t, creation.Challenge, 32)
+
+	credential, err := w.FinishRegistration(webAuthnContext(e, authenticator.create(creation.Challenge)), user)
+	require.NoError(t, err)
+	assert.Equal(t, Base64URL("credential-1"), credential.ID)
+
+	_, err = w.FinishRegistration(webAuthnContext(e, authenticator.create(creation.Challenge)), user)
+	assert.Error(t, err, "challenge can be used only once")
+
+	// login
+	request, err := w.BeginLogin(webAuthnContext(e, nil), user, []Credential{*credential})
+	require.NoError(t, err)
+	assert.Equal(t, []credentialDescriptor{{Type: "public-key", ID: credential.ID}}, request.AllowCredentials)
+
+	used, err := w.FinishLogin(webAuthnContext(e, authenticator.get(t, request.Challenge)), user, []Credential{*credential})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), used.SignCount)
+
+	// replayed assertion with old sign count is rejected
+	request, err = w.BeginLogin(webAuthnContext(e, nil), user, []Credential{*used})
+	require.NoError(t, err)
+	authenticator.signCount = 0
+	_, err = w.FinishLogin(webAuthnContext(e, authenticator.get(t, request.Challenge)), user, []Credential{*used})
+	assert.Error(t, err)
+
+	// options are serialized with base64 URL encoded binary values
+	b, err := json.Marshal(request)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"id":"Y3JlZGVudGlhbC0x"`)
+}
+
+func TestWebAuthn_invalid(t *testing.T) {
+	e := echo.New()
+	w := NewWebAuthn(WebAuthnConfig{RPID: "example.com"})
+	user := WebAuthnUser{ID: []byte("user-1"), Name: "jon"}
+
+	var testCases = []struct {
+		name          string
+		authenticator *testAuthenticator
+	}{
+		{name: "nok, wrong origin", authenticator: newTestAuthenticator(t, "example.com", "https://evil.com")},
+		{name: "nok, wrong relying party", authenticator: newTestAuthenticator(t, "evil.com", "https://example.com")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			creation, err := w.BeginRegistration(webAuthnContext(e, nil), user, nil)
+			require.NoError(t, err)
+			_, err = w.FinishRegistration(webAuthnContext(e, tc.authenticator.create(creation.Challenge)), user)
+			he, ok := err.(*echo.HTTPError)
+			require.True(t, ok)
+			assert.Equal(t, http.StatusBadRequest, he.Code)
+		})
+	}
+
+	_, err := w.FinishRegistration(webAuthnContext(e, []byte(`{"type":"public-key","rawId":"AA"}`)), user)
+	assert.Error(t, err, "ceremony not started")
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	v, n, err := decodeCBOR(encodeCBOR(map[interface{}]interface{}{"a": []byte{1}, -3: 1000, 1: "x"}))
+	require.NoError(t, err)
+	assert.Equal(t, 12, n)
+	assert.Equal(t, map[interface{}]interface{}{"a": []byte{1}, int64(-3): int64(1000), int64(1): "x"}, v)
+
+	_, _, err = decodeCBOR([]byte{0x5f})
+	assert.Error(t, err, "indefinite length is not supported")
+	_, _, err = decodeCBOR([]byte{0x45, 1})
+	assert.Error(t, err, "truncated byte string")
+}
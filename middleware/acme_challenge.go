@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// ACMEChallengeConfig defines the config for ACMEChallenge middleware.
+	ACMEChallengeConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Store returns key authorizations of pending HTTP-01 challenges.
+		// Required.
+		Store ACMEChallengeStore
+	}
+
+	// ACMEChallengeStore is the interface to be implemented by stores of HTTP-01 challenges.
+	ACMEChallengeStore interface {
+		// KeyAuthorization returns key authorization for token. It returns ErrACMEChallengeNotFound when there is
+		// no pending challenge with the token.
+		KeyAuthorization(c echo.Context, token string) (string, error)
+	}
+
+	// ACMEChallengeMemoryStore is in-memory ACMEChallengeStore. Certificate automation running in the same process
+	// adds challenges with Put and removes them with Delete when they are validated.
+	ACMEChallengeMemoryStore struct {
+		mutex      sync.RWMutex
+		challenges map[string]string
+	}
+
+	// ACMEChallengeDirStore is ACMEChallengeStore reading challenge files from directory, i.e. webroot directory of
+	// certbot (`<webroot>/.well-known/acme-challenge`).
+	ACMEChallengeDirStore struct {
+		// Dir is directory with challenge files named by their tokens.
+		Dir string
+	}
+)
+
+// ACMEChallengePathPrefix is path prefix of HTTP-01 challenge requests (RFC 8555 section 8.3).
+const ACMEChallengePathPrefix = "/.well-known/acme-challenge/"
+
+// ErrACMEChallengeNotFound is returned by ACMEChallengeStore when challenge does not exist.
+var ErrACMEChallengeNotFound = errors.New("acme challenge not found")
+
+// DefaultACMEChallengeConfig is the default ACMEChallenge middleware config.
+var DefaultACMEChallengeConfig = ACMEChallengeConfig{
+	Skipper: DefaultSkipper,
+}
+
+// ACMEChallenge returns a middleware that serves HTTP-01 challenges of ACME (i.e. Let's Encrypt) from store so
+// certificate automation running outside of Echo (not `Echo#AutoTLSManager`) can complete challenges through the
+// main server. Requests to other paths are passed to next handler. Use it with `Echo#Pre` before redirect
+// middlewares as challenges are requested over plain HTTP.
+//
+// Example:
+//
+//	challenges := middleware.NewACMEChallengeMemoryStore()
+//	e.Pre(middleware.ACMEChallenge(challenges))
+//	e.Pre(middleware.HTTPSRedirect())
+func ACMEChallenge(store ACMEChallengeStore) echo.MiddlewareFunc {
+	c := DefaultACMEChallengeConfig
+	c.Store = store
+	return ACMEChallengeWithConfig(c)
+}
+
+// ACMEChallengeWithConfig returns an ACMEChallenge middleware with config.
+// See: `ACMEChallenge()`.
+func ACMEChallengeWithConfig(config ACMEChallengeConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultACMEChallengeConfig.Skipper
+	}
+	if config.Store == nil {
+		panic("echo: acme challenge middleware requires store")
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if config.Skipper(c) || !strings.HasPrefix(req.URL.Path, ACMEChallengePathPrefix) {
+				return next(c)
+			}
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return echo.ErrMethodNotAllowed
+			}
+
+			token := strings.TrimPrefix(req.URL.Path, ACMEChallengePathPrefix)
+			if !validACMEToken(token) {
+				return echo.ErrNotFound
+			}
+			keyAuth, err := config.Store.KeyAuthorization(c, token)
+			if errors.Is(err, ErrACMEChallengeNotFound) {
+				return echo.ErrNotFound
+			}
+			if err != nil {
+				return err
+			}
+			return c.String(http.StatusOK, keyAuth)
+		}
+	}
+}
+
+// validACMEToken checks that token contains only base64url characters (RFC 8555 section 8.3) so it can not be
+// used for path traversal by stores.
+func validACMEToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, r := range token {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// NewACMEChallengeMemoryStore creates new in-memory ACME challenge store.
+func NewACMEChallengeMemoryStore() *ACMEChallengeMemoryStore {
+	return &ACMEChallengeMemoryStore{challenges: map[string]string{}}
+}
+
+// Put adds pending challenge.
+func (s *ACMEChallengeMemoryStore) Put(token, keyAuthorization string) {
+	s.mutex.Lock()
+	s.challenges[token] = keyAuthorization
+	s.mutex.Unlock()
+}
+
+// Delete removes challenge.
+func (s *ACMEChallengeMemoryStore) Delete(token string) {
+	s.mutex.Lock()
+	delete(s.challenges, token)
+	s.mutex.Unlock()
+}
+
+// KeyAuthorization implements ACMEChallengeStore.KeyAuthorization.
+func (s *ACMEChallengeMemoryStore) KeyAuthorization(c echo.Context, token string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	keyAuth, ok := s.challenges[token]
+	if !ok {
+		return "", ErrACMEChallengeNotFound
+	}
+	return keyAuth, nil
+}
+
+// KeyAuthorization implements ACMEChallengeStore.KeyAuthorization.
+func (s ACMEChallengeDirStore) KeyAuthorization(c echo.Context, token string) (string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(s.Dir, token))
+	if os.IsNotExist(err) {
+		return "", ErrACMEChallengeNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACMEChallenge(t *testing.T) {
+	store := NewACMEChallengeMemoryStore()
+	store.Put("token_1", "token_1.thumbprint")
+	store.Put("token_2", "token_2.thumbprint")
+	store.Delete("token_2")
+
+	var testCases = []struct {
+		name       string
+		method     string
+		path       string
+		expectCode int
+		expectBody string
+	}{
+		{name: "ok, pending challenge", path: "/.well-known/acme-challenge/token_1", expectCode: http.StatusOK, expectBody: "token_1.thumbprint"},
+		{name: "nok, deleted challenge", path: "/.well-known/acme-challenge/token_2", expectCode: http.StatusNotFound},
+		{name: "nok, invalid token", path: "/.well-known/acme-challenge/../token_1", expectCode: http.StatusNotFound},
+		{name: "nok, POST", method: http.MethodPost, path: "/.well-known/acme-challenge/token_1", expectCode: http.StatusMethodNotAllowed},
+		{name: "ok, other paths are passed to next handler", path: "/", expectCode: http.StatusOK, expectBody: "home"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Pre(ACMEChallenge(store))
+			e.GET("/", func(c echo.Context) error {
+				return c.String(http.StatusOK, "home")
+			})
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tc.path, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectBody != "" {
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestACMEChallengeDirStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acme")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "token"), []byte("token.thumbprint\n"), 0644))
+
+	store := ACMEChallengeDirStore{Dir: dir}
+	keyAuth, err := store.KeyAuthorization(nil, "token")
+	assert.NoError(t, err)
+	assert.Equal(t, "token.thumbprint", keyAuth)
+
+	_, err = store.KeyAuthorization(nil, "missing")
+	assert.True(t, errors.Is(err, ErrACMEChallengeNotFound))
+}
+
+func TestACMEChallengeWithConfig_panics(t *testing.T) {
+	assert.Panics(t, func() { ACMEChallengeWithConfig(ACMEChallengeConfig{}) })
+}
@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// AdaptiveShedConfig defines the config for AdaptiveShed middleware.
+	AdaptiveShedConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Algorithm adjusting concurrency limit. Possible values:
+		// - "gradient" compares short term latency to long term (baseline) latency and shrinks the limit when
+		// requests get slower than the baseline, growing it again while latency stays close to the baseline.
+		// - "aimd" increases limit by one while latency is below LatencyThreshold and multiplies it by
+		// BackoffRatio when latency exceeds the threshold.
+		// Optional. Default value "gradient".
+		Algorithm string `yaml:"algorithm"`
+
+		// InitialLimit is concurrency limit used before first latency samples are collected.
+		// Optional. Default value 20.
+		InitialLimit int `yaml:"initial_limit"`
+
+		// MinLimit is lower bound of the concurrency limit.
+		// Optional. Default value 1.
+		MinLimit int `yaml:"min_limit"`
+
+		// MaxLimit is upper bound of the concurrency limit.
+		// Optional. Default value 1000.
+		MaxLimit int `yaml:"max_limit"`
+
+		// Tolerance is how much short term latency can exceed baseline latency before gradient algorithm
+		// shrinks the limit (1.5 = 50% slower).
+		// Optional. Default value 1.5.
+		Tolerance float64 `yaml:"tolerance"`
+
+		// Smoothing is weight of newly computed limit in gradient algorithm (0-1). Lower values react slower.
+		// Optional. Default value 0.2.
+		Smoothing float64 `yaml:"smoothing"`
+
+		// LatencyThreshold is latency over which aimd algorithm decreases the limit.
+		// Required for "aimd" algorithm.
+		LatencyThreshold time.Duration `yaml:"latency_threshold"`
+
+		// BackoffRatio is multiplier applied to limit by aimd algorithm when latency exceeds the threshold.
+		// Optional. Default value 0.9.
+		BackoffRatio float64 `yaml:"backoff_ratio"`
+
+		// OnLimitChange is called after concurrency limit changes, for example to export it as metric.
+		// Optional.
+		OnLimitChange func(limit int)
+
+		// ErrorHandler is called with ErrAdaptiveShed when request is rejected.
+		// Optional. Default value returns the error.
+		ErrorHandler func(c echo.Context, err error) error
+	}
+
+	adaptiveLimiter struct {
+		mutex    sync.Mutex
+		config   AdaptiveShedConfig
+		limit    float64
+		inflight int
+		shortRTT float64
+		longRTT  float64
+		samples  int
+	}
+)
+
+const (
+	// AdaptiveShedGradient is gradient algorithm of AdaptiveShed middleware.
+	AdaptiveShedGradient = "gradient"
+	// AdaptiveShedAIMD is additive increase/multiplicative decrease algorithm of AdaptiveShed middleware.
+	AdaptiveShedAIMD = "aimd"
+)
+
+const (
+	// adaptiveShortWindow and adaptiveLongWindow are number of samples of exponential moving averages of
+	// short term and baseline latency.
+	adaptiveShortWindow = 10
+	adaptiveLongWindow  = 600
+)
+
+// ErrAdaptiveShed denotes an error raised when request is rejected because concurrency limit is reached.
+var ErrAdaptiveShed = echo.NewHTTPError(http.StatusServiceUnavailable, "server is overloaded")
+
+// DefaultAdaptiveShedConfig is the default AdaptiveShed middleware config.
+var DefaultAdaptiveShedConfig = AdaptiveShedConfig{
+	Skipper:      DefaultSkipper,
+	Algorithm:    AdaptiveShedGradient,
+	InitialLimit: 20,
+	MinLimit:     1,
+	MaxLimit:     1000,
+	Tolerance:    1.5,
+	Smoothing:    0.2,
+	BackoffRatio: 0.9,
+	ErrorHandler: func(c echo.Context, err error) error {
+		return err
+	},
+}
+
+// AdaptiveShed returns a middleware that limits number of concurrently handled requests with limit adjusted by
+// observed latency (gradient algorithm). Requests over the limit are rejected with 503 status so latency of
+// admitted requests stays close to latency of unloaded server.
+func AdaptiveShed() echo.MiddlewareFunc {
+	return AdaptiveShedWithConfig(DefaultAdaptiveShedConfig)
+}
+
+// AdaptiveShedWithConfig returns an AdaptiveShed middleware with config.
+// See: `AdaptiveShed()`.
+func AdaptiveShedWithConfig(config AdaptiveShedConfig) echo.MiddlewareFunc {
+	l := newAdaptiveLimiter(config)
+	config = l.config
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			if !l.acquire() {
+				return config.ErrorHandler(c, ErrAdaptiveShed)
+			}
+			start := echo.Now(c)
+			defer func() {
+				l.release(echo.Now(c).Sub(start))
+			}()
+			return next(c)
+		}
+	}
+}
+
+func newAdaptiveLimiter(config AdaptiveShedConfig) *adaptiveLimiter {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultAdaptiveShedConfig.Skipper
+	}
+	if config.Algorithm == "" {
+		config.Algorithm = DefaultAdaptiveShedConfig.Algorithm
+	}
+	if config.MinLimit <= 0 {
+		config.MinLimit = DefaultAdaptiveShedConfig.MinLimit
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = DefaultAdaptiveShedConfig.MaxLimit
+	}
+	if config.InitialLimit <= 0 {
+		config.InitialLimit = DefaultAdaptiveShedConfig.InitialLimit
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = DefaultAdaptiveShedConfig.Tolerance
+	}
+	if config.Smoothing <= 0 || config.Smoothing > 1 {
+		config.Smoothing = DefaultAdaptiveShedConfig.Smoothing
+	}
+	if config.BackoffRatio <= 0 || config.BackoffRatio >= 1 {
+		config.BackoffRatio = DefaultAdaptiveShedConfig.BackoffRatio
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultAdaptiveShedConfig.ErrorHandler
+	}
+	switch config.Algorithm {
+	case AdaptiveShedGradient:
+	case AdaptiveShedAIMD:
+		if config.LatencyThreshold <= 0 {
+			panic("echo: adaptive shed middleware with aimd algorithm requires latency threshold")
+		}
+	default:
+		panic("echo: unknown adaptive shed algorithm: " + config.Algorithm)
+	}
+	if config.MinLimit > config.MaxLimit {
+		panic("echo: adaptive shed middleware min limit is greater than max limit")
+	}
+
+	l := &adaptiveLimiter{config: config}
+	l.limit = l.bound(float64(config.InitialLimit))
+	return l
+}
+
+func (l *adaptiveLimiter) acquire() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.inflight >= int(l.limit) {
+		return false
+	}
+	l.inflight++
+	return true
+}
+
+func (l *adaptiveLimiter) release(latency time.Duration) {
+	l.mutex.Lock()
+	inflight := l.inflight
+	l.inflight--
+	old := int(l.limit)
+	switch l.config.Algorithm {
+	case AdaptiveShedAIMD:
+		l.sampleAIMD(latency, inflight)
+	default:
+		l.sampleGradient(latency, inflight)
+	}
+	limit := int(l.limit)
+	l.mutex.Unlock()
+
+	if limit != old && l.config.OnLimitChange != nil {
+		l.config.OnLimitChange(limit)
+	}
+}
+
+func (l *adaptiveLimiter) sampleAIMD(latency time.Duration, inflight int) {
+	if latency > l.config.LatencyThreshold {
+		l.limit = l.bound(l.limit * l.config.BackoffRatio)
+		return
+	}
+	// grow only when limit is actually used, otherwise limit would grow without bound on idle server
+	if float64(inflight)*2 >= l.limit {
+		l.limit = l.bound(l.limit + 1)
+	}
+}
+
+func (l *adaptiveLimiter) sampleGradient(latency time.Duration, inflight int) {
+	rtt := float64(latency)
+	if rtt <= 0 {
+		rtt = 1
+	}
+	l.samples++
+	if l.samples == 1 {
+		l.shortRTT, l.longRTT = rtt, rtt
+	} else {
+		l.shortRTT = ema(l.shortRTT, rtt, adaptiveShortWindow)
+		l.longRTT = ema(l.longRTT, rtt, adaptiveLongWindow)
+	}
+	// baseline recovers faster when latency stays lower than it for a long time
+	if l.longRTT/l.shortRTT > 2 {
+		l.longRTT *= 0.95
+	}
+	if l.samples < adaptiveShortWindow {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.config.Tolerance*l.longRTT/l.shortRTT))
+	queue := math.Sqrt(l.limit)
+	newLimit := l.limit*gradient + queue
+	if newLimit > l.limit && float64(inflight)*2 < l.limit {
+		// limit is not used enough to know if it can be increased
+		return
+	}
+	l.limit = l.bound(l.limit*(1-l.config.Smoothing) + newLimit*l.config.Smoothing)
+}
+
+func (l *adaptiveLimiter) bound(limit float64) float64 {
+	return math.Max(float64(l.config.MinLimit), math.Min(float64(l.config.MaxLimit), limit))
+}
+
+func ema(avg, value float64, window int) float64 {
+	alpha := 2 / float64(window+1)
+	return avg*(1-alpha) + value*alpha
+}
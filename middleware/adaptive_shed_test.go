@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveShed(t *testing.T) {
+	e := echo.New()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	e.Use(AdaptiveShedWithConfig(AdaptiveShedConfig{InitialLimit: 1, MaxLimit: 1}))
+	e.GET("/", func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.String(http.StatusOK, "OK")
+	})
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		done <- rec.Code
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestAdaptiveShed_gradient(t *testing.T) {
+	changes := 0
+	l := newTestAdaptiveLimiter(AdaptiveShedConfig{
+		InitialLimit:  10,
+		MaxLimit:      100,
+		OnLimitChange: func(int) { changes++ },
+	})
+
+	for i := 0; i < 100; i++ {
+		l.sample(10 * time.Millisecond)
+	}
+	assert.Equal(t, 100, l.current(), "limit grows while latency stays at baseline")
+	assert.True(t, changes > 0)
+
+	for i := 0; i < 50; i++ {
+		l.sample(200 * time.Millisecond)
+	}
+	assert.True(t, l.current() < 10, "limit shrinks when latency increases, got %d", l.current())
+}
+
+func TestAdaptiveShed_aimd(t *testing.T) {
+	l := newTestAdaptiveLimiter(AdaptiveShedConfig{
+		Algorithm:        AdaptiveShedAIMD,
+		InitialLimit:     10,
+		LatencyThreshold: 100 * time.Millisecond,
+		BackoffRatio:     0.5,
+	})
+
+	l.sample(10 * time.Millisecond)
+	assert.Equal(t, 11, l.current())
+
+	l.sample(200 * time.Millisecond)
+	assert.Equal(t, 5, l.current())
+
+	for i := 0; i < 10; i++ {
+		l.sample(time.Second)
+	}
+	assert.Equal(t, 1, l.current(), "limit does not go below MinLimit")
+}
+
+func TestAdaptiveShedWithConfig_panics(t *testing.T) {
+	assert.Panics(t, func() {
+		AdaptiveShedWithConfig(AdaptiveShedConfig{Algorithm: "unknown"})
+	})
+	assert.Panics(t, func() {
+		AdaptiveShedWithConfig(AdaptiveShedConfig{Algorithm: AdaptiveShedAIMD})
+	})
+	assert.Panics(t, func() {
+		AdaptiveShedWithConfig(AdaptiveShedConfig{MinLimit: 10, MaxLimit: 5})
+	})
+}
+
+type testAdaptiveLimiter struct {
+	*adaptiveLimiter
+}
+
+func newTestAdaptiveLimiter(config AdaptiveShedConfig) testAdaptiveLimiter {
+	return testAdaptiveLimiter{newAdaptiveLimiter(config)}
+}
+
+// sample simulates request that was handled while limit was fully used.
+func (l testAdaptiveLimiter) sample(latency time.Duration) {
+	l.mutex.Lock()
+	l.inflight = int(l.limit)
+	l.mutex.Unlock()
+	l.release(latency)
+}
+
+func (l testAdaptiveLimiter) current() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return int(l.limit)
+}
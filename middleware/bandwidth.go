@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+type (
+	// BandwidthConfig defines the config for Bandwidth middleware.
+	BandwidthConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Rate is maximum write rate of response bodies in bytes per second.
+		// Required.
+		Rate int64 `yaml:"rate"`
+
+		// Burst is number of bytes written without waiting after idle period, bodies are written in chunks of at
+		// most Burst bytes.
+		// Optional. Default value equals to Rate.
+		Burst int `yaml:"burst"`
+
+		// KeyExtractor returns key of token bucket shared by requests, i.e. tenant of the request for limiting
+		// total bandwidth of all downloads of the tenant. Requests failing to extract the key are limited per
+		// connection.
+		// Optional. Default value limits each connection (remote address of the request).
+		KeyExtractor Extractor
+
+		// ExpiresIn is duration after which idle token bucket is removed.
+		// Optional. Default value 3 minutes.
+		ExpiresIn time.Duration `yaml:"expires_in"`
+	}
+
+	bandwidthBuckets struct {
+		mutex       sync.Mutex
+		buckets     map[string]*bandwidthBucket
+		expiresIn   time.Duration
+		lastCleanup time.Time
+	}
+
+	bandwidthBucket struct {
+		limiter  *rate.Limiter
+		lastSeen time.Time
+		active   int
+	}
+
+	bandwidthWriter struct {
+		http.ResponseWriter
+		limiter *rate.Limiter
+		context echo.Context
+	}
+)
+
+// DefaultBandwidthConfig is the default Bandwidth middleware config.
+var DefaultBandwidthConfig = BandwidthConfig{
+	Skipper: DefaultSkipper,
+	KeyExtractor: func(c echo.Context) (string, error) {
+		return c.Request().RemoteAddr, nil
+	},
+	ExpiresIn: 3 * time.Minute,
+}
+
+// bandwidthWait waits for duration or until context is canceled.
+var bandwidthWait = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Bandwidth returns a middleware that throttles writing of response bodies to rate bytes per second per connection,
+// for fair use of large download endpoints. Writes wait for tokens of the bucket and fail with error of request
+// context when the client goes away.
+//
+// Example:
+//
+//	e.GET("/downloads/:file", download, middleware.Bandwidth(512<<10))
+func Bandwidth(rate int64) echo.MiddlewareFunc {
+	c := DefaultBandwidthConfig
+	c.Rate = rate
+	return BandwidthWithConfig(c)
+}
+
+// BandwidthWithConfig returns a Bandwidth middleware with config.
+// See: `Bandwidth()`.
+func BandwidthWithConfig(config BandwidthConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Rate <= 0 {
+		panic("echo: bandwidth middleware requires rate")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultBandwidthConfig.Skipper
+	}
+	if config.Burst <= 0 {
+		config.Burst = int(config.Rate)
+	}
+	if config.KeyExtractor == nil {
+		config.KeyExtractor = DefaultBandwidthConfig.KeyExtractor
+	}
+	if config.ExpiresIn <= 0 {
+		config.ExpiresIn = DefaultBandwidthConfig.ExpiresIn
+	}
+	buckets := &bandwidthBuckets{
+		buckets:   make(map[string]*bandwidthBucket),
+		expiresIn: config.ExpiresIn,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			key, err := config.KeyExtractor(c)
+			if err != nil {
+				key = c.Request().RemoteAddr
+			}
+			bucket := buckets.acquire(key, &config, echo.Now(c))
+			defer buckets.release(bucket, echo.Now(c))
+
+			res := c.Response()
+			w := &bandwidthWriter{ResponseWriter: res.Writer, limiter: bucket.limiter, context: c}
+			res.Writer = w
+			defer func() { res.Writer = w.ResponseWriter }()
+			return next(c)
+		}
+	}
+}
+
+func (s *bandwidthBuckets) acquire(key string, config *BandwidthConfig, now time.Time) *bandwidthBucket {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.lastCleanup) > s.expiresIn || now.Before(s.lastCleanup) {
+		for k, b := range s.buckets {
+			if b.active == 0 && now.Sub(b.lastSeen) > s.expiresIn {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastCleanup = now
+	}
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bandwidthBucket{limiter: rate.NewLimiter(rate.Limit(config.Rate), config.Burst)}
+		s.buckets[key] = b
+	}
+	b.active++
+	b.lastSeen = now
+	return b
+}
+
+func (s *bandwidthBuckets) release(b *bandwidthBucket, now time.Time) {
+	s.mutex.Lock()
+	b.active--
+	b.lastSeen = now
+	s.mutex.Unlock()
+}
+
+func (w *bandwidthWriter) Write(b []byte) (n int, err error) {
+	burst := w.limiter.Burst()
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > burst {
+			chunk = chunk[:burst]
+		}
+		if err = w.wait(len(chunk)); err != nil {
+			return n, err
+		}
+		var m int
+		m, err = w.ResponseWriter.Write(chunk)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		b = b[m:]
+	}
+	return n, nil
+}
+
+// wait waits until size bytes can be written.
+func (w *bandwidthWriter) wait(size int) error {
+	now := echo.Now(w.context)
+	r := w.limiter.ReserveN(now, size)
+	d := r.DelayFrom(now)
+	if d <= 0 {
+		return nil
+	}
+	// bytes written so far are sent before waiting instead of sitting in buffer of the server
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+	if err := bandwidthWait(w.context.Request().Context(), d); err != nil {
+		r.CancelAt(echo.Now(w.context))
+		return err
+	}
+	return nil
+}
+
+func (w *bandwidthWriter) Flush() {
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *bandwidthWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
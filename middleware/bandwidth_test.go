@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBandwidthWait replaces waiting of Bandwidth middleware with advancing the clock and records waits.
+func fakeBandwidthWait(clock *echotest.Clock) (waits *[]time.Duration, restore func()) {
+	waits = new([]time.Duration)
+	wait := bandwidthWait
+	bandwidthWait = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		clock.Advance(d)
+		return nil
+	}
+	return waits, func() {
+		bandwidthWait = wait
+	}
+}
+
+func TestBandwidth(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	waits, restore := fakeBandwidthWait(clock)
+	defer restore()
+
+	e := echo.New()
+	e.Clock = clock
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := Bandwidth(10)(func(c echo.Context) error {
+		return c.String(http.StatusOK, strings.Repeat("x", 35))
+	})
+
+	assert.NoError(t, h(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, strings.Repeat("x", 35), rec.Body.String())
+	assert.Equal(t, []time.Duration{time.Second, time.Second, 500 * time.Millisecond}, *waits)
+	assert.Equal(t, int64(35), c.Response().Size)
+	assert.Equal(t, rec, c.Response().Writer, "writer is restored")
+}
+
+func TestBandwidthWithConfig_key(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	waits, restore := fakeBandwidthWait(clock)
+	defer restore()
+
+	e := echo.New()
+	e.Clock = clock
+	e.Use(BandwidthWithConfig(BandwidthConfig{
+		Rate:  100,
+		Burst: 50,
+		KeyExtractor: func(c echo.Context) (string, error) {
+			return c.Request().Header.Get("X-Tenant"), nil
+		},
+		ExpiresIn: time.Minute,
+	}))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, strings.Repeat("x", 50))
+	})
+	serve := func(tenant, remoteAddr string) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Tenant", tenant)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, 50, rec.Body.Len())
+	}
+
+	serve("a", "192.0.2.1:1234")
+	serve("b", "192.0.2.1:1234")
+	assert.Empty(t, *waits, "tenants have own buckets")
+	serve("a", "192.0.2.2:1234")
+	assert.Equal(t, []time.Duration{500 * time.Millisecond}, *waits, "connections of tenant share bucket")
+
+	clock.Advance(2 * time.Minute)
+	serve("c", "192.0.2.1:1234")
+	assert.Len(t, *waits, 1)
+}
+
+func TestBandwidth_canceled(t *testing.T) {
+	e := echo.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := BandwidthWithConfig(BandwidthConfig{Rate: 1, Burst: 1})(func(c echo.Context) error {
+		return c.String(http.StatusOK, "ab")
+	})
+
+	assert.Equal(t, context.Canceled, h(c))
+	assert.Equal(t, "a", rec.Body.String())
+}
+
+func TestBandwidthWithConfig_panics(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: bandwidth middleware requires rate", func() {
+		BandwidthWithConfig(BandwidthConfig{})
+	})
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/bytes"
+)
+
+type (
+	// BodyBufferConfig defines the config for BodyBuffer middleware.
+	BodyBufferConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Maximum allowed size for a buffered request body, it can be specified
+		// as `4x` or `4xB`, where x is one of the multiple from K, M, G, T or P.
+		// Required.
+		Limit string `yaml:"limit"`
+	}
+
+	bufferLimitReader struct {
+		reader io.ReadCloser
+		left   int64
+	}
+)
+
+var (
+	// DefaultBodyBufferConfig is the default BodyBuffer middleware config.
+	DefaultBodyBufferConfig = BodyBufferConfig{
+		Skipper: DefaultSkipper,
+	}
+)
+
+// BodyBuffer returns a BodyBuffer middleware.
+//
+// BodyBuffer middleware reads the whole request body into memory before any parsing
+// is done. Exact raw bytes are available to following middlewares and handlers with
+// `echo.RawBody(c)` (i.e. for verifying HMAC signature of webhooks) while request body
+// can still be read or bound with `c.Bind()` as many times as needed.
+// Bodies larger than limit are rejected with "413 - Request Entity Too Large".
+func BodyBuffer(limit string) echo.MiddlewareFunc {
+	c := DefaultBodyBufferConfig
+	c.Limit = limit
+	return BodyBufferWithConfig(c)
+}
+
+// BodyBufferWithConfig returns a BodyBuffer middleware with config.
+// See: `BodyBuffer()`.
+func BodyBufferWithConfig(config BodyBufferConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultBodyBufferConfig.Skipper
+	}
+
+	limit, err := bytes.Parse(config.Limit)
+	if err != nil {
+		panic(fmt.Errorf("echo: invalid body-buffer limit=%s", config.Limit))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			if req.ContentLength > limit {
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+			if req.Body != nil && req.Body != http.NoBody {
+				req.Body = &bufferLimitReader{reader: req.Body, left: limit}
+			}
+			if _, err := echo.BodyBytes(c); err != nil {
+				if err == echo.ErrStatusRequestEntityTooLarge {
+					return err
+				}
+				return echo.NewHTTPError(http.StatusBadRequest).SetInternal(err)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func (r *bufferLimitReader) Read(b []byte) (n int, err error) {
+	n, err = r.reader.Read(b)
+	r.left -= int64(n)
+	if r.left < 0 {
+		return n, echo.ErrStatusRequestEntityTooLarge
+	}
+	return
+}
+
+func (r *bufferLimitReader) Close() error {
+	return r.reader.Close()
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyBuffer(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		givenLimit        string
+		whenBody          string
+		whenUnknownLength bool
+		expectCode        int
+		expectBody        string
+	}{
+		{
+			name:       "ok, raw body and bind",
+			givenLimit: "1K",
+			whenBody:   `{"name":"Jon Snow"}`,
+			expectCode: http.StatusOK,
+			expectBody: "Jon Snow 4bf1168267f38aa09046849c1fb5e8ed4f78309edf942eb46f46c03ec2cdbf07",
+		},
+		{
+			name:       "nok, content length over limit",
+			givenLimit: "2B",
+			whenBody:   `{"name":"Jon Snow"}`,
+			expectCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:              "nok, content read over limit",
+			givenLimit:        "2B",
+			whenBody:          `{"name":"Jon Snow"}`,
+			whenUnknownLength: true,
+			expectCode:        http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(BodyBuffer(tc.givenLimit))
+			e.POST("/", func(c echo.Context) error {
+				mac := hmac.New(sha256.New, []byte("secret"))
+				mac.Write(echo.RawBody(c))
+
+				body, err := ioutil.ReadAll(c.Request().Body)
+				if err != nil {
+					return err
+				}
+				if string(body) != string(echo.RawBody(c)) {
+					return echo.ErrInternalServerError
+				}
+
+				u := struct {
+					Name string `json:"name"`
+				}{}
+				if err := c.Bind(&u); err != nil {
+					return err
+				}
+				return c.String(http.StatusOK, u.Name+" "+hex.EncodeToString(mac.Sum(nil)))
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.whenBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tc.whenUnknownLength {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectBody != "" {
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBodyBuffer_panicsOnInvalidLimit(t *testing.T) {
+	assert.Panics(t, func() {
+		BodyBuffer("x")
+	})
+}
@@ -17,7 +17,8 @@ type (
 		// Skipper defines a function to skip middleware.
 		Skipper Skipper
 
-		// Handler receives request and response payload.
+		// Handler receives request and response payload. Payloads are redacted with `Echo#Redactor` according to
+		// their content types.
 		// Required.
 		Handler BodyDumpHandler
 	}
@@ -83,7 +84,10 @@ func BodyDumpWithConfig(config BodyDumpConfig) echo.MiddlewareFunc {
 			}
 
 			// Callback
-			config.Handler(c, reqBody, resBody.Bytes())
+			redactor := c.Echo().Redactor
+			config.Handler(c,
+				redactor.Body(c.Request().Header.Get(echo.HeaderContentType), reqBody),
+				redactor.Body(c.Response().Header().Get(echo.HeaderContentType), resBody.Bytes()))
 
 			return
 		}
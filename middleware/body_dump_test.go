@@ -52,6 +52,29 @@ func TestBodyDump(t *testing.T) {
 	})
 }
 
+func TestBodyDump_redacted(t *testing.T) {
+	e := echo.New()
+	e.POST("/login", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"user": "jon", "token": "abc"})
+	})
+	requestBody := ""
+	responseBody := ""
+	e.Use(BodyDump(func(c echo.Context, reqBody, resBody []byte) {
+		requestBody = string(reqBody)
+		responseBody = string(resBody)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"user":"jon","password":"hunter2"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, `{"password":"[REDACTED]","user":"jon"}`, requestBody)
+	assert.Equal(t, `{"token":"[REDACTED]","user":"jon"}`, responseBody)
+
+	e.Redactor = nil
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("password=x")))
+	assert.Equal(t, "password=x", requestBody)
+}
+
 func TestBodyDumpFails(t *testing.T) {
 	e := echo.New()
 	hw := "Hello, World!"
@@ -17,12 +17,13 @@ type (
 
 		// Maximum allowed size for a request body, it can be specified
 		// as `4x` or `4xB`, where x is one of the multiple from K, M, G, T or P.
+		// Empty value means bodies are limited only on routes declaring `Route#BodyLimit()`.
 		Limit string `yaml:"limit"`
 		limit int64
 	}
 
 	limitedReader struct {
-		BodyLimitConfig
+		limit   int64
 		reader  io.ReadCloser
 		read    int64
 		context echo.Context
@@ -43,7 +44,7 @@ var (
 // response. The BodyLimit is determined based on both `Content-Length` request
 // header and actual content read, which makes it super secure.
 // Limit can be specified as `4x` or `4xB`, where x is one of the multiple from K, M,
-// G, T or P.
+// G, T or P. Routes declaring `Route#BodyLimit()` are limited by their own limit instead.
 func BodyLimit(limit string) echo.MiddlewareFunc {
 	c := DefaultBodyLimitConfig
 	c.Limit = limit
@@ -58,12 +59,15 @@ func BodyLimitWithConfig(config BodyLimitConfig) echo.MiddlewareFunc {
 		config.Skipper = DefaultBodyLimitConfig.Skipper
 	}
 
-	limit, err := bytes.Parse(config.Limit)
-	if err != nil {
-		panic(fmt.Errorf("echo: invalid body-limit=%s", config.Limit))
+	config.limit = -1
+	if config.Limit != "" {
+		limit, err := bytes.Parse(config.Limit)
+		if err != nil {
+			panic(fmt.Errorf("echo: invalid body-limit=%s", config.Limit))
+		}
+		config.limit = limit
 	}
-	config.limit = limit
-	pool := limitedReaderPool(config)
+	pool := limitedReaderPool()
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -71,16 +75,26 @@ func BodyLimitWithConfig(config BodyLimitConfig) echo.MiddlewareFunc {
 				return next(c)
 			}
 
+			limit := config.limit
+			if r := echo.RouteOf(c); r != nil {
+				if l, ok := r.GetMeta(echo.MetaBodyLimit).(int64); ok {
+					limit = l
+				}
+			}
+			if limit < 0 {
+				return next(c)
+			}
+
 			req := c.Request()
 
 			// Based on content length
-			if req.ContentLength > config.limit {
+			if req.ContentLength > limit {
 				return echo.ErrStatusRequestEntityTooLarge
 			}
 
 			// Based on content read
 			r := pool.Get().(*limitedReader)
-			r.Reset(req.Body, c)
+			r.Reset(req.Body, c, limit)
 			defer pool.Put(r)
 			req.Body = r
 
@@ -102,16 +116,17 @@ func (r *limitedReader) Close() error {
 	return r.reader.Close()
 }
 
-func (r *limitedReader) Reset(reader io.ReadCloser, context echo.Context) {
+func (r *limitedReader) Reset(reader io.ReadCloser, context echo.Context, limit int64) {
 	r.reader = reader
 	r.context = context
+	r.limit = limit
 	r.read = 0
 }
 
-func limitedReaderPool(c BodyLimitConfig) sync.Pool {
+func limitedReaderPool() sync.Pool {
 	return sync.Pool{
 		New: func() interface{} {
-			return &limitedReader{BodyLimitConfig: c}
+			return &limitedReader{}
 		},
 	}
 }
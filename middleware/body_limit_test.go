@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
@@ -54,21 +55,58 @@ func TestBodyLimit(t *testing.T) {
 	assert.Equal(http.StatusRequestEntityTooLarge, he.Code)
 }
 
+func TestBodyLimit_routeLimit(t *testing.T) {
+	e := echo.New()
+	e.Use(BodyLimitWithConfig(BodyLimitConfig{}))
+	handler := func(c echo.Context) error {
+		body, err := ioutil.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(body))
+	}
+	e.POST("/small", handler).BodyLimit("2B")
+	e.POST("/unlimited", handler)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/small", strings.NewReader("Hello")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/unlimited", strings.NewReader("Hello")))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Hello", rec.Body.String())
+
+	// route limit overrides limit of the config
+	e = echo.New()
+	e.Use(BodyLimit("2B"))
+	e.POST("/large", handler).BodyLimit("1K")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/large", strings.NewReader("Hello")))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// limit of host route is not shadowed by route with the same method and path of the default router
+	e = echo.New()
+	e.Use(BodyLimitWithConfig(BodyLimitConfig{}))
+	e.Host("api.example.com").POST("/upload", handler).BodyLimit("2B")
+	e.POST("/upload", handler)
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("Hello"))
+	req.Host = "api.example.com"
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
 func TestBodyLimitReader(t *testing.T) {
 	hw := []byte("Hello, World!")
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(hw))
 	rec := httptest.NewRecorder()
 
-	config := BodyLimitConfig{
-		Skipper: DefaultSkipper,
-		Limit:   "2B",
-		limit:   2,
-	}
 	reader := &limitedReader{
-		BodyLimitConfig: config,
-		reader:          ioutil.NopCloser(bytes.NewReader(hw)),
-		context:         e.NewContext(req, rec),
+		limit:   2,
+		reader:  ioutil.NopCloser(bytes.NewReader(hw)),
+		context: e.NewContext(req, rec),
 	}
 
 	// read all should return ErrStatusRequestEntityTooLarge
@@ -78,7 +116,7 @@ func TestBodyLimitReader(t *testing.T) {
 
 	// reset reader and read two bytes must succeed
 	bt := make([]byte, 2)
-	reader.Reset(ioutil.NopCloser(bytes.NewReader(hw)), e.NewContext(req, rec))
+	reader.Reset(ioutil.NopCloser(bytes.NewReader(hw)), e.NewContext(req, rec), 2)
 	n, err := reader.Read(bt)
 	assert.Equal(t, 2, n)
 	assert.Equal(t, nil, err)
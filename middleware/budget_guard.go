@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+type (
+	// BudgetGuardConfig defines the config for BudgetGuard middleware.
+	BudgetGuardConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// SampleRate is fraction of requests (0-1) that are measured.
+		// Optional. Default value 0.1.
+		SampleRate float64 `yaml:"sample_rate"`
+
+		// MaxAllocBytes is number of bytes allocated during the request over which the request is reported.
+		// Optional. Default value 64MB.
+		MaxAllocBytes uint64 `yaml:"max_alloc_bytes"`
+
+		// MaxGoroutines is number of goroutines started by the request and still running after it over which the
+		// request is reported.
+		// Optional. Default value 50.
+		MaxGoroutines int64 `yaml:"max_goroutines"`
+
+		// Reject aborts measured requests exceeding MaxAllocBytes while they are running: context of the request
+		// is canceled and, when response was not sent yet, ErrBudgetExceeded is returned.
+		// Optional. Default value false.
+		Reject bool `yaml:"reject"`
+
+		// CheckInterval is interval of allocation checks of running requests when Reject is enabled.
+		// Optional. Default value 10ms.
+		CheckInterval time.Duration `yaml:"check_interval"`
+
+		// OnExceeded is called with report of request exceeding the budget.
+		// Optional. Default value logs the report as warning with logger of the context.
+		OnExceeded func(c echo.Context, report BudgetReport)
+
+		// ErrorHandler is called with ErrBudgetExceeded when request is rejected.
+		// Optional. Default value returns the error.
+		ErrorHandler func(c echo.Context, err error) error
+	}
+
+	// BudgetReport describes resource usage of request exceeding the budget.
+	BudgetReport struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		// AllocBytes is number of bytes allocated by the process during the request.
+		AllocBytes uint64 `json:"alloc_bytes"`
+		// Goroutines is change of number of goroutines of the process between start and end of the request.
+		Goroutines int64         `json:"goroutines"`
+		Duration   time.Duration `json:"duration"`
+		// Rejected is true when the request was aborted.
+		Rejected bool `json:"rejected"`
+	}
+)
+
+// ErrBudgetExceeded denotes an error raised when request is aborted because it allocated more memory than allowed.
+var ErrBudgetExceeded = echo.NewHTTPError(http.StatusServiceUnavailable, "request exceeded resource budget")
+
+// DefaultBudgetGuardConfig is the default BudgetGuard middleware config.
+var DefaultBudgetGuardConfig = BudgetGuardConfig{
+	Skipper:       DefaultSkipper,
+	SampleRate:    0.1,
+	MaxAllocBytes: 64 << 20,
+	MaxGoroutines: 50,
+	CheckInterval: 10 * time.Millisecond,
+	OnExceeded: func(c echo.Context, report BudgetReport) {
+		c.Logger().Warnj(log.JSON{
+			"message":     "request exceeded resource budget",
+			"method":      report.Method,
+			"path":        report.Path,
+			"alloc_bytes": report.AllocBytes,
+			"goroutines":  report.Goroutines,
+			"duration":    report.Duration.String(),
+			"rejected":    report.Rejected,
+		})
+	},
+	ErrorHandler: func(c echo.Context, err error) error {
+		return err
+	},
+}
+
+// BudgetGuard returns a middleware that samples memory allocated and goroutines left running by requests and
+// reports requests over the budget, to catch handlers leaking goroutines or allocating unbounded memory.
+//
+// Go runtime does not attribute allocations to goroutines, so usage is measured as change of process-wide
+// counters of `runtime/metrics` (`runtime.MemStats` before Go 1.16) during the request and includes work done concurrently by other requests. Set
+// limits well above usage of normal requests.
+func BudgetGuard() echo.MiddlewareFunc {
+	return BudgetGuardWithConfig(DefaultBudgetGuardConfig)
+}
+
+// BudgetGuardWithConfig returns a BudgetGuard middleware with config.
+// See: `BudgetGuard()`.
+func BudgetGuardWithConfig(config BudgetGuardConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultBudgetGuardConfig.Skipper
+	}
+	if config.SampleRate <= 0 {
+		config.SampleRate = DefaultBudgetGuardConfig.SampleRate
+	}
+	if config.MaxAllocBytes == 0 {
+		config.MaxAllocBytes = DefaultBudgetGuardConfig.MaxAllocBytes
+	}
+	if config.MaxGoroutines <= 0 {
+		config.MaxGoroutines = DefaultBudgetGuardConfig.MaxGoroutines
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultBudgetGuardConfig.CheckInterval
+	}
+	if config.OnExceeded == nil {
+		config.OnExceeded = DefaultBudgetGuardConfig.OnExceeded
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultBudgetGuardConfig.ErrorHandler
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || config.SampleRate < 1 && rand.Float64() >= config.SampleRate {
+				return next(c)
+			}
+
+			start := echo.Now(c)
+			startAllocs, startGoroutines := readBudgetMetrics()
+			var rejected chan bool
+			if config.Reject {
+				ctx, cancel := context.WithCancel(c.Request().Context())
+				defer cancel()
+				c.SetRequest(c.Request().WithContext(ctx))
+				rejected = make(chan bool, 1)
+				stop := make(chan struct{})
+				go watchAllocs(startAllocs, config.MaxAllocBytes, config.CheckInterval, cancel, stop, rejected)
+				defer close(stop)
+				err := next(c)
+				stop <- struct{}{}
+				return finishBudget(c, config, start, startAllocs, startGoroutines, <-rejected, err)
+			}
+			err := next(c)
+			return finishBudget(c, config, start, startAllocs, startGoroutines, false, err)
+		}
+	}
+}
+
+// finishBudget reports request over the budget and returns error of the request.
+func finishBudget(c echo.Context, config BudgetGuardConfig, start time.Time, startAllocs uint64, startGoroutines int64,
+	rejected bool, err error) error {
+	allocs, goroutines := readBudgetMetrics()
+	report := BudgetReport{
+		Method:     c.Request().Method,
+		Path:       c.Path(),
+		AllocBytes: allocs - startAllocs,
+		Goroutines: goroutines - startGoroutines,
+		Duration:   echo.Now(c).Sub(start),
+		Rejected:   rejected,
+	}
+	if rejected || report.AllocBytes > config.MaxAllocBytes || report.Goroutines > config.MaxGoroutines {
+		config.OnExceeded(c, report)
+	}
+	if rejected && !c.Response().Committed {
+		return config.ErrorHandler(c, ErrBudgetExceeded)
+	}
+	return err
+}
+
+// watchAllocs cancels request when bytes allocated since start exceed the limit. Result is sent to rejected when
+// stop receives value.
+func watchAllocs(startAllocs, limit uint64, interval time.Duration, cancel func(), stop chan struct{},
+	rejected chan<- bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	exceeded := false
+	for {
+		select {
+		case <-stop:
+			rejected <- exceeded
+			return
+		case <-ticker.C:
+			if exceeded {
+				continue
+			}
+			if allocs, _ := readBudgetMetrics(); allocs-startAllocs > limit {
+				exceeded = true
+				cancel()
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/stretchr/testify/assert"
+)
+
+var budgetSink [][]byte
+
+func TestBudgetGuard(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		config         BudgetGuardConfig
+		handler        echo.HandlerFunc
+		expectStatus   int
+		expectReport   bool
+		expectRejected bool
+	}{
+		{
+			name:   "within budget",
+			config: BudgetGuardConfig{SampleRate: 1},
+			handler: func(c echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:   "allocations over budget",
+			config: BudgetGuardConfig{SampleRate: 1, MaxAllocBytes: 1 << 20},
+			handler: func(c echo.Context) error {
+				budgetSink = append(budgetSink[:0], make([]byte, 4<<20))
+				return c.String(http.StatusOK, "ok")
+			},
+			expectStatus: http.StatusOK,
+			expectReport: true,
+		},
+		{
+			name:   "goroutines left running",
+			config: BudgetGuardConfig{SampleRate: 1, MaxGoroutines: 3},
+			handler: func(c echo.Context) error {
+				for i := 0; i < 10; i++ {
+					go func() {
+						time.Sleep(500 * time.Millisecond)
+					}()
+				}
+				return c.String(http.StatusOK, "ok")
+			},
+			expectStatus: http.StatusOK,
+			expectReport: true,
+		},
+		{
+			name:   "rejected while running",
+			config: BudgetGuardConfig{SampleRate: 1, MaxAllocBytes: 1 << 20, Reject: true, CheckInterval: time.Millisecond},
+			handler: func(c echo.Context) error {
+				ctx := c.Request().Context()
+				for i := 0; i < 10000; i++ {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					default:
+					}
+					budgetSink = append(budgetSink, make([]byte, 64<<10))
+					time.Sleep(100 * time.Microsecond)
+				}
+				return c.String(http.StatusOK, "ok")
+			},
+			expectStatus:   http.StatusServiceUnavailable,
+			expectReport:   true,
+			expectRejected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				budgetSink = nil
+			}()
+			var report *BudgetReport
+			tc.config.OnExceeded = func(c echo.Context, r BudgetReport) {
+				report = &r
+			}
+			e := echo.New()
+			e.GET("/work", tc.handler, BudgetGuardWithConfig(tc.config))
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/work", nil))
+
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			if !tc.expectReport {
+				assert.Nil(t, report)
+				return
+			}
+			if assert.NotNil(t, report) {
+				assert.Equal(t, "/work", report.Path)
+				assert.Equal(t, http.MethodGet, report.Method)
+				assert.Equal(t, tc.expectRejected, report.Rejected)
+			}
+		})
+	}
+}
+
+func TestBudgetGuard_notSampled(t *testing.T) {
+	called := false
+	mw := BudgetGuardWithConfig(BudgetGuardConfig{
+		SampleRate:    0.000001,
+		MaxAllocBytes: 1,
+		OnExceeded: func(c echo.Context, r BudgetReport) {
+			called = true
+		},
+	})
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	err := mw(func(c echo.Context) error {
+		return c.String(http.StatusOK, strings.Repeat("a", 1024))
+	})(c)
+	assert.NoError(t, err)
+	assert.False(t, called)
+}
+
+func TestBudgetGuard_defaultOnExceededLogs(t *testing.T) {
+	e := echo.New()
+	buf := new(bytes.Buffer)
+	e.Logger.SetOutput(buf)
+	e.Logger.SetLevel(log.WARN)
+	e.GET("/", func(c echo.Context) error {
+		budgetSink = append(budgetSink[:0], make([]byte, 2<<20))
+		return c.NoContent(http.StatusOK)
+	}, BudgetGuardWithConfig(BudgetGuardConfig{SampleRate: 1, MaxAllocBytes: 1 << 20}))
+	defer func() {
+		budgetSink = nil
+	}()
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, buf.String(), `"message":"request exceeded resource budget"`)
+}
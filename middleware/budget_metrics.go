@@ -0,0 +1,23 @@
+// +build go1.16
+
+package middleware
+
+import "runtime/metrics"
+
+const (
+	metricHeapAllocs = "/gc/heap/allocs:bytes"
+	metricGoroutines = "/sched/goroutines:goroutines"
+)
+
+// readBudgetMetrics returns cumulative bytes allocated and number of goroutines of the process.
+func readBudgetMetrics() (allocs uint64, goroutines int64) {
+	samples := []metrics.Sample{{Name: metricHeapAllocs}, {Name: metricGoroutines}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		allocs = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		goroutines = int64(samples[1].Value.Uint64())
+	}
+	return allocs, goroutines
+}
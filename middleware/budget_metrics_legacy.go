@@ -0,0 +1,13 @@
+// +build !go1.16
+
+package middleware
+
+import "runtime"
+
+// readBudgetMetrics returns cumulative bytes allocated and number of goroutines of the process. `runtime/metrics`
+// is not available before Go 1.16, `runtime.ReadMemStats()` stops the world so keep sample rate low.
+func readBudgetMetrics() (allocs uint64, goroutines int64) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.TotalAlloc, int64(runtime.NumGoroutine())
+}
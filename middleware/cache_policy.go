@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// CachePolicyConfig defines the config for CachePolicy middleware.
+	CachePolicyConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Default is cache policy applied to responses of routes without policy declared with
+		// `Route#CachePolicy()`.
+		// Optional. Default value nil (no `Cache-Control` header).
+		Default *echo.CacheControl
+
+		// ErrorPolicy is cache policy applied to error responses (status code 400 and above). Route and default
+		// policies are applied only to successful responses so errors are not cached for the lifetime of the content.
+		// Optional. Default value nil (no `Cache-Control` header).
+		ErrorPolicy *echo.CacheControl
+	}
+)
+
+var (
+	// DefaultCachePolicyConfig is the default CachePolicy middleware config.
+	DefaultCachePolicyConfig = CachePolicyConfig{
+		Skipper: DefaultSkipper,
+	}
+)
+
+// CachePolicy returns a middleware which sets `Cache-Control` response header from cache policy of the matched
+// route (see `Route#CachePolicy()`). Header set by the handler itself is never overwritten.
+//
+// Example:
+//
+//	e.Use(middleware.CachePolicy())
+//	e.GET("/products", listProducts).CachePolicy(echo.NewCacheControl().Public().MaxAge(5 * time.Minute))
+func CachePolicy() echo.MiddlewareFunc {
+	return CachePolicyWithConfig(DefaultCachePolicyConfig)
+}
+
+// CachePolicyWithConfig returns a CachePolicy middleware with config.
+// See: `CachePolicy()`.
+func CachePolicyWithConfig(config CachePolicyConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultCachePolicyConfig.Skipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			policy := config.Default
+			if r := echo.RouteOf(c); r != nil {
+				if p, ok := r.GetMeta(echo.MetaCachePolicy).(*echo.CacheControl); ok {
+					policy = p
+				}
+			}
+
+			res := c.Response()
+			res.Before(func() {
+				if res.Header().Get(echo.HeaderCacheControl) != "" {
+					return
+				}
+				p := policy
+				if res.Status >= http.StatusBadRequest {
+					p = config.ErrorPolicy
+				}
+				if p != nil {
+					p.Apply(res.Header())
+				}
+			})
+			return next(c)
+		}
+	}
+}
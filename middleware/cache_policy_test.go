@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachePolicy(t *testing.T) {
+	e := echo.New()
+	e.Use(CachePolicyWithConfig(CachePolicyConfig{
+		Default:     echo.NewCacheControl().NoCache(),
+		ErrorPolicy: echo.NewCacheControl().NoStore(),
+	}))
+	e.GET("/products", func(c echo.Context) error {
+		return c.String(http.StatusOK, "products")
+	}).CachePolicy(echo.NewCacheControl().Public().MaxAge(5 * time.Minute))
+	e.GET("/products/:id", func(c echo.Context) error {
+		return echo.ErrNotFound
+	}).CachePolicy(echo.NewCacheControl().Public().MaxAge(5 * time.Minute))
+	e.GET("/session", func(c echo.Context) error {
+		echo.CacheControlOf(c).Private().NoStore()
+		return c.String(http.StatusOK, "session")
+	}).CachePolicy(echo.NewCacheControl().Public().MaxAge(5 * time.Minute))
+	e.GET("/other", func(c echo.Context) error {
+		return c.String(http.StatusOK, "other")
+	})
+
+	var testCases = []struct {
+		name         string
+		path         string
+		expectHeader string
+	}{
+		{
+			name:         "ok, route policy",
+			path:         "/products",
+			expectHeader: "public, max-age=300",
+		},
+		{
+			name:         "ok, error policy for error response",
+			path:         "/products/1",
+			expectHeader: "no-store",
+		},
+		{
+			name:         "ok, header set by handler is kept",
+			path:         "/session",
+			expectHeader: "private, no-store",
+		},
+		{
+			name:         "ok, default policy for route without policy",
+			path:         "/other",
+			expectHeader: "no-cache",
+		},
+		{
+			name:         "ok, error policy for unknown route",
+			path:         "/unknown",
+			expectHeader: "no-store",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.expectHeader, rec.Header().Get(echo.HeaderCacheControl))
+		})
+	}
+}
+
+func TestCachePolicy_noPolicy(t *testing.T) {
+	e := echo.New()
+	e.Use(CachePolicy())
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "", rec.Header().Get(echo.HeaderCacheControl))
+}
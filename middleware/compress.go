@@ -44,7 +44,7 @@ var (
 )
 
 // Gzip returns a middleware which compresses HTTP response using gzip compression
-// scheme.
+// scheme. Compression of single route can be disabled with `Route#Compress(false)`.
 func Gzip() echo.MiddlewareFunc {
 	return GzipWithConfig(DefaultGzipConfig)
 }
@@ -67,6 +67,11 @@ func GzipWithConfig(config GzipConfig) echo.MiddlewareFunc {
 			if config.Skipper(c) {
 				return next(c)
 			}
+			if r := echo.RouteOf(c); r != nil {
+				if enabled, ok := r.GetMeta(echo.MetaCompress).(bool); ok && !enabled {
+					return next(c)
+				}
+			}
 
 			res := c.Response()
 			res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
@@ -114,7 +119,14 @@ func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
+// Flush writes compressed data buffered so far to the client. Flushing before anything is written still sends
+// gzip header so streaming responses (e.g. SSE) can start without waiting for the first event.
 func (w *gzipResponseWriter) Flush() {
+	if !w.wroteBody {
+		// compressed stream is started now, response must not be reverted to uncompressed one
+		w.Header().Del(echo.HeaderContentLength)
+		w.wroteBody = true
+	}
 	w.Writer.(*gzip.Writer).Flush()
 	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
 		flusher.Flush()
@@ -122,7 +134,19 @@ func (w *gzipResponseWriter) Flush() {
 }
 
 func (w *gzipResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	return w.ResponseWriter.(http.Hijacker).Hijack()
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// CloseNotify implements the deprecated http.CloseNotifier interface for handlers still relying on it. Returned
+// channel never receives when underlying writer does not support it.
+func (w *gzipResponseWriter) CloseNotify() <-chan bool {
+	if cn, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return cn.CloseNotify()
+	}
+	return make(chan bool)
 }
 
 func (w *gzipResponseWriter) Push(target string, opts *http.PushOptions) error {
@@ -90,6 +90,30 @@ func TestGzip(t *testing.T) {
 	assert.Equal("test", buf.String())
 }
 
+func TestGzip_routeDisabled(t *testing.T) {
+	e := echo.New()
+	e.Use(Gzip())
+	e.GET("/archive", func(c echo.Context) error {
+		return c.String(http.StatusOK, "test")
+	}).Compress(false)
+	e.GET("/text", func(c echo.Context) error {
+		return c.String(http.StatusOK, "test")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/archive", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, gzipScheme)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Equal(t, "test", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/text", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, gzipScheme)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, gzipScheme, rec.Header().Get(echo.HeaderContentEncoding))
+}
+
 func TestGzipNoContent(t *testing.T) {
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -205,3 +229,65 @@ func BenchmarkGzip(b *testing.B) {
 		h(c)
 	}
 }
+
+type closeNotifyRecorder struct {
+	*httptest.ResponseRecorder
+	closed chan bool
+}
+
+func (r *closeNotifyRecorder) CloseNotify() <-chan bool {
+	return r.closed
+}
+
+func TestGzip_flushBeforeWrite(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, gzipScheme)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := Gzip()(func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+		c.Response().Header().Set(echo.HeaderContentLength, "100")
+		c.Response().WriteHeader(http.StatusOK)
+		c.Response().Flush()
+
+		assert.True(t, rec.Flushed)
+		assert.Equal(t, gzipScheme, rec.Header().Get(echo.HeaderContentEncoding))
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentLength))
+		return nil
+	})(c)
+	assert.NoError(t, err)
+
+	// response stays valid (empty) gzip stream
+	r, err := gzip.NewReader(rec.Body)
+	if assert.NoError(t, err) {
+		body, err := ioutil.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Empty(t, body)
+	}
+}
+
+func TestGzip_writerInterfaces(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, gzipScheme)
+	closed := make(chan bool, 1)
+	rec := &closeNotifyRecorder{ResponseRecorder: httptest.NewRecorder(), closed: closed}
+	c := e.NewContext(req, rec)
+
+	err := Gzip()(func(c echo.Context) error {
+		w := c.Response().Writer
+
+		cn, ok := w.(http.CloseNotifier)
+		if assert.True(t, ok) {
+			closed <- true
+			assert.True(t, <-cn.CloseNotify())
+		}
+
+		_, _, err := w.(http.Hijacker).Hijack()
+		assert.Equal(t, http.ErrNotSupported, err)
+		return nil
+	})(c)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// ContentTypeConfig defines the config for ContentType middleware.
+	ContentTypeConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Allowed are request content types accepted by routes not declaring their own with `Route#ContentTypes()`
+		// or `Group.ContentTypes`. Type matches also types with its structured syntax suffix (`application/json`
+		// matches `application/vnd.api+json`), `type/*` matches all subtypes. Parameters are ignored.
+		// Required.
+		Allowed []string `yaml:"allowed"`
+
+		// ErrorHandler is called with ErrUnsupportedMediaType when request content type is not allowed.
+		// Optional. Default value returns the error.
+		ErrorHandler func(c echo.Context, err error) error
+	}
+)
+
+// DefaultContentTypeConfig is the default ContentType middleware config.
+var DefaultContentTypeConfig = ContentTypeConfig{
+	Skipper: DefaultSkipper,
+	ErrorHandler: func(c echo.Context, err error) error {
+		return err
+	},
+}
+
+// ContentType returns a middleware that rejects requests with body of content type other than allowed with 415
+// Unsupported Media Type, so handlers do not parse unintended formats (i.e. form instead of JSON). Requests without
+// body are not checked. Routes and groups may accept other content types with `Route#ContentTypes()` and
+// `Group.ContentTypes`.
+//
+// Example:
+//
+//	e.Use(middleware.ContentType(echo.MIMEApplicationJSON))
+//	e.POST("/avatars", uploadAvatar).ContentTypes("image/png", "image/jpeg")
+func ContentType(allowed ...string) echo.MiddlewareFunc {
+	c := DefaultContentTypeConfig
+	c.Allowed = allowed
+	return ContentTypeWithConfig(c)
+}
+
+// ContentTypeWithConfig returns a ContentType middleware with config.
+// See: `ContentType()`.
+func ContentTypeWithConfig(config ContentTypeConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultContentTypeConfig.Skipper
+	}
+	if len(config.Allowed) == 0 {
+		panic("echo: content-type middleware requires allowed content types")
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultContentTypeConfig.ErrorHandler
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			if req.ContentLength == 0 {
+				return next(c)
+			}
+			allowed := echo.ContentTypesOf(c)
+			if allowed == nil {
+				allowed = config.Allowed
+			}
+			mediaType, _, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+			if err == nil && contentTypeAllowed(mediaType, allowed) {
+				return next(c)
+			}
+			return config.ErrorHandler(c, echo.ErrUnsupportedMediaType)
+		}
+	}
+}
+
+// contentTypeAllowed reports whether media type (lower case, without parameters) matches any of allowed types.
+func contentTypeAllowed(mediaType string, allowed []string) bool {
+	typ, subtype := splitMediaType(mediaType)
+	suffix := ""
+	if i := strings.LastIndexByte(subtype, '+'); i >= 0 {
+		suffix = subtype[i+1:]
+	}
+	for _, a := range allowed {
+		if i := strings.IndexByte(a, ';'); i >= 0 {
+			a = a[:i]
+		}
+		aTyp, aSubtype := splitMediaType(strings.ToLower(strings.TrimSpace(a)))
+		if aTyp == "*" || aTyp == typ && (aSubtype == "*" || aSubtype == subtype || suffix != "" && aSubtype == suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitMediaType(mediaType string) (string, string) {
+	if i := strings.IndexByte(mediaType, '/'); i >= 0 {
+		return mediaType[:i], mediaType[i+1:]
+	}
+	return mediaType, ""
+}
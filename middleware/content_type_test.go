@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentType(t *testing.T) {
+	e := echo.New()
+	e.Use(ContentType(echo.MIMEApplicationJSON))
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}
+	e.POST("/users", handler)
+	e.POST("/avatars", handler).ContentTypes("image/*")
+	admin := e.Group("/admin")
+	admin.ContentTypes = []string{echo.MIMEApplicationForm}
+	admin.POST("/settings", handler)
+	admin.POST("/import", handler).ContentTypes("text/csv")
+
+	var testCases = []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		expectCode  int
+	}{
+		{
+			name:        "ok, allowed",
+			path:        "/users",
+			contentType: echo.MIMEApplicationJSONCharsetUTF8,
+			body:        "{}",
+			expectCode:  http.StatusNoContent,
+		},
+		{
+			name:        "ok, structured syntax suffix",
+			path:        "/users",
+			contentType: "application/vnd.api+json",
+			body:        "{}",
+			expectCode:  http.StatusNoContent,
+		},
+		{
+			name:       "ok, no body",
+			path:       "/users",
+			expectCode: http.StatusNoContent,
+		},
+		{
+			name:        "nok, not allowed",
+			path:        "/users",
+			contentType: echo.MIMEApplicationForm,
+			body:        "a=b",
+			expectCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "nok, missing content type",
+			path:       "/users",
+			body:       "{}",
+			expectCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "nok, suffix of other type",
+			path:        "/users",
+			contentType: "text/vnd.api+json",
+			body:        "{}",
+			expectCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "ok, route wildcard",
+			path:        "/avatars",
+			contentType: "image/png",
+			body:        "png",
+			expectCode:  http.StatusNoContent,
+		},
+		{
+			name:        "nok, route overrides config",
+			path:        "/avatars",
+			contentType: echo.MIMEApplicationJSON,
+			body:        "{}",
+			expectCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "ok, group",
+			path:        "/admin/settings",
+			contentType: echo.MIMEApplicationForm,
+			body:        "a=b",
+			expectCode:  http.StatusNoContent,
+		},
+		{
+			name:        "nok, group overrides config",
+			path:        "/admin/settings",
+			contentType: echo.MIMEApplicationJSON,
+			body:        "{}",
+			expectCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "ok, route overrides group",
+			path:        "/admin/import",
+			contentType: "text/csv",
+			body:        "a,b",
+			expectCode:  http.StatusNoContent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, tc.contentType)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectCode, rec.Code)
+		})
+	}
+}
+
+func TestContentTypeWithConfig_panicsWithoutAllowed(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: content-type middleware requires allowed content types", func() {
+		ContentTypeWithConfig(ContentTypeConfig{})
+	})
+}
@@ -28,7 +28,8 @@ type (
 
 		// AllowMethods defines a list methods allowed when accessing the resource.
 		// This is used in response to a preflight request.
-		// Optional. Default value DefaultCORSConfig.AllowMethods.
+		// Optional. When not set, methods registered by router for the requested path are used (see
+		// `echo.AllowedMethods()`) and DefaultCORSConfig.AllowMethods for paths with OPTIONS route.
 		AllowMethods []string `yaml:"allow_methods"`
 
 		// AllowHeaders defines a list of request headers that can be used when
@@ -52,6 +53,11 @@ type (
 		// can be cached.
 		// Optional. Default value 0.
 		MaxAge int `yaml:"max_age"`
+
+		// PassPreflight passes preflight requests to the next handler (OPTIONS route) after CORS headers are set
+		// instead of answering them with 204 No Content. Use it for custom preflight responses.
+		// Optional. Default value false.
+		PassPreflight bool `yaml:"pass_preflight"`
 	}
 )
 
@@ -80,7 +86,9 @@ func CORSWithConfig(config CORSConfig) echo.MiddlewareFunc {
 	if len(config.AllowOrigins) == 0 {
 		config.AllowOrigins = DefaultCORSConfig.AllowOrigins
 	}
+	hasCustomAllowMethods := true
 	if len(config.AllowMethods) == 0 {
+		hasCustomAllowMethods = false
 		config.AllowMethods = DefaultCORSConfig.AllowMethods
 	}
 
@@ -190,7 +198,11 @@ func CORSWithConfig(config CORSConfig) echo.MiddlewareFunc {
 			res.Header().Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
 			res.Header().Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
 			res.Header().Set(echo.HeaderAccessControlAllowOrigin, allowOrigin)
-			res.Header().Set(echo.HeaderAccessControlAllowMethods, allowMethods)
+			if routerAllowMethods := echo.AllowedMethods(c); routerAllowMethods != "" && !hasCustomAllowMethods {
+				res.Header().Set(echo.HeaderAccessControlAllowMethods, routerAllowMethods)
+			} else {
+				res.Header().Set(echo.HeaderAccessControlAllowMethods, allowMethods)
+			}
 			if config.AllowCredentials {
 				res.Header().Set(echo.HeaderAccessControlAllowCredentials, "true")
 			}
@@ -205,6 +217,9 @@ func CORSWithConfig(config CORSConfig) echo.MiddlewareFunc {
 			if config.MaxAge > 0 {
 				res.Header().Set(echo.HeaderAccessControlMaxAge, maxAge)
 			}
+			if config.PassPreflight {
+				return next(c)
+			}
 			return c.NoContent(http.StatusNoContent)
 		}
 	}
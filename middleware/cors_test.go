@@ -407,3 +407,42 @@ func Test_allowOriginFunc(t *testing.T) {
 		}
 	}
 }
+
+func TestCORSPreflightWithRouterAllowMethods(t *testing.T) {
+	e := echo.New()
+	e.Use(CORSWithConfig(CORSConfig{AllowOrigins: []string{"http://example.com"}}))
+	e.GET("/users", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.POST("/users", func(c echo.Context) error { return c.NoContent(http.StatusCreated) })
+
+	req := httptest.NewRequest(http.MethodOptions, "/users", nil)
+	req.Header.Set(echo.HeaderOrigin, "http://example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPost)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "http://example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "GET, POST", rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+}
+
+func TestCORSPassPreflight(t *testing.T) {
+	e := echo.New()
+	e.Use(CORSWithConfig(CORSConfig{
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{http.MethodGet},
+		PassPreflight: true,
+	}))
+	e.OPTIONS("/users", func(c echo.Context) error {
+		return c.String(http.StatusOK, "custom")
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/users", nil)
+	req.Header.Set(echo.HeaderOrigin, "http://example.com")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "custom", rec.Body.String())
+	assert.Equal(t, "*", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, http.MethodGet, rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+}
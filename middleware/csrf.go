@@ -168,7 +168,7 @@ func CSRFWithConfig(config CSRFConfig) echo.MiddlewareFunc {
 			if config.CookieSameSite != http.SameSiteDefaultMode {
 				cookie.SameSite = config.CookieSameSite
 			}
-			cookie.Expires = time.Now().Add(time.Duration(config.CookieMaxAge) * time.Second)
+			cookie.Expires = echo.Now(c).Add(time.Duration(config.CookieMaxAge) * time.Second)
 			cookie.Secure = config.CookieSecure
 			cookie.HttpOnly = config.CookieHTTPOnly
 			c.SetCookie(cookie)
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// DeadlineConfig defines the config for Deadline middleware.
+	DeadlineConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Headers are request headers with timeout budget of the client, first header present in request is used.
+		// `X-Request-Timeout` value is number of milliseconds or duration (`1.5s`), `Grpc-Timeout` value is in
+		// gRPC format (`100m`, `2S`). Invalid values are ignored.
+		// Optional. Default value []string{echo.HeaderXRequestTimeout, echo.HeaderGrpcTimeout}.
+		Headers []string
+
+		// MaxTimeout caps timeout requested by client.
+		// Optional. Default value 0 (not capped).
+		MaxTimeout time.Duration `yaml:"max_timeout"`
+
+		// DefaultTimeout is used when request does not contain timeout header.
+		// Optional. Default value 0 (no deadline is set).
+		DefaultTimeout time.Duration `yaml:"default_timeout"`
+	}
+)
+
+// ErrDeadlineExceeded denotes an error raised when request arrives with timeout budget already spent.
+var ErrDeadlineExceeded = echo.NewHTTPError(http.StatusGatewayTimeout, "request deadline exceeded")
+
+// DefaultDeadlineConfig is the default Deadline middleware config.
+var DefaultDeadlineConfig = DeadlineConfig{
+	Skipper: DefaultSkipper,
+	Headers: []string{echo.HeaderXRequestTimeout, echo.HeaderGrpcTimeout},
+}
+
+// Deadline returns a middleware that reads timeout budget sent by client (`X-Request-Timeout` or `Grpc-Timeout`
+// header) and sets it as deadline of the request context. Handlers and clients using the request context stop
+// when budget is spent and Proxy middleware passes remaining budget to upstream.
+func Deadline() echo.MiddlewareFunc {
+	return DeadlineWithConfig(DefaultDeadlineConfig)
+}
+
+// DeadlineWithConfig returns a Deadline middleware with config.
+// See: `Deadline()`.
+func DeadlineWithConfig(config DeadlineConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultDeadlineConfig.Skipper
+	}
+	if len(config.Headers) == 0 {
+		config.Headers = DefaultDeadlineConfig.Headers
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			timeout, ok := requestTimeout(req.Header, config.Headers)
+			if !ok {
+				timeout = config.DefaultTimeout
+			}
+			if config.MaxTimeout > 0 && timeout > config.MaxTimeout {
+				timeout = config.MaxTimeout
+			}
+			if ok && timeout <= 0 {
+				return ErrDeadlineExceeded
+			}
+			if timeout <= 0 {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// requestTimeout returns timeout from the first of given headers present in request.
+func requestTimeout(header http.Header, names []string) (time.Duration, bool) {
+	for _, name := range names {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+		var timeout time.Duration
+		var err error
+		if http.CanonicalHeaderKey(name) == echo.HeaderGrpcTimeout {
+			timeout, err = parseGrpcTimeout(value)
+		} else {
+			timeout, err = parseRequestTimeout(value)
+		}
+		if err == nil {
+			return timeout, true
+		}
+	}
+	return 0, false
+}
+
+// parseRequestTimeout parses `X-Request-Timeout` value which is number of milliseconds or duration.
+func parseRequestTimeout(value string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// parseGrpcTimeout parses timeout in gRPC format: at most 8 digits followed by unit (H, M, S, m, u, n).
+// See: https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
+func parseGrpcTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, strconv.ErrSyntax
+	}
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, strconv.ErrSyntax
+	}
+	n, err := strconv.ParseUint(value[:len(value)-1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// setDeadlineHeaders replaces timeout headers of request to upstream with time remaining until deadline of the
+// request context. Grpc-Timeout is sent only when client sent it.
+func setDeadlineHeaders(req *http.Request) {
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return
+	}
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+	ms := int64(remaining / time.Millisecond)
+	req.Header.Set(echo.HeaderXRequestTimeout, strconv.FormatInt(ms, 10))
+	if req.Header.Get(echo.HeaderGrpcTimeout) != "" {
+		req.Header.Set(echo.HeaderGrpcTimeout, strconv.FormatInt(ms, 10)+"m")
+	}
+}
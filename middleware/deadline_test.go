@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadlineWithConfig(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		config         DeadlineConfig
+		header         map[string]string
+		expectDeadline time.Duration
+		expectErr      error
+	}{
+		{
+			name:           "ok, milliseconds",
+			header:         map[string]string{echo.HeaderXRequestTimeout: "1500"},
+			expectDeadline: 1500 * time.Millisecond,
+		},
+		{
+			name:           "ok, duration",
+			header:         map[string]string{echo.HeaderXRequestTimeout: "2s"},
+			expectDeadline: 2 * time.Second,
+		},
+		{
+			name:           "ok, grpc timeout",
+			header:         map[string]string{"grpc-timeout": "3S"},
+			expectDeadline: 3 * time.Second,
+		},
+		{
+			name:           "ok, first header wins",
+			header:         map[string]string{echo.HeaderXRequestTimeout: "100", echo.HeaderGrpcTimeout: "3S"},
+			expectDeadline: 100 * time.Millisecond,
+		},
+		{
+			name:           "ok, capped by max timeout",
+			config:         DeadlineConfig{MaxTimeout: time.Second},
+			header:         map[string]string{echo.HeaderXRequestTimeout: "1h"},
+			expectDeadline: time.Second,
+		},
+		{
+			name:           "ok, default timeout",
+			config:         DeadlineConfig{DefaultTimeout: 5 * time.Second, MaxTimeout: 10 * time.Second},
+			expectDeadline: 5 * time.Second,
+		},
+		{
+			name:           "ok, invalid header is ignored",
+			header:         map[string]string{echo.HeaderGrpcTimeout: "10x"},
+			expectDeadline: 0,
+		},
+		{
+			name:      "nok, budget spent",
+			header:    map[string]string{echo.HeaderXRequestTimeout: "0"},
+			expectErr: ErrDeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			var remaining time.Duration
+			start := time.Now()
+			err := DeadlineWithConfig(tc.config)(func(c echo.Context) error {
+				if deadline, ok := c.Request().Context().Deadline(); ok {
+					remaining = deadline.Sub(start)
+				}
+				return nil
+			})(c)
+
+			assert.Equal(t, tc.expectErr, err)
+			assert.InDelta(t, float64(tc.expectDeadline), float64(remaining), float64(100*time.Millisecond))
+		})
+	}
+}
+
+func TestParseGrpcTimeout(t *testing.T) {
+	var testCases = []struct {
+		value     string
+		expect    time.Duration
+		expectErr bool
+	}{
+		{value: "1H", expect: time.Hour},
+		{value: "2M", expect: 2 * time.Minute},
+		{value: "100m", expect: 100 * time.Millisecond},
+		{value: "5u", expect: 5 * time.Microsecond},
+		{value: "99999999n", expect: 99999999 * time.Nanosecond},
+		{value: "123456789S", expectErr: true},
+		{value: "S", expectErr: true},
+		{value: "-1S", expectErr: true},
+		{value: "10s", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			d, err := parseGrpcTimeout(tc.value)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, d)
+		})
+	}
+}
+
+func TestProxy_propagateDeadline(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get(echo.HeaderXRequestTimeout) + "|" + r.Header.Get(echo.HeaderGrpcTimeout)))
+	}))
+	defer upstream.Close()
+	u, _ := url.Parse(upstream.URL)
+
+	e := echo.New()
+	e.Use(Deadline())
+	e.Use(ProxyWithConfig(ProxyConfig{
+		Balancer:          NewRoundRobinBalancer([]*ProxyTarget{{URL: u}}),
+		PropagateDeadline: true,
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderGrpcTimeout, "2S")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	parts := strings.SplitN(rec.Body.String(), "|", 2)
+	ms, err := strconv.Atoi(parts[0])
+	assert.NoError(t, err)
+	assert.True(t, ms > 1500 && ms <= 2000, "remaining budget is sent to upstream, got %d", ms)
+	assert.Equal(t, parts[0]+"m", parts[1])
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// DeprecationConfig defines the config for Deprecation middleware.
+	DeprecationConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Caller returns identity of the caller logged with usage of deprecated route, i.e. API key or client ID.
+		// Optional. Default value returns `Context#RealIP()`.
+		Caller func(c echo.Context) string
+
+		// OnUse is called for every request to deprecated route.
+		// Optional. Default value logs warning with `Context#Logger()`.
+		OnUse func(c echo.Context, deprecation *echo.RouteDeprecation, caller string)
+
+		// RejectAfterSunset rejects requests to deprecated route after its sunset time with ErrRouteSunset.
+		// Optional. Default value false.
+		RejectAfterSunset bool `yaml:"reject_after_sunset"`
+	}
+)
+
+var (
+	// ErrRouteSunset denotes an error raised when deprecated route is requested after its sunset time.
+	ErrRouteSunset = echo.NewHTTPError(http.StatusGone, "route is no longer available")
+
+	// DefaultDeprecationConfig is the default Deprecation middleware config.
+	DefaultDeprecationConfig = DeprecationConfig{
+		Skipper: DefaultSkipper,
+		Caller: func(c echo.Context) string {
+			return c.RealIP()
+		},
+		OnUse: func(c echo.Context, d *echo.RouteDeprecation, caller string) {
+			c.Logger().Warnf("deprecated route %s %s used by %s", c.Request().Method, c.Path(), caller)
+		},
+	}
+)
+
+// Deprecation returns a middleware which announces deprecation of the matched route (see `Route#Deprecated()`)
+// with `Deprecation`, `Sunset` (RFC 8594) and `Link` response headers and reports usage of deprecated routes.
+//
+// Example:
+//
+//	e.Use(middleware.Deprecation())
+//	e.GET("/v1/users", listUsersV1).Deprecated(sunset, "https://example.com/migrate-v2")
+func Deprecation() echo.MiddlewareFunc {
+	return DeprecationWithConfig(DefaultDeprecationConfig)
+}
+
+// DeprecationWithConfig returns a Deprecation middleware with config.
+// See: `Deprecation()`.
+func DeprecationWithConfig(config DeprecationConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultDeprecationConfig.Skipper
+	}
+	if config.Caller == nil {
+		config.Caller = DefaultDeprecationConfig.Caller
+	}
+	if config.OnUse == nil {
+		config.OnUse = DefaultDeprecationConfig.OnUse
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			r := echo.RouteOf(c)
+			if r == nil {
+				return next(c)
+			}
+			d, ok := r.GetMeta(echo.MetaDeprecation).(*echo.RouteDeprecation)
+			if !ok {
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set(echo.HeaderDeprecation, "true")
+			if !d.Sunset.IsZero() {
+				h.Set(echo.HeaderSunset, d.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if d.Link != "" {
+				h.Add(echo.HeaderLink, "<"+d.Link+`>; rel="deprecation"`)
+			}
+			config.OnUse(c, d, config.Caller(c))
+
+			if config.RejectAfterSunset && !d.Sunset.IsZero() && !echo.Now(c).Before(d.Sunset) {
+				return ErrRouteSunset
+			}
+			return next(c)
+		}
+	}
+}
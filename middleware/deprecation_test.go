@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/labstack/gommon/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecation(t *testing.T) {
+	sunset := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock := echotest.NewClock(sunset.Add(-time.Hour))
+
+	e := echo.New()
+	e.Clock = clock
+	var used []string
+	e.Use(DeprecationWithConfig(DeprecationConfig{
+		Caller: func(c echo.Context) string {
+			return c.Request().Header.Get("X-API-Key")
+		},
+		OnUse: func(c echo.Context, d *echo.RouteDeprecation, caller string) {
+			used = append(used, c.Path()+" "+caller)
+		},
+		RejectAfterSunset: true,
+	}))
+	e.GET("/v1/users", func(c echo.Context) error {
+		return c.String(http.StatusOK, "v1")
+	}).Deprecated(sunset, "https://example.com/migrate")
+	e.GET("/v1/legacy", func(c echo.Context) error {
+		return c.String(http.StatusOK, "legacy")
+	}).Deprecated(time.Time{}, "")
+	e.GET("/v2/users", func(c echo.Context) error {
+		return c.String(http.StatusOK, "v2")
+	})
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Key", "client-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("/v1/users")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(echo.HeaderDeprecation))
+	assert.Equal(t, "Tue, 01 Jun 2021 00:00:00 GMT", rec.Header().Get(echo.HeaderSunset))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, rec.Header().Get(echo.HeaderLink))
+
+	rec = request("/v1/legacy")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(echo.HeaderDeprecation))
+	assert.Empty(t, rec.Header().Get(echo.HeaderSunset))
+	assert.Empty(t, rec.Header().Get(echo.HeaderLink))
+
+	rec = request("/v2/users")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderDeprecation))
+
+	clock.Advance(time.Hour)
+	rec = request("/v1/users")
+	assert.Equal(t, http.StatusGone, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(echo.HeaderDeprecation))
+
+	rec = request("/v1/legacy")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Equal(t, []string{
+		"/v1/users client-1",
+		"/v1/legacy client-1",
+		"/v1/users client-1",
+		"/v1/legacy client-1",
+	}, used)
+}
+
+func TestDeprecationDefaultLogsUsage(t *testing.T) {
+	e := echo.New()
+	buf := new(bytes.Buffer)
+	e.Logger.SetOutput(buf)
+	e.Logger.SetLevel(log.WARN)
+	e.Use(Deprecation())
+	e.GET("/old", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}).Deprecated(time.Now().Add(-time.Hour), "")
+
+	req := httptest.NewRequest(http.MethodGet, "/old", nil)
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Contains(t, buf.String(), "deprecated route GET /old used by 203.0.113.1")
+}
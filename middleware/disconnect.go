@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// SkipDisconnectedConfig defines the config for SkipDisconnected middleware.
+	SkipDisconnectedConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// ErrorHandler is called instead of the handler when client has disconnected.
+		// Optional. Default value returns ErrClientDisconnected.
+		ErrorHandler func(c echo.Context) error
+	}
+)
+
+// ErrClientDisconnected denotes an error returned when request is not handled because client closed the connection.
+// Status code 499 is logged by request loggers, the response itself is never received by the client.
+var ErrClientDisconnected = echo.NewHTTPError(StatusCodeContextCanceled, "client closed connection")
+
+// DefaultSkipDisconnectedConfig is the default SkipDisconnected middleware config.
+var DefaultSkipDisconnectedConfig = SkipDisconnectedConfig{
+	Skipper: DefaultSkipper,
+	ErrorHandler: func(c echo.Context) error {
+		return ErrClientDisconnected
+	},
+}
+
+// SkipDisconnected returns a middleware that does not execute the handler when client has already closed the
+// connection (i.e. impatient mobile clients retrying requests waiting in queue), see `echo.Disconnected()`.
+// Register it after middleware that may delay requests (rate limiters, priority queues).
+func SkipDisconnected() echo.MiddlewareFunc {
+	return SkipDisconnectedWithConfig(DefaultSkipDisconnectedConfig)
+}
+
+// SkipDisconnectedWithConfig returns a SkipDisconnected middleware with config.
+// See: `SkipDisconnected()`.
+func SkipDisconnectedWithConfig(config SkipDisconnectedConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultSkipDisconnectedConfig.Skipper
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultSkipDisconnectedConfig.ErrorHandler
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || !echo.Disconnected(c) {
+				return next(c)
+			}
+			return config.ErrorHandler(c)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipDisconnected(t *testing.T) {
+	e := echo.New()
+	e.Use(SkipDisconnected())
+	called := false
+	e.GET("/", func(c echo.Context) error {
+		called = true
+		return c.String(http.StatusOK, "OK")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	called = false
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	assert.False(t, called)
+	assert.Equal(t, StatusCodeContextCanceled, rec.Code)
+}
+
+func TestSkipDisconnectedWithConfig_ErrorHandler(t *testing.T) {
+	e := echo.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := SkipDisconnectedWithConfig(SkipDisconnectedConfig{
+		ErrorHandler: func(c echo.Context) error {
+			return c.NoContent(http.StatusNoContent)
+		},
+	})(func(c echo.Context) error {
+		t.Fatal("handler called")
+		return nil
+	})
+	assert.NoError(t, h(c))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
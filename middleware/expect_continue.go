@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// ExpectContinueConfig defines the config for ExpectContinue middleware.
+	ExpectContinueConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Default is policy applied to routes without policy declared with `Route#ExpectContinue()`.
+		// Optional. Default value nil (all requests may send body).
+		Default *echo.ExpectContinuePolicy
+	}
+)
+
+// DefaultExpectContinueConfig is the default ExpectContinue middleware config.
+var DefaultExpectContinueConfig = ExpectContinueConfig{
+	Skipper: DefaultSkipper,
+}
+
+// ExpectContinue returns a middleware which applies `Expect: 100-continue` policy of the matched route (see
+// `Route#ExpectContinue()`) to requests expecting 100-continue. Rejected requests are answered with final status
+// before client sends the body and connection is closed. Middleware must be added before any middleware reading the
+// request body.
+//
+// Example:
+//
+//	e.Use(middleware.ExpectContinue())
+//	e.PUT("/uploads/:name", upload).ExpectContinue(echo.ExpectContinuePolicy{
+//		MaxContentLength: 1 << 30,
+//		Check: func(c echo.Context) error {
+//			if !canUpload(c) {
+//				return echo.ErrForbidden
+//			}
+//			return nil
+//		},
+//	})
+func ExpectContinue() echo.MiddlewareFunc {
+	return ExpectContinueWithConfig(DefaultExpectContinueConfig)
+}
+
+// ExpectContinueWithConfig returns a ExpectContinue middleware with config.
+// See: `ExpectContinue()`.
+func ExpectContinueWithConfig(config ExpectContinueConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultExpectContinueConfig.Skipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			req := c.Request()
+			if !strings.EqualFold(req.Header.Get(echo.HeaderExpect), "100-continue") {
+				return next(c)
+			}
+
+			policy := config.Default
+			if r := echo.RouteOf(c); r != nil {
+				if p, ok := r.GetMeta(echo.MetaExpectContinue).(*echo.ExpectContinuePolicy); ok {
+					policy = p
+				}
+			}
+			if policy == nil {
+				return next(c)
+			}
+
+			var err error
+			switch {
+			case policy.Reject:
+				err = echo.ErrExpectationFailed
+			case policy.MaxContentLength > 0 && req.ContentLength > policy.MaxContentLength:
+				err = echo.ErrStatusRequestEntityTooLarge
+			case policy.Check != nil:
+				err = policy.Check(c)
+			}
+			if err != nil {
+				// body was not sent by client, connection can not be reused
+				c.Response().Header().Set(echo.HeaderConnection, "close")
+				return err
+			}
+			return next(c)
+		}
+	}
+}
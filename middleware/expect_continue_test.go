@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectContinue(t *testing.T) {
+	e := echo.New()
+	e.Use(ExpectContinueWithConfig(ExpectContinueConfig{
+		Default: &echo.ExpectContinuePolicy{MaxContentLength: 100},
+	}))
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	}
+	e.PUT("/uploads", handler).ExpectContinue(echo.ExpectContinuePolicy{
+		MaxContentLength: 1000,
+		Check: func(c echo.Context) error {
+			if c.Request().Header.Get(echo.HeaderAuthorization) == "" {
+				return echo.ErrUnauthorized
+			}
+			return nil
+		},
+	})
+	e.PUT("/readonly", handler).ExpectContinue(echo.ExpectContinuePolicy{Reject: true})
+	e.PUT("/default", handler)
+
+	var testCases = []struct {
+		name          string
+		whenPath      string
+		whenExpect    string
+		whenLength    int64
+		whenAuth      string
+		expectCode    int
+		expectConnect string
+	}{
+		{name: "allowed", whenPath: "/uploads", whenExpect: "100-continue", whenLength: 500, whenAuth: "Bearer x", expectCode: http.StatusCreated},
+		{name: "too large", whenPath: "/uploads", whenExpect: "100-continue", whenLength: 5000, whenAuth: "Bearer x", expectCode: http.StatusRequestEntityTooLarge, expectConnect: "close"},
+		{name: "check fails", whenPath: "/uploads", whenExpect: "100-Continue", whenLength: 500, expectCode: http.StatusUnauthorized, expectConnect: "close"},
+		{name: "rejected route", whenPath: "/readonly", whenExpect: "100-continue", whenLength: 1, expectCode: http.StatusExpectationFailed, expectConnect: "close"},
+		{name: "default policy", whenPath: "/default", whenExpect: "100-continue", whenLength: 500, expectCode: http.StatusRequestEntityTooLarge, expectConnect: "close"},
+		{name: "without expect", whenPath: "/readonly", whenLength: 1, expectCode: http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, tc.whenPath, strings.NewReader(strings.Repeat("x", int(tc.whenLength))))
+			if tc.whenExpect != "" {
+				req.Header.Set(echo.HeaderExpect, tc.whenExpect)
+			}
+			if tc.whenAuth != "" {
+				req.Header.Set(echo.HeaderAuthorization, tc.whenAuth)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.Equal(t, tc.expectConnect, rec.Header().Get(echo.HeaderConnection))
+		})
+	}
+}
+
+func TestExpectContinueRejectsBeforeBodyIsSent(t *testing.T) {
+	e := echo.New()
+	e.Use(ExpectContinue())
+	e.PUT("/uploads", func(c echo.Context) error {
+		return errors.New("handler must not be called")
+	}).ExpectContinue(echo.ExpectContinuePolicy{MaxContentLength: 10})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("PUT /uploads HTTP/1.1\r\nHost: example.com\r\nContent-Length: 1000000\r\nExpect: 100-continue\r\n\r\n"))
+	require.NoError(t, err)
+
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	assert.True(t, res.Close)
+}
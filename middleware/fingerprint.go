@@ -0,0 +1,281 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// FingerprintConfig defines the config for Fingerprint middleware.
+	FingerprintConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// ClientHellos provides TLS ClientHello of the connection for TLS fingerprint. Without it (or for plain
+		// HTTP connections) fingerprint is computed only from request headers.
+		// Optional.
+		ClientHellos *ClientHelloRecorder
+
+		// Headers are names of request headers whose values are part of the fingerprint.
+		// Optional. Default value "User-Agent", "Accept", "Accept-Language" and "Accept-Encoding".
+		Headers []string
+
+		// IPv4Prefix is number of leading bits of client IPv4 address included in the fingerprint. Value 0
+		// excludes the address. Keeping only network prefix (i.e. 24) does not identify single client.
+		// Optional.
+		IPv4Prefix int
+
+		// IPv6Prefix is number of leading bits of client IPv6 address included in the fingerprint. Value 0
+		// excludes the address.
+		// Optional.
+		IPv6Prefix int
+
+		// Hasher hashes fingerprint components. Use keyed hash (i.e. HMAC with server secret) so fingerprints can
+		// not be compared across services or brute forced back to header values.
+		// Optional. Default value SHA-256.
+		Hasher func(data []byte) []byte
+
+		// Size is number of bytes of hash kept in fingerprint. Shorter fingerprints are less unique and reveal less
+		// about the client.
+		// Optional. Default value 16.
+		Size int
+
+		// ContextKey is key under which `*ClientFingerprint` is stored in context.
+		// Optional. Default value "fingerprint".
+		ContextKey string
+	}
+
+	// ClientFingerprint is fingerprint of the client sending the request. All values are hex encoded hashes,
+	// empty when component is not available.
+	ClientFingerprint struct {
+		// ID combines all other components.
+		ID string `json:"id"`
+		// TLS is hash of TLS ClientHello parameters.
+		TLS string `json:"tls,omitempty"`
+		// Headers is hash of names of sent headers and values of configured headers.
+		Headers string `json:"headers"`
+		// UserAgent is hash of User-Agent header.
+		UserAgent string `json:"user_agent,omitempty"`
+	}
+
+	// ClientHelloRecorder records TLS ClientHello of connections so Fingerprint middleware can compute TLS
+	// fingerprint of requests. Install it with `TLSConfig` and `ConnState`:
+	//
+	//	hellos := middleware.NewClientHelloRecorder()
+	//	e.TLSConfig = hellos.TLSConfig(e.TLSConfig)
+	//	e.TLSServer.ConnState = hellos.ConnState
+	//	e.Use(middleware.FingerprintWithConfig(middleware.FingerprintConfig{ClientHellos: hellos}))
+	ClientHelloRecorder struct {
+		hellos sync.Map // remote address => string
+	}
+)
+
+// DefaultFingerprintConfig is the default Fingerprint middleware config.
+var DefaultFingerprintConfig = FingerprintConfig{
+	Skipper:    DefaultSkipper,
+	Headers:    []string{echo.HeaderUserAgent, echo.HeaderAccept, echo.HeaderAcceptLanguage, echo.HeaderAcceptEncoding},
+	Hasher:     func(data []byte) []byte { sum := sha256.Sum256(data); return sum[:] },
+	Size:       16,
+	ContextKey: "fingerprint",
+}
+
+// Fingerprint returns a middleware that computes stable fingerprint of the client from request headers and stores
+// it as `*ClientFingerprint` in context under key "fingerprint" for fraud and abuse detection.
+//
+// Example:
+//
+//	e.Use(middleware.Fingerprint())
+//	e.POST("/signup", func(c echo.Context) error {
+//		fp := c.Get("fingerprint").(*middleware.ClientFingerprint)
+//		...
+//	})
+func Fingerprint() echo.MiddlewareFunc {
+	return FingerprintWithConfig(DefaultFingerprintConfig)
+}
+
+// FingerprintWithConfig returns a Fingerprint middleware with config.
+// See: `Fingerprint()`.
+func FingerprintWithConfig(config FingerprintConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultFingerprintConfig.Skipper
+	}
+	if config.Headers == nil {
+		config.Headers = DefaultFingerprintConfig.Headers
+	}
+	if config.Hasher == nil {
+		config.Hasher = DefaultFingerprintConfig.Hasher
+	}
+	if config.Size <= 0 {
+		config.Size = DefaultFingerprintConfig.Size
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultFingerprintConfig.ContextKey
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			c.Set(config.ContextKey, config.fingerprint(c))
+			return next(c)
+		}
+	}
+}
+
+func (config FingerprintConfig) fingerprint(c echo.Context) *ClientFingerprint {
+	req := c.Request()
+	fp := &ClientFingerprint{}
+	if config.ClientHellos != nil && req.TLS != nil {
+		if hello := config.ClientHellos.Get(req.RemoteAddr); hello != "" {
+			fp.TLS = config.hash(hello)
+		}
+	}
+	fp.Headers = config.hash(headerSignature(req.Header, config.Headers))
+	if ua := req.UserAgent(); ua != "" {
+		fp.UserAgent = config.hash(ua)
+	}
+
+	ip := ""
+	if config.IPv4Prefix > 0 || config.IPv6Prefix > 0 {
+		ip = config.ipPrefix(c.RealIP())
+	}
+	fp.ID = config.hash(fp.TLS + "|" + fp.Headers + "|" + ip)
+	return fp
+}
+
+func (config FingerprintConfig) hash(data string) string {
+	sum := config.Hasher([]byte(data))
+	if len(sum) > config.Size {
+		sum = sum[:config.Size]
+	}
+	return hex.EncodeToString(sum)
+}
+
+func (config FingerprintConfig) ipPrefix(address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		if config.IPv4Prefix <= 0 {
+			return ""
+		}
+		return ip4.Mask(net.CIDRMask(config.IPv4Prefix, 32)).String()
+	}
+	if config.IPv6Prefix <= 0 {
+		return ""
+	}
+	return ip.Mask(net.CIDRMask(config.IPv6Prefix, 128)).String()
+}
+
+// headerSignature returns sorted names of sent headers followed by values of headers. `net/http` does not preserve
+// order of headers so set of names is used instead.
+func headerSignature(header http.Header, values []string) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	sb := strings.Builder{}
+	sb.WriteString(strings.Join(names, ","))
+	for _, name := range values {
+		sb.WriteByte('|')
+		sb.WriteString(header.Get(name))
+	}
+	return sb.String()
+}
+
+// NewClientHelloRecorder creates new ClientHelloRecorder.
+func NewClientHelloRecorder() *ClientHelloRecorder {
+	return &ClientHelloRecorder{}
+}
+
+// TLSConfig returns clone of config (or new config when nil) recording ClientHello of every connection.
+// `GetConfigForClient` already set on config is still called.
+func (r *ClientHelloRecorder) TLSConfig(config *tls.Config) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	getConfigForClient := config.GetConfigForClient
+	config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		if hello.Conn != nil {
+			r.hellos.Store(hello.Conn.RemoteAddr().String(), clientHelloString(hello))
+		}
+		if getConfigForClient != nil {
+			return getConfigForClient(hello)
+		}
+		return nil, nil
+	}
+	return config
+}
+
+// ConnState removes ClientHello of closed and hijacked connections. Set it as `http.Server.ConnState`.
+func (r *ClientHelloRecorder) ConnState(c net.Conn, state http.ConnState) {
+	if state == http.StateClosed || state == http.StateHijacked {
+		r.hellos.Delete(c.RemoteAddr().String())
+	}
+}
+
+// Get returns ClientHello parameters of connection from remoteAddr or empty string when it is not recorded.
+func (r *ClientHelloRecorder) Get(remoteAddr string) string {
+	v, ok := r.hellos.Load(remoteAddr)
+	if !ok {
+		return ""
+	}
+	return v.(string)
+}
+
+// clientHelloString returns JA3 like description of ClientHello: supported versions, cipher suites, curves,
+// point formats, signature schemes and ALPN protocols. `crypto/tls` does not expose list of extensions so the
+// value is not compatible with JA3. GREASE values (RFC 8701) are removed as clients pick them randomly.
+func clientHelloString(hello *tls.ClientHelloInfo) string {
+	sb := strings.Builder{}
+	writeUint16s := func(values []uint16) {
+		first := true
+		for _, v := range values {
+			if v&0x0f0f == 0x0a0a && v>>8 == v&0xff {
+				continue
+			}
+			if !first {
+				sb.WriteByte('-')
+			}
+			first = false
+			sb.WriteString(strconv.Itoa(int(v)))
+		}
+		sb.WriteByte(',')
+	}
+
+	writeUint16s(hello.SupportedVersions)
+	writeUint16s(hello.CipherSuites)
+	curves := make([]uint16, 0, len(hello.SupportedCurves))
+	for _, c := range hello.SupportedCurves {
+		curves = append(curves, uint16(c))
+	}
+	writeUint16s(curves)
+	points := make([]uint16, 0, len(hello.SupportedPoints))
+	for _, p := range hello.SupportedPoints {
+		points = append(points, uint16(p))
+	}
+	writeUint16s(points)
+	schemes := make([]uint16, 0, len(hello.SignatureSchemes))
+	for _, s := range hello.SignatureSchemes {
+		schemes = append(schemes, uint16(s))
+	}
+	writeUint16s(schemes)
+	sb.WriteString(strings.Join(hello.SupportedProtos, "-"))
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	e := echo.New()
+	e.Use(Fingerprint())
+	e.GET("/", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, c.Get("fingerprint"))
+	})
+
+	fingerprint := func(ua, remoteAddr string) ClientFingerprint {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderUserAgent, ua)
+		req.Header.Set(echo.HeaderAcceptLanguage, "en")
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		fp := ClientFingerprint{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fp))
+		return fp
+	}
+
+	fp := fingerprint("curl/7.79", "192.0.2.1:1234")
+	assert.Len(t, fp.ID, 32)
+	assert.Len(t, fp.UserAgent, 32)
+	assert.Empty(t, fp.TLS)
+	assert.Equal(t, fp, fingerprint("curl/7.79", "198.51.100.1:4321"), "address is not included by default")
+
+	other := fingerprint("Mozilla/5.0", "192.0.2.1:1234")
+	assert.NotEqual(t, fp.ID, other.ID)
+	assert.NotEqual(t, fp.UserAgent, other.UserAgent)
+}
+
+func TestFingerprintWithConfig(t *testing.T) {
+	e := echo.New()
+	config := FingerprintConfig{
+		IPv4Prefix: 24,
+		Hasher: func(data []byte) []byte {
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write(data)
+			return mac.Sum(nil)
+		},
+		Size:       4,
+		ContextKey: "fp",
+	}
+	mw := FingerprintWithConfig(config)
+
+	fingerprint := func(remoteAddr string) *ClientFingerprint {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		c := e.NewContext(req, httptest.NewRecorder())
+		err := mw(func(c echo.Context) error { return nil })(c)
+		require.NoError(t, err)
+		return c.Get("fp").(*ClientFingerprint)
+	}
+
+	fp := fingerprint("192.0.2.1:1234")
+	assert.Len(t, fp.ID, 8)
+	assert.Equal(t, fp, fingerprint("192.0.2.200:1234"), "same network prefix")
+	assert.NotEqual(t, fp.ID, fingerprint("192.0.3.1:1234").ID)
+}
+
+func TestFingerprint_clientHello(t *testing.T) {
+	hellos := NewClientHelloRecorder()
+	e := echo.New()
+	e.Use(FingerprintWithConfig(FingerprintConfig{ClientHellos: hellos}))
+	e.GET("/", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, c.Get("fingerprint"))
+	})
+
+	server := httptest.NewUnstartedServer(e)
+	server.TLS = hellos.TLSConfig(nil)
+	server.Config.ConnState = hellos.ConnState
+	server.StartTLS()
+	defer server.Close()
+
+	fingerprint := func(config *tls.Config) ClientFingerprint {
+		client := server.Client()
+		transport := client.Transport.(*http.Transport).Clone()
+		config.RootCAs = transport.TLSClientConfig.RootCAs
+		transport.TLSClientConfig = config
+		res, err := (&http.Client{Transport: transport}).Get(server.URL)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		transport.CloseIdleConnections()
+		fp := ClientFingerprint{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&fp))
+		return fp
+	}
+
+	fp := fingerprint(&tls.Config{})
+	assert.NotEmpty(t, fp.TLS)
+	assert.Equal(t, fp.TLS, fingerprint(&tls.Config{}).TLS)
+	assert.NotEqual(t, fp.TLS, fingerprint(&tls.Config{CurvePreferences: []tls.CurveID{tls.CurveP384}}).TLS)
+}
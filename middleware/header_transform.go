@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/valyala/fasttemplate"
+)
+
+type (
+	// HeaderTransformConfig defines the config for HeaderTransform middleware.
+	HeaderTransformConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Request are rules applied to request headers before next handler is called.
+		Request []HeaderRule `yaml:"request"`
+
+		// Response are rules applied to response headers just before they are written.
+		Response []HeaderRule `yaml:"response"`
+	}
+
+	// HeaderRule is single header transformation. Rules are applied in order.
+	HeaderRule struct {
+		// Action is one of:
+		// - "add" adds Value to values of header Name
+		// - "set" replaces values of header Name with Value
+		// - "remove" removes header Name. Name ending with "*" removes all headers with the prefix (i.e. "X-Internal-*")
+		// - "rename" moves values of header Name to header To
+		// - "replace" replaces matches of Pattern in values of header Name with Value (regexp replacement, `$1`)
+		// Required.
+		Action string `yaml:"action"`
+
+		// Name of the header.
+		// Required.
+		Name string `yaml:"name"`
+
+		// Value of the header. Value can contain tags replaced with values of the request:
+		// - ${context:<key>} (value stored in context)
+		// - ${header:<name>} (request header)
+		// - ${param:<name>} (path parameter)
+		// - ${query:<name>} (query parameter)
+		// - ${id} (request ID)
+		// - ${remote_ip}
+		// - ${host}
+		// - ${method}
+		// - ${path}
+		// - ${route} (path of matched route)
+		// Unknown tags (i.e. regexp replacement `${1}`) are kept as they are.
+		Value string `yaml:"value"`
+
+		// To is new name of header for "rename" action.
+		To string `yaml:"to"`
+
+		// Pattern is regular expression matched by "replace" action.
+		Pattern string `yaml:"pattern"`
+	}
+
+	headerRule struct {
+		HeaderRule
+		template *fasttemplate.Template
+		pattern  *regexp.Regexp
+	}
+)
+
+// DefaultHeaderTransformConfig is the default HeaderTransform middleware config.
+var DefaultHeaderTransformConfig = HeaderTransformConfig{
+	Skipper: DefaultSkipper,
+}
+
+// HeaderTransform returns a middleware that transforms request headers with request rules and response headers
+// with response rules, i.e. strips internal headers and injects tenant or trace metadata at the edge.
+//
+// Example:
+//
+//	e.Use(middleware.HeaderTransform(
+//		[]middleware.HeaderRule{
+//			{Action: "remove", Name: "X-Internal-*"},
+//			{Action: "set", Name: "X-Tenant", Value: "${context:tenant}"},
+//		},
+//		[]middleware.HeaderRule{
+//			{Action: "remove", Name: "X-Debug-*"},
+//			{Action: "rename", Name: "X-Backend-Version", To: "X-Version"},
+//		},
+//	))
+func HeaderTransform(request, response []HeaderRule) echo.MiddlewareFunc {
+	c := DefaultHeaderTransformConfig
+	c.Request = request
+	c.Response = response
+	return HeaderTransformWithConfig(c)
+}
+
+// HeaderTransformWithConfig returns a HeaderTransform middleware with config.
+// See: `HeaderTransform()`.
+func HeaderTransformWithConfig(config HeaderTransformConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultHeaderTransformConfig.Skipper
+	}
+	requestRules := compileHeaderRules(config.Request)
+	responseRules := compileHeaderRules(config.Response)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			for _, r := range requestRules {
+				r.apply(c, c.Request().Header)
+			}
+			if len(responseRules) > 0 {
+				c.Response().Before(func() {
+					for _, r := range responseRules {
+						r.apply(c, c.Response().Header())
+					}
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+func compileHeaderRules(rules []HeaderRule) []headerRule {
+	result := make([]headerRule, 0, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			panic("echo: header transform rule requires name")
+		}
+		hr := headerRule{HeaderRule: r}
+		switch r.Action {
+		case "add", "set":
+			hr.template = fasttemplate.New(r.Value, "${", "}")
+		case "remove":
+		case "rename":
+			if r.To == "" {
+				panic(fmt.Sprintf("echo: header transform rename of %q requires target name", r.Name))
+			}
+		case "replace":
+			hr.pattern = regexp.MustCompile(r.Pattern)
+			hr.template = fasttemplate.New(r.Value, "${", "}")
+		default:
+			panic(fmt.Sprintf("echo: invalid header transform action %q", r.Action))
+		}
+		result = append(result, hr)
+	}
+	return result
+}
+
+func (r headerRule) apply(c echo.Context, header http.Header) {
+	switch r.Action {
+	case "add":
+		header.Add(r.Name, r.value(c))
+	case "set":
+		header.Set(r.Name, r.value(c))
+	case "remove":
+		if !strings.HasSuffix(r.Name, "*") {
+			header.Del(r.Name)
+			return
+		}
+		prefix := http.CanonicalHeaderKey(strings.TrimSuffix(r.Name, "*"))
+		for name := range header {
+			if strings.HasPrefix(http.CanonicalHeaderKey(name), prefix) {
+				delete(header, name)
+			}
+		}
+	case "rename":
+		values := header.Values(r.Name)
+		if len(values) == 0 {
+			return
+		}
+		header.Del(r.Name)
+		for _, v := range values {
+			header.Add(r.To, v)
+		}
+	case "replace":
+		values := header.Values(r.Name)
+		if len(values) == 0 {
+			return
+		}
+		replacement := r.value(c)
+		replaced := make([]string, len(values))
+		for i, v := range values {
+			replaced[i] = r.pattern.ReplaceAllString(v, replacement)
+		}
+		header[http.CanonicalHeaderKey(r.Name)] = replaced
+	}
+}
+
+func (r headerRule) value(c echo.Context) string {
+	return r.template.ExecuteFuncString(func(w io.Writer, tag string) (int, error) {
+		req := c.Request()
+		switch tag {
+		case "id":
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if id == "" {
+				id = c.Response().Header().Get(echo.HeaderXRequestID)
+			}
+			return io.WriteString(w, id)
+		case "remote_ip":
+			return io.WriteString(w, c.RealIP())
+		case "host":
+			return io.WriteString(w, req.Host)
+		case "method":
+			return io.WriteString(w, req.Method)
+		case "path":
+			return io.WriteString(w, req.URL.Path)
+		case "route":
+			return io.WriteString(w, c.Path())
+		}
+		switch {
+		case strings.HasPrefix(tag, "context:"):
+			if v := c.Get(tag[8:]); v != nil {
+				return io.WriteString(w, fmt.Sprint(v))
+			}
+			return 0, nil
+		case strings.HasPrefix(tag, "header:"):
+			return io.WriteString(w, req.Header.Get(tag[7:]))
+		case strings.HasPrefix(tag, "param:"):
+			return io.WriteString(w, c.Param(tag[6:]))
+		case strings.HasPrefix(tag, "query:"):
+			return io.WriteString(w, c.QueryParam(tag[6:]))
+		}
+		return io.WriteString(w, "${"+tag+"}")
+	})
+}
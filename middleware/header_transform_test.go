@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderTransform(t *testing.T) {
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("tenant", "acme")
+			return next(c)
+		}
+	})
+	e.Use(HeaderTransform(
+		[]HeaderRule{
+			{Action: "remove", Name: "X-Internal-*"},
+			{Action: "set", Name: "X-Tenant", Value: "${context:tenant}"},
+			{Action: "add", Name: "X-Forwarded-Route", Value: "${method} ${route} ${param:id}"},
+			{Action: "rename", Name: "X-Old", To: "X-New"},
+			{Action: "replace", Name: "Authorization", Pattern: `^Token (.*)$`, Value: "Bearer ${1}"},
+		},
+		[]HeaderRule{
+			{Action: "remove", Name: "x-debug-*"},
+			{Action: "set", Name: "X-Request-Host", Value: "${host}${unknown}"},
+			{Action: "rename", Name: "X-Backend-Version", To: "X-Version"},
+		},
+	))
+
+	var requestHeader http.Header
+	e.GET("/users/:id", func(c echo.Context) error {
+		requestHeader = c.Request().Header.Clone()
+		c.Response().Header().Set("X-Debug-Query", "SELECT 1")
+		c.Response().Header().Set("X-Debug-Time", "1ms")
+		c.Response().Header().Set("X-Backend-Version", "1.2.3")
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("X-Internal-Secret", "s3cr3t")
+	req.Header.Set("X-Internal-User", "admin")
+	req.Header.Set("X-Tenant", "spoofed")
+	req.Header.Set("X-Old", "value")
+	req.Header.Set(echo.HeaderAuthorization, "Token abc")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Empty(t, requestHeader.Get("X-Internal-Secret"))
+	assert.Empty(t, requestHeader.Get("X-Internal-User"))
+	assert.Equal(t, []string{"acme"}, requestHeader.Values("X-Tenant"))
+	assert.Equal(t, "GET /users/:id 1", requestHeader.Get("X-Forwarded-Route"))
+	assert.Empty(t, requestHeader.Get("X-Old"))
+	assert.Equal(t, "value", requestHeader.Get("X-New"))
+	assert.Equal(t, "Bearer abc", requestHeader.Get(echo.HeaderAuthorization))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-Debug-Query"))
+	assert.Empty(t, rec.Header().Get("X-Debug-Time"))
+	assert.Equal(t, "example.com${unknown}", rec.Header().Get("X-Request-Host"))
+	assert.Equal(t, "1.2.3", rec.Header().Get("X-Version"))
+	assert.Empty(t, rec.Header().Get("X-Backend-Version"))
+}
+
+func TestHeaderTransformWithConfig_panics(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		givenRule HeaderRule
+	}{
+		{name: "missing name", givenRule: HeaderRule{Action: "set"}},
+		{name: "invalid action", givenRule: HeaderRule{Action: "copy", Name: "X-A"}},
+		{name: "rename without target", givenRule: HeaderRule{Action: "rename", Name: "X-A"}},
+		{name: "invalid pattern", givenRule: HeaderRule{Action: "replace", Name: "X-A", Pattern: "("}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Panics(t, func() {
+				HeaderTransformWithConfig(HeaderTransformConfig{Response: []HeaderRule{tc.givenRule}})
+			})
+		})
+	}
+}
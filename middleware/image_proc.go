@@ -0,0 +1,490 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// ImageProcConfig defines the config for ImageProc middleware.
+	ImageProcConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// SignatureKey enables signed URL guard. When set, every transformation request must contain `s` query
+		// parameter created with `SignImageURL` using the same key. Unsigned requests are rejected with 403.
+		// Optional. Default value nil.
+		SignatureKey []byte `yaml:"-"`
+
+		// MaxWidth and MaxHeight limit dimensions of resulting images.
+		// Optional. Default value 4096.
+		MaxWidth  int `yaml:"max_width"`
+		MaxHeight int `yaml:"max_height"`
+
+		// MaxSourcePixels limits number of pixels (width x height) of original images. Dimensions are read before
+		// decoding and larger images are rejected with 422, so small compressed files can not expand into huge
+		// bitmaps exhausting memory.
+		// Optional. Default value 40000000.
+		MaxSourcePixels int64 `yaml:"max_source_pixels"`
+
+		// DefaultQuality is quality used for lossy formats when `q` query param is not set.
+		// Optional. Default value 85.
+		DefaultQuality int `yaml:"default_quality"`
+
+		// Encoders registers additional output formats (e.g. "webp", "avif") by format name. Built-in formats
+		// "jpeg", "png" and "gif" can also be overridden.
+		// Optional.
+		Encoders map[string]ImageEncoder `yaml:"-"`
+
+		// CacheSize is the maximum total size in bytes of transformed images kept in memory.
+		// Optional. Default value 0 (caching disabled).
+		CacheSize int64 `yaml:"cache_size"`
+
+		// CacheTTL is how long a transformed image is kept in cache.
+		// Optional. Default value 10 minutes.
+		CacheTTL time.Duration `yaml:"cache_ttl"`
+	}
+
+	// ImageEncoder encodes an image into specific output format.
+	ImageEncoder interface {
+		// ContentType returns media type of encoded images, e.g. "image/webp".
+		ContentType() string
+		// Encode writes image m to w with given quality (1-100, applicable to lossy formats).
+		Encode(w io.Writer, m image.Image, quality int) error
+	}
+
+	imageTransform struct {
+		width   int
+		height  int
+		fit     string
+		format  string
+		quality int
+	}
+
+	imageCaptureWriter struct {
+		http.ResponseWriter
+		header http.Header
+		status int
+		buf    bytes.Buffer
+	}
+
+	jpegEncoder struct{}
+	pngEncoder  struct{}
+	gifEncoder  struct{}
+)
+
+const (
+	imageFitContain = "contain"
+	imageFitCover   = "cover"
+	imageFitFill    = "fill"
+
+	imageSignatureParam = "s"
+)
+
+var (
+	// DefaultImageProcConfig is the default ImageProc middleware config.
+	DefaultImageProcConfig = ImageProcConfig{
+		Skipper:         DefaultSkipper,
+		MaxWidth:        4096,
+		MaxHeight:       4096,
+		MaxSourcePixels: 40000000,
+		DefaultQuality:  85,
+		CacheTTL:        10 * time.Minute,
+	}
+
+	imageParams = []string{"w", "h", "fit", "fm", "q"}
+)
+
+// ImageProc returns an ImageProc middleware.
+//
+// ImageProc middleware transforms images produced by the next handler (e.g. Static middleware) on the fly based on
+// query parameters:
+//
+// - w: width in pixels
+// - h: height in pixels
+// - fit: "contain" (default, keep aspect ratio within w x h), "cover" (fill w x h and crop) or "fill" (stretch)
+// - fm: output format "jpeg", "png", "gif" or any format registered with `ImageProcConfig.Encoders`
+// - q: quality for lossy formats (1-100)
+//
+// Requests without any of these parameters are passed through untouched.
+func ImageProc() echo.MiddlewareFunc {
+	return ImageProcWithConfig(DefaultImageProcConfig)
+}
+
+// ImageProcWithConfig returns an ImageProc middleware with config.
+// See: `ImageProc()`.
+func ImageProcWithConfig(config ImageProcConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultImageProcConfig.Skipper
+	}
+	if config.MaxWidth == 0 {
+		config.MaxWidth = DefaultImageProcConfig.MaxWidth
+	}
+	if config.MaxHeight == 0 {
+		config.MaxHeight = DefaultImageProcConfig.MaxHeight
+	}
+	if config.MaxSourcePixels == 0 {
+		config.MaxSourcePixels = DefaultImageProcConfig.MaxSourcePixels
+	}
+	if config.DefaultQuality == 0 {
+		config.DefaultQuality = DefaultImageProcConfig.DefaultQuality
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultImageProcConfig.CacheTTL
+	}
+	encoders := map[string]ImageEncoder{
+		"jpeg": jpegEncoder{},
+		"jpg":  jpegEncoder{},
+		"png":  pngEncoder{},
+		"gif":  gifEncoder{},
+	}
+	for name, enc := range config.Encoders {
+		encoders[name] = enc
+	}
+	var cache *objectLRUCache
+	if config.CacheSize > 0 {
+		cache = newObjectLRUCache(config.CacheSize)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			req := c.Request()
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return next(c)
+			}
+			query := req.URL.Query()
+			if !hasImageParams(query) {
+				return next(c)
+			}
+			if config.SignatureKey != nil {
+				expected := imageSignature(config.SignatureKey, req.URL.Path, query)
+				if !hmac.Equal([]byte(expected), []byte(query.Get(imageSignatureParam))) {
+					return echo.NewHTTPError(http.StatusForbidden, "invalid image signature")
+				}
+			}
+
+			t, err := parseImageTransform(query, config)
+			if err != nil {
+				return err
+			}
+			if t.format != "" {
+				if _, ok := encoders[t.format]; !ok {
+					return echo.NewHTTPError(http.StatusBadRequest, "unsupported image format")
+				}
+			}
+
+			cacheKey := req.Host + req.URL.Path + "?" + imageCanonicalQuery(query)
+			if cache != nil {
+				if o := cache.get(cacheKey, echo.Now(c)); o != nil {
+					return serveCachedObject(c, o)
+				}
+			}
+
+			// conditional and range headers are meant for transformed image and not for the original
+			for _, h := range []string{echo.HeaderIfNoneMatch, echo.HeaderIfModifiedSince, "If-Range", "Range"} {
+				req.Header.Del(h)
+			}
+
+			res := c.Response()
+			original := res.Writer
+			cw := &imageCaptureWriter{ResponseWriter: original, header: http.Header{}}
+			res.Writer = cw
+			err = next(c)
+			res.Writer = original
+			if err != nil {
+				return err
+			}
+			res.Committed = false
+			res.Size = 0
+			if cw.status == 0 {
+				// handler returned without writing anything
+				cw.status = http.StatusOK
+			}
+
+			if cw.status != http.StatusOK || !strings.HasPrefix(cw.header.Get(echo.HeaderContentType), "image/") {
+				// not an image, send response as it was produced
+				copyHeader(res.Header(), cw.header)
+				res.WriteHeader(cw.status)
+				_, err = res.Write(cw.buf.Bytes())
+				return err
+			}
+
+			srcConfig, _, err := image.DecodeConfig(bytes.NewReader(cw.buf.Bytes()))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnprocessableEntity, "could not decode image").SetInternal(err)
+			}
+			if int64(srcConfig.Width)*int64(srcConfig.Height) > config.MaxSourcePixels {
+				return echo.NewHTTPError(http.StatusUnprocessableEntity, "image too large")
+			}
+			src, srcFormat, err := image.Decode(&cw.buf)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnprocessableEntity, "could not decode image").SetInternal(err)
+			}
+			if t.format == "" {
+				t.format = srcFormat
+			}
+			enc, ok := encoders[t.format]
+			if !ok {
+				return echo.NewHTTPError(http.StatusBadRequest, "unsupported image format")
+			}
+
+			buf := new(bytes.Buffer)
+			if err := enc.Encode(buf, transformImage(src, t), t.quality); err != nil {
+				return err
+			}
+
+			lastModified := echo.Now(c)
+			if lm, err := http.ParseTime(cw.header.Get(echo.HeaderLastModified)); err == nil {
+				lastModified = lm
+			}
+			sum := sha256.Sum256(buf.Bytes())
+			o := &cachedObject{
+				key:         cacheKey,
+				content:     buf.Bytes(),
+				modTime:     lastModified,
+				etag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+				contentType: enc.ContentType(),
+				expires:     echo.Now(c).Add(config.CacheTTL),
+			}
+			if cache != nil {
+				cache.add(o)
+			}
+			return serveCachedObject(c, o)
+		}
+	}
+}
+
+// SignImageURL returns path with given transformation params and signature param `s` that is accepted by ImageProc
+// middleware configured with the same key.
+func SignImageURL(key []byte, path string, params url.Values) string {
+	q := url.Values{}
+	for k, v := range params {
+		q[k] = v
+	}
+	q.Set(imageSignatureParam, imageSignature(key, path, q))
+	return path + "?" + q.Encode()
+}
+
+func imageSignature(key []byte, path string, query url.Values) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(path + "?" + imageCanonicalQuery(query)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func imageCanonicalQuery(query url.Values) string {
+	q := url.Values{}
+	for _, p := range imageParams {
+		if v := query.Get(p); v != "" {
+			q.Set(p, v)
+		}
+	}
+	return q.Encode()
+}
+
+func hasImageParams(query url.Values) bool {
+	for _, p := range imageParams {
+		if query.Get(p) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseImageTransform(query url.Values, config ImageProcConfig) (t imageTransform, err error) {
+	atoi := func(name string, max int) (int, error) {
+		v := query.Get(name)
+		if v == "" {
+			return 0, nil
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i > max {
+			return 0, echo.NewHTTPError(http.StatusBadRequest, "invalid image parameter "+name)
+		}
+		return i, nil
+	}
+	if t.width, err = atoi("w", config.MaxWidth); err != nil {
+		return
+	}
+	if t.height, err = atoi("h", config.MaxHeight); err != nil {
+		return
+	}
+	if t.quality, err = atoi("q", 100); err != nil {
+		return
+	}
+	if t.quality == 0 {
+		t.quality = config.DefaultQuality
+	}
+	t.format = strings.ToLower(query.Get("fm"))
+	t.fit = query.Get("fit")
+	switch t.fit {
+	case "":
+		t.fit = imageFitContain
+	case imageFitContain, imageFitCover, imageFitFill:
+	default:
+		return t, echo.NewHTTPError(http.StatusBadRequest, "invalid image parameter fit")
+	}
+	return t, nil
+}
+
+func transformImage(src image.Image, t imageTransform) image.Image {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	w, h := t.width, t.height
+	if (w == 0 && h == 0) || sw == 0 || sh == 0 {
+		return src
+	}
+	if w == 0 {
+		w = sw * h / sh
+	}
+	if h == 0 {
+		h = sh * w / sw
+	}
+
+	crop := b
+	switch t.fit {
+	case imageFitContain:
+		if sw*h > sh*w {
+			h = sh * w / sw
+		} else {
+			w = sw * h / sh
+		}
+	case imageFitCover:
+		if sw*h > sh*w {
+			cw := sh * w / h
+			crop.Min.X += (sw - cw) / 2
+			crop.Max.X = crop.Min.X + cw
+		} else {
+			ch := sw * h / w
+			crop.Min.Y += (sh - ch) / 2
+			crop.Max.Y = crop.Min.Y + ch
+		}
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return resizeImage(src, crop, w, h)
+}
+
+// resizeImage scales area r of src to w x h image. Downscaling averages all source pixels covered by target pixel
+// (box filter), upscaling uses nearest neighbour.
+func resizeImage(src image.Image, r image.Rectangle, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	rw, rh := r.Dx(), r.Dy()
+	for y := 0; y < h; y++ {
+		y0 := r.Min.Y + y*rh/h
+		y1 := r.Min.Y + (y+1)*rh/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := r.Min.X + x*rw/w
+			x1 := r.Min.X + (x+1)*rw/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var sr, sg, sb, sa, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					sr, sg, sb, sa = sr+uint64(cr), sg+uint64(cg), sb+uint64(cb), sa+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(sr / n >> 8),
+				G: uint8(sg / n >> 8),
+				B: uint8(sb / n >> 8),
+				A: uint8(sa / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+func (w *imageCaptureWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *imageCaptureWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *imageCaptureWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.buf.Write(b)
+}
+
+func (jpegEncoder) ContentType() string {
+	return "image/jpeg"
+}
+
+func (jpegEncoder) Encode(w io.Writer, m image.Image, quality int) error {
+	return jpeg.Encode(w, m, &jpeg.Options{Quality: quality})
+}
+
+func (pngEncoder) ContentType() string {
+	return "image/png"
+}
+
+func (pngEncoder) Encode(w io.Writer, m image.Image, quality int) error {
+	return png.Encode(w, m)
+}
+
+func (gifEncoder) ContentType() string {
+	return "image/gif"
+}
+
+func (gifEncoder) Encode(w io.Writer, m image.Image, quality int) error {
+	if _, ok := m.(*image.Paletted); !ok {
+		p := image.NewPaletted(m.Bounds(), nil)
+		p.Palette = gifPalette
+		draw.FloydSteinberg.Draw(p, p.Bounds(), m, m.Bounds().Min)
+		m = p
+	}
+	return gif.Encode(w, m, nil)
+}
+
+var gifPalette = func() color.Palette {
+	p := make(color.Palette, 0, 256)
+	for r := 0; r < 6; r++ {
+		for g := 0; g < 6; g++ {
+			for b := 0; b < 6; b++ {
+				p = append(p, color.RGBA{R: uint8(r * 51), G: uint8(g * 51), B: uint8(b * 51), A: 0xff})
+			}
+		}
+	}
+	return append(p, color.Transparent)
+}()
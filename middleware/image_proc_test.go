@@ -0,0 +1,225 @@
+package middleware
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type testImageEncoder struct{}
+
+func (testImageEncoder) ContentType() string {
+	return "image/x-test"
+}
+
+func (testImageEncoder) Encode(w io.Writer, m image.Image, quality int) error {
+	_, err := w.Write([]byte("test-image"))
+	return err
+}
+
+func testImageHandler(calls *int) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		*calls++
+		m := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 40; x++ {
+				m.Set(x, y, color.RGBA{R: uint8(x * 6), G: uint8(y * 12), B: 0x80, A: 0xff})
+			}
+		}
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, m); err != nil {
+			return err
+		}
+		return c.Blob(http.StatusOK, "image/png", buf.Bytes())
+	}
+}
+
+func TestImageProc(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		givenConfig  ImageProcConfig
+		whenURL      string
+		expectCode   int
+		expectType   string
+		expectWidth  int
+		expectHeight int
+		expectBody   string
+	}{
+		{
+			name:         "ok, no params passes original through",
+			whenURL:      "/img.png",
+			expectCode:   http.StatusOK,
+			expectType:   "image/png",
+			expectWidth:  40,
+			expectHeight: 20,
+		},
+		{
+			name:         "ok, resize keeping aspect ratio",
+			whenURL:      "/img.png?w=20",
+			expectCode:   http.StatusOK,
+			expectType:   "image/png",
+			expectWidth:  20,
+			expectHeight: 10,
+		},
+		{
+			name:         "ok, contain within box",
+			whenURL:      "/img.png?w=10&h=10",
+			expectCode:   http.StatusOK,
+			expectType:   "image/png",
+			expectWidth:  10,
+			expectHeight: 5,
+		},
+		{
+			name:         "ok, cover crops to box",
+			whenURL:      "/img.png?w=10&h=10&fit=cover",
+			expectCode:   http.StatusOK,
+			expectType:   "image/png",
+			expectWidth:  10,
+			expectHeight: 10,
+		},
+		{
+			name:         "ok, fill stretches to box",
+			whenURL:      "/img.png?w=15&h=30&fit=fill",
+			expectCode:   http.StatusOK,
+			expectType:   "image/png",
+			expectWidth:  15,
+			expectHeight: 30,
+		},
+		{
+			name:       "ok, convert to jpeg",
+			whenURL:    "/img.png?fm=jpeg&q=50",
+			expectCode: http.StatusOK,
+			expectType: "image/jpeg",
+		},
+		{
+			name:        "ok, custom encoder",
+			givenConfig: ImageProcConfig{Encoders: map[string]ImageEncoder{"webp": testImageEncoder{}}},
+			whenURL:     "/img.png?fm=webp",
+			expectCode:  http.StatusOK,
+			expectType:  "image/x-test",
+			expectBody:  "test-image",
+		},
+		{
+			name:       "nok, unknown format",
+			whenURL:    "/img.png?fm=avif",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "nok, too large",
+			whenURL:    "/img.png?w=5000",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:        "nok, original exceeds pixel limit",
+			givenConfig: ImageProcConfig{MaxSourcePixels: 40*20 - 1},
+			whenURL:     "/img.png?w=10",
+			expectCode:  http.StatusUnprocessableEntity,
+			expectBody:  `{"message":"image too large"}` + "\n",
+		},
+		{
+			name:       "nok, invalid fit",
+			whenURL:    "/img.png?w=5&fit=zoom",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:        "nok, missing signature",
+			givenConfig: ImageProcConfig{SignatureKey: []byte("secret")},
+			whenURL:     "/img.png?w=10",
+			expectCode:  http.StatusForbidden,
+		},
+		{
+			name:         "ok, valid signature",
+			givenConfig:  ImageProcConfig{SignatureKey: []byte("secret")},
+			whenURL:      SignImageURL([]byte("secret"), "/img.png", url.Values{"w": []string{"10"}}),
+			expectCode:   http.StatusOK,
+			expectType:   "image/png",
+			expectWidth:  10,
+			expectHeight: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			e := echo.New()
+			e.GET("/img.png", testImageHandler(&calls), ImageProcWithConfig(tc.givenConfig))
+
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectType != "" {
+				assert.Equal(t, tc.expectType, rec.Header().Get(echo.HeaderContentType))
+			}
+			if tc.expectBody != "" {
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			}
+			if tc.expectWidth != 0 {
+				cfg, _, err := image.DecodeConfig(rec.Body)
+				if assert.NoError(t, err) {
+					assert.Equal(t, tc.expectWidth, cfg.Width)
+					assert.Equal(t, tc.expectHeight, cfg.Height)
+				}
+			}
+		})
+	}
+}
+
+func TestImageProc_cache(t *testing.T) {
+	calls := 0
+	e := echo.New()
+	e.GET("/img.png", testImageHandler(&calls), ImageProcWithConfig(ImageProcConfig{CacheSize: 1 << 20}))
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/img.png?w=8", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get(echo.HeaderETag))
+	}
+	assert.Equal(t, 1, calls)
+
+	req := httptest.NewRequest(http.MethodGet, "/img.png?w=8", nil)
+	req.Host = "other.example.com"
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, 2, calls, "cache is separated by host")
+}
+
+func TestImageProc_noResponse(t *testing.T) {
+	e := echo.New()
+	e.GET("/img.png", func(c echo.Context) error {
+		return nil
+	}, ImageProc())
+
+	req := httptest.NewRequest(http.MethodGet, "/img.png?w=10", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestImageProc_notImage(t *testing.T) {
+	e := echo.New()
+	e.GET("/file.txt", func(c echo.Context) error {
+		return c.String(http.StatusOK, "plain")
+	}, ImageProc())
+
+	req := httptest.NewRequest(http.MethodGet, "/file.txt?w=10", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "plain", rec.Body.String())
+	assert.Equal(t, echo.MIMETextPlainCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+}
@@ -0,0 +1,272 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+type (
+	// ImpersonateConfig defines the config for Impersonate middleware.
+	ImpersonateConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// ActorExtractor returns ID of authenticated user sending the request. Requests with empty actor are not
+		// allowed to impersonate.
+		// Required.
+		ActorExtractor Extractor
+
+		// TargetExtractor returns ID of user the actor wants to act as. Empty target ends impersonation.
+		// Optional. Default value reads header "X-Impersonate-User".
+		TargetExtractor Extractor
+
+		// Authorize reports whether actor is allowed to impersonate target. It is called when impersonation starts,
+		// not for every request.
+		// Required.
+		Authorize func(c echo.Context, actor, target string) (bool, error)
+
+		// MaxDuration is how long impersonation lasts since it started. Requests of expired impersonation are
+		// rejected with ErrImpersonationExpired until actor sends request without target, so new impersonation
+		// must be started explicitly.
+		// Optional. Default value 30 minutes.
+		MaxDuration time.Duration
+
+		// OnEvent is called for every audit event: start, stop, expiry and denial of impersonation and every request
+		// made as target.
+		// Optional. Default value logs the event.
+		OnEvent func(c echo.Context, event ImpersonationEvent)
+
+		// ContextKey is key under which `*Impersonation` of impersonated requests is stored in context. Use it to
+		// show banner to the actor and to attribute changes to the real user.
+		// Optional. Default value "impersonation".
+		ContextKey string
+	}
+
+	// Impersonation is state of actor acting as target user.
+	Impersonation struct {
+		Actor     string
+		Target    string
+		StartedAt time.Time
+		ExpiresAt time.Time
+	}
+
+	// ImpersonationEventType is type of impersonation audit event.
+	ImpersonationEventType string
+
+	// ImpersonationEvent is audit event of impersonation.
+	ImpersonationEvent struct {
+		Type   ImpersonationEventType
+		Actor  string
+		Target string
+		Time   time.Time
+		// Method and Path are set for ImpersonationRequest events.
+		Method string
+		Path   string
+	}
+
+	impersonator struct {
+		config ImpersonateConfig
+		mutex  sync.Mutex
+		// active impersonations by actor, expired ones are kept until actor stops them
+		active map[string]*Impersonation
+	}
+)
+
+// Impersonation event types.
+const (
+	ImpersonationStart   ImpersonationEventType = "start"
+	ImpersonationStop    ImpersonationEventType = "stop"
+	ImpersonationExpire  ImpersonationEventType = "expire"
+	ImpersonationDeny    ImpersonationEventType = "deny"
+	ImpersonationRequest ImpersonationEventType = "request"
+)
+
+// Errors
+var (
+	// ErrImpersonationForbidden denotes an error raised when actor is not allowed to impersonate target.
+	ErrImpersonationForbidden = echo.NewHTTPError(http.StatusForbidden, "impersonation not allowed")
+	// ErrImpersonationExpired denotes an error raised for requests of expired impersonation.
+	ErrImpersonationExpired = echo.NewHTTPError(http.StatusForbidden, "impersonation expired")
+)
+
+// DefaultImpersonateConfig is the default Impersonate middleware config.
+var DefaultImpersonateConfig = ImpersonateConfig{
+	Skipper: DefaultSkipper,
+	TargetExtractor: func(c echo.Context) (string, error) {
+		return c.Request().Header.Get("X-Impersonate-User"), nil
+	},
+	MaxDuration: 30 * time.Minute,
+	OnEvent: func(c echo.Context, event ImpersonationEvent) {
+		j := log.JSON{
+			"message": "impersonation " + string(event.Type),
+			"actor":   event.Actor,
+			"target":  event.Target,
+			"remote":  c.RealIP(),
+		}
+		if event.Type == ImpersonationRequest {
+			j["method"] = event.Method
+			j["path"] = c.Echo().Redactor.Text(event.Path)
+		}
+		c.Logger().Infoj(j)
+	},
+	ContextKey: "impersonation",
+}
+
+// Impersonate returns an Impersonate middleware with actor extractor and authorize function.
+// See: `ImpersonateWithConfig()`.
+func Impersonate(actor Extractor, authorize func(c echo.Context, actor, target string) (bool, error)) echo.MiddlewareFunc {
+	c := DefaultImpersonateConfig
+	c.ActorExtractor = actor
+	c.Authorize = authorize
+	return ImpersonateWithConfig(c)
+}
+
+// ImpersonateWithConfig returns a middleware that lets privileged users (support staff) act as another user. Actor
+// sends target user ID (header "X-Impersonate-User" by default), impersonation is authorized once when it starts
+// and lasts at most MaxDuration. Requests made as target have `*Impersonation` in context, handlers use it to
+// resolve the effective user and to show banner. Every step is reported as audit event.
+//
+// Impersonations are kept in memory of the middleware instance.
+//
+// Example:
+//
+//	e.Use(middleware.ImpersonateWithConfig(middleware.ImpersonateConfig{
+//		ActorExtractor: func(c echo.Context) (string, error) { return sessionUser(c), nil },
+//		Authorize: func(c echo.Context, actor, target string) (bool, error) {
+//			return hasRole(actor, "support"), nil
+//		},
+//	}))
+func ImpersonateWithConfig(config ImpersonateConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.ActorExtractor == nil {
+		panic("echo: impersonate middleware requires actor extractor")
+	}
+	if config.Authorize == nil {
+		panic("echo: impersonate middleware requires authorize function")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultImpersonateConfig.Skipper
+	}
+	if config.TargetExtractor == nil {
+		config.TargetExtractor = DefaultImpersonateConfig.TargetExtractor
+	}
+	if config.MaxDuration <= 0 {
+		config.MaxDuration = DefaultImpersonateConfig.MaxDuration
+	}
+	if config.OnEvent == nil {
+		config.OnEvent = DefaultImpersonateConfig.OnEvent
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultImpersonateConfig.ContextKey
+	}
+
+	m := &impersonator{config: config, active: map[string]*Impersonation{}}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			actor, err := config.ActorExtractor(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest).SetInternal(err)
+			}
+			target, err := config.TargetExtractor(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest).SetInternal(err)
+			}
+			if target == "" {
+				if actor != "" {
+					m.stop(c, actor)
+				}
+				return next(c)
+			}
+			if actor == "" || actor == target {
+				m.event(c, ImpersonationDeny, actor, target)
+				return ErrImpersonationForbidden
+			}
+
+			imp, err := m.impersonation(c, actor, target)
+			if err != nil {
+				return err
+			}
+			c.Set(config.ContextKey, imp)
+			m.event(c, ImpersonationRequest, actor, target)
+			return next(c)
+		}
+	}
+}
+
+// ImpersonationFrom returns impersonation of the request stored under default context key or nil when request is
+// not impersonated.
+func ImpersonationFrom(c echo.Context) *Impersonation {
+	imp, _ := c.Get(DefaultImpersonateConfig.ContextKey).(*Impersonation)
+	return imp
+}
+
+// impersonation returns active impersonation of target by actor, starting new one when actor has none.
+func (m *impersonator) impersonation(c echo.Context, actor, target string) (*Impersonation, error) {
+	now := echo.Now(c)
+
+	m.mutex.Lock()
+	imp, ok := m.active[actor]
+	m.mutex.Unlock()
+	if ok {
+		if imp.StartedAt.IsZero() {
+			// already expired, actor has to stop it
+			return nil, ErrImpersonationExpired
+		}
+		if !now.Before(imp.ExpiresAt) {
+			m.mutex.Lock()
+			// keep expired impersonation (without start) until actor stops it
+			m.active[actor] = &Impersonation{Actor: actor, Target: imp.Target, ExpiresAt: imp.ExpiresAt}
+			m.mutex.Unlock()
+			m.event(c, ImpersonationExpire, actor, imp.Target)
+			return nil, ErrImpersonationExpired
+		}
+		if imp.Target == target {
+			return imp, nil
+		}
+		// switching to other target ends the current impersonation first
+		m.stop(c, actor)
+	}
+
+	allowed, err := m.config.Authorize(c, actor, target)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		m.event(c, ImpersonationDeny, actor, target)
+		return nil, ErrImpersonationForbidden
+	}
+	imp = &Impersonation{Actor: actor, Target: target, StartedAt: now, ExpiresAt: now.Add(m.config.MaxDuration)}
+	m.mutex.Lock()
+	m.active[actor] = imp
+	m.mutex.Unlock()
+	m.event(c, ImpersonationStart, actor, target)
+	return imp, nil
+}
+
+func (m *impersonator) stop(c echo.Context, actor string) {
+	m.mutex.Lock()
+	imp, ok := m.active[actor]
+	delete(m.active, actor)
+	m.mutex.Unlock()
+	if ok && !imp.StartedAt.IsZero() {
+		m.event(c, ImpersonationStop, actor, imp.Target)
+	}
+}
+
+func (m *impersonator) event(c echo.Context, typ ImpersonationEventType, actor, target string) {
+	event := ImpersonationEvent{Type: typ, Actor: actor, Target: target, Time: echo.Now(c)}
+	if typ == ImpersonationRequest {
+		event.Method = c.Request().Method
+		event.Path = c.Request().URL.Path
+	}
+	m.config.OnEvent(c, event)
+}
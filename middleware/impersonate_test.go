@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonate(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := echo.New()
+	e.Clock = clock
+
+	var events []string
+	e.Use(ImpersonateWithConfig(ImpersonateConfig{
+		ActorExtractor: func(c echo.Context) (string, error) {
+			return c.Request().Header.Get("X-User"), nil
+		},
+		Authorize: func(c echo.Context, actor, target string) (bool, error) {
+			return actor == "support" && target != "admin", nil
+		},
+		MaxDuration: 10 * time.Minute,
+		OnEvent: func(c echo.Context, event ImpersonationEvent) {
+			assert.Equal(t, clock.Now(), event.Time)
+			events = append(events, string(event.Type)+" "+event.Actor+">"+event.Target)
+		},
+	}))
+	e.GET("/me", func(c echo.Context) error {
+		if imp := ImpersonationFrom(c); imp != nil {
+			return c.String(http.StatusOK, imp.Target+" (by "+imp.Actor+")")
+		}
+		return c.String(http.StatusOK, c.Request().Header.Get("X-User"))
+	})
+
+	request := func(actor, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("X-User", actor)
+		if target != "" {
+			req.Header.Set("X-Impersonate-User", target)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	expect := func(rec *httptest.ResponseRecorder, code int, body string, expectEvents ...string) {
+		t.Helper()
+		assert.Equal(t, code, rec.Code)
+		if code == http.StatusOK {
+			assert.Equal(t, body, rec.Body.String())
+		}
+		assert.Equal(t, expectEvents, events)
+		events = nil
+	}
+
+	expect(request("support", ""), http.StatusOK, "support")
+	expect(request("jon", "ann"), http.StatusForbidden, "", "deny jon>ann")
+	expect(request("support", "admin"), http.StatusForbidden, "", "deny support>admin")
+	expect(request("", "ann"), http.StatusForbidden, "", "deny >ann")
+
+	expect(request("support", "ann"), http.StatusOK, "ann (by support)", "start support>ann", "request support>ann")
+	clock.Advance(5 * time.Minute)
+	expect(request("support", "ann"), http.StatusOK, "ann (by support)", "request support>ann")
+
+	// switching target
+	expect(request("support", "jon"), http.StatusOK, "jon (by support)",
+		"stop support>ann", "start support>jon", "request support>jon")
+
+	// forced expiry, new impersonation is possible only after stop
+	clock.Advance(10 * time.Minute)
+	expect(request("support", "jon"), http.StatusForbidden, "", "expire support>jon")
+	expect(request("support", "jon"), http.StatusForbidden, "")
+	expect(request("support", "ann"), http.StatusForbidden, "")
+	expect(request("support", ""), http.StatusOK, "support")
+	expect(request("support", "jon"), http.StatusOK, "jon (by support)", "start support>jon", "request support>jon")
+	expect(request("support", ""), http.StatusOK, "support", "stop support>jon")
+}
+
+func TestImpersonate_authorizeError(t *testing.T) {
+	e := echo.New()
+	mw := Impersonate(
+		func(c echo.Context) (string, error) { return "support", nil },
+		func(c echo.Context, actor, target string) (bool, error) { return false, errors.New("db down") },
+	)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Impersonate-User", "ann")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	err := mw(func(c echo.Context) error { return nil })(c)
+	assert.EqualError(t, err, "db down")
+	assert.Nil(t, ImpersonationFrom(c))
+}
+
+func TestImpersonateWithConfig_panics(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: impersonate middleware requires actor extractor", func() {
+		ImpersonateWithConfig(ImpersonateConfig{})
+	})
+	assert.PanicsWithValue(t, "echo: impersonate middleware requires authorize function", func() {
+		ImpersonateWithConfig(ImpersonateConfig{ActorExtractor: func(c echo.Context) (string, error) { return "", nil }})
+	})
+}
@@ -57,7 +57,9 @@ type (
 		ContextKey string
 
 		// Claims are extendable claims data defining token content. Used by default ParseTokenFunc implementation.
-		// Not used if custom ParseTokenFunc is set.
+		// Not used if custom ParseTokenFunc is set. Time based claims (exp, iat, nbf) of jwt.MapClaims,
+		// jwt.StandardClaims and claims embedding jwt.StandardClaims are validated against `Echo#Clock`. Their
+		// Valid() method is still called but its errors of time based claims are ignored.
 		// Optional. Default value jwt.MapClaims
 		Claims jwt.Claims
 
@@ -264,15 +266,19 @@ func JWTWithConfig(config JWTConfig) echo.MiddlewareFunc {
 }
 
 func (config *JWTConfig) defaultParseToken(auth string, c echo.Context) (interface{}, error) {
-	token := new(jwt.Token)
-	var err error
+	var claims jwt.Claims
 	// Issue #647, #656
 	if _, ok := config.Claims.(jwt.MapClaims); ok {
-		token, err = jwt.Parse(auth, config.KeyFunc)
+		claims = jwt.MapClaims{}
 	} else {
 		t := reflect.ValueOf(config.Claims).Type().Elem()
-		claims := reflect.New(t).Interface().(jwt.Claims)
-		token, err = jwt.ParseWithClaims(auth, claims, config.KeyFunc)
+		claims = reflect.New(t).Interface().(jwt.Claims)
+	}
+	// claims are validated after parsing so time based claims are validated against `Echo#Clock`
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(auth, claims, config.KeyFunc)
+	if err == nil {
+		err = validateClaims(token.Claims, echo.Now(c).Unix())
 	}
 	if err != nil {
 		return nil, err
@@ -283,6 +289,47 @@ func (config *JWTConfig) defaultParseToken(auth string, c echo.Context) (interfa
 	return token, nil
 }
 
+// timeValidationErrors are errors of time based claims validation.
+const timeValidationErrors = jwt.ValidationErrorExpired | jwt.ValidationErrorIssuedAt | jwt.ValidationErrorNotValidYet
+
+// validateClaims validates claims with their Valid() method. Time based claims of claims able to verify them
+// (jwt.MapClaims, jwt.StandardClaims and claims embedding it) are validated against given unix time instead of
+// system time used by Valid().
+func validateClaims(claims jwt.Claims, now int64) error {
+	tc, ok := claims.(timeClaims)
+	if !ok {
+		return claims.Valid()
+	}
+	if err := claims.Valid(); err != nil {
+		ve, ok := err.(*jwt.ValidationError)
+		if !ok || ve.Errors&^timeValidationErrors != 0 {
+			return err
+		}
+	}
+	return verifyTimeClaims(tc, now)
+}
+
+type timeClaims interface {
+	VerifyExpiresAt(cmp int64, req bool) bool
+	VerifyIssuedAt(cmp int64, req bool) bool
+	VerifyNotBefore(cmp int64, req bool) bool
+}
+
+// verifyTimeClaims validates `exp`, `iat` and `nbf` claims against given unix time. Errors match the ones returned
+// by jwt library claims validation.
+func verifyTimeClaims(claims timeClaims, now int64) error {
+	if !claims.VerifyExpiresAt(now, false) {
+		return jwt.NewValidationError("Token is expired", jwt.ValidationErrorExpired)
+	}
+	if !claims.VerifyIssuedAt(now, false) {
+		return jwt.NewValidationError("Token used before issued", jwt.ValidationErrorIssuedAt)
+	}
+	if !claims.VerifyNotBefore(now, false) {
+		return jwt.NewValidationError("Token is not valid yet", jwt.ValidationErrorNotValidYet)
+	}
+	return nil
+}
+
 // defaultKeyFunc returns a signing key of the given token.
 func (config *JWTConfig) defaultKeyFunc(t *jwt.Token) (interface{}, error) {
 	// Check the signing method
@@ -10,9 +10,11 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -619,3 +621,141 @@ func TestJWTConfig_custom_ParseTokenFunc_Keyfunc(t *testing.T) {
 
 	assert.Equal(t, http.StatusTeapot, res.Code)
 }
+
+// jwtAdminClaims are custom claims accepting only admins.
+type jwtAdminClaims struct {
+	jwtCustomClaims
+}
+
+func (c *jwtAdminClaims) Valid() error {
+	if !c.Admin {
+		return errors.New("not admin")
+	}
+	return c.StandardClaims.Valid()
+}
+
+func TestJWT_clock(t *testing.T) {
+	signingKey := []byte("secret")
+	issuedAt := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	sign := func(claims jwt.Claims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(signingKey)
+		assert.NoError(t, err)
+		return token
+	}
+	mapToken := sign(jwt.MapClaims{
+		"iat": issuedAt.Unix(),
+		"nbf": issuedAt.Add(time.Minute).Unix(),
+		"exp": issuedAt.Add(time.Hour).Unix(),
+	})
+	standardToken := sign(&jwt.StandardClaims{
+		IssuedAt:  issuedAt.Unix(),
+		NotBefore: issuedAt.Add(time.Minute).Unix(),
+		ExpiresAt: issuedAt.Add(time.Hour).Unix(),
+	})
+
+	customToken := func(admin bool) string {
+		return sign(&jwtCustomClaims{
+			StandardClaims: &jwt.StandardClaims{
+				IssuedAt:  issuedAt.Unix(),
+				ExpiresAt: issuedAt.Add(time.Hour).Unix(),
+			},
+			jwtCustomInfo: jwtCustomInfo{Name: "jon", Admin: admin},
+		})
+	}
+
+	var testCases = []struct {
+		name        string
+		givenClaims jwt.Claims
+		givenToken  string
+		whenNow     time.Time
+		expectError string
+	}{
+		{
+			name:       "ok, map claims",
+			givenToken: mapToken,
+			whenNow:    issuedAt.Add(30 * time.Minute),
+		},
+		{
+			name:        "ok, standard claims",
+			givenClaims: &jwt.StandardClaims{},
+			givenToken:  standardToken,
+			whenNow:     issuedAt.Add(30 * time.Minute),
+		},
+		{
+			name:        "nok, map claims expired",
+			givenToken:  mapToken,
+			whenNow:     issuedAt.Add(2 * time.Hour),
+			expectError: "code=401, message=invalid or expired jwt, internal=Token is expired",
+		},
+		{
+			name:        "nok, standard claims expired",
+			givenClaims: &jwt.StandardClaims{},
+			givenToken:  standardToken,
+			whenNow:     issuedAt.Add(2 * time.Hour),
+			expectError: "code=401, message=invalid or expired jwt, internal=Token is expired",
+		},
+		{
+			name:        "ok, custom claims",
+			givenClaims: &jwtCustomClaims{},
+			givenToken:  customToken(false),
+			whenNow:     issuedAt.Add(30 * time.Minute),
+		},
+		{
+			name:        "nok, custom claims expired",
+			givenClaims: &jwtCustomClaims{},
+			givenToken:  customToken(false),
+			whenNow:     issuedAt.Add(2 * time.Hour),
+			expectError: "code=401, message=invalid or expired jwt, internal=Token is expired",
+		},
+		{
+			name:        "ok, custom claims with custom validation",
+			givenClaims: &jwtAdminClaims{},
+			givenToken:  customToken(true),
+			whenNow:     issuedAt.Add(30 * time.Minute),
+		},
+		{
+			name:        "nok, custom validation fails",
+			givenClaims: &jwtAdminClaims{},
+			givenToken:  customToken(false),
+			whenNow:     issuedAt.Add(30 * time.Minute),
+			expectError: "code=401, message=invalid or expired jwt, internal=not admin",
+		},
+		{
+			name:        "nok, used before issued",
+			givenToken:  mapToken,
+			whenNow:     issuedAt.Add(-time.Minute),
+			expectError: "code=401, message=invalid or expired jwt, internal=Token used before issued",
+		},
+		{
+			name:        "nok, not valid yet",
+			givenClaims: &jwt.StandardClaims{},
+			givenToken:  standardToken,
+			whenNow:     issuedAt.Add(30 * time.Second),
+			expectError: "code=401, message=invalid or expired jwt, internal=Token is not valid yet",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Clock = echotest.NewClock(tc.whenNow)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderAuthorization, DefaultJWTConfig.AuthScheme+" "+tc.givenToken)
+			res := httptest.NewRecorder()
+			c := e.NewContext(req, res)
+
+			mw := JWTWithConfig(JWTConfig{SigningKey: signingKey, Claims: tc.givenClaims})
+			err := mw(func(c echo.Context) error {
+				return c.String(http.StatusOK, "test")
+			})(c)
+
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.IsType(t, &jwt.Token{}, c.Get("user"))
+		})
+	}
+}
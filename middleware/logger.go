@@ -3,6 +3,7 @@ package middleware
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
@@ -27,12 +28,17 @@ type (
 		// - time_rfc3339
 		// - time_rfc3339_nano
 		// - time_custom
+		// - time_clf (Common Log Format time, e.g. 10/Oct/2000:13:55:36 -0700)
+		// - time_w3c_date (UTC date, e.g. 2000-10-10)
+		// - time_w3c_time (UTC time, e.g. 20:55:36)
 		// - id (Request ID)
 		// - remote_ip
+		// - remote_user (Username of basic authentication)
 		// - uri
 		// - host
 		// - method
 		// - path
+		// - query_string
 		// - protocol
 		// - referer
 		// - user_agent
@@ -40,17 +46,32 @@ type (
 		// - error
 		// - latency (In nanoseconds)
 		// - latency_human (Human readable)
+		// - latency_seconds (In seconds with millisecond precision)
 		// - bytes_in (Bytes received)
 		// - bytes_out (Bytes sent)
+		// - bytes_out_clf (Bytes sent, "-" when no bytes were sent)
 		// - header:<NAME>
 		// - query:<NAME>
 		// - form:<NAME>
+		// - context:<NAME> (Value of context key registered with `Echo#RegisterLogKey()`)
 		//
 		// Example "${remote_ip} ${status}"
 		//
+		// Ready-made formats are LoggerFormatCommon, LoggerFormatCombined, LoggerFormatJSON and LoggerFormatW3C.
+		//
 		// Optional. Default value DefaultLoggerConfig.Format.
 		Format string `yaml:"format"`
 
+		// Escape defines how values of tags are escaped. Possible values:
+		// - "json" escapes values as content of JSON string so every log line is valid JSON
+		// - "quoted" escapes `"`, `\` and non-printable characters with backslash and replaces empty values
+		//   with "-" (as Apache HTTP server access log)
+		// Colors of status are disabled when values are escaped.
+		//
+		// Optional. Default value is "json" for LoggerFormatJSON, "quoted" for LoggerFormatCommon,
+		// LoggerFormatCombined and LoggerFormatW3C and no escaping for other formats.
+		Escape string `yaml:"escape"`
+
 		// Optional. Default value DefaultLoggerConfig.CustomTimeFormat.
 		CustomTimeFormat string `yaml:"custom_time_format"`
 
@@ -61,9 +82,35 @@ type (
 		template *fasttemplate.Template
 		colorer  *color.Color
 		pool     *sync.Pool
+		escape   func(buf *bytes.Buffer, value []byte)
 	}
 )
 
+// Ready-made access log formats.
+const (
+	// LoggerFormatCommon is Common Log Format (CLF) of Apache HTTP server.
+	LoggerFormatCommon = `${remote_ip} - ${remote_user} [${time_clf}] "${method} ${uri} ${protocol}" ${status} ${bytes_out_clf}` + "\n"
+
+	// LoggerFormatCombined is Combined Log Format of Apache HTTP server (CLF with referer and user agent).
+	LoggerFormatCombined = `${remote_ip} - ${remote_user} [${time_clf}] "${method} ${uri} ${protocol}" ${status} ${bytes_out_clf}` +
+		` "${referer}" "${user_agent}"` + "\n"
+
+	// LoggerFormatJSON is JSON format with all values escaped.
+	LoggerFormatJSON = `{"time":"${time_rfc3339_nano}","id":"${id}","remote_ip":"${remote_ip}",` +
+		`"host":"${host}","method":"${method}","uri":"${uri}","protocol":"${protocol}","referer":"${referer}",` +
+		`"user_agent":"${user_agent}","status":${status},"error":"${error}","latency":${latency},` +
+		`"bytes_in":${bytes_in},"bytes_out":${bytes_out}}` + "\n"
+
+	// LoggerFormatW3C is W3C Extended Log File Format with fields listed in LoggerW3CHeader.
+	LoggerFormatW3C = `${time_w3c_date} ${time_w3c_time} ${remote_ip} ${remote_user} ${method} ${path} ${query_string} ` +
+		`${status} ${bytes_out} ${latency_seconds} "${user_agent}" "${referer}"` + "\n"
+
+	// LoggerW3CHeader are directives which must start log file in W3C Extended Log File Format. Set it as
+	// `RotatingFileConfig.Header` or write it to output before logging.
+	LoggerW3CHeader = "#Version: 1.0\n" +
+		"#Fields: date time c-ip cs-username cs-method cs-uri-stem cs-uri-query sc-status sc-bytes time-taken cs(User-Agent) cs(Referer)\n"
+)
+
 var (
 	// DefaultLoggerConfig is the default Logger middleware config.
 	DefaultLoggerConfig = LoggerConfig{
@@ -96,9 +143,30 @@ func LoggerWithConfig(config LoggerConfig) echo.MiddlewareFunc {
 		config.Output = DefaultLoggerConfig.Output
 	}
 
+	if config.Escape == "" {
+		switch config.Format {
+		case LoggerFormatJSON:
+			config.Escape = "json"
+		case LoggerFormatCommon, LoggerFormatCombined, LoggerFormatW3C:
+			config.Escape = "quoted"
+		}
+	}
+
 	config.template = fasttemplate.New(config.Format, "${", "}")
 	config.colorer = color.New()
 	config.colorer.SetOutput(config.Output)
+	switch config.Escape {
+	case "":
+	case "json":
+		config.escape = escapeJSON
+	case "quoted":
+		config.escape = escapeQuoted
+	default:
+		panic("echo: invalid logger escape " + config.Escape)
+	}
+	if config.escape != nil {
+		config.colorer.Disable()
+	}
 	config.pool = &sync.Pool{
 		New: func() interface{} {
 			return bytes.NewBuffer(make([]byte, 256))
@@ -113,27 +181,34 @@ func LoggerWithConfig(config LoggerConfig) echo.MiddlewareFunc {
 
 			req := c.Request()
 			res := c.Response()
-			start := time.Now()
+			start := echo.Now(c)
 			if err = next(c); err != nil {
 				c.Error(err)
 			}
-			stop := time.Now()
+			stop := echo.Now(c)
+			redactor := c.Echo().Redactor
 			buf := config.pool.Get().(*bytes.Buffer)
 			buf.Reset()
 			defer config.pool.Put(buf)
 
-			if _, err = config.template.ExecuteFunc(buf, func(w io.Writer, tag string) (int, error) {
+			writeTag := func(buf *bytes.Buffer, tag string) (int, error) {
 				switch tag {
 				case "time_unix":
-					return buf.WriteString(strconv.FormatInt(time.Now().Unix(), 10))
+					return buf.WriteString(strconv.FormatInt(stop.Unix(), 10))
 				case "time_unix_nano":
-					return buf.WriteString(strconv.FormatInt(time.Now().UnixNano(), 10))
+					return buf.WriteString(strconv.FormatInt(stop.UnixNano(), 10))
 				case "time_rfc3339":
-					return buf.WriteString(time.Now().Format(time.RFC3339))
+					return buf.WriteString(stop.Format(time.RFC3339))
 				case "time_rfc3339_nano":
-					return buf.WriteString(time.Now().Format(time.RFC3339Nano))
+					return buf.WriteString(stop.Format(time.RFC3339Nano))
 				case "time_custom":
-					return buf.WriteString(time.Now().Format(config.CustomTimeFormat))
+					return buf.WriteString(stop.Format(config.CustomTimeFormat))
+				case "time_clf":
+					return buf.WriteString(stop.Format("02/Jan/2006:15:04:05 -0700"))
+				case "time_w3c_date":
+					return buf.WriteString(stop.UTC().Format("2006-01-02"))
+				case "time_w3c_time":
+					return buf.WriteString(stop.UTC().Format("15:04:05"))
 				case "id":
 					id := req.Header.Get(echo.HeaderXRequestID)
 					if id == "" {
@@ -142,10 +217,13 @@ func LoggerWithConfig(config LoggerConfig) echo.MiddlewareFunc {
 					return buf.WriteString(id)
 				case "remote_ip":
 					return buf.WriteString(c.RealIP())
+				case "remote_user":
+					username, _, _ := req.BasicAuth()
+					return buf.WriteString(username)
 				case "host":
 					return buf.WriteString(req.Host)
 				case "uri":
-					return buf.WriteString(req.RequestURI)
+					return buf.WriteString(redactor.URI(req.RequestURI))
 				case "method":
 					return buf.WriteString(req.Method)
 				case "path":
@@ -153,11 +231,13 @@ func LoggerWithConfig(config LoggerConfig) echo.MiddlewareFunc {
 					if p == "" {
 						p = "/"
 					}
-					return buf.WriteString(p)
+					return buf.WriteString(redactor.Text(p))
+				case "query_string":
+					return buf.WriteString(redactor.Query(req.URL.RawQuery))
 				case "protocol":
 					return buf.WriteString(req.Proto)
 				case "referer":
-					return buf.WriteString(req.Referer())
+					return buf.WriteString(redactor.URI(req.Referer()))
 				case "user_agent":
 					return buf.WriteString(req.UserAgent())
 				case "status":
@@ -173,9 +253,12 @@ func LoggerWithConfig(config LoggerConfig) echo.MiddlewareFunc {
 					}
 					return buf.WriteString(s)
 				case "error":
+					if err != nil && config.escape != nil {
+						return buf.WriteString(redactor.Text(err.Error()))
+					}
 					if err != nil {
 						// Error may contain invalid JSON e.g. `"`
-						b, _ := json.Marshal(err.Error())
+						b, _ := json.Marshal(redactor.Text(err.Error()))
 						b = b[1 : len(b)-1]
 						return buf.Write(b)
 					}
@@ -184,6 +267,8 @@ func LoggerWithConfig(config LoggerConfig) echo.MiddlewareFunc {
 					return buf.WriteString(strconv.FormatInt(int64(l), 10))
 				case "latency_human":
 					return buf.WriteString(stop.Sub(start).String())
+				case "latency_seconds":
+					return buf.WriteString(strconv.FormatFloat(stop.Sub(start).Seconds(), 'f', 3, 64))
 				case "bytes_in":
 					cl := req.Header.Get(echo.HeaderContentLength)
 					if cl == "" {
@@ -192,22 +277,45 @@ func LoggerWithConfig(config LoggerConfig) echo.MiddlewareFunc {
 					return buf.WriteString(cl)
 				case "bytes_out":
 					return buf.WriteString(strconv.FormatInt(res.Size, 10))
+				case "bytes_out_clf":
+					if res.Size == 0 {
+						return buf.WriteString("-")
+					}
+					return buf.WriteString(strconv.FormatInt(res.Size, 10))
 				default:
 					switch {
 					case strings.HasPrefix(tag, "header:"):
-						return buf.Write([]byte(c.Request().Header.Get(tag[7:])))
+						return buf.Write([]byte(redactor.Header(tag[7:], c.Request().Header.Get(tag[7:]))))
 					case strings.HasPrefix(tag, "query:"):
-						return buf.Write([]byte(c.QueryParam(tag[6:])))
+						return buf.Write([]byte(redactor.Field(tag[6:], c.QueryParam(tag[6:]))))
 					case strings.HasPrefix(tag, "form:"):
-						return buf.Write([]byte(c.FormValue(tag[5:])))
+						return buf.Write([]byte(redactor.Field(tag[5:], c.FormValue(tag[5:]))))
+					case strings.HasPrefix(tag, "context:"):
+						if value, ok := echo.LogValue(c, tag[8:]); ok {
+							return fmt.Fprint(buf, value)
+						}
 					case strings.HasPrefix(tag, "cookie:"):
 						cookie, err := c.Cookie(tag[7:])
 						if err == nil {
-							return buf.Write([]byte(cookie.Value))
+							return buf.Write([]byte(redactor.Field(cookie.Name, cookie.Value)))
 						}
 					}
 				}
 				return 0, nil
+			}
+
+			if _, err = config.template.ExecuteFunc(buf, func(w io.Writer, tag string) (int, error) {
+				if config.escape == nil {
+					return writeTag(buf, tag)
+				}
+				value := config.pool.Get().(*bytes.Buffer)
+				value.Reset()
+				defer config.pool.Put(value)
+				if _, err := writeTag(value, tag); err != nil {
+					return 0, err
+				}
+				config.escape(buf, value.Bytes())
+				return value.Len(), nil
 			}); err != nil {
 				return
 			}
@@ -221,3 +329,32 @@ func LoggerWithConfig(config LoggerConfig) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// escapeJSON writes value escaped as content of JSON string.
+func escapeJSON(buf *bytes.Buffer, value []byte) {
+	b, _ := json.Marshal(string(value))
+	buf.Write(b[1 : len(b)-1])
+}
+
+// escapeQuoted writes value with `"`, `\` and non-printable characters escaped by backslash. Empty value is written
+// as "-".
+func escapeQuoted(buf *bytes.Buffer, value []byte) {
+	if len(value) == 0 {
+		buf.WriteByte('-')
+		return
+	}
+	const hex = "0123456789abcdef"
+	for _, b := range value {
+		switch {
+		case b == '"' || b == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(b)
+		case b < 0x20 || b == 0x7f:
+			buf.WriteString(`\x`)
+			buf.WriteByte(hex[b>>4])
+			buf.WriteByte(hex[b&0x0f])
+		default:
+			buf.WriteByte(b)
+		}
+	}
+}
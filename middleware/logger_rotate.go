@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// RotatingFileConfig defines the config for RotatingFile.
+	RotatingFileConfig struct {
+		// Filename is path of the log file. Rotated files are renamed to "<Filename>.<time>".
+		// Required.
+		Filename string
+
+		// MaxSize is size in bytes after which file is rotated. Value 0 disables rotation by size.
+		// Optional.
+		MaxSize int64
+
+		// Interval is time after which file is rotated, i.e. 24 hours. Rotation happens on multiples of Interval
+		// since start of the day (in local time zone). Value 0 disables rotation by time.
+		// Optional.
+		Interval time.Duration
+
+		// MaxBackups is number of rotated files kept. Oldest files are removed. Value 0 keeps all rotated files.
+		// Optional.
+		MaxBackups int
+
+		// Header is written to beginning of every new file, i.e. LoggerW3CHeader.
+		// Optional.
+		Header string
+
+		// Clock is source of current time.
+		// Optional. Default value `echo.SystemClock`.
+		Clock echo.Clock
+	}
+
+	// RotatingFile is log file writer rotating file by size and time. It can be used as `LoggerConfig.Output`.
+	// When file is rotated by external tool (i.e. logrotate) call `Reopen` or use `ReopenOnSignal`.
+	RotatingFile struct {
+		config       RotatingFileConfig
+		mutex        sync.Mutex
+		file         *os.File
+		size         int64
+		nextRotation time.Time
+	}
+)
+
+const rotatedFileTimeFormat = "2006-01-02T15-04-05.000"
+
+// NewRotatingFile creates new RotatingFile and opens log file for appending.
+//
+// Example:
+//
+//	file, err := middleware.NewRotatingFile(middleware.RotatingFileConfig{
+//		Filename:   "/var/log/app/access.log",
+//		MaxSize:    100 << 20,
+//		Interval:   24 * time.Hour,
+//		MaxBackups: 7,
+//	})
+//	if err != nil {
+//		e.Logger.Fatal(err)
+//	}
+//	defer file.Close()
+//	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{Format: middleware.LoggerFormatCombined, Output: file}))
+func NewRotatingFile(config RotatingFileConfig) (*RotatingFile, error) {
+	if config.Filename == "" {
+		return nil, errors.New("rotating file requires filename")
+	}
+	if config.Clock == nil {
+		config.Clock = echo.SystemClock
+	}
+	f := &RotatingFile{config: config}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes p to log file. File is rotated before writing when it would exceed MaxSize or Interval elapsed.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	sizeExceeded := f.config.MaxSize > 0 && f.size > int64(len(f.config.Header)) && f.size+int64(len(p)) > f.config.MaxSize
+	intervalElapsed := f.config.Interval > 0 && !f.config.Clock.Now().Before(f.nextRotation)
+	if sizeExceeded || intervalElapsed {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate renames current log file and opens new one.
+func (f *RotatingFile) Rotate() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.rotate()
+}
+
+// Reopen closes and opens log file again. Use it after log file was renamed by external tool.
+func (f *RotatingFile) Reopen() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+	return f.open()
+}
+
+// ReopenOnSignal reopens log file whenever process receives one of signals (default SIGUSR1 on Unix, as expected by
+// logrotate `postrotate` scripts). Call returned function to stop listening.
+func (f *RotatingFile) ReopenOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = defaultReopenSignals
+	}
+	if len(signals) == 0 {
+		return func() {}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				f.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Close closes log file.
+func (f *RotatingFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	if f.size == 0 && f.config.Header != "" {
+		n, err := file.WriteString(f.config.Header)
+		f.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	if f.config.Interval > 0 {
+		now := f.config.Clock.Now()
+		day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		f.nextRotation = day.Add((now.Sub(day)/f.config.Interval + 1) * f.config.Interval)
+	}
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+	rotated := f.config.Filename + "." + f.config.Clock.Now().Format(rotatedFileTimeFormat)
+	if err := os.Rename(f.config.Filename, rotated); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.removeBackups()
+}
+
+// removeBackups removes the oldest rotated files over MaxBackups.
+func (f *RotatingFile) removeBackups() error {
+	if f.config.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(f.config.Filename + ".*")
+	if err != nil {
+		return err
+	}
+	prefix := f.config.Filename + "."
+	rotatedFiles := backups[:0]
+	for _, b := range backups {
+		if _, err := time.Parse(rotatedFileTimeFormat, strings.TrimPrefix(b, prefix)); err == nil {
+			rotatedFiles = append(rotatedFiles, b)
+		}
+	}
+	if len(rotatedFiles) <= f.config.MaxBackups {
+		return nil
+	}
+	// time format sorts lexically in chronological order
+	sort.Strings(rotatedFiles)
+	for _, b := range rotatedFiles[:len(rotatedFiles)-f.config.MaxBackups] {
+		if err := os.Remove(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+// +build windows plan9
+
+package middleware
+
+import "os"
+
+// defaultReopenSignals is empty as there is no SIGUSR1 on this platform.
+var defaultReopenSignals []os.Signal
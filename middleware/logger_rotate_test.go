@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_size(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "access.log")
+	clock := echotest.NewClock(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	f, err := NewRotatingFile(RotatingFileConfig{Filename: filename, MaxSize: 10, MaxBackups: 2, Header: "#H\n", Clock: clock})
+	require.NoError(t, err)
+	defer f.Close()
+
+	for _, line := range []string{"line1\n", "line2\n", "line3\n", "line4\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+		clock.Advance(time.Second)
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "#H\nline4\n", string(b))
+
+	backups, err := filepath.Glob(filename + ".*")
+	require.NoError(t, err)
+	sort.Strings(backups)
+	require.Len(t, backups, 2, "oldest backup is removed")
+	b, err = ioutil.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "#H\nline2\n", string(b))
+	assert.Equal(t, filename+".2021-01-02T03-04-07.000", backups[0])
+}
+
+func TestRotatingFile_interval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "access.log")
+	clock := echotest.NewClock(time.Date(2021, 1, 2, 23, 0, 0, 0, time.UTC))
+
+	f, err := NewRotatingFile(RotatingFileConfig{Filename: filename, Interval: 24 * time.Hour, Clock: clock})
+	require.NoError(t, err)
+	defer f.Close()
+
+	f.Write([]byte("day1\n"))
+	clock.Advance(59 * time.Minute)
+	f.Write([]byte("day1\n"))
+	clock.Advance(time.Minute)
+	f.Write([]byte("day2\n"))
+
+	b, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "day2\n", string(b))
+	b, err = ioutil.ReadFile(filename + ".2021-01-03T00-00-00.000")
+	require.NoError(t, err)
+	assert.Equal(t, "day1\nday1\n", string(b))
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "access.log")
+
+	f, err := NewRotatingFile(RotatingFileConfig{Filename: filename})
+	require.NoError(t, err)
+	f.Write([]byte("before\n"))
+
+	// logrotate renames file and signals process to reopen it
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	require.NoError(t, f.Reopen())
+	f.Write([]byte("after\n"))
+	require.NoError(t, f.Close())
+
+	b, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(b))
+	b, err = ioutil.ReadFile(filename + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", string(b))
+
+	_, err = f.Write([]byte("closed\n"))
+	assert.Error(t, err)
+}
+
+func TestNewRotatingFile_error(t *testing.T) {
+	_, err := NewRotatingFile(RotatingFileConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,10 @@
+// +build !windows,!plan9
+
+package middleware
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultReopenSignals = []os.Signal{syscall.SIGUSR1}
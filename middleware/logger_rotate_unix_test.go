@@ -0,0 +1,35 @@
+// +build !windows,!plan9
+
+package middleware
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_ReopenOnSignal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "access.log")
+
+	f, err := NewRotatingFile(RotatingFileConfig{Filename: filename})
+	require.NoError(t, err)
+	defer f.Close()
+	stop := f.ReopenOnSignal()
+	defer stop()
+
+	require.NoError(t, os.Rename(filename, filename+".1"))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filename)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+}
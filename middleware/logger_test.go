@@ -13,6 +13,7 @@ import (
 	"unsafe"
 
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -244,3 +245,119 @@ func BenchmarkLoggerWithConfig_withMapFields(b *testing.B) {
 		buf.Reset()
 	}
 }
+
+func TestLogger_clock(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := echo.New()
+	e.Clock = echotest.NewClock(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+	e.Use(LoggerWithConfig(LoggerConfig{
+		Format: `${time_rfc3339} ${time_unix} ${latency}` + "\n",
+		Output: buf,
+	}))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "test")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, "2021-01-02T03:04:05Z 1609556645 0\n", buf.String())
+}
+
+func TestLoggerFormats(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		givenFormat  string
+		givenEscape  string
+		expectOutput string
+	}{
+		{
+			name:         "ok, common log format",
+			givenFormat:  LoggerFormatCommon,
+			expectOutput: `192.0.2.1 - jon [02/Jan/2021:03:04:05 +0000] "GET /users?q=%22a%22 HTTP/1.1" 200 4` + "\n",
+		},
+		{
+			name:        "ok, combined log format",
+			givenFormat: LoggerFormatCombined,
+			expectOutput: `192.0.2.1 - jon [02/Jan/2021:03:04:05 +0000] "GET /users?q=%22a%22 HTTP/1.1" 200 4` +
+				` "-" "agent \"quoted\"\x09"` + "\n",
+		},
+		{
+			name:        "ok, W3C extended log format",
+			givenFormat: LoggerFormatW3C,
+			expectOutput: `2021-01-02 03:04:05 192.0.2.1 jon GET /users q=%22a%22 200 4 0.000` +
+				` "agent \"quoted\"\x09" "-"` + "\n",
+		},
+		{
+			name:         "ok, custom format escaped as JSON",
+			givenFormat:  `{"ua":"${user_agent}","status":${status}}` + "\n",
+			givenEscape:  "json",
+			expectOutput: `{"ua":"agent \"quoted\"\t","status":200}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			e := echo.New()
+			e.Clock = echotest.NewClock(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+			e.Use(LoggerWithConfig(LoggerConfig{Format: tc.givenFormat, Escape: tc.givenEscape, Output: buf}))
+			e.GET("/users", func(c echo.Context) error {
+				return c.String(http.StatusOK, "test")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users?q=%22a%22", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.SetBasicAuth("jon", "secret")
+			req.Header.Set(echo.HeaderUserAgent, "agent \"quoted\"\t")
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tc.expectOutput, buf.String())
+		})
+	}
+}
+
+func TestLoggerFormatJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := echo.New()
+	e.Use(LoggerWithConfig(LoggerConfig{Format: LoggerFormatJSON, Output: buf}))
+	e.GET("/", func(c echo.Context) error {
+		return errors.New(`invalid "value"` + "\n")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, `/?q="a"`, nil)
+	req.Header.Set(echo.HeaderUserAgent, `agent "quoted"`)
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, `agent "quoted"`, entry["user_agent"])
+	assert.Equal(t, `/?q="a"`, entry["uri"])
+	assert.Equal(t, `invalid "value"`+"\n", entry["error"])
+	assert.Equal(t, float64(http.StatusInternalServerError), entry["status"])
+}
+
+func TestLoggerContextValues(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := echo.New()
+	e.RegisterLogKey("tenant", nil)
+	e.RegisterLogKey("card", echo.RedactMask(4))
+	e.Use(LoggerWithConfig(LoggerConfig{
+		Format: "${context:tenant} ${context:card} ${context:secret} ${context:order}\n",
+		Output: buf,
+	}))
+	e.GET("/", func(c echo.Context) error {
+		c.Set("tenant", "acme")
+		c.Set("card", "4111111111111111")
+		c.Set("secret", "not registered")
+		return c.NoContent(http.StatusOK)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "acme ************1111  \n", buf.String())
+}
+
+func TestLoggerWithConfig_invalidEscape(t *testing.T) {
+	assert.Panics(t, func() { LoggerWithConfig(LoggerConfig{Escape: "xml"}) })
+}
@@ -0,0 +1,352 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// LoginGuardConfig defines the config for LoginGuard middleware.
+	LoginGuardConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// AccountExtractor returns account (i.e. username or email) the login attempt is made for. Requests with
+		// empty account are counted only against the client IP.
+		// Optional. Default value reads form field "username".
+		AccountExtractor Extractor
+
+		// IPExtractor returns client IP address.
+		// Optional. Default value `echo.Context#RealIP()`.
+		IPExtractor Extractor
+
+		// Window is length of the sliding window failed attempts are counted in.
+		// Optional. Default value 15 minutes.
+		Window time.Duration
+
+		// MaxAccountFailures is number of failed attempts for one account within Window after which the account
+		// is locked. Locking account stops password guessing even when attempts come from many IP addresses.
+		// Optional. Default value 5.
+		MaxAccountFailures int
+
+		// MaxIPFailures is number of failed attempts from one IP address within Window after which the IP address
+		// is locked. Locking IP address stops credential stuffing trying many accounts from single client.
+		// Optional. Default value 50.
+		MaxIPFailures int
+
+		// Lockout is duration of the first lockout. Each following lockout of the same account or IP address is
+		// twice as long as the previous one.
+		// Optional. Default value 1 minute.
+		Lockout time.Duration
+
+		// MaxLockout is maximum duration of lockout.
+		// Optional. Default value 1 hour.
+		MaxLockout time.Duration
+
+		// CaptchaThreshold is number of failed attempts for account or IP address within Window after which
+		// every next attempt must pass VerifyCaptcha. Value 0 disables CAPTCHA challenge.
+		// Optional.
+		CaptchaThreshold int
+
+		// VerifyCaptcha checks CAPTCHA response sent with the request (i.e. by calling CAPTCHA provider API).
+		// Required when CaptchaThreshold is set.
+		VerifyCaptcha func(c echo.Context) (bool, error)
+
+		// IsFailure reports whether login attempt handled by next handler failed.
+		// Optional. Default value treats 401 and 403 responses (or errors with these codes) as failures.
+		IsFailure func(c echo.Context, err error) bool
+
+		// DenyHandler is called with ErrLoginLocked or ErrLoginCaptchaRequired when attempt is rejected before
+		// reaching next handler. retryAfter is remaining lockout time.
+		// Optional. Default value sets `Retry-After` header for lockouts and returns the error.
+		DenyHandler func(c echo.Context, err error, retryAfter time.Duration) error
+	}
+
+	loginGuard struct {
+		config      LoginGuardConfig
+		mutex       sync.Mutex
+		accounts    map[string]*loginAttempts
+		ips         map[string]*loginAttempts
+		lastCleanup time.Time
+	}
+
+	// loginAttempts tracks failed login attempts of one account or IP address.
+	loginAttempts struct {
+		failures    []time.Time
+		lockouts    int
+		lockedUntil time.Time
+		// pending is number of attempts in progress, they count toward the limit until they finish so parallel
+		// attempts can not exceed it
+		pending int
+	}
+)
+
+// Errors
+var (
+	// ErrLoginLocked denotes an error raised when account or IP address is locked after too many failed attempts.
+	ErrLoginLocked = echo.NewHTTPError(http.StatusTooManyRequests, "too many failed login attempts")
+	// ErrLoginCaptchaRequired denotes an error raised when CAPTCHA is required and was not passed.
+	ErrLoginCaptchaRequired = echo.NewHTTPError(http.StatusForbidden, "captcha required")
+)
+
+// DefaultLoginGuardConfig is the default LoginGuard middleware config.
+var DefaultLoginGuardConfig = LoginGuardConfig{
+	Skipper: DefaultSkipper,
+	AccountExtractor: func(c echo.Context) (string, error) {
+		return c.FormValue("username"), nil
+	},
+	IPExtractor: func(c echo.Context) (string, error) {
+		return c.RealIP(), nil
+	},
+	Window:             15 * time.Minute,
+	MaxAccountFailures: 5,
+	MaxIPFailures:      50,
+	Lockout:            time.Minute,
+	MaxLockout:         time.Hour,
+	IsFailure: func(c echo.Context, err error) bool {
+		code := c.Response().Status
+		if err != nil {
+			he := new(echo.HTTPError)
+			if !errors.As(err, &he) {
+				return false
+			}
+			code = he.Code
+		}
+		return code == http.StatusUnauthorized || code == http.StatusForbidden
+	},
+	DenyHandler: func(c echo.Context, err error, retryAfter time.Duration) error {
+		if retryAfter > 0 {
+			c.Response().Header().Set(echo.HeaderRetryAfter, strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10))
+		}
+		return err
+	},
+}
+
+// LoginGuard returns a middleware that defends authentication routes against password guessing and credential
+// stuffing. Failed attempts are counted per account (form field "username") and per client IP address and both
+// are locked out for exponentially growing time when they fail too often.
+//
+// Example:
+//
+//	e.POST("/login", loginHandler, middleware.LoginGuard())
+func LoginGuard() echo.MiddlewareFunc {
+	return LoginGuardWithConfig(DefaultLoginGuardConfig)
+}
+
+// LoginGuardWithConfig returns a LoginGuard middleware with config.
+// See: `LoginGuard()`.
+func LoginGuardWithConfig(config LoginGuardConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultLoginGuardConfig.Skipper
+	}
+	if config.AccountExtractor == nil {
+		config.AccountExtractor = DefaultLoginGuardConfig.AccountExtractor
+	}
+	if config.IPExtractor == nil {
+		config.IPExtractor = DefaultLoginGuardConfig.IPExtractor
+	}
+	if config.Window <= 0 {
+		config.Window = DefaultLoginGuardConfig.Window
+	}
+	if config.MaxAccountFailures <= 0 {
+		config.MaxAccountFailures = DefaultLoginGuardConfig.MaxAccountFailures
+	}
+	if config.MaxIPFailures <= 0 {
+		config.MaxIPFailures = DefaultLoginGuardConfig.MaxIPFailures
+	}
+	if config.Lockout <= 0 {
+		config.Lockout = DefaultLoginGuardConfig.Lockout
+	}
+	if config.MaxLockout < config.Lockout {
+		config.MaxLockout = DefaultLoginGuardConfig.MaxLockout
+		if config.MaxLockout < config.Lockout {
+			config.MaxLockout = config.Lockout
+		}
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = DefaultLoginGuardConfig.IsFailure
+	}
+	if config.DenyHandler == nil {
+		config.DenyHandler = DefaultLoginGuardConfig.DenyHandler
+	}
+	if config.CaptchaThreshold > 0 && config.VerifyCaptcha == nil {
+		panic("echo: login guard middleware requires verify captcha function when captcha threshold is set")
+	}
+
+	g := &loginGuard{
+		config:   config,
+		accounts: map[string]*loginAttempts{},
+		ips:      map[string]*loginAttempts{},
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			account, err := config.AccountExtractor(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest).SetInternal(err)
+			}
+			ip, err := config.IPExtractor(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest).SetInternal(err)
+			}
+
+			retryAfter, failures, ok := g.begin(echo.Now(c), account, ip)
+			if !ok {
+				return config.DenyHandler(c, ErrLoginLocked, retryAfter)
+			}
+			failed, succeeded := false, false
+			// reservation is released also when CAPTCHA is not passed or next handler panics
+			defer func() {
+				g.finish(echo.Now(c), account, ip, failed, succeeded)
+			}()
+
+			if config.CaptchaThreshold > 0 && failures >= config.CaptchaThreshold {
+				ok, err := config.VerifyCaptcha(c)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return config.DenyHandler(c, ErrLoginCaptchaRequired, 0)
+				}
+			}
+
+			err = next(c)
+			failed = config.IsFailure(c, err)
+			succeeded = !failed && err == nil && c.Response().Status < http.StatusBadRequest
+			return err
+		}
+	}
+}
+
+// begin reserves attempt of account and IP address. It returns remaining lockout time and the higher of their
+// failure counts including attempts in progress. Attempt is not reserved when account or IP address is locked or
+// attempts in progress would exceed its limit.
+func (g *loginGuard) begin(now time.Time, account, ip string) (time.Duration, int, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	// clock moving backwards (e.g. fake `Echo#Clock` set to the past) also resets cleanup time
+	if now.Sub(g.lastCleanup) > g.config.Window || now.Before(g.lastCleanup) {
+		g.cleanup(now)
+	}
+
+	retryAfter := time.Duration(0)
+	failures := 0
+	full := false
+	for _, a := range []struct {
+		attempts *loginAttempts
+		max      int
+	}{
+		{attempts: g.accounts[account], max: g.config.MaxAccountFailures},
+		{attempts: g.ips[ip], max: g.config.MaxIPFailures},
+	} {
+		if a.attempts == nil {
+			continue
+		}
+		if d := a.attempts.lockedUntil.Sub(now); d > retryAfter {
+			retryAfter = d
+		}
+		n := a.attempts.count(now, g.config.Window) + a.attempts.pending
+		if n > failures {
+			failures = n
+		}
+		if n >= a.max {
+			full = true
+		}
+	}
+	if retryAfter > 0 || full {
+		return retryAfter, failures, false
+	}
+
+	if account != "" {
+		g.attempts(g.accounts, account).pending++
+	}
+	g.attempts(g.ips, ip).pending++
+	return 0, failures, true
+}
+
+// finish releases attempt reserved by begin and records its result.
+func (g *loginGuard) finish(now time.Time, account, ip string, failed, succeeded bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if account != "" {
+		a := g.attempts(g.accounts, account)
+		a.pending--
+		if failed {
+			g.record(a, now, g.config.MaxAccountFailures)
+		} else if succeeded {
+			// IP address is not reset as credential stuffing attacks also include successful attempts
+			a.failures = a.failures[:0]
+			a.lockouts = 0
+			a.lockedUntil = time.Time{}
+		}
+	}
+	a := g.attempts(g.ips, ip)
+	a.pending--
+	if failed {
+		g.record(a, now, g.config.MaxIPFailures)
+	}
+}
+
+// attempts returns attempts of key, they are created when they do not exist.
+func (g *loginGuard) attempts(attempts map[string]*loginAttempts, key string) *loginAttempts {
+	a, ok := attempts[key]
+	if !ok {
+		a = &loginAttempts{}
+		attempts[key] = a
+	}
+	return a
+}
+
+func (g *loginGuard) record(a *loginAttempts, now time.Time, max int) {
+	if a.count(now, g.config.Window)+1 < max {
+		a.failures = append(a.failures, now)
+		return
+	}
+
+	lockout := g.config.Lockout
+	for i := 0; i < a.lockouts && lockout < g.config.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > g.config.MaxLockout {
+		lockout = g.config.MaxLockout
+	}
+	a.lockouts++
+	a.lockedUntil = now.Add(lockout)
+	// attempts after lockout ends are counted from zero, next lockout is longer
+	a.failures = a.failures[:0]
+}
+
+// cleanup removes accounts and IP addresses without recent failures or lockouts. Escalation of lockouts is kept
+// while failures continue within Window after previous lockout ended.
+func (g *loginGuard) cleanup(now time.Time) {
+	for _, attempts := range []map[string]*loginAttempts{g.accounts, g.ips} {
+		for key, a := range attempts {
+			if a.pending == 0 && a.count(now, g.config.Window) == 0 && now.Sub(a.lockedUntil) > g.config.Window {
+				delete(attempts, key)
+			}
+		}
+	}
+	g.lastCleanup = now
+}
+
+// count removes failures older than window and returns number of remaining ones.
+func (a *loginAttempts) count(now time.Time, window time.Duration) int {
+	i := 0
+	for i < len(a.failures) && now.Sub(a.failures[i]) >= window {
+		i++
+	}
+	a.failures = a.failures[i:]
+	return len(a.failures)
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginGuard(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := echo.New()
+	e.Clock = clock
+	e.POST("/login", func(c echo.Context) error {
+		if c.FormValue("password") != "secret" {
+			return echo.ErrUnauthorized
+		}
+		return c.NoContent(http.StatusNoContent)
+	}, LoginGuardWithConfig(LoginGuardConfig{MaxAccountFailures: 3, Lockout: time.Minute, MaxLockout: 3 * time.Minute}))
+
+	login := func(username, password, ip string) *httptest.ResponseRecorder {
+		form := url.Values{"username": {username}, "password": {password}}
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login("jon", "wrong", "10.0.0.1").Code)
+	}
+	rec := login("jon", "secret", "10.0.0.2")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "account is locked for all IP addresses")
+	assert.Equal(t, "60", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Equal(t, http.StatusNoContent, login("ann", "secret", "10.0.0.1").Code, "other accounts are not locked")
+
+	// second lockout is twice as long
+	clock.Advance(time.Minute)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, login("jon", "wrong", "10.0.0.1").Code)
+	}
+	assert.Equal(t, "120", login("jon", "secret", "10.0.0.1").Header().Get(echo.HeaderRetryAfter))
+
+	// lockout is capped with MaxLockout
+	clock.Advance(2 * time.Minute)
+	for i := 0; i < 3; i++ {
+		login("jon", "wrong", "10.0.0.1")
+	}
+	assert.Equal(t, "180", login("jon", "secret", "10.0.0.1").Header().Get(echo.HeaderRetryAfter))
+
+	// successful login resets failures of account
+	clock.Advance(3 * time.Minute)
+	assert.Equal(t, http.StatusNoContent, login("jon", "secret", "10.0.0.1").Code)
+	for i := 0; i < 2; i++ {
+		login("jon", "wrong", "10.0.0.1")
+	}
+	assert.Equal(t, http.StatusNoContent, login("jon", "secret", "10.0.0.1").Code)
+
+	// failures older than window are not counted
+	for i := 0; i < 2; i++ {
+		login("jon", "wrong", "10.0.0.1")
+	}
+	clock.Advance(15 * time.Minute)
+	login("jon", "wrong", "10.0.0.1")
+	assert.Equal(t, http.StatusNoContent, login("jon", "secret", "10.0.0.1").Code)
+}
+
+func TestLoginGuardWithConfig_ipLockout(t *testing.T) {
+	e := echo.New()
+	e.POST("/login", func(c echo.Context) error {
+		return echo.ErrUnauthorized
+	}, LoginGuardWithConfig(LoginGuardConfig{MaxIPFailures: 3}))
+
+	codes := []int{}
+	for _, username := range []string{"a", "b", "c", "d"} {
+		req := httptest.NewRequest(http.MethodPost, "/login?username="+username, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+}
+
+func TestLoginGuardWithConfig_parallelAttempts(t *testing.T) {
+	e := echo.New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.POST("/login", func(c echo.Context) error {
+		started <- struct{}{}
+		<-release
+		return echo.ErrUnauthorized
+	}, LoginGuardWithConfig(LoginGuardConfig{MaxAccountFailures: 3}))
+
+	login := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/login?username=jon", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	codes := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func() { codes <- login() }()
+		<-started
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login(), "attempts in progress count toward the limit")
+
+	close(release)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnauthorized, <-codes)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login())
+}
+
+func TestLoginGuardWithConfig_captcha(t *testing.T) {
+	e := echo.New()
+	e.POST("/login", func(c echo.Context) error {
+		return c.NoContent(http.StatusForbidden)
+	}, LoginGuardWithConfig(LoginGuardConfig{
+		CaptchaThreshold: 2,
+		VerifyCaptcha: func(c echo.Context) (bool, error) {
+			return c.QueryParam("captcha") == "ok", nil
+		},
+	}))
+
+	request := func(target string) int {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, target, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, request("/login?username=jon"))
+	assert.Equal(t, http.StatusForbidden, request("/login?username=jon"))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/login?username=jon", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "captcha required")
+	assert.Equal(t, http.StatusForbidden, request("/login?username=jon&captcha=ok"), "handler is reached with captcha")
+}
+
+func TestLoginGuardWithConfig_panics(t *testing.T) {
+	assert.Panics(t, func() { LoginGuardWithConfig(LoginGuardConfig{CaptchaThreshold: 1}) })
+}
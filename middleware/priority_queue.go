@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// PriorityQueueConfig defines the config for PriorityQueue middleware.
+	PriorityQueueConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Classes are priority classes with their own concurrency budgets. Requests of one class never wait
+		// for requests of other classes so batch traffic can not starve interactive traffic.
+		// Required.
+		Classes []PriorityClass
+
+		// Classifier returns name of the priority class for the request. Use `PriorityByRoute`,
+		// `PriorityByHeader` or `PriorityByContextKey` or custom function (e.g. by tier of authenticated user).
+		// Required.
+		Classifier PriorityClassifier
+
+		// DefaultClass is used for requests classified with empty or unknown class name.
+		// Optional. Default value is name of the last class in Classes.
+		DefaultClass string
+
+		// ErrorHandler is called with ErrPriorityQueueFull or ErrPriorityQueueTimeout when request is rejected.
+		// Optional. Default value returns the error.
+		ErrorHandler func(c echo.Context, class string, err error) error
+	}
+
+	// PriorityClass defines concurrency budget of requests of one priority class.
+	PriorityClass struct {
+		// Name of the class. Required.
+		Name string `yaml:"name"`
+
+		// Concurrency is maximum number of requests of the class handled at the same time. Required.
+		Concurrency int `yaml:"concurrency"`
+
+		// QueueSize is maximum number of requests waiting for free slot. Requests over the limit are rejected.
+		// Optional. Default value 0 (requests are rejected immediately when all slots are taken).
+		QueueSize int `yaml:"queue_size"`
+
+		// QueueTimeout is maximum time request waits in queue.
+		// Optional. Default value 0 (requests wait until slot is free or request is canceled).
+		QueueTimeout time.Duration `yaml:"queue_timeout"`
+	}
+
+	// PriorityClassifier returns name of the priority class for the request.
+	PriorityClassifier func(c echo.Context) string
+
+	priorityClass struct {
+		// waiting is first to keep it 64-bit aligned for atomic access on 32-bit platforms
+		waiting int64
+		PriorityClass
+		slots chan struct{}
+	}
+)
+
+// Errors
+var (
+	// ErrPriorityQueueFull denotes an error raised when all slots and queue of priority class are taken.
+	ErrPriorityQueueFull = echo.NewHTTPError(http.StatusServiceUnavailable, "request queue is full")
+	// ErrPriorityQueueTimeout denotes an error raised when request waited in queue longer than QueueTimeout.
+	ErrPriorityQueueTimeout = echo.NewHTTPError(http.StatusServiceUnavailable, "request queue timeout")
+)
+
+// DefaultPriorityQueueConfig is the default PriorityQueue middleware config.
+var DefaultPriorityQueueConfig = PriorityQueueConfig{
+	Skipper: DefaultSkipper,
+	ErrorHandler: func(c echo.Context, class string, err error) error {
+		return err
+	},
+}
+
+// PriorityByRoute classifies requests by path of matched route (e.g. `/reports/:id`). Requests to other routes
+// get DefaultClass.
+func PriorityByRoute(routes map[string]string) PriorityClassifier {
+	return func(c echo.Context) string {
+		return routes[c.Path()]
+	}
+}
+
+// PriorityByHeader classifies requests by value of request header (e.g. `X-Priority: interactive`).
+func PriorityByHeader(header string) PriorityClassifier {
+	return func(c echo.Context) string {
+		return c.Request().Header.Get(header)
+	}
+}
+
+// PriorityByContextKey classifies requests by string value stored in context by previous middleware (e.g. tier of
+// authenticated user).
+func PriorityByContextKey(key string) PriorityClassifier {
+	return func(c echo.Context) string {
+		v, _ := c.Get(key).(string)
+		return v
+	}
+}
+
+// PriorityQueue returns a middleware that limits number of concurrently handled requests separately for each
+// priority class. Requests are classified by route path with given route to class name mapping.
+//
+// Example:
+//
+//	e.Use(middleware.PriorityQueue(
+//		[]middleware.PriorityClass{
+//			{Name: "interactive", Concurrency: 100, QueueSize: 200, QueueTimeout: time.Second},
+//			{Name: "batch", Concurrency: 4, QueueSize: 100, QueueTimeout: 30 * time.Second},
+//		},
+//		map[string]string{"/search": "interactive", "/users/:id": "interactive"},
+//	))
+func PriorityQueue(classes []PriorityClass, routes map[string]string) echo.MiddlewareFunc {
+	c := DefaultPriorityQueueConfig
+	c.Classes = classes
+	c.Classifier = PriorityByRoute(routes)
+	return PriorityQueueWithConfig(c)
+}
+
+// PriorityQueueWithConfig returns a PriorityQueue middleware with config.
+// See: `PriorityQueue()`.
+func PriorityQueueWithConfig(config PriorityQueueConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultPriorityQueueConfig.Skipper
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultPriorityQueueConfig.ErrorHandler
+	}
+	if config.Classifier == nil {
+		panic("echo: priority queue middleware requires classifier")
+	}
+	if len(config.Classes) == 0 {
+		panic("echo: priority queue middleware requires at least one class")
+	}
+	classes := make(map[string]*priorityClass, len(config.Classes))
+	for _, pc := range config.Classes {
+		if pc.Concurrency <= 0 {
+			panic(fmt.Sprintf("echo: priority queue class %q requires positive concurrency", pc.Name))
+		}
+		if _, ok := classes[pc.Name]; ok {
+			panic(fmt.Sprintf("echo: priority queue class %q is defined more than once", pc.Name))
+		}
+		classes[pc.Name] = &priorityClass{PriorityClass: pc, slots: make(chan struct{}, pc.Concurrency)}
+	}
+	if config.DefaultClass == "" {
+		config.DefaultClass = config.Classes[len(config.Classes)-1].Name
+	}
+	defaultClass, ok := classes[config.DefaultClass]
+	if !ok {
+		panic(fmt.Sprintf("echo: priority queue default class %q is not defined", config.DefaultClass))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			pc, ok := classes[config.Classifier(c)]
+			if !ok {
+				pc = defaultClass
+			}
+			if err := pc.acquire(c); err != nil {
+				return config.ErrorHandler(c, pc.Name, err)
+			}
+			defer pc.release()
+			return next(c)
+		}
+	}
+}
+
+func (pc *priorityClass) acquire(c echo.Context) error {
+	select {
+	case pc.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if atomic.AddInt64(&pc.waiting, 1) > int64(pc.QueueSize) {
+		atomic.AddInt64(&pc.waiting, -1)
+		return ErrPriorityQueueFull
+	}
+	defer atomic.AddInt64(&pc.waiting, -1)
+
+	var timeout <-chan time.Time
+	if pc.QueueTimeout > 0 {
+		timer := time.NewTimer(pc.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case pc.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return ErrPriorityQueueTimeout
+	case <-c.Request().Context().Done():
+		return c.Request().Context().Err()
+	}
+}
+
+func (pc *priorityClass) release() {
+	<-pc.slots
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	stdContext "context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	e := echo.New()
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	e.Use(PriorityQueue(
+		[]PriorityClass{
+			{Name: "interactive", Concurrency: 1},
+			{Name: "batch", Concurrency: 1},
+		},
+		map[string]string{"/search": "interactive"},
+	))
+	e.GET("/search", func(c echo.Context) error {
+		return c.String(http.StatusOK, "search")
+	})
+	e.GET("/export", func(c echo.Context) error {
+		started <- struct{}{}
+		<-release
+		return c.String(http.StatusOK, "export")
+	})
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+		done <- rec.Code
+	}()
+	<-started
+
+	// batch class budget is taken and its queue is empty
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// interactive class is not affected by batch requests
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "search", rec.Body.String())
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestPriorityQueueWithConfig_queue(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		class     PriorityClass
+		cancel    bool
+		expectErr error
+	}{
+		{
+			name:      "nok, queue timeout",
+			class:     PriorityClass{Name: "premium", Concurrency: 1, QueueSize: 1, QueueTimeout: 10 * time.Millisecond},
+			expectErr: ErrPriorityQueueTimeout,
+		},
+		{
+			name:      "nok, queue full",
+			class:     PriorityClass{Name: "premium", Concurrency: 1},
+			expectErr: ErrPriorityQueueFull,
+		},
+		{
+			name:      "nok, request canceled while waiting",
+			class:     PriorityClass{Name: "premium", Concurrency: 1, QueueSize: 1},
+			cancel:    true,
+			expectErr: stdContext.Canceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			var errClass string
+			mw := PriorityQueueWithConfig(PriorityQueueConfig{
+				Classes:    []PriorityClass{tc.class, {Name: "free", Concurrency: 1}},
+				Classifier: PriorityByHeader("X-Tier"),
+				ErrorHandler: func(c echo.Context, class string, err error) error {
+					errClass = class
+					return err
+				},
+			})
+			release := make(chan struct{})
+			started := make(chan struct{})
+			blocking := mw(func(c echo.Context) error {
+				close(started)
+				<-release
+				return nil
+			})
+			go func() {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("X-Tier", "premium")
+				blocking(e.NewContext(req, httptest.NewRecorder()))
+			}()
+			<-started
+			defer close(release)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Tier", "premium")
+			if tc.cancel {
+				ctx, cancel := stdContext.WithCancel(req.Context())
+				req = req.WithContext(ctx)
+				time.AfterFunc(10*time.Millisecond, cancel)
+			}
+			err := mw(func(c echo.Context) error { return nil })(e.NewContext(req, httptest.NewRecorder()))
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, "premium", errClass)
+
+			// unknown tier uses default (last) class which has free slot
+			req = httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Tier", "unknown")
+			err = mw(func(c echo.Context) error { return nil })(e.NewContext(req, httptest.NewRecorder()))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPriorityByContextKey(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	classifier := PriorityByContextKey("tier")
+	assert.Equal(t, "", classifier(c))
+	c.Set("tier", "premium")
+	assert.Equal(t, "premium", classifier(c))
+}
+
+func TestPriorityQueueWithConfig_panics(t *testing.T) {
+	classifier := PriorityByHeader("X-Tier")
+	assert.Panics(t, func() {
+		PriorityQueueWithConfig(PriorityQueueConfig{Classes: []PriorityClass{{Name: "a", Concurrency: 1}}})
+	})
+	assert.Panics(t, func() {
+		PriorityQueueWithConfig(PriorityQueueConfig{Classifier: classifier})
+	})
+	assert.Panics(t, func() {
+		PriorityQueueWithConfig(PriorityQueueConfig{Classifier: classifier, Classes: []PriorityClass{{Name: "a"}}})
+	})
+	assert.Panics(t, func() {
+		PriorityQueueWithConfig(PriorityQueueConfig{Classifier: classifier, Classes: []PriorityClass{
+			{Name: "a", Concurrency: 1}, {Name: "a", Concurrency: 1},
+		}})
+	})
+	assert.Panics(t, func() {
+		PriorityQueueWithConfig(PriorityQueueConfig{
+			Classifier:   classifier,
+			Classes:      []PriorityClass{{Name: "a", Concurrency: 1}},
+			DefaultClass: "b",
+		})
+	})
+}
@@ -27,9 +27,14 @@ type (
 		Skipper Skipper
 
 		// Balancer defines a load balancing technique.
-		// Required.
+		// Required. Optional when TargetProvider is set (default value is round-robin balancer).
 		Balancer ProxyBalancer
 
+		// TargetProvider discovers targets of Balancer (see DNSSRVTargetProvider, ConsulTargetProvider and
+		// KubernetesTargetProvider). Targets are added to and removed from Balancer as provider reports changes.
+		// Optional.
+		TargetProvider ProxyTargetProvider
+
 		// Rewrite defines URL path rewrite rules. The values captured in asterisk can be
 		// retrieved by index e.g. $1, $2 and so on.
 		// Examples:
@@ -56,6 +61,21 @@ type (
 
 		// ModifyResponse defines function to modify response from ProxyTarget.
 		ModifyResponse func(*http.Response) error
+
+		// PropagateDeadline sends time remaining until deadline of the request context (see Deadline middleware)
+		// to ProxyTarget in `X-Request-Timeout` header (and `Grpc-Timeout` header when client sent it).
+		// Optional. Default value false.
+		PropagateDeadline bool
+
+		// HedgeDelay enables request hedging. When ProxyTarget does not respond within the delay, request is sent
+		// also to another target from Balancer and response that arrives first is used. Only requests without
+		// body with methods in HedgeMethods are hedged.
+		// Optional. Default value 0 (requests are not hedged).
+		HedgeDelay time.Duration
+
+		// HedgeMethods are methods of requests that can be hedged. Methods must be idempotent.
+		// Optional. Default value DefaultProxyHedgeMethods (GET, HEAD, OPTIONS).
+		HedgeMethods []string
 	}
 
 	// ProxyTarget defines the upstream target.
@@ -90,6 +110,9 @@ type (
 	}
 )
 
+// ErrProxyTargetUnavailable denotes an error raised when balancer has no target to proxy request to.
+var ErrProxyTargetUnavailable = echo.NewHTTPError(http.StatusServiceUnavailable, "no proxy target available")
+
 var (
 	// DefaultProxyConfig is the default Proxy middleware config.
 	DefaultProxyConfig = ProxyConfig{
@@ -100,7 +123,7 @@ var (
 
 func proxyRaw(t *ProxyTarget, c echo.Context) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		in, _, err := c.Response().Hijack()
+		in, brw, err := c.Response().Hijack()
 		if err != nil {
 			c.Set("_error", fmt.Sprintf("proxy raw, hijack error=%v, url=%s", t.URL, err))
 			return
@@ -121,6 +144,16 @@ func proxyRaw(t *ProxyTarget, c echo.Context) http.Handler {
 			return
 		}
 
+		// Data client sent after request header may already be read into hijacked connection buffer. Forward
+		// it before copying connections directly so copy between connections can use splice(2).
+		if n := brw.Reader.Buffered(); n > 0 {
+			buffered, _ := brw.Reader.Peek(n)
+			if _, err = out.Write(buffered); err != nil {
+				c.Set("_error", echo.NewHTTPError(http.StatusBadGateway, fmt.Sprintf("proxy raw, request body copy error=%v, url=%s", t.URL, err)))
+				return
+			}
+		}
+
 		errCh := make(chan error, 2)
 		cp := func(dst io.Writer, src io.Reader) {
 			_, err = io.Copy(dst, src)
@@ -152,13 +185,13 @@ func NewRoundRobinBalancer(targets []*ProxyTarget) ProxyBalancer {
 
 // AddTarget adds an upstream target to the list.
 func (b *commonBalancer) AddTarget(target *ProxyTarget) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
 	for _, t := range b.targets {
 		if t.Name == target.Name {
 			return false
 		}
 	}
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
 	b.targets = append(b.targets, target)
 	return true
 }
@@ -176,22 +209,28 @@ func (b *commonBalancer) RemoveTarget(name string) bool {
 	return false
 }
 
-// Next randomly returns an upstream target.
+// Next randomly returns an upstream target. Returns nil when there are no targets.
 func (b *randomBalancer) Next(c echo.Context) *ProxyTarget {
 	if b.random == nil {
 		b.random = rand.New(rand.NewSource(int64(time.Now().Nanosecond())))
 	}
 	b.mutex.RLock()
 	defer b.mutex.RUnlock()
+	if len(b.targets) == 0 {
+		return nil
+	}
 	return b.targets[b.random.Intn(len(b.targets))]
 }
 
-// Next returns an upstream target using round-robin technique.
+// Next returns an upstream target using round-robin technique. Returns nil when there are no targets.
 func (b *roundRobinBalancer) Next(c echo.Context) *ProxyTarget {
-	b.i = b.i % uint32(len(b.targets))
-	t := b.targets[b.i]
-	atomic.AddUint32(&b.i, 1)
-	return t
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if len(b.targets) == 0 {
+		return nil
+	}
+	i := atomic.AddUint32(&b.i, 1) - 1
+	return b.targets[i%uint32(len(b.targets))]
 }
 
 // Proxy returns a Proxy middleware.
@@ -210,9 +249,18 @@ func ProxyWithConfig(config ProxyConfig) echo.MiddlewareFunc {
 	if config.Skipper == nil {
 		config.Skipper = DefaultProxyConfig.Skipper
 	}
+	if config.Balancer == nil && config.TargetProvider != nil {
+		config.Balancer = NewRoundRobinBalancer(nil)
+	}
 	if config.Balancer == nil {
 		panic("echo: proxy middleware requires balancer")
 	}
+	if config.TargetProvider != nil {
+		go watchTargets(config.TargetProvider, config.Balancer)
+	}
+	if len(config.HedgeMethods) == 0 {
+		config.HedgeMethods = DefaultProxyHedgeMethods
+	}
 
 	if config.Rewrite != nil {
 		if config.RegexRewrite == nil {
@@ -232,6 +280,9 @@ func ProxyWithConfig(config ProxyConfig) echo.MiddlewareFunc {
 			req := c.Request()
 			res := c.Response()
 			tgt := config.Balancer.Next(c)
+			if tgt == nil {
+				return ErrProxyTargetUnavailable
+			}
 			c.Set(config.ContextKey, tgt)
 
 			if err := rewriteURL(config.RegexRewrite, req); err != nil {
@@ -250,6 +301,9 @@ func ProxyWithConfig(config ProxyConfig) echo.MiddlewareFunc {
 			if c.IsWebSocket() && req.Header.Get(echo.HeaderXForwardedFor) == "" { // For HTTP, it is automatically set by Go HTTP reverse proxy.
 				req.Header.Set(echo.HeaderXForwardedFor, c.RealIP())
 			}
+			if config.PropagateDeadline {
+				setDeadlineHeaders(req)
+			}
 
 			// Proxy
 			switch {
@@ -298,6 +352,18 @@ func proxyHTTP(tgt *ProxyTarget, c echo.Context, config ProxyConfig) http.Handle
 		}
 	}
 	proxy.Transport = config.Transport
+	if config.HedgeDelay > 0 && hedgeable(c.Request(), config.HedgeMethods) {
+		transport := config.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		proxy.Transport = &hedgeTransport{
+			transport: transport,
+			delay:     config.HedgeDelay,
+			target:    tgt,
+			next:      func() *ProxyTarget { return config.Balancer.Next(c) },
+		}
+	}
 	proxy.ModifyResponse = config.ModifyResponse
 	return proxy
 }
@@ -0,0 +1,472 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// ProxyTargetProvider discovers proxy targets. Watch blocks until ctx is canceled and calls update with complete
+	// list of targets whenever it changes. Targets are identified by Name so names must be unique.
+	ProxyTargetProvider interface {
+		Watch(ctx context.Context, update func(targets []*ProxyTarget)) error
+	}
+
+	// DNSSRVTargetProvider discovers targets from DNS SRV records (`_service._proto.name`). DNS has no change
+	// notifications so records are looked up periodically.
+	DNSSRVTargetProvider struct {
+		// Service, Proto and Name of SRV record. When Service and Proto are empty Name is looked up directly.
+		Service string
+		Proto   string
+		Name    string
+
+		// Scheme of target URLs.
+		// Optional. Default value "http".
+		Scheme string
+
+		// Interval between lookups.
+		// Optional. Default value 30s.
+		Interval time.Duration
+
+		// Resolver used for lookups.
+		// Optional. Default value net.DefaultResolver.
+		Resolver *net.Resolver
+
+		lookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	}
+
+	// ConsulTargetProvider discovers healthy instances of service registered in Consul. Changes are watched with
+	// Consul blocking queries.
+	ConsulTargetProvider struct {
+		// Address of Consul HTTP API.
+		// Optional. Default value "http://127.0.0.1:8500".
+		Address string
+
+		// Service name. Required.
+		Service string
+
+		// Tag filters instances by tag.
+		// Optional.
+		Tag string
+
+		// Token is Consul ACL token.
+		// Optional.
+		Token string
+
+		// Scheme of target URLs.
+		// Optional. Default value "http".
+		Scheme string
+
+		// Client used for Consul API requests. Client must not have timeout shorter than 5 minutes (wait time of
+		// blocking queries).
+		// Optional. Default value client without timeout.
+		Client *http.Client
+	}
+
+	// KubernetesTargetProvider discovers ready addresses of Kubernetes service from its Endpoints object.
+	// Changes are watched with Kubernetes watch API. By default in-cluster service account credentials are used,
+	// service account needs permission to get and watch endpoints.
+	KubernetesTargetProvider struct {
+		// Namespace of the service. Required.
+		Namespace string
+
+		// Service name. Required.
+		Service string
+
+		// Port is name of endpoint port. Can be empty when endpoints have single port.
+		// Optional.
+		Port string
+
+		// Scheme of target URLs.
+		// Optional. Default value "http".
+		Scheme string
+
+		// APIServer is address of Kubernetes API server.
+		// Optional. Default value "https://kubernetes.default.svc".
+		APIServer string
+
+		// TokenFile contains bearer token for API requests.
+		// Optional. Default value "/var/run/secrets/kubernetes.io/serviceaccount/token".
+		TokenFile string
+
+		// Client used for API requests.
+		// Optional. Default value client trusting in-cluster CA certificate.
+		Client *http.Client
+	}
+
+	consulServiceEntry struct {
+		Node struct {
+			Address string
+		}
+		Service struct {
+			Address string
+			Port    int
+		}
+	}
+
+	kubernetesEndpoints struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+		Subsets []struct {
+			Addresses []struct {
+				IP string `json:"ip"`
+			} `json:"addresses"`
+			Ports []struct {
+				Name string `json:"name"`
+				Port int    `json:"port"`
+			} `json:"ports"`
+		} `json:"subsets"`
+	}
+
+	kubernetesWatchEvent struct {
+		Type   string              `json:"type"`
+		Object kubernetesEndpoints `json:"object"`
+	}
+)
+
+const (
+	kubernetesAPIServer = "https://kubernetes.default.svc"
+	kubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	kubernetesCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// watchTargets keeps balancer targets in sync with provider. Watch is restarted after errors.
+func watchTargets(provider ProxyTargetProvider, balancer ProxyBalancer) {
+	current := map[string]bool{}
+	update := func(targets []*ProxyTarget) {
+		next := make(map[string]bool, len(targets))
+		for _, t := range targets {
+			next[t.Name] = true
+			if !current[t.Name] {
+				balancer.AddTarget(t)
+			}
+		}
+		for name := range current {
+			if !next[name] {
+				balancer.RemoveTarget(name)
+			}
+		}
+		current = next
+	}
+	for {
+		if err := provider.Watch(context.Background(), update); err == nil {
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func targetURL(scheme, host string, port int) (*ProxyTarget, error) {
+	if scheme == "" {
+		scheme = "http"
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	u, err := url.Parse(scheme + "://" + addr)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyTarget{Name: addr, URL: u}, nil
+}
+
+// sameTargets reports whether target lists contain same target names.
+func sameTargets(a, b []*ProxyTarget) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+func sortTargets(targets []*ProxyTarget) {
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+}
+
+// Watch looks up SRV records every Interval and calls update when records change.
+func (p *DNSSRVTargetProvider) Watch(ctx context.Context, update func(targets []*ProxyTarget)) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	lookup := p.lookupSRV
+	if lookup == nil {
+		resolver := p.Resolver
+		if resolver == nil {
+			resolver = net.DefaultResolver
+		}
+		lookup = resolver.LookupSRV
+	}
+
+	var last []*ProxyTarget
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, records, err := lookup(ctx, p.Service, p.Proto, p.Name)
+		if err == nil {
+			targets := make([]*ProxyTarget, 0, len(records))
+			for _, r := range records {
+				t, err := targetURL(p.Scheme, strings.TrimSuffix(r.Target, "."), int(r.Port))
+				if err != nil {
+					return err
+				}
+				targets = append(targets, t)
+			}
+			sortTargets(targets)
+			if last == nil || !sameTargets(last, targets) {
+				update(targets)
+				last = targets
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Watch queries healthy service instances with Consul blocking queries and calls update when they change.
+func (p *ConsulTargetProvider) Watch(ctx context.Context, update func(targets []*ProxyTarget)) error {
+	address := p.Address
+	if address == "" {
+		address = "http://127.0.0.1:8500"
+	}
+	client := p.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	index := uint64(0)
+	for {
+		q := url.Values{}
+		q.Set("passing", "true")
+		q.Set("index", strconv.FormatUint(index, 10))
+		q.Set("wait", "5m")
+		if p.Tag != "" {
+			q.Set("tag", p.Tag)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+			strings.TrimSuffix(address, "/")+"/v1/health/service/"+url.PathEscape(p.Service)+"?"+q.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		if p.Token != "" {
+			req.Header.Set("X-Consul-Token", p.Token)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		entries := []consulServiceEntry{}
+		err = json.NewDecoder(res.Body).Decode(&entries)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("consul: unexpected response status=%d", res.StatusCode)
+		}
+		if err != nil {
+			return err
+		}
+
+		newIndex, _ := strconv.ParseUint(res.Header.Get("X-Consul-Index"), 10, 64)
+		if newIndex == index {
+			continue // wait timed out without changes
+		}
+		if newIndex < index {
+			// index going backwards (e.g. Consul restart) resets blocking query
+			newIndex = 0
+		}
+		index = newIndex
+
+		targets := make([]*ProxyTarget, 0, len(entries))
+		for _, e := range entries {
+			host := e.Service.Address
+			if host == "" {
+				host = e.Node.Address
+			}
+			t, err := targetURL(p.Scheme, host, e.Service.Port)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, t)
+		}
+		sortTargets(targets)
+		update(targets)
+	}
+}
+
+// Watch reads Endpoints of the service and watches them for changes.
+func (p *KubernetesTargetProvider) Watch(ctx context.Context, update func(targets []*ProxyTarget)) error {
+	client, err := p.client()
+	if err != nil {
+		return err
+	}
+	server := p.APIServer
+	if server == "" {
+		server = kubernetesAPIServer
+	}
+	base := strings.TrimSuffix(server, "/") + "/api/v1/namespaces/" + url.PathEscape(p.Namespace) + "/endpoints"
+
+	for {
+		endpoints := kubernetesEndpoints{}
+		if err := p.get(ctx, client, base+"/"+url.PathEscape(p.Service), &endpoints); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		targets, err := p.targets(endpoints)
+		if err != nil {
+			return err
+		}
+		update(targets)
+
+		q := url.Values{}
+		q.Set("watch", "true")
+		q.Set("fieldSelector", "metadata.name="+p.Service)
+		q.Set("resourceVersion", endpoints.Metadata.ResourceVersion)
+		if err := p.watch(ctx, client, base+"?"+q.Encode(), update); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		// watch was closed by server, read current state and start new watch
+	}
+}
+
+func (p *KubernetesTargetProvider) client() (*http.Client, error) {
+	if p.Client != nil {
+		return p.Client, nil
+	}
+	ca, err := ioutil.ReadFile(kubernetesCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Transport: transport}, nil
+}
+
+func (p *KubernetesTargetProvider) request(ctx context.Context, u string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	tokenFile := p.TokenFile
+	if tokenFile == "" {
+		tokenFile = kubernetesTokenFile
+	}
+	// token is read on every request as it is rotated by kubelet
+	if token, err := ioutil.ReadFile(tokenFile); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	} else if p.TokenFile != "" {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (p *KubernetesTargetProvider) get(ctx context.Context, client *http.Client, u string, v interface{}) error {
+	req, err := p.request(ctx, u)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("kubernetes: unexpected response status=%d", res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+func (p *KubernetesTargetProvider) watch(ctx context.Context, client *http.Client, u string, update func([]*ProxyTarget)) error {
+	req, err := p.request(ctx, u)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("kubernetes: unexpected watch response status=%d", res.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		event := kubernetesWatchEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return err
+		}
+		switch event.Type {
+		case "ADDED", "MODIFIED":
+			targets, err := p.targets(event.Object)
+			if err != nil {
+				return err
+			}
+			update(targets)
+		case "DELETED":
+			update([]*ProxyTarget{})
+		case "ERROR":
+			// usually expired resource version, start over
+			return nil
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+func (p *KubernetesTargetProvider) targets(endpoints kubernetesEndpoints) ([]*ProxyTarget, error) {
+	targets := []*ProxyTarget{}
+	for _, s := range endpoints.Subsets {
+		port := 0
+		for _, sp := range s.Ports {
+			if p.Port == "" || sp.Name == p.Port {
+				port = sp.Port
+				break
+			}
+		}
+		if port == 0 {
+			continue
+		}
+		for _, a := range s.Addresses {
+			t, err := targetURL(p.Scheme, a.IP, port)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, t)
+		}
+	}
+	sortTargets(targets)
+	return targets, nil
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectTargets runs provider until it reported expected number of updates and returns target names of each update.
+func collectTargets(t *testing.T, provider ProxyTargetProvider, updates int) [][]string {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result := [][]string{}
+	mutex := sync.Mutex{}
+	done := make(chan error, 1)
+	go func() {
+		done <- provider.Watch(ctx, func(targets []*ProxyTarget) {
+			names := []string{}
+			for _, t := range targets {
+				names = append(names, t.URL.String())
+			}
+			mutex.Lock()
+			result = append(result, names)
+			if len(result) == updates {
+				cancel()
+			}
+			mutex.Unlock()
+		})
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for target updates")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	return result
+}
+
+func TestDNSSRVTargetProvider(t *testing.T) {
+	lookups := [][]*net.SRV{
+		{{Target: "b.service.local.", Port: 8080}, {Target: "a.service.local.", Port: 8080}},
+		{{Target: "a.service.local.", Port: 8080}, {Target: "b.service.local.", Port: 8080}}, // no change
+		{{Target: "a.service.local.", Port: 8080}},
+	}
+	i := 0
+	provider := &DNSSRVTargetProvider{
+		Service:  "http",
+		Proto:    "tcp",
+		Name:     "service.local",
+		Interval: time.Millisecond,
+		lookupSRV: func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+			assert.Equal(t, "http", service)
+			records := lookups[i%len(lookups)]
+			i++
+			return "", records, nil
+		},
+	}
+
+	assert.Equal(t, [][]string{
+		{"http://a.service.local:8080", "http://b.service.local:8080"},
+		{"http://a.service.local:8080"},
+	}, collectTargets(t, provider, 2))
+}
+
+func TestConsulTargetProvider(t *testing.T) {
+	responses := []struct {
+		index string
+		body  string
+	}{
+		{index: "10", body: `[{"Node":{"Address":"10.0.0.1"},"Service":{"Address":"","Port":80}},{"Node":{"Address":"10.0.0.9"},"Service":{"Address":"10.0.0.2","Port":81}}]`},
+		{index: "10", body: `[]`}, // blocking query timed out
+		{index: "11", body: `[{"Node":{"Address":"10.0.0.1"},"Service":{"Address":"","Port":80}}]`},
+	}
+	calls := 0
+	mutex := sync.Mutex{}
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert.Equal(t, "/v1/health/service/web", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("passing"))
+		assert.Equal(t, "primary", r.URL.Query().Get("tag"))
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+		if calls > 0 {
+			assert.Equal(t, "10", r.URL.Query().Get("index"))
+		}
+		res := responses[calls%len(responses)]
+		calls++
+		w.Header().Set("X-Consul-Index", res.index)
+		w.Write([]byte(res.body))
+	}))
+	defer consul.Close()
+
+	provider := &ConsulTargetProvider{Address: consul.URL, Service: "web", Tag: "primary", Token: "secret"}
+	assert.Equal(t, [][]string{
+		{"http://10.0.0.1:80", "http://10.0.0.2:81"},
+		{"http://10.0.0.1:80"},
+	}, collectTargets(t, provider, 2))
+}
+
+func TestKubernetesTargetProvider(t *testing.T) {
+	endpoints := `{"metadata":{"resourceVersion":"100"},"subsets":[{"addresses":[{"ip":"10.1.0.2"},{"ip":"10.1.0.1"}],"ports":[{"name":"metrics","port":9090},{"name":"http","port":8080}]}]}`
+	k8s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/default/endpoints/web":
+			w.Write([]byte(endpoints))
+		case "/api/v1/namespaces/default/endpoints":
+			assert.Equal(t, "true", r.URL.Query().Get("watch"))
+			assert.Equal(t, "metadata.name=web", r.URL.Query().Get("fieldSelector"))
+			assert.Equal(t, "100", r.URL.Query().Get("resourceVersion"))
+			fmt.Fprintln(w, `{"type":"MODIFIED","object":{"subsets":[{"addresses":[{"ip":"10.1.0.3"}],"ports":[{"name":"http","port":8080}]}]}}`)
+			w.(http.Flusher).Flush()
+			fmt.Fprintln(w, `{"type":"DELETED","object":{}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer k8s.Close()
+
+	provider := &KubernetesTargetProvider{
+		Namespace: "default",
+		Service:   "web",
+		Port:      "http",
+		APIServer: k8s.URL,
+		Client:    k8s.Client(),
+	}
+	assert.Equal(t, [][]string{
+		{"http://10.1.0.1:8080", "http://10.1.0.2:8080"},
+		{"http://10.1.0.3:8080"},
+		{},
+	}, collectTargets(t, provider, 3))
+}
+
+type staticTargetProvider struct {
+	updates [][]*ProxyTarget
+	done    chan struct{}
+}
+
+func (p *staticTargetProvider) Watch(ctx context.Context, update func(targets []*ProxyTarget)) error {
+	for _, targets := range p.updates {
+		update(targets)
+	}
+	close(p.done)
+	<-ctx.Done()
+	return nil
+}
+
+func TestProxy_targetProvider(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("upstream"))
+	}))
+	defer upstream.Close()
+	u, _ := url.Parse(upstream.URL)
+	gone, _ := url.Parse("http://127.0.0.1:1")
+
+	provider := &staticTargetProvider{
+		updates: [][]*ProxyTarget{
+			{{Name: "gone", URL: gone}},
+			{{Name: "upstream", URL: u}},
+		},
+		done: make(chan struct{}),
+	}
+	balancer := NewRoundRobinBalancer(nil)
+	e := echo.New()
+	e.Use(ProxyWithConfig(ProxyConfig{Balancer: balancer, TargetProvider: provider}))
+	<-provider.done
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "upstream", rec.Body.String())
+	}
+}
+
+func TestProxy_noTargets(t *testing.T) {
+	e := echo.New()
+	e.Use(Proxy(NewRandomBalancer(nil)))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
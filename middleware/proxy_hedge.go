@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type (
+	// hedgeTransport sends request to second ProxyTarget when first one does not respond within delay and uses
+	// whichever response arrives first.
+	hedgeTransport struct {
+		transport http.RoundTripper
+		delay     time.Duration
+		target    *ProxyTarget
+		next      func() *ProxyTarget
+	}
+
+	hedgeResult struct {
+		index int
+		res   *http.Response
+		err   error
+	}
+
+	// cancelBody cancels context of the winning attempt when response body is closed.
+	cancelBody struct {
+		io.ReadCloser
+		cancel context.CancelFunc
+	}
+)
+
+// DefaultProxyHedgeMethods are methods of requests hedged when `ProxyConfig.HedgeMethods` is not set.
+var DefaultProxyHedgeMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// hedgeable reports whether request can be sent to multiple targets. Only idempotent requests without body are
+// hedged as body can be read only once.
+func hedgeable(req *http.Request, methods []string) bool {
+	if req.ContentLength != 0 || (req.Body != nil && req.Body != http.NoBody) {
+		return false
+	}
+	for _, m := range methods {
+		if m == req.Method {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *hedgeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	cancels := make([]context.CancelFunc, 0, 2)
+	send := func(r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		cancels = append(cancels, cancel)
+		index := len(cancels) - 1
+		go func() {
+			res, err := t.transport.RoundTrip(r.WithContext(ctx))
+			results <- hedgeResult{index: index, res: res, err: err}
+		}()
+	}
+
+	send(req)
+	pending := 1
+	timer := time.NewTimer(t.delay)
+	defer timer.Stop()
+	hedge := timer.C
+	var err error
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.err != nil {
+				cancels[r.index]()
+				err = r.err
+				continue
+			}
+			for i, cancel := range cancels {
+				if i != r.index {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go discardHedgeResults(results, pending)
+			}
+			r.res.Body = &cancelBody{ReadCloser: r.res.Body, cancel: cancels[r.index]}
+			return r.res, nil
+		case <-hedge:
+			hedge = nil
+			if second := t.hedgeRequest(req); second != nil {
+				send(second)
+				pending++
+			}
+		}
+	}
+	return nil, err
+}
+
+// hedgeRequest returns copy of request directed to another target or nil when balancer has no other target.
+func (t *hedgeTransport) hedgeRequest(req *http.Request) *http.Request {
+	var target *ProxyTarget
+	for i := 0; i < 3; i++ {
+		if tgt := t.next(); tgt != nil && tgt.URL.String() != t.target.URL.String() {
+			target = tgt
+			break
+		}
+	}
+	if target == nil {
+		return nil
+	}
+	r := req.Clone(req.Context())
+	r.URL = retargetURL(req.URL, t.target.URL, target.URL)
+	r.Body = http.NoBody
+	return r
+}
+
+// retargetURL returns u (directed to `from` target) directed to `to` target.
+func retargetURL(u, from, to *url.URL) *url.URL {
+	nu := *u
+	nu.Scheme = to.Scheme
+	nu.Host = to.Host
+	nu.RawPath = ""
+	path := strings.TrimPrefix(u.Path, strings.TrimSuffix(from.Path, "/"))
+	nu.Path = strings.TrimSuffix(to.Path, "/") + path
+	if !strings.HasPrefix(nu.Path, "/") {
+		nu.Path = "/" + nu.Path
+	}
+	return &nu
+}
+
+func discardHedgeResults(results <-chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		if r := <-results; r.res != nil {
+			r.res.Body.Close()
+		}
+	}
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxy_hedge(t *testing.T) {
+	var slowCanceled int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			w.Write([]byte("slow"))
+		case <-r.Context().Done():
+			atomic.AddInt32(&slowCanceled, 1)
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast" + r.URL.Path))
+	}))
+	defer fast.Close()
+	slowURL, _ := url.Parse(slow.URL)
+	fastURL, _ := url.Parse(fast.URL)
+
+	var testCases = []struct {
+		name       string
+		method     string
+		body       string
+		expectBody string
+	}{
+		{
+			name:       "ok, GET is hedged",
+			method:     http.MethodGet,
+			expectBody: "fast/users",
+		},
+		{
+			name:       "ok, POST is not hedged",
+			method:     http.MethodPost,
+			expectBody: "slow",
+		},
+		{
+			name:       "ok, request with body is not hedged",
+			method:     http.MethodGet,
+			body:       "data",
+			expectBody: "slow",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(ProxyWithConfig(ProxyConfig{
+				Balancer:   NewRoundRobinBalancer([]*ProxyTarget{{URL: slowURL}, {URL: fastURL}}),
+				HedgeDelay: 20 * time.Millisecond,
+			}))
+
+			req := httptest.NewRequest(tc.method, "/users", strings.NewReader(tc.body))
+			if tc.body == "" {
+				req.Body = http.NoBody
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+		})
+	}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&slowCanceled) == 1
+	}, time.Second, 10*time.Millisecond, "request to slow target is canceled")
+}
+
+func TestProxy_hedgeSingleTarget(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("only"))
+	}))
+	defer upstream.Close()
+	u, _ := url.Parse(upstream.URL)
+
+	e := echo.New()
+	e.Use(ProxyWithConfig(ProxyConfig{
+		Balancer:   NewRandomBalancer([]*ProxyTarget{{URL: u}}),
+		HedgeDelay: 10 * time.Millisecond,
+	}))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "only", rec.Body.String())
+}
+
+func TestRetargetURL(t *testing.T) {
+	from, _ := url.Parse("http://a:8080/api")
+	to, _ := url.Parse("https://b/v2/")
+	u, _ := url.Parse("http://a:8080/api/users?id=1")
+
+	assert.Equal(t, "https://b/v2/users?id=1", retargetURL(u, from, to).String())
+}
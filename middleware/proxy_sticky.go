@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// StickyBalancerConfig defines the config for sticky session balancer.
+	StickyBalancerConfig struct {
+		// Cookie is name of cookie pinning client to target. When set, target chosen for client without (valid)
+		// cookie is stored in the cookie and used for following requests as long as target exists. When empty,
+		// target is chosen only by consistent hash of the key.
+		// Optional. Default value "" (no cookie).
+		Cookie string `yaml:"cookie"`
+
+		// KeyExtractor returns key of the client that is hashed to choose target.
+		// Optional. Default value returns `Context#RealIP()`.
+		KeyExtractor func(c echo.Context) string
+
+		// Replicas is number of points of each target on consistent hash ring. More points distribute keys more
+		// evenly.
+		// Optional. Default value 100.
+		Replicas int `yaml:"replicas"`
+	}
+
+	stickyBalancer struct {
+		*commonBalancer
+		config StickyBalancerConfig
+		ring   []ringPoint
+	}
+
+	ringPoint struct {
+		hash   uint32
+		target *ProxyTarget
+	}
+)
+
+// DefaultStickyBalancerConfig is the default sticky session balancer config.
+var DefaultStickyBalancerConfig = StickyBalancerConfig{
+	KeyExtractor: func(c echo.Context) string {
+		return c.RealIP()
+	},
+	Replicas: 100,
+}
+
+// NewStickyBalancer returns a proxy balancer that sends requests of the same client (by IP address hash) to the
+// same target. Targets are chosen with consistent hashing so when target is removed only its clients are moved to
+// other targets.
+func NewStickyBalancer(targets []*ProxyTarget) ProxyBalancer {
+	return NewStickyBalancerWithConfig(targets, DefaultStickyBalancerConfig)
+}
+
+// NewStickyBalancerWithConfig returns a sticky session proxy balancer with config.
+// See: `NewStickyBalancer()`.
+func NewStickyBalancerWithConfig(targets []*ProxyTarget, config StickyBalancerConfig) ProxyBalancer {
+	// Defaults
+	if config.KeyExtractor == nil {
+		config.KeyExtractor = DefaultStickyBalancerConfig.KeyExtractor
+	}
+	if config.Replicas <= 0 {
+		config.Replicas = DefaultStickyBalancerConfig.Replicas
+	}
+	b := &stickyBalancer{commonBalancer: new(commonBalancer), config: config}
+	b.targets = targets
+	b.buildRing()
+	return b
+}
+
+// AddTarget adds an upstream target to the list.
+func (b *stickyBalancer) AddTarget(target *ProxyTarget) bool {
+	if !b.commonBalancer.AddTarget(target) {
+		return false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.buildRing()
+	return true
+}
+
+// RemoveTarget removes an upstream target from the list.
+func (b *stickyBalancer) RemoveTarget(name string) bool {
+	if !b.commonBalancer.RemoveTarget(name) {
+		return false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.buildRing()
+	return true
+}
+
+// buildRing rebuilds consistent hash ring. Must be called with mutex locked.
+func (b *stickyBalancer) buildRing() {
+	ring := make([]ringPoint, 0, len(b.targets)*b.config.Replicas)
+	for _, t := range b.targets {
+		for i := 0; i < b.config.Replicas; i++ {
+			ring = append(ring, ringPoint{hash: hashString(t.Name + "#" + strconv.Itoa(i)), target: t})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+	b.ring = ring
+}
+
+// Next returns target of the client. Returns nil when there are no targets.
+func (b *stickyBalancer) Next(c echo.Context) *ProxyTarget {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if len(b.ring) == 0 {
+		return nil
+	}
+
+	if b.config.Cookie != "" {
+		if cookie, err := c.Cookie(b.config.Cookie); err == nil {
+			for _, t := range b.targets {
+				if cookie.Value == targetCookieValue(t) {
+					return t
+				}
+			}
+		}
+	}
+
+	h := hashString(b.config.KeyExtractor(c))
+	i := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= h })
+	if i == len(b.ring) {
+		i = 0
+	}
+	t := b.ring[i].target
+
+	if b.config.Cookie != "" {
+		c.SetCookie(&http.Cookie{
+			Name:     b.config.Cookie,
+			Value:    targetCookieValue(t),
+			Path:     "/",
+			HttpOnly: true,
+		})
+	}
+	return t
+}
+
+// targetCookieValue returns value identifying target in cookie without revealing target address.
+func targetCookieValue(t *ProxyTarget) string {
+	return strconv.FormatUint(uint64(hashString(t.Name)), 36)
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
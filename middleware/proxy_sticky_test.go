@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stickyTargets(names ...string) []*ProxyTarget {
+	targets := []*ProxyTarget{}
+	for _, n := range names {
+		u, _ := url.Parse("http://" + n)
+		targets = append(targets, &ProxyTarget{Name: n, URL: u})
+	}
+	return targets
+}
+
+func stickyContext(e *echo.Echo, ip string, cookie *http.Cookie) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = ip + ":1234"
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestStickyBalancer(t *testing.T) {
+	e := echo.New()
+	b := NewStickyBalancer(stickyTargets("a:80", "b:80", "c:80"))
+
+	before := map[string]string{}
+	used := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		ip := fmt.Sprintf("10.0.%d.%d", i/250, i%250)
+		c, _ := stickyContext(e, ip, nil)
+		tgt := b.Next(c)
+		require.NotNil(t, tgt)
+		before[ip] = tgt.Name
+		used[tgt.Name] = true
+
+		c, _ = stickyContext(e, ip, nil)
+		assert.Equal(t, tgt.Name, b.Next(c).Name, "same client gets same target")
+	}
+	assert.Len(t, used, 3, "clients are distributed to all targets")
+
+	assert.True(t, b.RemoveTarget("b:80"))
+	assert.False(t, b.RemoveTarget("b:80"))
+	for ip, name := range before {
+		c, _ := stickyContext(e, ip, nil)
+		tgt := b.Next(c)
+		if name == "b:80" {
+			assert.NotEqual(t, "b:80", tgt.Name)
+		} else {
+			assert.Equal(t, name, tgt.Name, "clients of remaining targets are not moved")
+		}
+	}
+
+	assert.True(t, b.AddTarget(stickyTargets("b:80")[0]))
+	for ip, name := range before {
+		c, _ := stickyContext(e, ip, nil)
+		assert.Equal(t, name, b.Next(c).Name, "clients return to re-added target")
+	}
+}
+
+func TestStickyBalancer_cookie(t *testing.T) {
+	e := echo.New()
+	b := NewStickyBalancerWithConfig(stickyTargets("a:80", "b:80"), StickyBalancerConfig{Cookie: "backend"})
+
+	c, rec := stickyContext(e, "10.0.0.1", nil)
+	first := b.Next(c)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "backend", cookies[0].Name)
+	assert.NotContains(t, cookies[0].Value, "80")
+
+	// client keeps its target even when IP address changes
+	for i := 0; i < 20; i++ {
+		c, rec = stickyContext(e, fmt.Sprintf("192.168.0.%d", i), &http.Cookie{Name: "backend", Value: cookies[0].Value})
+		assert.Equal(t, first.Name, b.Next(c).Name)
+		assert.Len(t, rec.Result().Cookies(), 0)
+	}
+
+	// cookie of removed target is replaced
+	b.RemoveTarget(first.Name)
+	c, rec = stickyContext(e, "10.0.0.1", &http.Cookie{Name: "backend", Value: cookies[0].Value})
+	assert.NotEqual(t, first.Name, b.Next(c).Name)
+	assert.Len(t, rec.Result().Cookies(), 1)
+}
+
+func TestStickyBalancer_empty(t *testing.T) {
+	e := echo.New()
+	b := NewStickyBalancer(nil)
+	c, _ := stickyContext(e, "10.0.0.1", nil)
+	assert.Nil(t, b.Next(c))
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// RateLimitProblem is problem details (RFC 7807) body of 429 Too Many Requests responses sent by `RateLimiter`
+	// and quota middlewares. It is passed as error to deny and error handlers of the middlewares, so custom handlers
+	// can send it with `RateLimitProblem#Send()` or use it in their own responses.
+	RateLimitProblem struct {
+		// Type is URI identifying the problem type.
+		// Default value "about:blank".
+		Type string `json:"type"`
+		// Title is short summary of the problem type.
+		// Default value "Too Many Requests".
+		Title string `json:"title"`
+		// Status is HTTP status code of the response.
+		Status int `json:"status"`
+		// Detail is explanation specific to this occurrence of the problem.
+		Detail string `json:"detail,omitempty"`
+		// Policy is name of the exceeded rate limit policy or quota.
+		Policy string `json:"policy,omitempty"`
+		// Limit is maximum number of requests (or bytes of bandwidth quotas) allowed by the policy.
+		Limit int64 `json:"limit"`
+		// Remaining is number of requests remaining before the limit is reached.
+		Remaining int64 `json:"remaining"`
+		// Reset is time when the limit is reset and requests are allowed again.
+		Reset time.Time `json:"reset"`
+		// RetryAfter is number of seconds until Reset, it is also sent as `Retry-After` header.
+		RetryAfter int64 `json:"retry_after"`
+		// Internal is error that caused the rejection, i.e. ErrRateLimitExceeded.
+		Internal error `json:"-"`
+	}
+
+	// RateLimitProblemFunc customizes problem before it is passed to deny handler, i.e. translates title and detail
+	// to language of the request or links documentation of the plan of the tenant (identifier).
+	RateLimitProblemFunc func(c echo.Context, identifier string, problem *RateLimitProblem)
+)
+
+// NewRateLimitProblem creates problem of request rejected by policy at time now.
+func NewRateLimitProblem(internal error, policy string, limit, remaining int64, reset, now time.Time) *RateLimitProblem {
+	p := &RateLimitProblem{
+		Type:      "about:blank",
+		Title:     http.StatusText(http.StatusTooManyRequests),
+		Status:    http.StatusTooManyRequests,
+		Policy:    policy,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     reset,
+		Internal:  internal,
+	}
+	if he, ok := internal.(*echo.HTTPError); ok {
+		if msg, ok := he.Message.(string); ok {
+			p.Detail = msg
+		}
+	}
+	if wait := reset.Sub(now); wait > 0 {
+		p.RetryAfter = int64((wait + time.Second - 1) / time.Second)
+	}
+	return p
+}
+
+// Error returns detail of the problem.
+func (p *RateLimitProblem) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+// Unwrap returns internal error so `errors.Is(err, ErrRateLimitExceeded)` works with the problem.
+func (p *RateLimitProblem) Unwrap() error {
+	return p.Internal
+}
+
+// Send responds with the problem as `application/problem+json` and sets `Retry-After` header.
+func (p *RateLimitProblem) Send(c echo.Context) error {
+	if p.RetryAfter > 0 {
+		c.Response().Header().Set(echo.HeaderRetryAfter, strconv.FormatInt(p.RetryAfter, 10))
+	}
+	return echo.Encode(c, p.Status, echo.MIMEApplicationProblemJSON, p)
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimitProblem(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p := NewRateLimitProblem(ErrRateLimitExceeded, "api", 10, 0, now.Add(1500*time.Millisecond), now)
+	assert.Equal(t, &RateLimitProblem{
+		Type:       "about:blank",
+		Title:      "Too Many Requests",
+		Status:     http.StatusTooManyRequests,
+		Detail:     "rate limit exceeded",
+		Policy:     "api",
+		Limit:      10,
+		Reset:      now.Add(1500 * time.Millisecond),
+		RetryAfter: 2,
+		Internal:   ErrRateLimitExceeded,
+	}, p)
+	assert.EqualError(t, p, "rate limit exceeded")
+	assert.True(t, errors.Is(p, ErrRateLimitExceeded))
+
+	p = NewRateLimitProblem(errors.New("store failed"), "", 0, 0, now, now)
+	assert.Empty(t, p.Detail)
+	assert.Equal(t, int64(0), p.RetryAfter)
+	assert.EqualError(t, p, "Too Many Requests")
+}
+
+func TestRateLimitProblem_Send(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	p := NewRateLimitProblem(ErrRateLimitExceeded, "api", 10, 0, now.Add(time.Minute), now)
+	p.Type = "https://example.com/problems/rate-limit"
+
+	assert.NoError(t, p.Send(c))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "60", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.JSONEq(t, `{"type":"https://example.com/problems/rate-limit","title":"Too Many Requests","status":429,
+		"detail":"rate limit exceeded","policy":"api","limit":10,"remaining":0,"reset":"2021-01-01T00:01:00Z",
+		"retry_after":60}`, rec.Body.String())
+}
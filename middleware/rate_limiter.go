@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"container/list"
+	"math"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +19,35 @@ type (
 		// Stores for the rate limiter have to implement the Allow method
 		Allow(identifier string) (bool, error)
 	}
+
+	// RateLimiterInfoStore is implemented by stores able to describe limit of denied identifier in 429 responses.
+	RateLimiterInfoStore interface {
+		RateLimiterStore
+		// Info returns state of rate limit of the identifier.
+		Info(identifier string) (RateLimitInfo, error)
+	}
+
+	// RateLimiterCostStore is implemented by stores supporting requests with cost other than 1, see
+	// `RateLimiterConfig.Cost`. Other stores count every request as 1.
+	RateLimiterCostStore interface {
+		RateLimiterStore
+		// AllowN reports whether request with cost is allowed and deducts the cost when it is. Requests with
+		// cost 0 are allowed.
+		AllowN(identifier string, cost int) (bool, error)
+		// Charge deducts cost of already allowed request even when it exceeds the remaining limit, following
+		// requests are denied until the debt is paid off.
+		Charge(identifier string, cost int) error
+	}
+
+	// RateLimitInfo is state of rate limit of an identifier.
+	RateLimitInfo struct {
+		// Limit is maximum number of requests allowed at once (burst).
+		Limit int64
+		// Remaining is number of requests allowed at the moment.
+		Remaining int64
+		// Reset is time when next request is allowed.
+		Reset time.Time
+	}
 )
 
 type (
@@ -28,8 +61,44 @@ type (
 		Store RateLimiterStore
 		// ErrorHandler provides a handler to be called when IdentifierExtractor returns an error
 		ErrorHandler func(context echo.Context, err error) error
-		// DenyHandler provides a handler to be called when RateLimiter denies access
+		// DenyHandler provides a handler to be called when RateLimiter denies access. Error is error of the store,
+		// nil when the limit was exceeded. Problem describing the exceeded limit is available with
+		// `RateLimitProblemOf()`, `RateLimitProblemDenyHandler` sends it as the response.
 		DenyHandler func(context echo.Context, identifier string, err error) error
+		// Policy is name of the rate limit policy reported in 429 responses, i.e. "api-per-ip".
+		// Optional.
+		Policy string
+		// Problem customizes problem details of denied requests, i.e. translates them or adds link to
+		// documentation of the plan of the identifier.
+		// Optional.
+		Problem RateLimitProblemFunc
+		// Exempt defines requests passed without being limited or counted, so infrastructure traffic does not
+		// consume limits of users.
+		// Optional.
+		Exempt RateLimiterExemptions
+		// Cost returns cost of the request deducted from the limit, i.e. by requested page size or complexity of
+		// GraphQL query computed by previous middleware. Cost above burst of the store is limited to the burst.
+		// Requests with cost 0 are free and always allowed. Handlers can charge additional cost after the request
+		// is allowed with `AddRateLimitCost`. Costs are supported by stores implementing RateLimiterCostStore.
+		// Optional. Default value returns cost set with `Route#RateLimitCost()` or 1.
+		Cost func(c echo.Context) int
+	}
+
+	// RateLimiterExemptions defines requests exempted from rate limiting. Request matching any of the rules is
+	// exempted.
+	RateLimiterExemptions struct {
+		// Networks of trusted clients (internal services, monitoring) matched against `Context#RealIP()`.
+		Networks []*net.IPNet
+		// ServiceAccounts are authenticated accounts returned by AccountExtractor which are exempted.
+		ServiceAccounts []string
+		// AccountExtractor returns authenticated account of the request, i.e. subject of token validated by
+		// JWT middleware registered before the rate limiter. Errors and empty accounts are not exempted.
+		// Required when ServiceAccounts are set.
+		AccountExtractor Extractor
+		// UserAgents are prefixes of `User-Agent` header of health checkers, i.e. "kube-probe/" or
+		// "ELB-HealthChecker/". NB: header is sent by the client and can be forged, use it only for routes
+		// where bypassing the limit is harmless.
+		UserAgents []string
 	}
 	// Extractor is used to extract data from echo.Context
 	Extractor func(context echo.Context) (string, error)
@@ -43,6 +112,13 @@ var (
 	ErrExtractorError = echo.NewHTTPError(http.StatusForbidden, "error while extracting identifier")
 )
 
+const (
+	// rateLimitCostContextKey is context key of additional cost of the request added by `AddRateLimitCost`.
+	rateLimitCostContextKey = "echo_rate_limit_cost"
+	// rateLimitProblemContextKey is context key of problem of denied request returned by `RateLimitProblemOf`.
+	rateLimitProblemContextKey = "echo_rate_limit_problem"
+)
+
 // DefaultRateLimiterConfig defines default values for RateLimiterConfig
 var DefaultRateLimiterConfig = RateLimiterConfig{
 	Skipper: DefaultSkipper,
@@ -57,7 +133,20 @@ var DefaultRateLimiterConfig = RateLimiterConfig{
 			Internal: err,
 		}
 	},
+	Cost: func(c echo.Context) int {
+		if r := echo.RouteOf(c); r != nil {
+			if cost, ok := r.GetMeta(echo.MetaRateLimitCost).(int); ok {
+				return cost
+			}
+		}
+		return 1
+	},
 	DenyHandler: func(context echo.Context, identifier string, err error) error {
+		if err == nil {
+			if p := RateLimitProblemOf(context); p != nil {
+				err = p
+			}
+		}
 		return &echo.HTTPError{
 			Code:     ErrRateLimitExceeded.Code,
 			Message:  ErrRateLimitExceeded.Message,
@@ -66,6 +155,30 @@ var DefaultRateLimiterConfig = RateLimiterConfig{
 	},
 }
 
+// RateLimitProblemDenyHandler is DenyHandler responding with problem details of the exceeded limit as 429
+// `application/problem+json` response. Errors of the store are returned as the default DenyHandler does.
+//
+// Example:
+//
+//	e.Use(middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+//		Store:       middleware.NewRateLimiterMemoryStore(20),
+//		Policy:      "api-per-ip",
+//		DenyHandler: middleware.RateLimitProblemDenyHandler,
+//	}))
+func RateLimitProblemDenyHandler(c echo.Context, identifier string, err error) error {
+	if p := RateLimitProblemOf(c); err == nil && p != nil {
+		return p.Send(c)
+	}
+	return DefaultRateLimiterConfig.DenyHandler(c, identifier, err)
+}
+
+// RateLimitProblemOf returns problem describing limit exceeded by the request denied by RateLimiter or nil when
+// the request was not denied.
+func RateLimitProblemOf(c echo.Context) *RateLimitProblem {
+	p, _ := c.Get(rateLimitProblemContextKey).(*RateLimitProblem)
+	return p
+}
+
 /*
 RateLimiter returns a rate limiting middleware
 
@@ -123,9 +236,15 @@ func RateLimiterWithConfig(config RateLimiterConfig) echo.MiddlewareFunc {
 	if config.DenyHandler == nil {
 		config.DenyHandler = DefaultRateLimiterConfig.DenyHandler
 	}
+	if config.Cost == nil {
+		config.Cost = DefaultRateLimiterConfig.Cost
+	}
 	if config.Store == nil {
 		panic("Store configuration must be provided")
 	}
+	if len(config.Exempt.ServiceAccounts) > 0 && config.Exempt.AccountExtractor == nil {
+		panic("echo: rate limiter exemption of service accounts requires account extractor")
+	}
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if config.Skipper(c) {
@@ -134,6 +253,9 @@ func RateLimiterWithConfig(config RateLimiterConfig) echo.MiddlewareFunc {
 			if config.BeforeFunc != nil {
 				config.BeforeFunc(c)
 			}
+			if config.Exempt.matches(c) {
+				return next(c)
+			}
 
 			identifier, err := config.IdentifierExtractor(c)
 			if err != nil {
@@ -141,15 +263,110 @@ func RateLimiterWithConfig(config RateLimiterConfig) echo.MiddlewareFunc {
 				return nil
 			}
 
-			if allow, err := config.Store.Allow(identifier); !allow {
-				c.Error(config.DenyHandler(c, identifier, err))
+			var allow bool
+			switch store := config.Store.(type) {
+			case clockRateLimiterStore:
+				allow, err = store.allowNAt(identifier, config.Cost(c), echo.Now(c))
+			case RateLimiterCostStore:
+				allow, err = store.AllowN(identifier, config.Cost(c))
+			default:
+				allow, err = config.Store.Allow(identifier)
+			}
+			if !allow {
+				if err == nil {
+					var p *RateLimitProblem
+					if p, err = config.problem(c, identifier); err == nil {
+						c.Set(rateLimitProblemContextKey, p)
+					}
+				}
+				if err = config.DenyHandler(c, identifier, err); err != nil {
+					c.Error(err)
+				}
 				return nil
 			}
-			return next(c)
+
+			err = next(c)
+			if cost, ok := c.Get(rateLimitCostContextKey).(int); ok && cost > 0 {
+				var cErr error
+				switch store := config.Store.(type) {
+				case clockRateLimiterStore:
+					cErr = store.chargeAt(identifier, cost, echo.Now(c))
+				case RateLimiterCostStore:
+					cErr = store.Charge(identifier, cost)
+				}
+				if cErr != nil {
+					c.Logger().Error(cErr)
+				}
+			}
+			return err
 		}
 	}
 }
 
+// AddRateLimitCost adds cost charged by RateLimiter after the handler returns, i.e. by number of items returned
+// by GraphQL query. Cost is charged even when it exceeds the remaining limit, so following requests of the client
+// are denied until it is paid off.
+func AddRateLimitCost(c echo.Context, cost int) {
+	if cost <= 0 {
+		return
+	}
+	prev, _ := c.Get(rateLimitCostContextKey).(int)
+	c.Set(rateLimitCostContextKey, prev+cost)
+}
+
+// matches reports whether request is exempted by any of the rules.
+func (e RateLimiterExemptions) matches(c echo.Context) bool {
+	if len(e.Networks) > 0 {
+		if ip := net.ParseIP(c.RealIP()); ip != nil {
+			for _, n := range e.Networks {
+				if n.Contains(ip) {
+					return true
+				}
+			}
+		}
+	}
+	if len(e.UserAgents) > 0 {
+		ua := c.Request().UserAgent()
+		for _, prefix := range e.UserAgents {
+			if strings.HasPrefix(ua, prefix) {
+				return true
+			}
+		}
+	}
+	if len(e.ServiceAccounts) > 0 {
+		if account, err := e.AccountExtractor(c); err == nil && account != "" {
+			for _, a := range e.ServiceAccounts {
+				if a == account {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// problem returns problem describing limit of denied identifier. Limit details are known only for stores
+// implementing RateLimiterInfoStore.
+func (config RateLimiterConfig) problem(c echo.Context, identifier string) (*RateLimitProblem, error) {
+	now := echo.Now(c)
+	info := RateLimitInfo{Reset: now}
+	var err error
+	switch store := config.Store.(type) {
+	case clockRateLimiterInfoStore:
+		info, err = store.infoAt(identifier, now)
+	case RateLimiterInfoStore:
+		info, err = store.Info(identifier)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := NewRateLimitProblem(ErrRateLimitExceeded, config.Policy, info.Limit, info.Remaining, info.Reset, now)
+	if config.Problem != nil {
+		config.Problem(c, identifier, p)
+	}
+	return p, nil
+}
+
 type (
 	// RateLimiterMemoryStore is the built-in store implementation for RateLimiter
 	RateLimiterMemoryStore struct {
@@ -160,11 +377,22 @@ type (
 		burst       int
 		expiresIn   time.Duration
 		lastCleanup time.Time
+
+		warmUp      time.Duration
+		burstDecay  float64
+		maxVisitors int
+		lru         *list.List // identifiers of visitors, most recently seen first
 	}
 	// Visitor signifies a unique user's limiter details
 	Visitor struct {
 		*rate.Limiter
 		lastSeen time.Time
+
+		element    *list.Element // element of visitor in LRU list
+		burstLevel float64       // fraction of store burst available to visitor, see `RateLimiterMemoryStoreConfig.WarmUp`
+		levelAt    time.Time
+		tokens     float64   // tokens of the limiter at tokensAt, mirrored by the store to report remaining requests
+		tokensAt   time.Time // rate.Limiter of golang.org/x/time version used does not expose its tokens
 	}
 )
 
@@ -217,7 +445,14 @@ func NewRateLimiterMemoryStoreWithConfig(config RateLimiterMemoryStoreConfig) (s
 	if config.Burst == 0 {
 		store.burst = int(config.Rate)
 	}
+	if config.BurstDecay < 0 || config.BurstDecay > 1 {
+		panic("echo: rate limiter burst decay must be between 0 and 1")
+	}
+	store.warmUp = config.WarmUp
+	store.burstDecay = config.BurstDecay
+	store.maxVisitors = config.MaxVisitors
 	store.visitors = make(map[string]*Visitor)
+	store.lru = list.New()
 	store.lastCleanup = now()
 	return
 }
@@ -227,6 +462,17 @@ type RateLimiterMemoryStoreConfig struct {
 	Rate      rate.Limit    // Rate of requests allowed to pass as req/s. For more info check out Limiter docs - https://pkg.go.dev/golang.org/x/time/rate#Limit.
 	Burst     int           // Burst additionally allows a number of requests to pass when rate limit is reached
 	ExpiresIn time.Duration // ExpiresIn is the duration after that a rate limiter is cleaned up
+
+	// WarmUp is period during which burst of a new visitor grows linearly from 1 to Burst, so clients rotating
+	// their addresses can not use full burst of every address immediately. Zero value disables warm-up.
+	WarmUp time.Duration
+	// BurstDecay is fraction (0-1) of current burst a visitor loses each time it is denied, so clients
+	// continuously hitting the limit are shaped to the rate. Lost burst is regained linearly over WarmUp (or
+	// ExpiresIn when WarmUp is not set). Zero value disables decay.
+	BurstDecay float64
+	// MaxVisitors bounds number of tracked visitors and so memory used by the store. When it is reached, the
+	// least recently seen visitor is evicted. Zero value means no bound.
+	MaxVisitors int
 }
 
 // DefaultRateLimiterMemoryStoreConfig provides default configuration values for RateLimiterMemoryStore
@@ -234,21 +480,241 @@ var DefaultRateLimiterMemoryStoreConfig = RateLimiterMemoryStoreConfig{
 	ExpiresIn: 3 * time.Minute,
 }
 
+// clockRateLimiterStore is implemented by stores that can use time from `Echo#Clock` instead of the system time.
+type clockRateLimiterStore interface {
+	allowNAt(identifier string, cost int, t time.Time) (bool, error)
+	chargeAt(identifier string, cost int, t time.Time) error
+}
+
+// clockRateLimiterInfoStore is clockRateLimiterStore able to describe limit of identifier.
+type clockRateLimiterInfoStore interface {
+	infoAt(identifier string, t time.Time) (RateLimitInfo, error)
+}
+
 // Allow implements RateLimiterStore.Allow
 func (store *RateLimiterMemoryStore) Allow(identifier string) (bool, error) {
+	return store.allowAt(identifier, now())
+}
+
+// AllowN implements RateLimiterCostStore.AllowN
+func (store *RateLimiterMemoryStore) AllowN(identifier string, cost int) (bool, error) {
+	return store.allowNAt(identifier, cost, now())
+}
+
+// Charge implements RateLimiterCostStore.Charge
+func (store *RateLimiterMemoryStore) Charge(identifier string, cost int) error {
+	return store.chargeAt(identifier, cost, now())
+}
+
+func (store *RateLimiterMemoryStore) allowAt(identifier string, t time.Time) (bool, error) {
+	return store.allowNAt(identifier, 1, t)
+}
+
+func (store *RateLimiterMemoryStore) allowNAt(identifier string, cost int, t time.Time) (bool, error) {
+	if cost <= 0 {
+		// free requests are allowed even to visitors in debt
+		return true, nil
+	}
+	// limiter is used only under store lock, so tokens mirrored by visitor match tokens of the limiter
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	limiter := store.visitorAt(identifier, t)
+	store.shapeBurstAt(limiter, t)
+	n := limitCost(limiter, cost)
+	allowed := limiter.AllowN(t, n)
+	if allowed {
+		limiter.take(t, n)
+	} else if store.burstDecay > 0 {
+		limiter.burstLevel *= 1 - store.burstDecay
+		limiter.levelAt = t
+		limiter.setBurstAt(t, store.burstAt(limiter.burstLevel))
+	}
+	return allowed, nil
+}
+
+func (store *RateLimiterMemoryStore) chargeAt(identifier string, cost int, t time.Time) error {
 	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	limiter := store.visitorAt(identifier, t)
+	store.shapeBurstAt(limiter, t)
+	// reservation is kept, tokens may go negative
+	n := limitCost(limiter, cost)
+	limiter.ReserveN(t, n)
+	limiter.take(t, n)
+	return nil
+}
+
+// visitorAt returns visitor seen at t, it is created when it does not exist. Expired visitors are evicted.
+func (store *RateLimiterMemoryStore) visitorAt(identifier string, t time.Time) *Visitor {
 	limiter, exists := store.visitors[identifier]
 	if !exists {
-		limiter = new(Visitor)
-		limiter.Limiter = rate.NewLimiter(store.rate, store.burst)
-		store.visitors[identifier] = limiter
+		limiter = store.newVisitor(identifier, t)
+	} else if limiter.element != nil {
+		store.lru.MoveToFront(limiter.element)
+	}
+	limiter.lastSeen = t
+	store.evictExpiredAt(t)
+	// clock moving backwards (e.g. fake `Echo#Clock` set to the past) also resets cleanup time
+	if t.Sub(store.lastCleanup) > store.expiresIn || t.Before(store.lastCleanup) {
+		store.cleanupStaleVisitorsAt(t)
+	}
+	return limiter
+}
+
+// limitCost limits cost to burst of the limiter, limiter never allows more tokens than its burst.
+func limitCost(limiter *Visitor, cost int) int {
+	if cost < 0 {
+		return 0
+	}
+	if b := limiter.Burst(); cost > b {
+		return b
+	}
+	return cost
+}
+
+// newVisitor adds visitor and evicts the least recently seen visitors above MaxVisitors.
+func (store *RateLimiterMemoryStore) newVisitor(identifier string, t time.Time) *Visitor {
+	if store.maxVisitors > 0 {
+		for len(store.visitors) >= store.maxVisitors && store.lru.Len() > 0 {
+			store.removeElement(store.lru.Back())
+		}
+	}
+	v := &Visitor{burstLevel: 1, levelAt: t}
+	if store.warmUp > 0 {
+		v.burstLevel = 0
+	}
+	v.Limiter = rate.NewLimiter(store.rate, store.burstAt(v.burstLevel))
+	v.tokens = float64(v.Burst())
+	v.tokensAt = t
+	v.element = store.lru.PushFront(identifier)
+	store.visitors[identifier] = v
+	return v
+}
+
+// evictExpiredAt removes visitors not seen for ExpiresIn from the end of LRU list, so every visitor expires on
+// its own and not only when all visitors are cleaned up.
+func (store *RateLimiterMemoryStore) evictExpiredAt(t time.Time) {
+	for e := store.lru.Back(); e != nil; e = store.lru.Back() {
+		if v, ok := store.visitors[e.Value.(string)]; ok && t.Sub(v.lastSeen) <= store.expiresIn {
+			return
+		}
+		store.removeElement(e)
+	}
+}
+
+// removeElement removes element from LRU list together with its visitor.
+func (store *RateLimiterMemoryStore) removeElement(e *list.Element) {
+	store.lru.Remove(e)
+	id := e.Value.(string)
+	if v, ok := store.visitors[id]; ok && v.element == e {
+		delete(store.visitors, id)
+	}
+}
+
+// shapeBurstAt regains burst of visitor lost by warm-up or decay.
+func (store *RateLimiterMemoryStore) shapeBurstAt(v *Visitor, t time.Time) {
+	if v.burstLevel >= 1 {
+		return
+	}
+	recovery := store.warmUp
+	if recovery <= 0 {
+		recovery = store.expiresIn
+	}
+	elapsed := t.Sub(v.levelAt)
+	if elapsed <= 0 {
+		return
+	}
+	v.burstLevel += float64(elapsed) / float64(recovery)
+	if v.burstLevel > 1 {
+		v.burstLevel = 1
+	}
+	// limiter caps tokens at burst when it is changed, so increased burst is set at time of previous shaping
+	// to let tokens refilled since then fill it
+	if b := store.burstAt(v.burstLevel); b != v.Burst() {
+		v.setBurstAt(v.levelAt, b)
+	}
+	v.levelAt = t
+}
+
+// burstAt returns burst of visitor with burst level between 1 (at level 0) and store burst (at level 1).
+func (store *RateLimiterMemoryStore) burstAt(level float64) int {
+	if store.burst <= 1 {
+		return store.burst
+	}
+	return 1 + int(level*float64(store.burst-1))
+}
+
+// Info implements RateLimiterInfoStore.Info
+func (store *RateLimiterMemoryStore) Info(identifier string) (RateLimitInfo, error) {
+	return store.infoAt(identifier, now())
+}
+
+func (store *RateLimiterMemoryStore) infoAt(identifier string, t time.Time) (RateLimitInfo, error) {
+	info := RateLimitInfo{Limit: int64(store.burst), Remaining: int64(store.burst), Reset: t}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	visitor, exists := store.visitors[identifier]
+	if !exists {
+		if store.warmUp > 0 {
+			info.Limit = int64(store.burstAt(0))
+			info.Remaining = info.Limit
+		}
+		return info, nil
+	}
+	// burst regained since the last request is applied as it would be by the next request
+	store.shapeBurstAt(visitor, t)
+	info.Limit = int64(visitor.Burst())
+	tokens := visitor.tokensAtTime(t)
+	limit := visitor.Limit()
+	remaining := math.Floor(tokens)
+	// limiter allows request when the missing part of token is refilled in less than a nanosecond
+	if limit > 0 && remaining < float64(info.Limit) && durationFromTokens(limit, remaining+1-tokens) == 0 {
+		remaining++
+	}
+	if remaining >= 1 {
+		info.Remaining = int64(remaining)
+		return info, nil
 	}
-	limiter.lastSeen = now()
-	if now().Sub(store.lastCleanup) > store.expiresIn {
-		store.cleanupStaleVisitors()
+	info.Remaining = 0
+	if limit > 0 {
+		info.Reset = t.Add(durationFromTokens(limit, 1-tokens))
 	}
-	store.mutex.Unlock()
-	return limiter.AllowN(now(), 1), nil
+	return info, nil
+}
+
+// durationFromTokens returns duration the limit needs to refill tokens, computed as rate.Limiter does.
+func durationFromTokens(limit rate.Limit, tokens float64) time.Duration {
+	return time.Nanosecond * time.Duration(1e9*(tokens/float64(limit)))
+}
+
+// tokensAtTime returns tokens of the visitor limiter refilled until t.
+func (v *Visitor) tokensAtTime(t time.Time) float64 {
+	limit := v.Limit()
+	if limit == rate.Inf {
+		return float64(v.Burst())
+	}
+	tokens := v.tokens
+	if elapsed := t.Sub(v.tokensAt); elapsed > 0 && limit > 0 {
+		// computed as rate.Limiter does to get the same rounding
+		tokens += float64(elapsed/time.Second)*float64(limit) + float64(elapsed%time.Second)*float64(limit)/1e9
+	}
+	if b := float64(v.Burst()); tokens > b {
+		tokens = b
+	}
+	return tokens
+}
+
+// take records n tokens taken from the visitor limiter at t.
+func (v *Visitor) take(t time.Time, n int) {
+	v.tokens = v.tokensAtTime(t) - float64(n)
+	v.tokensAt = t
+}
+
+// setBurstAt sets burst of the visitor limiter at t, tokens above the new burst are lost.
+func (v *Visitor) setBurstAt(t time.Time, burst int) {
+	v.tokens = v.tokensAtTime(t)
+	v.tokensAt = t
+	v.SetBurstAt(t, burst)
 }
 
 /*
@@ -256,12 +722,19 @@ cleanupStaleVisitors helps manage the size of the visitors map by removing stale
 of users who haven't visited again after the configured expiry time has elapsed
 */
 func (store *RateLimiterMemoryStore) cleanupStaleVisitors() {
+	store.cleanupStaleVisitorsAt(now())
+}
+
+func (store *RateLimiterMemoryStore) cleanupStaleVisitorsAt(t time.Time) {
 	for id, visitor := range store.visitors {
-		if now().Sub(visitor.lastSeen) > store.expiresIn {
+		if t.Sub(visitor.lastSeen) > store.expiresIn {
 			delete(store.visitors, id)
+			if visitor.element != nil {
+				store.lru.Remove(visitor.element)
+			}
 		}
 	}
-	store.lastCleanup = now()
+	store.lastCleanup = t
 }
 
 /*
@@ -4,13 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
 	"github.com/labstack/gommon/random"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/time/rate"
@@ -458,3 +461,462 @@ func BenchmarkRateLimiterMemoryStore_conc100_10000(b *testing.B) {
 	var store = NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 100, Burst: 200, ExpiresIn: testExpiresIn})
 	benchmarkStore(store, 100, 10000, b)
 }
+
+func TestRateLimiterWithConfig_clock(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := echo.New()
+	e.Clock = clock
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 1, ExpiresIn: time.Minute})
+	e.Use(RateLimiterWithConfig(RateLimiterConfig{Store: store}))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "test")
+	})
+
+	get := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderXRealIP, "127.0.0.1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, http.StatusTooManyRequests, get())
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, http.StatusTooManyRequests, get())
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, get())
+
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, http.StatusOK, get())
+	assert.Len(t, store.visitors, 1)
+	assert.True(t, clock.Now().Equal(store.lastCleanup))
+}
+
+func TestRateLimiterWithConfig_problem(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := echo.New()
+	e.Clock = clock
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 0.5, Burst: 2})
+	e.Use(RateLimiterWithConfig(RateLimiterConfig{
+		Store:       store,
+		Policy:      "per-ip",
+		DenyHandler: RateLimitProblemDenyHandler,
+		Problem: func(c echo.Context, identifier string, p *RateLimitProblem) {
+			if c.Request().Header.Get(echo.HeaderAcceptLanguage) == "de" {
+				p.Detail = "Zu viele Anfragen von " + identifier
+			}
+		},
+	}))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "test")
+	})
+
+	get := func(lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderXRealIP, "127.0.0.1")
+		req.Header.Set(echo.HeaderAcceptLanguage, lang)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, get("en").Code)
+	assert.Equal(t, http.StatusOK, get("en").Code)
+
+	clock.Advance(500 * time.Millisecond)
+	rec := get("en")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "2", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Too Many Requests","status":429,"detail":"rate limit exceeded",
+		"policy":"per-ip","limit":2,"remaining":0,"reset":"2021-01-01T00:00:02Z","retry_after":2}`, rec.Body.String())
+
+	rec = get("de")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"detail":"Zu viele Anfragen von 127.0.0.1"`)
+
+	clock.Advance(1500 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, get("en").Code, "problem does not consume requests")
+}
+
+func TestRateLimiterWithConfig_problemToCustomDenyHandler(t *testing.T) {
+	var denyErr error
+	var problem *RateLimitProblem
+	mw := RateLimiterWithConfig(RateLimiterConfig{
+		Store: NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 1}),
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			denyErr = err
+			problem = RateLimitProblemOf(c)
+			return ErrRateLimitExceeded
+		},
+	})
+	h := mw(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	e := echo.New()
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		_ = h(e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec))
+	}
+
+	assert.NoError(t, denyErr)
+	if assert.NotNil(t, problem) {
+		assert.Equal(t, int64(1), problem.Limit)
+		assert.True(t, errors.Is(problem, ErrRateLimitExceeded))
+	}
+}
+
+func TestRateLimiterWithConfig_defaultDenyHandlerProblem(t *testing.T) {
+	e := echo.New()
+	e.Use(RateLimiterWithConfig(RateLimiterConfig{
+		Store: NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 1}),
+	}))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	var handled error
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		handled = err
+		e.DefaultHTTPErrorHandler(err, c)
+	}
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationJSONCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `{"message":"rate limit exceeded"}`+"\n", rec.Body.String())
+	var p *RateLimitProblem
+	if assert.True(t, errors.As(handled, &p)) {
+		assert.Equal(t, int64(1), p.Limit)
+		assert.Equal(t, int64(0), p.Remaining)
+	}
+}
+
+func TestRateLimiterMemoryStore_Info(t *testing.T) {
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 2})
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	info, err := store.infoAt("a", now)
+	assert.NoError(t, err)
+	assert.Equal(t, RateLimitInfo{Limit: 2, Remaining: 2, Reset: now}, info)
+
+	store.allowAt("a", now)
+	info, _ = store.infoAt("a", now)
+	assert.Equal(t, RateLimitInfo{Limit: 2, Remaining: 1, Reset: now}, info)
+
+	store.allowAt("a", now)
+	info, _ = store.infoAt("a", now)
+	assert.Equal(t, RateLimitInfo{Limit: 2, Remaining: 0, Reset: now.Add(time.Second)}, info)
+
+	info, _ = store.infoAt("a", now.Add(1500*time.Millisecond))
+	assert.Equal(t, RateLimitInfo{Limit: 2, Remaining: 1, Reset: now.Add(1500 * time.Millisecond)}, info)
+
+	info, _ = store.infoAt("a", now.Add(time.Minute))
+	assert.Equal(t, RateLimitInfo{Limit: 2, Remaining: 2, Reset: now.Add(time.Minute)}, info)
+
+	allowed, _ := store.allowAt("a", now.Add(time.Second))
+	assert.True(t, allowed, "info does not consume requests")
+
+	assert.NoError(t, store.chargeAt("a", 2, now.Add(time.Second)))
+	info, _ = store.infoAt("a", now.Add(time.Second))
+	assert.Equal(t, RateLimitInfo{Limit: 2, Remaining: 0, Reset: now.Add(4 * time.Second)}, info, "debt is paid off first")
+}
+
+func TestRateLimiterMemoryStore_InfoMatchesLimiter(t *testing.T) {
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{
+		Rate:       2,
+		Burst:      6,
+		WarmUp:     3 * time.Second,
+		BurstDecay: 0.5,
+	})
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Duration(i*300) * time.Millisecond)
+		info, err := store.infoAt("a", now)
+		assert.NoError(t, err)
+		allowed := int64(0)
+		for ok, _ := store.allowAt("a", now); ok; ok, _ = store.allowAt("a", now) {
+			allowed++
+		}
+		assert.Equal(t, info.Remaining, allowed)
+	}
+}
+
+func TestRateLimiterMemoryStore_warmUp(t *testing.T) {
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 5, WarmUp: 4 * time.Second})
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	allowed := func(t time.Time, n int) int {
+		count := 0
+		for i := 0; i < n; i++ {
+			if ok, _ := store.allowAt("a", t); ok {
+				count++
+			}
+		}
+		return count
+	}
+
+	assert.Equal(t, 1, allowed(start, 5), "new visitor starts with burst of 1")
+	assert.Equal(t, 2, allowed(start.Add(2*time.Second), 5), "burst 3 after half of warm-up, 2 tokens refilled")
+	assert.Equal(t, 5, allowed(start.Add(10*time.Second), 10), "full burst after warm-up")
+
+	other := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 5})
+	count := 0
+	for i := 0; i < 10; i++ {
+		if ok, _ := other.allowAt("a", start); ok {
+			count++
+		}
+	}
+	assert.Equal(t, 5, count, "full burst without warm-up")
+}
+
+func TestRateLimiterMemoryStore_burstDecay(t *testing.T) {
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{
+		Rate:       1,
+		Burst:      9,
+		BurstDecay: 0.5,
+		ExpiresIn:  8 * time.Second,
+	})
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 9; i++ {
+		ok, _ := store.allowAt("a", start)
+		assert.True(t, ok)
+	}
+	ok, _ := store.allowAt("a", start)
+	assert.False(t, ok)
+	assert.Equal(t, 5, store.visitors["a"].Burst(), "half of burst is lost")
+	ok, _ = store.allowAt("a", start)
+	assert.False(t, ok)
+	assert.Equal(t, 3, store.visitors["a"].Burst())
+
+	// burst is regained over ExpiresIn
+	ok, _ = store.allowAt("a", start.Add(2*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 5, store.visitors["a"].Burst())
+	ok, _ = store.allowAt("a", start.Add(7*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 9, store.visitors["a"].Burst())
+}
+
+func TestRateLimiterMemoryStore_maxVisitors(t *testing.T) {
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 1, MaxVisitors: 2})
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store.allowAt("a", now)
+	store.allowAt("b", now)
+	store.allowAt("a", now) // "b" is least recently seen
+	store.allowAt("c", now)
+
+	assert.Len(t, store.visitors, 2)
+	assert.Contains(t, store.visitors, "a")
+	assert.Contains(t, store.visitors, "c")
+	assert.Equal(t, 2, store.lru.Len())
+
+	for i := 0; i < 1000; i++ {
+		store.allowAt(fmt.Sprintf("10.0.%d.%d", i/256, i%256), now)
+	}
+	assert.Len(t, store.visitors, 2)
+	assert.Equal(t, 2, store.lru.Len())
+}
+
+func TestRateLimiterMemoryStore_expiresPerVisitor(t *testing.T) {
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 1, ExpiresIn: time.Minute})
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.lastCleanup = start
+
+	store.allowAt("a", start)
+	store.allowAt("b", start.Add(30*time.Second))
+	store.allowAt("c", start.Add(61*time.Second))
+
+	assert.NotContains(t, store.visitors, "a", "expired before periodic cleanup")
+	assert.Contains(t, store.visitors, "b")
+	assert.Equal(t, 2, store.lru.Len())
+}
+
+func TestNewRateLimiterMemoryStoreWithConfig_panics(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, BurstDecay: 1.5})
+	})
+}
+
+func TestRateLimiterWithConfig_exempt(t *testing.T) {
+	_, internal, _ := net.ParseCIDR("10.0.0.0/8")
+	var testCases = []struct {
+		name        string
+		whenIP      string
+		whenUA      string
+		whenAccount string
+		expectCodes []int
+	}{
+		{
+			name:        "limited",
+			whenIP:      "203.0.113.1",
+			whenUA:      "curl/7.68.0",
+			expectCodes: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:        "trusted network",
+			whenIP:      "10.1.2.3",
+			expectCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:        "health checker",
+			whenIP:      "203.0.113.1",
+			whenUA:      "kube-probe/1.20",
+			expectCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:        "service account",
+			whenIP:      "203.0.113.1",
+			whenAccount: "billing-service",
+			expectCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:        "other account",
+			whenIP:      "203.0.113.1",
+			whenAccount: "jon",
+			expectCodes: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 0.001, Burst: 1})
+			e := echo.New()
+			e.Use(RateLimiterWithConfig(RateLimiterConfig{
+				Store: store,
+				Exempt: RateLimiterExemptions{
+					Networks:        []*net.IPNet{internal},
+					ServiceAccounts: []string{"billing-service"},
+					AccountExtractor: func(c echo.Context) (string, error) {
+						return c.Request().Header.Get("X-Account"), nil
+					},
+					UserAgents: []string{"kube-probe/", "ELB-HealthChecker/"},
+				},
+			}))
+			e.GET("/", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			codes := make([]int, 0, len(tc.expectCodes))
+			for range tc.expectCodes {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set(echo.HeaderXRealIP, tc.whenIP)
+				req.Header.Set(echo.HeaderUserAgent, tc.whenUA)
+				req.Header.Set("X-Account", tc.whenAccount)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				codes = append(codes, rec.Code)
+			}
+			assert.Equal(t, tc.expectCodes, codes)
+			if tc.expectCodes[1] == http.StatusOK {
+				assert.Empty(t, store.visitors, "exempted requests are not counted")
+			}
+		})
+	}
+}
+
+func TestRateLimiterWithConfig_exemptPanics(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: rate limiter exemption of service accounts requires account extractor", func() {
+		RateLimiterWithConfig(RateLimiterConfig{
+			Store:  NewRateLimiterMemoryStore(1),
+			Exempt: RateLimiterExemptions{ServiceAccounts: []string{"ci"}},
+		})
+	})
+}
+
+func TestRateLimiterWithConfig_cost(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 1, Burst: 5})
+	e := echo.New()
+	e.Clock = clock
+	e.Use(RateLimiter(store))
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	e.GET("/", handler)
+	e.GET("/export", handler).RateLimitCost(3)
+	e.GET("/health", handler).RateLimitCost(0)
+	e.GET("/search", func(c echo.Context) error {
+		AddRateLimitCost(c, 4)
+		return c.NoContent(http.StatusOK)
+	})
+
+	var testCases = []struct {
+		whenURL    string
+		expectCode int
+	}{
+		{whenURL: "/health", expectCode: http.StatusOK},
+		{whenURL: "/export", expectCode: http.StatusOK},
+		{whenURL: "/export", expectCode: http.StatusTooManyRequests},
+		{whenURL: "/", expectCode: http.StatusOK},
+		{whenURL: "/search", expectCode: http.StatusOK},
+		{whenURL: "/", expectCode: http.StatusTooManyRequests},
+		{whenURL: "/health", expectCode: http.StatusOK},
+	}
+	for i, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+		req.Header.Set(echo.HeaderXRealIP, "203.0.113.1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, tc.expectCode, rec.Code, "request %d to %s", i, tc.whenURL)
+	}
+
+	// search charged 4 after last token was used, debt is paid off after 4 seconds
+	clock.Advance(4 * time.Second)
+	allowed, _ := store.allowAt("203.0.113.1", clock.Now())
+	assert.False(t, allowed)
+	clock.Advance(time.Second)
+	allowed, _ = store.allowAt("203.0.113.1", clock.Now())
+	assert.True(t, allowed)
+}
+
+func TestRateLimiterWithConfig_costFunc(t *testing.T) {
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 0.001, Burst: 10})
+	e := echo.New()
+	e.Use(RateLimiterWithConfig(RateLimiterConfig{
+		Store: store,
+		Cost: func(c echo.Context) int {
+			size, _ := strconv.Atoi(c.QueryParam("size"))
+			return size
+		},
+	}))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	codes := make([]int, 0, 4)
+	for _, size := range []string{"6", "5", "4", "100"} {
+		req := httptest.NewRequest(http.MethodGet, "/?size="+size, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK, http.StatusTooManyRequests}, codes)
+}
+
+func TestRateLimiterMemoryStore_AllowN(t *testing.T) {
+	store := NewRateLimiterMemoryStoreWithConfig(RateLimiterMemoryStoreConfig{Rate: 0.001, Burst: 3})
+
+	allowed, err := store.AllowN("a", 10)
+	assert.NoError(t, err)
+	assert.True(t, allowed, "cost is limited to burst")
+	allowed, _ = store.AllowN("a", 1)
+	assert.False(t, allowed)
+	allowed, _ = store.AllowN("a", 0)
+	assert.True(t, allowed, "free request")
+
+	assert.NoError(t, store.Charge("b", 2))
+	allowed, _ = store.AllowN("b", 1)
+	assert.True(t, allowed)
+	assert.NoError(t, store.Charge("b", 2))
+	allowed, _ = store.AllowN("b", 1)
+	assert.False(t, allowed)
+}
@@ -30,6 +30,11 @@ type (
 		// LogLevel is log level to printing stack trace.
 		// Optional. Default value 0 (Print).
 		LogLevel log.Lvl
+
+		// Policy is panic recovery policy of routes without policy set on the route or its group (see
+		// `Route#RecoveryPolicy()` and `Group#RecoveryPolicy`).
+		// Optional. Default value echo.RecoveryRespond.
+		Policy echo.RecoveryPolicy
 	}
 )
 
@@ -41,6 +46,7 @@ var (
 		DisableStackAll:   false,
 		DisablePrintStack: false,
 		LogLevel:          0,
+		Policy:            echo.RecoveryRespond,
 	}
 )
 
@@ -50,6 +56,13 @@ func Recover() echo.MiddlewareFunc {
 	return RecoverWithConfig(DefaultRecoverConfig)
 }
 
+// recoverCrash crashes the process with the panic value. Panic raised in new goroutine can not be recovered by
+// net/http server.
+var recoverCrash = func(r interface{}) {
+	go panic(r)
+	select {}
+}
+
 // RecoverWithConfig returns a Recover middleware with config.
 // See: `Recover()`.
 func RecoverWithConfig(config RecoverConfig) echo.MiddlewareFunc {
@@ -60,6 +73,9 @@ func RecoverWithConfig(config RecoverConfig) echo.MiddlewareFunc {
 	if config.StackSize == 0 {
 		config.StackSize = DefaultRecoverConfig.StackSize
 	}
+	if config.Policy == echo.RecoveryDefault {
+		config.Policy = echo.RecoveryRespond
+	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -67,35 +83,69 @@ func RecoverWithConfig(config RecoverConfig) echo.MiddlewareFunc {
 				return next(c)
 			}
 
-			defer func() {
-				if r := recover(); r != nil {
-					err, ok := r.(error)
-					if !ok {
-						err = fmt.Errorf("%v", r)
-					}
-					stack := make([]byte, config.StackSize)
-					length := runtime.Stack(stack, !config.DisableStackAll)
-					if !config.DisablePrintStack {
-						msg := fmt.Sprintf("[PANIC RECOVER] %v %s\n", err, stack[:length])
-						switch config.LogLevel {
-						case log.DEBUG:
-							c.Logger().Debug(msg)
-						case log.INFO:
-							c.Logger().Info(msg)
-						case log.WARN:
-							c.Logger().Warn(msg)
-						case log.ERROR:
-							c.Logger().Error(msg)
-						case log.OFF:
-							// None.
-						default:
-							c.Logger().Print(msg)
-						}
+			policy := echo.RecoveryPolicyOf(c)
+			if policy == echo.RecoveryDefault {
+				policy = config.Policy
+			}
+			r, err := config.call(next, c)
+			if r == nil {
+				return err
+			}
+			switch policy {
+			case echo.RecoveryRetry:
+				if !c.Response().Committed {
+					if r, err = config.call(next, c); r == nil {
+						return err
 					}
-					c.Error(err)
 				}
-			}()
-			return next(c)
+			case echo.RecoveryCrash:
+				recoverCrash(r)
+			}
+
+			err, ok := r.(error)
+			if !ok {
+				err = fmt.Errorf("%v", r)
+			}
+			c.Error(err)
+			return nil
+		}
+	}
+}
+
+// call calls the handler and returns recovered panic value (after logging the panic) or error of the handler.
+func (config RecoverConfig) call(next echo.HandlerFunc, c echo.Context) (recovered interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			recovered = r
+			config.logPanic(c, r)
 		}
+	}()
+	return nil, next(c)
+}
+
+func (config RecoverConfig) logPanic(c echo.Context, r interface{}) {
+	if config.DisablePrintStack {
+		return
+	}
+	stack := make([]byte, config.StackSize)
+	length := runtime.Stack(stack, !config.DisableStackAll)
+	panicMsg := c.Echo().Redactor.Text(fmt.Sprint(r))
+	msg := fmt.Sprintf("[PANIC RECOVER] %s %s\n", panicMsg, stack[:length])
+	if values := echo.LogValues(c); values != nil {
+		msg = fmt.Sprintf("[PANIC RECOVER] %s %v %s\n", panicMsg, values, stack[:length])
+	}
+	switch config.LogLevel {
+	case log.DEBUG:
+		c.Logger().Debug(msg)
+	case log.INFO:
+		c.Logger().Info(msg)
+	case log.WARN:
+		c.Logger().Warn(msg)
+	case log.ERROR:
+		c.Logger().Error(msg)
+	case log.OFF:
+		// None.
+	default:
+		c.Logger().Print(msg)
 	}
 }
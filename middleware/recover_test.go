@@ -27,6 +27,33 @@ func TestRecover(t *testing.T) {
 	assert.Contains(t, buf.String(), "PANIC RECOVER")
 }
 
+func TestRecover_contextValues(t *testing.T) {
+	e := echo.New()
+	e.RegisterLogKey("tenant", nil)
+	buf := new(bytes.Buffer)
+	e.Logger.SetOutput(buf)
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	h := Recover()(echo.HandlerFunc(func(c echo.Context) error {
+		c.Set("tenant", "acme")
+		panic("test")
+	}))
+	h(c)
+	assert.Contains(t, buf.String(), "[PANIC RECOVER] test map[tenant:acme] goroutine")
+}
+
+func TestRecover_redacted(t *testing.T) {
+	e := echo.New()
+	buf := new(bytes.Buffer)
+	e.Logger.SetOutput(buf)
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	h := Recover()(echo.HandlerFunc(func(c echo.Context) error {
+		panic("no account for jon@example.com")
+	}))
+	h(c)
+	assert.Contains(t, buf.String(), "[PANIC RECOVER] no account for [REDACTED] goroutine")
+	assert.NotContains(t, buf.String(), "jon@example.com")
+}
+
 func TestRecoverWithConfig_LogLevel(t *testing.T) {
 	tests := []struct {
 		logLevel  log.Lvl
@@ -81,3 +108,111 @@ func TestRecoverWithConfig_LogLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestRecover_policy(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		config       RecoverConfig
+		group        echo.RecoveryPolicy
+		route        echo.RecoveryPolicy
+		panics       int
+		expectCalls  int
+		expectStatus int
+		expectCrash  bool
+	}{
+		{
+			name:         "respond by default",
+			panics:       1,
+			expectCalls:  1,
+			expectStatus: http.StatusInternalServerError,
+		},
+		{
+			name:         "retry succeeds",
+			route:        echo.RecoveryRetry,
+			panics:       1,
+			expectCalls:  2,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "retry panics again",
+			group:        echo.RecoveryRetry,
+			panics:       2,
+			expectCalls:  2,
+			expectStatus: http.StatusInternalServerError,
+		},
+		{
+			name:         "route overrides group",
+			group:        echo.RecoveryRetry,
+			route:        echo.RecoveryRespond,
+			panics:       1,
+			expectCalls:  1,
+			expectStatus: http.StatusInternalServerError,
+		},
+		{
+			name:         "config policy",
+			config:       RecoverConfig{Policy: echo.RecoveryRetry},
+			panics:       1,
+			expectCalls:  2,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "crash",
+			group:        echo.RecoveryCrash,
+			panics:       1,
+			expectCalls:  1,
+			expectStatus: http.StatusInternalServerError,
+			expectCrash:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			crashed := false
+			defer func(crash func(r interface{})) {
+				recoverCrash = crash
+			}(recoverCrash)
+			recoverCrash = func(r interface{}) {
+				crashed = true
+			}
+
+			e := echo.New()
+			e.Logger.SetOutput(new(bytes.Buffer))
+			e.Use(RecoverWithConfig(tc.config))
+			g := e.Group("/api")
+			g.RecoveryPolicy = tc.group
+			calls := 0
+			r := g.GET("/work", func(c echo.Context) error {
+				calls++
+				if calls <= tc.panics {
+					panic("failed")
+				}
+				return c.String(http.StatusOK, "ok")
+			})
+			if tc.route != echo.RecoveryDefault {
+				r.RecoveryPolicy(tc.route)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/work", nil))
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			assert.Equal(t, tc.expectCalls, calls)
+			assert.Equal(t, tc.expectCrash, crashed)
+		})
+	}
+}
+
+func TestRecover_retryNotAfterResponse(t *testing.T) {
+	e := echo.New()
+	e.Logger.SetOutput(new(bytes.Buffer))
+	e.Use(RecoverWithConfig(RecoverConfig{Policy: echo.RecoveryRetry}))
+	calls := 0
+	e.GET("/", func(c echo.Context) error {
+		calls++
+		c.Response().WriteHeader(http.StatusAccepted)
+		panic("after response")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
@@ -118,6 +118,11 @@ type RequestLoggerConfig struct {
 	// LogFormValues instructs logger to extract given list of form values from request body+URI. Note: request can
 	// contain more than one form value with same name so slice of values is been logger for each given form value name.
 	LogFormValues []string
+	// LogPhaseTimings instructs logger to extract durations of request processing phases (bind, validate, handler,
+	// render and middleware). Note: timings are recorded only when `Echo#RecordPhaseTimings` is enabled.
+	LogPhaseTimings bool
+	// LogContextValues instructs logger to extract values of context keys registered with `Echo#RegisterLogKey()`.
+	LogContextValues bool
 
 	timeNow func() time.Time
 }
@@ -168,6 +173,11 @@ type RequestLoggerValues struct {
 	// FormValues are list of form values from request body+URI. Note: request can contain more than one form value with
 	// same name so slice of values is been logger for each given form value name.
 	FormValues map[string][]string
+	// PhaseTimings are durations of request processing phases. Middleware is time spent in middlewares executed after
+	// request logger (latency of next(c) call minus time spent in route handler).
+	PhaseTimings echo.PhaseTimings
+	// ContextValues are redacted values of registered context keys set during the request (i.e. order ID, tenant).
+	ContextValues map[string]interface{}
 }
 
 // RequestLoggerWithConfig returns a RequestLogger middleware with config.
@@ -184,9 +194,9 @@ func (config RequestLoggerConfig) ToMiddleware() (echo.MiddlewareFunc, error) {
 	if config.Skipper == nil {
 		config.Skipper = DefaultSkipper
 	}
-	now = time.Now
+	timeNow := echo.Now
 	if config.timeNow != nil {
-		now = config.timeNow
+		timeNow = func(echo.Context) time.Time { return config.timeNow() }
 	}
 
 	if config.LogValuesFunc == nil {
@@ -210,18 +220,19 @@ func (config RequestLoggerConfig) ToMiddleware() (echo.MiddlewareFunc, error) {
 
 			req := c.Request()
 			res := c.Response()
-			start := now()
+			start := timeNow(c)
 
 			if config.BeforeNextFunc != nil {
 				config.BeforeNextFunc(c)
 			}
 			err := next(c)
 
+			redactor := c.Echo().Redactor
 			v := RequestLoggerValues{
 				StartTime: start,
 			}
 			if config.LogLatency {
-				v.Latency = now().Sub(start)
+				v.Latency = timeNow(c).Sub(start)
 			}
 			if config.LogProtocol {
 				v.Protocol = req.Proto
@@ -236,14 +247,14 @@ func (config RequestLoggerConfig) ToMiddleware() (echo.MiddlewareFunc, error) {
 				v.Method = req.Method
 			}
 			if config.LogURI {
-				v.URI = req.RequestURI
+				v.URI = redactor.URI(req.RequestURI)
 			}
 			if config.LogURIPath {
 				p := req.URL.Path
 				if p == "" {
 					p = "/"
 				}
-				v.URIPath = p
+				v.URIPath = redactor.Text(p)
 			}
 			if config.LogRoutePath {
 				v.RoutePath = c.Path()
@@ -256,7 +267,7 @@ func (config RequestLoggerConfig) ToMiddleware() (echo.MiddlewareFunc, error) {
 				v.RequestID = id
 			}
 			if config.LogReferer {
-				v.Referer = req.Referer()
+				v.Referer = redactor.URI(req.Referer())
 			}
 			if config.LogUserAgent {
 				v.UserAgent = req.UserAgent()
@@ -282,7 +293,9 @@ func (config RequestLoggerConfig) ToMiddleware() (echo.MiddlewareFunc, error) {
 				v.Headers = map[string][]string{}
 				for _, header := range headers {
 					if values, ok := req.Header[header]; ok {
-						v.Headers[header] = values
+						v.Headers[header] = redactValues(values, func(value string) string {
+							return redactor.Header(header, value)
+						})
 					}
 				}
 			}
@@ -291,15 +304,28 @@ func (config RequestLoggerConfig) ToMiddleware() (echo.MiddlewareFunc, error) {
 				v.QueryParams = map[string][]string{}
 				for _, param := range config.LogQueryParams {
 					if values, ok := queryParams[param]; ok {
-						v.QueryParams[param] = values
+						v.QueryParams[param] = redactValues(values, func(value string) string {
+							return redactor.Field(param, value)
+						})
 					}
 				}
 			}
+			if config.LogPhaseTimings {
+				if t := echo.PhaseTimingsOf(c); t != nil {
+					v.PhaseTimings = *t
+					v.PhaseTimings.Middleware = timeNow(c).Sub(start) - t.Handler
+				}
+			}
+			if config.LogContextValues {
+				v.ContextValues = echo.LogValues(c)
+			}
 			if logFormValues {
 				v.FormValues = map[string][]string{}
 				for _, formValue := range config.LogFormValues {
 					if values, ok := req.Form[formValue]; ok {
-						v.FormValues[formValue] = values
+						v.FormValues[formValue] = redactValues(values, func(value string) string {
+							return redactor.Field(formValue, value)
+						})
 					}
 				}
 			}
@@ -312,3 +338,12 @@ func (config RequestLoggerConfig) ToMiddleware() (echo.MiddlewareFunc, error) {
 		}
 	}, nil
 }
+
+// redactValues returns copy of values redacted with redact.
+func redactValues(values []string, redact func(value string) string) []string {
+	redacted := make([]string, len(values))
+	for i, value := range values {
+		redacted[i] = redact(value)
+	}
+	return redacted
+}
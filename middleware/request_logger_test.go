@@ -416,3 +416,94 @@ func BenchmarkRequestLogger_withMapFields(b *testing.B) {
 		mw(c)
 	}
 }
+
+func TestRequestLogger_phaseTimings(t *testing.T) {
+	e := echo.New()
+	e.RecordPhaseTimings = true
+
+	var expect RequestLoggerValues
+	e.Use(RequestLoggerWithConfig(RequestLoggerConfig{
+		LogPhaseTimings: true,
+		LogValuesFunc: func(c echo.Context, values RequestLoggerValues) error {
+			expect = values
+			return nil
+		},
+	}))
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return next(c)
+		}
+	})
+	e.GET("/test", func(c echo.Context) error {
+		time.Sleep(5 * time.Millisecond)
+		return c.String(http.StatusOK, "OK")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, expect.PhaseTimings.Handler >= 5*time.Millisecond)
+	assert.True(t, expect.PhaseTimings.Middleware >= 5*time.Millisecond)
+	assert.True(t, expect.PhaseTimings.Render > 0)
+}
+
+func TestRequestLogger_contextValues(t *testing.T) {
+	e := echo.New()
+	e.RegisterLogKey("order_id", nil)
+	e.RegisterLogKey("email", echo.RedactAll)
+
+	var expect RequestLoggerValues
+	e.Use(RequestLoggerWithConfig(RequestLoggerConfig{
+		LogContextValues: true,
+		LogValuesFunc: func(c echo.Context, values RequestLoggerValues) error {
+			expect = values
+			return nil
+		},
+	}))
+	e.POST("/orders", func(c echo.Context) error {
+		c.Set("order_id", 42)
+		c.Set("email", "user@example.com")
+		c.Set("internal", "not logged")
+		return c.NoContent(http.StatusCreated)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+	assert.Equal(t, map[string]interface{}{"order_id": 42, "email": "[REDACTED]"}, expect.ContextValues)
+}
+
+func TestRequestLogger_redacted(t *testing.T) {
+	e := echo.New()
+
+	var expect RequestLoggerValues
+	e.Use(RequestLoggerWithConfig(RequestLoggerConfig{
+		LogURI:         true,
+		LogReferer:     true,
+		LogHeaders:     []string{"Authorization", "Accept"},
+		LogQueryParams: []string{"token", "page"},
+		LogFormValues:  []string{"password", "name"},
+		LogValuesFunc: func(c echo.Context, values RequestLoggerValues) error {
+			expect = values
+			return nil
+		},
+	}))
+	e.POST("/users", func(c echo.Context) error {
+		return c.String(http.StatusCreated, c.FormValue("name"))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users?token=abc&page=1", strings.NewReader("password=hunter2&name=jon"))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer abc")
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Referer", "https://example.com/invite?email=jon@example.com")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "/users?token=%5BREDACTED%5D&page=1", expect.URI)
+	assert.Equal(t, "https://example.com/invite?email=%5BREDACTED%5D", expect.Referer)
+	assert.Equal(t, map[string][]string{"Authorization": {"[REDACTED]"}, "Accept": {"*/*"}}, expect.Headers)
+	assert.Equal(t, map[string][]string{"token": {"[REDACTED]"}, "page": {"1"}}, expect.QueryParams)
+	assert.Equal(t, map[string][]string{"password": {"[REDACTED]"}, "name": {"jon"}}, expect.FormValues)
+	assert.Equal(t, "abc", req.URL.Query().Get("token"), "request is not modified")
+}
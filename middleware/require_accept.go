@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// RequireAcceptConfig defines the config for RequireAccept middleware.
+	RequireAcceptConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Types are media types the handlers can produce.
+		// Required.
+		Types []string `yaml:"types"`
+
+		// ErrorHandler is called with ErrNotAcceptable when client accepts none of the types.
+		// Optional. Default value returns the error.
+		ErrorHandler func(c echo.Context, err error) error
+	}
+)
+
+// DefaultRequireAcceptConfig is the default RequireAccept middleware config.
+var DefaultRequireAcceptConfig = RequireAcceptConfig{
+	Skipper: DefaultSkipper,
+	ErrorHandler: func(c echo.Context, err error) error {
+		return err
+	},
+}
+
+// RequireAccept returns a middleware that responds with 406 Not Acceptable before the handler is executed when
+// `Accept` request header (with its quality values) accepts none of the types, so handlers do not generate bodies
+// the client would discard. Requests without `Accept` header accept any type.
+//
+// Example:
+//
+//	e.Use(middleware.RequireAccept(echo.MIMEApplicationJSON, echo.MIMEApplicationXML))
+func RequireAccept(types ...string) echo.MiddlewareFunc {
+	c := DefaultRequireAcceptConfig
+	c.Types = types
+	return RequireAcceptWithConfig(c)
+}
+
+// RequireAcceptWithConfig returns a RequireAccept middleware with config.
+// See: `RequireAccept()`.
+func RequireAcceptWithConfig(config RequireAcceptConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultRequireAcceptConfig.Skipper
+	}
+	if len(config.Types) == 0 {
+		panic("echo: require-accept middleware requires types")
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultRequireAcceptConfig.ErrorHandler
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || echo.NegotiateType(c, config.Types...) != "" {
+				return next(c)
+			}
+			return config.ErrorHandler(c, echo.ErrNotAcceptable)
+		}
+	}
+}
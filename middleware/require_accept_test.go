@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireAccept(t *testing.T) {
+	var testCases = []struct {
+		name       string
+		accept     string
+		expectCode int
+	}{
+		{
+			name:       "ok, no accept header",
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "ok, exact type",
+			accept:     echo.MIMEApplicationJSON,
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "ok, wildcard",
+			accept:     "text/html, */*;q=0.1",
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "ok, subtype wildcard",
+			accept:     "application/*",
+			expectCode: http.StatusOK,
+		},
+		{
+			name:       "nok, other type",
+			accept:     "text/html",
+			expectCode: http.StatusNotAcceptable,
+		},
+		{
+			name:       "nok, excluded with zero quality",
+			accept:     "application/json;q=0, application/xml;q=0, */*",
+			expectCode: http.StatusNotAcceptable,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			called := false
+			h := RequireAccept(echo.MIMEApplicationJSON, echo.MIMEApplicationXML)(func(c echo.Context) error {
+				called = true
+				return c.String(http.StatusOK, "ok")
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := h(c)
+			if tc.expectCode == http.StatusOK {
+				assert.NoError(t, err)
+				assert.True(t, called)
+			} else {
+				assert.Equal(t, echo.ErrNotAcceptable, err)
+				assert.False(t, called)
+			}
+			assert.Equal(t, echo.HeaderAccept, rec.Header().Get(echo.HeaderVary))
+		})
+	}
+}
+
+func TestRequireAcceptWithConfig_panicsWithoutTypes(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: require-accept middleware requires types", func() {
+		RequireAcceptWithConfig(RequireAcceptConfig{})
+	})
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/bytes"
+)
+
+type (
+	// ResponseLimitConfig defines the config for ResponseLimit middleware.
+	ResponseLimitConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Maximum allowed size for a response body, it can be specified
+		// as `4x` or `4xB`, where x is one of the multiple from K, M, G, T or P.
+		// Required.
+		Limit string `yaml:"limit"`
+
+		// Policy defines what is sent to the client when response exceeds the limit.
+		// Optional. Default value ResponseLimitError.
+		Policy ResponseLimitPolicy
+	}
+
+	// ResponseLimitPolicy defines handling of responses exceeding the limit of ResponseLimit middleware.
+	ResponseLimitPolicy uint8
+
+	responseLimitWriter struct {
+		http.ResponseWriter
+		limit       int64
+		written     int64
+		code        int
+		wroteHeader bool
+		exceeded    bool
+		truncate    bool
+	}
+)
+
+const (
+	// ResponseLimitError discards response and sends 500 Internal Server Error instead when nothing was sent to the
+	// client yet. Response already flushed to the client is cut at the limit.
+	ResponseLimitError ResponseLimitPolicy = iota
+	// ResponseLimitTruncate sends response cut at the limit.
+	ResponseLimitTruncate
+)
+
+var (
+	// ErrResponseTooLarge denotes response exceeding the limit of ResponseLimit middleware.
+	ErrResponseTooLarge = echo.NewHTTPError(http.StatusInternalServerError, "response too large")
+
+	// DefaultResponseLimitConfig is the default ResponseLimit middleware config.
+	DefaultResponseLimitConfig = ResponseLimitConfig{
+		Skipper: DefaultSkipper,
+		Policy:  ResponseLimitError,
+	}
+)
+
+// ResponseLimit returns a ResponseLimit middleware.
+//
+// ResponseLimit middleware limits size of response bodies, protecting clients and network from handlers accidentally
+// serializing massive objects. Status and headers are held back until the first body write, so response exceeding
+// the limit with its first write (i.e. whole JSON document) is replaced with "500 - Internal Server Error".
+// Exceeded limits are logged with `Context#Logger()`.
+// Limit can be specified as `4x` or `4xB`, where x is one of the multiple from K, M, G, T or P.
+func ResponseLimit(limit string) echo.MiddlewareFunc {
+	c := DefaultResponseLimitConfig
+	c.Limit = limit
+	return ResponseLimitWithConfig(c)
+}
+
+// ResponseLimitWithConfig returns a ResponseLimit middleware with config.
+// See: `ResponseLimit()`.
+func ResponseLimitWithConfig(config ResponseLimitConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultResponseLimitConfig.Skipper
+	}
+
+	limit, err := bytes.Parse(config.Limit)
+	if err != nil {
+		panic(fmt.Errorf("echo: invalid response-limit=%s", config.Limit))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			res := c.Response()
+			w := &responseLimitWriter{
+				ResponseWriter: res.Writer,
+				limit:          limit,
+				truncate:       config.Policy == ResponseLimitTruncate,
+			}
+			res.Writer = w
+			err := next(c)
+			res.Writer = w.ResponseWriter
+
+			if !w.exceeded {
+				w.writeHeader()
+				return err
+			}
+			req := c.Request()
+			c.Logger().Errorf("response to %s %s exceeded limit of %d bytes", req.Method, req.URL.Path, limit)
+			if !w.wroteHeader {
+				// nothing was sent, error handler can respond instead
+				res.Committed = false
+				res.Size = 0
+				h := res.Header()
+				h.Del(echo.HeaderContentLength)
+				h.Del(echo.HeaderContentType)
+				h.Del(echo.HeaderContentEncoding)
+				return ErrResponseTooLarge
+			}
+			if w.truncate {
+				return nil
+			}
+			return ErrResponseTooLarge
+		}
+	}
+}
+
+// WriteHeader holds status code back until the body is written.
+func (w *responseLimitWriter) WriteHeader(code int) {
+	w.code = code
+}
+
+func (w *responseLimitWriter) Write(b []byte) (int, error) {
+	if w.exceeded {
+		return 0, ErrResponseTooLarge
+	}
+	over := w.written+int64(len(b)) > w.limit
+	if !over && !w.wroteHeader {
+		// declared length exceeding the limit can not be sent
+		if l, err := strconv.ParseInt(w.Header().Get(echo.HeaderContentLength), 10, 64); err == nil && l > w.limit {
+			over = true
+		}
+	}
+	if !over {
+		w.writeHeader()
+		n, err := w.ResponseWriter.Write(b)
+		w.written += int64(n)
+		return n, err
+	}
+
+	w.exceeded = true
+	if !w.wroteHeader && !w.truncate {
+		return 0, ErrResponseTooLarge
+	}
+	w.Header().Del(echo.HeaderContentLength)
+	w.writeHeader()
+	if len(b) > int(w.limit-w.written) {
+		b = b[:w.limit-w.written]
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	if err == nil {
+		err = ErrResponseTooLarge
+	}
+	return n, err
+}
+
+func (w *responseLimitWriter) writeHeader() {
+	if w.wroteHeader || w.code == 0 {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+func (w *responseLimitWriter) Flush() {
+	w.writeHeader()
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *responseLimitWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseLimit(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		givenPolicy  ResponseLimitPolicy
+		whenHandler  echo.HandlerFunc
+		expectCode   int
+		expectBody   string
+		expectErr    error
+		expectLogged bool
+		expectNoCLen bool
+	}{
+		{
+			name: "ok, within limit",
+			whenHandler: func(c echo.Context) error {
+				return c.String(http.StatusCreated, "0123456789")
+			},
+			expectCode: http.StatusCreated,
+			expectBody: "0123456789",
+		},
+		{
+			name: "ok, no content",
+			whenHandler: func(c echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			},
+			expectCode: http.StatusNoContent,
+		},
+		{
+			name: "nok, error",
+			whenHandler: func(c echo.Context) error {
+				return c.JSON(http.StatusOK, strings.Repeat("x", 100))
+			},
+			expectCode:   http.StatusInternalServerError,
+			expectBody:   `{"message":"response too large"}` + "\n",
+			expectErr:    ErrResponseTooLarge,
+			expectLogged: true,
+		},
+		{
+			name: "nok, error by content length",
+			whenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderContentLength, "100")
+				c.Response().WriteHeader(http.StatusOK)
+				_, err := c.Response().Write([]byte("0123456789"))
+				return err
+			},
+			expectCode:   http.StatusInternalServerError,
+			expectBody:   `{"message":"response too large"}` + "\n",
+			expectErr:    ErrResponseTooLarge,
+			expectLogged: true,
+		},
+		{
+			name: "nok, error after flush cuts response",
+			whenHandler: func(c echo.Context) error {
+				c.Response().Write([]byte("0123456789"))
+				c.Response().Flush()
+				_, err := c.Response().Write([]byte("0123456789"))
+				return err
+			},
+			expectCode:   http.StatusOK,
+			expectBody:   "0123456789012345",
+			expectErr:    ErrResponseTooLarge,
+			expectLogged: true,
+		},
+		{
+			name:        "nok, truncate",
+			givenPolicy: ResponseLimitTruncate,
+			whenHandler: func(c echo.Context) error {
+				c.Response().Header().Set(echo.HeaderContentLength, "100")
+				return c.Blob(http.StatusOK, echo.MIMETextPlain, bytes.Repeat([]byte("x"), 100))
+			},
+			expectCode:   http.StatusOK,
+			expectBody:   strings.Repeat("x", 16),
+			expectLogged: true,
+			expectNoCLen: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			logged := new(bytes.Buffer)
+			e.Logger.SetOutput(logged)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			mw := ResponseLimitWithConfig(ResponseLimitConfig{Limit: "16B", Policy: tc.givenPolicy})
+			err := mw(tc.whenHandler)(c)
+			if err != nil {
+				e.HTTPErrorHandler(err, c)
+			}
+
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+			assert.Equal(t, tc.expectLogged, strings.Contains(logged.String(), "response to GET / exceeded limit of 16 bytes"))
+			if tc.expectNoCLen {
+				assert.Empty(t, rec.Header().Get(echo.HeaderContentLength))
+			}
+			assert.Equal(t, int64(rec.Body.Len()), c.Response().Size)
+			if tc.expectCode == http.StatusInternalServerError {
+				assert.Equal(t, echo.MIMEApplicationJSONCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestResponseLimit_panics(t *testing.T) {
+	assert.PanicsWithError(t, "echo: invalid response-limit=", func() {
+		ResponseLimit("")
+	})
+}
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// SanitizeHeadersConfig defines the config for SanitizeHeaders middleware.
+	SanitizeHeadersConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Deny lists response headers which are removed. Name ending with "*" removes all headers with the prefix
+		// (i.e. "X-Debug-*").
+		// Optional. Default value DefaultSanitizeHeadersConfig.Deny.
+		Deny []string `yaml:"deny"`
+
+		// StripInvalid removes control characters (CR, LF, NUL...) from header values instead of removing the
+		// whole header.
+		// Optional. Default value false.
+		StripInvalid bool `yaml:"strip_invalid"`
+
+		// OnInvalid is called for every header with invalid name or value (i.e. CRLF injection attempt) before it is
+		// removed or fixed.
+		// Optional.
+		OnInvalid func(c echo.Context, name, value string)
+	}
+)
+
+// DefaultSanitizeHeadersConfig is the default SanitizeHeaders middleware config.
+var DefaultSanitizeHeadersConfig = SanitizeHeadersConfig{
+	Skipper: DefaultSkipper,
+	Deny: []string{
+		echo.HeaderServer,
+		"X-Powered-By",
+		"X-AspNet-Version",
+		"X-AspNetMvc-Version",
+		"X-Runtime",
+		"X-Debug-*",
+	},
+}
+
+// SanitizeHeaders returns a middleware that enforces policy on response headers just before they are written:
+// headers from deny list (revealing server software or internal debugging information) are removed, header names
+// are canonicalized and headers with invalid names or values containing control characters (i.e. CRLF injection
+// of user input into header) are removed. Register it with `Echo#Pre` so it applies to all responses including
+// errors.
+//
+// Example:
+//
+//	e.Pre(middleware.SanitizeHeaders())
+func SanitizeHeaders() echo.MiddlewareFunc {
+	return SanitizeHeadersWithConfig(DefaultSanitizeHeadersConfig)
+}
+
+// SanitizeHeadersWithConfig returns a SanitizeHeaders middleware with config.
+// See: `SanitizeHeaders()`.
+func SanitizeHeadersWithConfig(config SanitizeHeadersConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultSanitizeHeadersConfig.Skipper
+	}
+	if config.Deny == nil {
+		config.Deny = DefaultSanitizeHeadersConfig.Deny
+	}
+	deny := map[string]bool{}
+	denyPrefixes := []string{}
+	for _, name := range config.Deny {
+		if strings.HasSuffix(name, "*") {
+			denyPrefixes = append(denyPrefixes, http.CanonicalHeaderKey(strings.TrimSuffix(name, "*")))
+		} else {
+			deny[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			c.Response().Before(func() {
+				header := c.Response().Header()
+				for name, values := range header {
+					canonical := http.CanonicalHeaderKey(name)
+					if deny[canonical] || hasAnyPrefix(canonical, denyPrefixes) {
+						delete(header, name)
+						continue
+					}
+					if !validHeaderName(name) {
+						if config.OnInvalid != nil {
+							config.OnInvalid(c, name, strings.Join(values, ", "))
+						}
+						delete(header, name)
+						continue
+					}
+
+					sanitized := values[:0]
+					for _, v := range values {
+						if validHeaderValue(v) {
+							sanitized = append(sanitized, v)
+							continue
+						}
+						if config.OnInvalid != nil {
+							config.OnInvalid(c, canonical, v)
+						}
+						if config.StripInvalid {
+							sanitized = append(sanitized, stripControlChars(v))
+						}
+					}
+					if canonical != name {
+						delete(header, name)
+						sanitized = append(header[canonical], sanitized...)
+					}
+					if len(sanitized) == 0 {
+						delete(header, canonical)
+					} else {
+						header[canonical] = sanitized
+					}
+				}
+			})
+			return next(c)
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// validHeaderName checks that name is a token (RFC 7230 section 3.2.6).
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		if b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", rune(b)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validHeaderValue checks that value contains no control characters other than horizontal tab.
+func validHeaderValue(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if b := value[i]; b < ' ' && b != '\t' || b == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func stripControlChars(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' && r != '\t' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeHeaders(t *testing.T) {
+	e := echo.New()
+	var invalid []string
+	e.Pre(SanitizeHeadersWithConfig(SanitizeHeadersConfig{
+		OnInvalid: func(c echo.Context, name, value string) {
+			invalid = append(invalid, name)
+		},
+	}))
+	e.GET("/", func(c echo.Context) error {
+		h := c.Response().Header()
+		h.Set(echo.HeaderServer, "nginx/1.2.3")
+		h.Set("X-Powered-By", "PHP/5.6")
+		h.Set("X-Debug-Query", "SELECT 1")
+		h.Set("X-Redirect", "/next\r\nSet-Cookie: session=evil")
+		h["x-lower"] = []string{"a"}
+		h["X-Lower"] = []string{"b"}
+		h["Bad Name"] = []string{"x"}
+		return c.String(http.StatusOK, "OK")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	h := rec.Header()
+	assert.Empty(t, h.Get(echo.HeaderServer))
+	assert.Empty(t, h.Get("X-Powered-By"))
+	assert.Empty(t, h.Get("X-Debug-Query"))
+	assert.NotContains(t, h, "X-Redirect")
+	assert.NotContains(t, h, "Set-Cookie")
+	assert.NotContains(t, h, "Bad Name")
+	assert.NotContains(t, h, "x-lower")
+	assert.ElementsMatch(t, []string{"a", "b"}, h["X-Lower"])
+	assert.ElementsMatch(t, []string{"X-Redirect", "Bad Name"}, invalid)
+}
+
+func TestSanitizeHeadersStripInvalid(t *testing.T) {
+	e := echo.New()
+	e.Pre(SanitizeHeadersWithConfig(SanitizeHeadersConfig{
+		Deny:         []string{"X-Internal-*"},
+		StripInvalid: true,
+	}))
+	e.GET("/", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderServer, "echo")
+		c.Response().Header().Set("X-Internal-Node", "node-1")
+		c.Response().Header().Set("X-Redirect", "/next\r\nSet-Cookie: a=b\t")
+		return errors.New("failure")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "echo", rec.Header().Get(echo.HeaderServer))
+	assert.Empty(t, rec.Header().Get("X-Internal-Node"))
+	assert.Equal(t, "/nextSet-Cookie: a=b\t", rec.Header().Get("X-Redirect"))
+}
+
+func TestSanitizeHeadersSkipper(t *testing.T) {
+	e := echo.New()
+	e.Pre(SanitizeHeadersWithConfig(SanitizeHeadersConfig{
+		Skipper: func(c echo.Context) bool { return true },
+	}))
+	e.GET("/", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderServer, "echo")
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, "echo", rec.Header().Get(echo.HeaderServer))
+}
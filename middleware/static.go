@@ -46,6 +46,11 @@ type (
 		// Filesystem provides access to the static content.
 		// Optional. Defaults to http.Dir(config.Root)
 		Filesystem http.FileSystem `yaml:"-"`
+
+		// Assets is manifest of fingerprinted asset names. Assets requested by fingerprinted name are served with
+		// immutable caching and precompressed variants. See `NewAssetManifest()`.
+		// Optional.
+		Assets *AssetManifest `yaml:"-"`
 	}
 )
 
@@ -175,6 +180,11 @@ func StaticWithConfig(config StaticConfig) echo.MiddlewareFunc {
 			if err != nil {
 				return
 			}
+			if config.Assets != nil {
+				if a := config.Assets.lookup(p); a != nil {
+					return config.Assets.serve(c, config.Filesystem, config.Root, a)
+				}
+			}
 			name := filepath.Join(config.Root, filepath.Clean("/"+p)) // "/"+ for security
 
 			if config.IgnoreBase {
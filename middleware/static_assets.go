@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// AssetManifest maps names of static assets to fingerprinted names containing hash of their content. Assets
+	// served under fingerprinted names never change and are cached by clients forever, new content gets new name.
+	// Manifest is either built at startup with `NewAssetManifest()` or at build time and loaded with
+	// `LoadAssetManifest()`.
+	AssetManifest struct {
+		// Prefix is URL path the assets are served under, i.e. "/static" when Static middleware is added to group
+		// "/static". Empty for Static middleware added to Echo.
+		Prefix string
+
+		assets map[string]*staticAsset
+		hashed map[string]*staticAsset
+	}
+
+	staticAsset struct {
+		Name string `json:"-"`
+		Path string `json:"path"`
+		// Encodings are content codings of precompressed variants of the asset stored next to it (`app.css.br`,
+		// `app.css.gz`), in order of preference.
+		Encodings []string `json:"encodings,omitempty"`
+	}
+)
+
+// AssetMaxAge is max-age of `Cache-Control` header of assets served under fingerprinted names.
+const AssetMaxAge = 365 * 24 * time.Hour
+
+// assetEncodings are content codings of precompressed asset variants with their file extensions, in order of
+// preference.
+var assetEncodings = []struct {
+	coding string
+	ext    string
+}{
+	{coding: "br", ext: ".br"},
+	{coding: "gzip", ext: ".gz"},
+}
+
+// assetHashLength is number of hex digits of content hash in fingerprinted names.
+const assetHashLength = 8
+
+// NewAssetManifest hashes all files of the filesystem and returns manifest of their fingerprinted names
+// (`css/app.css` is served as `css/app.1f2e3d4c.css`). Files `<name>.br` and `<name>.gz` next to an asset are
+// precompressed variants of it and are served to clients accepting the encoding. Filesystem is the one Static
+// middleware serves from.
+//
+// Example:
+//
+//	assets, err := middleware.NewAssetManifest(http.Dir("public"), "/static")
+//	if err != nil {
+//		e.Logger.Fatal(err)
+//	}
+//	g := e.Group("/static")
+//	g.Use(middleware.StaticWithConfig(middleware.StaticConfig{Root: "public", Assets: assets}))
+//	t := template.Must(template.New("").Funcs(assets.FuncMap()).ParseGlob("views/*.html"))
+//
+// and in templates:
+//
+//	<link rel="stylesheet" href="{{asset "css/app.css"}}">
+func NewAssetManifest(fs http.FileSystem, prefix string) (*AssetManifest, error) {
+	files := map[string]bool{}
+	if err := walkFiles(fs, "/", files); err != nil {
+		return nil, err
+	}
+
+	m := &AssetManifest{Prefix: prefix, assets: map[string]*staticAsset{}}
+	for name := range files {
+		if isAssetVariant(name, files) {
+			continue
+		}
+		hash, err := hashFile(fs, name)
+		if err != nil {
+			return nil, err
+		}
+		a := &staticAsset{Name: name, Path: fingerprint(name, hash)}
+		for _, enc := range assetEncodings {
+			if files[name+enc.ext] {
+				a.Encodings = append(a.Encodings, enc.coding)
+			}
+		}
+		m.assets[name] = a
+	}
+	m.index()
+	return m, nil
+}
+
+// LoadAssetManifest loads manifest written by `AssetManifest#WriteTo()`, i.e. at build time.
+func LoadAssetManifest(r io.Reader, prefix string) (*AssetManifest, error) {
+	m := &AssetManifest{Prefix: prefix, assets: map[string]*staticAsset{}}
+	if err := json.NewDecoder(r).Decode(&m.assets); err != nil {
+		return nil, err
+	}
+	for name, a := range m.assets {
+		a.Name = name
+	}
+	m.index()
+	return m, nil
+}
+
+// WriteTo writes manifest as JSON object keyed by asset names.
+func (m *AssetManifest) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.MarshalIndent(m.assets, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// Path returns URL path of the asset under its fingerprinted name. Names not in manifest are returned unchanged
+// (prefixed) so missing asset is visible as 404 instead of broken template.
+func (m *AssetManifest) Path(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if a, ok := m.assets[name]; ok {
+		name = a.Path
+	}
+	return strings.TrimSuffix(m.Prefix, "/") + "/" + name
+}
+
+// FuncMap returns template functions rewriting asset references. Function `asset` returns URL path of the asset
+// (see `AssetManifest#Path()`).
+func (m *AssetManifest) FuncMap() template.FuncMap {
+	return template.FuncMap{"asset": m.Path}
+}
+
+func (m *AssetManifest) index() {
+	m.hashed = make(map[string]*staticAsset, len(m.assets))
+	for _, a := range m.assets {
+		m.hashed[a.Path] = a
+	}
+}
+
+// lookup returns asset requested by fingerprinted path (relative to the Static middleware root).
+func (m *AssetManifest) lookup(p string) *staticAsset {
+	return m.hashed[strings.TrimPrefix(path.Clean("/"+p), "/")]
+}
+
+// serve sends the asset, or its precompressed variant best matching `Accept-Encoding`, with immutable caching.
+func (m *AssetManifest) serve(c echo.Context, fs http.FileSystem, root string, a *staticAsset) error {
+	name := filepath.Join(root, filepath.FromSlash(a.Name))
+	header := c.Response().Header()
+	if len(a.Encodings) > 0 {
+		offers := append([]string{"identity"}, a.Encodings...)
+		if coding := echo.NegotiateEncoding(c, offers...); coding != "" && coding != "identity" {
+			for _, enc := range assetEncodings {
+				if enc.coding == coding {
+					name += enc.ext
+					header.Set(echo.HeaderContentEncoding, coding)
+				}
+			}
+		}
+	}
+
+	file, err := openFile(fs, name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if ct := mime.TypeByExtension(path.Ext(a.Name)); ct != "" {
+		header.Set(echo.HeaderContentType, ct)
+	}
+	echo.NewCacheControl().Public().MaxAge(AssetMaxAge).Immutable().Apply(header)
+	http.ServeContent(c.Response(), c.Request(), path.Base(a.Name), info.ModTime(), file)
+	return nil
+}
+
+// walkFiles collects slash separated names (without leading slash) of all regular files under dir.
+func walkFiles(fs http.FileSystem, dir string, files map[string]bool) error {
+	d, err := fs.Open(dir)
+	if err != nil {
+		return err
+	}
+	infos, err := d.Readdir(-1)
+	d.Close()
+	if err != nil {
+		return err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	for _, info := range infos {
+		name := path.Join(dir, info.Name())
+		if info.IsDir() {
+			if err := walkFiles(fs, name, files); err != nil {
+				return err
+			}
+			continue
+		}
+		if info.Mode().IsRegular() {
+			files[strings.TrimPrefix(name, "/")] = true
+		}
+	}
+	return nil
+}
+
+// isAssetVariant reports whether file is precompressed variant of another file.
+func isAssetVariant(name string, files map[string]bool) bool {
+	for _, enc := range assetEncodings {
+		if strings.HasSuffix(name, enc.ext) && files[strings.TrimSuffix(name, enc.ext)] {
+			return true
+		}
+	}
+	return false
+}
+
+func hashFile(fs http.FileSystem, name string) (string, error) {
+	f, err := fs.Open("/" + name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:assetHashLength], nil
+}
+
+// fingerprint inserts hash before extension of the file name: `css/app.css` -> `css/app.1f2e3d4c.css`.
+func fingerprint(name, hash string) string {
+	ext := path.Ext(name)
+	if ext == path.Base(name) { // dot file, i.e. `.htaccess`
+		ext = ""
+	}
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bytes"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAssets(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "assets")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestAssetManifest(t *testing.T) {
+	dir := writeAssets(t, map[string]string{
+		"css/app.css":    "body{}",
+		"css/app.css.br": "br-body",
+		"css/app.css.gz": "gz-body",
+		"js/app.js":      "alert(1)",
+		"robots.txt.gz":  "not a variant",
+		".htaccess":      "deny",
+	})
+
+	m, err := NewAssetManifest(http.Dir(dir), "/static/")
+	require.NoError(t, err)
+
+	css := m.Path("css/app.css")
+	assert.Regexp(t, `^/static/css/app\.[0-9a-f]{8}\.css$`, css)
+	assert.Equal(t, css, m.Path("/css/app.css"))
+	assert.Regexp(t, `^/static/js/app\.[0-9a-f]{8}\.js$`, m.Path("js/app.js"))
+	assert.Regexp(t, `^/static/robots\.txt\.[0-9a-f]{8}\.gz$`, m.Path("robots.txt.gz"))
+	assert.Regexp(t, `^/static/\.htaccess\.[0-9a-f]{8}$`, m.Path(".htaccess"))
+	assert.Equal(t, "/static/missing.css", m.Path("missing.css"))
+	assert.Equal(t, "/static/css/app.css.br", m.Path("css/app.css.br"))
+
+	buf := new(bytes.Buffer)
+	tmpl := template.Must(template.New("page").Funcs(m.FuncMap()).Parse(`<link href="{{asset "css/app.css"}}">`))
+	require.NoError(t, tmpl.Execute(buf, nil))
+	assert.Equal(t, `<link href="`+css+`">`, buf.String())
+
+	// manifest written at build time is loaded at startup
+	buf.Reset()
+	_, err = m.WriteTo(buf)
+	require.NoError(t, err)
+	loaded, err := LoadAssetManifest(buf, "/static")
+	require.NoError(t, err)
+	assert.Equal(t, css, loaded.Path("css/app.css"))
+	assert.Equal(t, []string{"br", "gzip"}, loaded.lookup(css[len("/static"):]).Encodings)
+
+	// content change changes the name
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "css", "app.css"), []byte("body{color:red}"), 0644))
+	m2, err := NewAssetManifest(http.Dir(dir), "/static")
+	require.NoError(t, err)
+	assert.NotEqual(t, css, m2.Path("css/app.css"))
+}
+
+func TestStatic_Assets(t *testing.T) {
+	dir := writeAssets(t, map[string]string{
+		"css/app.css":    "body{}",
+		"css/app.css.br": "br-body",
+		"css/app.css.gz": "gz-body",
+		"js/app.js":      "alert(1)",
+	})
+	m, err := NewAssetManifest(http.Dir(dir), "/static")
+	require.NoError(t, err)
+
+	e := echo.New()
+	g := e.Group("/static")
+	g.Use(StaticWithConfig(StaticConfig{Root: dir, Assets: m}))
+
+	var testCases = []struct {
+		name                 string
+		whenURL              string
+		whenAcceptEncoding   string
+		expectBody           string
+		expectCacheControl   string
+		expectEncoding       string
+		expectVary           string
+		expectContentTypeHas string
+	}{
+		{
+			name:                 "ok, fingerprinted name is cached forever",
+			whenURL:              m.Path("js/app.js"),
+			expectBody:           "alert(1)",
+			expectCacheControl:   "public, max-age=31536000, immutable",
+			expectContentTypeHas: "javascript",
+		},
+		{
+			name:                 "ok, identity without Accept-Encoding",
+			whenURL:              m.Path("css/app.css"),
+			expectBody:           "body{}",
+			expectCacheControl:   "public, max-age=31536000, immutable",
+			expectVary:           echo.HeaderAcceptEncoding,
+			expectContentTypeHas: "text/css",
+		},
+		{
+			name:                 "ok, brotli preferred",
+			whenURL:              m.Path("css/app.css"),
+			whenAcceptEncoding:   "gzip, deflate, br",
+			expectBody:           "br-body",
+			expectCacheControl:   "public, max-age=31536000, immutable",
+			expectEncoding:       "br",
+			expectVary:           echo.HeaderAcceptEncoding,
+			expectContentTypeHas: "text/css",
+		},
+		{
+			name:                 "ok, gzip",
+			whenURL:              m.Path("css/app.css"),
+			whenAcceptEncoding:   "gzip",
+			expectBody:           "gz-body",
+			expectCacheControl:   "public, max-age=31536000, immutable",
+			expectEncoding:       "gzip",
+			expectVary:           echo.HeaderAcceptEncoding,
+			expectContentTypeHas: "text/css",
+		},
+		{
+			name:                 "ok, original name is served as plain static file",
+			whenURL:              "/static/css/app.css",
+			whenAcceptEncoding:   "br",
+			expectBody:           "body{}",
+			expectContentTypeHas: "text/css",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			if tc.whenAcceptEncoding != "" {
+				req.Header.Set(echo.HeaderAcceptEncoding, tc.whenAcceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+			assert.Equal(t, tc.expectCacheControl, rec.Header().Get(echo.HeaderCacheControl))
+			assert.Equal(t, tc.expectEncoding, rec.Header().Get(echo.HeaderContentEncoding))
+			assert.Equal(t, tc.expectVary, rec.Header().Get(echo.HeaderVary))
+			assert.Contains(t, rec.Header().Get(echo.HeaderContentType), tc.expectContentTypeHas)
+		})
+	}
+}
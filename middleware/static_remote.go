@@ -0,0 +1,338 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// StaticRemoteConfig defines the config for StaticRemote middleware.
+	StaticRemoteConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// Store is the object store assets are served from.
+		// Required.
+		Store ObjectStore
+
+		// Prefix is prepended to the request path to form the object key, e.g. "assets/".
+		// Optional. Default value "".
+		Prefix string `yaml:"prefix"`
+
+		// Index object for serving a "directory" (request path ending with `/`).
+		// Optional. Default value "index.html".
+		Index string `yaml:"index"`
+
+		// CacheSize is the maximum total size in bytes of objects kept in the local LRU cache.
+		// Optional. Default value 0 (caching disabled).
+		CacheSize int64 `yaml:"cache_size"`
+
+		// CacheMaxObjectSize is the maximum size in bytes of a single object to be cached. Larger objects
+		// are always streamed from the store.
+		// Optional. Default value 1MB.
+		CacheMaxObjectSize int64 `yaml:"cache_max_object_size"`
+
+		// CacheTTL is how long a cached object is considered fresh.
+		// Optional. Default value 5 minutes.
+		CacheTTL time.Duration `yaml:"cache_ttl"`
+
+		// RedirectToSignedURL redirects clients to a short-lived signed URL of the object instead of proxying
+		// its content. Store must implement `ObjectURLSigner`.
+		// Optional. Default value false.
+		RedirectToSignedURL bool `yaml:"redirect_to_signed_url"`
+
+		// SignedURLExpiry is the validity duration of signed URLs.
+		// Optional. Default value 15 minutes.
+		SignedURLExpiry time.Duration `yaml:"signed_url_expiry"`
+	}
+
+	// ObjectStore is the interface to be implemented by object stores (S3, GCS, MinIO etc.) StaticRemote
+	// serves content from.
+	ObjectStore interface {
+		// GetObject returns object with given key. When object does not exist ErrObjectNotFound must be returned.
+		GetObject(ctx context.Context, key string) (*RemoteObject, error)
+	}
+
+	// ObjectURLSigner is implemented by object stores that are able to create signed (presigned) URLs.
+	ObjectURLSigner interface {
+		// SignURL returns an URL granting temporary read access to object with given key.
+		SignURL(key string, expires time.Duration) (string, error)
+	}
+
+	// RemoteObject is an object returned by ObjectStore. Body must be closed by the caller.
+	RemoteObject struct {
+		Body        io.ReadCloser
+		Size        int64
+		ModTime     time.Time
+		ETag        string
+		ContentType string
+	}
+
+	cachedObject struct {
+		key         string
+		content     []byte
+		modTime     time.Time
+		etag        string
+		contentType string
+		expires     time.Time
+	}
+
+	objectLRUCache struct {
+		mutex    sync.Mutex
+		maxSize  int64
+		size     int64
+		ll       *list.List
+		elements map[string]*list.Element
+	}
+)
+
+var (
+	// ErrObjectNotFound denotes an error returned by ObjectStore when requested object does not exist.
+	ErrObjectNotFound = errors.New("object not found")
+
+	// DefaultStaticRemoteConfig is the default StaticRemote middleware config.
+	DefaultStaticRemoteConfig = StaticRemoteConfig{
+		Skipper:            DefaultSkipper,
+		Index:              "index.html",
+		CacheMaxObjectSize: 1 << 20,
+		CacheTTL:           5 * time.Minute,
+		SignedURLExpiry:    15 * time.Minute,
+	}
+)
+
+// StaticRemote returns a StaticRemote middleware to serve static content from provided object store.
+func StaticRemote(store ObjectStore) echo.MiddlewareFunc {
+	c := DefaultStaticRemoteConfig
+	c.Store = store
+	return StaticRemoteWithConfig(c)
+}
+
+// StaticRemoteWithConfig returns a StaticRemote middleware with config.
+// See `StaticRemote()`.
+func StaticRemoteWithConfig(config StaticRemoteConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultStaticRemoteConfig.Skipper
+	}
+	if config.Store == nil {
+		panic("echo: static remote middleware requires store")
+	}
+	if config.Index == "" {
+		config.Index = DefaultStaticRemoteConfig.Index
+	}
+	if config.CacheMaxObjectSize == 0 {
+		config.CacheMaxObjectSize = DefaultStaticRemoteConfig.CacheMaxObjectSize
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultStaticRemoteConfig.CacheTTL
+	}
+	if config.SignedURLExpiry == 0 {
+		config.SignedURLExpiry = DefaultStaticRemoteConfig.SignedURLExpiry
+	}
+	var signer ObjectURLSigner
+	if config.RedirectToSignedURL {
+		s, ok := config.Store.(ObjectURLSigner)
+		if !ok {
+			panic("echo: static remote middleware store does not support signed URLs")
+		}
+		signer = s
+	}
+	var cache *objectLRUCache
+	if config.CacheSize > 0 {
+		cache = newObjectLRUCache(config.CacheSize)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			if config.Skipper(c) {
+				return next(c)
+			}
+			req := c.Request()
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return next(c)
+			}
+
+			p := req.URL.Path
+			if strings.HasSuffix(c.Path(), "*") { // When serving from a group, e.g. `/static*`.
+				p = c.Param("*")
+			}
+			p, err = url.PathUnescape(p)
+			if err != nil {
+				return
+			}
+			key := objectKey(config.Prefix, p, config.Index)
+
+			if signer != nil {
+				u, err := signer.SignURL(key, config.SignedURLExpiry)
+				if err != nil {
+					return err
+				}
+				return c.Redirect(http.StatusTemporaryRedirect, u)
+			}
+
+			if cache != nil {
+				if o := cache.get(key, echo.Now(c)); o != nil {
+					return serveCachedObject(c, o)
+				}
+			}
+
+			obj, err := config.Store.GetObject(req.Context(), key)
+			if err != nil {
+				if err == ErrObjectNotFound {
+					return next(c)
+				}
+				return echo.NewHTTPError(http.StatusBadGateway).SetInternal(err)
+			}
+			defer obj.Body.Close()
+			if obj.ContentType == "" {
+				obj.ContentType = mime.TypeByExtension(path.Ext(key))
+			}
+
+			if cache != nil && obj.Size >= 0 && obj.Size <= config.CacheMaxObjectSize {
+				content, err := ioutil.ReadAll(io.LimitReader(obj.Body, config.CacheMaxObjectSize+1))
+				if err != nil {
+					return echo.NewHTTPError(http.StatusBadGateway).SetInternal(err)
+				}
+				o := &cachedObject{
+					key:         key,
+					content:     content,
+					modTime:     obj.ModTime,
+					etag:        obj.ETag,
+					contentType: obj.ContentType,
+					expires:     echo.Now(c).Add(config.CacheTTL),
+				}
+				if int64(len(content)) <= config.CacheMaxObjectSize {
+					cache.add(o)
+				}
+				return serveCachedObject(c, o)
+			}
+			return serveRemoteObject(c, obj)
+		}
+	}
+}
+
+func objectKey(prefix, p, index string) string {
+	key := path.Clean("/" + p) // "/"+ for security
+	if strings.HasSuffix(p, "/") {
+		key = path.Join(key, index)
+	}
+	return prefix + strings.TrimPrefix(key, "/")
+}
+
+func serveCachedObject(c echo.Context, o *cachedObject) error {
+	res := c.Response()
+	if o.contentType != "" {
+		res.Header().Set(echo.HeaderContentType, o.contentType)
+	}
+	if o.etag != "" {
+		res.Header().Set(echo.HeaderETag, o.etag)
+	}
+	http.ServeContent(res, c.Request(), "", o.modTime, bytes.NewReader(o.content))
+	return nil
+}
+
+// serveRemoteObject streams object content to the client. Unlike `http.ServeContent` it does not need a seekable
+// reader and therefore does not support range requests.
+func serveRemoteObject(c echo.Context, obj *RemoteObject) error {
+	req := c.Request()
+	res := c.Response()
+	if obj.ETag != "" {
+		res.Header().Set(echo.HeaderETag, obj.ETag)
+	}
+	if !obj.ModTime.IsZero() {
+		res.Header().Set(echo.HeaderLastModified, obj.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if isNotModified(req, obj.ETag, obj.ModTime) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	if obj.Size >= 0 {
+		res.Header().Set(echo.HeaderContentLength, strconv.FormatInt(obj.Size, 10))
+	}
+	if req.Method == http.MethodHead {
+		c.Response().Header().Set(echo.HeaderContentType, obj.ContentType)
+		return c.NoContent(http.StatusOK)
+	}
+	return c.Stream(http.StatusOK, obj.ContentType, obj.Body)
+}
+
+func isNotModified(req *http.Request, etag string, modTime time.Time) bool {
+	if inm := req.Header.Get(echo.HeaderIfNoneMatch); inm != "" {
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := req.Header.Get(echo.HeaderIfModifiedSince); ims != "" && !modTime.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !modTime.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+func newObjectLRUCache(maxSize int64) *objectLRUCache {
+	return &objectLRUCache{
+		maxSize:  maxSize,
+		ll:       list.New(),
+		elements: map[string]*list.Element{},
+	}
+}
+
+func (l *objectLRUCache) get(key string, now time.Time) *cachedObject {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	e, ok := l.elements[key]
+	if !ok {
+		return nil
+	}
+	o := e.Value.(*cachedObject)
+	if now.After(o.expires) {
+		l.remove(e)
+		return nil
+	}
+	l.ll.MoveToFront(e)
+	return o
+}
+
+func (l *objectLRUCache) add(o *cachedObject) {
+	size := int64(len(o.content))
+	if size > l.maxSize {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if e, ok := l.elements[o.key]; ok {
+		l.remove(e)
+	}
+	l.elements[o.key] = l.ll.PushFront(o)
+	l.size += size
+	for l.size > l.maxSize {
+		l.remove(l.ll.Back())
+	}
+}
+
+func (l *objectLRUCache) remove(e *list.Element) {
+	o := e.Value.(*cachedObject)
+	l.ll.Remove(e)
+	delete(l.elements, o.key)
+	l.size -= int64(len(o.content))
+}
@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// S3ObjectStoreConfig defines the config for S3 compatible object store. Same store can be used with
+	// Amazon S3, MinIO and Google Cloud Storage (using its XML API and HMAC keys).
+	S3ObjectStoreConfig struct {
+		// Endpoint is base URL of the storage service, e.g. "https://s3.eu-west-1.amazonaws.com",
+		// "https://storage.googleapis.com" or "http://localhost:9000".
+		// Required.
+		Endpoint string `yaml:"endpoint"`
+
+		// Region is used for request signing.
+		// Optional. Default value "us-east-1".
+		Region string `yaml:"region"`
+
+		// Bucket is name of the bucket objects are served from.
+		// Required.
+		Bucket string `yaml:"bucket"`
+
+		// AccessKeyID and SecretAccessKey are credentials used to sign requests. When empty, requests are sent
+		// unsigned (public buckets).
+		AccessKeyID     string `yaml:"access_key_id"`
+		SecretAccessKey string `yaml:"-"`
+
+		// PathStyle uses "endpoint/bucket/key" addressing instead of "bucket.endpoint/key". MinIO usually
+		// requires path style addressing.
+		// Optional. Default value false.
+		PathStyle bool `yaml:"path_style"`
+
+		// Client is HTTP client used to communicate with the store.
+		// Optional. Default value http.DefaultClient.
+		Client *http.Client `yaml:"-"`
+
+		timeNow func() time.Time
+	}
+
+	// S3ObjectStore implements ObjectStore and ObjectURLSigner for S3 compatible storage services.
+	S3ObjectStore struct {
+		config   S3ObjectStoreConfig
+		endpoint *url.URL
+	}
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+	s3DateFormat      = "20060102"
+)
+
+// NewS3ObjectStore returns S3ObjectStore for given config.
+func NewS3ObjectStore(config S3ObjectStoreConfig) (*S3ObjectStore, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("echo: s3 object store requires bucket")
+	}
+	u, err := url.Parse(config.Endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("echo: invalid s3 object store endpoint=%s", config.Endpoint)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.timeNow == nil {
+		config.timeNow = time.Now
+	}
+	return &S3ObjectStore{config: config, endpoint: u}, nil
+}
+
+// NewGCSObjectStore returns object store for Google Cloud Storage bucket accessed with HMAC keys through the
+// S3 interoperable XML API.
+func NewGCSObjectStore(bucket, accessKeyID, secret string) (*S3ObjectStore, error) {
+	return NewS3ObjectStore(S3ObjectStoreConfig{
+		Endpoint:        "https://storage.googleapis.com",
+		Region:          "auto",
+		Bucket:          bucket,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secret,
+	})
+}
+
+// GetObject implements `ObjectStore.GetObject`.
+func (s *S3ObjectStore) GetObject(ctx context.Context, key string) (*RemoteObject, error) {
+	u := s.objectURL(key)
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	s.sign(req)
+
+	res, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusForbidden:
+		// S3 responds with 403 for missing keys when caller has no list permission
+		res.Body.Close()
+		return nil, ErrObjectNotFound
+	default:
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		return nil, fmt.Errorf("s3 object store: unexpected status=%d, body=%s", res.StatusCode, body)
+	}
+
+	obj := &RemoteObject{
+		Body:        res.Body,
+		Size:        res.ContentLength,
+		ETag:        res.Header.Get(echo.HeaderETag),
+		ContentType: res.Header.Get(echo.HeaderContentType),
+	}
+	if lm := res.Header.Get(echo.HeaderLastModified); lm != "" {
+		if t, err := http.ParseTime(lm); err == nil {
+			obj.ModTime = t
+		}
+	}
+	return obj, nil
+}
+
+// SignURL implements `ObjectURLSigner.SignURL` by creating AWS Signature Version 4 presigned URL.
+func (s *S3ObjectStore) SignURL(key string, expires time.Duration) (string, error) {
+	now := s.config.timeNow().UTC()
+	u := s.objectURL(key)
+
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", s3Algorithm)
+	q.Set("X-Amz-Credential", s.config.AccessKeyID+"/"+s.scope(now))
+	q.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		s3EncodePath(u.Path),
+		s3CanonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	q.Set("X-Amz-Signature", s.signature(now, canonicalRequest))
+	u.RawQuery = s3CanonicalQuery(q)
+	return u.String(), nil
+}
+
+func (s *S3ObjectStore) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	if s.config.PathStyle {
+		u.Path = "/" + s.config.Bucket + "/" + key
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	return &u
+}
+
+func (s *S3ObjectStore) sign(req *http.Request) {
+	if s.config.AccessKeyID == "" {
+		return
+	}
+	now := s.config.timeNow().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(s3TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + s3UnsignedPayload + "\n" +
+		"x-amz-date:" + now.Format(s3TimeFormat) + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EncodePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	req.Header.Set(echo.HeaderAuthorization, fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKeyID, s.scope(now), signedHeaders, s.signature(now, canonicalRequest)))
+}
+
+func (s *S3ObjectStore) scope(t time.Time) string {
+	return t.Format(s3DateFormat) + "/" + s.config.Region + "/s3/aws4_request"
+}
+
+func (s *S3ObjectStore) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + t.Format(s3TimeFormat) + "\n" + s.scope(t) + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), t.Format(s3DateFormat))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func s3CanonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, s3Encode(k, true)+"="+s3Encode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func s3EncodePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	return s3Encode(p, false)
+}
+
+// s3Encode URI encodes value as described in AWS Signature Version 4 documentation. Only unreserved characters
+// are left as is. Slash is encoded only when encodeSlash is set.
+func s3Encode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
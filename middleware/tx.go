@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// TxConfig defines the config for Tx middleware.
+	TxConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// DB is database transactions are started on.
+		// Required.
+		DB *sql.DB
+
+		// Isolation is isolation level of transactions. Zero value uses default level of the driver.
+		// Optional. Default value sql.LevelDefault.
+		Isolation sql.IsolationLevel
+
+		// ReadOnly returns true for requests served with read-only transaction (e.g. GET routes served by
+		// replicas).
+		// Optional. Default value nil (all transactions are read-write).
+		ReadOnly func(c echo.Context) bool
+
+		// Savepoints makes nested Tx middleware (e.g. on group and on route) create savepoint in transaction of the
+		// request instead of sharing it. Failed nested handler rolls back only to its savepoint so outer handler
+		// can recover from the error. Database must support `SAVEPOINT` statements.
+		// Optional. Default value false (nested middleware shares transaction of the request).
+		Savepoints bool
+	}
+
+	// txState is transaction of the request shared by nested Tx middlewares.
+	txState struct {
+		tx       *sql.Tx
+		depth    int
+		done     bool
+		onCommit []func()
+	}
+)
+
+// txStateKey is context key of `*txState`.
+const txStateKey = "echo_tx_state"
+
+var (
+	// DefaultTxConfig is the default Tx middleware config.
+	DefaultTxConfig = TxConfig{
+		Skipper: DefaultSkipper,
+	}
+)
+
+// Tx returns a middleware which begins database transaction for every request. See `TxWithConfig()`.
+func Tx(db *sql.DB) echo.MiddlewareFunc {
+	config := DefaultTxConfig
+	config.DB = db
+	return TxWithConfig(config)
+}
+
+// TxWithConfig returns a Tx middleware with config. Transaction is stored in the context (see `echo.TxFrom()`) and
+// is committed when response with 2xx status is sent (just before the status is written, so client does not see
+// success of failed commit) or when handler returns without error and without response. It is rolled back when
+// handler returns error, panics or sends other status.
+//
+// Example:
+//
+//	e.Use(middleware.TxWithConfig(middleware.TxConfig{
+//		DB: db,
+//		ReadOnly: func(c echo.Context) bool {
+//			return c.Request().Method == http.MethodGet
+//		},
+//	}))
+func TxWithConfig(config TxConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultTxConfig.Skipper
+	}
+	if config.DB == nil {
+		panic("echo: tx middleware requires db")
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			if state, ok := c.Get(txStateKey).(*txState); ok {
+				if !config.Savepoints || state.done {
+					return next(c)
+				}
+				return state.savepoint(c, next)
+			}
+
+			opts := &sql.TxOptions{Isolation: config.Isolation}
+			if config.ReadOnly != nil {
+				opts.ReadOnly = config.ReadOnly(c)
+			}
+			tx, err := config.DB.BeginTx(c.Request().Context(), opts)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to begin transaction").SetInternal(err)
+			}
+			state := &txState{tx: tx}
+			c.Set(echo.ContextKeyTx, tx)
+			c.Set(txStateKey, state)
+
+			res := c.Response()
+			res.Before(func() {
+				if err := state.finish(res.Status); err != nil {
+					c.Logger().Errorf("echo: failed to commit transaction: %v", err)
+					res.Status = http.StatusInternalServerError
+				}
+			})
+
+			defer func() {
+				if r := recover(); r != nil {
+					state.rollback(c)
+					panic(r)
+				}
+			}()
+			if err := next(c); err != nil {
+				state.rollback(c)
+				return err
+			}
+			status := res.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if err := state.finish(status); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to commit transaction").SetInternal(err)
+			}
+			return nil
+		}
+	}
+}
+
+// TxOnCommit registers function called after transaction of the request started by Tx middleware is committed, i.e.
+// to publish events stored in the transaction. False is returned when the request has no unfinished transaction.
+func TxOnCommit(c echo.Context, fn func()) bool {
+	state, ok := c.Get(txStateKey).(*txState)
+	if !ok || state.done {
+		return false
+	}
+	state.onCommit = append(state.onCommit, fn)
+	return true
+}
+
+// finish commits transaction for 2xx status and rolls it back otherwise. Finished transaction is not touched again.
+func (s *txState) finish(status int) error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	if status < 200 || status > 299 {
+		return s.tx.Rollback()
+	}
+	if err := s.tx.Commit(); err != nil {
+		return err
+	}
+	for _, fn := range s.onCommit {
+		fn()
+	}
+	return nil
+}
+
+func (s *txState) rollback(c echo.Context) {
+	if s.done {
+		return
+	}
+	s.done = true
+	if err := s.tx.Rollback(); err != nil {
+		c.Logger().Errorf("echo: failed to roll back transaction: %v", err)
+	}
+}
+
+// savepoint calls handler within savepoint of the transaction. Savepoint is rolled back when handler returns error
+// or panics and released otherwise. Nothing is done when the response was sent (and transaction finished) by the
+// handler.
+func (s *txState) savepoint(c echo.Context, next echo.HandlerFunc) (err error) {
+	ctx := c.Request().Context()
+	s.depth++
+	name := fmt.Sprintf("echo_tx_%d", s.depth)
+	defer func() { s.depth-- }()
+	if _, err := s.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create savepoint").SetInternal(err)
+	}
+
+	rollback := func() {
+		if s.done {
+			return
+		}
+		if _, err := s.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); err != nil {
+			c.Logger().Errorf("echo: failed to roll back to savepoint: %v", err)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			rollback()
+			panic(r)
+		}
+	}()
+	if err := next(c); err != nil {
+		rollback()
+		return err
+	}
+	if s.done {
+		return nil
+	}
+	if _, err := s.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to release savepoint").SetInternal(err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// txTestDriver is database driver recording transaction statements.
+type txTestDriver struct {
+	mu        sync.Mutex
+	log       []string
+	commitErr error
+}
+
+type txTestConn struct {
+	driver *txTestDriver
+}
+
+type txTestTx struct {
+	driver *txTestDriver
+}
+
+func (d *txTestDriver) record(s string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, s)
+}
+
+func (d *txTestDriver) statements() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return strings.Join(d.log, ";")
+}
+
+func (d *txTestDriver) Open(name string) (driver.Conn, error) {
+	return &txTestConn{driver: d}, nil
+}
+
+func (c *txTestConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *txTestConn) Close() error {
+	return nil
+}
+
+func (c *txTestConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *txTestConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.ReadOnly {
+		c.driver.record("BEGIN READ ONLY")
+	} else {
+		c.driver.record("BEGIN")
+	}
+	return &txTestTx{driver: c.driver}, nil
+}
+
+func (c *txTestConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query)
+	return driver.RowsAffected(1), nil
+}
+
+func (tx *txTestTx) Commit() error {
+	tx.driver.record("COMMIT")
+	return tx.driver.commitErr
+}
+
+func (tx *txTestTx) Rollback() error {
+	tx.driver.record("ROLLBACK")
+	return nil
+}
+
+type txTestConnector struct {
+	driver *txTestDriver
+}
+
+func (c txTestConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c txTestConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func newTxTestDB() (*sql.DB, *txTestDriver) {
+	d := &txTestDriver{}
+	return sql.OpenDB(txTestConnector{driver: d}), d
+}
+
+func TestTx(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		handler       echo.HandlerFunc
+		expectStatus  int
+		expectQueries string
+	}{
+		{
+			name: "ok, commit on 2xx",
+			handler: func(c echo.Context) error {
+				echo.TxFrom(c).Exec("INSERT")
+				return c.String(http.StatusCreated, "created")
+			},
+			expectStatus:  http.StatusCreated,
+			expectQueries: "BEGIN;INSERT;COMMIT",
+		},
+		{
+			name: "ok, commit without response",
+			handler: func(c echo.Context) error {
+				return nil
+			},
+			expectStatus:  http.StatusOK,
+			expectQueries: "BEGIN;COMMIT",
+		},
+		{
+			name: "nok, rollback on error",
+			handler: func(c echo.Context) error {
+				echo.TxFrom(c).Exec("INSERT")
+				return echo.ErrBadRequest
+			},
+			expectStatus:  http.StatusBadRequest,
+			expectQueries: "BEGIN;INSERT;ROLLBACK",
+		},
+		{
+			name: "nok, rollback on non 2xx response",
+			handler: func(c echo.Context) error {
+				return c.String(http.StatusConflict, "conflict")
+			},
+			expectStatus:  http.StatusConflict,
+			expectQueries: "BEGIN;ROLLBACK",
+		},
+		{
+			name: "nok, rollback on panic",
+			handler: func(c echo.Context) error {
+				panic("boom")
+			},
+			expectStatus:  http.StatusInternalServerError,
+			expectQueries: "BEGIN;ROLLBACK",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, d := newTxTestDB()
+			defer db.Close()
+			e := echo.New()
+			e.Use(RecoverWithConfig(RecoverConfig{DisablePrintStack: true}))
+			e.Use(Tx(db))
+			e.POST("/", tc.handler)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			assert.Equal(t, tc.expectQueries, d.statements())
+		})
+	}
+}
+
+func TestTx_commitError(t *testing.T) {
+	db, d := newTxTestDB()
+	defer db.Close()
+	d.commitErr = errors.New("serialization failure")
+	e := echo.New()
+	e.Use(Tx(db))
+	e.POST("/written", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.POST("/", func(c echo.Context) error {
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/written", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestTx_readOnly(t *testing.T) {
+	db, d := newTxTestDB()
+	defer db.Close()
+	e := echo.New()
+	e.Use(TxWithConfig(TxConfig{
+		DB: db,
+		ReadOnly: func(c echo.Context) bool {
+			return c.Request().Method == http.MethodGet
+		},
+	}))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "BEGIN READ ONLY;COMMIT", d.statements())
+}
+
+func TestTx_savepoints(t *testing.T) {
+	db, d := newTxTestDB()
+	defer db.Close()
+	e := echo.New()
+	e.Use(TxWithConfig(TxConfig{DB: db, Savepoints: true}))
+	failing := TxWithConfig(TxConfig{DB: db, Savepoints: true})(func(c echo.Context) error {
+		echo.TxFrom(c).Exec("INSERT audit")
+		return errors.New("audit failed")
+	})
+	succeeding := TxWithConfig(TxConfig{DB: db, Savepoints: true})(func(c echo.Context) error {
+		echo.TxFrom(c).Exec("INSERT event")
+		return nil
+	})
+	e.POST("/", func(c echo.Context) error {
+		echo.TxFrom(c).Exec("INSERT order")
+		assert.Error(t, failing(c))
+		assert.NoError(t, succeeding(c))
+		return c.NoContent(http.StatusCreated)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "BEGIN;INSERT order;SAVEPOINT echo_tx_1;INSERT audit;ROLLBACK TO SAVEPOINT echo_tx_1;"+
+		"SAVEPOINT echo_tx_1;INSERT event;RELEASE SAVEPOINT echo_tx_1;COMMIT", d.statements())
+}
+
+func TestTx_nestedWithoutSavepoints(t *testing.T) {
+	db, d := newTxTestDB()
+	defer db.Close()
+	e := echo.New()
+	e.Use(Tx(db))
+	g := e.Group("/api", Tx(db))
+	g.POST("", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api", nil))
+	assert.Equal(t, "BEGIN;COMMIT", d.statements())
+}
+
+func TestTxOnCommit(t *testing.T) {
+	db, _ := newTxTestDB()
+	defer db.Close()
+	e := echo.New()
+	e.Use(Tx(db))
+	var committed []string
+	e.POST("/:status", func(c echo.Context) error {
+		status := c.Param("status")
+		assert.True(t, TxOnCommit(c, func() {
+			committed = append(committed, status)
+		}))
+		if status == "ok" {
+			return c.NoContent(http.StatusOK)
+		}
+		return echo.ErrBadRequest
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/ok", nil))
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/conflict", nil))
+	assert.Equal(t, []string{"ok"}, committed)
+
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+	assert.False(t, TxOnCommit(c, func() {}))
+}
+
+func TestTx_requiresDB(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: tx middleware requires db", func() {
+		Tx(nil)
+	})
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+type (
+	// UploadRateConfig defines the config for UploadRate middleware.
+	UploadRateConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// MinRate is minimum read rate of request body in bytes per second. Value 0 disables the check. Handlers
+		// can change it for single request with `UploadStats.MinRate` (i.e. for clients on slow networks).
+		// Optional. Default value 0.
+		MinRate int64 `yaml:"min_rate"`
+
+		// GracePeriod is time since start of the request during which the rate is not checked, so short pauses at
+		// the beginning of the upload (connection setup, slow start) are not reported.
+		// Optional. Default value 5 seconds.
+		GracePeriod time.Duration `yaml:"grace_period"`
+
+		// Abort makes reading of request body from slow uploaders fail with ErrUploadTooSlow (408), so handler
+		// binding the body returns it without waiting for the rest of the upload.
+		// Optional. Default value false.
+		Abort bool `yaml:"abort"`
+
+		// OnSlow is called once per request when read rate drops below MinRate.
+		// Optional. Default value logs the stats as warning with logger of the context.
+		OnSlow func(c echo.Context, stats *UploadStats)
+
+		// ContextKey is key under which `*UploadStats` is stored in context.
+		// Optional. Default value "upload".
+		ContextKey string
+	}
+
+	// UploadStats holds upload throughput of request body. Stats are updated as the body is read and are not safe
+	// for concurrent use.
+	UploadStats struct {
+		// Bytes is number of bytes of body read so far.
+		Bytes int64 `json:"bytes"`
+		// Duration is time from start of the request to the last read.
+		Duration time.Duration `json:"duration"`
+		// Complete is true when the whole body was read.
+		Complete bool `json:"complete"`
+		// MinRate is minimum read rate in bytes per second of the request, initialized from config.
+		MinRate int64 `json:"min_rate"`
+		// Slow is true when read rate dropped below MinRate.
+		Slow bool `json:"slow"`
+
+		start time.Time
+	}
+
+	uploadRateReader struct {
+		reader  io.ReadCloser
+		config  *UploadRateConfig
+		context echo.Context
+		stats   *UploadStats
+	}
+)
+
+// ErrUploadTooSlow denotes an error raised when request body is read slower than minimum rate.
+var ErrUploadTooSlow = echo.NewHTTPError(http.StatusRequestTimeout, "upload too slow")
+
+// DefaultUploadRateConfig is the default UploadRate middleware config.
+var DefaultUploadRateConfig = UploadRateConfig{
+	Skipper:     DefaultSkipper,
+	GracePeriod: 5 * time.Second,
+	OnSlow: func(c echo.Context, stats *UploadStats) {
+		c.Logger().Warnj(log.JSON{
+			"message":  "slow upload",
+			"method":   c.Request().Method,
+			"path":     c.Path(),
+			"remote":   c.RealIP(),
+			"bytes":    stats.Bytes,
+			"duration": stats.Duration.String(),
+			"rate":     int64(stats.Rate()),
+			"min_rate": stats.MinRate,
+		})
+	},
+	ContextKey: "upload",
+}
+
+// UploadRate returns a middleware that measures throughput of reading request body and stores it as `*UploadStats`
+// in context under key "upload". Requests read slower than `UploadRateConfig.MinRate` are reported to the logger
+// and, with `UploadRateConfig.Abort`, their body reads fail with ErrUploadTooSlow.
+//
+// Rate is checked when the handler reads the body, client sending nothing at all blocks the read; limit it with
+// `http.Server.ReadTimeout`.
+//
+// Example:
+//
+//	e.Use(middleware.UploadRateWithConfig(middleware.UploadRateConfig{MinRate: 10 << 10}))
+//	e.POST("/files", func(c echo.Context) error {
+//		if _, err := io.Copy(dst, c.Request().Body); err != nil {
+//			return err
+//		}
+//		stats := c.Get("upload").(*middleware.UploadStats)
+//		...
+//	})
+func UploadRate() echo.MiddlewareFunc {
+	return UploadRateWithConfig(DefaultUploadRateConfig)
+}
+
+// UploadRateWithConfig returns an UploadRate middleware with config.
+// See: `UploadRate()`.
+func UploadRateWithConfig(config UploadRateConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultUploadRateConfig.Skipper
+	}
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = DefaultUploadRateConfig.GracePeriod
+	}
+	if config.OnSlow == nil {
+		config.OnSlow = DefaultUploadRateConfig.OnSlow
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultUploadRateConfig.ContextKey
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			stats := &UploadStats{MinRate: config.MinRate, start: echo.Now(c)}
+			c.Set(config.ContextKey, stats)
+			if req.Body == nil || req.Body == http.NoBody {
+				stats.Complete = true
+				return next(c)
+			}
+			req.Body = &uploadRateReader{reader: req.Body, config: &config, context: c, stats: stats}
+			return next(c)
+		}
+	}
+}
+
+// Rate returns read rate in bytes per second.
+func (s *UploadStats) Rate() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Duration.Seconds()
+}
+
+func (r *uploadRateReader) Read(b []byte) (int, error) {
+	stats := r.stats
+	if stats.Slow && r.config.Abort {
+		return 0, ErrUploadTooSlow
+	}
+	n, err := r.reader.Read(b)
+	stats.Bytes += int64(n)
+	stats.Duration = echo.Now(r.context).Sub(stats.start)
+	if err == io.EOF {
+		stats.Complete = true
+		return n, err
+	}
+	if stats.Slow || stats.MinRate <= 0 || stats.Duration < r.config.GracePeriod {
+		return n, err
+	}
+	if stats.Rate() < float64(stats.MinRate) {
+		stats.Slow = true
+		r.config.OnSlow(r.context, stats)
+		if r.config.Abort {
+			return n, ErrUploadTooSlow
+		}
+	}
+	return n, err
+}
+
+func (r *uploadRateReader) Close() error {
+	return r.reader.Close()
+}
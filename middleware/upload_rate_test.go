@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/labstack/gommon/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowReader returns one byte per read and advances clock by delay before each read.
+type slowReader struct {
+	data  string
+	clock *echotest.Clock
+	delay time.Duration
+}
+
+func (r *slowReader) Read(b []byte) (int, error) {
+	if r.data == "" {
+		return 0, io.EOF
+	}
+	r.clock.Advance(r.delay)
+	b[0] = r.data[0]
+	r.data = r.data[1:]
+	return 1, nil
+}
+
+func TestUploadRate(t *testing.T) {
+	e := echo.New()
+	e.Clock = echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var stats *UploadStats
+	h := UploadRate()(func(c echo.Context) error {
+		body, err := ioutil.ReadAll(c.Request().Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(body))
+		stats = c.Get("upload").(*UploadStats)
+		return nil
+	})
+	assert.NoError(t, h(c))
+	assert.Equal(t, int64(5), stats.Bytes)
+	assert.True(t, stats.Complete)
+	assert.False(t, stats.Slow)
+}
+
+func TestUploadRate_slow(t *testing.T) {
+	tests := []struct {
+		name         string
+		givenAbort   bool
+		givenMinRate int64
+		expectErr    error
+		expectSlow   bool
+		expectBytes  int64
+	}{
+		{
+			name:         "report",
+			givenMinRate: 10,
+			expectSlow:   true,
+			expectBytes:  20,
+		},
+		{
+			name:         "abort",
+			givenAbort:   true,
+			givenMinRate: 10,
+			expectErr:    ErrUploadTooSlow,
+			expectSlow:   true,
+			expectBytes:  5,
+		},
+		{
+			name:         "handler lowers min rate",
+			givenAbort:   true,
+			givenMinRate: 0,
+			expectBytes:  20,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+			e.Clock = clock
+			buf := new(bytes.Buffer)
+			e.Logger.SetOutput(buf)
+			e.Logger.SetLevel(log.WARN)
+
+			// 1 byte per second
+			body := &slowReader{data: strings.Repeat("x", 20), clock: clock, delay: time.Second}
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			var stats *UploadStats
+			mw := UploadRateWithConfig(UploadRateConfig{MinRate: 10, Abort: tc.givenAbort})
+			err := mw(func(c echo.Context) error {
+				stats = c.Get("upload").(*UploadStats)
+				stats.MinRate = tc.givenMinRate
+				_, err := ioutil.ReadAll(c.Request().Body)
+				return err
+			})(c)
+
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectSlow, stats.Slow)
+			assert.Equal(t, tc.expectBytes, stats.Bytes)
+			if tc.expectSlow {
+				assert.Contains(t, buf.String(), `"message":"slow upload"`)
+				assert.Equal(t, 1, strings.Count(buf.String(), "slow upload"))
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+func TestUploadRate_noBody(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := UploadRate()(func(c echo.Context) error {
+		stats := c.Get("upload").(*UploadStats)
+		assert.True(t, stats.Complete)
+		assert.Equal(t, float64(0), stats.Rate())
+		return nil
+	})
+	assert.NoError(t, h(c))
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// URLPolicyConfig defines the config for URLPolicy middleware.
+	URLPolicyConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper Skipper
+
+		// DuplicateParams is policy for query parameters sent more than once:
+		// - "" keeps all values
+		// - "first" keeps first value
+		// - "last" keeps last value
+		// - "error" rejects request with 400 Bad Request
+		// Optional. Default value "".
+		DuplicateParams string `yaml:"duplicate_params"`
+
+		// EmptyParams is policy for query parameters with empty value (`?a=&b`):
+		// - "" keeps parameters
+		// - "drop" removes parameters
+		// - "error" rejects request with 400 Bad Request
+		// Optional. Default value "".
+		EmptyParams string `yaml:"empty_params"`
+
+		// Semicolons is policy for semicolons in query string. Go since 1.17 silently drops query parameters
+		// containing semicolon while some proxies and frameworks treat semicolon as separator:
+		// - "" keeps behaviour of Go (parameters with semicolon are dropped)
+		// - "split" treats semicolon as separator same as `&`
+		// - "error" rejects request with 400 Bad Request
+		// Optional. Default value "".
+		Semicolons string `yaml:"semicolons"`
+
+		// DuplicateSlashes is policy for consecutive slashes in path (`/a//b`):
+		// - "" keeps path as it is
+		// - "merge" replaces consecutive slashes with single slash
+		// - "error" rejects request with 400 Bad Request
+		// Optional. Default value "".
+		DuplicateSlashes string `yaml:"duplicate_slashes"`
+	}
+)
+
+// DefaultURLPolicyConfig is the default URLPolicy middleware config. It rejects requests with ambiguous query
+// strings and merges duplicate slashes in path.
+var DefaultURLPolicyConfig = URLPolicyConfig{
+	Skipper:          DefaultSkipper,
+	DuplicateParams:  "error",
+	Semicolons:       "error",
+	DuplicateSlashes: "merge",
+}
+
+// URLPolicy returns a root level (before router) middleware which enforces policies for duplicate and empty query
+// parameters, semicolon separators and duplicate slashes in path. Query string is rewritten before any handler or
+// binder parses it, so all parts of the application (and upstream proxies) see the same parameters. This closes
+// parser differential issues, i.e. proxy authorizing first value of parameter while application uses last one.
+//
+// Usage `Echo#Pre(URLPolicy())`
+func URLPolicy() echo.MiddlewareFunc {
+	return URLPolicyWithConfig(DefaultURLPolicyConfig)
+}
+
+// URLPolicyWithConfig returns a URLPolicy middleware with config.
+// See `URLPolicy()`.
+func URLPolicyWithConfig(config URLPolicyConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultURLPolicyConfig.Skipper
+	}
+	checkPolicy("duplicate params", config.DuplicateParams, "first", "last", "error")
+	checkPolicy("empty params", config.EmptyParams, "drop", "error")
+	checkPolicy("semicolons", config.Semicolons, "split", "error")
+	checkPolicy("duplicate slashes", config.DuplicateSlashes, "merge", "error")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			u := c.Request().URL
+			if config.DuplicateSlashes != "" && strings.Contains(u.Path, "//") {
+				if config.DuplicateSlashes == "error" {
+					return echo.NewHTTPError(http.StatusBadRequest, "duplicate slashes in path")
+				}
+				u.Path = mergeSlashes(u.Path)
+				if u.RawPath != "" {
+					u.RawPath = mergeSlashes(u.RawPath)
+				}
+			}
+
+			if u.RawQuery != "" {
+				query, err := config.parseQuery(u.RawQuery)
+				if err != nil {
+					return err
+				}
+				u.RawQuery = query
+			}
+			return next(c)
+		}
+	}
+}
+
+func checkPolicy(name, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	panic(fmt.Sprintf("echo: invalid %s policy %q", name, value))
+}
+
+// parseQuery applies policies to raw query string and returns query string with parameters in original order.
+func (config URLPolicyConfig) parseQuery(rawQuery string) (string, error) {
+	if config.Semicolons == "error" && strings.Contains(rawQuery, ";") {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "semicolon in query string")
+	}
+	separators := "&"
+	if config.Semicolons == "split" {
+		separators = "&;"
+	}
+
+	type param struct {
+		key, pair string
+	}
+	params := []param{}
+	index := map[string]int{}
+	for _, pair := range strings.FieldsFunc(rawQuery, func(r rune) bool { return strings.ContainsRune(separators, r) }) {
+		rawKey, rawValue := pair, ""
+		if i := strings.IndexByte(pair, '='); i >= 0 {
+			rawKey, rawValue = pair[:i], pair[i+1:]
+		}
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "invalid query string").SetInternal(err)
+		}
+		if _, err := url.QueryUnescape(rawValue); err != nil {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "invalid query string").SetInternal(err)
+		}
+
+		if rawValue == "" {
+			switch config.EmptyParams {
+			case "drop":
+				continue
+			case "error":
+				return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("empty query parameter %q", key))
+			}
+		}
+		if i, ok := index[key]; ok {
+			switch config.DuplicateParams {
+			case "first":
+				continue
+			case "last":
+				params[i].pair = pair
+				continue
+			case "error":
+				return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("duplicate query parameter %q", key))
+			}
+		} else {
+			index[key] = len(params)
+		}
+		params = append(params, param{key: key, pair: pair})
+	}
+
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p.pair
+	}
+	return strings.Join(pairs, "&"), nil
+}
+
+func mergeSlashes(path string) string {
+	for strings.Contains(path, "//") {
+		path = strings.Replace(path, "//", "/", -1)
+	}
+	return path
+}
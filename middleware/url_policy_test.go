@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLPolicy(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		givenConfig URLPolicyConfig
+		whenURL     string
+		expectCode  int
+		expectPath  string
+		expectQuery string
+	}{
+		{
+			name:        "default keeps unique params",
+			givenConfig: DefaultURLPolicyConfig,
+			whenURL:     "/users?b=2&a=1&c=",
+			expectCode:  http.StatusOK,
+			expectPath:  "/users",
+			expectQuery: "b=2&a=1&c=",
+		},
+		{
+			name:        "default rejects duplicate params",
+			givenConfig: DefaultURLPolicyConfig,
+			whenURL:     "/users?id=1&id=2",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "default rejects duplicate params differing in encoding",
+			givenConfig: DefaultURLPolicyConfig,
+			whenURL:     "/users?id=1&i%64=2",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "default rejects semicolons",
+			givenConfig: DefaultURLPolicyConfig,
+			whenURL:     "/users?id=1;admin=true",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "default merges slashes",
+			givenConfig: DefaultURLPolicyConfig,
+			whenURL:     "/api//users///1",
+			expectCode:  http.StatusOK,
+			expectPath:  "/api/users/1",
+		},
+		{
+			name:        "first value",
+			givenConfig: URLPolicyConfig{DuplicateParams: "first"},
+			whenURL:     "/users?id=1&x=y&id=2",
+			expectCode:  http.StatusOK,
+			expectPath:  "/users",
+			expectQuery: "id=1&x=y",
+		},
+		{
+			name:        "last value",
+			givenConfig: URLPolicyConfig{DuplicateParams: "last"},
+			whenURL:     "/users?id=1&x=y&id=2",
+			expectCode:  http.StatusOK,
+			expectPath:  "/users",
+			expectQuery: "id=2&x=y",
+		},
+		{
+			name:        "drop empty params",
+			givenConfig: URLPolicyConfig{EmptyParams: "drop"},
+			whenURL:     "/users?a=&b&c=1",
+			expectCode:  http.StatusOK,
+			expectPath:  "/users",
+			expectQuery: "c=1",
+		},
+		{
+			name:        "reject empty params",
+			givenConfig: URLPolicyConfig{EmptyParams: "error"},
+			whenURL:     "/users?a=&c=1",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "split semicolons",
+			givenConfig: URLPolicyConfig{Semicolons: "split"},
+			whenURL:     "/users?a=1;b=2",
+			expectCode:  http.StatusOK,
+			expectPath:  "/users",
+			expectQuery: "a=1&b=2",
+		},
+		{
+			name:        "reject duplicate slashes",
+			givenConfig: URLPolicyConfig{DuplicateSlashes: "error"},
+			whenURL:     "/users//1",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "reject invalid escape",
+			givenConfig: URLPolicyConfig{},
+			whenURL:     "/users?a=%zz",
+			expectCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Pre(URLPolicyWithConfig(tc.givenConfig))
+			var path, query string
+			e.Any("/*", func(c echo.Context) error {
+				path = c.Request().URL.Path
+				query = c.QueryString()
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectCode == http.StatusOK {
+				assert.Equal(t, tc.expectPath, path)
+				assert.Equal(t, tc.expectQuery, query)
+			}
+		})
+	}
+}
+
+func TestURLPolicyInvalidConfig(t *testing.T) {
+	assert.PanicsWithValue(t, `echo: invalid duplicate params policy "random"`, func() {
+		URLPolicyWithConfig(URLPolicyConfig{DuplicateParams: "random"})
+	})
+}
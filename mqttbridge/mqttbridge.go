@@ -0,0 +1,100 @@
+/*
+Package mqttbridge bridges MQTT broker and HTTP clients for IoT dashboards: HTTP POSTs are published to MQTT topics
+and messages of MQTT topics are streamed to browsers as server-sent events or over WebSocket.
+
+Broker is accessed through `Client` implemented by a small adapter of MQTT client library, i.e. for Eclipse Paho:
+
+	type pahoClient struct {
+		c mqtt.Client
+	}
+
+	func (p pahoClient) Publish(ctx context.Context, topic string, qos byte, retain bool, payload []byte) error {
+		t := p.c.Publish(topic, qos, retain, payload)
+		t.Wait()
+		return t.Error()
+	}
+
+	func (p pahoClient) Subscribe(topic string, qos byte, handle func(mqttbridge.Message)) (func(), error) {
+		t := p.c.Subscribe(topic, qos, func(_ mqtt.Client, m mqtt.Message) {
+			handle(mqttbridge.Message{Topic: m.Topic(), Payload: m.Payload(), Retained: m.Retained()})
+		})
+		if t.Wait(); t.Error() != nil {
+			return nil, t.Error()
+		}
+		return func() { p.c.Unsubscribe(topic) }, nil
+	}
+
+Example:
+
+	client := pahoClient{c: c}
+	e.POST("/devices/*", mqttbridge.Publish(client))     // POST /devices/lamp/1/set -> devices/lamp/1/set
+	e.GET("/sensors/events/*", mqttbridge.Events(client)) // GET /sensors/events/%2B/temperature -> sensors/+/temperature
+	e.GET("/sensors/ws/*", mqttbridge.WebSocket(client))
+*/
+package mqttbridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Client is MQTT broker client used by bridge handlers.
+	Client interface {
+		// Publish publishes payload to the topic and waits until it is delivered to broker (for QoS above 0).
+		Publish(ctx context.Context, topic string, qos byte, retain bool, payload []byte) error
+
+		// Subscribe subscribes handle to messages of topic filter, which may contain `+` and `#` wildcards.
+		// Returned function unsubscribes handle.
+		Subscribe(filter string, qos byte, handle func(m Message)) (unsubscribe func(), err error)
+	}
+
+	// Message is MQTT message received from broker.
+	Message struct {
+		Topic    string
+		Payload  []byte
+		Retained bool
+	}
+
+	// TopicFunc returns MQTT topic (or topic filter) of the request.
+	TopicFunc func(c echo.Context) (string, error)
+)
+
+// ErrInvalidTopic is returned when topic of the request is empty, malformed or (for publishing) contains wildcards.
+var ErrInvalidTopic = echo.NewHTTPError(http.StatusBadRequest, "invalid MQTT topic")
+
+// TopicFromParam returns TopicFunc reading topic from path param. Param value is unescaped so topic filter
+// wildcards can be sent encoded (`%2B` for `+` and `%23` for `#`).
+func TopicFromParam(name string) TopicFunc {
+	return func(c echo.Context) (string, error) {
+		topic, err := url.PathUnescape(c.Param(name))
+		if err != nil || topic == "" {
+			return "", ErrInvalidTopic
+		}
+		return topic, nil
+	}
+}
+
+// EncodeJSON encodes message as JSON object with `topic`, `payload` and `retained` fields. Payload is embedded as
+// JSON value when it is valid JSON, otherwise as string.
+func EncodeJSON(m Message) ([]byte, error) {
+	var payload interface{} = string(m.Payload)
+	if json.Valid(m.Payload) {
+		payload = json.RawMessage(m.Payload)
+	}
+	return json.Marshal(struct {
+		Topic    string      `json:"topic"`
+		Payload  interface{} `json:"payload"`
+		Retained bool        `json:"retained,omitempty"`
+	}{m.Topic, payload, m.Retained})
+}
+
+// isTopicName reports whether topic can be published to: it is not empty and has no wildcards.
+func isTopicName(topic string) bool {
+	return topic != "" && !strings.ContainsAny(topic, "+#\x00")
+}
@@ -0,0 +1,115 @@
+package mqttbridge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type published struct {
+	topic   string
+	qos     byte
+	retain  bool
+	payload string
+}
+
+type testClient struct {
+	mutex      sync.Mutex
+	published  []published
+	handlers   map[string]func(m Message)
+	subscribed chan string
+	err        error
+}
+
+func newTestClient() *testClient {
+	return &testClient{handlers: map[string]func(m Message){}, subscribed: make(chan string, 1)}
+}
+
+func (t *testClient) Publish(ctx context.Context, topic string, qos byte, retain bool, payload []byte) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.err != nil {
+		return t.err
+	}
+	t.published = append(t.published, published{topic: topic, qos: qos, retain: retain, payload: string(payload)})
+	return nil
+}
+
+func (t *testClient) Subscribe(filter string, qos byte, handle func(m Message)) (func(), error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.err != nil {
+		return nil, t.err
+	}
+	t.handlers[filter] = handle
+	t.subscribed <- filter
+	return func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		delete(t.handlers, filter)
+	}, nil
+}
+
+func (t *testClient) deliver(filter string, m Message) {
+	t.mutex.Lock()
+	handle := t.handlers[filter]
+	t.mutex.Unlock()
+	handle(m)
+}
+
+func (t *testClient) subscriptions() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.handlers)
+}
+
+func TestTopicFromParam(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		param       string
+		expect      string
+		expectError error
+	}{
+		{name: "plain", param: "sensors/1/temperature", expect: "sensors/1/temperature"},
+		{name: "encoded wildcards", param: "sensors/%2B/%23", expect: "sensors/+/#"},
+		{name: "empty", param: "", expectError: ErrInvalidTopic},
+		{name: "invalid escape", param: "sensors/%zz", expectError: ErrInvalidTopic},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+			c.SetParamNames("*")
+			c.SetParamValues(tc.param)
+
+			topic, err := TopicFromParam("*")(c)
+			assert.Equal(t, tc.expect, topic)
+			assert.Equal(t, tc.expectError, err)
+		})
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	b, err := EncodeJSON(Message{Topic: "a/b", Payload: []byte(`{"t":21.5}`), Retained: true})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"topic":"a/b","payload":{"t":21.5},"retained":true}`, string(b))
+
+	b, err = EncodeJSON(Message{Topic: "a/b", Payload: []byte("on")})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"topic":"a/b","payload":"on"}`, string(b))
+}
+
+func TestIsTopicName(t *testing.T) {
+	assert.True(t, isTopicName("devices/lamp/set"))
+	assert.False(t, isTopicName(""))
+	assert.False(t, isTopicName("devices/+/set"))
+	assert.False(t, isTopicName("devices/#"))
+}
+
+var errBroker = errors.New("broker unavailable")
@@ -0,0 +1,78 @@
+package mqttbridge
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// PublishConfig defines the config for publish handler.
+	PublishConfig struct {
+		// Topic returns topic the request body is published to.
+		// Optional. Default value `TopicFromParam("*")`.
+		Topic TopicFunc
+
+		// QoS is MQTT quality of service level of published messages.
+		// Optional. Default value 0.
+		QoS byte
+
+		// Retain makes broker keep the last message of topic for new subscribers, i.e. for device state.
+		// Optional. Default value false.
+		Retain bool
+
+		// MaxPayloadSize is maximum size of request body in bytes. Larger requests are rejected with 413.
+		// Optional. Default value 64KB.
+		MaxPayloadSize int64
+	}
+)
+
+// DefaultPublishConfig is the default publish handler config.
+var DefaultPublishConfig = PublishConfig{
+	Topic:          TopicFromParam("*"),
+	MaxPayloadSize: 64 * 1024,
+}
+
+// Publish returns handler publishing request body to MQTT topic from the path. See `PublishWithConfig()`.
+func Publish(client Client) echo.HandlerFunc {
+	return PublishWithConfig(client, DefaultPublishConfig)
+}
+
+// PublishWithConfig returns handler publishing request body as payload of MQTT message. Handler responds with 202
+// when message is accepted by broker.
+func PublishWithConfig(client Client, config PublishConfig) echo.HandlerFunc {
+	// Defaults
+	if client == nil {
+		panic("echo: mqttbridge publish handler requires client")
+	}
+	if config.Topic == nil {
+		config.Topic = DefaultPublishConfig.Topic
+	}
+	if config.MaxPayloadSize == 0 {
+		config.MaxPayloadSize = DefaultPublishConfig.MaxPayloadSize
+	}
+
+	return func(c echo.Context) error {
+		topic, err := config.Topic(c)
+		if err != nil {
+			return err
+		}
+		if !isTopicName(topic) {
+			return ErrInvalidTopic
+		}
+
+		req := c.Request()
+		if req.ContentLength > config.MaxPayloadSize {
+			return echo.ErrStatusRequestEntityTooLarge
+		}
+		payload, err := ioutil.ReadAll(http.MaxBytesReader(c.Response(), req.Body, config.MaxPayloadSize))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge).SetInternal(err)
+		}
+		if err := client.Publish(req.Context(), topic, config.QoS, config.Retain, payload); err != nil {
+			return echo.NewHTTPError(http.StatusBadGateway, "failed to publish message").SetInternal(err)
+		}
+		return c.NoContent(http.StatusAccepted)
+	}
+}
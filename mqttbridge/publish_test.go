@@ -0,0 +1,78 @@
+package mqttbridge
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	var testCases = []struct {
+		name            string
+		config          PublishConfig
+		url             string
+		body            string
+		clientErr       error
+		expectStatus    int
+		expectPublished []published
+	}{
+		{
+			name:            "published",
+			url:             "/devices/lamp/1/set",
+			body:            `{"on":true}`,
+			expectStatus:    http.StatusAccepted,
+			expectPublished: []published{{topic: "lamp/1/set", payload: `{"on":true}`}},
+		},
+		{
+			name:            "qos and retain",
+			config:          PublishConfig{QoS: 1, Retain: true},
+			url:             "/devices/lamp/1/state",
+			body:            "on",
+			expectStatus:    http.StatusAccepted,
+			expectPublished: []published{{topic: "lamp/1/state", qos: 1, retain: true, payload: "on"}},
+		},
+		{
+			name:         "wildcard topic",
+			url:          "/devices/lamp/%2B/set",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "payload too large",
+			config:       PublishConfig{MaxPayloadSize: 2},
+			url:          "/devices/lamp",
+			body:         "long",
+			expectStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "broker error",
+			url:          "/devices/lamp",
+			clientErr:    errBroker,
+			expectStatus: http.StatusBadGateway,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newTestClient()
+			client.err = tc.clientErr
+			e := echo.New()
+			e.POST("/devices/*", PublishWithConfig(client, tc.config))
+
+			req := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectStatus, rec.Code)
+			assert.Equal(t, tc.expectPublished, client.published)
+		})
+	}
+}
+
+func TestPublishWithConfig_panicsWithoutClient(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: mqttbridge publish handler requires client", func() {
+		Publish(nil)
+	})
+}
@@ -0,0 +1,234 @@
+package mqttbridge
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+type (
+	// StreamConfig defines the config for handlers streaming MQTT messages with server-sent events and WebSocket.
+	StreamConfig struct {
+		// Topic returns topic filter the client subscribes to. Filter may contain `+` and `#` wildcards.
+		// Optional. Default value `TopicFromParam("*")`.
+		Topic TopicFunc
+
+		// QoS is MQTT quality of service level of the subscription.
+		// Optional. Default value 0.
+		QoS byte
+
+		// Encode encodes message sent to the client.
+		// Optional. Default value `EncodeJSON`.
+		Encode func(m Message) ([]byte, error)
+
+		// BufferSize is number of messages buffered for the client. Messages received while the buffer is full are
+		// dropped so slow clients do not block the broker client.
+		// Optional. Default value 16.
+		BufferSize int
+
+		// KeepAliveInterval is interval of comments sent to idle server-sent event streams to keep proxies from
+		// closing them.
+		// Optional. Default value 15 seconds.
+		KeepAliveInterval time.Duration
+
+		// AllowOrigins lists origins allowed to open WebSocket connections, "*" allows all origins.
+		// Optional. Default value allows only connections from the same host (and clients not sending `Origin`).
+		AllowOrigins []string
+	}
+)
+
+// DefaultStreamConfig is the default stream handlers config.
+var DefaultStreamConfig = StreamConfig{
+	Topic:             TopicFromParam("*"),
+	Encode:            EncodeJSON,
+	BufferSize:        16,
+	KeepAliveInterval: 15 * time.Second,
+}
+
+// Events returns handler streaming messages of MQTT topic filter from the path as server-sent events. See
+// `EventsWithConfig()`.
+func Events(client Client) echo.HandlerFunc {
+	return EventsWithConfig(client, DefaultStreamConfig)
+}
+
+// EventsWithConfig returns handler streaming messages of MQTT topic filter as server-sent events with `message`
+// event type. Stream ends when the client disconnects or, with final `shutdown` event (see `echo.WriteSSEShutdown()`),
+// when the server shuts down or long-lived connections are closed by `Echo#CloseLongLived()`.
+func EventsWithConfig(client Client, config StreamConfig) echo.HandlerFunc {
+	config = config.withDefaults(client)
+
+	return func(c echo.Context) error {
+		messages, unsubscribe, err := config.subscribe(client, c)
+		if err != nil {
+			return err
+		}
+		defer unsubscribe()
+		drain, done := c.Echo().LongLived()
+		defer done()
+
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set(echo.HeaderCacheControl, "no-cache")
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(http.StatusOK)
+		res.Flush()
+
+		ticker := time.NewTicker(config.KeepAliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.Request().Context().Done():
+				return nil
+			case msg := <-drain:
+				echo.WriteSSEShutdown(c, msg)
+				return nil
+			case <-ticker.C:
+				if _, err := io.WriteString(res, ": keep-alive\n\n"); err != nil {
+					return nil
+				}
+			case m := <-messages:
+				data, err := config.Encode(m)
+				if err != nil {
+					c.Logger().Errorf("mqttbridge: failed to encode message of topic %s: %v", m.Topic, err)
+					continue
+				}
+				if _, err := io.WriteString(res, formatEvent(data)); err != nil {
+					return nil
+				}
+			}
+			res.Flush()
+		}
+	}
+}
+
+// WebSocket returns handler streaming messages of MQTT topic filter from the path over WebSocket. See
+// `WebSocketWithConfig()`.
+func WebSocket(client Client) echo.HandlerFunc {
+	return WebSocketWithConfig(client, DefaultStreamConfig)
+}
+
+// WebSocketWithConfig returns handler streaming messages of MQTT topic filter over WebSocket, one text frame per
+// message. Frames sent by the client are ignored. Connections from origins not allowed by the config are rejected
+// with 403. When the server shuts down or long-lived connections are closed by `Echo#CloseLongLived()`, connection
+// is closed with 1001 (Going Away) status and the grace message (see `echo.WebSocketShutdownClose()`).
+func WebSocketWithConfig(client Client, config StreamConfig) echo.HandlerFunc {
+	config = config.withDefaults(client)
+
+	return func(c echo.Context) error {
+		if !config.allowOrigin(c.Request()) {
+			return echo.ErrForbidden
+		}
+		messages, unsubscribe, err := config.subscribe(client, c)
+		if err != nil {
+			return err
+		}
+		defer unsubscribe()
+		drain, done := c.Echo().LongLived()
+		defer done()
+
+		websocket.Server{Handler: func(ws *websocket.Conn) {
+			closed := make(chan struct{})
+			go func() {
+				io.Copy(ioutil.Discard, ws)
+				close(closed)
+			}()
+			for {
+				select {
+				case <-closed:
+					return
+				case msg := <-drain:
+					ws.PayloadType = websocket.CloseFrame
+					ws.Write(echo.WebSocketShutdownClose(msg))
+					return
+				case m := <-messages:
+					data, err := config.Encode(m)
+					if err != nil {
+						c.Logger().Errorf("mqttbridge: failed to encode message of topic %s: %v", m.Topic, err)
+						continue
+					}
+					if err := websocket.Message.Send(ws, string(data)); err != nil {
+						return
+					}
+				}
+			}
+		}}.ServeHTTP(c.Response(), c.Request())
+		return nil
+	}
+}
+
+func (config StreamConfig) withDefaults(client Client) StreamConfig {
+	if client == nil {
+		panic("echo: mqttbridge stream handler requires client")
+	}
+	if config.Topic == nil {
+		config.Topic = DefaultStreamConfig.Topic
+	}
+	if config.Encode == nil {
+		config.Encode = DefaultStreamConfig.Encode
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultStreamConfig.BufferSize
+	}
+	if config.KeepAliveInterval <= 0 {
+		config.KeepAliveInterval = DefaultStreamConfig.KeepAliveInterval
+	}
+	return config
+}
+
+// subscribe subscribes to topic filter of the request. Messages not fitting into the buffer are dropped.
+func (config StreamConfig) subscribe(client Client, c echo.Context) (<-chan Message, func(), error) {
+	filter, err := config.Topic(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if filter == "" || strings.ContainsRune(filter, 0) {
+		return nil, nil, ErrInvalidTopic
+	}
+	messages := make(chan Message, config.BufferSize)
+	unsubscribe, err := client.Subscribe(filter, config.QoS, func(m Message) {
+		select {
+		case messages <- m:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadGateway, "failed to subscribe").SetInternal(err)
+	}
+	return messages, unsubscribe, nil
+}
+
+func (config StreamConfig) allowOrigin(r *http.Request) bool {
+	origin := r.Header.Get(echo.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	for _, o := range config.AllowOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	if len(config.AllowOrigins) > 0 {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// formatEvent formats data as server-sent event, every line of data as separate data field.
+func formatEvent(data []byte) string {
+	var b strings.Builder
+	b.WriteString("event: message\n")
+	for _, line := range strings.Split(string(data), "\n") {
+		b.WriteString("data: ")
+		b.WriteString(strings.TrimSuffix(line, "\r"))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,184 @@
+package mqttbridge
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestEvents(t *testing.T) {
+	client := newTestClient()
+	e := echo.New()
+	e.GET("/events/*", Events(client))
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events/sensors/%2B/temperature", nil)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get(echo.HeaderContentType))
+	assert.Equal(t, "sensors/+/temperature", <-client.subscribed)
+
+	client.deliver("sensors/+/temperature", Message{Topic: "sensors/1/temperature", Payload: []byte("21.5")})
+	r := bufio.NewReader(res.Body)
+	var lines []string
+	for i := 0; i < 3; i++ {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+	assert.Equal(t, []string{"event: message\n", "data: {\"topic\":\"sensors/1/temperature\",\"payload\":21.5}\n", "\n"}, lines)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		return client.subscriptions() == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestEvents_drain(t *testing.T) {
+	client := newTestClient()
+	e := echo.New()
+	e.GET("/events/*", Events(client))
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/events/sensors")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	<-client.subscribed
+
+	assert.Equal(t, 1, e.CloseLongLived("redeploy"))
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "event: shutdown\ndata: redeploy\n\n", string(body))
+	assert.Eventually(t, func() bool {
+		return client.subscriptions() == 0 && e.DrainStatus().LongLived == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestEvents_subscribeError(t *testing.T) {
+	client := newTestClient()
+	client.err = errBroker
+	e := echo.New()
+	e.GET("/events/*", Events(client))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/sensors", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
+
+func TestWebSocket(t *testing.T) {
+	client := newTestClient()
+	e := echo.New()
+	e.GET("/ws/*", WebSocket(client))
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/sensors/%23"
+	ws, err := websocket.Dial(wsURL, "", server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "sensors/#", <-client.subscribed)
+
+	client.deliver("sensors/#", Message{Topic: "sensors/1/humidity", Payload: []byte("40")})
+	var msg string
+	require.NoError(t, websocket.Message.Receive(ws, &msg))
+	assert.Equal(t, `{"topic":"sensors/1/humidity","payload":40}`, msg)
+
+	ws.Close()
+	assert.Eventually(t, func() bool {
+		return client.subscriptions() == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWebSocket_drain(t *testing.T) {
+	client := newTestClient()
+	e := echo.New()
+	e.GET("/ws/*", WebSocket(client))
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = io.WriteString(conn, "GET /ws/sensors HTTP/1.1\r\nHost: "+server.Listener.Addr().String()+
+		"\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Version: 13\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	require.NoError(t, err)
+	r := bufio.NewReader(conn)
+	res, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	<-client.subscribed
+
+	assert.Equal(t, 1, e.CloseLongLived("redeploy"))
+	header := make([]byte, 2)
+	_, err = io.ReadFull(r, header)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x80|websocket.CloseFrame), header[0])
+	payload := make([]byte, header[1])
+	_, err = io.ReadFull(r, payload)
+	require.NoError(t, err)
+	assert.Equal(t, echo.WebSocketShutdownClose("redeploy"), payload)
+	assert.Eventually(t, func() bool {
+		return client.subscriptions() == 0 && e.DrainStatus().LongLived == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWebSocket_origin(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		config       StreamConfig
+		origin       string
+		expectStatus int
+	}{
+		{name: "other origin", origin: "https://evil.example", expectStatus: http.StatusForbidden},
+		{name: "same host", origin: "https://example.com", expectStatus: http.StatusBadRequest},
+		{name: "no origin", expectStatus: http.StatusBadRequest},
+		{
+			name:         "allowed origin",
+			config:       StreamConfig{AllowOrigins: []string{"https://dashboard.example"}},
+			origin:       "https://dashboard.example",
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "not allowed origin",
+			config:       StreamConfig{AllowOrigins: []string{"https://dashboard.example"}},
+			origin:       "https://example.com",
+			expectStatus: http.StatusForbidden,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.GET("/ws/*", WebSocketWithConfig(newTestClient(), tc.config))
+			server := httptest.NewServer(e)
+			defer server.Close()
+
+			// allowed requests fail websocket handshake (400) as request is not upgrade request
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/ws/sensors", nil)
+			req.Host = "example.com"
+			if tc.origin != "" {
+				req.Header.Set(echo.HeaderOrigin, tc.origin)
+			}
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, tc.expectStatus, res.StatusCode)
+		})
+	}
+}
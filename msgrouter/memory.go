@@ -0,0 +1,95 @@
+package msgrouter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+type (
+	// MemoryBroker is in-process message broker. Messages are delivered synchronously to subscribers with matching
+	// subjects so it is suitable for tests and for decoupling components of single instance.
+	MemoryBroker struct {
+		mutex         sync.RWMutex
+		subscriptions []memorySubscription
+		drained       bool
+	}
+
+	memorySubscription struct {
+		subject string
+		handle  HandleFunc
+	}
+)
+
+var (
+	// ErrBrokerDrained is returned when message is published to drained broker.
+	ErrBrokerDrained = errors.New("msgrouter: broker is drained")
+	// ErrNoSubscribers is returned when no subscriber matches subject of published message.
+	ErrNoSubscribers = errors.New("msgrouter: no subscribers")
+)
+
+var _ Subscriber = (*MemoryBroker)(nil)
+
+// NewMemoryBroker creates new in-process message broker.
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// Subscribe subscribes handle to messages published with the subjects.
+func (b *MemoryBroker) Subscribe(subjects []string, handle HandleFunc) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, subject := range subjects {
+		b.subscriptions = append(b.subscriptions, memorySubscription{subject: subject, handle: handle})
+	}
+	b.drained = false
+	return nil
+}
+
+// Drain stops delivery of published messages.
+func (b *MemoryBroker) Drain(ctx context.Context) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.subscriptions = nil
+	b.drained = true
+	return nil
+}
+
+// Publish delivers message to the first subscription matching its subject and returns the error returned by the
+// subscriber.
+func (b *MemoryBroker) Publish(ctx context.Context, m *Message) error {
+	b.mutex.RLock()
+	if b.drained {
+		b.mutex.RUnlock()
+		return ErrBrokerDrained
+	}
+	var handle HandleFunc
+	for _, s := range b.subscriptions {
+		if matchSubject(s.subject, m.Subject) {
+			handle = s.handle
+			break
+		}
+	}
+	b.mutex.RUnlock()
+
+	if handle == nil {
+		return ErrNoSubscribers
+	}
+	return handle(ctx, m)
+}
+
+// matchSubject reports whether subject matches subscription subject with `*` and `>` wildcards.
+func matchSubject(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	tokens := strings.Split(subject, ".")
+	for i, p := range patternTokens {
+		if p == ">" {
+			return len(tokens) > i
+		}
+		if i >= len(tokens) || p != "*" && p != tokens[i] {
+			return false
+		}
+	}
+	return len(tokens) == len(patternTokens)
+}
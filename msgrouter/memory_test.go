@@ -0,0 +1,48 @@
+package msgrouter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBroker_Publish(t *testing.T) {
+	b := NewMemoryBroker()
+	var got []string
+	err := b.Subscribe([]string{"a.*", "b.>"}, func(ctx context.Context, m *Message) error {
+		got = append(got, m.Subject)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), &Message{Subject: "a.1"}))
+	assert.NoError(t, b.Publish(context.Background(), &Message{Subject: "b.1.2"}))
+	assert.Equal(t, ErrNoSubscribers, b.Publish(context.Background(), &Message{Subject: "a.1.2"}))
+	assert.Equal(t, []string{"a.1", "b.1.2"}, got)
+
+	assert.NoError(t, b.Drain(context.Background()))
+	assert.Equal(t, ErrBrokerDrained, b.Publish(context.Background(), &Message{Subject: "a.1"}))
+}
+
+func TestMatchSubject(t *testing.T) {
+	var testCases = []struct {
+		pattern string
+		subject string
+		expect  bool
+	}{
+		{pattern: "a.b", subject: "a.b", expect: true},
+		{pattern: "a.b", subject: "a.c", expect: false},
+		{pattern: "a.*", subject: "a.b", expect: true},
+		{pattern: "a.*", subject: "a.b.c", expect: false},
+		{pattern: "a.*", subject: "a", expect: false},
+		{pattern: "a.>", subject: "a.b.c", expect: true},
+		{pattern: "a.>", subject: "a", expect: false},
+		{pattern: ">", subject: "a", expect: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.subject, func(t *testing.T) {
+			assert.Equal(t, tc.expect, matchSubject(tc.pattern, tc.subject))
+		})
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Package msgrouter routes messages of message brokers (NATS, AMQP, ...) to Echo handlers. Messages are served the same
+way as requests: subject is matched against routes registered with patterns, handlers get `echo.Context` with subject
+tokens as path params and payload as request body, so `Context#Bind()`, validator and middleware (logger, recover,
+metrics, ...) of Echo are shared by HTTP and message handlers. Consumers are started by `Echo#Start()` and drained by
+`Echo#Shutdown()`.
+
+Brokers are connected through `Subscriber` implemented by a small adapter, i.e. for NATS:
+
+	type natsSubscriber struct {
+		nc   *nats.Conn
+		subs []*nats.Subscription
+	}
+
+	func (s *natsSubscriber) Subscribe(subjects []string, handle msgrouter.HandleFunc) error {
+		for _, subject := range subjects {
+			sub, err := s.nc.QueueSubscribe(subject, "app", func(m *nats.Msg) {
+				handle(context.Background(), &msgrouter.Message{Subject: m.Subject, Header: http.Header(m.Header), Data: m.Data})
+			})
+			if err != nil {
+				return err
+			}
+			s.subs = append(s.subs, sub)
+		}
+		return nil
+	}
+
+	func (s *natsSubscriber) Drain(ctx context.Context) error {
+		return s.nc.Drain()
+	}
+
+AMQP adapter binds a queue to subjects used as routing keys of topic exchange (replacing `>` with `#`), consumes the
+queue and acknowledges deliveries when handle returns nil.
+
+Example:
+
+	e := echo.New()
+	e.Use(middleware.Recover())
+
+	r := msgrouter.New(e, &natsSubscriber{nc: nc})
+	r.Use(middleware.Logger())
+	r.Add("orders.:id.created", func(c echo.Context) error {
+		var order Order
+		if err := c.Bind(&order); err != nil { // binds `id` param and JSON payload
+			return err
+		}
+		return process(c.Request().Context(), order)
+	})
+
+	e.Logger.Fatal(e.Start(":8080"))
+*/
+package msgrouter
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Message is message received from broker.
+	Message struct {
+		// Subject is subject (NATS) or routing key (AMQP) the message was published with. Tokens are separated by
+		// dots and must not contain slashes.
+		Subject string
+		// Header holds message headers. `Content-Type` header selects codec used by `Context#Bind()`.
+		Header http.Header
+		// Data is payload of the message.
+		Data []byte
+	}
+
+	// HandleFunc handles message received by subscriber. Returned error means message was not processed and
+	// broker should redeliver it (i.e. AMQP nack), nil means it can be acknowledged.
+	HandleFunc func(ctx context.Context, m *Message) error
+
+	// Subscriber connects router to message broker.
+	Subscriber interface {
+		// Subscribe starts consuming messages published with the subjects and calls handle for each of them.
+		// Subjects use `*` for single token and `>` for one or more trailing tokens wildcards. Subscribe must not
+		// block.
+		Subscribe(subjects []string, handle HandleFunc) error
+
+		// Drain stops consuming messages and waits until messages being handled are processed or context is done.
+		Drain(ctx context.Context) error
+	}
+
+	// Router routes messages of subscriber to handlers.
+	Router struct {
+		// DefaultContentType is content type of messages without `Content-Type` header.
+		// Default value "application/json".
+		DefaultContentType string
+
+		echo       *echo.Echo
+		router     *echo.Router
+		subscriber Subscriber
+		middleware []echo.MiddlewareFunc
+		subjects   []string
+		inflight   sync.WaitGroup
+	}
+
+	// discardWriter is response writer of message contexts. Messages have no response so body is dropped.
+	discardWriter struct {
+		header http.Header
+	}
+)
+
+// New creates router consuming messages of the subscriber. Subscriber is subscribed to subjects of routes when Echo
+// is started and drained when Echo is shut down.
+func New(e *echo.Echo, s Subscriber) *Router {
+	r := &Router{
+		DefaultContentType: echo.MIMEApplicationJSON,
+		echo:               e,
+		router:             echo.NewRouter(e),
+		subscriber:         s,
+	}
+	e.OnStart(r.Start)
+	e.OnShutdown(r.Shutdown)
+	return r
+}
+
+// Use adds middleware run for every message after route is matched. Middleware of Echo (added with `Echo#Use()`)
+// is not run for messages.
+func (r *Router) Use(middleware ...echo.MiddlewareFunc) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Add registers handler for messages with subjects matching the pattern. Pattern tokens are separated by dots, token
+// `:name` matches single token available as path param `name`, `*` matches single token and last token `>` (or
+// `#`) matches remaining tokens available as path param `*`.
+func (r *Router) Add(pattern string, h echo.HandlerFunc, middleware ...echo.MiddlewareFunc) {
+	path, subject := parsePattern(pattern)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	r.router.Add(http.MethodPost, path, h)
+	r.subjects = append(r.subjects, subject)
+}
+
+// Subjects returns subjects of registered routes in the wildcard syntax of `Subscriber#Subscribe()`.
+func (r *Router) Subjects() []string {
+	return append([]string(nil), r.subjects...)
+}
+
+// Start subscribes the subscriber to subjects of routes. It is called by `Echo#Start()`.
+func (r *Router) Start() error {
+	return r.subscriber.Subscribe(r.Subjects(), r.Handle)
+}
+
+// Shutdown drains the subscriber and waits until messages being handled are processed or context is done. It is
+// called by `Echo#Shutdown()`.
+func (r *Router) Shutdown(ctx context.Context) error {
+	if err := r.subscriber.Drain(ctx); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		r.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Handle serves the message with the handler of matching route. Message is served as POST request to path with
+// subject tokens as segments (`orders.42.created` as `/orders/42/created`). Error is returned when no route matches,
+// handler returns error or error response (status 400 and above) was sent, i.e. by recover middleware.
+func (r *Router) Handle(ctx context.Context, m *Message) error {
+	r.inflight.Add(1)
+	defer r.inflight.Done()
+
+	header := make(http.Header, len(m.Header)+1)
+	for k, v := range m.Header {
+		header[k] = v
+	}
+	if header.Get(echo.HeaderContentType) == "" {
+		header.Set(echo.HeaderContentType, r.DefaultContentType)
+	}
+	path := subjectPath(m.Subject)
+	req := (&http.Request{
+		Method:        http.MethodPost,
+		URL:           &url.URL{Path: path},
+		RequestURI:    path,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(m.Data)),
+		ContentLength: int64(len(m.Data)),
+	}).WithContext(ctx)
+
+	c := r.echo.NewContext(req, &discardWriter{header: http.Header{}})
+	r.router.Find(http.MethodPost, path, c)
+	h := c.Handler()
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	if err := h(c); err != nil {
+		return err
+	}
+	if status := c.Response().Status; status >= http.StatusBadRequest {
+		return echo.NewHTTPError(status)
+	}
+	return nil
+}
+
+// parsePattern returns router path and subscription subject of the pattern.
+func parsePattern(pattern string) (path, subject string) {
+	tokens := strings.Split(pattern, ".")
+	segments := make([]string, len(tokens))
+	for i, token := range tokens {
+		switch {
+		case token == ">" || token == "#":
+			if i != len(tokens)-1 {
+				panic("msgrouter: wildcard " + token + " must be the last token of pattern " + pattern)
+			}
+			segments[i], tokens[i] = "*", ">"
+		case token == "*":
+			segments[i] = ":_" + strconv.Itoa(i)
+		case strings.HasPrefix(token, ":"):
+			segments[i], tokens[i] = token, "*"
+		case token == "" || strings.Contains(token, "/"):
+			panic("msgrouter: invalid token " + strconv.Quote(token) + " of pattern " + pattern)
+		default:
+			segments[i] = token
+		}
+	}
+	return "/" + strings.Join(segments, "/"), strings.Join(tokens, ".")
+}
+
+func subjectPath(subject string) string {
+	return "/" + strings.Replace(subject, ".", "/", -1)
+}
+
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardWriter) WriteHeader(int) {}
@@ -0,0 +1,169 @@
+package msgrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type order struct {
+	ID    int    `param:"id" json:"-"`
+	Items int    `json:"items"`
+	Note  string `json:"note"`
+}
+
+func TestRouter_Handle(t *testing.T) {
+	e := echo.New()
+	broker := NewMemoryBroker()
+	r := New(e, broker)
+
+	var got order
+	var params []string
+	r.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			params = append(params, "router")
+			return next(c)
+		}
+	})
+	r.Add("orders.:id.created", func(c echo.Context) error {
+		return c.Bind(&got)
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			params = append(params, "route")
+			return next(c)
+		}
+	})
+	r.Add("orders.*.shipped", func(c echo.Context) error {
+		params = append(params, c.Param("_1"))
+		return nil
+	})
+	r.Add("audit.>", func(c echo.Context) error {
+		params = append(params, c.Param("*"))
+		return nil
+	})
+	assert.Equal(t, []string{"orders.*.created", "orders.*.shipped", "audit.>"}, r.Subjects())
+	require.NoError(t, r.Start())
+
+	err := broker.Publish(context.Background(), &Message{Subject: "orders.42.created", Data: []byte(`{"items":3,"note":"gift"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, order{ID: 42, Items: 3, Note: "gift"}, got)
+	assert.Equal(t, []string{"router", "route"}, params)
+
+	params = nil
+	require.NoError(t, broker.Publish(context.Background(), &Message{Subject: "orders.7.shipped"}))
+	require.NoError(t, broker.Publish(context.Background(), &Message{Subject: "audit.users.1.deleted"}))
+	assert.Equal(t, []string{"router", "7", "router", "users/1/deleted"}, params)
+}
+
+func TestRouter_HandleErrors(t *testing.T) {
+	e := echo.New()
+	r := New(e, NewMemoryBroker())
+	r.Use(middleware.Recover())
+	r.Add("jobs.fail", func(c echo.Context) error {
+		return errors.New("failed")
+	})
+	r.Add("jobs.panic", func(c echo.Context) error {
+		panic("boom")
+	})
+	r.Add("jobs.bind", func(c echo.Context) error {
+		var o order
+		return c.Bind(&o)
+	})
+
+	var testCases = []struct {
+		name        string
+		message     *Message
+		expectError string
+	}{
+		{
+			name:        "handler error",
+			message:     &Message{Subject: "jobs.fail"},
+			expectError: "failed",
+		},
+		{
+			name:        "panic recovered",
+			message:     &Message{Subject: "jobs.panic"},
+			expectError: "code=500, message=Internal Server Error",
+		},
+		{
+			name:        "unsupported content type",
+			message:     &Message{Subject: "jobs.bind", Header: http.Header{"Content-Type": {"text/csv"}}, Data: []byte("a,b")},
+			expectError: "code=415, message=Unsupported Media Type",
+		},
+		{
+			name:        "no route",
+			message:     &Message{Subject: "jobs.unknown"},
+			expectError: "code=404, message=Not Found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := r.Handle(context.Background(), tc.message)
+			assert.EqualError(t, err, tc.expectError)
+		})
+	}
+}
+
+func TestRouter_Lifecycle(t *testing.T) {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	broker := NewMemoryBroker()
+	r := New(e, broker)
+	handled := make(chan string, 1)
+	r.Add("greetings.:name", func(c echo.Context) error {
+		handled <- c.Param("name")
+		return nil
+	})
+
+	assert.Equal(t, ErrNoSubscribers, broker.Publish(context.Background(), &Message{Subject: "greetings.bob"}))
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.Start("127.0.0.1:0")
+	}()
+	require.Eventually(t, func() bool {
+		return e.ListenerAddr() != nil
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, broker.Publish(context.Background(), &Message{Subject: "greetings.bob"}))
+	assert.Equal(t, "bob", <-handled)
+
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-errs)
+	assert.Equal(t, ErrBrokerDrained, broker.Publish(context.Background(), &Message{Subject: "greetings.bob"}))
+}
+
+func TestParsePattern(t *testing.T) {
+	var testCases = []struct {
+		pattern       string
+		expectPath    string
+		expectSubject string
+	}{
+		{pattern: "orders.created", expectPath: "/orders/created", expectSubject: "orders.created"},
+		{pattern: "orders.:id.*", expectPath: "/orders/:id/:_2", expectSubject: "orders.*.*"},
+		{pattern: "audit.#", expectPath: "/audit/*", expectSubject: "audit.>"},
+		{pattern: ">", expectPath: "/*", expectSubject: ">"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			path, subject := parsePattern(tc.pattern)
+			assert.Equal(t, tc.expectPath, path)
+			assert.Equal(t, tc.expectSubject, subject)
+		})
+	}
+
+	assert.PanicsWithValue(t, "msgrouter: wildcard > must be the last token of pattern a.>.b", func() {
+		parsePattern("a.>.b")
+	})
+	assert.PanicsWithValue(t, `msgrouter: invalid token "" of pattern a..b`, func() {
+		parsePattern("a..b")
+	})
+}
@@ -0,0 +1,175 @@
+package echo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+type (
+	// MultipartPart is part of multipart response sent by `MultipartMixed()`.
+	MultipartPart struct {
+		// Header of the part, i.e. `Content-Type` and `Content-ID`.
+		Header http.Header
+
+		// Body of the part.
+		Body io.Reader
+	}
+
+	// ByteRange is range of bytes of content. See `ParseRange()`.
+	ByteRange struct {
+		// Start is offset of the first byte of the range.
+		Start int64
+		// Length is number of bytes of the range.
+		Length int64
+	}
+)
+
+// ErrRangeNotSatisfiable denotes an error raised when none of ranges requested with `Range` header overlaps the
+// content.
+var ErrRangeNotSatisfiable = NewHTTPError(http.StatusRequestedRangeNotSatisfiable)
+
+// ParseRange parses `Range` request header (RFC 7233) for content of given size. Ranges not overlapping content are
+// skipped, ErrRangeNotSatisfiable is returned when no range overlaps content. Empty header returns nil ranges.
+func ParseRange(header string, size int64) ([]ByteRange, error) {
+	if header == "" {
+		return nil, nil
+	}
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, errors.New("invalid range unit")
+	}
+	var ranges []ByteRange
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		i := strings.IndexByte(spec, '-')
+		if i < 0 {
+			return nil, errors.New("invalid range")
+		}
+		first, last := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+		var r ByteRange
+		if first == "" {
+			// suffix range `-500` is last 500 bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = ByteRange{Start: size - n, Length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if start >= size {
+				continue
+			}
+			end := size - 1
+			if last != "" {
+				if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+					return nil, errors.New("invalid range")
+				}
+				if end >= size {
+					end = size - 1
+				}
+			}
+			r = ByteRange{Start: start, Length: end - start + 1}
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, ErrRangeNotSatisfiable
+	}
+	return ranges, nil
+}
+
+// ContentRange returns value of `Content-Range` header of the range of content with given size.
+func (r ByteRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.Start+r.Length-1, size)
+}
+
+// MultipartMixed sends a `multipart/mixed` response (i.e. response of batch request) with status code. Every
+// part is sent with its own headers.
+func MultipartMixed(c Context, code int, parts []*MultipartPart) (err error) {
+	defer trackRender(c)()
+	mw := multipart.NewWriter(c.Response())
+	c.Response().Header().Set(HeaderContentType, "multipart/mixed; boundary="+mw.Boundary())
+	c.Response().WriteHeader(code)
+	for _, p := range parts {
+		w, err := mw.CreatePart(textproto.MIMEHeader(p.Header))
+		if err != nil {
+			return err
+		}
+		if p.Body != nil {
+			if _, err = io.Copy(w, p.Body); err != nil {
+				return err
+			}
+		}
+	}
+	return mw.Close()
+}
+
+// MultipartByteRanges sends ranges of the content of given size (see `ParseRange()`) with status code 206.
+// Multiple ranges are sent as `multipart/byteranges` response, single range as plain partial content and no
+// ranges as whole content with status code 200. Invalid ranges are answered with ErrRangeNotSatisfiable.
+func MultipartByteRanges(c Context, contentType string, content io.ReaderAt, size int64, ranges []ByteRange) (err error) {
+	defer trackRender(c)()
+	header := c.Response().Header()
+	header.Set(HeaderAcceptRanges, "bytes")
+	for _, r := range ranges {
+		if r.Start < 0 || r.Length <= 0 || r.Start+r.Length > size {
+			header.Set(HeaderContentRange, "bytes */"+strconv.FormatInt(size, 10))
+			return ErrRangeNotSatisfiable
+		}
+	}
+
+	switch len(ranges) {
+	case 0:
+		header.Set(HeaderContentType, contentType)
+		header.Set(HeaderContentLength, strconv.FormatInt(size, 10))
+		c.Response().WriteHeader(http.StatusOK)
+		_, err = io.Copy(c.Response(), io.NewSectionReader(content, 0, size))
+		return
+	case 1:
+		// single range is sent without multipart envelope (RFC 7233 section 4.1)
+		r := ranges[0]
+		header.Set(HeaderContentType, contentType)
+		header.Set(HeaderContentRange, r.ContentRange(size))
+		header.Set(HeaderContentLength, strconv.FormatInt(r.Length, 10))
+		c.Response().WriteHeader(http.StatusPartialContent)
+		_, err = io.Copy(c.Response(), io.NewSectionReader(content, r.Start, r.Length))
+		return
+	}
+
+	mw := multipart.NewWriter(c.Response())
+	header.Set(HeaderContentType, "multipart/byteranges; boundary="+mw.Boundary())
+	c.Response().WriteHeader(http.StatusPartialContent)
+	for _, r := range ranges {
+		partHeader := textproto.MIMEHeader{}
+		if contentType != "" {
+			partHeader.Set(HeaderContentType, contentType)
+		}
+		partHeader.Set(HeaderContentRange, r.ContentRange(size))
+		w, err := mw.CreatePart(partHeader)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(w, io.NewSectionReader(content, r.Start, r.Length)); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
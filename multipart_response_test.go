@@ -0,0 +1,146 @@
+package echo
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRange(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		whenHeader  string
+		expect      []ByteRange
+		expectError string
+	}{
+		{name: "no header"},
+		{name: "single range", whenHeader: "bytes=0-9", expect: []ByteRange{{Start: 0, Length: 10}}},
+		{name: "open range", whenHeader: "bytes=90-", expect: []ByteRange{{Start: 90, Length: 10}}},
+		{name: "suffix range", whenHeader: "bytes=-5", expect: []ByteRange{{Start: 95, Length: 5}}},
+		{name: "suffix larger than size", whenHeader: "bytes=-500", expect: []ByteRange{{Start: 0, Length: 100}}},
+		{name: "end beyond size", whenHeader: "bytes=95-200", expect: []ByteRange{{Start: 95, Length: 5}}},
+		{
+			name:       "multiple ranges",
+			whenHeader: "bytes=0-0, 10-19,200-300",
+			expect:     []ByteRange{{Start: 0, Length: 1}, {Start: 10, Length: 10}},
+		},
+		{name: "not satisfiable", whenHeader: "bytes=100-", expectError: "code=416, message=Requested Range Not Satisfiable"},
+		{name: "invalid unit", whenHeader: "items=0-1", expectError: "invalid range unit"},
+		{name: "invalid range", whenHeader: "bytes=5-1", expectError: "invalid range"},
+		{name: "invalid number", whenHeader: "bytes=a-1", expectError: "invalid range"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ranges, err := ParseRange(tc.whenHeader, 100)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, ranges)
+		})
+	}
+}
+
+func TestContext_MultipartMixed(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/batch", nil), rec)
+
+	err := MultipartMixed(c, http.StatusOK, []*MultipartPart{
+		{Header: http.Header{HeaderContentType: {MIMEApplicationJSON}, "Content-Id": {"1"}}, Body: strings.NewReader(`{"id":1}`)},
+		{Header: http.Header{HeaderContentType: {MIMETextPlain}}, Body: strings.NewReader("second")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get(HeaderContentType))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	mr := multipart.NewReader(rec.Body, params["boundary"])
+	p, err := mr.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, MIMEApplicationJSON, p.Header.Get(HeaderContentType))
+	assert.Equal(t, "1", p.Header.Get("Content-Id"))
+	b, _ := ioutil.ReadAll(p)
+	assert.Equal(t, `{"id":1}`, string(b))
+	p, err = mr.NextPart()
+	require.NoError(t, err)
+	b, _ = ioutil.ReadAll(p)
+	assert.Equal(t, "second", string(b))
+	_, err = mr.NextPart()
+	assert.Error(t, err)
+}
+
+func TestContext_MultipartByteRanges(t *testing.T) {
+	content := strings.NewReader("0123456789abcdefghij")
+	size := int64(content.Len())
+
+	t.Run("multiple ranges", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+		err := MultipartByteRanges(c, MIMETextPlain, content, size, []ByteRange{{Start: 0, Length: 3}, {Start: 10, Length: 5}})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		mediaType, params, err := mime.ParseMediaType(rec.Header().Get(HeaderContentType))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/byteranges", mediaType)
+
+		mr := multipart.NewReader(rec.Body, params["boundary"])
+		for _, expect := range []struct{ contentRange, body string }{
+			{"bytes 0-2/20", "012"},
+			{"bytes 10-14/20", "abcde"},
+		} {
+			p, err := mr.NextPart()
+			require.NoError(t, err)
+			assert.Equal(t, MIMETextPlain, p.Header.Get(HeaderContentType))
+			assert.Equal(t, expect.contentRange, p.Header.Get(HeaderContentRange))
+			b, _ := ioutil.ReadAll(p)
+			assert.Equal(t, expect.body, string(b))
+		}
+	})
+
+	t.Run("single range", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+		require.NoError(t, MultipartByteRanges(c, MIMETextPlain, content, size, []ByteRange{{Start: 18, Length: 2}}))
+
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, MIMETextPlain, rec.Header().Get(HeaderContentType))
+		assert.Equal(t, "bytes 18-19/20", rec.Header().Get(HeaderContentRange))
+		assert.Equal(t, "ij", rec.Body.String())
+	})
+
+	t.Run("no ranges", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+		require.NoError(t, MultipartByteRanges(c, MIMETextPlain, content, size, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "bytes", rec.Header().Get(HeaderAcceptRanges))
+		assert.Equal(t, "0123456789abcdefghij", rec.Body.String())
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+		err := MultipartByteRanges(c, MIMETextPlain, content, size, []ByteRange{{Start: 15, Length: 10}})
+
+		assert.Equal(t, ErrRangeNotSatisfiable, err)
+		assert.Equal(t, "bytes */20", rec.Header().Get(HeaderContentRange))
+	})
+}
@@ -0,0 +1,234 @@
+package echo
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// variant is representation selected for request header by content negotiation.
+	variant struct {
+		header string
+		value  string
+	}
+
+	// acceptRange is single element of `Accept*` header list.
+	acceptRange struct {
+		value string
+		q     float64
+	}
+)
+
+// NegotiateType returns the offered media type best matching the `Accept` request header, first offer when header
+// is not sent or empty string when no offer is acceptable. `Accept` is added to the `Vary` response header and
+// selected media type is used by `CacheKey()`.
+func NegotiateType(c Context, offers ...string) string {
+	return negotiate(c, HeaderAccept, offers, matchMediaRange)
+}
+
+// NegotiateEncoding returns the offered content coding best matching the `Accept-Encoding` request header. See
+// `NegotiateType()`.
+func NegotiateEncoding(c Context, offers ...string) string {
+	return negotiate(c, HeaderAcceptEncoding, offers, matchToken)
+}
+
+// NegotiateLanguage returns the offered language tag best matching the `Accept-Language` request header. See
+// `NegotiateType()`.
+func NegotiateLanguage(c Context, offers ...string) string {
+	return negotiate(c, HeaderAcceptLanguage, offers, matchLanguageRange)
+}
+
+// negotiate selects the best offer for request header and records selection for `Vary` header and cache key.
+func negotiate(c Context, header string, offers []string, match func(rng, offer string) int) string {
+	addVary(c.Response().Header(), header)
+
+	selected := ""
+	if values := c.Request().Header.Values(header); len(values) == 0 {
+		if len(offers) > 0 {
+			selected = offers[0]
+		}
+	} else {
+		selected = bestOffer(parseAccept(strings.Join(values, ",")), offers, match)
+	}
+
+	if cc := contextOf(c); cc != nil {
+		cc.setVariant(header, selected)
+	}
+	return selected
+}
+
+func (c *context) setVariant(header, value string) {
+	for i := range c.variants {
+		if c.variants[i].header == header {
+			c.variants[i].value = value
+			return
+		}
+	}
+	c.variants = append(c.variants, variant{header: header, value: value})
+}
+
+// CacheKey returns canonical key of the response representation for response caches. Key consists of request method,
+// host, path, sorted query and values of all request headers listed in `Vary` response header, using representation
+// selected by `Negotiate*` functions instead of raw header values. Empty string is returned when response varies by
+// `*` and must not be cached.
+func CacheKey(c Context) string {
+	vary := []string{}
+	for _, v := range c.Response().Header().Values(HeaderVary) {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return ""
+			}
+			if name != "" {
+				vary = append(vary, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(vary)
+
+	r := c.Request()
+	b := new(strings.Builder)
+	b.WriteString(r.Method)
+	b.WriteByte(' ')
+	b.WriteString(strings.ToLower(r.Host))
+	b.WriteString(r.URL.EscapedPath())
+	if q := c.QueryParams(); len(q) > 0 {
+		b.WriteByte('?')
+		b.WriteString(q.Encode())
+	}
+	cc := contextOf(c)
+	for i, name := range vary {
+		if i > 0 && vary[i-1] == name {
+			continue
+		}
+		b.WriteByte('|')
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(variantValue(cc, r, name))
+	}
+	return b.String()
+}
+
+// variantValue returns representation selected for the header or normalized request header value when header was
+// not negotiated.
+func variantValue(c *context, r *http.Request, header string) string {
+	if c != nil {
+		for _, v := range c.variants {
+			if v.header == header {
+				return v.value
+			}
+		}
+	}
+	values := r.Header.Values(header)
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				parts = append(parts, p)
+			}
+		}
+	}
+	return strings.ToLower(strings.Join(parts, ","))
+}
+
+// addVary adds header name to `Vary` response header unless it is already listed.
+func addVary(h http.Header, name string) {
+	for _, v := range h.Values(HeaderVary) {
+		for _, n := range strings.Split(v, ",") {
+			if n = strings.TrimSpace(n); n == "*" || strings.EqualFold(n, name) {
+				return
+			}
+		}
+	}
+	h.Add(HeaderVary, name)
+}
+
+// parseAccept parses `Accept*` header value into ranges with their quality values.
+func parseAccept(header string) []acceptRange {
+	ranges := []acceptRange{}
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if len(p) > 2 && (p[0] == 'q' || p[0] == 'Q') && p[1] == '=' {
+				if f, err := strconv.ParseFloat(p[2:], 64); err == nil && f >= 0 && f <= 1 {
+					q = f
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{value: value, q: q})
+	}
+	return ranges
+}
+
+// bestOffer returns offer with the highest quality value of its most specific matching range. Ties are resolved by
+// order of offers. Offers matching only ranges with quality 0 are not acceptable.
+func bestOffer(ranges []acceptRange, offers []string, match func(rng, offer string) int) string {
+	best := ""
+	bestQ := 0.0
+	for _, offer := range offers {
+		o := strings.ToLower(offer)
+		specificity := -1
+		q := 0.0
+		for _, r := range ranges {
+			if s := match(r.value, o); s > specificity {
+				specificity = s
+				q = r.q
+			}
+		}
+		if specificity >= 0 && q > bestQ {
+			best = offer
+			bestQ = q
+		}
+	}
+	return best
+}
+
+// matchMediaRange returns specificity of media range (`*/*`, `text/*`, `text/html`) matching media type or -1.
+func matchMediaRange(rng, offer string) int {
+	if i := strings.IndexByte(offer, ';'); i != -1 {
+		offer = strings.TrimSpace(offer[:i])
+	}
+	switch {
+	case rng == offer:
+		return 2
+	case rng == "*/*":
+		return 0
+	case strings.HasSuffix(rng, "/*") && strings.HasPrefix(offer, rng[:len(rng)-1]):
+		return 1
+	}
+	return -1
+}
+
+// matchToken returns specificity of token (i.e. content coding) or `*` matching offer or -1.
+func matchToken(rng, offer string) int {
+	switch rng {
+	case offer:
+		return 1
+	case "*":
+		return 0
+	}
+	return -1
+}
+
+// matchLanguageRange returns specificity of language range matching language tag or -1. Range matches tags it is
+// prefix of (range `en` matches `en-US`) and, with lower specificity, tags which are its prefix (range `de-CH`
+// matches `de`) as in lookup of RFC 4647.
+func matchLanguageRange(rng, offer string) int {
+	switch {
+	case rng == "*":
+		return 0
+	case rng == offer || strings.HasPrefix(offer, rng+"-"):
+		return strings.Count(rng, "-") + 2
+	case strings.HasPrefix(rng, offer+"-"):
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,118 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Negotiate(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		header    string
+		value     string
+		negotiate func(c Context) string
+		expect    string
+	}{
+		{
+			name:      "type, no header selects first offer",
+			header:    HeaderAccept,
+			negotiate: func(c Context) string { return NegotiateType(c, MIMEApplicationJSON, MIMEApplicationXML) },
+			expect:    MIMEApplicationJSON,
+		},
+		{
+			name:      "type, highest quality wins",
+			header:    HeaderAccept,
+			value:     "application/json;q=0.5, application/xml",
+			negotiate: func(c Context) string { return NegotiateType(c, MIMEApplicationJSON, MIMEApplicationXML) },
+			expect:    MIMEApplicationXML,
+		},
+		{
+			name:      "type, most specific range is used",
+			header:    HeaderAccept,
+			value:     "text/*;q=0.9, text/plain;q=0.1, */*;q=0.2",
+			negotiate: func(c Context) string { return NegotiateType(c, MIMETextPlain, MIMETextHTML, MIMEApplicationJSON) },
+			expect:    MIMETextHTML,
+		},
+		{
+			name:      "type, nothing acceptable",
+			header:    HeaderAccept,
+			value:     "image/png, */*;q=0",
+			negotiate: func(c Context) string { return NegotiateType(c, MIMEApplicationJSON) },
+			expect:    "",
+		},
+		{
+			name:      "encoding, wildcard",
+			header:    HeaderAcceptEncoding,
+			value:     "br;q=0.1, *",
+			negotiate: func(c Context) string { return NegotiateEncoding(c, "br", "GZIP") },
+			expect:    "GZIP",
+		},
+		{
+			name:      "language, range matches subtags",
+			header:    HeaderAcceptLanguage,
+			value:     "fr-CH, en;q=0.8",
+			negotiate: func(c Context) string { return NegotiateLanguage(c, "de", "en-US") },
+			expect:    "en-US",
+		},
+		{
+			name:      "language, tag matching truncated range",
+			header:    HeaderAcceptLanguage,
+			value:     "de-CH, en;q=0.8",
+			negotiate: func(c Context) string { return NegotiateLanguage(c, "en", "de") },
+			expect:    "de",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.value != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			assert.Equal(t, tc.expect, tc.negotiate(c))
+			tc.negotiate(c)
+			assert.Equal(t, []string{tc.header}, rec.Header().Values(HeaderVary))
+		})
+	}
+}
+
+func TestContext_CacheKey(t *testing.T) {
+	e := New()
+	key := func(header http.Header, handler func(c Context)) string {
+		req := httptest.NewRequest(http.MethodGet, "http://Example.com/users?b=2&a=1", nil)
+		req.Header = header
+		c := e.NewContext(req, httptest.NewRecorder())
+		handler(c)
+		return CacheKey(c)
+	}
+	negotiate := func(c Context) {
+		NegotiateType(c, MIMEApplicationJSON, MIMEApplicationXML)
+		NegotiateLanguage(c, "en", "de")
+		c.Response().Header().Add(HeaderVary, "origin")
+	}
+
+	assert.Equal(t, "GET example.com/users?a=1&b=2", key(http.Header{}, func(c Context) {}))
+	assert.Equal(t,
+		"GET example.com/users?a=1&b=2|Accept=application/json|Accept-Language=de|Origin=https://a.example.com",
+		key(http.Header{
+			HeaderAccept:         {"application/json"},
+			HeaderAcceptLanguage: {"de-DE"},
+			HeaderOrigin:         {"https://A.example.com"},
+		}, negotiate),
+	)
+	// different headers selecting the same representation share the cache key
+	assert.Equal(t,
+		key(http.Header{HeaderAccept: {"*/*"}}, negotiate),
+		key(http.Header{HeaderAccept: {"application/json, text/html;q=0.5"}}, negotiate),
+	)
+	assert.Equal(t, "", key(http.Header{}, func(c Context) {
+		c.Response().Header().Set(HeaderVary, "*")
+	}))
+}
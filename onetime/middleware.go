@@ -0,0 +1,105 @@
+package onetime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type (
+	// MiddlewareConfig defines the config for one-time token verification middleware.
+	MiddlewareConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper middleware.Skipper
+
+		// Purpose is purpose tokens must be issued for.
+		// Required.
+		Purpose string
+
+		// TokenLookup is a string in the form of "<source>:<name>" that is used to extract token from the request.
+		// Possible values:
+		// - "query:<name>"
+		// - "form:<name>"
+		// - "header:<name>"
+		// Optional. Default value "query:token".
+		TokenLookup string
+
+		// ContextKey is the key under which verified `*Token` is stored in the context.
+		// Optional. Default value "onetime".
+		ContextKey string
+
+		// ErrorHandler is called when token is missing, invalid or expired. Return value is returned by the
+		// middleware.
+		// Optional. Default value returns the error.
+		ErrorHandler func(c echo.Context, err error) error
+	}
+)
+
+// DefaultMiddlewareConfig is the default one-time token verification middleware config.
+var DefaultMiddlewareConfig = MiddlewareConfig{
+	Skipper:     middleware.DefaultSkipper,
+	TokenLookup: "query:token",
+	ContextKey:  "onetime",
+}
+
+// Middleware returns a middleware which consumes token from the request and stores verified token in the context.
+// Requests without valid token are rejected with 401. See `FromContext()`.
+func (m *Manager) Middleware(config MiddlewareConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Purpose == "" {
+		panic("echo: onetime middleware requires purpose")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultMiddlewareConfig.Skipper
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = DefaultMiddlewareConfig.TokenLookup
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultMiddlewareConfig.ContextKey
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c echo.Context, err error) error {
+			return err
+		}
+	}
+
+	parts := strings.SplitN(config.TokenLookup, ":", 2)
+	if len(parts) != 2 {
+		panic(fmt.Sprintf("echo: invalid onetime token lookup %q", config.TokenLookup))
+	}
+	var extract func(c echo.Context) string
+	switch parts[0] {
+	case "query":
+		extract = func(c echo.Context) string { return c.QueryParam(parts[1]) }
+	case "form":
+		extract = func(c echo.Context) string { return c.FormValue(parts[1]) }
+	case "header":
+		extract = func(c echo.Context) string { return c.Request().Header.Get(parts[1]) }
+	default:
+		panic(fmt.Sprintf("echo: invalid onetime token lookup source %q", parts[0]))
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			token, err := m.Verify(c, extract(c), config.Purpose)
+			if err != nil {
+				return config.ErrorHandler(c, err)
+			}
+			c.Set(config.ContextKey, token)
+			return next(c)
+		}
+	}
+}
+
+// FromContext returns token verified by middleware stored under default context key or nil.
+func FromContext(c echo.Context) *Token {
+	t, _ := c.Get(DefaultMiddlewareConfig.ContextKey).(*Token)
+	return t
+}
@@ -0,0 +1,183 @@
+/*
+Package onetime issues single-use, expiring tokens bound to a purpose and a subject. Tokens are used for flows like
+passwordless login (magic links), email confirmation or password reset where possession of the link proves
+ownership of the address it was sent to.
+
+Example:
+
+	tokens := onetime.New(onetime.Config{TTL: 15 * time.Minute})
+
+	e.POST("/login", func(c echo.Context) error {
+		token, err := tokens.Issue(c, "login", c.FormValue("email"))
+		if err != nil {
+			return err
+		}
+		return sendMail(c.FormValue("email"), "https://example.com/login/verify?token="+token)
+	})
+
+	e.GET("/login/verify", func(c echo.Context) error {
+		t := onetime.FromContext(c)
+		return startSession(c, t.Subject)
+	}, tokens.Middleware(onetime.MiddlewareConfig{Purpose: "login"}))
+*/
+package onetime
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Config defines the config for one-time token Manager.
+	Config struct {
+		// Store keeps issued tokens until they are used or expire.
+		// Optional. Default value NewMemoryStore().
+		Store Store
+
+		// TTL is how long issued token is valid.
+		// Optional. Default value 15 minutes.
+		TTL time.Duration
+
+		// Length is number of random bytes in token. Token string is base64 (URL encoding) of these bytes.
+		// Optional. Default value 32.
+		Length int
+
+		// Clock is source of current time used to set and check expiration.
+		// Optional. Default value `Echo#Clock` of the request.
+		Clock echo.Clock
+	}
+
+	// Token is issued one-time token. Token secret is never stored, only its hash is used as `ID`.
+	Token struct {
+		// ID is hash of token secret and purpose identifying token in store.
+		ID string `json:"id"`
+		// Purpose is action token was issued for, i.e. "login" or "confirm-email". Token verified for different
+		// purpose is rejected.
+		Purpose string `json:"purpose"`
+		// Subject is identity token was issued to, i.e. user ID or email address.
+		Subject string `json:"subject"`
+		// Data is additional data stored with token, i.e. redirect URL after login.
+		Data map[string]string `json:"data,omitempty"`
+		// IssuedAt is time when token was issued.
+		IssuedAt time.Time `json:"issued_at"`
+		// ExpiresAt is time after which token is no longer valid.
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	// Store is storage of issued tokens.
+	Store interface {
+		// Save stores token until it is consumed or expires.
+		Save(ctx context.Context, token *Token) error
+		// Consume removes token with given ID from store and returns it. It returns ErrTokenNotFound when token
+		// does not exist (or was already consumed). Implementations must guarantee that token is returned at most
+		// once even with concurrent calls.
+		Consume(ctx context.Context, id string) (*Token, error)
+	}
+
+	// Manager issues and verifies one-time tokens.
+	Manager struct {
+		config Config
+	}
+)
+
+var (
+	// ErrTokenNotFound is returned by store when token does not exist.
+	ErrTokenNotFound = errors.New("onetime: token not found")
+
+	// ErrTokenInvalid is returned when token does not exist, was already used or was issued for different purpose.
+	ErrTokenInvalid = echo.NewHTTPError(http.StatusUnauthorized, "invalid or used token")
+
+	// ErrTokenExpired is returned when token expired.
+	ErrTokenExpired = echo.NewHTTPError(http.StatusUnauthorized, "token expired")
+)
+
+// DefaultConfig is the default one-time token Manager config.
+var DefaultConfig = Config{
+	TTL:    15 * time.Minute,
+	Length: 32,
+}
+
+// New creates new one-time token Manager with config.
+func New(config Config) *Manager {
+	// Defaults
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfig.TTL
+	}
+	if config.Length <= 0 {
+		config.Length = DefaultConfig.Length
+	}
+	return &Manager{config: config}
+}
+
+// Issue creates new token for purpose and subject and returns its secret to be sent to the user.
+func (m *Manager) Issue(c echo.Context, purpose, subject string) (string, error) {
+	return m.IssueWithData(c, purpose, subject, nil)
+}
+
+// IssueWithData creates new token for purpose and subject with additional data and returns its secret to be sent
+// to the user.
+func (m *Manager) IssueWithData(c echo.Context, purpose, subject string, data map[string]string) (string, error) {
+	b := make([]byte, m.config.Length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	now := m.now(c)
+	token := &Token{
+		ID:        tokenID(purpose, secret),
+		Purpose:   purpose,
+		Subject:   subject,
+		Data:      data,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(m.config.TTL),
+	}
+	if err := m.config.Store.Save(c.Request().Context(), token); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Verify consumes token secret issued for purpose and returns the token. Token can be verified only once.
+func (m *Manager) Verify(c echo.Context, secret, purpose string) (*Token, error) {
+	if secret == "" {
+		return nil, ErrTokenInvalid
+	}
+	token, err := m.config.Store.Consume(c.Request().Context(), tokenID(purpose, secret))
+	if errors.Is(err, ErrTokenNotFound) {
+		return nil, ErrTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !m.now(c).Before(token.ExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+	return token, nil
+}
+
+// tokenID returns ID of token in store. Purpose is part of the hash so token can not be consumed (burned) by
+// verifying it for different purpose.
+func tokenID(purpose, secret string) string {
+	h := sha256.Sum256([]byte(purpose + "\x00" + secret))
+	return hex.EncodeToString(h[:])
+}
+
+// now returns current time from Config.Clock or clock of Echo instance handling the request.
+func (m *Manager) now(c echo.Context) time.Time {
+	if m.config.Clock != nil {
+		return m.config.Clock.Now()
+	}
+	return echo.Now(c)
+}
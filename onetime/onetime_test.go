@@ -0,0 +1,203 @@
+package onetime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestContext() echo.Context {
+	return echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+}
+
+func TestManager(t *testing.T) {
+	c := newTestContext()
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := New(Config{TTL: time.Minute, Clock: clock})
+
+	secret, err := m.IssueWithData(c, "login", "jon@example.com", map[string]string{"redirect": "/home"})
+	require.NoError(t, err)
+	assert.Len(t, secret, 43)
+
+	// verifying for different purpose does not consume the token
+	_, err = m.Verify(c, secret, "reset-password")
+	assert.Equal(t, ErrTokenInvalid, err)
+
+	token, err := m.Verify(c, secret, "login")
+	require.NoError(t, err)
+	assert.Equal(t, "jon@example.com", token.Subject)
+	assert.Equal(t, "/home", token.Data["redirect"])
+	assert.Equal(t, clock.Now().Add(time.Minute), token.ExpiresAt)
+	assert.NotContains(t, token.ID, secret)
+
+	_, err = m.Verify(c, secret, "login")
+	assert.Equal(t, ErrTokenInvalid, err, "token can be used only once")
+
+	secret, err = m.Issue(c, "login", "jon@example.com")
+	require.NoError(t, err)
+	clock.Advance(time.Minute)
+	_, err = m.Verify(c, secret, "login")
+	assert.Equal(t, ErrTokenExpired, err)
+
+	_, err = m.Verify(c, "", "login")
+	assert.Equal(t, ErrTokenInvalid, err)
+}
+
+func TestManager_concurrentVerify(t *testing.T) {
+	c := newTestContext()
+	m := New(Config{})
+	secret, err := m.Issue(c, "confirm-email", "1")
+	require.NoError(t, err)
+
+	var verified int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Verify(c, secret, "confirm-email"); err == nil {
+				atomic.AddInt32(&verified, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), verified)
+}
+
+func TestMemoryStore_removesExpired(t *testing.T) {
+	c := newTestContext()
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := NewMemoryStore()
+	m := New(Config{Store: store, TTL: time.Minute, Clock: clock})
+
+	issue := func(after time.Duration) {
+		clock.Advance(after)
+		_, err := m.Issue(c, "login", "jon")
+		require.NoError(t, err)
+	}
+	issue(0)
+	issue(30 * time.Second)
+	issue(35 * time.Second)
+	assert.Len(t, store.tokens, 2)
+
+	issue(30 * time.Second)
+	assert.Len(t, store.tokens, 3, "expired token is kept until next sweep")
+
+	issue(35 * time.Second)
+	assert.Len(t, store.tokens, 2)
+}
+
+func TestManager_usesEchoClock(t *testing.T) {
+	m := New(Config{TTL: time.Minute})
+	e := echo.New()
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e.Clock = clock
+	e.GET("/verify", func(c echo.Context) error {
+		return c.String(http.StatusOK, FromContext(c).Subject)
+	}, m.Middleware(MiddlewareConfig{Purpose: "login"}))
+
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/login", nil), httptest.NewRecorder())
+	secret, err := m.Issue(c, "login", "jon")
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/verify?token="+secret, nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "token issued and verified with the same clock")
+
+	secret, err = m.Issue(c, "login", "jon")
+	require.NoError(t, err)
+	clock.Advance(time.Minute)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/verify?token="+secret, nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "token expired")
+}
+
+func TestManager_Middleware(t *testing.T) {
+	c := newTestContext()
+	m := New(Config{})
+	e := echo.New()
+	e.GET("/verify", func(c echo.Context) error {
+		return c.String(http.StatusOK, FromContext(c).Subject)
+	}, m.Middleware(MiddlewareConfig{Purpose: "login"}))
+	e.POST("/confirm", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Get("token").(*Token).Subject)
+	}, m.Middleware(MiddlewareConfig{Purpose: "confirm-email", TokenLookup: "header:X-Token", ContextKey: "token"}))
+
+	login, err := m.Issue(c, "login", "jon")
+	require.NoError(t, err)
+	confirm, err := m.Issue(c, "confirm-email", "jon@example.com")
+	require.NoError(t, err)
+
+	var testCases = []struct {
+		name       string
+		method     string
+		target     string
+		header     string
+		expectCode int
+		expectBody string
+	}{
+		{
+			name:       "ok, query token",
+			method:     http.MethodGet,
+			target:     "/verify?token=" + login,
+			expectCode: http.StatusOK,
+			expectBody: "jon",
+		},
+		{
+			name:       "nok, token already used",
+			method:     http.MethodGet,
+			target:     "/verify?token=" + login,
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "nok, token for different purpose",
+			method:     http.MethodGet,
+			target:     "/verify?token=" + confirm,
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "ok, header token",
+			method:     http.MethodPost,
+			target:     "/confirm",
+			header:     confirm,
+			expectCode: http.StatusOK,
+			expectBody: "jon@example.com",
+		},
+		{
+			name:       "nok, missing token",
+			method:     http.MethodGet,
+			target:     "/verify",
+			expectCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, nil)
+			if tc.header != "" {
+				req.Header.Set("X-Token", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectBody != "" {
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestManager_MiddlewarePanics(t *testing.T) {
+	m := New(Config{})
+	assert.Panics(t, func() { m.Middleware(MiddlewareConfig{}) })
+	assert.Panics(t, func() { m.Middleware(MiddlewareConfig{Purpose: "login", TokenLookup: "cookie:token"}) })
+}
@@ -0,0 +1,50 @@
+package onetime
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is in-memory token store. Tokens are lost on restart and are not shared between instances so it is
+// suitable for single instance deployments and tests.
+type MemoryStore struct {
+	mutex       sync.Mutex
+	tokens      map[string]*Token
+	lastCleanup time.Time
+}
+
+// NewMemoryStore creates new in-memory token store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: map[string]*Token{}}
+}
+
+// Save stores token. Tokens expired before the saved token was issued are removed from store at most once per
+// lifetime of the saved token.
+func (s *MemoryStore) Save(ctx context.Context, token *Token) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := token.IssuedAt
+	if now.Sub(s.lastCleanup) >= token.ExpiresAt.Sub(now) || now.Before(s.lastCleanup) {
+		for id, t := range s.tokens {
+			if !now.Before(t.ExpiresAt) {
+				delete(s.tokens, id)
+			}
+		}
+		s.lastCleanup = now
+	}
+	s.tokens[token.ID] = token
+	return nil
+}
+
+// Consume removes token from store and returns it.
+func (s *MemoryStore) Consume(ctx context.Context, id string) (*Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	t, ok := s.tokens[id]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	delete(s.tokens, id)
+	return t, nil
+}
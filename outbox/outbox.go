@@ -0,0 +1,290 @@
+/*
+Package outbox implements transactional outbox for Echo handlers. Events added during request are inserted into
+outbox table in the database transaction of the request (started by `middleware.Tx`), so they are stored only when
+changes made by the handler are committed. Dispatcher publishes stored events asynchronously: right after the
+transaction is committed and periodically (events of crashed instances, failed attempts). Failed events are retried
+with backoff and, after `Config.MaxAttempts` attempts, moved to dead letters (marked in the table and passed to
+`Config.OnDeadLetter`). Events are delivered at least once, consumers should be idempotent (i.e. by `Event.ID`).
+
+Example:
+
+	o := outbox.New(e, outbox.Config{
+		Store: outbox.NewSQLStore(db),
+		Publish: func(ctx context.Context, ev outbox.Event) error {
+			return nc.Publish(ev.Type, ev.Payload)
+		},
+	})
+
+	e.Use(middleware.Tx(db))
+	e.POST("/orders", func(c echo.Context) error {
+		order, err := createOrder(c, echo.TxFrom(c))
+		if err != nil {
+			return err
+		}
+		if err := o.Add(c, "orders.created", order); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, order)
+	})
+*/
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type (
+	// Event is domain event stored in outbox.
+	Event struct {
+		// ID is ID of the event assigned by the store.
+		ID int64
+		// Type is type of the event, i.e. subject or topic it is published to.
+		Type string
+		// Payload is payload of the event.
+		Payload []byte
+		// Attempts is number of failed publish attempts.
+		Attempts int
+		// CreatedAt is time the event was added.
+		CreatedAt time.Time
+	}
+
+	// PublishFunc publishes event to message broker. Returned error means the event is retried.
+	PublishFunc func(ctx context.Context, ev Event) error
+
+	// Config defines the config for outbox.
+	Config struct {
+		// Store stores events.
+		// Required.
+		Store Store
+
+		// Publish publishes events.
+		// Required.
+		Publish PublishFunc
+
+		// PollInterval is interval of dispatching events not published after commit (failed attempts, events of
+		// crashed instances).
+		// Optional. Default value 1 second.
+		PollInterval time.Duration
+
+		// BatchSize is maximum number of events loaded from store at once.
+		// Optional. Default value 100.
+		BatchSize int
+
+		// MaxAttempts is number of publish attempts after which the event is moved to dead letters.
+		// Optional. Default value 10.
+		MaxAttempts int
+
+		// Backoff returns delay before next attempt after given number of failed attempts.
+		// Optional. Default value exponential backoff starting at 1 second limited to 5 minutes.
+		Backoff func(attempts int) time.Duration
+
+		// OnDeadLetter is called when the event is moved to dead letters with the error of its last attempt.
+		// Optional. Default value logs the event with ERROR level.
+		OnDeadLetter func(ev Event, err error)
+	}
+
+	// Outbox adds events to the store and dispatches them.
+	Outbox struct {
+		config Config
+		echo   *echo.Echo
+		wake   chan struct{}
+		stop   chan struct{}
+		done   chan struct{}
+		cancel context.CancelFunc
+		mu     sync.Mutex
+	}
+)
+
+// ErrNoTx is returned by `Outbox#Add()` when the request has no transaction started by `middleware.Tx`.
+var ErrNoTx = errors.New("outbox: request has no transaction")
+
+// DefaultConfig is the default outbox config.
+var DefaultConfig = Config{
+	PollInterval: time.Second,
+	BatchSize:    100,
+	MaxAttempts:  10,
+	Backoff:      DefaultBackoff,
+}
+
+// DefaultBackoff is exponential backoff starting at 1 second limited to 5 minutes.
+func DefaultBackoff(attempts int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempts && d < 5*time.Minute; i++ {
+		d *= 2
+	}
+	if d > 5*time.Minute {
+		d = 5 * time.Minute
+	}
+	return d
+}
+
+// New creates outbox. Dispatcher is started when Echo is started and stopped when Echo is shut down.
+func New(e *echo.Echo, config Config) *Outbox {
+	// Defaults
+	if config.Store == nil {
+		panic("echo: outbox requires store")
+	}
+	if config.Publish == nil {
+		panic("echo: outbox requires publish function")
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultConfig.BatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultConfig.MaxAttempts
+	}
+	if config.Backoff == nil {
+		config.Backoff = DefaultConfig.Backoff
+	}
+	if config.OnDeadLetter == nil {
+		config.OnDeadLetter = func(ev Event, err error) {
+			e.Logger.Errorf("outbox: event %d (%s) moved to dead letters after %d attempts: %v", ev.ID, ev.Type,
+				ev.Attempts, err)
+		}
+	}
+
+	o := &Outbox{
+		config: config,
+		echo:   e,
+		wake:   make(chan struct{}, 1),
+	}
+	e.OnStart(o.Start)
+	e.OnShutdown(o.Shutdown)
+	return o
+}
+
+// Add stores event in the transaction of the request. Payload of `[]byte` type is stored as is, other values are
+// encoded as JSON. Event is dispatched after the transaction is committed.
+func (o *Outbox) Add(c echo.Context, eventType string, payload interface{}) error {
+	tx := echo.TxFrom(c)
+	if tx == nil {
+		return ErrNoTx
+	}
+	data, ok := payload.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return err
+		}
+	}
+	ev := Event{Type: eventType, Payload: data, CreatedAt: o.echo.Now()}
+	if err := o.config.Store.Insert(c.Request().Context(), tx, ev); err != nil {
+		return err
+	}
+	middleware.TxOnCommit(c, o.notify)
+	return nil
+}
+
+// Start starts dispatcher. It is called by `Echo#Start()`.
+func (o *Outbox) Start() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stop != nil {
+		return nil
+	}
+	var ctx context.Context
+	ctx, o.cancel = context.WithCancel(context.Background())
+	o.stop = make(chan struct{})
+	o.done = make(chan struct{})
+	go o.run(ctx, o.stop, o.done)
+	return nil
+}
+
+// Shutdown stops dispatcher and waits until events due for publishing are published or context is done. It is
+// called by `Echo#Shutdown()`.
+func (o *Outbox) Shutdown(ctx context.Context) error {
+	o.mu.Lock()
+	stop, done, cancel := o.stop, o.done, o.cancel
+	o.stop, o.done, o.cancel = nil, nil, nil
+	o.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	defer cancel()
+	close(stop)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dispatch publishes events due for publishing until there is none left or context is done and returns number of
+// published events.
+func (o *Outbox) Dispatch(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		events, err := o.config.Store.Pending(ctx, o.echo.Now(), o.config.BatchSize)
+		if err != nil {
+			return published, err
+		}
+		for _, ev := range events {
+			if err := ctx.Err(); err != nil {
+				return published, err
+			}
+			ok, err := o.publish(ctx, ev)
+			if err != nil {
+				return published, err
+			}
+			if ok {
+				published++
+			}
+		}
+		if len(events) < o.config.BatchSize {
+			return published, nil
+		}
+	}
+}
+
+// publish publishes the event and updates it in the store. Returned error is error of the store.
+func (o *Outbox) publish(ctx context.Context, ev Event) (bool, error) {
+	pErr := o.config.Publish(ctx, ev)
+	if pErr == nil {
+		return true, o.config.Store.Delivered(ctx, ev)
+	}
+	ev.Attempts++
+	if ev.Attempts >= o.config.MaxAttempts {
+		if err := o.config.Store.DeadLetter(ctx, ev, pErr); err != nil {
+			return false, err
+		}
+		o.config.OnDeadLetter(ev, pErr)
+		return false, nil
+	}
+	return false, o.config.Store.Failed(ctx, ev, o.echo.Now().Add(o.config.Backoff(ev.Attempts)), pErr)
+}
+
+func (o *Outbox) run(ctx context.Context, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(o.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := o.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			o.echo.Logger.Errorf("outbox: failed to dispatch events: %v", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// notify wakes up dispatcher.
+func (o *Outbox) notify() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,363 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDriver records executed statements and returns rows for queries.
+type testDriver struct {
+	mu         sync.Mutex
+	statements []string
+	rows       [][]driver.Value
+}
+
+type testConn struct {
+	driver *testDriver
+}
+
+type testRows struct {
+	rows [][]driver.Value
+}
+
+func (d *testDriver) record(query string, args []driver.NamedValue) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	values := make([]string, len(args))
+	for i, a := range args {
+		values[i] = fmt.Sprint(a.Value)
+	}
+	d.statements = append(d.statements, query+" ["+strings.Join(values, ",")+"]")
+}
+
+func (d *testDriver) executed() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.statements...)
+}
+
+func (d *testDriver) Open(name string) (driver.Conn, error) {
+	return &testConn{driver: d}, nil
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	c.driver.record("BEGIN", nil)
+	return c, nil
+}
+
+func (c *testConn) Commit() error {
+	c.driver.record("COMMIT", nil)
+	return nil
+}
+
+func (c *testConn) Rollback() error {
+	c.driver.record("ROLLBACK", nil)
+	return nil
+}
+
+func (c *testConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *testConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(query, args)
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	return &testRows{rows: c.driver.rows}, nil
+}
+
+func (r *testRows) Columns() []string {
+	return []string{"id", "type", "payload", "attempts", "created_at"}
+}
+
+func (r *testRows) Close() error {
+	return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type testConnector struct {
+	driver *testDriver
+}
+
+func (c testConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c testConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func newTestDB() (*sql.DB, *testDriver) {
+	d := &testDriver{}
+	return sql.OpenDB(testConnector{driver: d}), d
+}
+
+// memoryStore keeps events in memory. Events inserted in transaction are stored immediately.
+type memoryStore struct {
+	mu     sync.Mutex
+	nextID int64
+	events map[int64]*storedEvent
+}
+
+type storedEvent struct {
+	Event
+	next time.Time
+	dead bool
+	err  string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{events: map[int64]*storedEvent{}}
+}
+
+func (s *memoryStore) Insert(ctx context.Context, tx *sql.Tx, ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	ev.ID = s.nextID
+	s.events[ev.ID] = &storedEvent{Event: ev, next: ev.CreatedAt}
+	return nil
+}
+
+func (s *memoryStore) Pending(ctx context.Context, now time.Time, limit int) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []Event
+	for _, ev := range s.events {
+		if !ev.dead && !ev.next.After(now) {
+			events = append(events, ev.Event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (s *memoryStore) Delivered(ctx context.Context, ev Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.events, ev.ID)
+	return nil
+}
+
+func (s *memoryStore) Failed(ctx context.Context, ev Event, next time.Time, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.events[ev.ID]
+	stored.Attempts = ev.Attempts
+	stored.next = next
+	stored.err = err.Error()
+	return nil
+}
+
+func (s *memoryStore) DeadLetter(ctx context.Context, ev Event, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.events[ev.ID]
+	stored.Attempts = ev.Attempts
+	stored.dead = true
+	stored.err = err.Error()
+	return nil
+}
+
+func (s *memoryStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestOutbox_Add(t *testing.T) {
+	db, _ := newTestDB()
+	defer db.Close()
+	store := newMemoryStore()
+	published := make(chan Event, 10)
+	e := echo.New()
+	o := New(e, Config{
+		Store: store,
+		Publish: func(ctx context.Context, ev Event) error {
+			published <- ev
+			return nil
+		},
+		PollInterval: time.Hour,
+	})
+	require.NoError(t, o.Start())
+	defer o.Shutdown(context.Background())
+
+	e.Use(middleware.Tx(db))
+	e.POST("/orders", func(c echo.Context) error {
+		if err := o.Add(c, "orders.created", map[string]int{"id": 1}); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusCreated)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	select {
+	case ev := <-published:
+		assert.Equal(t, "orders.created", ev.Type)
+		assert.Equal(t, `{"id":1}`, string(ev.Payload))
+	case <-time.After(time.Second):
+		t.Fatal("event was not published after commit")
+	}
+	assert.Eventually(t, func() bool {
+		return store.len() == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestOutbox_AddWithoutTx(t *testing.T) {
+	e := echo.New()
+	o := New(e, Config{Store: newMemoryStore(), Publish: func(context.Context, Event) error { return nil }})
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+	assert.Equal(t, ErrNoTx, o.Add(c, "orders.created", []byte("{}")))
+}
+
+func TestOutbox_DispatchRetryAndDeadLetter(t *testing.T) {
+	db, _ := newTestDB()
+	defer db.Close()
+	store := newMemoryStore()
+	clock := &testClock{now: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	e := echo.New()
+	e.Clock = clock
+	var dead []Event
+	o := New(e, Config{
+		Store: store,
+		Publish: func(ctx context.Context, ev Event) error {
+			if ev.Type == "broken" {
+				return errors.New("broker unavailable")
+			}
+			return nil
+		},
+		MaxAttempts: 2,
+		OnDeadLetter: func(ev Event, err error) {
+			dead = append(dead, ev)
+		},
+	})
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	require.NoError(t, store.Insert(context.Background(), tx, Event{Type: "broken", CreatedAt: clock.Now()}))
+	require.NoError(t, store.Insert(context.Background(), tx, Event{Type: "ok", CreatedAt: clock.Now()}))
+
+	n, err := o.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, store.events[1].Attempts)
+	assert.Equal(t, clock.Now().Add(time.Second), store.events[1].next)
+
+	n, err = o.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n) // not due yet
+	assert.Len(t, dead, 0)
+
+	clock.add(time.Second)
+	n, err = o.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, int64(1), dead[0].ID)
+		assert.Equal(t, 2, dead[0].Attempts)
+	}
+	assert.True(t, store.events[1].dead)
+	assert.Equal(t, "broker unavailable", store.events[1].err)
+}
+
+func TestOutbox_StartedByEcho(t *testing.T) {
+	store := newMemoryStore()
+	published := make(chan Event, 1)
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	New(e, Config{
+		Store: store,
+		Publish: func(ctx context.Context, ev Event) error {
+			published <- ev
+			return nil
+		},
+		PollInterval: 10 * time.Millisecond,
+	})
+	store.Insert(context.Background(), nil, Event{Type: "left.by.crashed.instance", CreatedAt: time.Now()})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.Start("127.0.0.1:0")
+	}()
+	select {
+	case ev := <-published:
+		assert.Equal(t, "left.by.crashed.instance", ev.Type)
+	case <-time.After(time.Second):
+		t.Fatal("event was not published")
+	}
+
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-errs)
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, DefaultBackoff(1))
+	assert.Equal(t, 2*time.Second, DefaultBackoff(2))
+	assert.Equal(t, 8*time.Second, DefaultBackoff(4))
+	assert.Equal(t, 5*time.Minute, DefaultBackoff(20))
+}
+
+func TestNew_requiresStoreAndPublish(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: outbox requires store", func() {
+		New(echo.New(), Config{})
+	})
+	assert.PanicsWithValue(t, "echo: outbox requires publish function", func() {
+		New(echo.New(), Config{Store: newMemoryStore()})
+	})
+}
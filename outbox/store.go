@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+)
+
+type (
+	// Store stores events of outbox.
+	Store interface {
+		// Insert inserts the event in the transaction.
+		Insert(ctx context.Context, tx *sql.Tx, ev Event) error
+
+		// Pending returns at most limit events due for publishing at now ordered by ID. Events moved to dead
+		// letters are not returned.
+		Pending(ctx context.Context, now time.Time, limit int) ([]Event, error)
+
+		// Delivered removes published event.
+		Delivered(ctx context.Context, ev Event) error
+
+		// Failed records failed attempt (`Event.Attempts` is already incremented) and time of next attempt.
+		Failed(ctx context.Context, ev Event, next time.Time, err error) error
+
+		// DeadLetter moves event to dead letters.
+		DeadLetter(ctx context.Context, ev Event, err error) error
+	}
+
+	// SQLStore stores events in database table. Table is expected to be created as (adjust types to the database):
+	//
+	//	CREATE TABLE echo_outbox (
+	//		id              BIGSERIAL PRIMARY KEY, -- or INTEGER PRIMARY KEY AUTOINCREMENT, ...
+	//		type            VARCHAR(255) NOT NULL,
+	//		payload         BYTEA NOT NULL,
+	//		attempts        INTEGER NOT NULL DEFAULT 0,
+	//		next_attempt_at TIMESTAMP NOT NULL,
+	//		last_error      TEXT,
+	//		dead_at         TIMESTAMP,
+	//		created_at      TIMESTAMP NOT NULL
+	//	);
+	//	CREATE INDEX echo_outbox_pending ON echo_outbox (next_attempt_at) WHERE dead_at IS NULL;
+	//
+	// Pending events are not locked, events are published by every instance polling the table at the same time.
+	SQLStore struct {
+		// DB is database of the table.
+		DB *sql.DB
+
+		// Table is name of the table.
+		// Default value "echo_outbox".
+		Table string
+
+		// Placeholder returns query placeholder of n-th (starting at 1) argument.
+		// Default value returns "?", use `DollarPlaceholder` for PostgreSQL.
+		Placeholder func(n int) string
+	}
+)
+
+// NewSQLStore creates store with `echo_outbox` table and `?` placeholders.
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{
+		DB:          db,
+		Table:       "echo_outbox",
+		Placeholder: QuestionPlaceholder,
+	}
+}
+
+// QuestionPlaceholder returns "?" placeholders (MySQL, SQLite).
+func QuestionPlaceholder(n int) string {
+	return "?"
+}
+
+// DollarPlaceholder returns "$n" placeholders (PostgreSQL).
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (s *SQLStore) Insert(ctx context.Context, tx *sql.Tx, ev Event) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO "+s.Table+" (type, payload, attempts, next_attempt_at, created_at) "+
+		"VALUES ("+s.args(1, 5)+")", ev.Type, ev.Payload, 0, ev.CreatedAt, ev.CreatedAt)
+	return err
+}
+
+func (s *SQLStore) Pending(ctx context.Context, now time.Time, limit int) ([]Event, error) {
+	rows, err := s.DB.QueryContext(ctx, "SELECT id, type, payload, attempts, created_at FROM "+s.Table+
+		" WHERE dead_at IS NULL AND next_attempt_at <= "+s.Placeholder(1)+" ORDER BY id LIMIT "+strconv.Itoa(limit), now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var ev Event
+		if err := rows.Scan(&ev.ID, &ev.Type, &ev.Payload, &ev.Attempts, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, rows.Err()
+}
+
+func (s *SQLStore) Delivered(ctx context.Context, ev Event) error {
+	_, err := s.DB.ExecContext(ctx, "DELETE FROM "+s.Table+" WHERE id = "+s.Placeholder(1), ev.ID)
+	return err
+}
+
+func (s *SQLStore) Failed(ctx context.Context, ev Event, next time.Time, err error) error {
+	_, dbErr := s.DB.ExecContext(ctx, "UPDATE "+s.Table+" SET attempts = "+s.Placeholder(1)+", next_attempt_at = "+
+		s.Placeholder(2)+", last_error = "+s.Placeholder(3)+" WHERE id = "+s.Placeholder(4),
+		ev.Attempts, next, err.Error(), ev.ID)
+	return dbErr
+}
+
+func (s *SQLStore) DeadLetter(ctx context.Context, ev Event, err error) error {
+	_, dbErr := s.DB.ExecContext(ctx, "UPDATE "+s.Table+" SET attempts = "+s.Placeholder(1)+", dead_at = "+
+		s.Placeholder(2)+", last_error = "+s.Placeholder(3)+" WHERE id = "+s.Placeholder(4),
+		ev.Attempts, time.Now(), err.Error(), ev.ID)
+	return dbErr
+}
+
+// args returns comma separated placeholders of arguments from-to.
+func (s *SQLStore) args(from, to int) string {
+	list := s.Placeholder(from)
+	for i := from + 1; i <= to; i++ {
+		list += ", " + s.Placeholder(i)
+	}
+	return list
+}
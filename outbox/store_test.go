@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLStore(t *testing.T) {
+	db, d := newTestDB()
+	defer db.Close()
+	created := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.rows = [][]driver.Value{{int64(7), "orders.created", []byte(`{"id":1}`), int64(2), created}}
+	s := NewSQLStore(db)
+	s.Placeholder = DollarPlaceholder
+	ctx := context.Background()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	require.NoError(t, s.Insert(ctx, tx, Event{Type: "orders.created", Payload: []byte("{}"), CreatedAt: created}))
+	require.NoError(t, tx.Commit())
+
+	events, err := s.Pending(ctx, created, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Event{{ID: 7, Type: "orders.created", Payload: []byte(`{"id":1}`), Attempts: 2, CreatedAt: created}}, events)
+
+	ev := events[0]
+	require.NoError(t, s.Failed(ctx, ev, created, errors.New("timeout")))
+	require.NoError(t, s.Delivered(ctx, ev))
+
+	assert.Equal(t, []string{
+		"BEGIN []",
+		"INSERT INTO echo_outbox (type, payload, attempts, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5) " +
+			"[orders.created,[123 125],0,2021-01-01 00:00:00 +0000 UTC,2021-01-01 00:00:00 +0000 UTC]",
+		"COMMIT []",
+		"SELECT id, type, payload, attempts, created_at FROM echo_outbox WHERE dead_at IS NULL AND next_attempt_at <= $1 " +
+			"ORDER BY id LIMIT 10 [2021-01-01 00:00:00 +0000 UTC]",
+		"UPDATE echo_outbox SET attempts = $1, next_attempt_at = $2, last_error = $3 WHERE id = $4 " +
+			"[2,2021-01-01 00:00:00 +0000 UTC,timeout,7]",
+		"DELETE FROM echo_outbox WHERE id = $1 [7]",
+	}, d.executed())
+}
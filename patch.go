@@ -0,0 +1,432 @@
+package echo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type (
+	// PatchOperation is operation of JSON Patch document (RFC 6902).
+	PatchOperation struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		From  string          `json:"from,omitempty"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}
+
+	// PatchError describes failed operation of JSON Patch. It is message of the `HTTPError` returned by
+	// `ApplyPatch()`.
+	PatchError struct {
+		// Index is index of the operation in the patch document.
+		Index int `json:"index"`
+		// Op is the operation name.
+		Op string `json:"op"`
+		// Path is target location of the operation.
+		Path string `json:"path"`
+		// Message describes why the operation failed.
+		Message string `json:"message"`
+	}
+)
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %s", e.Index, e.Op, e.Path, e.Message)
+}
+
+// ApplyPatch applies JSON Patch (`application/json-patch+json`, RFC 6902) or JSON Merge Patch
+// (`application/merge-patch+json`, RFC 7386) request body to target (pointer to the resource). Target is
+// patched through its JSON representation and validated with `Echo#Validator` (when registered) and is
+// modified only when the whole patch is applied. When target implements `Versioned`, `If-Match` is checked
+// first (see `CheckVersion()`). Failed JSON Patch operation is reported as `*PatchError` message of returned
+// `HTTPError` (409 for failed `test` operation, 422 otherwise), other content types as 415.
+func ApplyPatch(c Context, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		err := fmt.Errorf("echo: patch target must be non-nil pointer, got %T", target)
+		return NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	if v, ok := target.(Versioned); ok {
+		if err := CheckVersion(c, v); err != nil {
+			return err
+		}
+	}
+
+	ctype := c.Request().Header.Get(HeaderContentType)
+	if i := strings.IndexByte(ctype, ';'); i >= 0 {
+		ctype = ctype[:i]
+	}
+	ctype = strings.ToLower(strings.TrimSpace(ctype))
+	if ctype != MIMEApplicationJSONPatch && ctype != MIMEApplicationMergePatch {
+		return ErrUnsupportedMediaType
+	}
+	body, err := BodyBytes(c)
+	if err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(target)
+	if err != nil {
+		return NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	var current interface{}
+	if err := decodeJSONValue(doc, &current); err != nil {
+		return NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+
+	var patched interface{}
+	if ctype == MIMEApplicationJSONPatch {
+		var ops []PatchOperation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			return NewHTTPError(http.StatusBadRequest, "invalid JSON Patch document").SetInternal(err)
+		}
+		if patched, err = applyJSONPatch(current, ops); err != nil {
+			return err
+		}
+	} else {
+		var patch interface{}
+		if err := decodeJSONValue(body, &patch); err != nil {
+			return NewHTTPError(http.StatusBadRequest, "invalid JSON Merge Patch document").SetInternal(err)
+		}
+		patched = mergePatch(current, patch)
+	}
+
+	if doc, err = json.Marshal(patched); err != nil {
+		return NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+	// start with copy of the target so fields not present in JSON (`json:"-"`, unexported) are kept
+	result := reflect.New(rv.Elem().Type())
+	result.Elem().Set(rv.Elem())
+	zeroJSONFields(result.Elem())
+	if err := json.Unmarshal(doc, result.Interface()); err != nil {
+		return NewHTTPError(http.StatusUnprocessableEntity, "patched document does not match the resource").SetInternal(err)
+	}
+	if c.Echo().Validator != nil {
+		if err := c.Validate(result.Interface()); err != nil {
+			return err
+		}
+	}
+	rv.Elem().Set(result.Elem())
+	return nil
+}
+
+// zeroJSONFields sets fields of the value encoded to JSON to zero values.
+func zeroJSONFields(v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("json") == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && v.Field(i).CanSet() {
+			zeroJSONFields(v.Field(i))
+			continue
+		}
+		if f.PkgPath != "" || !v.Field(i).CanSet() {
+			continue
+		}
+		v.Field(i).Set(reflect.Zero(f.Type))
+	}
+}
+
+func decodeJSONValue(data []byte, v *interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// applyJSONPatch applies operations to the document. Patch is atomic, error of the first failed operation is returned.
+func applyJSONPatch(doc interface{}, ops []PatchOperation) (interface{}, error) {
+	for i, op := range ops {
+		var err error
+		if doc, err = applyPatchOperation(doc, op); err != nil {
+			pe := &PatchError{Index: i, Op: op.Op, Path: op.Path, Message: err.Error()}
+			code := http.StatusUnprocessableEntity
+			if err == errPatchTestFailed {
+				code = http.StatusConflict
+			}
+			return nil, NewHTTPError(code, pe).SetInternal(pe)
+		}
+	}
+	return doc, nil
+}
+
+var errPatchTestFailed = errors.New("test failed")
+
+func applyPatchOperation(doc interface{}, op PatchOperation) (interface{}, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		if err := decodeJSONValue(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value")
+		}
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = jsonPointerGet(doc, from); err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, fmt.Errorf("can not move value into its child")
+			}
+			if doc, err = jsonPointerRemove(doc, from); err != nil {
+				return nil, err
+			}
+		}
+		value = deepCopyJSON(value)
+	}
+
+	switch op.Op {
+	case "add", "move", "copy":
+		return jsonPointerAdd(doc, path, value)
+	case "remove":
+		return jsonPointerRemove(doc, path)
+	case "replace":
+		if _, err := jsonPointerGet(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return value, nil
+		}
+		if doc, err = jsonPointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+		return jsonPointerAdd(doc, path, value)
+	case "test":
+		current, err := jsonPointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(current, value) {
+			return nil, errPatchTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation")
+}
+
+// parseJSONPointer parses JSON Pointer (RFC 6901) into unescaped reference tokens.
+func parseJSONPointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid path")
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func jsonPointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			doc = child
+		case []interface{}:
+			i, err := arrayIndex(token, len(v)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = v[i]
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointerAdd adds value at the path and returns the modified document.
+func jsonPointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch v := parent.(type) {
+	case map[string]interface{}:
+		v[token] = value
+		return doc, nil
+	case []interface{}:
+		i := len(v)
+		if token != "-" {
+			if i, err = arrayIndex(token, len(v)); err != nil {
+				return nil, err
+			}
+		}
+		v = append(v, nil)
+		copy(v[i+1:], v[i:])
+		v[i] = value
+		return jsonPointerSet(doc, path[:len(path)-1], v)
+	}
+	return nil, fmt.Errorf("path not found")
+}
+
+// jsonPointerRemove removes value at the path and returns the modified document.
+func jsonPointerRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("can not remove document root")
+	}
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch v := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := v[token]; !ok {
+			return nil, fmt.Errorf("path not found")
+		}
+		delete(v, token)
+		return doc, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(v)-1)
+		if err != nil {
+			return nil, err
+		}
+		v = append(v[:i:i], v[i+1:]...)
+		return jsonPointerSet(doc, path[:len(path)-1], v)
+	}
+	return nil, fmt.Errorf("path not found")
+}
+
+// jsonPointerSet replaces value at existing path (used for arrays changing their length).
+func jsonPointerSet(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch v := parent.(type) {
+	case map[string]interface{}:
+		v[token] = value
+	case []interface{}:
+		i, err := arrayIndex(token, len(v)-1)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = value
+	}
+	return doc, nil
+}
+
+// arrayIndex parses array index token not greater than max.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index")
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index")
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index out of bounds")
+	}
+	return i, nil
+}
+
+// mergePatch applies JSON Merge Patch (RFC 7386) to the document.
+func mergePatch(doc, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	target, ok := doc.(map[string]interface{})
+	if !ok {
+		target = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(target, k)
+			continue
+		}
+		target[k] = mergePatch(target[k], v)
+	}
+	return target
+}
+
+func deepCopyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = deepCopyJSON(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = deepCopyJSON(val)
+		}
+		return s
+	}
+	return v
+}
+
+// jsonEqual compares decoded JSON values, numbers are compared by value.
+func jsonEqual(a, b interface{}) bool {
+	if na, ok := a.(json.Number); ok {
+		nb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		return errA == nil && errB == nil && fa == fb
+	}
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for k, v := range va {
+			if w, ok := vb[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !jsonEqual(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
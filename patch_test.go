@@ -0,0 +1,192 @@
+package echo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type patchTestUser struct {
+	Name  string            `json:"name"`
+	Email string            `json:"email,omitempty"`
+	Tags  []string          `json:"tags"`
+	Meta  map[string]string `json:"meta,omitempty"`
+	Age   int               `json:"age"`
+}
+
+type patchTestVersionedUser struct {
+	patchTestUser
+	Rev string `json:"-"`
+}
+
+func (u *patchTestVersionedUser) Version() string {
+	return u.Rev
+}
+
+type patchTestValidator struct{}
+
+func (patchTestValidator) Validate(i interface{}) error {
+	if u, ok := i.(*patchTestUser); ok && u.Name == "" {
+		return NewHTTPError(http.StatusBadRequest, "name is required")
+	}
+	return nil
+}
+
+func newPatchContext(contentType, body string) (*Echo, Context) {
+	e := New()
+	req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+	req.Header.Set(HeaderContentType, contentType)
+	return e, e.NewContext(req, httptest.NewRecorder())
+}
+
+func testPatchUser() *patchTestUser {
+	return &patchTestUser{Name: "Jon", Email: "jon@labstack.com", Tags: []string{"a", "b"}, Age: 30}
+}
+
+func TestContext_ApplyPatch_JSONPatch(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		patch  string
+		expect patchTestUser
+	}{
+		{
+			name:   "replace",
+			patch:  `[{"op":"replace","path":"/name","value":"Joe"}]`,
+			expect: patchTestUser{Name: "Joe", Email: "jon@labstack.com", Tags: []string{"a", "b"}, Age: 30},
+		},
+		{
+			name:   "add to array and remove",
+			patch:  `[{"op":"add","path":"/tags/1","value":"x"},{"op":"add","path":"/tags/-","value":"z"},{"op":"remove","path":"/email"}]`,
+			expect: patchTestUser{Name: "Jon", Tags: []string{"a", "x", "b", "z"}, Age: 30},
+		},
+		{
+			name:   "move, copy and test",
+			patch:  `[{"op":"test","path":"/age","value":30.0},{"op":"copy","from":"/name","path":"/email"},{"op":"move","from":"/tags/0","path":"/tags/1"}]`,
+			expect: patchTestUser{Name: "Jon", Email: "Jon", Tags: []string{"b", "a"}, Age: 30},
+		},
+		{
+			name:   "escaped pointer",
+			patch:  `[{"op":"add","path":"/meta","value":{}},{"op":"add","path":"/meta/a~1b~0c","value":"v"}]`,
+			expect: patchTestUser{Name: "Jon", Email: "jon@labstack.com", Tags: []string{"a", "b"}, Meta: map[string]string{"a/b~c": "v"}, Age: 30},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, c := newPatchContext(MIMEApplicationJSONPatch, tc.patch)
+			u := testPatchUser()
+			assert.NoError(t, ApplyPatch(c, u))
+			assert.Equal(t, tc.expect, *u)
+		})
+	}
+}
+
+func TestContext_ApplyPatch_JSONPatchErrors(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		patch       string
+		expectCode  int
+		expectError *PatchError
+	}{
+		{
+			name:       "invalid document",
+			patch:      `{"op":"add"}`,
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:        "failed test",
+			patch:       `[{"op":"replace","path":"/name","value":"Joe"},{"op":"test","path":"/age","value":31}]`,
+			expectCode:  http.StatusConflict,
+			expectError: &PatchError{Index: 1, Op: "test", Path: "/age", Message: "test failed"},
+		},
+		{
+			name:        "missing path",
+			patch:       `[{"op":"remove","path":"/phone"}]`,
+			expectCode:  http.StatusUnprocessableEntity,
+			expectError: &PatchError{Index: 0, Op: "remove", Path: "/phone", Message: "path not found"},
+		},
+		{
+			name:        "index out of bounds",
+			patch:       `[{"op":"replace","path":"/tags/2","value":"c"}]`,
+			expectCode:  http.StatusUnprocessableEntity,
+			expectError: &PatchError{Index: 0, Op: "replace", Path: "/tags/2", Message: "array index out of bounds"},
+		},
+		{
+			name:        "unknown operation",
+			patch:       `[{"op":"increment","path":"/age"}]`,
+			expectCode:  http.StatusUnprocessableEntity,
+			expectError: &PatchError{Index: 0, Op: "increment", Path: "/age", Message: "unknown operation"},
+		},
+		{
+			name:        "move into child",
+			patch:       `[{"op":"move","from":"/tags","path":"/tags/0"}]`,
+			expectCode:  http.StatusUnprocessableEntity,
+			expectError: &PatchError{Index: 0, Op: "move", Path: "/tags/0", Message: "can not move value into its child"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, c := newPatchContext(MIMEApplicationJSONPatch, tc.patch)
+			u := testPatchUser()
+			err := ApplyPatch(c, u)
+			var he *HTTPError
+			if assert.True(t, errors.As(err, &he)) {
+				assert.Equal(t, tc.expectCode, he.Code)
+				if tc.expectError != nil {
+					assert.Equal(t, tc.expectError, he.Message)
+				}
+			}
+			assert.Equal(t, *testPatchUser(), *u) // patch is atomic
+		})
+	}
+}
+
+func TestContext_ApplyPatch_TypeMismatch(t *testing.T) {
+	_, c := newPatchContext(MIMEApplicationJSONPatch, `[{"op":"replace","path":"/age","value":"old"}]`)
+	u := testPatchUser()
+
+	err := ApplyPatch(c, u)
+	assert.Equal(t, http.StatusUnprocessableEntity, err.(*HTTPError).Code)
+	assert.Equal(t, *testPatchUser(), *u)
+}
+
+func TestContext_ApplyPatch_MergePatch(t *testing.T) {
+	_, c := newPatchContext(MIMEApplicationMergePatch+"; charset=UTF-8",
+		`{"name":"Joe","email":null,"meta":{"team":"core"}}`)
+	u := testPatchUser()
+
+	assert.NoError(t, ApplyPatch(c, u))
+	assert.Equal(t, patchTestUser{Name: "Joe", Tags: []string{"a", "b"}, Meta: map[string]string{"team": "core"}, Age: 30}, *u)
+}
+
+func TestContext_ApplyPatch_Validate(t *testing.T) {
+	e, c := newPatchContext(MIMEApplicationMergePatch, `{"name":""}`)
+	e.Validator = patchTestValidator{}
+	u := testPatchUser()
+
+	err := ApplyPatch(c, u)
+	assert.Equal(t, http.StatusBadRequest, err.(*HTTPError).Code)
+	assert.Equal(t, "Jon", u.Name)
+}
+
+func TestContext_ApplyPatch_UnsupportedMediaType(t *testing.T) {
+	_, c := newPatchContext(MIMEApplicationJSON, `{"name":"Joe"}`)
+	assert.Equal(t, ErrUnsupportedMediaType, ApplyPatch(c, testPatchUser()))
+}
+
+func TestContext_ApplyPatch_Versioned(t *testing.T) {
+	_, c := newPatchContext(MIMEApplicationMergePatch, `{"name":"Joe"}`)
+	u := &patchTestVersionedUser{patchTestUser: *testPatchUser(), Rev: "2"}
+	assert.Equal(t, ErrPreconditionRequired, ApplyPatch(c, u))
+
+	c.Request().Header.Set(HeaderIfMatch, `"1"`)
+	assert.Equal(t, ErrPreconditionFailed, ApplyPatch(c, u))
+
+	c.Request().Header.Set(HeaderIfMatch, `"2"`)
+	assert.NoError(t, ApplyPatch(c, u))
+	assert.Equal(t, "Joe", u.Name)
+	assert.Equal(t, "2", u.Rev)
+}
@@ -0,0 +1,203 @@
+package echo
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type (
+	// PathPolicy is treatment of ambiguous request path forms by the router. See `PathNormalizationConfig`.
+	PathPolicy uint8
+
+	// PathNormalizationConfig defines how router treats request paths which proxies, security scanners and
+	// applications may interpret differently. Zero value passes all paths through unchanged.
+	PathNormalizationConfig struct {
+		// EncodedSlashes is policy for encoded slashes (`%2F`). Pass-through matches routes with encoded slash kept
+		// inside of path segment (param value contains `/`), normalize decodes them to path separators.
+		EncodedSlashes PathPolicy
+
+		// DoubleSlashes is policy for consecutive slashes (`/a//b`). Normalize merges them to single slash.
+		DoubleSlashes PathPolicy
+
+		// DotSegments is policy for `.` and `..` path segments, including their encoded forms (`%2e%2e`).
+		// Normalize resolves them as described in RFC 3986 section 5.2.4, `..` never goes above root.
+		DotSegments PathPolicy
+	}
+)
+
+const (
+	// PathPassThrough routes request with path as it was received.
+	PathPassThrough PathPolicy = iota
+	// PathNormalize rewrites request path to normalized form before routing. Handlers see normalized path.
+	PathNormalize
+	// PathReject rejects request with ErrAmbiguousPath.
+	PathReject
+)
+
+// MetaPathNormalization is metadata key of route path normalization (`*PathNormalizationConfig`) set by
+// `Route#PathNormalization()`.
+const MetaPathNormalization = "path_normalization"
+
+// ErrAmbiguousPath denotes an error raised when request path is rejected by path normalization policy.
+var ErrAmbiguousPath = NewHTTPError(http.StatusBadRequest, "ambiguous request path")
+
+// PathNormalization overrides `Echo#PathNormalization` for requests matching the route by their received (not
+// normalized) path, i.e. to pass encoded slashes to a proxy route while rejecting them everywhere else.
+//
+// Example:
+//
+//	e.PathNormalization = echo.PathNormalizationConfig{EncodedSlashes: echo.PathReject, DotSegments: echo.PathNormalize}
+//	e.GET("/proxy/*", proxyHandler).PathNormalization(echo.PathNormalizationConfig{})
+func (r *Route) PathNormalization(config PathNormalizationConfig) *Route {
+	return r.SetMeta(MetaPathNormalization, &config)
+}
+
+// find matches request to route, applies path normalization policies when request path is ambiguous and coerces
+// declared route parameters.
+func (e *Echo) find(r *http.Request, c Context) {
+	if e.findRoute(r, c) {
+		if ctx, ok := c.(*context); ok {
+			ctx.coerceParams()
+		}
+	}
+}
+
+// findRoute matches request to route. Returns false when request was rejected by path normalization policy.
+func (e *Echo) findRoute(r *http.Request, c Context) bool {
+	router := e.findRouter(r.Host)
+	path := GetPath(r)
+	router.Find(r.Method, path, c)
+	if !isAmbiguousPath(r.URL) {
+		return true
+	}
+
+	config := e.PathNormalization
+	if route := RouteOf(c); route != nil {
+		if rc, ok := route.GetMeta(MetaPathNormalization).(*PathNormalizationConfig); ok {
+			config = *rc
+		}
+	}
+	if config == (PathNormalizationConfig{}) {
+		return true
+	}
+	if err := config.normalize(r.URL); err != nil {
+		c.SetHandler(func(c Context) error {
+			return err
+		})
+		return false
+	}
+	if normalized := GetPath(r); normalized != path {
+		router.Find(r.Method, normalized, c)
+	}
+	return true
+}
+
+// isAmbiguousPath reports whether path contains encoded slashes, double slashes or dot segments.
+func isAmbiguousPath(u *url.URL) bool {
+	if u.RawPath != "" && containsFold(u.RawPath, "%2f") {
+		return true
+	}
+	return strings.Contains(u.Path, "//") || hasDotSegment(u.Path)
+}
+
+func (config PathNormalizationConfig) normalize(u *url.URL) error {
+	if u.RawPath != "" && containsFold(u.RawPath, "%2f") {
+		switch config.EncodedSlashes {
+		case PathReject:
+			return ErrAmbiguousPath
+		case PathNormalize:
+			u.RawPath = ""
+		}
+	}
+
+	// segments of RawPath are kept escaped, dot segments are detected on decoded path which is the same for both
+	path := u.Path
+	if u.RawPath != "" {
+		path = u.RawPath
+	}
+	segments := strings.Split(path, "/")
+	normalized := make([]string, 0, len(segments))
+	changed := false
+	for i, s := range segments {
+		last := i == len(segments)-1
+		switch {
+		case s == "" && i > 0 && !last:
+			if config.DoubleSlashes == PathReject {
+				return ErrAmbiguousPath
+			}
+			if config.DoubleSlashes == PathNormalize {
+				changed = true
+				continue
+			}
+		case isDotSegment(s, "."), isDotSegment(s, ".."):
+			if config.DotSegments == PathReject {
+				return ErrAmbiguousPath
+			}
+			if config.DotSegments == PathNormalize {
+				changed = true
+				if isDotSegment(s, "..") && len(normalized) > 1 {
+					normalized = normalized[:len(normalized)-1]
+				}
+				if last {
+					// `/a/..` resolves to `/` and `/a/.` to `/a/` so trailing slash is kept
+					normalized = append(normalized, "")
+				}
+				continue
+			}
+		}
+		normalized = append(normalized, s)
+	}
+	if !changed {
+		return nil
+	}
+
+	path = strings.Join(normalized, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if u.RawPath == "" {
+		u.Path = path
+		return nil
+	}
+	decoded, err := url.PathUnescape(path)
+	if err != nil {
+		return ErrAmbiguousPath
+	}
+	u.Path = decoded
+	u.RawPath = path
+	return nil
+}
+
+// hasDotSegment reports whether decoded path contains `.` or `..` segment.
+func hasDotSegment(path string) bool {
+	for i := 0; i < len(path); i++ {
+		if path[i] != '.' || (i > 0 && path[i-1] != '/') {
+			continue
+		}
+		j := i + 1
+		if j < len(path) && path[j] == '.' {
+			j++
+		}
+		if j == len(path) || path[j] == '/' {
+			return true
+		}
+	}
+	return false
+}
+
+// isDotSegment reports whether path segment (possibly percent encoded) is equal to dot.
+func isDotSegment(segment, dot string) bool {
+	if segment == dot {
+		return true
+	}
+	if !strings.Contains(segment, "%") {
+		return false
+	}
+	s, err := url.PathUnescape(segment)
+	return err == nil && s == dot
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), substr)
+}
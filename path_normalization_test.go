@@ -0,0 +1,140 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEcho_PathNormalization(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		givenConfig PathNormalizationConfig
+		whenURL     string
+		expectCode  int
+		expectBody  string
+	}{
+		{
+			name:       "pass through by default",
+			whenURL:    "/files/a%2Fb",
+			expectCode: http.StatusOK,
+			expectBody: "file a%2Fb",
+		},
+		{
+			name:        "normalize encoded slashes",
+			givenConfig: PathNormalizationConfig{EncodedSlashes: PathNormalize},
+			whenURL:     "/files/a%2Fb",
+			expectCode:  http.StatusOK,
+			expectBody:  "dir a file b",
+		},
+		{
+			name:        "reject encoded slashes",
+			givenConfig: PathNormalizationConfig{EncodedSlashes: PathReject},
+			whenURL:     "/files/a%2fb",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "normalize double slashes",
+			givenConfig: PathNormalizationConfig{DoubleSlashes: PathNormalize},
+			whenURL:     "/files//a",
+			expectCode:  http.StatusOK,
+			expectBody:  "file a",
+		},
+		{
+			name:        "reject double slashes",
+			givenConfig: PathNormalizationConfig{DoubleSlashes: PathReject},
+			whenURL:     "/files//a",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "normalize dot segments",
+			givenConfig: PathNormalizationConfig{DotSegments: PathNormalize},
+			whenURL:     "/admin/../files/./a",
+			expectCode:  http.StatusOK,
+			expectBody:  "file a",
+		},
+		{
+			name:        "normalize encoded dot segments",
+			givenConfig: PathNormalizationConfig{DotSegments: PathNormalize},
+			whenURL:     "/files/x/%2e%2e/a",
+			expectCode:  http.StatusOK,
+			expectBody:  "file a",
+		},
+		{
+			name:        "dot segments do not go above root",
+			givenConfig: PathNormalizationConfig{DotSegments: PathNormalize},
+			whenURL:     "/../../files/a",
+			expectCode:  http.StatusOK,
+			expectBody:  "file a",
+		},
+		{
+			name:        "reject dot segments",
+			givenConfig: PathNormalizationConfig{DotSegments: PathReject},
+			whenURL:     "/files/../admin",
+			expectCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "route overrides policy",
+			givenConfig: PathNormalizationConfig{EncodedSlashes: PathReject, DoubleSlashes: PathReject},
+			whenURL:     "/proxy/a%2Fb//c",
+			expectCode:  http.StatusOK,
+			expectBody:  "proxy a%2Fb//c",
+		},
+		{
+			name:        "dots are not dot segments",
+			givenConfig: PathNormalizationConfig{DotSegments: PathReject},
+			whenURL:     "/files/.a..",
+			expectCode:  http.StatusOK,
+			expectBody:  "file .a..",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			e.PathNormalization = tc.givenConfig
+			e.GET("/files/:name", func(c Context) error {
+				return c.String(http.StatusOK, "file "+c.Param("name"))
+			})
+			e.GET("/files/:dir/:name", func(c Context) error {
+				return c.String(http.StatusOK, "dir "+c.Param("dir")+" file "+c.Param("name"))
+			})
+			e.GET("/proxy/*", func(c Context) error {
+				return c.String(http.StatusOK, "proxy "+c.Param("*"))
+			}).PathNormalization(PathNormalizationConfig{})
+			e.GET("/*", func(c Context) error {
+				return c.String(http.StatusOK, "any "+c.Param("*"))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectCode == http.StatusOK {
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestEcho_PathNormalizationPreMiddleware(t *testing.T) {
+	e := New()
+	e.PathNormalization = PathNormalizationConfig{DotSegments: PathNormalize}
+	e.Pre(func(next HandlerFunc) HandlerFunc {
+		return next
+	})
+	var path string
+	e.GET("/files/:name", func(c Context) error {
+		path = c.Request().URL.Path
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/x/../a", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/files/a", path)
+}
@@ -0,0 +1,67 @@
+package echo
+
+import (
+	"io"
+	"net/http"
+)
+
+// pdfWriter commits response with PDF headers on the first write.
+type pdfWriter struct {
+	context Context
+	code    int
+	written bool
+}
+
+// PDF sends a PDF document written by generator with status code. Output is streamed to the client as it is
+// written. Document is displayed inline unless `Content-Disposition` header was set, see
+// `PDFAttachment()`. Error of generator which has not written anything yet is returned before
+// response is committed so error handler can send error response.
+func PDF(c Context, code int, generator func(w io.Writer) error) (err error) {
+	defer trackRender(c)()
+	header := c.Response().Header()
+	if header.Get(HeaderContentDisposition) == "" {
+		header.Set(HeaderContentDisposition, "inline")
+	}
+	w := &pdfWriter{context: c, code: code}
+	if err = generator(w); err != nil {
+		if !w.written {
+			// error handler sends its own response
+			header.Del(HeaderContentDisposition)
+		}
+		return
+	}
+	if !w.written {
+		w.commit()
+	}
+	return
+}
+
+// PDFAttachment sends a PDF document written by generator with status code as attachment with file name,
+// prompting client to save it. See `PDF()`.
+func PDFAttachment(c Context, code int, name string, generator func(w io.Writer) error) error {
+	c.Response().Header().Set(HeaderContentDisposition, ContentDisposition("attachment", name))
+	return PDF(c, code, generator)
+}
+
+func (w *pdfWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.commit()
+	}
+	return w.context.Response().Write(b)
+}
+
+// Flush sends output written so far to the client.
+func (w *pdfWriter) Flush() {
+	if !w.written {
+		w.commit()
+	}
+	flush(w.context)
+}
+
+func (w *pdfWriter) commit() {
+	w.written = true
+	writeContentType(w.context, MIMEApplicationPDF)
+	w.context.Response().WriteHeader(w.code)
+}
+
+var _ http.Flusher = (*pdfWriter)(nil)
@@ -0,0 +1,144 @@
+package echo
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_PDF(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		whenDisposition   string
+		generator         func(w io.Writer) error
+		expectErr         string
+		expectCode        int
+		expectType        string
+		expectDisposition string
+		expectBody        string
+		expectFlushed     bool
+	}{
+		{
+			name: "inline",
+			generator: func(w io.Writer) error {
+				_, err := io.WriteString(w, "%PDF-1.4 ...")
+				return err
+			},
+			expectCode:        http.StatusOK,
+			expectType:        MIMEApplicationPDF,
+			expectDisposition: "inline",
+			expectBody:        "%PDF-1.4 ...",
+		},
+		{
+			name:            "preset disposition",
+			whenDisposition: `inline; filename="invoice.pdf"`,
+			generator: func(w io.Writer) error {
+				_, err := io.WriteString(w, "%PDF")
+				return err
+			},
+			expectCode:        http.StatusOK,
+			expectType:        MIMEApplicationPDF,
+			expectDisposition: `inline; filename="invoice.pdf"`,
+			expectBody:        "%PDF",
+		},
+		{
+			name: "streamed",
+			generator: func(w io.Writer) error {
+				io.WriteString(w, "%PDF page 1")
+				w.(http.Flusher).Flush()
+				_, err := io.WriteString(w, " page 2")
+				return err
+			},
+			expectCode:        http.StatusOK,
+			expectType:        MIMEApplicationPDF,
+			expectDisposition: "inline",
+			expectBody:        "%PDF page 1 page 2",
+			expectFlushed:     true,
+		},
+		{
+			name: "error before output",
+			generator: func(w io.Writer) error {
+				return errors.New("template not found")
+			},
+			expectErr:  "template not found",
+			expectCode: http.StatusOK, // recorder default, nothing written
+		},
+		{
+			name: "error after output",
+			generator: func(w io.Writer) error {
+				io.WriteString(w, "%PDF")
+				return errors.New("image failed")
+			},
+			expectErr:         "image failed",
+			expectCode:        http.StatusOK,
+			expectType:        MIMEApplicationPDF,
+			expectDisposition: "inline",
+			expectBody:        "%PDF",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/invoice", nil), rec)
+			if tc.whenDisposition != "" {
+				c.Response().Header().Set(HeaderContentDisposition, tc.whenDisposition)
+			}
+
+			err := PDF(c, http.StatusOK, tc.generator)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.Equal(t, tc.expectType, rec.Header().Get(HeaderContentType))
+			assert.Equal(t, tc.expectDisposition, rec.Header().Get(HeaderContentDisposition))
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+			assert.Equal(t, tc.expectFlushed, rec.Flushed)
+			assert.Equal(t, tc.expectBody != "", c.Response().Committed)
+		})
+	}
+}
+
+func TestContext_PDF_empty(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	assert.NoError(t, PDF(c, http.StatusCreated, func(w io.Writer) error { return nil }))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, MIMEApplicationPDF, rec.Header().Get(HeaderContentType))
+}
+
+func TestContext_PDFAttachment(t *testing.T) {
+	e := New()
+	e.GET("/report", func(c Context) error {
+		return PDFAttachment(c, http.StatusOK, "Bericht März.pdf", func(w io.Writer) error {
+			_, err := io.WriteString(w, "%PDF")
+			return err
+		})
+	})
+	e.GET("/broken", func(c Context) error {
+		return PDFAttachment(c, http.StatusOK, "broken.pdf", func(w io.Writer) error {
+			return ErrServiceUnavailable
+		})
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename="Bericht M_rz.pdf"; filename*=UTF-8''Bericht%20M%C3%A4rz.pdf`,
+		rec.Header().Get(HeaderContentDisposition))
+	assert.Equal(t, "%PDF", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, rec.Header().Get(HeaderContentType))
+	assert.Empty(t, rec.Header().Get(HeaderContentDisposition))
+}
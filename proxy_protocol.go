@@ -0,0 +1,274 @@
+package echo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// ProxyProtocolConfig defines the config for PROXY protocol (v1 and v2) listener. Proxies working in TCP mode
+	// (HAProxy, AWS NLB etc.) send PROXY protocol header with address of the original client before any other
+	// data. Listener strips the header and reports client address as connection remote address so
+	// `Request.RemoteAddr` and `Context#RealIP()` return the true client.
+	// See: https://www.haproxy.org/download/2.4/doc/proxy-protocol.txt
+	ProxyProtocolConfig struct {
+		// HeaderTimeout is how long to wait for PROXY protocol header after connection is accepted.
+		// Optional. Default value 5 seconds.
+		HeaderTimeout time.Duration
+
+		// TrustedProxies are networks of proxies allowed to send PROXY protocol header. Header of connections
+		// from other addresses is not parsed.
+		// Optional. Default value nil (all addresses are trusted).
+		TrustedProxies []*net.IPNet
+
+		// Required rejects connections (from trusted proxies) that do not start with PROXY protocol header.
+		// Optional. Default value false.
+		Required bool
+	}
+
+	// ProxyHeader is parsed PROXY protocol header. Connections accepted by PROXY protocol listener implement
+	// `interface{ ProxyHeader() *ProxyHeader }`.
+	ProxyHeader struct {
+		// Version is PROXY protocol version (1 or 2).
+		Version int
+		// Local is set for connections made by the proxy itself (v2 LOCAL command, v1 UNKNOWN protocol). Source
+		// and destination addresses of such connections are not changed.
+		Local           bool
+		SourceAddr      net.Addr
+		DestinationAddr net.Addr
+	}
+
+	proxyProtocolListener struct {
+		net.Listener
+		config ProxyProtocolConfig
+	}
+
+	proxyProtocolConn struct {
+		net.Conn
+		config ProxyProtocolConfig
+		reader *bufio.Reader
+		once   sync.Once
+		header *ProxyHeader
+		err    error
+	}
+)
+
+// Errors
+var (
+	ErrProxyProtocolHeaderMissing = errors.New("proxy protocol: header missing")
+	ErrProxyProtocolHeaderInvalid = errors.New("proxy protocol: invalid header")
+)
+
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyProtocolV1MaxLength is maximum length of v1 header line including CRLF.
+const proxyProtocolV1MaxLength = 107
+
+// ProxyProtocolListener wraps listener with PROXY protocol listener using default config. Can be used as
+// `Echo#ListenerWrapper`.
+func ProxyProtocolListener(l net.Listener) net.Listener {
+	return ProxyProtocolConfig{}.Wrap(l)
+}
+
+// Wrap wraps listener with PROXY protocol listener using the config. Method value can be used as
+// `Echo#ListenerWrapper`.
+func (config ProxyProtocolConfig) Wrap(l net.Listener) net.Listener {
+	if config.HeaderTimeout == 0 {
+		config.HeaderTimeout = 5 * time.Second
+	}
+	return &proxyProtocolListener{Listener: l, config: config}
+}
+
+// Accept waits for next connection. PROXY protocol header is read lazily on first use of the connection so slow
+// clients do not block accepting of other connections.
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyProtocolConn{Conn: c, config: l.config}, nil
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns address of the client sent in PROXY protocol header.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.header != nil && !c.header.Local && c.header.SourceAddr != nil {
+		return c.header.SourceAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns destination address sent in PROXY protocol header.
+func (c *proxyProtocolConn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.header != nil && !c.header.Local && c.header.DestinationAddr != nil {
+		return c.header.DestinationAddr
+	}
+	return c.Conn.LocalAddr()
+}
+
+// ProxyHeader returns parsed PROXY protocol header or nil when connection did not send one.
+func (c *proxyProtocolConn) ProxyHeader() *ProxyHeader {
+	c.once.Do(c.readHeader)
+	return c.header
+}
+
+func (c *proxyProtocolConn) trusted() bool {
+	if len(c.config.TrustedProxies) == 0 {
+		return true
+	}
+	addr, ok := c.Conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range c.config.TrustedProxies {
+		if n.Contains(addr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *proxyProtocolConn) readHeader() {
+	c.reader = bufio.NewReaderSize(c.Conn, 256)
+	if !c.trusted() {
+		return
+	}
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.config.HeaderTimeout)); err != nil {
+		c.err = err
+		return
+	}
+	c.header, c.err = readProxyHeader(c.reader)
+	if c.err == nil && c.header == nil && c.config.Required {
+		c.err = ErrProxyProtocolHeaderMissing
+	}
+	if err := c.Conn.SetReadDeadline(time.Time{}); err != nil && c.err == nil {
+		c.err = err
+	}
+	if c.err != nil {
+		c.Conn.Close()
+	}
+}
+
+// readProxyHeader reads PROXY protocol header from the reader. Returns nil header when data does not start with
+// PROXY protocol signature.
+func readProxyHeader(r *bufio.Reader) (*ProxyHeader, error) {
+	sig, err := r.Peek(len(proxyProtocolV2Signature))
+	if bytes.Equal(sig, proxyProtocolV2Signature) {
+		return readProxyHeaderV2(r)
+	}
+	if bytes.HasPrefix(sig, []byte("PROXY ")) {
+		return readProxyHeaderV1(r)
+	}
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func readProxyHeaderV1(r *bufio.Reader) (*ProxyHeader, error) {
+	line := make([]byte, 0, proxyProtocolV1MaxLength)
+	for len(line) < proxyProtocolV1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, ErrProxyProtocolHeaderInvalid
+	}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return &ProxyHeader{Version: 1, Local: true}, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, ErrProxyProtocolHeaderInvalid
+	}
+	src, err := parseProxyAddr(fields[1], fields[2], fields[4])
+	if err != nil {
+		return nil, err
+	}
+	dst, err := parseProxyAddr(fields[1], fields[3], fields[5])
+	if err != nil {
+		return nil, err
+	}
+	return &ProxyHeader{Version: 1, SourceAddr: src, DestinationAddr: dst}, nil
+}
+
+func parseProxyAddr(protocol, ip, port string) (net.Addr, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil || (protocol == "TCP4") != (addr.To4() != nil) {
+		return nil, ErrProxyProtocolHeaderInvalid
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, ErrProxyProtocolHeaderInvalid
+	}
+	return &net.TCPAddr{IP: addr, Port: int(p)}, nil
+}
+
+func readProxyHeaderV2(r *bufio.Reader) (*ProxyHeader, error) {
+	head := make([]byte, 16)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+	if head[12]>>4 != 2 {
+		return nil, fmt.Errorf("%w: unsupported version", ErrProxyProtocolHeaderInvalid)
+	}
+	command := head[12] & 0x0f
+	family := head[13]
+	payload := make([]byte, binary.BigEndian.Uint16(head[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	h := &ProxyHeader{Version: 2}
+	switch command {
+	case 0x0: // LOCAL
+		h.Local = true
+		return h, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("%w: unsupported command", ErrProxyProtocolHeaderInvalid)
+	}
+
+	switch family {
+	case 0x11, 0x12: // TCP over IPv4, UDP over IPv4
+		if len(payload) < 12 {
+			return nil, ErrProxyProtocolHeaderInvalid
+		}
+		h.SourceAddr = &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}
+		h.DestinationAddr = &net.TCPAddr{IP: net.IP(payload[4:8]), Port: int(binary.BigEndian.Uint16(payload[10:12]))}
+	case 0x21, 0x22: // TCP over IPv6, UDP over IPv6
+		if len(payload) < 36 {
+			return nil, ErrProxyProtocolHeaderInvalid
+		}
+		h.SourceAddr = &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}
+		h.DestinationAddr = &net.TCPAddr{IP: net.IP(payload[16:32]), Port: int(binary.BigEndian.Uint16(payload[34:36]))}
+	default:
+		// unix sockets and unspecified family, addresses are not usable as TCP addresses
+		h.Local = true
+	}
+	return h, nil
+}
@@ -0,0 +1,222 @@
+package echo
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadProxyHeader(t *testing.T) {
+	v2IPv4 := "\r\n\r\n\x00\r\nQUIT\n" + "\x21\x11\x00\x0c" +
+		"\xc0\x00\x02\x01" + "\x0a\x00\x00\x01" + "\x1f\x90" + "\x01\xbb"
+	v2IPv6 := "\r\n\r\n\x00\r\nQUIT\n" + "\x21\x21\x00\x24" +
+		"\x20\x01\x0d\xb8\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01" +
+		"\x20\x01\x0d\xb8\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02" + "\x1f\x90" + "\x01\xbb"
+	v2Local := "\r\n\r\n\x00\r\nQUIT\n" + "\x20\x00\x00\x00"
+
+	var testCases = []struct {
+		name         string
+		given        string
+		expectHeader *ProxyHeader
+		expectRest   string
+		expectError  string
+	}{
+		{
+			name:       "ok, v1 tcp4",
+			given:      "PROXY TCP4 192.0.2.1 10.0.0.1 8080 443\r\nGET /",
+			expectRest: "GET /",
+			expectHeader: &ProxyHeader{
+				Version:         1,
+				SourceAddr:      &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 8080},
+				DestinationAddr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 443},
+			},
+		},
+		{
+			name:       "ok, v1 tcp6",
+			given:      "PROXY TCP6 2001:db8::1 2001:db8::2 8080 443\r\n",
+			expectRest: "",
+			expectHeader: &ProxyHeader{
+				Version:         1,
+				SourceAddr:      &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 8080},
+				DestinationAddr: &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443},
+			},
+		},
+		{
+			name:         "ok, v1 unknown",
+			given:        "PROXY UNKNOWN\r\nGET /",
+			expectRest:   "GET /",
+			expectHeader: &ProxyHeader{Version: 1, Local: true},
+		},
+		{
+			name:       "ok, v2 ipv4",
+			given:      v2IPv4 + "GET /",
+			expectRest: "GET /",
+			expectHeader: &ProxyHeader{
+				Version:         2,
+				SourceAddr:      &net.TCPAddr{IP: net.IP{192, 0, 2, 1}, Port: 8080},
+				DestinationAddr: &net.TCPAddr{IP: net.IP{10, 0, 0, 1}, Port: 443},
+			},
+		},
+		{
+			name:       "ok, v2 ipv6",
+			given:      v2IPv6,
+			expectRest: "",
+			expectHeader: &ProxyHeader{
+				Version:         2,
+				SourceAddr:      &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 8080},
+				DestinationAddr: &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443},
+			},
+		},
+		{
+			name:         "ok, v2 local",
+			given:        v2Local + "GET /",
+			expectRest:   "GET /",
+			expectHeader: &ProxyHeader{Version: 2, Local: true},
+		},
+		{
+			name:       "ok, no header",
+			given:      "GET / HTTP/1.1\r\n",
+			expectRest: "GET / HTTP/1.1\r\n",
+		},
+		{
+			name:        "nok, v1 invalid address",
+			given:       "PROXY TCP4 2001:db8::1 10.0.0.1 8080 443\r\n",
+			expectError: "proxy protocol: invalid header",
+		},
+		{
+			name:        "nok, v1 too long",
+			given:       "PROXY TCP4 " + strings.Repeat("1", 100) + "\r\n",
+			expectError: "proxy protocol: invalid header",
+		},
+		{
+			name:        "nok, v2 unsupported command",
+			given:       "\r\n\r\n\x00\r\nQUIT\n" + "\x22\x11\x00\x00",
+			expectError: "proxy protocol: invalid header: unsupported command",
+		},
+		{
+			name:        "nok, v2 truncated",
+			given:       "\r\n\r\n\x00\r\nQUIT\n" + "\x21\x11\x00\x0c\xc0",
+			expectError: "unexpected EOF",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tc.given))
+			h, err := readProxyHeader(r)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectHeader, h)
+			rest, _ := ioutil.ReadAll(r)
+			assert.Equal(t, tc.expectRest, string(rest))
+		})
+	}
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.ListenerWrapper = ProxyProtocolListener
+	e.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, c.RealIP())
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.Start("127.0.0.1:0")
+	}()
+	assert.NoError(t, waitForServerStart(e, errCh, false))
+	defer e.Close()
+
+	request := func(header string) string {
+		conn, err := net.Dial("tcp", e.ListenerAddr().String())
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(time.Second))
+		_, err = conn.Write([]byte(header + "GET / HTTP/1.1\r\nHost: example.com\r\nConnection: close\r\n\r\n"))
+		assert.NoError(t, err)
+		res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		return string(body)
+	}
+
+	assert.Equal(t, "192.0.2.1", request("PROXY TCP4 192.0.2.1 10.0.0.1 8080 443\r\n"))
+	assert.Equal(t, "127.0.0.1", request(""))
+}
+
+func TestProxyProtocolConfig_Wrap(t *testing.T) {
+	_, trusted, _ := net.ParseCIDR("10.0.0.0/8")
+	var testCases = []struct {
+		name         string
+		givenConfig  ProxyProtocolConfig
+		whenData     string
+		expectRemote string
+		expectError  string
+	}{
+		{
+			name:         "ok, header from trusted proxy",
+			whenData:     "PROXY TCP4 192.0.2.1 10.0.0.1 8080 443\r\nGET /",
+			expectRemote: "192.0.2.1:8080",
+		},
+		{
+			name:         "ok, header from untrusted proxy is not parsed",
+			givenConfig:  ProxyProtocolConfig{TrustedProxies: []*net.IPNet{trusted}},
+			whenData:     "PROXY TCP4 192.0.2.1 10.0.0.1 8080 443\r\nGET /",
+			expectRemote: "127.0.0.1",
+		},
+		{
+			name:        "nok, header required",
+			givenConfig: ProxyProtocolConfig{Required: true},
+			whenData:    "GET / HTTP/1.1\r\n",
+			expectError: "proxy protocol: header missing",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if !assert.NoError(t, err) {
+				return
+			}
+			l := tc.givenConfig.Wrap(ln)
+			defer l.Close()
+
+			go func() {
+				conn, err := net.Dial("tcp", ln.Addr().String())
+				if err == nil {
+					conn.Write([]byte(tc.whenData))
+					time.Sleep(50 * time.Millisecond)
+					conn.Close()
+				}
+			}()
+
+			conn, err := l.Accept()
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+
+			_, err = conn.Read(make([]byte, 1))
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(conn.RemoteAddr().String(), tc.expectRemote))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// MemoryCounter is in-memory usage counter. Usage is lost on restart and is not shared between instances so it
+	// is suitable for single instance deployments and tests.
+	MemoryCounter struct {
+		mutex    sync.Mutex
+		counters map[string]*memoryCount
+		clock    echo.Clock
+	}
+
+	memoryCount struct {
+		usage     Usage
+		expiresAt time.Time
+	}
+)
+
+// NewMemoryCounter creates new in-memory usage counter.
+func NewMemoryCounter() *MemoryCounter {
+	return newMemoryCounter(echo.SystemClock)
+}
+
+func newMemoryCounter(clock echo.Clock) *MemoryCounter {
+	return &MemoryCounter{counters: map[string]*memoryCount{}, clock: clock}
+}
+
+// Add adds requests and bytes to usage stored under key. Expired counters are removed when new counter is created.
+func (m *MemoryCounter) Add(ctx context.Context, key string, requests, bytes int64, expiresAt time.Time) (Usage, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	c, ok := m.counters[key]
+	if !ok {
+		c = &memoryCount{}
+		now := m.clock.Now()
+		for k, v := range m.counters {
+			if !now.Before(v.expiresAt) {
+				delete(m.counters, k)
+			}
+		}
+		m.counters[key] = c
+	}
+	c.usage.Requests += requests
+	c.usage.Bytes += bytes
+	if expiresAt.After(c.expiresAt) {
+		c.expiresAt = expiresAt
+	}
+	return c.usage, nil
+}
+
+// Get returns usage stored under key.
+func (m *MemoryCounter) Get(ctx context.Context, key string) (Usage, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if c, ok := m.counters[key]; ok {
+		return c.usage, nil
+	}
+	return Usage{}, nil
+}
@@ -0,0 +1,182 @@
+package quota
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type (
+	// MiddlewareConfig defines the config for quota middleware and usage handler.
+	MiddlewareConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper middleware.Skipper
+
+		// Tenant returns tenant of the request, i.e. API key or ID of customer account set to context by
+		// authentication middleware.
+		// Optional. Default value reads "X-API-Key" header.
+		Tenant func(c echo.Context) (string, error)
+
+		// ErrorHandler is called when tenant is missing or quota is exceeded. Exceeded quota is reported as
+		// `*middleware.RateLimitProblem` wrapping ErrQuotaExceeded. Return value is returned by the middleware.
+		// Optional. Default value sends problem as 429 `application/problem+json` response and returns other
+		// errors.
+		ErrorHandler func(c echo.Context, err error) error
+
+		// Problem customizes problem details of exceeded quota, i.e. translates them or adds link to upgrade of
+		// the plan of the tenant.
+		// Optional.
+		Problem middleware.RateLimitProblemFunc
+	}
+)
+
+// Usage headers set by middleware. Values describe request quota with the least remaining requests.
+const (
+	// HeaderQuotaLimit is maximum number of requests in quota period.
+	HeaderQuotaLimit = "X-Quota-Limit"
+	// HeaderQuotaRemaining is number of remaining requests in quota period.
+	HeaderQuotaRemaining = "X-Quota-Remaining"
+	// HeaderQuotaReset is number of seconds until quota period ends.
+	HeaderQuotaReset = "X-Quota-Reset"
+)
+
+// ErrMissingTenant is returned when request has no tenant.
+var ErrMissingTenant = echo.NewHTTPError(http.StatusUnauthorized, "missing api key")
+
+// DefaultMiddlewareConfig is the default quota middleware config.
+var DefaultMiddlewareConfig = MiddlewareConfig{
+	Skipper: middleware.DefaultSkipper,
+	Tenant: func(c echo.Context) (string, error) {
+		return c.Request().Header.Get("X-API-Key"), nil
+	},
+	ErrorHandler: func(c echo.Context, err error) error {
+		if p, ok := err.(*middleware.RateLimitProblem); ok {
+			return p.Send(c)
+		}
+		return err
+	},
+}
+
+// Middleware returns a middleware which counts requests and transferred bytes of tenants and rejects requests with
+// 429 when any quota of the tenant is exceeded. Bytes are counted after the response is sent so request exceeding
+// bandwidth quota is still completed and following requests are rejected.
+func (m *Manager) Middleware(config MiddlewareConfig) echo.MiddlewareFunc {
+	config = config.withDefaults()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			tenant, err := config.Tenant(c)
+			if err != nil {
+				return config.ErrorHandler(c, err)
+			}
+			if tenant == "" {
+				return config.ErrorHandler(c, ErrMissingTenant)
+			}
+
+			req := c.Request()
+			ctx := req.Context()
+			usages, err := m.acquire(ctx, tenant)
+			now := m.config.Clock.Now()
+			setUsageHeaders(c, usages, now)
+			if err == ErrQuotaExceeded {
+				p := exceededProblem(usages[0], now)
+				if config.Problem != nil {
+					config.Problem(c, tenant, p)
+				}
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.FormatInt(p.RetryAfter, 10))
+				return config.ErrorHandler(c, p)
+			}
+			if err != nil {
+				return err
+			}
+
+			err = next(c)
+
+			bytes := c.Response().Size
+			if req.ContentLength > 0 {
+				bytes += req.ContentLength
+			}
+			if bytes > 0 {
+				if bErr := m.addBytes(ctx, tenant, usages, bytes); bErr != nil {
+					c.Logger().Error(bErr)
+				}
+			}
+			return err
+		}
+	}
+}
+
+// UsageHandler returns handler responding with usage of tenant of the request (`[]PeriodUsage` JSON).
+func (m *Manager) UsageHandler(config MiddlewareConfig) echo.HandlerFunc {
+	config = config.withDefaults()
+	return func(c echo.Context) error {
+		tenant, err := config.Tenant(c)
+		if err != nil {
+			return config.ErrorHandler(c, err)
+		}
+		if tenant == "" {
+			return config.ErrorHandler(c, ErrMissingTenant)
+		}
+		usages, err := m.Usage(c.Request().Context(), tenant)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, usages)
+	}
+}
+
+func (config MiddlewareConfig) withDefaults() MiddlewareConfig {
+	if config.Skipper == nil {
+		config.Skipper = DefaultMiddlewareConfig.Skipper
+	}
+	if config.Tenant == nil {
+		config.Tenant = DefaultMiddlewareConfig.Tenant
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultMiddlewareConfig.ErrorHandler
+	}
+	return config
+}
+
+// exceededProblem describes exceeded quota. Policy is period and kind of the quota, i.e. "daily-requests".
+func exceededProblem(u PeriodUsage, now time.Time) *middleware.RateLimitProblem {
+	policy, limit := string(u.Limit.Period)+"-bytes", u.Limit.Bytes
+	if u.Limit.Requests > 0 && u.Usage.Requests > u.Limit.Requests {
+		policy, limit = string(u.Limit.Period)+"-requests", u.Limit.Requests
+	}
+	return middleware.NewRateLimitProblem(ErrQuotaExceeded, policy, limit, 0, u.Reset, now)
+}
+
+// setUsageHeaders sets usage headers of request quota with the least remaining requests.
+func setUsageHeaders(c echo.Context, usages []PeriodUsage, now time.Time) {
+	var tightest *PeriodUsage
+	remaining := int64(0)
+	for i := range usages {
+		u := &usages[i]
+		if u.Limit.Requests <= 0 {
+			continue
+		}
+		r := u.Limit.Requests - u.Usage.Requests
+		if r < 0 {
+			r = 0
+		}
+		if tightest == nil || r < remaining {
+			tightest = u
+			remaining = r
+		}
+	}
+	if tightest == nil {
+		return
+	}
+	h := c.Response().Header()
+	h.Set(HeaderQuotaLimit, strconv.FormatInt(tightest.Limit.Requests, 10))
+	h.Set(HeaderQuotaRemaining, strconv.FormatInt(remaining, 10))
+	h.Set(HeaderQuotaReset, strconv.FormatInt(int64(tightest.Reset.Sub(now)/time.Second), 10))
+}
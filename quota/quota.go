@@ -0,0 +1,210 @@
+/*
+Package quota enforces daily and monthly request and bandwidth quotas of API tenants (i.e. API keys or customer
+accounts) and reports their usage for billing.
+
+Usage is kept by `Counter`. `MemoryCounter` is suitable for single instance deployments and tests, counters shared
+by instances can be implemented on top of Redis (`HINCRBY` with `EXPIREAT`) or SQL (upsert of row per tenant and
+period).
+
+Example:
+
+	quotas := quota.New(quota.Config{
+		Limits: func(ctx context.Context, tenant string) ([]quota.Limit, error) {
+			return []quota.Limit{
+				{Period: quota.Daily, Requests: 10000},
+				{Period: quota.Monthly, Requests: 200000, Bytes: 10 << 30},
+			}, nil
+		},
+	})
+
+	api := e.Group("/api", quotas.Middleware(quota.MiddlewareConfig{}))
+	api.GET("/usage", quotas.UsageHandler(quota.MiddlewareConfig{}))
+*/
+package quota
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Config defines the config for quota Manager.
+	Config struct {
+		// Limits returns quotas of tenant. Tenant without limits is not restricted but its usage is still counted.
+		// Required.
+		Limits func(ctx context.Context, tenant string) ([]Limit, error)
+
+		// Counter keeps usage of tenants.
+		// Optional. Default value is in-memory counter using Clock.
+		Counter Counter
+
+		// Location is time zone in which days and months start.
+		// Optional. Default value UTC.
+		Location *time.Location
+
+		// Clock is source of current time.
+		// Optional. Default value `echo.SystemClock`.
+		Clock echo.Clock
+	}
+
+	// Period is length of quota window.
+	Period string
+
+	// Limit is quota of tenant for one period.
+	Limit struct {
+		// Period of the quota.
+		Period Period `json:"period"`
+		// Requests is maximum number of requests in period. Value 0 means unlimited.
+		Requests int64 `json:"requests,omitempty"`
+		// Bytes is maximum number of transferred (request and response body) bytes in period. Value 0 means
+		// unlimited.
+		Bytes int64 `json:"bytes,omitempty"`
+	}
+
+	// Usage is number of requests and bytes used in period.
+	Usage struct {
+		Requests int64 `json:"requests"`
+		Bytes    int64 `json:"bytes"`
+	}
+
+	// PeriodUsage is usage of tenant in current window of limit period.
+	PeriodUsage struct {
+		Limit Limit `json:"limit"`
+		Usage Usage `json:"usage"`
+		// Start is time when current window started.
+		Start time.Time `json:"start"`
+		// Reset is time when current window ends and usage is reset.
+		Reset time.Time `json:"reset"`
+	}
+
+	// Counter is storage of usage counters.
+	Counter interface {
+		// Add atomically adds requests and bytes to usage stored under key and returns usage after the addition.
+		// Counter can be removed after expiresAt.
+		Add(ctx context.Context, key string, requests, bytes int64, expiresAt time.Time) (Usage, error)
+		// Get returns usage stored under key or zero usage when there is none.
+		Get(ctx context.Context, key string) (Usage, error)
+	}
+
+	// Manager enforces quotas and reports usage.
+	Manager struct {
+		config Config
+	}
+)
+
+// Periods
+const (
+	Daily   Period = "daily"
+	Monthly Period = "monthly"
+)
+
+// ErrQuotaExceeded is returned when request or bandwidth quota of tenant is exceeded.
+var ErrQuotaExceeded = echo.NewHTTPError(http.StatusTooManyRequests, "quota exceeded")
+
+// New creates new quota Manager with config.
+func New(config Config) *Manager {
+	if config.Limits == nil {
+		panic("echo: quota requires limits function")
+	}
+	// Defaults
+	if config.Clock == nil {
+		config.Clock = echo.SystemClock
+	}
+	if config.Counter == nil {
+		config.Counter = newMemoryCounter(config.Clock)
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	return &Manager{config: config}
+}
+
+// Usage returns usage of tenant in current windows of its limits.
+func (m *Manager) Usage(ctx context.Context, tenant string) ([]PeriodUsage, error) {
+	limits, err := m.config.Limits(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	now := m.config.Clock.Now()
+	result := make([]PeriodUsage, 0, len(limits))
+	for _, l := range limits {
+		start, reset, err := m.window(l.Period, now)
+		if err != nil {
+			return nil, err
+		}
+		usage, err := m.config.Counter.Get(ctx, counterKey(tenant, l.Period, start))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, PeriodUsage{Limit: l, Usage: usage, Start: start, Reset: reset})
+	}
+	return result, nil
+}
+
+// acquire counts request of tenant. When any quota is exceeded the request is not counted and ErrQuotaExceeded is
+// returned with usage of exceeded period.
+func (m *Manager) acquire(ctx context.Context, tenant string) ([]PeriodUsage, error) {
+	limits, err := m.config.Limits(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	now := m.config.Clock.Now()
+	result := make([]PeriodUsage, 0, len(limits))
+	for _, l := range limits {
+		usage := Usage{}
+		start, reset, err := m.window(l.Period, now)
+		if err == nil {
+			usage, err = m.config.Counter.Add(ctx, counterKey(tenant, l.Period, start), 1, 0, reset)
+		}
+		if err != nil {
+			m.rollback(ctx, tenant, result)
+			return nil, err
+		}
+		pu := PeriodUsage{Limit: l, Usage: usage, Start: start, Reset: reset}
+		result = append(result, pu)
+		if (l.Requests > 0 && usage.Requests > l.Requests) || (l.Bytes > 0 && usage.Bytes >= l.Bytes) {
+			m.rollback(ctx, tenant, result)
+			return []PeriodUsage{pu}, ErrQuotaExceeded
+		}
+	}
+	return result, nil
+}
+
+// rollback removes request counted by acquire.
+func (m *Manager) rollback(ctx context.Context, tenant string, usages []PeriodUsage) {
+	for _, u := range usages {
+		m.config.Counter.Add(ctx, counterKey(tenant, u.Limit.Period, u.Start), -1, 0, u.Reset)
+	}
+}
+
+// addBytes counts transferred bytes of tenant.
+func (m *Manager) addBytes(ctx context.Context, tenant string, usages []PeriodUsage, bytes int64) error {
+	for _, u := range usages {
+		if _, err := m.config.Counter.Add(ctx, counterKey(tenant, u.Limit.Period, u.Start), 0, bytes, u.Reset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// window returns start and end of period window containing now.
+func (m *Manager) window(period Period, now time.Time) (time.Time, time.Time, error) {
+	now = now.In(m.config.Location)
+	switch period {
+	case Daily:
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, m.config.Location)
+		return start, start.AddDate(0, 0, 1), nil
+	case Monthly:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, m.config.Location)
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("quota: unknown period %q", period)
+}
+
+func counterKey(tenant string, period Period, start time.Time) string {
+	return tenant + ":" + string(period) + ":" + start.Format("20060102")
+}
@@ -0,0 +1,198 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Middleware(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 30, 23, 0, 0, 0, time.UTC))
+	quotas := New(Config{
+		Clock: clock,
+		Limits: func(ctx context.Context, tenant string) ([]Limit, error) {
+			if tenant == "free" {
+				return []Limit{{Period: Daily, Requests: 2}, {Period: Monthly, Requests: 10, Bytes: 100}}, nil
+			}
+			return nil, nil
+		},
+	})
+
+	e := echo.New()
+	e.POST("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "0123456789")
+	}, quotas.Middleware(MiddlewareConfig{}))
+	e.GET("/usage", quotas.UsageHandler(MiddlewareConfig{}))
+
+	request := func(tenant string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		if tenant != "" {
+			req.Header.Set("X-API-Key", tenant)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("free", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(HeaderQuotaLimit))
+	assert.Equal(t, "1", rec.Header().Get(HeaderQuotaRemaining))
+	assert.Equal(t, "3600", rec.Header().Get(HeaderQuotaReset))
+
+	assert.Equal(t, http.StatusOK, request("free", "").Code)
+	rec = request("free", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "daily quota exceeded")
+	assert.Equal(t, "0", rec.Header().Get(HeaderQuotaRemaining))
+	assert.Equal(t, "3600", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Equal(t, echo.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Too Many Requests","status":429,"detail":"quota exceeded",
+		"policy":"daily-requests","limit":2,"remaining":0,"reset":"2021-01-31T00:00:00Z","retry_after":3600}`, rec.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, request("", "").Code)
+	assert.Equal(t, http.StatusOK, request("unlimited", "").Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/usage", nil)
+	req.Header.Set("X-API-Key", "free")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	usages := []PeriodUsage{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &usages))
+	require.Len(t, usages, 2)
+	assert.Equal(t, Usage{Requests: 2, Bytes: 20}, usages[0].Usage, "rejected request is not counted")
+	assert.Equal(t, Usage{Requests: 2, Bytes: 20}, usages[1].Usage)
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), usages[1].Start)
+	assert.Equal(t, time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), usages[1].Reset)
+
+	// next day, monthly bandwidth quota is exceeded by large request
+	clock.Advance(time.Hour)
+	rec = request("free", strings.Repeat("x", 100))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = request("free", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "monthly bandwidth quota exceeded")
+	assert.Contains(t, rec.Body.String(), `"policy":"monthly-bytes","limit":100,`)
+
+	// next month
+	clock.Advance(24 * time.Hour)
+	assert.Equal(t, http.StatusOK, request("free", "").Code)
+}
+
+func TestManager_MiddlewareProblem(t *testing.T) {
+	quotas := New(Config{
+		Clock: echotest.NewClock(time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)),
+		Limits: func(ctx context.Context, tenant string) ([]Limit, error) {
+			return []Limit{{Period: Monthly, Requests: 1}}, nil
+		},
+	})
+	var handlerErr error
+	e := echo.New()
+	e.Use(quotas.Middleware(MiddlewareConfig{
+		Problem: func(c echo.Context, tenant string, p *middleware.RateLimitProblem) {
+			p.Type = "https://example.com/plans?tenant=" + tenant
+		},
+		ErrorHandler: func(c echo.Context, err error) error {
+			handlerErr = err
+			return DefaultMiddlewareConfig.ErrorHandler(c, err)
+		},
+	}))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "jon")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	assert.Equal(t, http.StatusOK, get().Code)
+	rec := get()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"type":"https://example.com/plans?tenant=jon"`)
+	assert.Equal(t, "2635200", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.True(t, errors.Is(handlerErr, ErrQuotaExceeded))
+}
+
+func TestManager_Usage(t *testing.T) {
+	quotas := New(Config{
+		Location: time.FixedZone("UTC+2", 2*3600),
+		Clock:    echotest.NewClock(time.Date(2021, 1, 1, 23, 0, 0, 0, time.UTC)),
+		Limits: func(ctx context.Context, tenant string) ([]Limit, error) {
+			if tenant == "invalid" {
+				return []Limit{{Period: "weekly"}}, nil
+			}
+			return []Limit{{Period: Daily, Requests: 10}}, nil
+		},
+	})
+
+	usages, err := quotas.Usage(context.Background(), "jon")
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	assert.Equal(t, "2021-01-02T00:00:00+02:00", usages[0].Start.Format(time.RFC3339))
+	assert.Equal(t, "2021-01-03T00:00:00+02:00", usages[0].Reset.Format(time.RFC3339))
+
+	_, err = quotas.Usage(context.Background(), "invalid")
+	assert.EqualError(t, err, `quota: unknown period "weekly"`)
+}
+
+func TestManager_MiddlewareCounterError(t *testing.T) {
+	quotas := New(Config{
+		Counter: failingCounter{},
+		Limits: func(ctx context.Context, tenant string) ([]Limit, error) {
+			return []Limit{{Period: Daily, Requests: 10}}, nil
+		},
+	})
+	e := echo.New()
+	e.Use(quotas.Middleware(MiddlewareConfig{}))
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "jon")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestMemoryCounter_removesExpired(t *testing.T) {
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	counter := newMemoryCounter(clock)
+	ctx := context.Background()
+
+	_, err := counter.Add(ctx, "a", 1, 0, clock.Now().Add(time.Hour))
+	require.NoError(t, err)
+	clock.Advance(time.Hour)
+	_, err = counter.Add(ctx, "b", 1, 0, clock.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	assert.Len(t, counter.counters, 1)
+	usage, err := counter.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{}, usage)
+}
+
+func TestNew_panics(t *testing.T) {
+	assert.Panics(t, func() { New(Config{}) })
+}
+
+type failingCounter struct{}
+
+func (failingCounter) Add(ctx context.Context, key string, requests, bytes int64, expiresAt time.Time) (Usage, error) {
+	return Usage{}, errors.New("counter unavailable")
+}
+
+func (failingCounter) Get(ctx context.Context, key string) (Usage, error) {
+	return Usage{}, errors.New("counter unavailable")
+}
@@ -0,0 +1,6 @@
+// +build race
+
+package echo
+
+// raceEnabled reports whether tests are built with the race detector, sync.Pool drops pooled items randomly then.
+const raceEnabled = true
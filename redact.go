@@ -0,0 +1,330 @@
+package echo
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/gommon/log"
+)
+
+// Redactor removes personal data and secrets from values written to logs, body dumps, audit events and error
+// reports. It is used by `middleware.Logger`, `middleware.RequestLogger`, `middleware.BodyDump`,
+// `middleware.Recover`, `middleware.Impersonate` audit events and `Echo#RegisterLogKey()` values through
+// `Echo#Redactor`. Nil Redactor returns values unchanged.
+type Redactor struct {
+	// Fields are names of form, query and JSON fields, cookies and log keys whose values are redacted. Names are
+	// split into words at "-", "_", "." and camel case boundaries and match when consecutive words of the name
+	// equal the field case-insensitively, so "password" also matches "new_password", "X-Password" and
+	// "passwordHint" and "apikey" matches "X-API-Key", but "token" does not match "tokenizer".
+	Fields []string
+
+	// Headers are names of headers whose values are redacted in addition to headers matching Fields.
+	Headers []string
+
+	// Patterns match sensitive parts of free text (paths, query values, error messages, bodies) which are
+	// replaced.
+	Patterns []*regexp.Regexp
+
+	// CardNumbers enables replacing of payment card numbers in free text. Sequences of 13 to 19 digits,
+	// optionally grouped with spaces or dashes, are replaced when they pass the Luhn check, so other long
+	// numbers (order IDs, timestamps) are kept.
+	CardNumbers bool
+
+	// Replacement is the value redacted parts are replaced with.
+	// Optional. Default value "[REDACTED]".
+	Replacement string
+}
+
+// DefaultRedactor is the default redactor used by `Echo#Redactor`. It redacts common credential and payment
+// fields, authentication headers and cookies, e-mail addresses, card numbers and bearer tokens.
+var DefaultRedactor = Redactor{
+	Fields: []string{
+		"password", "passwd", "secret", "token", "apikey", "accesskey", "privatekey", "authorization",
+		"credential", "cookie", "cardnumber", "creditcard", "cvv", "cvc", "iban", "ssn", "email",
+		"phone",
+	},
+	Headers: []string{
+		HeaderAuthorization, "Proxy-Authorization", HeaderCookie, HeaderSetCookie, "X-Api-Key",
+	},
+	Patterns: []*regexp.Regexp{
+		// e-mail addresses
+		regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		// bearer tokens in free text
+		regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+	},
+	CardNumbers: true,
+}
+
+// cardNumberPattern matches candidates of payment card numbers, optionally grouped with spaces or dashes.
+var cardNumberPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+
+const defaultRedactReplacement = "[REDACTED]"
+
+// Sensitive reports whether values of field (form, query, JSON field, cookie or log key) with the name are
+// redacted.
+func (r *Redactor) Sensitive(name string) bool {
+	if r == nil {
+		return false
+	}
+	words := redactWords(name)
+	for _, f := range r.Fields {
+		if f = normalizeRedactName(f); f != "" && containsRedactWords(words, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// Field returns value of field with the name as it should appear in logs.
+func (r *Redactor) Field(name, value string) string {
+	if r == nil {
+		return value
+	}
+	if r.Sensitive(name) {
+		return r.replacement()
+	}
+	return r.Text(value)
+}
+
+// Header returns value of header with the name as it should appear in logs.
+func (r *Redactor) Header(name, value string) string {
+	if r == nil {
+		return value
+	}
+	for _, h := range r.Headers {
+		if strings.EqualFold(h, name) {
+			return r.replacement()
+		}
+	}
+	return r.Field(name, value)
+}
+
+// Text replaces parts of free text matching Patterns.
+func (r *Redactor) Text(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, p := range r.Patterns {
+		s = p.ReplaceAllString(s, r.replacement())
+	}
+	if r.CardNumbers {
+		s = cardNumberPattern.ReplaceAllStringFunc(s, func(number string) string {
+			if luhnValid(number) {
+				return r.replacement()
+			}
+			return number
+		})
+	}
+	return s
+}
+
+// luhnValid reports whether digits of the number (spaces and dashes are ignored) pass the Luhn check.
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := number[i]
+		if d < '0' || d > '9' {
+			continue
+		}
+		n := int(d - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Query redacts URL encoded query string (or form body) keeping its order and encoding.
+func (r *Redactor) Query(query string) string {
+	if r == nil || query == "" {
+		return query
+	}
+	parts := strings.Split(query, "&")
+	for i, part := range parts {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, err := url.QueryUnescape(kv[0])
+		if err != nil {
+			key = kv[0]
+		}
+		value, err := url.QueryUnescape(kv[1])
+		if err != nil {
+			value = kv[1]
+		}
+		if redacted := r.Field(key, value); redacted != value {
+			parts[i] = kv[0] + "=" + url.QueryEscape(redacted)
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// URI redacts request URI (path and query string).
+func (r *Redactor) URI(uri string) string {
+	if r == nil {
+		return uri
+	}
+	i := strings.IndexByte(uri, '?')
+	if i < 0 {
+		return r.Text(uri)
+	}
+	return r.Text(uri[:i]) + "?" + r.Query(uri[i+1:])
+}
+
+// Body redacts request or response body of the content type. JSON bodies have values of sensitive fields
+// replaced, form bodies are redacted as query string and Patterns are applied to other text. Body without
+// anything to redact is returned as it is.
+func (r *Redactor) Body(contentType string, body []byte) []byte {
+	if r == nil || len(body) == 0 {
+		return body
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		d := json.NewDecoder(bytes.NewReader(body))
+		d.UseNumber()
+		var v interface{}
+		if err := d.Decode(&v); err == nil {
+			redacted := r.Value(v)
+			if reflect.DeepEqual(v, redacted) {
+				return body
+			}
+			if b, err := json.Marshal(redacted); err == nil {
+				return b
+			}
+		}
+	case mediaType == MIMEApplicationForm:
+		return redactedBytes(body, r.Query(string(body)))
+	}
+	return redactedBytes(body, r.Text(string(body)))
+}
+
+// redactedBytes returns original body when redaction did not change it.
+func redactedBytes(body []byte, redacted string) []byte {
+	if redacted == string(body) {
+		return body
+	}
+	return []byte(redacted)
+}
+
+// Value redacts value of a log entry: strings are redacted with Patterns and values of sensitive keys of maps
+// (i.e. `log.JSON`, decoded JSON) are replaced recursively. Other values are returned as they are.
+func (r *Redactor) Value(v interface{}) interface{} {
+	if r == nil {
+		return v
+	}
+	switch v := v.(type) {
+	case string:
+		return r.Text(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, e := range v {
+			redacted[i] = r.Value(e)
+		}
+		return redacted
+	case map[string]interface{}:
+		return r.redactMap(v)
+	case Map:
+		return Map(r.redactMap(v))
+	case log.JSON:
+		return log.JSON(r.redactMap(v))
+	case http.Header:
+		redacted := make(http.Header, len(v))
+		for name, values := range v {
+			for _, value := range values {
+				redacted[name] = append(redacted[name], r.Header(name, value))
+			}
+		}
+		return redacted
+	}
+	return v
+}
+
+func (r *Redactor) redactMap(m map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if r.Sensitive(k) {
+			redacted[k] = r.replacement()
+			continue
+		}
+		redacted[k] = r.Value(v)
+	}
+	return redacted
+}
+
+func (r *Redactor) replacement() string {
+	if r.Replacement == "" {
+		return defaultRedactReplacement
+	}
+	return r.Replacement
+}
+
+// redactWords splits name into lower case words at "-", "_", "." and camel case boundaries ("XAPIKey" is split
+// into "xapi" and "key").
+func redactWords(name string) []string {
+	var words []string
+	start := 0
+	for i := 0; i <= len(name); i++ {
+		if i < len(name) {
+			c := name[i]
+			if c != '-' && c != '_' && c != '.' {
+				if i == start || !isUpper(c) {
+					continue
+				}
+				prev := name[i-1]
+				if !isUpper(prev) || (i+1 < len(name) && isLower(name[i+1])) {
+					words = append(words, strings.ToLower(name[start:i]))
+					start = i
+				}
+				continue
+			}
+		}
+		if i > start {
+			words = append(words, strings.ToLower(name[start:i]))
+		}
+		start = i + 1
+	}
+	return words
+}
+
+// containsRedactWords reports whether consecutive words joined together equal field.
+func containsRedactWords(words []string, field string) bool {
+	for i := range words {
+		joined := ""
+		for _, w := range words[i:] {
+			joined += w
+			if len(joined) >= len(field) {
+				break
+			}
+		}
+		if joined == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func normalizeRedactName(name string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", "_", "", ".", "").Replace(name))
+}
@@ -0,0 +1,189 @@
+package echo
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/labstack/gommon/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRedactor(t *testing.T) {
+	r := New().Redactor
+
+	var testCases = []struct {
+		name   string
+		redact func() string
+		expect string
+	}{
+		{
+			name:   "password field",
+			redact: func() string { return r.Field("new_password", "hunter2") },
+			expect: "[REDACTED]",
+		},
+		{
+			name:   "api key field in other case",
+			redact: func() string { return r.Field("X-API-Key", "abc") },
+			expect: "[REDACTED]",
+		},
+		{
+			name:   "camel case token field",
+			redact: func() string { return r.Field("refreshToken", "abc") },
+			expect: "[REDACTED]",
+		},
+		{
+			name:   "ordinary field",
+			redact: func() string { return r.Field("username", "jon") },
+			expect: "jon",
+		},
+		{
+			name:   "field containing sensitive name within word",
+			redact: func() string { return r.Field("tokenizer", "bpe") + " " + r.Field("phoneticName", "jon") },
+			expect: "bpe jon",
+		},
+		{
+			name:   "email in ordinary field",
+			redact: func() string { return r.Field("note", "write to jon.doe+x@example.co.uk please") },
+			expect: "write to [REDACTED] please",
+		},
+		{
+			name:   "authorization header",
+			redact: func() string { return r.Header("authorization", "Basic am9uOnNlY3JldA==") },
+			expect: "[REDACTED]",
+		},
+		{
+			name:   "cookie header",
+			redact: func() string { return r.Header("Cookie", "session=abc") },
+			expect: "[REDACTED]",
+		},
+		{
+			name:   "ordinary header",
+			redact: func() string { return r.Header("User-Agent", "curl") },
+			expect: "curl",
+		},
+		{
+			name:   "card numbers",
+			redact: func() string { return r.Text("cards 4111111111111111, 4111 1111 1111 1111 and 5500-0000-0000-0004") },
+			expect: "cards [REDACTED], [REDACTED] and [REDACTED]",
+		},
+		{
+			name:   "long numbers failing luhn check are kept",
+			redact: func() string { return r.Text("order 4111111111111112 at 1609459200000000") },
+			expect: "order 4111111111111112 at 1609459200000000",
+		},
+		{
+			name:   "short numbers are kept",
+			redact: func() string { return r.Text("order 123456 of 2021") },
+			expect: "order 123456 of 2021",
+		},
+		{
+			name:   "bearer token",
+			redact: func() string { return r.Text(`upstream rejected "Bearer eyJhbGciOi.eyJzdWIi.c2ln"`) },
+			expect: `upstream rejected "[REDACTED]"`,
+		},
+		{
+			name:   "query",
+			redact: func() string { return r.Query("user=jon&password=hunter2&to=jon%40example.com&flag") },
+			expect: "user=jon&password=%5BREDACTED%5D&to=%5BREDACTED%5D&flag",
+		},
+		{
+			name:   "uri",
+			redact: func() string { return r.URI("/users/jon@example.com/reset?token=abc&page=1") },
+			expect: "/users/[REDACTED]/reset?token=%5BREDACTED%5D&page=1",
+		},
+		{
+			name: "json body",
+			redact: func() string {
+				return string(r.Body(MIMEApplicationJSONCharsetUTF8,
+					[]byte(`{"user":{"name":"jon","password":"hunter2","emails":["jon@example.com"]},"amount":1.50}`)))
+			},
+			expect: `{"amount":1.50,"user":{"emails":["[REDACTED]"],"name":"jon","password":"[REDACTED]"}}`,
+		},
+		{
+			name: "json array body",
+			redact: func() string {
+				return string(r.Body("application/problem+json", []byte(`[{"card_number":"4111"},"a@example.com"]`)))
+			},
+			expect: `[{"card_number":"[REDACTED]"},"[REDACTED]"]`,
+		},
+		{
+			name: "json body without sensitive data is kept",
+			redact: func() string {
+				return string(r.Body(MIMEApplicationJSON, []byte(`{ "name": "jon", "amount": 1.50e0 }`)))
+			},
+			expect: `{ "name": "jon", "amount": 1.50e0 }`,
+		},
+		{
+			name: "invalid json body",
+			redact: func() string {
+				return string(r.Body(MIMEApplicationJSON, []byte(`{"password":"hunter2", "to": "a@example.com"`)))
+			},
+			expect: `{"password":"hunter2", "to": "[REDACTED]"`,
+		},
+		{
+			name: "form body",
+			redact: func() string {
+				return string(r.Body(MIMEApplicationForm, []byte("username=jon&password=hunter2")))
+			},
+			expect: "username=jon&password=%5BREDACTED%5D",
+		},
+		{
+			name:   "text body",
+			redact: func() string { return string(r.Body(MIMETextPlain, []byte("contact a@example.com"))) },
+			expect: "contact [REDACTED]",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, tc.redact())
+		})
+	}
+}
+
+func TestRedactor_Value(t *testing.T) {
+	r := New().Redactor
+
+	assert.Equal(t, log.JSON{
+		"message": "failed for [REDACTED]",
+		"token":   "[REDACTED]",
+		"count":   3,
+		"nested":  map[string]interface{}{"secret": "[REDACTED]", "list": []interface{}{"[REDACTED]", 1}},
+	}, r.Value(log.JSON{
+		"message": "failed for a@example.com",
+		"token":   "abc",
+		"count":   3,
+		"nested":  map[string]interface{}{"secret": "s", "list": []interface{}{"b@example.com", 1}},
+	}))
+	assert.Equal(t, http.Header{"Authorization": {"[REDACTED]"}, "Accept": {"*/*"}},
+		r.Value(http.Header{"Authorization": {"Bearer abc"}, "Accept": {"*/*"}}))
+	err := errors.New("a@example.com")
+	assert.Equal(t, err, r.Value(err))
+}
+
+func TestRedactor_custom(t *testing.T) {
+	r := &Redactor{
+		Fields:      []string{"tenant"},
+		Headers:     []string{"X-Internal"},
+		Patterns:    []*regexp.Regexp{regexp.MustCompile(`acct-\d+`)},
+		Replacement: "***",
+	}
+
+	assert.Equal(t, "***", r.Field("Tenant-ID", "acme"))
+	assert.Equal(t, "hunter2", r.Field("password", "hunter2"))
+	assert.Equal(t, "***", r.Header("x-internal", "1"))
+	assert.Equal(t, "moved *** to a@example.com", r.Text("moved acct-42 to a@example.com"))
+}
+
+func TestRedactor_nil(t *testing.T) {
+	var r *Redactor
+
+	assert.False(t, r.Sensitive("password"))
+	assert.Equal(t, "hunter2", r.Field("password", "hunter2"))
+	assert.Equal(t, "abc", r.Header(HeaderAuthorization, "abc"))
+	assert.Equal(t, "/a?password=x", r.URI("/a?password=x"))
+	assert.Equal(t, "password=x", r.Query("password=x"))
+	assert.Equal(t, []byte(`{"password":"x"}`), r.Body(MIMEApplicationJSON, []byte(`{"password":"x"}`)))
+	assert.Equal(t, Map{"token": "x"}, r.Value(Map{"token": "x"}))
+}
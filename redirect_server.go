@@ -0,0 +1,111 @@
+package echo
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/acme"
+)
+
+const acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+// StartWithRedirect starts an HTTPS server on httpsAddr and an HTTP server on httpAddr that redirects all requests
+// to the HTTPS server. Both servers are stopped by `Echo#Close()` and `Echo#Shutdown()` and when one of them fails
+// the other one is closed too.
+//
+// When tlsConfig is nil certificates are obtained with `Echo#AutoTLSManager` and HTTP server answers its ACME
+// http-01 challenges. With custom tlsConfig requests to `/.well-known/acme-challenge/` are served by Echo routes so
+// certificates issued by external ACME client can be validated.
+//
+// Redirects keep host and request URI (as required by the HSTS preload list). GET and HEAD requests are redirected
+// with 301 status, other methods with 308 so method and body are preserved. HSTS header itself is sent by HTTPS
+// responses with `middleware.Secure` (`HSTSMaxAge`, `HSTSPreloadEnabled`).
+func (e *Echo) StartWithRedirect(httpAddr, httpsAddr string, tlsConfig *tls.Config) error {
+	e.startupMutex.Lock()
+	s := e.TLSServer
+	autoTLS := tlsConfig == nil
+	if autoTLS {
+		s.TLSConfig = e.newTLSConfig()
+		s.TLSConfig.GetCertificate = e.getAutoTLSCertificate
+		s.TLSConfig.NextProtos = appendNextProto(s.TLSConfig.NextProtos, acme.ALPNProto)
+	} else {
+		s.TLSConfig = tlsConfig.Clone()
+	}
+	e.configureTLS(httpsAddr)
+	if err := e.configureServer(s); err != nil {
+		e.startupMutex.Unlock()
+		return err
+	}
+
+	rs := e.Server
+	rs.Addr = httpAddr
+	rs.ErrorLog = e.StdLogger
+	rs.Handler = e.redirectHandler(autoTLS)
+	e.trackConnections(rs)
+	if e.Listener == nil {
+		l, err := e.listen(rs.Addr)
+		if err != nil {
+			e.startupMutex.Unlock()
+			return err
+		}
+		e.Listener = l
+	}
+	if !e.HidePort {
+		e.colorer.Printf("⇨ http redirect server started on %s\n", e.colorer.Green(e.Listener.Addr()))
+	}
+	tlsListener, listener := e.TLSListener, e.Listener
+	e.startupMutex.Unlock()
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- s.Serve(tlsListener)
+	}()
+	go func() {
+		errCh <- rs.Serve(listener)
+	}()
+
+	err := <-errCh
+	if err != http.ErrServerClosed {
+		// server failed on its own, stop the other one
+		e.Close()
+	}
+	<-errCh
+	return err
+}
+
+// redirectHandler returns handler of HTTP server started by StartWithRedirect.
+func (e *Echo) redirectHandler(autoTLS bool) http.Handler {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, acmeChallengePrefix) {
+			e.ServeHTTP(w, r)
+			return
+		}
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, e.httpsURL(r), code)
+	})
+	if autoTLS {
+		return e.AutoTLSManager.HTTPHandler(redirect)
+	}
+	return redirect
+}
+
+// httpsURL returns URL of the request on HTTPS server. Port is added to the host when HTTPS server is not listening
+// on the default port.
+func (e *Echo) httpsURL(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if addr, ok := e.TLSListenerAddr().(*net.TCPAddr); ok && addr.Port != 443 {
+		host = net.JoinHostPort(host, strconv.Itoa(addr.Port))
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return "https://" + host + r.URL.RequestURI()
+}
@@ -0,0 +1,134 @@
+package echo
+
+import (
+	stdContext "context"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startWithRedirect(t *testing.T, e *Echo, tlsConfig *tls.Config) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- e.StartWithRedirect("127.0.0.1:0", "127.0.0.1:0", tlsConfig)
+	}()
+	require.NoError(t, waitForServerStart(e, errCh, true))
+	return errCh
+}
+
+func TestEcho_StartWithRedirect(t *testing.T) {
+	cert, err := tls.LoadX509KeyPair("_fixture/certs/cert.pem", "_fixture/certs/key.pem")
+	require.NoError(t, err)
+
+	e := New()
+	e.HideBanner = true
+	e.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "secure")
+	})
+	e.GET("/.well-known/acme-challenge/:token", func(c Context) error {
+		return c.String(http.StatusOK, "token="+c.Param("token"))
+	})
+	errCh := startWithRedirect(t, e, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	httpURL := "http://" + e.ListenerAddr().String()
+	tlsAddr := e.TLSListenerAddr().String()
+
+	var testCases = []struct {
+		name             string
+		method           string
+		path             string
+		expectStatus     int
+		expectLocation   string
+		expectBodyPrefix string
+	}{
+		{
+			name:           "ok, GET is redirected with 301",
+			method:         http.MethodGet,
+			path:           "/users?page=2",
+			expectStatus:   http.StatusMovedPermanently,
+			expectLocation: "https://" + tlsAddr + "/users?page=2",
+		},
+		{
+			name:           "ok, POST is redirected with 308",
+			method:         http.MethodPost,
+			path:           "/users",
+			expectStatus:   http.StatusPermanentRedirect,
+			expectLocation: "https://" + tlsAddr + "/users",
+		},
+		{
+			name:             "ok, ACME challenge is served by routes",
+			method:           http.MethodGet,
+			path:             "/.well-known/acme-challenge/abc",
+			expectStatus:     http.StatusOK,
+			expectBodyPrefix: "token=abc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, httpURL+tc.path, strings.NewReader(""))
+			require.NoError(t, err)
+			res, err := client.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			assert.Equal(t, tc.expectStatus, res.StatusCode)
+			assert.Equal(t, tc.expectLocation, res.Header.Get(HeaderLocation))
+			assert.True(t, strings.HasPrefix(string(body), tc.expectBodyPrefix))
+		})
+	}
+
+	res, err := client.Get("https://" + tlsAddr + "/")
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "secure", string(body))
+
+	require.NoError(t, e.Close())
+	assert.Equal(t, http.ErrServerClosed, <-errCh)
+}
+
+func TestEcho_StartWithRedirect_autoTLS(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	errCh := startWithRedirect(t, e, nil)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	// unknown challenge token is answered by autocert manager, not redirected
+	res, err := client.Get("http://" + e.ListenerAddr().String() + "/.well-known/acme-challenge/unknown")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = client.Get("http://" + e.ListenerAddr().String() + "/")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+
+	require.NoError(t, e.Shutdown(stdContext.Background()))
+	assert.Equal(t, http.ErrServerClosed, <-errCh)
+}
+
+func TestEcho_StartWithRedirect_listenError(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	e.ListenerNetwork = "unix"
+
+	assert.Equal(t, ErrInvalidListenerNetwork, e.StartWithRedirect(":0", ":0", nil))
+}
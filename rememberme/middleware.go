@@ -0,0 +1,98 @@
+package rememberme
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type (
+	// MiddlewareConfig defines the config for remember-me login middleware.
+	MiddlewareConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper middleware.Skipper
+
+		// Authenticated reports whether request already has logged in user (i.e. valid session). Token is used
+		// only for requests without one.
+		// Required.
+		Authenticated func(c echo.Context) bool
+
+		// Login logs in subject of valid token, i.e. starts new session.
+		// Required.
+		Login func(c echo.Context, subject string) error
+
+		// ContextKey is the key under which subject logged in by token is stored in the context.
+		// Optional. Default value "remember_me".
+		ContextKey string
+
+		// ErrorHandler is called with ErrTokenInvalid, ErrTokenTheft or error of the store. Returned error is
+		// returned by the middleware, nil continues the request as anonymous.
+		// Optional. Default value continues for ErrTokenInvalid and returns other errors.
+		ErrorHandler func(c echo.Context, err error) error
+	}
+)
+
+// DefaultMiddlewareConfig is the default remember-me login middleware config.
+var DefaultMiddlewareConfig = MiddlewareConfig{
+	Skipper:    middleware.DefaultSkipper,
+	ContextKey: "remember_me",
+	ErrorHandler: func(c echo.Context, err error) error {
+		if errors.Is(err, ErrTokenInvalid) {
+			return nil
+		}
+		return err
+	},
+}
+
+// Middleware returns a middleware which logs in requests without logged in user but with valid token cookie. Token
+// is rotated and subject is stored in the context, see `FromContext()`. Requests without token continue as
+// anonymous.
+func (m *Manager) Middleware(config MiddlewareConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Authenticated == nil {
+		panic("echo: rememberme middleware requires authenticated function")
+	}
+	if config.Login == nil {
+		panic("echo: rememberme middleware requires login function")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultMiddlewareConfig.Skipper
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultMiddlewareConfig.ContextKey
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = DefaultMiddlewareConfig.ErrorHandler
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || config.Authenticated(c) {
+				return next(c)
+			}
+
+			subject, err := m.Authenticate(c)
+			if errors.Is(err, ErrNoToken) {
+				return next(c)
+			}
+			if err != nil {
+				if err := config.ErrorHandler(c, err); err != nil {
+					return err
+				}
+				return next(c)
+			}
+			if err := config.Login(c, subject); err != nil {
+				return err
+			}
+			c.Set(config.ContextKey, subject)
+			return next(c)
+		}
+	}
+}
+
+// FromContext returns subject logged in by middleware stored under default context key or empty string.
+func FromContext(c echo.Context) string {
+	s, _ := c.Get(DefaultMiddlewareConfig.ContextKey).(string)
+	return s
+}
@@ -0,0 +1,346 @@
+/*
+Package rememberme implements persistent login ("remember me") tokens using selector/validator pattern. Token
+cookie contains selector identifying token in the store and secret validator, only SHA-256 hash of the validator is
+stored so leaked store can not be used to log in. Validator is rotated on every use while selector identifies the
+whole series of tokens of one login. Use of an old validator of the series means the cookie was stolen (either the
+thief or the user already used it), the series is invalidated so neither can continue.
+
+Example:
+
+	tokens := rememberme.New(rememberme.Config{CookieSecure: true})
+
+	e.POST("/login", func(c echo.Context) error {
+		user, err := authenticate(c)
+		if err != nil {
+			return err
+		}
+		if c.FormValue("remember") == "on" {
+			if err := tokens.Issue(c, user.ID); err != nil {
+				return err
+			}
+		}
+		return startSession(c, user.ID)
+	})
+
+	e.Use(tokens.Middleware(rememberme.MiddlewareConfig{
+		Authenticated: func(c echo.Context) bool { return sessionUser(c) != "" },
+		Login:         startSession,
+	}))
+*/
+package rememberme
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Config defines the config for remember-me token Manager.
+	Config struct {
+		// Store keeps token series.
+		// Optional. Default value NewMemoryStore().
+		Store Store
+
+		// TTL is how long login is remembered since the token was issued. Token is not extended by use so stolen
+		// token can not be used forever.
+		// Optional. Default value 30 days.
+		TTL time.Duration
+
+		// GracePeriod is time after rotation during which previous validator is still accepted (without another
+		// rotation), so concurrent requests sent by browser with the same cookie are not taken for theft.
+		// Optional. Default value 1 minute.
+		GracePeriod time.Duration
+
+		// OnTheft is called when use of an old validator is detected, after the series was invalidated. Use it to
+		// invalidate all logins of the subject (`Manager#ForgetAll()`) and notify the user.
+		// Optional.
+		OnTheft func(c echo.Context, subject string)
+
+		// CookieName is name of the token cookie.
+		// Optional. Default value "remember_me".
+		CookieName string `yaml:"cookie_name"`
+
+		// CookieDomain is domain of the token cookie.
+		// Optional. Default value none.
+		CookieDomain string `yaml:"cookie_domain"`
+
+		// CookiePath is path of the token cookie.
+		// Optional. Default value "/".
+		CookiePath string `yaml:"cookie_path"`
+
+		// CookieSecure indicates if token cookie is sent only over HTTPS. Cookie is always secure for TLS requests.
+		// Optional. Default value false.
+		CookieSecure bool `yaml:"cookie_secure"`
+
+		// CookieSameSite is SameSite attribute of the token cookie.
+		// Optional. Default value http.SameSiteLaxMode.
+		CookieSameSite http.SameSite `yaml:"cookie_same_site"`
+	}
+
+	// Token is stored state of token series.
+	Token struct {
+		// Selector identifies the series in store.
+		Selector string `json:"selector"`
+		// ValidatorHash is hex encoded SHA-256 hash of current validator.
+		ValidatorHash string `json:"validator_hash"`
+		// PreviousHash is hash of validator replaced by the last rotation.
+		PreviousHash string `json:"previous_hash,omitempty"`
+		// Subject is identity the token was issued to, i.e. user ID.
+		Subject string `json:"subject"`
+		// IssuedAt is time the series was issued.
+		IssuedAt time.Time `json:"issued_at"`
+		// RotatedAt is time of the last rotation of validator.
+		RotatedAt time.Time `json:"rotated_at"`
+		// ExpiresAt is time after which the series is no longer valid.
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	// Store is storage of token series.
+	Store interface {
+		// Save creates or replaces token series.
+		Save(ctx context.Context, token *Token) error
+		// Get returns token series with the selector or ErrTokenNotFound.
+		Get(ctx context.Context, selector string) (*Token, error)
+		// Rotate replaces token series only when its stored ValidatorHash still equals previousHash, so only one
+		// of concurrent requests using the same validator rotates it. It returns ErrTokenRotated when validator
+		// was already rotated and ErrTokenNotFound when the series does not exist.
+		Rotate(ctx context.Context, previousHash string, token *Token) error
+		// Delete removes token series with the selector.
+		Delete(ctx context.Context, selector string) error
+		// DeleteSubject removes all token series of the subject.
+		DeleteSubject(ctx context.Context, subject string) error
+	}
+
+	// Manager issues, verifies and rotates remember-me tokens.
+	Manager struct {
+		config Config
+	}
+)
+
+const (
+	selectorLength  = 12
+	validatorLength = 32
+)
+
+var (
+	// ErrTokenNotFound is returned by store when token series does not exist.
+	ErrTokenNotFound = errors.New("rememberme: token not found")
+
+	// ErrTokenRotated is returned by store when validator of token series was rotated by another request.
+	ErrTokenRotated = errors.New("rememberme: token already rotated")
+
+	// ErrNoToken is returned when request has no token cookie.
+	ErrNoToken = echo.NewHTTPError(http.StatusUnauthorized, "missing remember-me token")
+
+	// ErrTokenInvalid is returned when token is malformed, unknown or expired.
+	ErrTokenInvalid = echo.NewHTTPError(http.StatusUnauthorized, "invalid remember-me token")
+
+	// ErrTokenTheft is returned when old validator of token series is used.
+	ErrTokenTheft = echo.NewHTTPError(http.StatusUnauthorized, "remember-me token was already used")
+)
+
+// DefaultConfig is the default remember-me token Manager config.
+var DefaultConfig = Config{
+	TTL:            30 * 24 * time.Hour,
+	GracePeriod:    time.Minute,
+	CookieName:     "remember_me",
+	CookiePath:     "/",
+	CookieSameSite: http.SameSiteLaxMode,
+}
+
+// New creates new remember-me token Manager with config.
+func New(config Config) *Manager {
+	// Defaults
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfig.TTL
+	}
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = DefaultConfig.GracePeriod
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultConfig.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultConfig.CookiePath
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = DefaultConfig.CookieSameSite
+	}
+	return &Manager{config: config}
+}
+
+// Issue starts new token series for the subject and sets token cookie. Call it after the user logged in with
+// credentials and asked to be remembered.
+func (m *Manager) Issue(c echo.Context, subject string) error {
+	selector, err := randomString(selectorLength)
+	if err != nil {
+		return err
+	}
+	validator, err := randomString(validatorLength)
+	if err != nil {
+		return err
+	}
+	now := echo.Now(c)
+	token := &Token{
+		Selector:      selector,
+		ValidatorHash: hash(validator),
+		Subject:       subject,
+		IssuedAt:      now,
+		RotatedAt:     now,
+		ExpiresAt:     now.Add(m.config.TTL),
+	}
+	if err := m.config.Store.Save(c.Request().Context(), token); err != nil {
+		return err
+	}
+	m.setCookie(c, selector+":"+validator, token.ExpiresAt)
+	return nil
+}
+
+// Authenticate verifies token cookie of the request and returns subject of the token. Validator is rotated and new
+// cookie is set. Invalid cookie is removed, use of an old validator invalidates the series and returns
+// ErrTokenTheft.
+func (m *Manager) Authenticate(c echo.Context) (string, error) {
+	cookie, err := c.Cookie(m.config.CookieName)
+	if err != nil || cookie.Value == "" {
+		return "", ErrNoToken
+	}
+	ctx := c.Request().Context()
+	parts := strings.SplitN(cookie.Value, ":", 2)
+	if len(parts) != 2 {
+		m.clearCookie(c)
+		return "", ErrTokenInvalid
+	}
+	selector, validator := parts[0], parts[1]
+
+	token, err := m.config.Store.Get(ctx, selector)
+	if errors.Is(err, ErrTokenNotFound) {
+		m.clearCookie(c)
+		return "", ErrTokenInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+	now := echo.Now(c)
+	if !now.Before(token.ExpiresAt) {
+		m.clearCookie(c)
+		return "", ErrTokenInvalid
+	}
+
+	h := hash(validator)
+	if equal(h, token.ValidatorHash) {
+		next, err := randomString(validatorLength)
+		if err != nil {
+			return "", err
+		}
+		rotated := *token
+		rotated.PreviousHash = token.ValidatorHash
+		rotated.ValidatorHash = hash(next)
+		rotated.RotatedAt = now
+		err = m.config.Store.Rotate(ctx, token.ValidatorHash, &rotated)
+		if err == nil {
+			m.setCookie(c, selector+":"+next, rotated.ExpiresAt)
+			return rotated.Subject, nil
+		}
+		if errors.Is(err, ErrTokenNotFound) {
+			m.clearCookie(c)
+			return "", ErrTokenInvalid
+		}
+		if !errors.Is(err, ErrTokenRotated) {
+			return "", err
+		}
+		// concurrent request rotated the validator first, series is checked again with its new state
+		if token, err = m.config.Store.Get(ctx, selector); errors.Is(err, ErrTokenNotFound) {
+			m.clearCookie(c)
+			return "", ErrTokenInvalid
+		} else if err != nil {
+			return "", err
+		}
+	}
+	if token.PreviousHash != "" && equal(h, token.PreviousHash) && now.Before(token.RotatedAt.Add(m.config.GracePeriod)) {
+		// concurrent request, cookie with current validator was sent in response of the request rotating it
+		return token.Subject, nil
+	}
+
+	if err := m.config.Store.Delete(ctx, selector); err != nil {
+		return "", err
+	}
+	m.clearCookie(c)
+	if m.config.OnTheft != nil {
+		m.config.OnTheft(c, token.Subject)
+	}
+	return "", ErrTokenTheft
+}
+
+// Forget invalidates token series of the request and removes token cookie. Call it on logout.
+func (m *Manager) Forget(c echo.Context) error {
+	m.clearCookie(c)
+	cookie, err := c.Cookie(m.config.CookieName)
+	if err != nil {
+		return nil
+	}
+	selector := strings.SplitN(cookie.Value, ":", 2)[0]
+	if selector == "" {
+		return nil
+	}
+	return m.config.Store.Delete(c.Request().Context(), selector)
+}
+
+// ForgetAll invalidates all token series of the subject, i.e. when password was changed or theft was detected.
+func (m *Manager) ForgetAll(ctx context.Context, subject string) error {
+	return m.config.Store.DeleteSubject(ctx, subject)
+}
+
+func (m *Manager) setCookie(c echo.Context, value string, expires time.Time) {
+	c.SetCookie(&http.Cookie{
+		Name:     m.config.CookieName,
+		Value:    value,
+		Path:     m.config.CookiePath,
+		Domain:   m.config.CookieDomain,
+		Expires:  expires,
+		Secure:   m.config.CookieSecure || c.IsTLS(),
+		HttpOnly: true,
+		SameSite: m.config.CookieSameSite,
+	})
+}
+
+func (m *Manager) clearCookie(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     m.config.CookieName,
+		Path:     m.config.CookiePath,
+		Domain:   m.config.CookieDomain,
+		MaxAge:   -1,
+		Secure:   m.config.CookieSecure || c.IsTLS(),
+		HttpOnly: true,
+		SameSite: m.config.CookieSameSite,
+	})
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hash(validator string) string {
+	h := sha256.Sum256([]byte(validator))
+	return hex.EncodeToString(h[:])
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,283 @@
+package rememberme
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+)
+
+// call runs fn with context of request sending cookie value and returns the cookie set in response.
+func call(e *echo.Echo, cookie string, fn func(c echo.Context)) *http.Cookie {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: "remember_me", Value: cookie})
+	}
+	rec := httptest.NewRecorder()
+	fn(e.NewContext(req, rec))
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "remember_me" {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	e := echo.New()
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e.Clock = clock
+	store := NewMemoryStore()
+	m := New(Config{Store: store})
+
+	issued := call(e, "", func(c echo.Context) {
+		assert.NoError(t, m.Issue(c, "user-1"))
+	})
+	if !assert.NotNil(t, issued) {
+		return
+	}
+	assert.True(t, issued.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, issued.SameSite)
+	assert.Equal(t, clock.Now().Add(30*24*time.Hour), issued.Expires)
+	selector := strings.SplitN(issued.Value, ":", 2)[0]
+	token, err := store.Get(context.Background(), selector)
+	assert.NoError(t, err)
+	assert.NotContains(t, issued.Value, token.ValidatorHash)
+
+	clock.Advance(time.Hour)
+	var subject string
+	rotated := call(e, issued.Value, func(c echo.Context) {
+		subject, err = m.Authenticate(c)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", subject)
+	if !assert.NotNil(t, rotated) {
+		return
+	}
+	assert.NotEqual(t, issued.Value, rotated.Value)
+	assert.True(t, strings.HasPrefix(rotated.Value, selector+":"))
+	// expiration is not extended by use
+	assert.Equal(t, issued.Expires, rotated.Expires)
+
+	// concurrent request with previous validator within grace period
+	cookie := call(e, issued.Value, func(c echo.Context) {
+		subject, err = m.Authenticate(c)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", subject)
+	assert.Nil(t, cookie)
+
+	// previous validator after grace period is theft
+	clock.Advance(time.Minute)
+	var stolen string
+	m.config.OnTheft = func(c echo.Context, subject string) {
+		stolen = subject
+	}
+	cookie = call(e, issued.Value, func(c echo.Context) {
+		_, err = m.Authenticate(c)
+	})
+	assert.Equal(t, ErrTokenTheft, err)
+	assert.Equal(t, "user-1", stolen)
+	assert.Equal(t, -1, cookie.MaxAge)
+
+	// series is invalidated, current validator does not work either
+	call(e, rotated.Value, func(c echo.Context) {
+		_, err = m.Authenticate(c)
+	})
+	assert.Equal(t, ErrTokenInvalid, err)
+}
+
+// racingStore runs concurrent request before the first rotation, so the validator is rotated by another request
+// between Get and Rotate.
+type racingStore struct {
+	*MemoryStore
+	concurrent func()
+}
+
+func (s *racingStore) Rotate(ctx context.Context, previousHash string, token *Token) error {
+	if f := s.concurrent; f != nil {
+		s.concurrent = nil
+		f()
+	}
+	return s.MemoryStore.Rotate(ctx, previousHash, token)
+}
+
+func TestManager_AuthenticateConcurrentRotation(t *testing.T) {
+	e := echo.New()
+	store := &racingStore{MemoryStore: NewMemoryStore()}
+	m := New(Config{Store: store})
+	issued := call(e, "", func(c echo.Context) {
+		assert.NoError(t, m.Issue(c, "user-1"))
+	})
+
+	var rotated *http.Cookie
+	store.concurrent = func() {
+		rotated = call(e, issued.Value, func(c echo.Context) {
+			subject, err := m.Authenticate(c)
+			assert.NoError(t, err)
+			assert.Equal(t, "user-1", subject)
+		})
+	}
+	var subject string
+	var err error
+	cookie := call(e, issued.Value, func(c echo.Context) {
+		subject, err = m.Authenticate(c)
+	})
+	assert.NoError(t, err, "request losing the rotation is not taken for theft")
+	assert.Equal(t, "user-1", subject)
+	assert.Nil(t, cookie)
+
+	if assert.NotNil(t, rotated) {
+		call(e, rotated.Value, func(c echo.Context) {
+			subject, err = m.Authenticate(c)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "user-1", subject)
+	}
+}
+
+func TestManager_AuthenticateInvalid(t *testing.T) {
+	e := echo.New()
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e.Clock = clock
+	m := New(Config{TTL: time.Hour})
+	issued := call(e, "", func(c echo.Context) {
+		assert.NoError(t, m.Issue(c, "user-1"))
+	})
+
+	var testCases = []struct {
+		name      string
+		cookie    string
+		advance   time.Duration
+		expectErr error
+	}{
+		{name: "no cookie", expectErr: ErrNoToken},
+		{name: "malformed", cookie: "abc", expectErr: ErrTokenInvalid},
+		{name: "unknown selector", cookie: "abc:def", expectErr: ErrTokenInvalid},
+		{name: "expired", cookie: issued.Value, advance: time.Hour, expectErr: ErrTokenInvalid},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clock.Advance(tc.advance)
+			var err error
+			call(e, tc.cookie, func(c echo.Context) {
+				_, err = m.Authenticate(c)
+			})
+			assert.Equal(t, tc.expectErr, err)
+		})
+	}
+}
+
+func TestManager_Forget(t *testing.T) {
+	e := echo.New()
+	m := New(Config{})
+	first := call(e, "", func(c echo.Context) {
+		assert.NoError(t, m.Issue(c, "user-1"))
+	})
+	second := call(e, "", func(c echo.Context) {
+		assert.NoError(t, m.Issue(c, "user-1"))
+	})
+	third := call(e, "", func(c echo.Context) {
+		assert.NoError(t, m.Issue(c, "user-2"))
+	})
+
+	cookie := call(e, first.Value, func(c echo.Context) {
+		assert.NoError(t, m.Forget(c))
+	})
+	assert.Equal(t, -1, cookie.MaxAge)
+	var err error
+	call(e, first.Value, func(c echo.Context) {
+		_, err = m.Authenticate(c)
+	})
+	assert.Equal(t, ErrTokenInvalid, err)
+
+	assert.NoError(t, m.ForgetAll(context.Background(), "user-1"))
+	call(e, second.Value, func(c echo.Context) {
+		_, err = m.Authenticate(c)
+	})
+	assert.Equal(t, ErrTokenInvalid, err)
+	call(e, third.Value, func(c echo.Context) {
+		_, err = m.Authenticate(c)
+	})
+	assert.NoError(t, err)
+}
+
+func TestManager_Middleware(t *testing.T) {
+	e := echo.New()
+	clock := echotest.NewClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	e.Clock = clock
+	m := New(Config{})
+	issued := call(e, "", func(c echo.Context) {
+		assert.NoError(t, m.Issue(c, "user-1"))
+	})
+
+	var loggedIn string
+	mw := m.Middleware(MiddlewareConfig{
+		Authenticated: func(c echo.Context) bool {
+			return c.Request().Header.Get("X-Session") != ""
+		},
+		Login: func(c echo.Context, subject string) error {
+			loggedIn = subject
+			return nil
+		},
+	})
+	h := mw(func(c echo.Context) error {
+		return c.String(http.StatusOK, FromContext(c))
+	})
+
+	var testCases = []struct {
+		name         string
+		cookie       string
+		session      bool
+		advance      time.Duration
+		expectLogin  string
+		expectBody   string
+		expectStatus int
+	}{
+		{name: "anonymous", expectStatus: http.StatusOK},
+		{name: "already logged in", cookie: issued.Value, session: true, expectStatus: http.StatusOK},
+		{name: "login", cookie: issued.Value, expectLogin: "user-1", expectBody: "user-1", expectStatus: http.StatusOK},
+		{name: "invalid token continues", cookie: "abc:def", expectStatus: http.StatusOK},
+		{name: "theft", cookie: issued.Value, advance: time.Minute, expectStatus: http.StatusUnauthorized},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			loggedIn = ""
+			clock.Advance(tc.advance)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "remember_me", Value: tc.cookie})
+			}
+			if tc.session {
+				req.Header.Set("X-Session", "1")
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := h(c)
+			if tc.expectStatus == http.StatusOK {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			} else {
+				assert.Equal(t, ErrTokenTheft, err)
+			}
+			assert.Equal(t, tc.expectLogin, loggedIn)
+		})
+	}
+}
+
+func TestManager_MiddlewarePanics(t *testing.T) {
+	m := New(Config{})
+	assert.PanicsWithValue(t, "echo: rememberme middleware requires authenticated function", func() {
+		m.Middleware(MiddlewareConfig{})
+	})
+	assert.PanicsWithValue(t, "echo: rememberme middleware requires login function", func() {
+		m.Middleware(MiddlewareConfig{Authenticated: func(c echo.Context) bool { return false }})
+	})
+}
@@ -0,0 +1,77 @@
+package rememberme
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore is in-memory token store. Tokens are lost on restart and are not shared between instances so it is
+// suitable for single instance deployments and tests.
+type MemoryStore struct {
+	mutex  sync.Mutex
+	tokens map[string]Token
+}
+
+// NewMemoryStore creates new in-memory token store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: map[string]Token{}}
+}
+
+// Save stores copy of token series. Series expired before the saved one was rotated are removed from store.
+func (s *MemoryStore) Save(ctx context.Context, token *Token) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for selector, t := range s.tokens {
+		if !token.RotatedAt.Before(t.ExpiresAt) {
+			delete(s.tokens, selector)
+		}
+	}
+	s.tokens[token.Selector] = *token
+	return nil
+}
+
+// Get returns copy of token series.
+func (s *MemoryStore) Get(ctx context.Context, selector string) (*Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	t, ok := s.tokens[selector]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return &t, nil
+}
+
+// Rotate stores copy of token series when validator hash of the stored series equals previousHash.
+func (s *MemoryStore) Rotate(ctx context.Context, previousHash string, token *Token) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	t, ok := s.tokens[token.Selector]
+	if !ok {
+		return ErrTokenNotFound
+	}
+	if t.ValidatorHash != previousHash {
+		return ErrTokenRotated
+	}
+	s.tokens[token.Selector] = *token
+	return nil
+}
+
+// Delete removes token series.
+func (s *MemoryStore) Delete(ctx context.Context, selector string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.tokens, selector)
+	return nil
+}
+
+// DeleteSubject removes all token series of the subject.
+func (s *MemoryStore) DeleteSubject(ctx context.Context, subject string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for selector, t := range s.tokens {
+		if t.Subject == subject {
+			delete(s.tokens, selector)
+		}
+	}
+	return nil
+}
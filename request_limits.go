@@ -0,0 +1,129 @@
+package echo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type (
+	// RequestLimitsConfig defines limits of request URL and headers checked before pre-middlewares and routing.
+	// Requests exceeding them are answered with 414 URI Too Long or 431 Request Header Fields Too Large and
+	// `RequestLimitError` as internal error. Zero value of a limit means no limit.
+	RequestLimitsConfig struct {
+		// MaxURLLength limits length in bytes of request target (path and query as received).
+		MaxURLLength int
+
+		// MaxQueryParams limits number of query parameters, repeated names are counted separately.
+		MaxQueryParams int
+
+		// MaxHeaderCount limits number of request header values.
+		MaxHeaderCount int
+
+		// MaxHeaderBytes limits total size of request header names and values. Servers started by Echo without
+		// `http.Server#MaxHeaderBytes` set read at most this many bytes of headers (plus URL and slack added by
+		// net/http), so oversized headers are not buffered in memory.
+		MaxHeaderBytes int
+	}
+
+	// RequestLimitError describes exceeded request limit.
+	RequestLimitError struct {
+		// Limit is name of exceeded limit, i.e. RequestLimitQueryParams.
+		Limit  string `json:"limit"`
+		Max    int    `json:"max"`
+		Actual int    `json:"actual"`
+	}
+)
+
+// Names of request limits reported by `RequestLimitError`.
+const (
+	RequestLimitURLLength   = "url_length"
+	RequestLimitQueryParams = "query_params"
+	RequestLimitHeaderCount = "header_count"
+	RequestLimitHeaderBytes = "header_bytes"
+)
+
+// headerLineOverhead is size of `: ` and CRLF of every header line.
+const headerLineOverhead = 4
+
+// Error returns error message.
+func (e *RequestLimitError) Error() string {
+	return fmt.Sprintf("request %s limit exceeded: max=%d, actual=%d", strings.Replace(e.Limit, "_", " ", -1), e.Max, e.Actual)
+}
+
+// check returns HTTPError for request exceeding a limit or nil.
+func (config RequestLimitsConfig) check(r *http.Request) *HTTPError {
+	if config == (RequestLimitsConfig{}) {
+		return nil
+	}
+	if config.MaxURLLength > 0 {
+		uri := r.RequestURI
+		if uri == "" {
+			uri = r.URL.RequestURI()
+		}
+		if len(uri) > config.MaxURLLength {
+			return requestLimitError(http.StatusRequestURITooLong, RequestLimitURLLength, config.MaxURLLength, len(uri))
+		}
+	}
+	if config.MaxQueryParams > 0 {
+		if n := countQueryParams(r.URL.RawQuery); n > config.MaxQueryParams {
+			return requestLimitError(http.StatusRequestURITooLong, RequestLimitQueryParams, config.MaxQueryParams, n)
+		}
+	}
+	if config.MaxHeaderCount > 0 || config.MaxHeaderBytes > 0 {
+		count, size := 0, 0
+		for name, values := range r.Header {
+			count += len(values)
+			for _, v := range values {
+				size += len(name) + len(v) + headerLineOverhead
+			}
+		}
+		if config.MaxHeaderCount > 0 && count > config.MaxHeaderCount {
+			return requestLimitError(http.StatusRequestHeaderFieldsTooLarge, RequestLimitHeaderCount, config.MaxHeaderCount, count)
+		}
+		if config.MaxHeaderBytes > 0 && size > config.MaxHeaderBytes {
+			return requestLimitError(http.StatusRequestHeaderFieldsTooLarge, RequestLimitHeaderBytes, config.MaxHeaderBytes, size)
+		}
+	}
+	return nil
+}
+
+// serverMaxHeaderBytes returns value for `http.Server#MaxHeaderBytes` covering request line and headers allowed by
+// the limits or 0 when headers are not limited.
+func (config RequestLimitsConfig) serverMaxHeaderBytes() int {
+	if config.MaxHeaderBytes <= 0 {
+		return 0
+	}
+	size := config.MaxHeaderBytes
+	if config.MaxURLLength > 0 {
+		size += config.MaxURLLength
+	} else {
+		size += http.DefaultMaxHeaderBytes
+	}
+	return size
+}
+
+func requestLimitError(code int, limit string, max, actual int) *HTTPError {
+	return NewHTTPError(code, Map{
+		"message": http.StatusText(code),
+		"limit":   limit,
+		"max":     max,
+	}).SetInternal(&RequestLimitError{Limit: limit, Max: max, Actual: actual})
+}
+
+// countQueryParams counts parameters of raw query without parsing it.
+func countQueryParams(query string) int {
+	n := 0
+	for query != "" {
+		var param string
+		if i := strings.IndexAny(query, "&;"); i >= 0 {
+			param, query = query[:i], query[i+1:]
+		} else {
+			param, query = query, ""
+		}
+		if param != "" {
+			n++
+		}
+	}
+	return n
+}
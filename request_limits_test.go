@@ -0,0 +1,130 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEcho_RequestLimits(t *testing.T) {
+	var testCases = []struct {
+		name         string
+		givenConfig  RequestLimitsConfig
+		whenURL      string
+		whenHeaders  map[string]string
+		expectCode   int
+		expectBody   string
+		expectLimit  *RequestLimitError
+		expectCalled bool
+	}{
+		{
+			name:         "ok, no limits",
+			whenURL:      "/?" + strings.Repeat("a=1&", 1000),
+			expectCode:   http.StatusOK,
+			expectCalled: true,
+		},
+		{
+			name:         "ok, within limits",
+			givenConfig:  RequestLimitsConfig{MaxURLLength: 16, MaxQueryParams: 2, MaxHeaderCount: 2, MaxHeaderBytes: 64},
+			whenURL:      "/?a=1&&b=2",
+			whenHeaders:  map[string]string{HeaderAccept: "*/*", HeaderUserAgent: "curl"},
+			expectCode:   http.StatusOK,
+			expectCalled: true,
+		},
+		{
+			name:        "nok, url too long",
+			givenConfig: RequestLimitsConfig{MaxURLLength: 16},
+			whenURL:     "/users/1234567890",
+			expectCode:  http.StatusRequestURITooLong,
+			expectBody:  `{"limit":"url_length","max":16,"message":"Request URI Too Long"}` + "\n",
+			expectLimit: &RequestLimitError{Limit: RequestLimitURLLength, Max: 16, Actual: 17},
+		},
+		{
+			name:        "nok, too many query params",
+			givenConfig: RequestLimitsConfig{MaxQueryParams: 2},
+			whenURL:     "/?a=1&b=2;c",
+			expectCode:  http.StatusRequestURITooLong,
+			expectBody:  `{"limit":"query_params","max":2,"message":"Request URI Too Long"}` + "\n",
+			expectLimit: &RequestLimitError{Limit: RequestLimitQueryParams, Max: 2, Actual: 3},
+		},
+		{
+			name:        "nok, too many headers",
+			givenConfig: RequestLimitsConfig{MaxHeaderCount: 1},
+			whenURL:     "/",
+			whenHeaders: map[string]string{HeaderAccept: "*/*", HeaderUserAgent: "curl"},
+			expectCode:  http.StatusRequestHeaderFieldsTooLarge,
+			expectBody:  `{"limit":"header_count","max":1,"message":"Request Header Fields Too Large"}` + "\n",
+			expectLimit: &RequestLimitError{Limit: RequestLimitHeaderCount, Max: 1, Actual: 2},
+		},
+		{
+			name:        "nok, headers too large",
+			givenConfig: RequestLimitsConfig{MaxHeaderBytes: 32},
+			whenURL:     "/",
+			whenHeaders: map[string]string{HeaderCookie: strings.Repeat("x", 30)},
+			expectCode:  http.StatusRequestHeaderFieldsTooLarge,
+			expectBody:  `{"limit":"header_bytes","max":32,"message":"Request Header Fields Too Large"}` + "\n",
+			expectLimit: &RequestLimitError{Limit: RequestLimitHeaderBytes, Max: 32, Actual: 40},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			e.RequestLimits = tc.givenConfig
+			var limitErr *RequestLimitError
+			e.HTTPErrorHandler = func(err error, c Context) {
+				if he, ok := err.(*HTTPError); ok {
+					limitErr, _ = he.Internal.(*RequestLimitError)
+				}
+				e.DefaultHTTPErrorHandler(err, c)
+			}
+			preCalled, called := false, false
+			e.Pre(func(next HandlerFunc) HandlerFunc {
+				return func(c Context) error {
+					preCalled = true
+					return next(c)
+				}
+			})
+			e.GET("/*", func(c Context) error {
+				called = true
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			for k, v := range tc.whenHeaders {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+			assert.Equal(t, tc.expectLimit, limitErr)
+			assert.Equal(t, tc.expectCalled, called)
+			assert.Equal(t, tc.expectCalled, preCalled)
+		})
+	}
+}
+
+func TestRequestLimitError_Error(t *testing.T) {
+	err := &RequestLimitError{Limit: RequestLimitQueryParams, Max: 2, Actual: 3}
+	assert.EqualError(t, err, "request query params limit exceeded: max=2, actual=3")
+}
+
+func TestRequestLimitsConfig_serverMaxHeaderBytes(t *testing.T) {
+	assert.Equal(t, 0, RequestLimitsConfig{MaxURLLength: 100}.serverMaxHeaderBytes())
+	assert.Equal(t, 8192+100, RequestLimitsConfig{MaxURLLength: 100, MaxHeaderBytes: 8192}.serverMaxHeaderBytes())
+	assert.Equal(t, 8192+http.DefaultMaxHeaderBytes, RequestLimitsConfig{MaxHeaderBytes: 8192}.serverMaxHeaderBytes())
+
+	e := New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.RequestLimits.MaxHeaderBytes = 8192
+	s := &http.Server{Addr: ":0"}
+	if assert.NoError(t, e.configureServer(s)) {
+		defer e.Listener.Close()
+		assert.Equal(t, 8192+http.DefaultMaxHeaderBytes, s.MaxHeaderBytes)
+	}
+}
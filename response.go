@@ -2,6 +2,7 @@ package echo
 
 import (
 	"bufio"
+	"io"
 	"net"
 	"net/http"
 )
@@ -80,6 +81,30 @@ func (r *Response) Write(b []byte) (n int, err error) {
 	return
 }
 
+// ReadFrom implements the io.ReaderFrom interface so `io.Copy` to the response is delegated to the underlying
+// writer. `http.ResponseWriter` of net/http implements io.ReaderFrom and uses sendfile(2)/splice(2) when source is
+// a file or network connection. Writers not implementing io.ReaderFrom (e.g. compressing writers of
+// middlewares) are written with ordinary Write calls.
+func (r *Response) ReadFrom(src io.Reader) (n int64, err error) {
+	if !r.Committed {
+		if r.Status == 0 {
+			r.Status = http.StatusOK
+		}
+		r.WriteHeader(r.Status)
+	}
+	if rf, ok := r.Writer.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		// hide ReadFrom of the writer (if any) from io.Copy so it does not end up calling this method again
+		n, err = io.Copy(writerOnly{r.Writer}, src)
+	}
+	r.Size += n
+	for _, fn := range r.afterFuncs {
+		fn()
+	}
+	return
+}
+
 // Flush implements the http.Flusher interface to allow an HTTP handler to flush
 // buffered data to the client.
 // See [http.Flusher](https://golang.org/pkg/net/http/#Flusher)
@@ -94,6 +119,11 @@ func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return r.Writer.(http.Hijacker).Hijack()
 }
 
+// writerOnly hides all methods of the wrapped writer except Write.
+type writerOnly struct {
+	io.Writer
+}
+
 func (r *Response) reset(w http.ResponseWriter) {
 	r.beforeFuncs = nil
 	r.afterFuncs = nil
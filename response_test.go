@@ -1,8 +1,10 @@
 package echo
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -72,3 +74,50 @@ func TestResponse_ChangeStatusCodeBeforeWrite(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
+
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFromCalls int
+}
+
+func (r *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	r.readFromCalls++
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestResponse_ReadFrom(t *testing.T) {
+	e := New()
+	rec := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	res := &Response{echo: e, Writer: rec}
+	afterCalls := 0
+	res.After(func() {
+		afterCalls++
+	})
+
+	// http.ServeContent copies content with io.CopyN which wraps source in io.LimitedReader
+	n, err := io.CopyN(res, strings.NewReader("large file"), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), n)
+	assert.Equal(t, 1, rec.readFromCalls)
+	assert.Equal(t, 1, afterCalls)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "large file", rec.Body.String())
+	assert.Equal(t, int64(10), res.Size)
+	assert.True(t, res.Committed)
+}
+
+func TestResponse_ReadFrom_withoutReaderFrom(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	res := &Response{echo: e, Writer: rec}
+	res.WriteHeader(http.StatusPartialContent)
+
+	n, err := res.ReadFrom(strings.NewReader("test"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "test", rec.Body.String())
+	assert.Equal(t, int64(4), res.Size)
+}
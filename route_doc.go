@@ -0,0 +1,164 @@
+package echo
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type (
+	// RouteDoc is human readable documentation of a route rendered by `DocsHandler`.
+	RouteDoc struct {
+		// Summary is short description of the route.
+		Summary string `json:"summary,omitempty"`
+
+		// Description is longer description of the route.
+		Description string `json:"description,omitempty"`
+
+		// Tags group routes in the catalog, i.e. "users".
+		Tags []string `json:"tags,omitempty"`
+
+		// Request is example of request body.
+		Request interface{} `json:"request,omitempty"`
+
+		// Responses are examples of response bodies by status code.
+		Responses map[int]interface{} `json:"responses,omitempty"`
+
+		// Hidden excludes the route from the catalog.
+		Hidden bool `json:"-"`
+	}
+
+	// RouteDocEntry is route in catalog served by `DocsHandler`.
+	RouteDocEntry struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		Name   string `json:"name,omitempty"`
+		// Params are parameters declared with `Route#Params()`.
+		Params []RouteParam `json:"params,omitempty"`
+		RouteDoc
+	}
+)
+
+// MetaDoc is metadata key of route documentation (`*RouteDoc`) set by `Route#Doc()`.
+const MetaDoc = "doc"
+
+// Doc sets documentation of the route and returns the route so calls can be chained.
+//
+// Example:
+//
+//	e.POST("/users", createUser).Doc(echo.RouteDoc{
+//		Summary: "Create user",
+//		Tags:    []string{"users"},
+//		Request: User{Name: "Jon Snow"},
+//		Responses: map[int]interface{}{
+//			http.StatusCreated: User{ID: 1, Name: "Jon Snow"},
+//		},
+//	})
+func (r *Route) Doc(doc RouteDoc) *Route {
+	return r.SetMeta(MetaDoc, &doc)
+}
+
+// GetDoc returns documentation of the route or nil when route is not documented.
+func (r *Route) GetDoc() *RouteDoc {
+	doc, _ := r.GetMeta(MetaDoc).(*RouteDoc)
+	return doc
+}
+
+// RouteDocs returns catalog of routes registered in Echo sorted by path and method. Routes without documentation
+// are included with empty documentation, hidden routes are excluded.
+func RouteDocs(e *Echo) []RouteDocEntry {
+	entries := []RouteDocEntry{}
+	for _, r := range e.Routes() {
+		entry := RouteDocEntry{Method: r.Method, Path: r.Path, Name: r.Name, Params: r.GetParams()}
+		if doc := r.GetDoc(); doc != nil {
+			if doc.Hidden {
+				continue
+			}
+			entry.RouteDoc = *doc
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Method < entries[j].Method
+	})
+	return entries
+}
+
+// DocsHandler returns a handler serving catalog of routes (see `RouteDocs()`) as browsable HTML page or as JSON
+// when client prefers `application/json` or query parameter `format=json` is set. Route of the handler itself is
+// hidden from the catalog.
+//
+// Example:
+//
+//	e.GET("/docs", echo.DocsHandler(e))
+func DocsHandler(e *Echo) HandlerFunc {
+	return func(c Context) error {
+		var entries []RouteDocEntry
+		for _, entry := range RouteDocs(e) {
+			if entry.Path == c.Path() && entry.Method == c.Request().Method {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		if entries == nil {
+			entries = []RouteDocEntry{}
+		}
+
+		if c.QueryParam("format") == "json" || NegotiateType(c, MIMETextHTML, MIMEApplicationJSON) == MIMEApplicationJSON {
+			return c.JSON(http.StatusOK, entries)
+		}
+		buf := new(bytes.Buffer)
+		if err := docsTemplate.Execute(buf, entries); err != nil {
+			return err
+		}
+		return c.HTMLBlob(http.StatusOK, buf.Bytes())
+	}
+}
+
+var docsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"example": func(v interface{}) string {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>API documentation</title>
+<style>
+body{font-family:sans-serif;max-width:960px;margin:2em auto;padding:0 1em}
+.route{border-top:1px solid #ddd;padding:.5em 0}
+.method{display:inline-block;min-width:5em;font-weight:bold}
+.tag{background:#eee;border-radius:3px;padding:0 .4em;margin-left:.4em;font-size:.8em}
+td{padding:0 .8em 0 0}
+pre{background:#f6f6f6;padding:.5em;overflow:auto}
+</style>
+</head>
+<body>
+<h1>API documentation</h1>
+{{range .}}<div class="route" id="{{.Method}} {{.Path}}">
+<h3><span class="method">{{.Method}}</span><code>{{.Path}}</code>{{range .Tags}}<span class="tag">{{.}}</span>{{end}}</h3>
+{{if .Summary}}<p><strong>{{.Summary}}</strong></p>{{end}}
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+{{if .Params}}<h4>Parameters</h4>
+<table>
+{{range .Params}}<tr><td><code>{{.Name}}</code></td><td>{{.In}}</td><td>{{.Type}}{{if .Enum}} ({{join .Enum ", "}}){{end}}</td><td>{{if .Required}}required{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Request}}<h4>Request</h4>
+<pre>{{example .Request}}</pre>{{end}}
+{{range $status, $body := .Responses}}<h4>Response {{$status}}</h4>
+<pre>{{example $body}}</pre>
+{{end}}</div>
+{{end}}</body>
+</html>
+`))
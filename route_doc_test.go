@@ -0,0 +1,72 @@
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteDocs(t *testing.T) {
+	e := New()
+	h := func(c Context) error { return nil }
+	e.POST("/users", h).Doc(RouteDoc{
+		Summary:   "Create user",
+		Tags:      []string{"users"},
+		Request:   Map{"name": "Jon"},
+		Responses: map[int]interface{}{http.StatusCreated: Map{"id": 1, "name": "Jon"}},
+	})
+	e.GET("/users", h).Doc(RouteDoc{Summary: "List users"})
+	e.GET("/internal", h).Doc(RouteDoc{Hidden: true})
+	e.GET("/health", h)
+
+	docs := RouteDocs(e)
+	if assert.Len(t, docs, 3) {
+		assert.Equal(t, "/health", docs[0].Path)
+		assert.Empty(t, docs[0].Summary)
+		assert.Equal(t, http.MethodGet, docs[1].Method)
+		assert.Equal(t, "List users", docs[1].Summary)
+		assert.Equal(t, http.MethodPost, docs[2].Method)
+		assert.Equal(t, []string{"users"}, docs[2].Tags)
+	}
+}
+
+func TestDocsHandler(t *testing.T) {
+	e := New()
+	e.POST("/users", func(c Context) error { return nil }).Doc(RouteDoc{
+		Summary:     "Create user",
+		Description: "Creates <new> user.",
+		Request:     Map{"name": "Jon"},
+		Responses:   map[int]interface{}{http.StatusCreated: Map{"id": 1}},
+	})
+	e.GET("/docs", DocsHandler(e))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MIMETextHTMLCharsetUTF8, rec.Header().Get(HeaderContentType))
+	body := rec.Body.String()
+	assert.Contains(t, body, "<code>/users</code>")
+	assert.Contains(t, body, "Creates &lt;new&gt; user.")
+	assert.Contains(t, body, "Response 201")
+	assert.NotContains(t, body, "<code>/docs</code>")
+
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	req.Header.Set(HeaderAccept, MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var entries []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "/users", entries[0]["path"])
+		assert.Equal(t, "Create user", entries[0]["summary"])
+		assert.Equal(t, map[string]interface{}{"201": map[string]interface{}{"id": float64(1)}}, entries[0]["responses"])
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs?format=json", nil))
+	assert.Contains(t, rec.Header().Get(HeaderContentType), MIMEApplicationJSON)
+}
@@ -0,0 +1,255 @@
+package echo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/labstack/gommon/bytes"
+)
+
+// Route metadata keys used by Echo and its middlewares.
+const (
+	// MetaBodyLimit is metadata key of route request body limit in bytes (`int64`) applied by `middleware.BodyLimit`.
+	MetaBodyLimit = "body_limit"
+
+	// MetaCachePolicy is metadata key of route cache policy (`*CacheControl`) applied by `middleware.CachePolicy`.
+	MetaCachePolicy = "cache_policy"
+
+	// MetaCompress is metadata key of route response compression switch (`bool`) applied by `middleware.Gzip`.
+	MetaCompress = "compress"
+
+	// MetaContentTypes is metadata key of request content types (`[]string`) accepted by the route applied by
+	// `middleware.ContentType`.
+	MetaContentTypes = "content_types"
+
+	// MetaDeprecation is metadata key of route deprecation (`*RouteDeprecation`) applied by `middleware.Deprecation`.
+	MetaDeprecation = "deprecation"
+
+	// MetaExpectContinue is metadata key of route `Expect: 100-continue` policy (`*ExpectContinuePolicy`) applied
+	// by `middleware.ExpectContinue`.
+	MetaExpectContinue = "expect_continue"
+
+	// MetaRateLimitCost is metadata key of route request cost (`int`) deducted by `middleware.RateLimiter`.
+	MetaRateLimitCost = "rate_limit_cost"
+
+	// MetaRecoveryPolicy is metadata key of route panic recovery policy (`RecoveryPolicy`) applied by
+	// `middleware.Recover`.
+	MetaRecoveryPolicy = "recovery_policy"
+)
+
+// SetMeta sets metadata value for the route and returns the route so calls can be chained. Middlewares read
+// metadata of the matched route with `RouteOf()` to apply per-route policies. Metadata is not guarded by a
+// lock and must be set while routes are registered, before the server is started.
+//
+// Example:
+//
+//	e.GET("/users", listUsers).SetMeta("owner", "accounts-team")
+func (r *Route) SetMeta(key string, val interface{}) *Route {
+	if r.meta == nil {
+		r.meta = Map{}
+	}
+	r.meta[key] = val
+	return r
+}
+
+// GetMeta returns metadata value of the route or nil when value is not set.
+func (r *Route) GetMeta(key string) interface{} {
+	return r.meta[key]
+}
+
+// Meta returns copy of all metadata values of the route.
+func (r *Route) Meta() Map {
+	m := Map{}
+	for k, v := range r.meta {
+		m[k] = v
+	}
+	return m
+}
+
+// CachePolicy sets cache policy of the route. Policy is applied to responses of the route by
+// `middleware.CachePolicy` unless handler sets `Cache-Control` header itself.
+//
+// Example:
+//
+//	e.GET("/products", listProducts).CachePolicy(echo.NewCacheControl().Public().MaxAge(5 * time.Minute))
+func (r *Route) CachePolicy(cc *CacheControl) *Route {
+	return r.SetMeta(MetaCachePolicy, cc)
+}
+
+// CacheTTL sets cache policy of the route allowing caches to store responses for ttl, see `Route#CachePolicy()`.
+//
+// Example:
+//
+//	e.GET("/products", listProducts).CacheTTL(30 * time.Second)
+func (r *Route) CacheTTL(ttl time.Duration) *Route {
+	return r.CachePolicy(NewCacheControl().MaxAge(ttl))
+}
+
+// Compress enables or disables compression of responses of the route by `middleware.Gzip`, i.e. for routes serving
+// already compressed content or streams that must not be buffered.
+//
+// Example:
+//
+//	e.GET("/archives/:name", downloadArchive).Compress(false)
+func (r *Route) Compress(enabled bool) *Route {
+	return r.SetMeta(MetaCompress, enabled)
+}
+
+// BodyLimit sets maximum allowed size of request body of the route enforced by `middleware.BodyLimit` instead of
+// limit of the middleware config. Limit is specified as `4x` or `4xB`, where x is one of the multiple from K, M, G,
+// T or P.
+//
+// Example:
+//
+//	e.POST("/uploads", upload).BodyLimit("100M")
+func (r *Route) BodyLimit(limit string) *Route {
+	n, err := bytes.Parse(limit)
+	if err != nil {
+		panic(fmt.Errorf("echo: invalid body-limit=%s", limit))
+	}
+	return r.SetMeta(MetaBodyLimit, n)
+}
+
+// RateLimitCost sets cost of requests of the route deducted by `middleware.RateLimiter` from limit of the client
+// instead of 1, i.e. for expensive reports or batch operations. Cost 0 makes requests free.
+//
+// Example:
+//
+//	e.POST("/reports", generateReport).RateLimitCost(10)
+func (r *Route) RateLimitCost(cost int) *Route {
+	if cost < 0 {
+		panic(fmt.Errorf("echo: invalid rate-limit-cost=%d", cost))
+	}
+	return r.SetMeta(MetaRateLimitCost, cost)
+}
+
+// RouteDeprecation describes deprecated route.
+type RouteDeprecation struct {
+	// Sunset is time after which route becomes unavailable. Zero value means sunset is not planned yet.
+	Sunset time.Time
+
+	// Link is URL of documentation of the deprecation, i.e. migration guide.
+	Link string
+}
+
+// Deprecated marks the route as deprecated. `middleware.Deprecation` announces deprecation and sunset of the route
+// in response headers, logs usage of the route and optionally rejects requests after sunset.
+//
+// Example:
+//
+//	e.GET("/v1/users", listUsersV1).Deprecated(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "https://example.com/migrate-v2")
+func (r *Route) Deprecated(sunset time.Time, link string) *Route {
+	return r.SetMeta(MetaDeprecation, &RouteDeprecation{Sunset: sunset, Link: link})
+}
+
+// ExpectContinuePolicy decides whether client which sent request with `Expect: 100-continue` header may send the
+// request body. Policy is checked before the body is read (Go server sends `100 Continue` on first read of the
+// body) so rejected clients do not upload large bodies in vain.
+type ExpectContinuePolicy struct {
+	// Reject rejects all requests expecting 100-continue with 417 Expectation Failed, i.e. for routes not
+	// accepting uploads.
+	Reject bool
+
+	// MaxContentLength rejects requests announcing larger `Content-Length` with 413 Request Entity Too Large. Zero
+	// value means no limit. Requests with unknown length (chunked) are not rejected, limit their body with
+	// `middleware.BodyLimit`.
+	MaxContentLength int64
+
+	// Check is called before the body is read, i.e. to authorize the upload. Returned error rejects the request.
+	Check func(c Context) error
+}
+
+// ExpectContinue sets `Expect: 100-continue` policy of the route.
+//
+// Example:
+//
+//	e.PUT("/uploads/:name", upload).ExpectContinue(echo.ExpectContinuePolicy{MaxContentLength: 1 << 30})
+func (r *Route) ExpectContinue(policy ExpectContinuePolicy) *Route {
+	return r.SetMeta(MetaExpectContinue, &policy)
+}
+
+// RecoveryPolicy defines how `middleware.Recover` handles panic of the route handler.
+type RecoveryPolicy uint8
+
+const (
+	// RecoveryDefault uses policy of the group or of the Recover middleware config.
+	RecoveryDefault RecoveryPolicy = iota
+	// RecoveryRespond converts panic to 500 Internal Server Error response.
+	RecoveryRespond
+	// RecoveryRetry runs the handler once more when response was not sent yet and converts second panic to 500
+	// response. Use only for idempotent handlers, request body is already read by the first run.
+	RecoveryRetry
+	// RecoveryCrash logs the panic and crashes the process, for services preferring restart over serving
+	// possibly corrupted state.
+	RecoveryCrash
+)
+
+// RecoveryPolicy sets panic recovery policy of the route, it takes precedence over policy of the group.
+//
+// Example:
+//
+//	e.POST("/ledger/transfer", transfer).RecoveryPolicy(echo.RecoveryCrash)
+func (r *Route) RecoveryPolicy(policy RecoveryPolicy) *Route {
+	return r.SetMeta(MetaRecoveryPolicy, policy)
+}
+
+// RecoveryPolicyOf returns panic recovery policy of the matched route: policy set on the route, or on its group or
+// closest parent group. RecoveryDefault is returned when none is set.
+func RecoveryPolicyOf(c Context) RecoveryPolicy {
+	r := RouteOf(c)
+	if r == nil {
+		return RecoveryDefault
+	}
+	if p, ok := r.GetMeta(MetaRecoveryPolicy).(RecoveryPolicy); ok && p != RecoveryDefault {
+		return p
+	}
+	g, _ := r.GetMeta(metaGroup).(*Group)
+	return g.recoveryPolicy()
+}
+
+// ContentTypes sets request content types accepted by the route, they take precedence over content types of the
+// group and of the `middleware.ContentType` config.
+//
+// Example:
+//
+//	e.POST("/images", upload).ContentTypes("image/png", "image/jpeg")
+func (r *Route) ContentTypes(allowed ...string) *Route {
+	return r.SetMeta(MetaContentTypes, allowed)
+}
+
+// ContentTypesOf returns request content types accepted by the matched route: content types set on the route, or on
+// its group or closest parent group. Nil is returned when none is set.
+func ContentTypesOf(c Context) []string {
+	r := RouteOf(c)
+	if r == nil {
+		return nil
+	}
+	if allowed, ok := r.GetMeta(MetaContentTypes).([]string); ok {
+		return allowed
+	}
+	g, _ := r.GetMeta(metaGroup).(*Group)
+	return g.contentTypes()
+}
+
+// RouteOf returns the registered route matched for the request or nil when request did not match any route.
+func RouteOf(c Context) *Route {
+	if cc := contextOf(c); cc != nil {
+		return cc.route()
+	}
+	r := c.Request()
+	if c.Path() == "" || r == nil {
+		return nil
+	}
+	return c.Echo().findRouter(r.Host).routes[r.Method+c.Path()]
+}
+
+func (c *context) route() *Route {
+	if c.path == "" || c.request == nil {
+		return nil
+	}
+	router := c.router
+	if router == nil {
+		router = c.echo.findRouter(c.request.Host)
+	}
+	return router.routes[c.request.Method+c.path]
+}
@@ -0,0 +1,131 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Route(t *testing.T) {
+	e := New()
+	var route *Route
+	e.GET("/users/:id", func(c Context) error {
+		route = RouteOf(c)
+		return nil
+	}).SetMeta("owner", "accounts")
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if assert.NotNil(t, route) {
+		assert.Equal(t, "/users/:id", route.Path)
+		assert.Equal(t, "accounts", route.GetMeta("owner"))
+		assert.Nil(t, route.GetMeta("unknown"))
+		assert.Equal(t, Map{"owner": "accounts"}, route.Meta())
+	}
+
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	assert.Nil(t, RouteOf(c))
+}
+
+func TestContext_RouteOfHost(t *testing.T) {
+	e := New()
+	var route *Route
+	h := func(c Context) error {
+		route = RouteOf(c)
+		return nil
+	}
+	e.POST("/upload", h).SetMeta("owner", "web")
+	e.Host("api.example.com").POST("/upload", h).SetMeta("owner", "api")
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	req.Host = "api.example.com"
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	if assert.NotNil(t, route) {
+		assert.Equal(t, "api", route.GetMeta("owner"))
+	}
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/upload", nil))
+	if assert.NotNil(t, route) {
+		assert.Equal(t, "web", route.GetMeta("owner"))
+	}
+	assert.Len(t, e.Routes(), 2)
+}
+
+func TestRoute_Deprecated(t *testing.T) {
+	e := New()
+	sunset := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	r := e.GET("/v1", func(c Context) error { return nil }).Deprecated(sunset, "https://example.com/migrate")
+
+	assert.Equal(t, &RouteDeprecation{Sunset: sunset, Link: "https://example.com/migrate"}, r.GetMeta(MetaDeprecation))
+}
+
+func TestRoute_Policies(t *testing.T) {
+	e := New()
+	r := e.POST("/uploads", func(c Context) error { return nil }).
+		Compress(false).
+		CacheTTL(30 * time.Second).
+		BodyLimit("10M").
+		RateLimitCost(5)
+
+	assert.Equal(t, false, r.GetMeta(MetaCompress))
+	assert.Equal(t, "max-age=30", r.GetMeta(MetaCachePolicy).(*CacheControl).String())
+	assert.Equal(t, int64(10*1024*1024), r.GetMeta(MetaBodyLimit))
+	assert.PanicsWithError(t, "echo: invalid body-limit=10X", func() {
+		r.BodyLimit("10X")
+	})
+	assert.Equal(t, 5, r.GetMeta(MetaRateLimitCost))
+	assert.PanicsWithError(t, "echo: invalid rate-limit-cost=-1", func() {
+		r.RateLimitCost(-1)
+	})
+}
+
+func TestContentTypesOf(t *testing.T) {
+	e := New()
+	var allowed []string
+	h := func(c Context) error {
+		allowed = ContentTypesOf(c)
+		return nil
+	}
+	e.POST("/plain", h)
+	g := e.Group("/api")
+	g.ContentTypes = []string{MIMEApplicationJSON}
+	sg := g.Group("/v2")
+	sg.POST("/group", h)
+	sg.POST("/route", h).ContentTypes(MIMEApplicationXML)
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/plain", nil))
+	assert.Nil(t, allowed)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v2/group", nil))
+	assert.Equal(t, []string{MIMEApplicationJSON}, allowed)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v2/route", nil))
+	assert.Equal(t, []string{MIMEApplicationXML}, allowed)
+}
+
+func TestRecoveryPolicyOf(t *testing.T) {
+	e := New()
+	api := e.Group("/api")
+	api.RecoveryPolicy = RecoveryCrash
+	v1 := api.Group("/v1")
+	v1.GET("/inherited", NotFoundHandler)
+	v1.GET("/own", NotFoundHandler).RecoveryPolicy(RecoveryRetry)
+	e.GET("/none", NotFoundHandler)
+
+	var testCases = []struct {
+		path   string
+		expect RecoveryPolicy
+	}{
+		{path: "/api/v1/inherited", expect: RecoveryCrash},
+		{path: "/api/v1/own", expect: RecoveryRetry},
+		{path: "/none", expect: RecoveryDefault},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, tc.path, nil), httptest.NewRecorder())
+			e.Router().Find(http.MethodGet, tc.path, c)
+			assert.Equal(t, tc.expect, RecoveryPolicyOf(c))
+		})
+	}
+}
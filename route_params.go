@@ -0,0 +1,185 @@
+package echo
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+type (
+	// ParamType is type of route parameter value. See `RouteParam`.
+	ParamType string
+
+	// RouteParam declares type of path or query parameter of the route. Router coerces and validates declared
+	// parameters before the handler is called and responds with 400 Bad Request to requests with missing or
+	// invalid values. Coerced values are returned by `TypedParam()`.
+	RouteParam struct {
+		// Name of the parameter.
+		Name string `json:"name"`
+
+		// In is location of the parameter, "path" or "query".
+		In string `json:"in"`
+
+		// Type of the parameter value. Default value ParamString.
+		Type ParamType `json:"type"`
+
+		// Required rejects requests without the (query) parameter. Path parameters are always required.
+		Required bool `json:"required,omitempty"`
+
+		// Enum lists allowed values of the parameter.
+		Enum []string `json:"enum,omitempty"`
+
+		// Description of the parameter.
+		Description string `json:"description,omitempty"`
+	}
+)
+
+// Route parameter types. Values are coerced to string, int64, float64 and bool respectively.
+const (
+	ParamString  ParamType = "string"
+	ParamInteger ParamType = "integer"
+	ParamNumber  ParamType = "number"
+	ParamBoolean ParamType = "boolean"
+)
+
+// MetaParams is metadata key of route parameter declarations (`[]RouteParam`) set by `Route#Params()`.
+const MetaParams = "params"
+
+// typedRoutes counts routes with declared parameters so router looks up route metadata only when it is needed.
+var typedRoutes int32
+
+// Params declares types of path and query parameters of the route and returns the route so calls can be chained.
+//
+// Example:
+//
+//	e.GET("/users/:id", getUser).Params(
+//		echo.RouteParam{Name: "id", In: "path", Type: echo.ParamInteger},
+//		echo.RouteParam{Name: "expand", In: "query", Type: echo.ParamBoolean},
+//	)
+//
+//	func getUser(c echo.Context) error {
+//		id := echo.TypedParam(c, "id").(int64)
+//		...
+//	}
+func (r *Route) Params(params ...RouteParam) *Route {
+	for i, p := range params {
+		if p.In != "path" && p.In != "query" {
+			panic(fmt.Sprintf("echo: invalid location %q of route parameter %q", p.In, p.Name))
+		}
+		switch p.Type {
+		case "":
+			params[i].Type = ParamString
+		case ParamString, ParamInteger, ParamNumber, ParamBoolean:
+		default:
+			panic(fmt.Sprintf("echo: invalid type %q of route parameter %q", p.Type, p.Name))
+		}
+		if p.In == "path" {
+			params[i].Required = true
+		}
+	}
+	if r.GetMeta(MetaParams) == nil {
+		atomic.AddInt32(&typedRoutes, 1)
+	}
+	return r.SetMeta(MetaParams, params)
+}
+
+// GetParams returns parameter declarations of the route.
+func (r *Route) GetParams() []RouteParam {
+	params, _ := r.GetMeta(MetaParams).([]RouteParam)
+	return params
+}
+
+// TypedParam returns value of path or query parameter coerced to type declared with `Route#Params()` or nil when
+// parameter is not declared or not sent.
+func TypedParam(c Context, name string) interface{} {
+	if cc := contextOf(c); cc != nil {
+		return cc.typed[name]
+	}
+	return nil
+}
+
+// coerceParams validates and coerces parameters declared for the matched route. Handler of the context is replaced
+// with handler returning error when parameter is missing or invalid.
+func (c *context) coerceParams() {
+	if atomic.LoadInt32(&typedRoutes) == 0 {
+		return
+	}
+	r := c.route()
+	if r == nil {
+		return
+	}
+	params := r.GetParams()
+	if len(params) == 0 {
+		return
+	}
+	if c.typed == nil {
+		c.typed = Map{}
+	}
+	for _, p := range params {
+		var raw string
+		var ok bool
+		if p.In == "path" {
+			raw = c.Param(p.Name)
+			ok = raw != ""
+		} else if values, exists := c.QueryParams()[p.Name]; exists && len(values) > 0 {
+			raw, ok = values[0], true
+		}
+		if !ok {
+			if p.Required {
+				c.rejectParam(fmt.Sprintf("missing %s parameter %q", p.In, p.Name))
+				return
+			}
+			continue
+		}
+		v, err := p.coerce(raw)
+		if err != nil {
+			c.rejectParam(fmt.Sprintf("invalid value of %s parameter %q: %v", p.In, p.Name, err))
+			return
+		}
+		c.typed[p.Name] = v
+	}
+}
+
+func (c *context) rejectParam(message string) {
+	err := NewHTTPError(http.StatusBadRequest, message)
+	c.handler = func(c Context) error {
+		return err
+	}
+}
+
+func (p RouteParam) coerce(raw string) (interface{}, error) {
+	if len(p.Enum) > 0 {
+		allowed := false
+		for _, e := range p.Enum {
+			if raw == e {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, fmt.Errorf("expected one of %v", p.Enum)
+		}
+	}
+	switch p.Type {
+	case ParamInteger:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected %s", p.Type)
+		}
+		return v, nil
+	case ParamNumber:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected %s", p.Type)
+		}
+		return v, nil
+	case ParamBoolean:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected %s", p.Type)
+		}
+		return v, nil
+	}
+	return raw, nil
+}
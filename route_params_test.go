@@ -0,0 +1,105 @@
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoute_Params(t *testing.T) {
+	e := New()
+	var typed Map
+	e.GET("/users/:id", func(c Context) error {
+		typed = Map{"id": TypedParam(c, "id"), "score": TypedParam(c, "score"), "active": TypedParam(c, "active"),
+			"sort": TypedParam(c, "sort"), "unknown": TypedParam(c, "unknown")}
+		return c.NoContent(http.StatusOK)
+	}).Params(
+		RouteParam{Name: "id", In: "path", Type: ParamInteger},
+		RouteParam{Name: "score", In: "query", Type: ParamNumber},
+		RouteParam{Name: "active", In: "query", Type: ParamBoolean, Required: true},
+		RouteParam{Name: "sort", In: "query", Enum: []string{"asc", "desc"}},
+	)
+	e.GET("/plain/:id", func(c Context) error {
+		return c.String(http.StatusOK, c.Param("id"))
+	})
+
+	var testCases = []struct {
+		name          string
+		whenURL       string
+		expectCode    int
+		expectMessage string
+		expectTyped   Map
+	}{
+		{
+			name:        "ok",
+			whenURL:     "/users/42?score=1.5&active=true&sort=asc",
+			expectCode:  http.StatusOK,
+			expectTyped: Map{"id": int64(42), "score": 1.5, "active": true, "sort": "asc", "unknown": nil},
+		},
+		{
+			name:        "optional params missing",
+			whenURL:     "/users/42?active=0",
+			expectCode:  http.StatusOK,
+			expectTyped: Map{"id": int64(42), "score": nil, "active": false, "sort": nil, "unknown": nil},
+		},
+		{
+			name:          "invalid path param",
+			whenURL:       "/users/abc?active=true",
+			expectCode:    http.StatusBadRequest,
+			expectMessage: `invalid value of path parameter "id": expected integer`,
+		},
+		{
+			name:          "missing required query param",
+			whenURL:       "/users/1",
+			expectCode:    http.StatusBadRequest,
+			expectMessage: `missing query parameter "active"`,
+		},
+		{
+			name:          "invalid enum value",
+			whenURL:       "/users/1?active=true&sort=random",
+			expectCode:    http.StatusBadRequest,
+			expectMessage: `invalid value of query parameter "sort": expected one of [asc desc]`,
+		},
+		{
+			name:       "route without params",
+			whenURL:    "/plain/abc",
+			expectCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			typed = nil
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.whenURL, nil))
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectMessage != "" {
+				var body map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, tc.expectMessage, body["message"])
+			}
+			if tc.expectTyped != nil {
+				assert.Equal(t, tc.expectTyped, typed)
+			}
+		})
+	}
+}
+
+func TestRoute_ParamsDeclarations(t *testing.T) {
+	e := New()
+	r := e.GET("/users/:id", func(c Context) error { return nil }).Params(RouteParam{Name: "id", In: "path"})
+
+	assert.Equal(t, []RouteParam{{Name: "id", In: "path", Type: ParamString, Required: true}}, r.GetParams())
+	assert.Equal(t, r.GetParams(), RouteDocs(e)[0].Params)
+
+	assert.PanicsWithValue(t, `echo: invalid location "body" of route parameter "id"`, func() {
+		r.Params(RouteParam{Name: "id", In: "body"})
+	})
+	assert.PanicsWithValue(t, `echo: invalid type "uuid" of route parameter "id"`, func() {
+		r.Params(RouteParam{Name: "id", In: "path", Type: "uuid"})
+	})
+}
@@ -2,6 +2,7 @@ package echo
 
 import (
 	"net/http"
+	"strings"
 )
 
 type (
@@ -11,6 +12,8 @@ type (
 		tree   *node
 		routes map[string]*Route
 		echo   *Echo
+		// static indexes nodes of routes without path params by their full path. Used only by RouterV2 engine.
+		static map[string]*staticRoute
 	}
 	node struct {
 		kind           kind
@@ -128,6 +131,9 @@ func (r *Router) Add(method, path string, h HandlerFunc) {
 	}
 
 	r.insert(method, path, h, staticKind, ppath, pnames)
+	if r.static != nil && len(pnames) == 0 {
+		r.indexStatic(path)
+	}
 }
 
 func (r *Router) insert(method, path string, h HandlerFunc, t kind, ppath string, pnames []string) {
@@ -334,41 +340,59 @@ func (n *node) addHandler(method string, h HandlerFunc) {
 }
 
 func (n *node) findHandler(method string) HandlerFunc {
+	return n.methodHandler.find(method)
+}
+
+func (m *methodHandler) find(method string) HandlerFunc {
 	switch method {
 	case http.MethodConnect:
-		return n.methodHandler.connect
+		return m.connect
 	case http.MethodDelete:
-		return n.methodHandler.delete
+		return m.delete
 	case http.MethodGet:
-		return n.methodHandler.get
+		return m.get
 	case http.MethodHead:
-		return n.methodHandler.head
+		return m.head
 	case http.MethodOptions:
-		return n.methodHandler.options
+		return m.options
 	case http.MethodPatch:
-		return n.methodHandler.patch
+		return m.patch
 	case http.MethodPost:
-		return n.methodHandler.post
+		return m.post
 	case PROPFIND:
-		return n.methodHandler.propfind
+		return m.propfind
 	case http.MethodPut:
-		return n.methodHandler.put
+		return m.put
 	case http.MethodTrace:
-		return n.methodHandler.trace
+		return m.trace
 	case REPORT:
-		return n.methodHandler.report
+		return m.report
 	default:
 		return nil
 	}
 }
 
-func (n *node) checkMethodNotAllowed() HandlerFunc {
-	for _, m := range methods {
-		if h := n.findHandler(m); h != nil {
-			return MethodNotAllowedHandler
+// AllowedMethods returns value of `Allow` header listing methods registered for path of the request when router
+// matched the path but not the request method (405 Method Not Allowed or automatic OPTIONS response), i.e. for
+// answering CORS preflight requests. It returns empty string otherwise.
+func AllowedMethods(c Context) string {
+	ctx, ok := c.(*context)
+	if !ok || ctx.allowed == nil {
+		return ""
+	}
+	return ctx.allowed.allowHeader(ctx.echo != nil && ctx.echo.AutoOptions)
+}
+
+// allowHeader returns value of `Allow` header listing methods with handlers. OPTIONS is listed also when it is
+// answered automatically.
+func (m *methodHandler) allowHeader(withOptions bool) string {
+	allow := make([]string, 0, len(methods))
+	for _, method := range methods {
+		if m.find(method) != nil || (withOptions && method == http.MethodOptions) {
+			allow = append(allow, method)
 		}
 	}
-	return NotFoundHandler
+	return strings.Join(allow, ", ")
 }
 
 // Find lookup a handler registered for method and path. It also parses URL for path
@@ -381,6 +405,11 @@ func (n *node) checkMethodNotAllowed() HandlerFunc {
 // - Return it `Echo#ReleaseContext()`.
 func (r *Router) Find(method, path string, c Context) {
 	ctx := c.(*context)
+	ctx.allowed = nil
+	ctx.router = r
+	if r.static != nil && r.findStatic(method, path, ctx) {
+		return
+	}
 	ctx.path = path
 	currentNode := r.tree // Current node as root
 
@@ -563,7 +592,15 @@ func (r *Router) Find(method, path string, c Context) {
 		// use previous match as basis. although we have no matching handler we have path match.
 		// so we can send http.StatusMethodNotAllowed (405) instead of http.StatusNotFound (404)
 		currentNode = previousBestMatchNode
-		ctx.handler = currentNode.checkMethodNotAllowed()
+		ctx.handler = NotFoundHandler
+		if currentNode.isHandler {
+			// `Allow` header is computed only when needed, see AllowedMethods()
+			ctx.allowed = currentNode.methodHandler
+			ctx.handler = methodNotAllowedHandler
+			if method == http.MethodOptions && r.echo != nil && r.echo.AutoOptions {
+				ctx.handler = optionsMethodHandler
+			}
+		}
 	}
 	ctx.path = currentNode.ppath
 	ctx.pnames = currentNode.pnames
@@ -3,14 +3,22 @@ package echo
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// testRoute is route to register and find in router tests.
+type testRoute struct {
+	Method string
+	Path   string
+	Name   string
+}
+
 var (
-	staticRoutes = []*Route{
+	staticRoutes = []*testRoute{
 		{"GET", "/", ""},
 		{"GET", "/cmd.html", ""},
 		{"GET", "/code.html", ""},
@@ -170,7 +178,7 @@ var (
 		{"GET", "/progs/update.bash", ""},
 	}
 
-	gitHubAPI = []*Route{
+	gitHubAPI = []*testRoute{
 		// OAuth Authorizations
 		{"GET", "/authorizations", ""},
 		{"GET", "/authorizations/:id", ""},
@@ -483,7 +491,7 @@ var (
 		{"DELETE", "/user/keys/:id", ""},
 	}
 
-	parseAPI = []*Route{
+	parseAPI = []*testRoute{
 		// Objects
 		{"POST", "/1/classes/:className", ""},
 		{"GET", "/1/classes/:className/:objectId", ""},
@@ -527,7 +535,7 @@ var (
 		{"POST", "/1/functions", ""},
 	}
 
-	googlePlusAPI = []*Route{
+	googlePlusAPI = []*testRoute{
 		// People
 		{"GET", "/people/:userId", ""},
 		{"GET", "/people", ""},
@@ -550,7 +558,7 @@ var (
 		{"DELETE", "/moments/:id", ""},
 	}
 
-	paramAndAnyAPI = []*Route{
+	paramAndAnyAPI = []*testRoute{
 		{"GET", "/root/:first/foo/*", ""},
 		{"GET", "/root/:first/:second/*", ""},
 		{"GET", "/root/:first/bar/:second/*", ""},
@@ -580,7 +588,7 @@ var (
 		{"DELETE", "/root/*", ""},
 	}
 
-	paramAndAnyAPIToFind = []*Route{
+	paramAndAnyAPIToFind = []*testRoute{
 		{"GET", "/root/one/foo/after/the/asterisk", ""},
 		{"GET", "/root/one/foo/path/after/the/asterisk", ""},
 		{"GET", "/root/one/two/path/after/the/asterisk", ""},
@@ -610,7 +618,7 @@ var (
 		{"DELETE", "/root/one/qux/two/three/four/after/the/asterisk", ""},
 	}
 
-	missesAPI = []*Route{
+	missesAPI = []*testRoute{
 		{"GET", "/missOne", ""},
 		{"GET", "/miss/two", ""},
 		{"GET", "/miss/three/levels", ""},
@@ -2027,7 +2035,7 @@ func TestRouterParamBacktraceNotFound(t *testing.T) {
 	}
 }
 
-func testRouterAPI(t *testing.T, api []*Route) {
+func testRouterAPI(t *testing.T, api []*testRoute) {
 	e := New()
 	r := e.router
 
@@ -2056,7 +2064,7 @@ func TestRouterGitHubAPI(t *testing.T) {
 
 // Issue #729
 func TestRouterParamAlias(t *testing.T) {
-	api := []*Route{
+	api := []*testRoute{
 		{http.MethodGet, "/users/:userID/following", ""},
 		{http.MethodGet, "/users/:userID/followedBy", ""},
 		{http.MethodGet, "/users/:userID/follow", ""},
@@ -2066,19 +2074,19 @@ func TestRouterParamAlias(t *testing.T) {
 
 // Issue #1052
 func TestRouterParamOrdering(t *testing.T) {
-	api := []*Route{
+	api := []*testRoute{
 		{http.MethodGet, "/:a/:b/:c/:id", ""},
 		{http.MethodGet, "/:a/:id", ""},
 		{http.MethodGet, "/:a/:e/:id", ""},
 	}
 	testRouterAPI(t, api)
-	api2 := []*Route{
+	api2 := []*testRoute{
 		{http.MethodGet, "/:a/:id", ""},
 		{http.MethodGet, "/:a/:e/:id", ""},
 		{http.MethodGet, "/:a/:b/:c/:id", ""},
 	}
 	testRouterAPI(t, api2)
-	api3 := []*Route{
+	api3 := []*testRoute{
 		{http.MethodGet, "/:a/:b/:c/:id", ""},
 		{http.MethodGet, "/:a/:e/:id", ""},
 		{http.MethodGet, "/:a/:id", ""},
@@ -2088,12 +2096,12 @@ func TestRouterParamOrdering(t *testing.T) {
 
 // Issue #1139
 func TestRouterMixedParams(t *testing.T) {
-	api := []*Route{
+	api := []*testRoute{
 		{http.MethodGet, "/teacher/:tid/room/suggestions", ""},
 		{http.MethodGet, "/teacher/:id", ""},
 	}
 	testRouterAPI(t, api)
-	api2 := []*Route{
+	api2 := []*testRoute{
 		{http.MethodGet, "/teacher/:id", ""},
 		{http.MethodGet, "/teacher/:tid/room/suggestions", ""},
 	}
@@ -2288,7 +2296,7 @@ func TestRouterPanicWhenParamNoRootOnlyChildsFailsFind(t *testing.T) {
 	}
 }
 
-func benchmarkRouterRoutes(b *testing.B, routes []*Route, routesToFind []*Route) {
+func benchmarkRouterRoutes(b *testing.B, routes []*testRoute, routesToFind []*testRoute) {
 	e := New()
 	r := e.router
 	b.ReportAllocs()
@@ -2378,3 +2386,53 @@ func prefix(tail bool, p, on, off string) string {
 	}
 	return fmt.Sprintf("%s%s", p, off)
 }
+
+func TestRouterAllowHeader(t *testing.T) {
+	e := New()
+	e.GET("/users", handlerFunc)
+	e.POST("/users", handlerFunc)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, POST", rec.Header().Get(HeaderAllow))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "OPTIONS is not answered by default")
+
+	c := e.NewContext(nil, nil)
+	e.router.Find(http.MethodGet, "/users", c)
+	assert.Equal(t, "", AllowedMethods(c))
+	e.router.Find(http.MethodPut, "/users", c)
+	assert.Equal(t, "GET, POST", AllowedMethods(c))
+}
+
+func TestRouterAutoOptions(t *testing.T) {
+	e := New()
+	e.AutoOptions = true
+	e.GET("/users", handlerFunc)
+	e.POST("/users", handlerFunc)
+	e.OPTIONS("/custom", func(c Context) error {
+		return c.String(http.StatusOK, "custom")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "GET, OPTIONS, POST", rec.Header().Get(HeaderAllow))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, OPTIONS, POST", rec.Header().Get(HeaderAllow))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/custom", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "custom", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,75 @@
+package echo
+
+// RouterEngine selects the algorithm router uses to match requests. See `Echo#UseRouter()`.
+type RouterEngine uint8
+
+const (
+	// RouterV1 matches every request by traversing the radix tree. This is the default engine.
+	RouterV1 RouterEngine = iota
+	// RouterV2 additionally keeps precomputed map of routes without path params (static routes). Requests to static
+	// routes are matched with single map lookup regardless of number of registered routes, other requests are
+	// matched by the radix tree the same way as with RouterV1. Path param values are written to the preallocated
+	// slice of the context so matching does not allocate.
+	RouterV2
+)
+
+// staticRoute is entry in static route index. Method handler is shared with the tree node so handlers added to
+// the route later are visible to the index without re-indexing.
+type staticRoute struct {
+	methodHandler *methodHandler
+	ppath         string
+	pnames        []string
+}
+
+func (r *Router) useEngine(engine RouterEngine) {
+	if engine != RouterV2 {
+		r.static = nil
+		return
+	}
+	r.static = map[string]*staticRoute{}
+	r.indexStaticChildren(r.tree, "")
+}
+
+// indexStaticChildren adds node and all its static descendants that have handlers to the static route index.
+func (r *Router) indexStaticChildren(n *node, path string) {
+	path += n.prefix
+	if n.isHandler {
+		r.static[path] = &staticRoute{methodHandler: n.methodHandler, ppath: n.ppath, pnames: n.pnames}
+	}
+	for _, c := range n.staticChildren {
+		r.indexStaticChildren(c, path)
+	}
+}
+
+// indexStatic adds route with given (unescaped) path to the static route index.
+func (r *Router) indexStatic(path string) {
+	n := r.tree
+	search := path
+	for n != nil && len(search) >= len(n.prefix) && search[:len(n.prefix)] == n.prefix {
+		search = search[len(n.prefix):]
+		if search == "" {
+			if n.kind == staticKind {
+				r.static[path] = &staticRoute{methodHandler: n.methodHandler, ppath: n.ppath, pnames: n.pnames}
+			}
+			return
+		}
+		n = n.findStaticChild(search[0])
+	}
+}
+
+// findStatic matches request to static route from the index. Returns false when request must be matched by the
+// tree (no static route or route has no handler for the method).
+func (r *Router) findStatic(method, path string, ctx *context) bool {
+	route, ok := r.static[path]
+	if !ok {
+		return false
+	}
+	h := route.methodHandler.find(method)
+	if h == nil {
+		return false
+	}
+	ctx.handler = h
+	ctx.path = route.ppath
+	ctx.pnames = route.pnames
+	return true
+}
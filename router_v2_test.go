@@ -0,0 +1,155 @@
+package echo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type routerMatch struct {
+	err    string
+	path   string
+	params map[string]string
+}
+
+func findRouterMatch(e *Echo, router *Router, method, path string) routerMatch {
+	c := e.NewContext(nil, nil).(*context)
+	router.Find(method, path, c)
+	m := routerMatch{path: c.Path(), params: map[string]string{}}
+	if err := c.Handler()(c); err != nil {
+		m.err = err.Error()
+	}
+	for i, name := range c.ParamNames() {
+		m.params[name] = c.ParamValues()[i]
+	}
+	return m
+}
+
+func TestRouterV2_sameMatchesAsV1(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		routes []*testRoute
+		find   []*testRoute
+	}{
+		{name: "static", routes: staticRoutes, find: staticRoutes},
+		{name: "static misses", routes: staticRoutes, find: missesAPI},
+		{name: "github", routes: gitHubAPI, find: gitHubAPI},
+		{name: "github misses", routes: gitHubAPI, find: missesAPI},
+		{name: "parse", routes: parseAPI, find: parseAPI},
+		{name: "google plus", routes: googlePlusAPI, find: googlePlusAPI},
+		{name: "params and any", routes: paramAndAnyAPI, find: paramAndAnyAPIToFind},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e1 := New()
+			e2 := New()
+			e2.UseRouter(RouterV2)
+			for _, r := range tc.routes {
+				route := r
+				h := func(c Context) error {
+					return errors.New(route.Method + " " + route.Path)
+				}
+				e1.router.Add(route.Method, route.Path, h)
+				e2.router.Add(route.Method, route.Path, h)
+			}
+
+			for _, r := range tc.find {
+				for _, method := range []string{r.Method, http.MethodPatch} {
+					expect := findRouterMatch(e1, e1.router, method, r.Path)
+					assert.Equal(t, expect, findRouterMatch(e2, e2.router, method, r.Path), method+" "+r.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestRouterV2(t *testing.T) {
+	e := New()
+	e.GET("/users", handlerFunc)
+	e.UseRouter(RouterV2)
+	e.POST("/users", handlerFunc)
+	e.GET("/users/new", handlerFunc)
+	e.GET("/users/:id", handlerFunc)
+	e.GET(`/users/a\:b`, handlerFunc)
+	e.GET("/u", handlerFunc) // splits "/users" node after it was indexed
+	e.PUT("/*", handlerFunc)
+
+	assert.Len(t, e.router.static, 4)
+	assert.Equal(t, routerMatch{path: "/users", params: map[string]string{}}, findRouterMatch(e, e.router, http.MethodGet, "/users"))
+	assert.Equal(t, routerMatch{path: "/users", params: map[string]string{}}, findRouterMatch(e, e.router, http.MethodPost, "/users"))
+	assert.Equal(t, routerMatch{path: "/u", params: map[string]string{}}, findRouterMatch(e, e.router, http.MethodGet, "/u"))
+	assert.Equal(t, routerMatch{path: `/users/a\:b`, params: map[string]string{}}, findRouterMatch(e, e.router, http.MethodGet, "/users/a:b"))
+	assert.Equal(t, routerMatch{path: "/users/:id", params: map[string]string{"id": "1"}}, findRouterMatch(e, e.router, http.MethodGet, "/users/1"))
+	// method without static handler falls back to tree
+	assert.Equal(t, routerMatch{path: "/*", params: map[string]string{"*": "users"}}, findRouterMatch(e, e.router, http.MethodPut, "/users"))
+	assert.Equal(t, "code=405, message=Method Not Allowed", findRouterMatch(e, e.router, http.MethodDelete, "/users").err)
+
+	e.UseRouter(RouterV1)
+	assert.Nil(t, e.router.static)
+	assert.Equal(t, routerMatch{path: "/users", params: map[string]string{}}, findRouterMatch(e, e.router, http.MethodGet, "/users"))
+}
+
+func TestRouterV2_host(t *testing.T) {
+	e := New()
+	e.UseRouter(RouterV2)
+	e.Host("example.com").GET("/ping", handlerFunc)
+
+	router := e.Routers()["example.com"]
+	assert.Len(t, router.static, 1)
+	assert.Equal(t, routerMatch{path: "/ping", params: map[string]string{}}, findRouterMatch(e, router, http.MethodGet, "/ping"))
+}
+
+func manyRoutes(n int) []*testRoute {
+	routes := make([]*testRoute, 0, 2*n)
+	for i := 0; i < n; i++ {
+		routes = append(routes,
+			&testRoute{Method: http.MethodGet, Path: fmt.Sprintf("/api/v1/resource%d/items", i)},
+			&testRoute{Method: http.MethodGet, Path: fmt.Sprintf("/api/v1/resource%d/items/:id", i)},
+		)
+	}
+	return routes
+}
+
+func benchmarkRouterEngineRoutes(b *testing.B, engine RouterEngine, routes []*testRoute, routesToFind []*testRoute) {
+	e := New()
+	e.UseRouter(engine)
+	r := e.router
+	b.ReportAllocs()
+
+	for _, route := range routes {
+		r.Add(route.Method, route.Path, func(c Context) error {
+			return nil
+		})
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, route := range routesToFind {
+			c := e.pool.Get().(*context)
+			r.Find(route.Method, route.Path, c)
+			e.pool.Put(c)
+		}
+	}
+}
+
+func BenchmarkRouterV2StaticRoutes(b *testing.B) {
+	benchmarkRouterEngineRoutes(b, RouterV2, staticRoutes, staticRoutes)
+}
+
+func BenchmarkRouterV2GitHubAPI(b *testing.B) {
+	benchmarkRouterEngineRoutes(b, RouterV2, gitHubAPI, gitHubAPI)
+}
+
+func BenchmarkRouterV1ManyRoutes(b *testing.B) {
+	routes := manyRoutes(2500)
+	benchmarkRouterEngineRoutes(b, RouterV1, routes, routes)
+}
+
+func BenchmarkRouterV2ManyRoutes(b *testing.B) {
+	routes := manyRoutes(2500)
+	benchmarkRouterEngineRoutes(b, RouterV2, routes, routes)
+}
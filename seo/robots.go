@@ -0,0 +1,79 @@
+package seo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// RobotsConfig defines the config for Robots handler.
+	RobotsConfig struct {
+		// Groups are rule groups for user agents. When empty, all crawlers are allowed everything.
+		Groups []RobotsGroup
+
+		// Sitemaps are absolute URLs of sitemaps advertised to crawlers.
+		Sitemaps []string
+	}
+
+	// RobotsGroup is group of rules applied to listed user agents.
+	RobotsGroup struct {
+		// UserAgents this group applies to, e.g. "*" or "Googlebot".
+		// Optional. Default value "*".
+		UserAgents []string
+		// Allow lists path prefixes crawlers are allowed to access.
+		Allow []string
+		// Disallow lists path prefixes crawlers must not access.
+		Disallow []string
+		// CrawlDelay is number of seconds crawler should wait between requests. Zero value is omitted.
+		CrawlDelay int
+	}
+)
+
+// Robots returns handler serving robots.txt built from given config.
+func Robots(config RobotsConfig) echo.HandlerFunc {
+	body := []byte(config.String())
+	return func(c echo.Context) error {
+		return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, body)
+	}
+}
+
+// String returns robots.txt content for config.
+func (config RobotsConfig) String() string {
+	groups := config.Groups
+	if len(groups) == 0 {
+		groups = []RobotsGroup{{Disallow: []string{""}}}
+	}
+
+	b := new(strings.Builder)
+	for i, g := range groups {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		agents := g.UserAgents
+		if len(agents) == 0 {
+			agents = []string{"*"}
+		}
+		for _, ua := range agents {
+			b.WriteString("User-agent: " + ua + "\n")
+		}
+		for _, p := range g.Allow {
+			b.WriteString("Allow: " + p + "\n")
+		}
+		for _, p := range g.Disallow {
+			b.WriteString("Disallow: " + p + "\n")
+		}
+		if g.CrawlDelay > 0 {
+			b.WriteString("Crawl-delay: " + strconv.Itoa(g.CrawlDelay) + "\n")
+		}
+	}
+	if len(config.Sitemaps) > 0 {
+		b.WriteString("\n")
+		for _, s := range config.Sitemaps {
+			b.WriteString("Sitemap: " + s + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package seo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRobots(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		givenConfig RobotsConfig
+		expectBody  string
+	}{
+		{
+			name:       "ok, allow all by default",
+			expectBody: "User-agent: *\nDisallow: \n",
+		},
+		{
+			name: "ok, groups and sitemaps",
+			givenConfig: RobotsConfig{
+				Groups: []RobotsGroup{
+					{UserAgents: []string{"Googlebot", "Bingbot"}, Allow: []string{"/public"}, Disallow: []string{"/admin", "/tmp"}},
+					{Disallow: []string{"/"}, CrawlDelay: 10},
+				},
+				Sitemaps: []string{"https://example.com/sitemap.xml"},
+			},
+			expectBody: "User-agent: Googlebot\nUser-agent: Bingbot\nAllow: /public\nDisallow: /admin\nDisallow: /tmp\n" +
+				"\nUser-agent: *\nDisallow: /\nCrawl-delay: 10\n" +
+				"\nSitemap: https://example.com/sitemap.xml\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.GET("/robots.txt", Robots(tc.givenConfig))
+
+			req := httptest.NewRequest(http.MethodGet, "/robots.txt", nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, echo.MIMETextPlainCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+		})
+	}
+}
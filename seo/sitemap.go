@@ -0,0 +1,218 @@
+/*
+Package seo provides handlers for search engine related resources like sitemap.xml and robots.txt.
+
+Example:
+
+	e := echo.New()
+	e.GET("/", home).Name = "home"
+	e.GET("/about", about).Name = "about"
+
+	e.GET("/sitemap.xml", seo.Sitemap(e, seo.SitemapConfig{
+		BaseURL:    "https://example.com",
+		RouteNames: []string{"home", "about"},
+		Provider: func(c echo.Context) ([]seo.URL, error) {
+			return []seo.URL{{Loc: "/blog/hello-world", LastMod: time.Now()}}, nil
+		},
+	}))
+	e.GET("/robots.txt", seo.Robots(seo.RobotsConfig{
+		Groups:   []seo.RobotsGroup{{UserAgents: []string{"*"}, Disallow: []string{"/admin"}}},
+		Sitemaps: []string{"https://example.com/sitemap.xml"},
+	}))
+*/
+package seo
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// SitemapConfig defines the config for Sitemap handler.
+	SitemapConfig struct {
+		// BaseURL is scheme and host prepended to all relative locations, e.g. "https://example.com".
+		// Required.
+		BaseURL string
+
+		// RouteNames lists names of registered GET routes to be included in sitemap. Routes with path
+		// parameters can not be expanded and are skipped.
+		// Optional.
+		RouteNames []string
+
+		// Provider returns dynamic URLs (e.g. blog posts from database) to be included in sitemap.
+		// Optional.
+		Provider URLProvider
+
+		// MaxURLsPerSitemap is the number of URLs in single sitemap before sitemap index is served instead and
+		// URLs are split to pages (`?page=N`).
+		// Optional. Default value 50000 (maximum allowed by sitemaps.org protocol).
+		MaxURLsPerSitemap int
+	}
+
+	// URL is single location in sitemap.
+	URL struct {
+		// Loc is absolute URL or path relative to `SitemapConfig.BaseURL`.
+		Loc string
+		// LastMod is time when resource was last modified. Zero value is omitted.
+		LastMod time.Time
+		// ChangeFreq is one of "always", "hourly", "daily", "weekly", "monthly", "yearly" or "never".
+		ChangeFreq string
+		// Priority is value between 0.0 and 1.0. Zero value is omitted.
+		Priority float64
+	}
+
+	// URLProvider returns URLs to be included in sitemap.
+	URLProvider func(c echo.Context) ([]URL, error)
+
+	urlSet struct {
+		XMLName xml.Name     `xml:"urlset"`
+		XMLNS   string       `xml:"xmlns,attr"`
+		URLs    []sitemapURL `xml:"url"`
+	}
+
+	sitemapURL struct {
+		Loc        string `xml:"loc"`
+		LastMod    string `xml:"lastmod,omitempty"`
+		ChangeFreq string `xml:"changefreq,omitempty"`
+		Priority   string `xml:"priority,omitempty"`
+	}
+
+	sitemapIndex struct {
+		XMLName  xml.Name       `xml:"sitemapindex"`
+		XMLNS    string         `xml:"xmlns,attr"`
+		Sitemaps []sitemapEntry `xml:"sitemap"`
+	}
+
+	sitemapEntry struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod,omitempty"`
+	}
+)
+
+const (
+	sitemapXMLNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	// MaxSitemapURLs is the maximum number of URLs in single sitemap allowed by sitemaps.org protocol.
+	MaxSitemapURLs = 50000
+)
+
+// Sitemap returns handler serving sitemap.xml generated from named routes of given Echo instance and URLs from
+// configured provider. When there are more URLs than `SitemapConfig.MaxURLsPerSitemap` a sitemap index is served
+// which refers to pages of the sitemap served by the same handler (`?page=1`, `?page=2` etc.).
+func Sitemap(e *echo.Echo, config SitemapConfig) echo.HandlerFunc {
+	if config.BaseURL == "" {
+		panic("echo: sitemap requires base URL")
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+	if config.MaxURLsPerSitemap <= 0 || config.MaxURLsPerSitemap > MaxSitemapURLs {
+		config.MaxURLsPerSitemap = MaxSitemapURLs
+	}
+
+	return func(c echo.Context) error {
+		urls := routeURLs(e, config.RouteNames)
+		if config.Provider != nil {
+			provided, err := config.Provider(c)
+			if err != nil {
+				return err
+			}
+			urls = append(urls, provided...)
+		}
+
+		pages := (len(urls) + config.MaxURLsPerSitemap - 1) / config.MaxURLsPerSitemap
+		page := c.QueryParam("page")
+		if page == "" {
+			if pages > 1 {
+				return c.XML(http.StatusOK, newSitemapIndex(config, c.Request().URL.Path, urls, pages))
+			}
+			return c.XML(http.StatusOK, newURLSet(config, urls))
+		}
+
+		p, err := strconv.Atoi(page)
+		if err != nil || p < 1 || p > pages {
+			return echo.ErrNotFound
+		}
+		from := (p - 1) * config.MaxURLsPerSitemap
+		to := from + config.MaxURLsPerSitemap
+		if to > len(urls) {
+			to = len(urls)
+		}
+		return c.XML(http.StatusOK, newURLSet(config, urls[from:to]))
+	}
+}
+
+func routeURLs(e *echo.Echo, names []string) []URL {
+	if len(names) == 0 {
+		return nil
+	}
+	wanted := map[string]bool{}
+	for _, n := range names {
+		wanted[n] = true
+	}
+	urls := make([]URL, 0, len(names))
+	for _, r := range e.Routes() {
+		if r.Method != http.MethodGet || !wanted[r.Name] || strings.ContainsAny(r.Path, ":*") {
+			continue
+		}
+		urls = append(urls, URL{Loc: r.Path})
+	}
+	// routes are stored in map so we sort them to have stable output
+	sort.Slice(urls, func(i, j int) bool {
+		return urls[i].Loc < urls[j].Loc
+	})
+	return urls
+}
+
+func newURLSet(config SitemapConfig, urls []URL) urlSet {
+	set := urlSet{XMLNS: sitemapXMLNS, URLs: make([]sitemapURL, len(urls))}
+	for i, u := range urls {
+		su := sitemapURL{
+			Loc:        absoluteURL(config.BaseURL, u.Loc),
+			ChangeFreq: u.ChangeFreq,
+		}
+		if !u.LastMod.IsZero() {
+			su.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		if u.Priority > 0 {
+			su.Priority = strconv.FormatFloat(u.Priority, 'f', 1, 64)
+		}
+		set.URLs[i] = su
+	}
+	return set
+}
+
+func newSitemapIndex(config SitemapConfig, path string, urls []URL, pages int) sitemapIndex {
+	index := sitemapIndex{XMLNS: sitemapXMLNS, Sitemaps: make([]sitemapEntry, pages)}
+	for p := 0; p < pages; p++ {
+		from := p * config.MaxURLsPerSitemap
+		to := from + config.MaxURLsPerSitemap
+		if to > len(urls) {
+			to = len(urls)
+		}
+		var lastMod time.Time
+		for _, u := range urls[from:to] {
+			if u.LastMod.After(lastMod) {
+				lastMod = u.LastMod
+			}
+		}
+		entry := sitemapEntry{Loc: config.BaseURL + path + "?page=" + strconv.Itoa(p+1)}
+		if !lastMod.IsZero() {
+			entry.LastMod = lastMod.UTC().Format(time.RFC3339)
+		}
+		index.Sitemaps[p] = entry
+	}
+	return index
+}
+
+func absoluteURL(base, loc string) string {
+	if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+		return loc
+	}
+	if !strings.HasPrefix(loc, "/") {
+		loc = "/" + loc
+	}
+	return base + loc
+}
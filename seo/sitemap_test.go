@@ -0,0 +1,119 @@
+package seo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSitemap(t *testing.T) {
+	e := echo.New()
+	h := func(c echo.Context) error { return nil }
+	e.GET("/", h).Name = "home"
+	e.GET("/about", h).Name = "about"
+	e.GET("/users/:id", h).Name = "user"
+	e.POST("/contact", h).Name = "contact"
+
+	e.GET("/sitemap.xml", Sitemap(e, SitemapConfig{
+		BaseURL:    "https://example.com/",
+		RouteNames: []string{"home", "about", "user", "contact"},
+		Provider: func(c echo.Context) ([]URL, error) {
+			return []URL{
+				{Loc: "/blog/hello", LastMod: time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC), ChangeFreq: "weekly", Priority: 0.8},
+				{Loc: "https://cdn.example.com/file.pdf"},
+			}, nil
+		},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationXMLCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+		`<url><loc>https://example.com/</loc></url>`+
+		`<url><loc>https://example.com/about</loc></url>`+
+		`<url><loc>https://example.com/blog/hello</loc><lastmod>2021-12-01T10:00:00Z</lastmod><changefreq>weekly</changefreq><priority>0.8</priority></url>`+
+		`<url><loc>https://cdn.example.com/file.pdf</loc></url>`+
+		`</urlset>`, rec.Body.String())
+}
+
+func TestSitemap_index(t *testing.T) {
+	e := echo.New()
+	e.GET("/sitemap.xml", Sitemap(e, SitemapConfig{
+		BaseURL:           "https://example.com",
+		MaxURLsPerSitemap: 2,
+		Provider: func(c echo.Context) ([]URL, error) {
+			return []URL{
+				{Loc: "/a", LastMod: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{Loc: "/b", LastMod: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)},
+				{Loc: "/c"},
+			}, nil
+		},
+	}))
+
+	var testCases = []struct {
+		name       string
+		whenURL    string
+		expectCode int
+		expectBody string
+	}{
+		{
+			name:       "ok, index",
+			whenURL:    "/sitemap.xml",
+			expectCode: http.StatusOK,
+			expectBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+				`<sitemap><loc>https://example.com/sitemap.xml?page=1</loc><lastmod>2021-01-02T00:00:00Z</lastmod></sitemap>` +
+				`<sitemap><loc>https://example.com/sitemap.xml?page=2</loc></sitemap>` +
+				`</sitemapindex>`,
+		},
+		{
+			name:       "ok, last page",
+			whenURL:    "/sitemap.xml?page=2",
+			expectCode: http.StatusOK,
+			expectBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>https://example.com/c</loc></url></urlset>`,
+		},
+		{
+			name:       "nok, page out of range",
+			whenURL:    "/sitemap.xml?page=3",
+			expectCode: http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.whenURL, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectBody != "" {
+				assert.Equal(t, tc.expectBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSitemap_providerError(t *testing.T) {
+	e := echo.New()
+	h := Sitemap(e, SitemapConfig{
+		BaseURL: "https://example.com",
+		Provider: func(c echo.Context) ([]URL, error) {
+			return nil, errors.New("db down")
+		},
+	})
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil), httptest.NewRecorder())
+	assert.EqualError(t, h(c), "db down")
+
+	assert.Panics(t, func() {
+		Sitemap(e, SitemapConfig{})
+	})
+}
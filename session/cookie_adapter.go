@@ -0,0 +1,242 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// Session is server-side session kept in Store.
+	Session struct {
+		Info
+		// Values are application data of the session.
+		Values map[string]interface{}
+	}
+
+	// Store is server-side storage of sessions used by CookieAdapter.
+	Store interface {
+		// Get returns session with the ID or ErrSessionNotFound.
+		Get(ctx context.Context, id string) (*Session, error)
+		// Save creates or replaces the session.
+		Save(ctx context.Context, session *Session) error
+		// Delete removes session with the ID.
+		Delete(ctx context.Context, id string) error
+	}
+
+	// CookieAdapterConfig defines the config for CookieAdapter.
+	CookieAdapterConfig struct {
+		// Store keeps sessions.
+		// Required.
+		Store Store
+
+		// CookieName is name of the cookie with session ID.
+		// Optional. Default value "session_id".
+		CookieName string `yaml:"cookie_name"`
+
+		// CookieDomain is domain of the session cookie.
+		// Optional. Default value none.
+		CookieDomain string `yaml:"cookie_domain"`
+
+		// CookiePath is path of the session cookie.
+		// Optional. Default value "/".
+		CookiePath string `yaml:"cookie_path"`
+
+		// CookieSecure indicates if session cookie is sent only over HTTPS. Cookie is always secure for TLS
+		// requests.
+		// Optional. Default value false.
+		CookieSecure bool `yaml:"cookie_secure"`
+
+		// CookieSameSite is SameSite attribute of the session cookie.
+		// Optional. Default value http.SameSiteLaxMode.
+		CookieSameSite http.SameSite `yaml:"cookie_same_site"`
+	}
+
+	// CookieAdapter is the session implementation of Echo: sessions are kept in Store and identified by ID sent in
+	// cookie. It implements Adapter so lifecycle of its sessions is enforced by Manager, stores implementing
+	// Sweeper (i.e. MemoryStore) are swept by it too. Handlers access the session with `Get()` and persist their
+	// changes with `Save()`.
+	CookieAdapter struct {
+		config CookieAdapterConfig
+	}
+)
+
+const (
+	sessionContextKey = "_session"
+	sessionIDLength   = 32
+)
+
+// ErrSessionNotFound is returned by store when session does not exist.
+var ErrSessionNotFound = errors.New("session: session not found")
+
+// DefaultCookieAdapterConfig is the default CookieAdapter config.
+var DefaultCookieAdapterConfig = CookieAdapterConfig{
+	CookieName:     "session_id",
+	CookiePath:     "/",
+	CookieSameSite: http.SameSiteLaxMode,
+}
+
+// NewCookieAdapter creates new CookieAdapter with config.
+//
+// Example:
+//
+//	store := session.NewMemoryStore()
+//	adapter := session.NewCookieAdapter(session.CookieAdapterConfig{Store: store, CookieSecure: true})
+//	sessions := session.New(e, session.Config{Adapter: adapter, Sweeper: store})
+//	e.Use(sessions.Middleware())
+func NewCookieAdapter(config CookieAdapterConfig) *CookieAdapter {
+	// Defaults
+	if config.Store == nil {
+		panic("echo: session cookie adapter requires store")
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultCookieAdapterConfig.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultCookieAdapterConfig.CookiePath
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = DefaultCookieAdapterConfig.CookieSameSite
+	}
+	return &CookieAdapter{config: config}
+}
+
+// Get returns session of the request. New session is created and its cookie is set when the request has none.
+func (a *CookieAdapter) Get(c echo.Context) (*Session, error) {
+	if s, ok, err := a.load(c); err != nil || ok {
+		return s, err
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	now := echo.Now(c)
+	s := &Session{
+		Info:   Info{ID: id, CreatedAt: now, LastSeen: now},
+		Values: map[string]interface{}{},
+	}
+	if err := a.config.Store.Save(c.Request().Context(), s); err != nil {
+		return nil, err
+	}
+	c.Set(sessionContextKey, s)
+	a.setCookie(c, id)
+	return s, nil
+}
+
+// Save stores changes of the session made by the handler, i.e. authenticated Principal or Values. Changes of
+// concurrent requests of the same session are not merged, the last saved session wins.
+func (a *CookieAdapter) Save(c echo.Context, s *Session) error {
+	return a.config.Store.Save(c.Request().Context(), s)
+}
+
+// Load implements Adapter.Load.
+func (a *CookieAdapter) Load(c echo.Context) (Info, bool, error) {
+	s, ok, err := a.load(c)
+	if err != nil || !ok {
+		return Info{}, false, err
+	}
+	return s.Info, true, nil
+}
+
+// Touch implements Adapter.Touch.
+func (a *CookieAdapter) Touch(c echo.Context, now time.Time) error {
+	s, ok, err := a.load(c)
+	if err != nil || !ok {
+		return err
+	}
+	s.LastSeen = now
+	return a.config.Store.Save(c.Request().Context(), s)
+}
+
+// Regenerate implements Adapter.Regenerate.
+func (a *CookieAdapter) Regenerate(c echo.Context) error {
+	s, ok, err := a.load(c)
+	if err != nil || !ok {
+		return err
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return err
+	}
+	ctx := c.Request().Context()
+	old := s.ID
+	s.ID = id
+	if err := a.config.Store.Save(ctx, s); err != nil {
+		s.ID = old
+		return err
+	}
+	a.setCookie(c, id)
+	return a.config.Store.Delete(ctx, old)
+}
+
+// Destroy implements Adapter.Destroy.
+func (a *CookieAdapter) Destroy(c echo.Context) error {
+	s, ok, err := a.load(c)
+	if err != nil {
+		return err
+	}
+	c.Set(sessionContextKey, (*Session)(nil))
+	a.clearCookie(c)
+	if !ok {
+		return nil
+	}
+	return a.config.Store.Delete(c.Request().Context(), s.ID)
+}
+
+// load returns session of the request loaded from store by ID in cookie. Loaded session is kept in context so
+// changes made by the handler are visible to the lifecycle middleware.
+func (a *CookieAdapter) load(c echo.Context) (*Session, bool, error) {
+	if s, ok := c.Get(sessionContextKey).(*Session); ok {
+		return s, s != nil, nil
+	}
+	cookie, err := c.Cookie(a.config.CookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, false, nil
+	}
+	s, err := a.config.Store.Get(c.Request().Context(), cookie.Value)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	c.Set(sessionContextKey, s)
+	return s, true, nil
+}
+
+func (a *CookieAdapter) setCookie(c echo.Context, id string) {
+	c.SetCookie(&http.Cookie{
+		Name:     a.config.CookieName,
+		Value:    id,
+		Path:     a.config.CookiePath,
+		Domain:   a.config.CookieDomain,
+		Secure:   a.config.CookieSecure || c.IsTLS(),
+		HttpOnly: true,
+		SameSite: a.config.CookieSameSite,
+	})
+}
+
+func (a *CookieAdapter) clearCookie(c echo.Context) {
+	c.SetCookie(&http.Cookie{
+		Name:     a.config.CookieName,
+		Path:     a.config.CookiePath,
+		Domain:   a.config.CookieDomain,
+		MaxAge:   -1,
+		Secure:   a.config.CookieSecure || c.IsTLS(),
+		HttpOnly: true,
+		SameSite: a.config.CookieSameSite,
+	})
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, sessionIDLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
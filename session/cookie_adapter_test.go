@@ -0,0 +1,175 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCookieAdapter(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := echotest.NewClock(start)
+	e := echo.New()
+	e.Clock = clock
+	store := NewMemoryStore()
+	adapter := NewCookieAdapter(CookieAdapterConfig{Store: store})
+	m := New(e, Config{Adapter: adapter, Sweeper: store})
+
+	e.Use(m.Middleware())
+	e.GET("/", func(c echo.Context) error {
+		s, err := adapter.Get(c)
+		if err != nil {
+			return err
+		}
+		s.Values["visits"] = s.Values["visits"].(int) + 1
+		if err := adapter.Save(c, s); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, s.Principal)
+	})
+	e.POST("/login", func(c echo.Context) error {
+		s, err := adapter.Get(c)
+		if err != nil {
+			return err
+		}
+		s.Principal = "jon"
+		return adapter.Save(c, s)
+	})
+	e.POST("/logout", func(c echo.Context) error {
+		return adapter.Destroy(c)
+	})
+	e.POST("/new", func(c echo.Context) error {
+		s, err := adapter.Get(c)
+		if err != nil {
+			return err
+		}
+		s.Values["visits"] = 0
+		return adapter.Save(c, s)
+	})
+
+	request := func(method, path, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if id != "" {
+			req.AddCookie(&http.Cookie{Name: "session_id", Value: id})
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	cookieOf := func(rec *httptest.ResponseRecorder) *http.Cookie {
+		cookies := rec.Result().Cookies()
+		if !assert.NotEmpty(t, cookies) {
+			t.FailNow()
+		}
+		return cookies[len(cookies)-1]
+	}
+
+	// new session
+	rec := request(http.MethodPost, "/new", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	cookie := cookieOf(rec)
+	assert.Equal(t, "/", cookie.Path)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	id := cookie.Value
+	assert.Len(t, store.sessions, 1)
+
+	// existing session is touched
+	clock.Advance(time.Minute)
+	rec = request(http.MethodGet, "/", id)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderSetCookie))
+	s, err := store.Get(nil, id)
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(time.Minute), s.LastSeen)
+	assert.Equal(t, 1, s.Values["visits"])
+
+	// login regenerates session ID and keeps values
+	rec = request(http.MethodPost, "/login", id)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	newID := cookieOf(rec).Value
+	assert.NotEqual(t, id, newID)
+	_, err = store.Get(nil, id)
+	assert.Equal(t, ErrSessionNotFound, err)
+	s, err = store.Get(nil, newID)
+	assert.NoError(t, err)
+	assert.Equal(t, "jon", s.Principal)
+	assert.Equal(t, 1, s.Values["visits"])
+
+	// old session ID is not accepted
+	rec = request(http.MethodPost, "/login", id)
+	assert.NotEqual(t, id, cookieOf(rec).Value)
+	assert.NotEqual(t, newID, cookieOf(rec).Value)
+
+	// logout destroys session
+	rec = request(http.MethodPost, "/logout", newID)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, -1, cookieOf(rec).MaxAge)
+	_, err = store.Get(nil, newID)
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	// expired sessions are swept
+	clock.Advance(time.Hour)
+	n, err := m.Sweep(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Empty(t, store.sessions)
+}
+
+func TestCookieAdapter_expiredSession(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := echo.New()
+	e.Clock = echotest.NewClock(start)
+	store := NewMemoryStore()
+	adapter := NewCookieAdapter(CookieAdapterConfig{Store: store, CookieSecure: true})
+	m := New(e, Config{Adapter: adapter})
+	assert.NoError(t, store.Save(nil, &Session{Info: Info{ID: "expired", CreatedAt: start.Add(-time.Hour)}}))
+
+	e.Use(m.Middleware())
+	e.GET("/", func(c echo.Context) error {
+		_, ok, err := adapter.Load(c)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, map[bool]string{true: "session", false: "none"}[ok])
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "expired"})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, "none", rec.Body.String())
+	assert.Empty(t, store.sessions)
+	cookies := rec.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, -1, cookies[0].MaxAge)
+		assert.True(t, cookies[0].Secure)
+	}
+}
+
+func TestMemoryStore_copiesSessions(t *testing.T) {
+	store := NewMemoryStore()
+	s := &Session{Info: Info{ID: "1"}, Values: map[string]interface{}{"a": 1}}
+	assert.NoError(t, store.Save(nil, s))
+
+	s.Values["a"] = 2
+	got, err := store.Get(nil, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, got.Values["a"])
+
+	got.Principal = "jon"
+	got2, err := store.Get(nil, "1")
+	assert.NoError(t, err)
+	assert.Empty(t, got2.Principal)
+}
+
+func TestNewCookieAdapter_panics(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: session cookie adapter requires store", func() {
+		NewCookieAdapter(CookieAdapterConfig{})
+	})
+}
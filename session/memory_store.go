@@ -0,0 +1,75 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is in-memory session store for CookieAdapter. Sessions are lost on restart and are not shared between
+// instances so it is suitable for single instance deployments and tests. It implements Sweeper.
+type MemoryStore struct {
+	mutex    sync.Mutex
+	sessions map[string]Session
+}
+
+// NewMemoryStore creates new in-memory session store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]Session{}}
+}
+
+// Get returns copy of the session.
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	session.Values = copyValues(session.Values)
+	return &session, nil
+}
+
+// Save stores copy of the session.
+func (s *MemoryStore) Save(ctx context.Context, session *Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored := *session
+	stored.Values = copyValues(session.Values)
+	s.sessions[session.ID] = stored
+	return nil
+}
+
+// Delete removes the session.
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// Sweep implements Sweeper.Sweep.
+func (s *MemoryStore) Sweep(ctx context.Context, idleBefore, createdBefore time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := 0
+	for id, session := range s.sessions {
+		lastSeen := session.LastSeen
+		if lastSeen.IsZero() {
+			lastSeen = session.CreatedAt
+		}
+		if lastSeen.Before(idleBefore) || session.CreatedAt.Before(createdBefore) {
+			delete(s.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		c[k] = v
+	}
+	return c
+}
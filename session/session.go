@@ -0,0 +1,321 @@
+/*
+Package session enforces lifecycle of server-side sessions independently of their implementation: idle and absolute
+lifetime, regeneration of session ID when user of the session changes (session fixation protection) and background
+sweeping of expired sessions from the store. The session implementation is connected with `Adapter`, sessions
+kept server-side and identified by cookie are implemented by `CookieAdapter` with `MemoryStore` or custom `Store`.
+
+Example:
+
+	store := session.NewMemoryStore()
+	adapter := session.NewCookieAdapter(session.CookieAdapterConfig{Store: store})
+	sessions := session.New(e, session.Config{
+		Adapter:         adapter,
+		Sweeper:         store,
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 12 * time.Hour,
+	})
+	e.Use(sessions.Middleware())
+
+	e.POST("/login", func(c echo.Context) error {
+		// ... authenticate the user
+		s, err := adapter.Get(c)
+		if err != nil {
+			return err
+		}
+		s.Principal = user.ID
+		// ID of the session is regenerated before the response is sent
+		return adapter.Save(c, s)
+	})
+*/
+package session
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type (
+	// Info is lifecycle state of a session.
+	Info struct {
+		// ID is ID of the session.
+		ID string
+		// CreatedAt is time the session was created.
+		CreatedAt time.Time
+		// LastSeen is time of the last request of the session, zero value means CreatedAt.
+		LastSeen time.Time
+		// Principal is user authenticated in the session, empty for anonymous sessions.
+		Principal string
+	}
+
+	// Adapter connects lifecycle management to session implementation.
+	Adapter interface {
+		// Load returns session of the request, ok is false when the request has no session. Changes of the session
+		// made by the handler (i.e. logged in user) must be visible to later calls within the same request.
+		Load(c echo.Context) (info Info, ok bool, err error)
+
+		// Touch records request of the session at now.
+		Touch(c echo.Context, now time.Time) error
+
+		// Regenerate moves data of the session under new ID, removes the old ID from the store and sends new
+		// session cookie.
+		Regenerate(c echo.Context) error
+
+		// Destroy removes session of the request from the store and clears the session cookie.
+		Destroy(c echo.Context) error
+	}
+
+	// Sweeper is implemented by server-side stores able to remove expired sessions.
+	Sweeper interface {
+		// Sweep removes sessions last seen before idleBefore or created before createdBefore and returns number of
+		// removed sessions.
+		Sweep(ctx context.Context, idleBefore, createdBefore time.Time) (int, error)
+	}
+
+	// Config defines the config for session lifecycle management.
+	Config struct {
+		// Skipper defines a function to skip middleware.
+		Skipper middleware.Skipper
+
+		// Adapter connects the middleware to session implementation.
+		// Required.
+		Adapter Adapter
+
+		// IdleTimeout is duration after the last request the session expires.
+		// Optional. Default value 30 minutes.
+		IdleTimeout time.Duration
+
+		// AbsoluteTimeout is duration after creation the session expires regardless of activity.
+		// Optional. Default value 12 hours.
+		AbsoluteTimeout time.Duration
+
+		// Sweeper removes expired sessions from the store while Echo is running.
+		// Optional. Default value nil, expired sessions are only destroyed by requests using them.
+		Sweeper Sweeper
+
+		// SweepInterval is interval of removing expired sessions.
+		// Optional. Default value 5 minutes.
+		SweepInterval time.Duration
+
+		// ExpiredHandler is called after expired session was destroyed. Returned error is returned by the
+		// middleware, nil continues the request without session.
+		// Optional. Default value continues the request.
+		ExpiredHandler func(c echo.Context, info Info) error
+	}
+
+	// Manager enforces lifecycle of sessions.
+	Manager struct {
+		config Config
+		echo   *echo.Echo
+		mu     sync.Mutex
+		stop   chan struct{}
+		done   chan struct{}
+	}
+
+	state struct {
+		manager     *Manager
+		principal   string
+		regenerated bool
+		checked     bool
+	}
+)
+
+const contextKey = "_session_lifecycle"
+
+// ErrNoMiddleware denotes call of `Regenerate()` for request not handled by the middleware.
+var ErrNoMiddleware = echo.NewHTTPError(http.StatusInternalServerError, "session middleware is not installed")
+
+// DefaultConfig is the default session lifecycle config.
+var DefaultConfig = Config{
+	Skipper:         middleware.DefaultSkipper,
+	IdleTimeout:     30 * time.Minute,
+	AbsoluteTimeout: 12 * time.Hour,
+	SweepInterval:   5 * time.Minute,
+	ExpiredHandler: func(c echo.Context, info Info) error {
+		return nil
+	},
+}
+
+// New creates manager of session lifecycle. When Sweeper is set, expired sessions are removed while Echo is running.
+func New(e *echo.Echo, config Config) *Manager {
+	// Defaults
+	if config.Adapter == nil {
+		panic("echo: session lifecycle requires adapter")
+	}
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = DefaultConfig.IdleTimeout
+	}
+	if config.AbsoluteTimeout <= 0 {
+		config.AbsoluteTimeout = DefaultConfig.AbsoluteTimeout
+	}
+	if config.SweepInterval <= 0 {
+		config.SweepInterval = DefaultConfig.SweepInterval
+	}
+	if config.ExpiredHandler == nil {
+		config.ExpiredHandler = DefaultConfig.ExpiredHandler
+	}
+
+	m := &Manager{
+		config: config,
+		echo:   e,
+	}
+	if config.Sweeper != nil {
+		e.OnStart(m.start)
+		e.OnShutdown(m.Shutdown)
+	}
+	return m
+}
+
+// Middleware returns a middleware which destroys expired sessions, records activity of valid ones and regenerates
+// session ID before the response is sent when different user was authenticated in the session by the handler.
+func (m *Manager) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if m.config.Skipper(c) {
+				return next(c)
+			}
+
+			now := m.echo.Now()
+			info, ok, err := m.config.Adapter.Load(c)
+			if err != nil {
+				return err
+			}
+			if ok && m.Expired(info, now) {
+				if err := m.config.Adapter.Destroy(c); err != nil {
+					return err
+				}
+				if err := m.config.ExpiredHandler(c, info); err != nil {
+					return err
+				}
+				ok = false
+				info = Info{}
+			}
+			if ok {
+				if err := m.config.Adapter.Touch(c, now); err != nil {
+					return err
+				}
+			}
+
+			s := &state{manager: m, principal: info.Principal}
+			c.Set(contextKey, s)
+			c.Response().Before(func() {
+				s.regenerateOnLogin(c)
+			})
+			err = next(c)
+			// before hooks are not called when the handler did not write the response
+			if !c.Response().Committed {
+				s.regenerateOnLogin(c)
+			}
+			return err
+		}
+	}
+}
+
+// Expired returns true when the session exceeded its idle or absolute lifetime at now.
+func (m *Manager) Expired(info Info, now time.Time) bool {
+	lastSeen := info.LastSeen
+	if lastSeen.IsZero() {
+		lastSeen = info.CreatedAt
+	}
+	return !now.Before(info.CreatedAt.Add(m.config.AbsoluteTimeout)) ||
+		!now.Before(lastSeen.Add(m.config.IdleTimeout))
+}
+
+// Sweep removes expired sessions from the store with Sweeper. It is called periodically while Echo is running.
+func (m *Manager) Sweep(ctx context.Context) (int, error) {
+	if m.config.Sweeper == nil {
+		return 0, nil
+	}
+	now := m.echo.Now()
+	return m.config.Sweeper.Sweep(ctx, now.Add(-m.config.IdleTimeout), now.Add(-m.config.AbsoluteTimeout))
+}
+
+// Regenerate regenerates ID of session of the request, i.e. after change of privileges the middleware can not
+// detect from Principal. Session ID is not regenerated again by the middleware for the request.
+func Regenerate(c echo.Context) error {
+	s, ok := c.Get(contextKey).(*state)
+	if !ok {
+		return ErrNoMiddleware
+	}
+	if err := s.manager.config.Adapter.Regenerate(c); err != nil {
+		return err
+	}
+	s.regenerated = true
+	return nil
+}
+
+// regenerateOnLogin regenerates session ID when the handler authenticated different user. It is called before the
+// response is written or after the handler returned without writing it.
+func (s *state) regenerateOnLogin(c echo.Context) {
+	if s.regenerated || s.checked {
+		return
+	}
+	s.checked = true
+	info, ok, err := s.manager.config.Adapter.Load(c)
+	if err != nil {
+		c.Logger().Errorf("session: failed to load session: %v", err)
+		return
+	}
+	if !ok || info.Principal == "" || info.Principal == s.principal {
+		return
+	}
+	if err := s.manager.config.Adapter.Regenerate(c); err != nil {
+		c.Logger().Errorf("session: failed to regenerate session: %v", err)
+		return
+	}
+	s.regenerated = true
+}
+
+// start starts removing expired sessions. It is called by `Echo#Start()`.
+func (m *Manager) start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		return nil
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.sweep(m.stop, m.done)
+	return nil
+}
+
+// Shutdown stops removing expired sessions. It is called by `Echo#Shutdown()`.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mu.Unlock()
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) sweep(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(m.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if _, err := m.Sweep(context.Background()); err != nil {
+			m.echo.Logger.Errorf("session: failed to sweep expired sessions: %v", err)
+		}
+	}
+}
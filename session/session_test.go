@@ -0,0 +1,347 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Info
+	nextID   int
+	swept    chan [2]time.Time
+}
+
+// testAdapter keeps ID of the session of the request in "sid" cookie.
+type testAdapter struct {
+	store *testStore
+}
+
+func newTestStore() *testStore {
+	return &testStore{sessions: make(map[string]*Info), swept: make(chan [2]time.Time, 10)}
+}
+
+func (s *testStore) create(createdAt, lastSeen time.Time, principal string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	s.sessions[id] = &Info{ID: id, CreatedAt: createdAt, LastSeen: lastSeen, Principal: principal}
+	return id
+}
+
+func (s *testStore) get(id string) *Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[id]
+}
+
+func (s *testStore) Sweep(ctx context.Context, idleBefore, createdBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, info := range s.sessions {
+		if info.LastSeen.Before(idleBefore) || info.CreatedAt.Before(createdBefore) {
+			delete(s.sessions, id)
+			n++
+		}
+	}
+	select {
+	case s.swept <- [2]time.Time{idleBefore, createdBefore}:
+	default:
+	}
+	return n, nil
+}
+
+func (a *testAdapter) id(c echo.Context) string {
+	if id, ok := c.Get("sid").(string); ok {
+		return id
+	}
+	cookie, err := c.Cookie("sid")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func (a *testAdapter) Load(c echo.Context) (Info, bool, error) {
+	info := a.store.get(a.id(c))
+	if info == nil {
+		return Info{}, false, nil
+	}
+	return *info, true, nil
+}
+
+func (a *testAdapter) Touch(c echo.Context, now time.Time) error {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+	a.store.sessions[a.id(c)].LastSeen = now
+	return nil
+}
+
+func (a *testAdapter) Regenerate(c echo.Context) error {
+	old := a.id(c)
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+	info := a.store.sessions[old]
+	delete(a.store.sessions, old)
+	a.store.nextID++
+	info.ID = strconv.Itoa(a.store.nextID)
+	a.store.sessions[info.ID] = info
+	c.Set("sid", info.ID)
+	c.SetCookie(&http.Cookie{Name: "sid", Value: info.ID})
+	return nil
+}
+
+func (a *testAdapter) Destroy(c echo.Context) error {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+	delete(a.store.sessions, a.id(c))
+	c.Set("sid", "")
+	c.SetCookie(&http.Cookie{Name: "sid", MaxAge: -1})
+	return nil
+}
+
+func (a *testAdapter) login(c echo.Context, principal string) {
+	a.store.mu.Lock()
+	defer a.store.mu.Unlock()
+	a.store.sessions[a.id(c)].Principal = principal
+}
+
+func TestMiddleware(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	var testCases = []struct {
+		name             string
+		givenCreatedAt   time.Time
+		givenLastSeen    time.Time
+		givenPrincipal   string
+		whenLogin        string
+		whenRegenerate   bool
+		expectDestroyed  bool
+		expectRegenerate bool
+	}{
+		{
+			name:           "ok, valid session is touched",
+			givenCreatedAt: start.Add(-time.Hour),
+			givenLastSeen:  start.Add(-time.Minute),
+		},
+		{
+			name:            "nok, idle session is destroyed",
+			givenCreatedAt:  start.Add(-time.Hour),
+			givenLastSeen:   start.Add(-30 * time.Minute),
+			expectDestroyed: true,
+		},
+		{
+			name:            "nok, idle session without last seen is destroyed",
+			givenCreatedAt:  start.Add(-time.Hour),
+			expectDestroyed: true,
+		},
+		{
+			name:            "nok, active session exceeding absolute lifetime is destroyed",
+			givenCreatedAt:  start.Add(-12 * time.Hour),
+			givenLastSeen:   start.Add(-time.Second),
+			expectDestroyed: true,
+		},
+		{
+			name:             "ok, login regenerates session ID",
+			givenCreatedAt:   start.Add(-time.Hour),
+			givenLastSeen:    start.Add(-time.Minute),
+			whenLogin:        "jon",
+			expectRegenerate: true,
+		},
+		{
+			name:             "ok, login of different user regenerates session ID",
+			givenCreatedAt:   start.Add(-time.Hour),
+			givenLastSeen:    start.Add(-time.Minute),
+			givenPrincipal:   "jon",
+			whenLogin:        "doe",
+			expectRegenerate: true,
+		},
+		{
+			name:           "ok, same user does not regenerate session ID",
+			givenCreatedAt: start.Add(-time.Hour),
+			givenLastSeen:  start.Add(-time.Minute),
+			givenPrincipal: "jon",
+			whenLogin:      "jon",
+		},
+		{
+			name:             "ok, explicit regeneration is done once",
+			givenCreatedAt:   start.Add(-time.Hour),
+			givenLastSeen:    start.Add(-time.Minute),
+			whenLogin:        "jon",
+			whenRegenerate:   true,
+			expectRegenerate: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Clock = echotest.NewClock(start)
+			store := newTestStore()
+			adapter := &testAdapter{store: store}
+			m := New(e, Config{Adapter: adapter})
+			id := store.create(tc.givenCreatedAt, tc.givenLastSeen, tc.givenPrincipal)
+
+			e.Use(m.Middleware())
+			e.GET("/", func(c echo.Context) error {
+				if tc.whenLogin != "" {
+					adapter.login(c, tc.whenLogin)
+				}
+				if tc.whenRegenerate {
+					if err := Regenerate(c); err != nil {
+						return err
+					}
+				}
+				return c.String(http.StatusOK, adapter.id(c))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: "sid", Value: id})
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			if tc.expectDestroyed {
+				assert.Nil(t, store.get(id))
+				assert.Empty(t, rec.Body.String())
+				assert.Contains(t, rec.Header().Get(echo.HeaderSetCookie), "Max-Age=0")
+				return
+			}
+			if !tc.expectRegenerate {
+				assert.Equal(t, start, store.get(id).LastSeen)
+				assert.Empty(t, rec.Header().Get(echo.HeaderSetCookie))
+				return
+			}
+			assert.Nil(t, store.get(id))
+			assert.Len(t, store.sessions, 1)
+			newID := strconv.Itoa(store.nextID)
+			assert.Equal(t, tc.whenLogin, store.get(newID).Principal)
+			assert.Equal(t, []string{"sid=" + newID}, rec.Header()[echo.HeaderSetCookie])
+		})
+	}
+}
+
+func TestMiddleware_regenerateWithoutResponse(t *testing.T) {
+	e := echo.New()
+	store := newTestStore()
+	adapter := &testAdapter{store: store}
+	m := New(e, Config{Adapter: adapter})
+	id := store.create(time.Now(), time.Now(), "")
+
+	e.Use(m.Middleware())
+	e.POST("/login", func(c echo.Context) error {
+		adapter.login(c, "jon")
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: id})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, store.get(id))
+	newID := strconv.Itoa(store.nextID)
+	assert.Equal(t, "jon", store.get(newID).Principal)
+	assert.Equal(t, []string{"sid=" + newID}, rec.Header()[echo.HeaderSetCookie])
+}
+
+func TestMiddleware_expiredHandler(t *testing.T) {
+	e := echo.New()
+	store := newTestStore()
+	errExpired := echo.NewHTTPError(http.StatusUnauthorized, "session expired")
+	var expired Info
+	m := New(e, Config{
+		Adapter: &testAdapter{store: store},
+		ExpiredHandler: func(c echo.Context, info Info) error {
+			expired = info
+			return errExpired
+		},
+	})
+	id := store.create(time.Now().Add(-13*time.Hour), time.Now(), "jon")
+
+	e.Use(m.Middleware())
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: id})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "jon", expired.Principal)
+	assert.Nil(t, store.get(id))
+}
+
+func TestMiddleware_noSession(t *testing.T) {
+	e := echo.New()
+	store := newTestStore()
+	m := New(e, Config{Adapter: &testAdapter{store: store}})
+
+	e.Use(m.Middleware())
+	e.GET("/", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderSetCookie))
+}
+
+func TestRegenerate_noMiddleware(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	assert.True(t, errors.Is(Regenerate(c), ErrNoMiddleware))
+}
+
+func TestManager_Sweep(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := echo.New()
+	e.Clock = echotest.NewClock(start)
+	store := newTestStore()
+	m := New(e, Config{Adapter: &testAdapter{store: store}, Sweeper: store})
+	valid := store.create(start.Add(-time.Hour), start.Add(-time.Minute), "")
+	store.create(start.Add(-time.Hour), start.Add(-31*time.Minute), "")
+	store.create(start.Add(-13*time.Hour), start, "")
+
+	n, err := m.Sweep(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, store.sessions, 1)
+	assert.NotNil(t, store.get(valid))
+	assert.Equal(t, [2]time.Time{start.Add(-30 * time.Minute), start.Add(-12 * time.Hour)}, <-store.swept)
+}
+
+func TestManager_sweepInBackground(t *testing.T) {
+	e := echo.New()
+	store := newTestStore()
+	m := New(e, Config{Adapter: &testAdapter{store: store}, Sweeper: store, SweepInterval: time.Millisecond})
+
+	assert.NoError(t, m.start())
+	select {
+	case <-store.swept:
+	case <-time.After(time.Second):
+		t.Fatal("expired sessions were not swept")
+	}
+	assert.NoError(t, m.Shutdown(context.Background()))
+	assert.NoError(t, m.Shutdown(context.Background()))
+}
+
+func TestNew_panics(t *testing.T) {
+	assert.PanicsWithValue(t, "echo: session lifecycle requires adapter", func() {
+		New(echo.New(), Config{})
+	})
+}
@@ -0,0 +1,246 @@
+package sqltrace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// DriverConfig defines the config for traced driver.
+type DriverConfig struct {
+	// Comment appends sqlcommenter comment (`/*request_id='...',traceparent='...'*/`) to queries executed with
+	// request context. Note: queries differing only in comment are different statements for statement caches of
+	// databases using text of the query as key.
+	// Optional. Default value false.
+	Comment bool
+}
+
+type (
+	tracedDriver struct {
+		driver.Driver
+		config DriverConfig
+	}
+
+	tracedConnector struct {
+		connector driver.Connector
+		config    DriverConfig
+	}
+
+	// dsnConnector opens connections of drivers not implementing `driver.DriverContext`.
+	dsnConnector struct {
+		driver driver.Driver
+		dsn    string
+	}
+
+	tracedConn struct {
+		driver.Conn
+		config DriverConfig
+	}
+
+	tracedStmt struct {
+		driver.Stmt
+		conn  *tracedConn
+		query string
+	}
+)
+
+var (
+	errNoIsolation = errors.New("sqltrace: driver does not support non-default isolation level")
+	errNoReadOnly  = errors.New("sqltrace: driver does not support read-only transactions")
+	errNamedArgs   = errors.New("sqltrace: driver does not support the use of Named Parameters")
+)
+
+// Open opens database with driver registered under the name wrapped by `WrapConnector()`.
+func Open(driverName, dataSourceName string, config DriverConfig) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	db.Close()
+
+	if dc, ok := d.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(dataSourceName)
+		if err != nil {
+			return nil, err
+		}
+		return sql.OpenDB(WrapConnector(connector, config)), nil
+	}
+	return sql.OpenDB(WrapConnector(dsnConnector{driver: d, dsn: dataSourceName}, config)), nil
+}
+
+// Wrap returns driver tracing queries of connections opened by d.
+func Wrap(d driver.Driver, config DriverConfig) driver.Driver {
+	return &tracedDriver{Driver: d, config: config}
+}
+
+// WrapConnector returns connector tracing queries of connections opened by c. Use it with `sql.OpenDB()`.
+func WrapConnector(c driver.Connector, config DriverConfig) driver.Connector {
+	return &tracedConnector{connector: c, config: config}
+}
+
+func (d *tracedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, config: d.config}, nil
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, config: c.config}, nil
+}
+
+func (c *tracedConnector) Driver() driver.Driver {
+	return Wrap(c.connector.Driver(), c.config)
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (c *tracedConn) tag(ctx context.Context, query string) string {
+	if !c.config.Comment {
+		return query
+	}
+	return comment(ctx, query)
+}
+
+func (c *tracedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = c.tag(ctx, query)
+	var s driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = p.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{Stmt: s, conn: c, query: query}, nil
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql prepares statement instead
+	}
+	query = c.tag(ctx, query)
+	start := time.Now()
+	res, err := e.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		record(ctx, query, start, err)
+	}
+	return res, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql prepares statement instead
+	}
+	query = c.tag(ctx, query)
+	start := time.Now()
+	rows, err := q.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		record(ctx, query, start, err)
+	}
+	return rows, err
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errNoIsolation
+	}
+	if opts.ReadOnly {
+		return nil, errNoReadOnly
+	}
+	return c.Conn.Begin()
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip // database/sql converts value with default converter
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (res driver.Result, err error) {
+	start := time.Now()
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err != nil {
+			return nil, err
+		}
+		res, err = s.Stmt.Exec(values)
+	}
+	record(ctx, s.query, start, err)
+	return res, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (rows driver.Rows, err error) {
+	start := time.Now()
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err != nil {
+			return nil, err
+		}
+		rows, err = s.Stmt.Query(values)
+	}
+	record(ctx, s.query, start, err)
+	return rows, err
+}
+
+func (s *tracedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamedArgs
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,190 @@
+package sqltrace
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDriver records queries. Connections implement `driver.ExecerContext` and `driver.QueryerContext` unless
+// prepareOnly is set.
+type testDriver struct {
+	prepareOnly bool
+
+	mu      sync.Mutex
+	queries []string
+}
+
+type testConn struct {
+	driver *testDriver
+}
+
+type testExecConn struct {
+	testConn
+}
+
+type testStmt struct {
+	driver *testDriver
+	query  string
+}
+
+type testRows struct{}
+
+var errTestQuery = errors.New("syntax error")
+
+func (d *testDriver) Open(name string) (driver.Conn, error) {
+	if d.prepareOnly {
+		return &testConn{driver: d}, nil
+	}
+	return &testExecConn{testConn{driver: d}}, nil
+}
+
+func (d *testDriver) run(query string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+	if strings.HasPrefix(query, "FAIL") {
+		return errTestQuery
+	}
+	return nil
+}
+
+func (d *testDriver) executed() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.queries...)
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{driver: c.driver, query: query}, nil
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *testExecConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), c.driver.run(query)
+}
+
+func (c *testExecConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.driver.run(query); err != nil {
+		return nil, err
+	}
+	return testRows{}, nil
+}
+
+func (s *testStmt) Close() error {
+	return nil
+}
+
+func (s *testStmt) NumInput() int {
+	return -1
+}
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), s.driver.run(s.query)
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.driver.run(s.query); err != nil {
+		return nil, err
+	}
+	return testRows{}, nil
+}
+
+func (testRows) Columns() []string {
+	return []string{"n"}
+}
+
+func (testRows) Close() error {
+	return nil
+}
+
+func (testRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+var registerOnce sync.Once
+
+func TestOpen(t *testing.T) {
+	d := &testDriver{}
+	registerOnce.Do(func() {
+		sql.Register("sqltrace_test", d)
+	})
+	db, err := Open("sqltrace_test", "", DriverConfig{Comment: true})
+	require.NoError(t, err)
+	defer db.Close()
+
+	c, _ := newTestContext()
+	_, err = db.ExecContext(c.Request().Context(), "DELETE FROM sessions")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DELETE FROM sessions /*request_id='abc'*/"}, d.executed())
+	assert.Equal(t, 1, StatsFrom(c).Queries)
+}
+
+func TestWrap(t *testing.T) {
+	for _, prepareOnly := range []bool{false, true} {
+		d := &testDriver{prepareOnly: prepareOnly}
+		db := sql.OpenDB(dsnConnector{driver: Wrap(d, DriverConfig{Comment: true})})
+		c, s := newTestContext()
+		ctx := c.Request().Context()
+
+		_, err := db.ExecContext(ctx, "UPDATE users SET name = ?;", "jon")
+		assert.NoError(t, err)
+		rows, err := db.QueryContext(ctx, "SELECT 1")
+		require.NoError(t, err)
+		rows.Close()
+		_, err = db.QueryContext(ctx, "FAIL")
+		assert.Equal(t, errTestQuery, err)
+		_, err = db.Exec("SELECT 2")
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"UPDATE users SET name = ? /*request_id='abc'*/",
+			"SELECT 1 /*request_id='abc'*/",
+			"FAIL /*request_id='abc'*/",
+			"SELECT 2",
+		}, d.executed())
+		stats := s.snapshot()
+		assert.Equal(t, 3, stats.Queries)
+		assert.Equal(t, 1, stats.Errors)
+		db.Close()
+	}
+}
+
+func TestWrap_noComment(t *testing.T) {
+	d := &testDriver{}
+	db := sql.OpenDB(WrapConnector(dsnConnector{driver: d}, DriverConfig{}))
+	defer db.Close()
+	c, _ := newTestContext()
+
+	_, err := db.ExecContext(c.Request().Context(), "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SELECT 1"}, d.executed())
+	assert.Equal(t, 1, StatsFrom(c).Queries)
+}
+
+// newTestContext returns context of request traced by the middleware.
+func newTestContext() (echo.Context, *scope) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	s := &scope{requestID: "abc", logger: e.Logger}
+	c := e.NewContext(req.WithContext(context.WithValue(req.Context(), scopeKey{}, s)), httptest.NewRecorder())
+	return c, s
+}
@@ -0,0 +1,92 @@
+package sqltrace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type (
+	// Config defines the config for sqltrace middleware.
+	Config struct {
+		// Skipper defines a function to skip middleware.
+		Skipper middleware.Skipper
+
+		// LogQueries logs every query of the request with DEBUG level of the request logger (query, duration,
+		// request ID and error). Query arguments are not logged.
+		// Optional. Default value false.
+		LogQueries bool
+
+		// DisableServerTiming disables `Server-Timing` response header with number and duration of queries executed
+		// before the response is written.
+		// Optional. Default value false.
+		DisableServerTiming bool
+
+		// ServerTimingName is metric name in `Server-Timing` header.
+		// Optional. Default value "db".
+		ServerTimingName string
+	}
+)
+
+// DefaultConfig is the default sqltrace middleware config.
+var DefaultConfig = Config{
+	Skipper:          middleware.DefaultSkipper,
+	ServerTimingName: "db",
+}
+
+// Middleware returns a middleware tracing queries executed with request context by traced driver. See
+// `MiddlewareWithConfig()`.
+func Middleware() echo.MiddlewareFunc {
+	return MiddlewareWithConfig(DefaultConfig)
+}
+
+// MiddlewareWithConfig returns a middleware tracing queries executed with request context by traced driver. Request ID
+// is taken from `X-Request-ID` header of the request or response so the middleware must be placed after
+// `middleware.RequestID`. Stats of the request are set to context (`ContextKeyStats`) when the handler returns.
+func MiddlewareWithConfig(config Config) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.ServerTimingName == "" {
+		config.ServerTimingName = DefaultConfig.ServerTimingName
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			res := c.Response()
+			s := &scope{
+				requestID:   req.Header.Get(echo.HeaderXRequestID),
+				traceparent: req.Header.Get(HeaderTraceparent),
+				logger:      c.Logger(),
+				logQueries:  config.LogQueries,
+			}
+			if s.requestID == "" {
+				s.requestID = res.Header().Get(echo.HeaderXRequestID)
+			}
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), scopeKey{}, s)))
+
+			if !config.DisableServerTiming {
+				res.Before(func() {
+					stats := s.snapshot()
+					if stats.Queries == 0 {
+						return
+					}
+					res.Header().Add(echo.HeaderServerTiming, fmt.Sprintf(`%s;dur=%.3f;desc="%d queries"`,
+						config.ServerTimingName, float64(stats.Duration.Microseconds())/1000, stats.Queries))
+				})
+			}
+
+			err := next(c)
+			c.Set(ContextKeyStats, s.snapshot())
+			return err
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package sqltrace
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	d := &testDriver{}
+	db := sql.OpenDB(WrapConnector(dsnConnector{driver: d}, DriverConfig{Comment: true}))
+	defer db.Close()
+
+	e := echo.New()
+	var logged interface{}
+	e.RegisterLogKey(ContextKeyStats, nil)
+	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogContextValues: true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			logged = v.ContextValues[ContextKeyStats]
+			return nil
+		},
+	}))
+	e.Use(middleware.RequestIDWithConfig(middleware.RequestIDConfig{Generator: func() string { return "req-1" }}))
+	e.Use(Middleware())
+	e.GET("/", func(c echo.Context) error {
+		ctx := c.Request().Context()
+		db.ExecContext(ctx, "SELECT 1")
+		db.ExecContext(ctx, "SELECT 2")
+		assert.Equal(t, 2, StatsFrom(c).Queries)
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Regexp(t, `^db;dur=\d+\.\d{3};desc="2 queries"$`, rec.Header().Get(echo.HeaderServerTiming))
+	assert.Equal(t, "SELECT 1 /*request_id='req-1',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/",
+		d.executed()[0])
+	if assert.IsType(t, Stats{}, logged) {
+		assert.Equal(t, 2, logged.(Stats).Queries)
+	}
+}
+
+func TestMiddleware_noQueries(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware())
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, rec.Header().Get(echo.HeaderServerTiming))
+}
+
+func TestMiddleware_logQueries(t *testing.T) {
+	d := &testDriver{}
+	db := sql.OpenDB(WrapConnector(dsnConnector{driver: d}, DriverConfig{}))
+	defer db.Close()
+
+	buf := new(bytes.Buffer)
+	e := echo.New()
+	e.Logger.SetOutput(buf)
+	e.Logger.SetLevel(log.DEBUG)
+	e.Use(MiddlewareWithConfig(Config{LogQueries: true, DisableServerTiming: true}))
+	e.GET("/", func(c echo.Context) error {
+		_, err := db.ExecContext(c.Request().Context(), "FAIL")
+		return err
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-2")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get(echo.HeaderServerTiming))
+	out := buf.String()
+	require.True(t, strings.Contains(out, `"sql":"FAIL"`), out)
+	assert.Contains(t, out, `"request_id":"req-2"`)
+	assert.Contains(t, out, `"error":"syntax error"`)
+}
@@ -0,0 +1,133 @@
+/*
+Package sqltrace correlates `database/sql` queries with Echo requests. Driver wrapper (`Open()`, `Wrap()`,
+`WrapConnector()`) tags queries executed with request context by request ID and trace of the request (as
+sqlcommenter comment seen in database slow query logs), logs them with logger of the request and aggregates number
+and duration of queries of the request. Aggregates are sent in `Server-Timing` response header and are available to
+request logger.
+
+Example:
+
+	db, err := sqltrace.Open("postgres", dsn, sqltrace.DriverConfig{Comment: true})
+
+	e.RegisterLogKey(sqltrace.ContextKeyStats, nil)
+	e.Use(middleware.RequestID())
+	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{LogContextValues: true, ...}))
+	e.Use(sqltrace.Middleware())
+
+	e.GET("/users/:id", func(c echo.Context) error {
+		row := db.QueryRowContext(c.Request().Context(), "SELECT name FROM users WHERE id = $1", c.Param("id"))
+		...
+	})
+
+Queries executed with context not derived from request context of the middleware are not traced.
+*/
+package sqltrace
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// Stats are aggregates of queries executed during request.
+type Stats struct {
+	// Queries is number of executed queries.
+	Queries int `json:"queries"`
+
+	// Errors is number of failed queries.
+	Errors int `json:"errors,omitempty"`
+
+	// Duration is total duration of queries. Duration of query is time until driver returns result, reading rows
+	// is not included.
+	Duration time.Duration `json:"duration"`
+}
+
+// ContextKeyStats is context key of request query `Stats` set by the middleware after the handler returns. Register
+// it with `Echo#RegisterLogKey()` to add stats to request logs.
+const ContextKeyStats = "sql"
+
+// HeaderTraceparent is W3C trace context request header added to query comments.
+const HeaderTraceparent = "Traceparent"
+
+// scope is request of traced queries.
+type scope struct {
+	requestID   string
+	traceparent string
+	logger      echo.Logger
+	logQueries  bool
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+type scopeKey struct{}
+
+func scopeFrom(ctx context.Context) *scope {
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	return s
+}
+
+// StatsFrom returns stats of queries executed so far during the request. Zero stats are returned when the request
+// is not served by the middleware.
+func StatsFrom(c echo.Context) Stats {
+	if s := scopeFrom(c.Request().Context()); s != nil {
+		return s.snapshot()
+	}
+	return Stats{}
+}
+
+func (s *scope) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// record adds executed query to stats of request of the context and logs it.
+func record(ctx context.Context, query string, start time.Time, err error) {
+	s := scopeFrom(ctx)
+	if s == nil {
+		return
+	}
+	d := time.Since(start)
+	s.mu.Lock()
+	s.stats.Queries++
+	s.stats.Duration += d
+	if err != nil {
+		s.stats.Errors++
+	}
+	s.mu.Unlock()
+
+	if !s.logQueries {
+		return
+	}
+	entry := log.JSON{"sql": query, "duration": d.String()}
+	if s.requestID != "" {
+		entry["request_id"] = s.requestID
+	}
+	if err != nil {
+		entry["error"] = err.Error()
+	}
+	s.logger.Debugj(entry)
+}
+
+// comment returns query with sqlcommenter comment holding request ID and trace of request of the context. Query is
+// returned unchanged for context without request.
+func comment(ctx context.Context, query string) string {
+	s := scopeFrom(ctx)
+	if s == nil || (s.requestID == "" && s.traceparent == "") {
+		return query
+	}
+	var tags []string
+	if s.requestID != "" {
+		tags = append(tags, "request_id='"+url.PathEscape(s.requestID)+"'")
+	}
+	if s.traceparent != "" {
+		tags = append(tags, "traceparent='"+url.PathEscape(s.traceparent)+"'")
+	}
+	return strings.TrimRight(query, "; \t\n") + " /*" + strings.Join(tags, ",") + "*/"
+}
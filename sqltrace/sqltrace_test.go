@@ -0,0 +1,41 @@
+package sqltrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComment(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		scope  *scope
+		query  string
+		expect string
+	}{
+		{name: "no request", query: "SELECT 1", expect: "SELECT 1"},
+		{name: "no tags", scope: &scope{}, query: "SELECT 1", expect: "SELECT 1"},
+		{
+			name:   "request ID",
+			scope:  &scope{requestID: "abc"},
+			query:  "SELECT 1;\n",
+			expect: "SELECT 1 /*request_id='abc'*/",
+		},
+		{
+			name:   "escaped",
+			scope:  &scope{requestID: "a'b*/c", traceparent: "00-01-02-01"},
+			query:  "SELECT 1",
+			expect: "SELECT 1 /*request_id='a%27b%2A%2Fc',traceparent='00-01-02-01'*/",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.scope != nil {
+				ctx = context.WithValue(ctx, scopeKey{}, tc.scope)
+			}
+			assert.Equal(t, tc.expect, comment(ctx, tc.query))
+		})
+	}
+}
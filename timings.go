@@ -0,0 +1,71 @@
+package echo
+
+import "time"
+
+// PhaseTimings holds durations of request processing phases. Timings are recorded only when
+// `Echo#RecordPhaseTimings` is enabled and are available with `PhaseTimingsOf()`.
+type PhaseTimings struct {
+	// Bind is time spent in `Context#Bind()` calls.
+	Bind time.Duration `json:"bind"`
+
+	// Validate is time spent in `Context#Validate()` calls.
+	Validate time.Duration `json:"validate"`
+
+	// Render is time spent in methods writing response body (`Render`, `JSON`, `XML`, `Blob`, `Stream` etc).
+	Render time.Duration `json:"render"`
+
+	// Handler is time spent in route handler, including Bind, Validate and Render done by the handler.
+	Handler time.Duration `json:"handler"`
+
+	// Middleware is time spent in middleware. It is not recorded by Echo itself but calculated by the middleware
+	// consuming timings (i.e. `RequestLogger`) as duration of its `next(c)` call minus Handler.
+	Middleware time.Duration `json:"middleware"`
+}
+
+var noopPhaseDone = func() {}
+
+// trackPhase starts measuring given phase and returns function to be called when phase ends. Nested phases
+// (i.e. `Render` calling `Blob`) are not measured twice.
+func (c *context) trackPhase(phase *time.Duration) func() {
+	if !c.echo.RecordPhaseTimings || c.inPhase {
+		return noopPhaseDone
+	}
+	c.inPhase = true
+	start := time.Now()
+	return func() {
+		*phase += time.Since(start)
+		c.inPhase = false
+	}
+}
+
+// trackRender starts measuring render phase of the request, see `trackPhase()`.
+func trackRender(c Context) func() {
+	if cc := contextOf(c); cc != nil {
+		return cc.trackPhase(&cc.timings.Render)
+	}
+	return noopPhaseDone
+}
+
+// PhaseTimingsOf returns durations of request processing phases recorded so far or nil when
+// `Echo#RecordPhaseTimings` is not enabled.
+func PhaseTimingsOf(c Context) *PhaseTimings {
+	cc := contextOf(c)
+	if cc == nil || !cc.echo.RecordPhaseTimings {
+		return nil
+	}
+	return &cc.timings
+}
+
+// timeHandler wraps route handler to record its duration into phase timings.
+func timeHandler(h HandlerFunc) HandlerFunc {
+	return func(c Context) error {
+		t := PhaseTimingsOf(c)
+		if t == nil {
+			return h(c)
+		}
+		start := time.Now()
+		err := h(c)
+		t.Handler += time.Since(start)
+		return err
+	}
+}
@@ -0,0 +1,73 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sleepValidator time.Duration
+
+func (v sleepValidator) Validate(i interface{}) error {
+	time.Sleep(time.Duration(v))
+	return nil
+}
+
+func TestEcho_RecordPhaseTimings(t *testing.T) {
+	e := New()
+	e.RecordPhaseTimings = true
+	e.Validator = sleepValidator(5 * time.Millisecond)
+
+	var timings PhaseTimings
+	e.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			err := next(c)
+			timings = *PhaseTimingsOf(c)
+			return err
+		}
+	})
+	e.POST("/", func(c Context) error {
+		u := new(user)
+		if err := c.Bind(u); err != nil {
+			return err
+		}
+		if err := c.Validate(u); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, u)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(userJSON))
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, timings.Bind > 0)
+	assert.True(t, timings.Validate >= 5*time.Millisecond)
+	assert.True(t, timings.Render > 0)
+	assert.True(t, timings.Handler >= timings.Bind+timings.Validate+timings.Render)
+	assert.Equal(t, time.Duration(0), timings.Middleware)
+}
+
+func TestContext_PhaseTimings(t *testing.T) {
+	e := New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Nil(t, PhaseTimingsOf(c))
+	assert.NoError(t, c.String(http.StatusOK, "OK"))
+
+	e.RecordPhaseTimings = true
+	c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	e.Renderer = &Template{templates: template.Must(template.New("hello").Parse("Hello, {{.}}!"))}
+	assert.NoError(t, c.Render(http.StatusOK, "hello", "Jon Snow"))
+	render := PhaseTimingsOf(c).Render
+	assert.True(t, render > 0)
+
+	c.Reset(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Equal(t, &PhaseTimings{}, PhaseTimingsOf(c))
+}
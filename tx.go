@@ -0,0 +1,22 @@
+package echo
+
+import "database/sql"
+
+// ContextKeyTx is context key of request database transaction (`*sql.Tx`) started by `middleware.Tx`.
+const ContextKeyTx = "echo_tx"
+
+// TxFrom returns database transaction of the request started by `middleware.Tx` or nil when there is none.
+//
+// Example:
+//
+//	func createUser(c echo.Context) error {
+//		tx := echo.TxFrom(c)
+//		if _, err := tx.ExecContext(c.Request().Context(), "INSERT INTO users(name) VALUES(?)", name); err != nil {
+//			return err
+//		}
+//		return c.NoContent(http.StatusCreated)
+//	}
+func TxFrom(c Context) *sql.Tx {
+	tx, _ := c.Get(ContextKeyTx).(*sql.Tx)
+	return tx
+}
@@ -0,0 +1,20 @@
+package echo
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxFrom(t *testing.T) {
+	e := New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Nil(t, TxFrom(c))
+
+	tx := new(sql.Tx)
+	c.Set(ContextKeyTx, tx)
+	assert.Same(t, tx, TxFrom(c))
+}
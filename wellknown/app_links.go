@@ -0,0 +1,108 @@
+package wellknown
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// AssetLink is Digital Asset Links statement (https://developers.google.com/digital-asset-links) declaring
+	// relation between the site and an app or another site.
+	AssetLink struct {
+		// Relation lists granted relations, i.e. "delegate_permission/common.handle_all_urls".
+		Relation []string `json:"relation"`
+		// Target is app or site the relations are granted to.
+		Target AssetLinkTarget `json:"target"`
+	}
+
+	// AssetLinkTarget is target of AssetLink statement.
+	AssetLinkTarget struct {
+		// Namespace is "android_app" or "web".
+		Namespace string `json:"namespace"`
+		// PackageName is name of Android app.
+		PackageName string `json:"package_name,omitempty"`
+		// SHA256CertFingerprints are fingerprints of Android app signing certificates.
+		SHA256CertFingerprints []string `json:"sha256_cert_fingerprints,omitempty"`
+		// Site is URL of web site.
+		Site string `json:"site,omitempty"`
+	}
+
+	// AppSiteAssociation is content of Apple App Site Association file
+	// (https://developer.apple.com/documentation/bundleresources/applinks).
+	AppSiteAssociation struct {
+		// AppLinks defines universal links handled by apps.
+		AppLinks *AppLinks `json:"applinks,omitempty"`
+		// WebCredentials lists app IDs sharing credentials with the site, i.e. "ABCDE12345.com.example.app".
+		WebCredentials *AppList `json:"webcredentials,omitempty"`
+		// AppClips lists app IDs of App Clips.
+		AppClips *AppList `json:"appclips,omitempty"`
+	}
+
+	// AppLinks defines universal links of AppSiteAssociation.
+	AppLinks struct {
+		Details []AppLinksDetail `json:"details"`
+	}
+
+	// AppLinksDetail maps URL components to apps.
+	AppLinksDetail struct {
+		// AppIDs lists app IDs (team ID and bundle ID), i.e. "ABCDE12345.com.example.app".
+		AppIDs []string `json:"appIDs"`
+		// Components are patterns of URLs handled by apps.
+		Components []AppLinksComponent `json:"components"`
+	}
+
+	// AppLinksComponent is pattern of URL. Patterns support "*" and "?" wildcards.
+	AppLinksComponent struct {
+		// Path is pattern of URL path, i.e. "/products/*".
+		Path string `json:"/,omitempty"`
+		// Query is pattern of URL query.
+		Query string `json:"?,omitempty"`
+		// Fragment is pattern of URL fragment.
+		Fragment string `json:"#,omitempty"`
+		// Exclude excludes matching URLs from universal links.
+		Exclude bool `json:"exclude,omitempty"`
+		// Comment is ignored by the system.
+		Comment string `json:"comment,omitempty"`
+	}
+
+	// AppList lists app IDs.
+	AppList struct {
+		Apps []string `json:"apps"`
+	}
+)
+
+// AndroidAppLink returns AssetLink statement letting Android app handle all URLs of the site.
+func AndroidAppLink(packageName string, sha256CertFingerprints ...string) AssetLink {
+	return AssetLink{
+		Relation: []string{"delegate_permission/common.handle_all_urls"},
+		Target: AssetLinkTarget{
+			Namespace:              "android_app",
+			PackageName:            packageName,
+			SHA256CertFingerprints: sha256CertFingerprints,
+		},
+	}
+}
+
+// AssetLinks returns handler serving assetlinks.json with given statements.
+func AssetLinks(links []AssetLink) echo.HandlerFunc {
+	return jsonHandler(links)
+}
+
+// AppleAppSiteAssociation returns handler serving apple-app-site-association. File is served without redirects
+// as `application/json` as required by Apple.
+func AppleAppSiteAssociation(association AppSiteAssociation) echo.HandlerFunc {
+	return jsonHandler(association)
+}
+
+// jsonHandler encodes value once and serves it as `application/json`.
+func jsonHandler(value interface{}) echo.HandlerFunc {
+	body, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return func(c echo.Context) error {
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, body)
+	}
+}
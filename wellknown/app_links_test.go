@@ -0,0 +1,54 @@
+package wellknown
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetLinks(t *testing.T) {
+	e := echo.New()
+	e.GET(PathAssetLinks, AssetLinks([]AssetLink{
+		AndroidAppLink("com.example.app", "14:6D:E9"),
+		{Relation: []string{"delegate_permission/common.get_login_creds"}, Target: AssetLinkTarget{Namespace: "web", Site: "https://example.org"}},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, PathAssetLinks, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.JSONEq(t, `[
+		{"relation":["delegate_permission/common.handle_all_urls"],"target":{"namespace":"android_app","package_name":"com.example.app","sha256_cert_fingerprints":["14:6D:E9"]}},
+		{"relation":["delegate_permission/common.get_login_creds"],"target":{"namespace":"web","site":"https://example.org"}}
+	]`, rec.Body.String())
+}
+
+func TestAppleAppSiteAssociation(t *testing.T) {
+	e := echo.New()
+	e.GET(PathAppleAppSiteAssociation, AppleAppSiteAssociation(AppSiteAssociation{
+		AppLinks: &AppLinks{Details: []AppLinksDetail{{
+			AppIDs: []string{"ABCDE12345.com.example.app"},
+			Components: []AppLinksComponent{
+				{Path: "/admin/*", Exclude: true},
+				{Path: "/products/*", Query: "ref=?*"},
+			},
+		}}},
+		WebCredentials: &AppList{Apps: []string{"ABCDE12345.com.example.app"}},
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, PathAppleAppSiteAssociation, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.JSONEq(t, `{
+		"applinks":{"details":[{"appIDs":["ABCDE12345.com.example.app"],"components":[{"/":"/admin/*","exclude":true},{"/":"/products/*","?":"ref=?*"}]}]},
+		"webcredentials":{"apps":["ABCDE12345.com.example.app"]}
+	}`, rec.Body.String())
+}
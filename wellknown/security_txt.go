@@ -0,0 +1,92 @@
+package wellknown
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SecurityTxtConfig defines the config for SecurityTxt handler. Fields are described in RFC 9116.
+type SecurityTxtConfig struct {
+	// Contact lists URIs (i.e. "mailto:security@example.com" or "https://example.com/security") for reporting
+	// vulnerabilities, in order of preference.
+	// Required.
+	Contact []string
+
+	// Expires is date after which the content should be considered stale.
+	// Required unless ExpiresIn is set.
+	Expires time.Time
+
+	// ExpiresIn sets Expires relative to time of the request so the content never goes stale. RFC 9116 recommends
+	// less than a year.
+	// Required unless Expires is set.
+	ExpiresIn time.Duration
+
+	// Encryption lists URIs of keys used for encrypted communication.
+	// Optional.
+	Encryption []string
+
+	// Acknowledgments lists URIs of pages recognizing security researchers.
+	// Optional.
+	Acknowledgments []string
+
+	// PreferredLanguages lists language tags (i.e. "en", "de") of languages reports are preferred in.
+	// Optional.
+	PreferredLanguages []string
+
+	// Canonical lists URIs where this security.txt is located.
+	// Optional.
+	Canonical []string
+
+	// Policy lists URIs of vulnerability disclosure policies.
+	// Optional.
+	Policy []string
+
+	// Hiring lists URIs of security related job positions.
+	// Optional.
+	Hiring []string
+}
+
+// SecurityTxt returns handler serving security.txt built from given config.
+func SecurityTxt(config SecurityTxtConfig) echo.HandlerFunc {
+	if len(config.Contact) == 0 {
+		panic("echo: security.txt requires contact")
+	}
+	if config.Expires.IsZero() && config.ExpiresIn <= 0 {
+		panic("echo: security.txt requires expires")
+	}
+	if config.ExpiresIn <= 0 {
+		body := []byte(config.String())
+		return func(c echo.Context) error {
+			return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, body)
+		}
+	}
+	return func(c echo.Context) error {
+		cfg := config
+		cfg.Expires = echo.Now(c).Add(config.ExpiresIn).Truncate(time.Hour)
+		return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, []byte(cfg.String()))
+	}
+}
+
+// String returns security.txt content for config.
+func (config SecurityTxtConfig) String() string {
+	b := new(strings.Builder)
+	field := func(name string, values []string) {
+		for _, v := range values {
+			b.WriteString(name + ": " + v + "\n")
+		}
+	}
+	field("Contact", config.Contact)
+	b.WriteString("Expires: " + config.Expires.UTC().Format(time.RFC3339) + "\n")
+	field("Encryption", config.Encryption)
+	field("Acknowledgments", config.Acknowledgments)
+	if len(config.PreferredLanguages) > 0 {
+		b.WriteString("Preferred-Languages: " + strings.Join(config.PreferredLanguages, ", ") + "\n")
+	}
+	field("Canonical", config.Canonical)
+	field("Policy", config.Policy)
+	field("Hiring", config.Hiring)
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package wellknown
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/echotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityTxt(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		givenConfig SecurityTxtConfig
+		expectBody  string
+	}{
+		{
+			name: "ok, required fields",
+			givenConfig: SecurityTxtConfig{
+				Contact: []string{"mailto:security@example.com"},
+				Expires: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expectBody: "Contact: mailto:security@example.com\nExpires: 2022-01-01T00:00:00Z\n",
+		},
+		{
+			name: "ok, all fields",
+			givenConfig: SecurityTxtConfig{
+				Contact:            []string{"mailto:security@example.com", "https://example.com/security"},
+				Expires:            time.Date(2022, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600)),
+				Encryption:         []string{"https://example.com/pgp-key.txt"},
+				Acknowledgments:    []string{"https://example.com/hall-of-fame"},
+				PreferredLanguages: []string{"en", "de"},
+				Canonical:          []string{"https://example.com/.well-known/security.txt"},
+				Policy:             []string{"https://example.com/disclosure"},
+				Hiring:             []string{"https://example.com/jobs"},
+			},
+			expectBody: "Contact: mailto:security@example.com\nContact: https://example.com/security\n" +
+				"Expires: 2021-12-31T23:00:00Z\n" +
+				"Encryption: https://example.com/pgp-key.txt\n" +
+				"Acknowledgments: https://example.com/hall-of-fame\n" +
+				"Preferred-Languages: en, de\n" +
+				"Canonical: https://example.com/.well-known/security.txt\n" +
+				"Policy: https://example.com/disclosure\n" +
+				"Hiring: https://example.com/jobs\n",
+		},
+		{
+			name: "ok, expires relative to request time",
+			givenConfig: SecurityTxtConfig{
+				Contact:   []string{"mailto:security@example.com"},
+				ExpiresIn: 24 * time.Hour,
+			},
+			expectBody: "Contact: mailto:security@example.com\nExpires: 2021-06-02T10:00:00Z\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			e.Clock = echotest.NewClock(time.Date(2021, 6, 1, 10, 30, 0, 0, time.UTC))
+			e.GET(PathSecurityTxt, SecurityTxt(tc.givenConfig))
+
+			req := httptest.NewRequest(http.MethodGet, PathSecurityTxt, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, echo.MIMETextPlainCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, tc.expectBody, rec.Body.String())
+		})
+	}
+}
+
+func TestSecurityTxt_panics(t *testing.T) {
+	assert.Panics(t, func() { SecurityTxt(SecurityTxtConfig{Expires: time.Now()}) })
+	assert.Panics(t, func() { SecurityTxt(SecurityTxtConfig{Contact: []string{"mailto:security@example.com"}}) })
+}
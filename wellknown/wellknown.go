@@ -0,0 +1,75 @@
+/*
+Package wellknown provides handlers for resources served under `/.well-known/` path (RFC 8615): security.txt,
+change-password redirect, Android Digital Asset Links and Apple App Site Association.
+
+Example:
+
+	e := echo.New()
+	wellknown.Register(e, wellknown.Config{
+		SecurityTxt: &wellknown.SecurityTxtConfig{
+			Contact:   []string{"mailto:security@example.com"},
+			ExpiresIn: 180 * 24 * time.Hour,
+		},
+		ChangePasswordURL: "/account/password",
+		AssetLinks: []wellknown.AssetLink{
+			wellknown.AndroidAppLink("com.example.app", "14:6D:E9:83:C5:73:06:50:D8:EE:B9:95:2F:34:FC:64:16:A0:83:42:E6:1D:BE:A8:8A:04:96:B2:3F:CF:44:E5"),
+		},
+	})
+*/
+package wellknown
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Paths of well-known resources.
+const (
+	PathSecurityTxt             = "/.well-known/security.txt"
+	PathChangePassword          = "/.well-known/change-password"
+	PathAssetLinks              = "/.well-known/assetlinks.json"
+	PathAppleAppSiteAssociation = "/.well-known/apple-app-site-association"
+)
+
+// Config defines resources registered by Register. Only configured resources are registered.
+type Config struct {
+	// SecurityTxt is content of security.txt (RFC 9116).
+	SecurityTxt *SecurityTxtConfig
+
+	// ChangePasswordURL is URL of page where user changes password. Password managers redirect users to it.
+	ChangePasswordURL string
+
+	// AssetLinks are Digital Asset Links statements served as assetlinks.json.
+	AssetLinks []AssetLink
+
+	// AppleAppSiteAssociation is content of apple-app-site-association.
+	AppleAppSiteAssociation *AppSiteAssociation
+}
+
+// Register registers GET routes of configured well-known resources.
+func Register(e *echo.Echo, config Config) {
+	if config.SecurityTxt != nil {
+		e.GET(PathSecurityTxt, SecurityTxt(*config.SecurityTxt))
+	}
+	if config.ChangePasswordURL != "" {
+		e.GET(PathChangePassword, ChangePassword(config.ChangePasswordURL))
+	}
+	if len(config.AssetLinks) > 0 {
+		e.GET(PathAssetLinks, AssetLinks(config.AssetLinks))
+	}
+	if config.AppleAppSiteAssociation != nil {
+		e.GET(PathAppleAppSiteAssociation, AppleAppSiteAssociation(*config.AppleAppSiteAssociation))
+	}
+}
+
+// ChangePassword returns handler redirecting to page where user changes password (W3C "A Well-Known URL for
+// Changing Passwords").
+func ChangePassword(url string) echo.HandlerFunc {
+	if url == "" {
+		panic("echo: change password requires url")
+	}
+	return func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, url)
+	}
+}
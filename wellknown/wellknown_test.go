@@ -0,0 +1,49 @@
+package wellknown
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	e := echo.New()
+	Register(e, Config{
+		SecurityTxt:       &SecurityTxtConfig{Contact: []string{"mailto:security@example.com"}, ExpiresIn: time.Hour},
+		ChangePasswordURL: "/account/password",
+		AssetLinks:        []AssetLink{AndroidAppLink("com.example.app", "AB:CD")},
+	})
+
+	var testCases = []struct {
+		path             string
+		expectCode       int
+		expectHeader     string
+		expectHeaderName string
+	}{
+		{path: PathSecurityTxt, expectCode: http.StatusOK, expectHeaderName: echo.HeaderContentType, expectHeader: echo.MIMETextPlainCharsetUTF8},
+		{path: PathChangePassword, expectCode: http.StatusFound, expectHeaderName: echo.HeaderLocation, expectHeader: "/account/password"},
+		{path: PathAssetLinks, expectCode: http.StatusOK, expectHeaderName: echo.HeaderContentType, expectHeader: echo.MIMEApplicationJSON},
+		{path: PathAppleAppSiteAssociation, expectCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectCode, rec.Code)
+			if tc.expectHeaderName != "" {
+				assert.Equal(t, tc.expectHeader, rec.Header().Get(tc.expectHeaderName))
+			}
+		})
+	}
+}
+
+func TestChangePassword_panics(t *testing.T) {
+	assert.Panics(t, func() { ChangePassword("") })
+}
@@ -0,0 +1,172 @@
+package echo
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultXMLSerializer implements XML encoding using encoding/xml.
+//
+// Note: encoding/xml never expands entities declared in document type definitions (DTD). Still, by default,
+// documents containing DTD (`<!DOCTYPE ...>` and other directives) are rejected by Deserialize as there is no
+// legitimate need for them in API payloads.
+type DefaultXMLSerializer struct {
+	// MaxDepth limits element nesting depth of deserialized documents. Zero value means no limit.
+	MaxDepth int
+
+	// MaxChildren limits number of child elements of a single element (i.e. items bound to a slice). Exceeding it
+	// is responded with 413. Zero value means no limit.
+	MaxChildren int
+
+	// MaxTokenSize limits size in bytes of a single character data, comment, name or attribute value. Exceeding it
+	// is responded with 413. Request body read for a single token (i.e. element with all its attributes) is
+	// limited to twice the size, so oversized tokens are rejected without buffering them whole. Zero value means
+	// no limit.
+	MaxTokenSize int
+
+	// AllowDTD allows documents containing DTD declarations to be deserialized.
+	AllowDTD bool
+
+	// Entity maps additional (non-standard) entity names to their replacement text. Only entities present in
+	// this map are replaced, declarations in DTD are never processed.
+	Entity map[string]string
+}
+
+// Errors
+var (
+	ErrXMLMaxDepthExceeded     = errors.New("xml: maximum nesting depth exceeded")
+	ErrXMLDTDNotAllowed        = errors.New("xml: document type definition is not allowed")
+	ErrXMLMaxChildrenExceeded  = errors.New("xml: maximum number of child elements exceeded")
+	ErrXMLMaxTokenSizeExceeded = errors.New("xml: maximum token size exceeded")
+)
+
+type xmlLimitTokenReader struct {
+	decoder      *xml.Decoder
+	body         *xmlTokenSizeReader
+	maxDepth     int
+	maxChildren  int
+	maxTokenSize int
+	allowDTD     bool
+	depth        int
+	children     []int // child elements of open elements
+}
+
+// xmlTokenSizeReader fails when more than limit bytes are read without the decoder producing a token.
+type xmlTokenSizeReader struct {
+	reader io.Reader
+	limit  int
+	read   int // bytes read since the last token
+}
+
+// xmlReadBufferSize is size of read buffer of xml.Decoder, bytes read ahead of a token.
+const xmlReadBufferSize = 4096
+
+// Serialize converts an interface into a xml and writes it to the response. Document is written to the response
+// while it is being encoded so large documents are not buffered in memory.
+// You can optionally use the indent parameter to produce pretty XMLs.
+func (d DefaultXMLSerializer) Serialize(c Context, i interface{}, indent string) error {
+	enc := xml.NewEncoder(c.Response())
+	if indent != "" {
+		enc.Indent("", indent)
+	}
+	return enc.Encode(i)
+}
+
+// Deserialize reads a XML from a request body and converts it into an interface.
+func (d DefaultXMLSerializer) Deserialize(c Context, i interface{}) error {
+	r := &xmlLimitTokenReader{
+		maxDepth:     d.MaxDepth,
+		maxChildren:  d.MaxChildren,
+		maxTokenSize: d.MaxTokenSize,
+		allowDTD:     d.AllowDTD,
+	}
+	var body io.Reader = c.Request().Body
+	if d.MaxTokenSize > 0 {
+		r.body = &xmlTokenSizeReader{reader: body, limit: 2*d.MaxTokenSize + xmlReadBufferSize}
+		body = r.body
+	}
+	r.decoder = xml.NewDecoder(body)
+	r.decoder.Entity = d.Entity
+	dec := xml.NewTokenDecoder(r)
+
+	err := dec.Decode(i)
+	if ute, ok := err.(*xml.UnsupportedTypeError); ok {
+		return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported type error: type=%v, error=%v", ute.Type, ute.Error())).SetInternal(err)
+	} else if se, ok := err.(*xml.SyntaxError); ok {
+		return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Syntax error: line=%v, error=%v", se.Line, se.Error())).SetInternal(err)
+	} else if err == ErrXMLMaxDepthExceeded || err == ErrXMLDTDNotAllowed {
+		return NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	} else if err == ErrXMLMaxChildrenExceeded || err == ErrXMLMaxTokenSizeExceeded {
+		return NewHTTPError(http.StatusRequestEntityTooLarge, err.Error()).SetInternal(err)
+	}
+	return err
+}
+
+func (r *xmlLimitTokenReader) Token() (xml.Token, error) {
+	t, err := r.decoder.RawToken()
+	if err != nil {
+		return t, err
+	}
+	if r.body != nil {
+		r.body.read = 0
+	}
+	switch t := t.(type) {
+	case xml.StartElement:
+		r.depth++
+		if r.maxDepth > 0 && r.depth > r.maxDepth {
+			return nil, ErrXMLMaxDepthExceeded
+		}
+		if n := len(r.children); n > 0 {
+			r.children[n-1]++
+			if r.maxChildren > 0 && r.children[n-1] > r.maxChildren {
+				return nil, ErrXMLMaxChildrenExceeded
+			}
+		}
+		r.children = append(r.children, 0)
+		if r.tooLarge(len(t.Name.Space) + len(t.Name.Local)) {
+			return nil, ErrXMLMaxTokenSizeExceeded
+		}
+		for _, a := range t.Attr {
+			if r.tooLarge(len(a.Name.Space)+len(a.Name.Local)) || r.tooLarge(len(a.Value)) {
+				return nil, ErrXMLMaxTokenSizeExceeded
+			}
+		}
+	case xml.EndElement:
+		r.depth--
+		if n := len(r.children); n > 0 {
+			r.children = r.children[:n-1]
+		}
+	case xml.CharData:
+		if r.tooLarge(len(t)) {
+			return nil, ErrXMLMaxTokenSizeExceeded
+		}
+	case xml.Comment:
+		if r.tooLarge(len(t)) {
+			return nil, ErrXMLMaxTokenSizeExceeded
+		}
+	case xml.Directive:
+		if !r.allowDTD {
+			return nil, ErrXMLDTDNotAllowed
+		}
+	}
+	return t, nil
+}
+
+func (r *xmlLimitTokenReader) tooLarge(size int) bool {
+	return r.maxTokenSize > 0 && size > r.maxTokenSize
+}
+
+func (r *xmlTokenSizeReader) Read(p []byte) (int, error) {
+	if r.read >= r.limit {
+		return 0, ErrXMLMaxTokenSizeExceeded
+	}
+	if len(p) > r.limit-r.read {
+		p = p[:r.limit-r.read]
+	}
+	n, err := r.reader.Read(p)
+	r.read += n
+	return n, err
+}
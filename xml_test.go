@@ -0,0 +1,181 @@
+package echo
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultXMLSerializer_Serialize(t *testing.T) {
+	e := New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	enc := new(DefaultXMLSerializer)
+	err := enc.Serialize(c, user{1, "Jon Snow"}, "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, userXML, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	c = e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	err = enc.Serialize(c, user{1, "Jon Snow"}, "  ")
+	if assert.NoError(t, err) {
+		assert.Equal(t, userXMLPretty, rec.Body.String())
+	}
+}
+
+func TestDefaultXMLSerializer_Deserialize(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		givenConfig DefaultXMLSerializer
+		whenBody    string
+		expect      user
+		expectError string
+	}{
+		{
+			name:     "ok",
+			whenBody: userXML,
+			expect:   user{1, "Jon Snow"},
+		},
+		{
+			name:        "ok, within max depth",
+			givenConfig: DefaultXMLSerializer{MaxDepth: 2},
+			whenBody:    userXML,
+			expect:      user{1, "Jon Snow"},
+		},
+		{
+			name:        "nok, max depth exceeded",
+			givenConfig: DefaultXMLSerializer{MaxDepth: 2},
+			whenBody:    `<user><id>1</id><name><a><b/></a></name></user>`,
+			expectError: "code=400, message=xml: maximum nesting depth exceeded, internal=xml: maximum nesting depth exceeded",
+		},
+		{
+			name:        "nok, DTD not allowed by default",
+			whenBody:    `<!DOCTYPE user [<!ENTITY name "Jon Snow">]><user><id>1</id><name>&name;</name></user>`,
+			expectError: "code=400, message=xml: document type definition is not allowed, internal=xml: document type definition is not allowed",
+		},
+		{
+			name:        "nok, DTD entities are not expanded",
+			givenConfig: DefaultXMLSerializer{AllowDTD: true},
+			whenBody:    `<!DOCTYPE user [<!ENTITY name "Jon Snow">]><user><id>1</id><name>&name;</name></user>`,
+			expectError: "code=400, message=Syntax error: line=1, error=XML syntax error on line 1: invalid character entity &name;, internal=XML syntax error on line 1: invalid character entity &name;",
+		},
+		{
+			name:        "ok, within max children and token size",
+			givenConfig: DefaultXMLSerializer{MaxChildren: 2, MaxTokenSize: 8},
+			whenBody:    userXML,
+			expect:      user{1, "Jon Snow"},
+		},
+		{
+			name:        "nok, max children exceeded",
+			givenConfig: DefaultXMLSerializer{MaxChildren: 2},
+			whenBody:    `<user><id>1</id><name>Jon Snow</name><name>Jon</name></user>`,
+			expectError: "code=413, message=xml: maximum number of child elements exceeded, internal=xml: maximum number of child elements exceeded",
+		},
+		{
+			name:        "nok, character data too large",
+			givenConfig: DefaultXMLSerializer{MaxTokenSize: 7},
+			whenBody:    userXML,
+			expectError: "code=413, message=xml: maximum token size exceeded, internal=xml: maximum token size exceeded",
+		},
+		{
+			name:        "nok, attribute value too large",
+			givenConfig: DefaultXMLSerializer{MaxTokenSize: 8},
+			whenBody:    `<user note="123456789"><id>1</id></user>`,
+			expectError: "code=413, message=xml: maximum token size exceeded, internal=xml: maximum token size exceeded",
+		},
+		{
+			name:        "ok, entity from config",
+			givenConfig: DefaultXMLSerializer{Entity: map[string]string{"name": "Jon Snow"}},
+			whenBody:    `<user><id>1</id><name>&name;</name></user>`,
+			expect:      user{1, "Jon Snow"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.whenBody))
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			u := user{}
+			err := tc.givenConfig.Deserialize(c, &u)
+			if tc.expectError != "" {
+				assert.EqualError(t, err, tc.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, u)
+		})
+	}
+}
+
+type testXMLSerializer struct {
+	DefaultXMLSerializer
+}
+
+func (s testXMLSerializer) Serialize(c Context, i interface{}, indent string) error {
+	_, err := c.Response().Write([]byte("<custom/>"))
+	return err
+}
+
+func TestDefaultXMLSerializer_Deserialize_tokenSizeBoundsRead(t *testing.T) {
+	e := New()
+	body := &countingReader{reader: io.MultiReader(
+		strings.NewReader("<user><name>"),
+		strings.NewReader(strings.Repeat("a", 1<<20)),
+		strings.NewReader("</name></user>"),
+	)}
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	err := DefaultXMLSerializer{MaxTokenSize: 16}.Deserialize(c, &user{})
+
+	assert.EqualError(t, err, "code=413, message=xml: maximum token size exceeded, internal=xml: maximum token size exceeded")
+	assert.Less(t, body.read, 2*xmlReadBufferSize)
+}
+
+func TestEcho_customXMLSerializer(t *testing.T) {
+	e := New()
+	e.XMLSerializer = testXMLSerializer{}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	err := c.XML(http.StatusOK, user{1, "Jon Snow"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, xml.Header+"<custom/>", rec.Body.String())
+	}
+}
+
+func TestDefaultBinder_BindBody_xmlMaxDepth(t *testing.T) {
+	e := New()
+	e.XMLSerializer = &DefaultXMLSerializer{MaxDepth: 1}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(userXML))
+	req.Header.Set(HeaderContentType, MIMEApplicationXML)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	u := user{}
+	err := c.Bind(&u)
+	if assert.Error(t, err) {
+		he, ok := err.(*HTTPError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	}
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += n
+	return n, err
+}
@@ -0,0 +1,102 @@
+package echo
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// pooledJSONEncoder is JSON encoder writing to buffer taken from `Echo#BufferPool`. Encoders are pooled as
+// json.Encoder can not be pointed to another writer once created.
+type pooledJSONEncoder struct {
+	buf *bytes.Buffer
+	enc *json.Encoder
+}
+
+var jsonEncoderPool = sync.Pool{
+	New: func() interface{} {
+		e := new(pooledJSONEncoder)
+		e.enc = json.NewEncoder(e)
+		return e
+	},
+}
+
+func (e *pooledJSONEncoder) Write(b []byte) (int, error) {
+	return e.buf.Write(b)
+}
+
+// sharedContentTypes holds immutable header values for content types set by Context response methods. In zero
+// allocation mode these slices are assigned directly to response headers.
+var sharedContentTypes = map[string][]string{}
+
+func init() {
+	for _, v := range []string{
+		MIMEApplicationJSONCharsetUTF8,
+		MIMEApplicationJavaScriptCharsetUTF8,
+		MIMEApplicationXMLCharsetUTF8,
+		MIMETextHTMLCharsetUTF8,
+		MIMETextPlainCharsetUTF8,
+		MIMEOctetStream,
+	} {
+		sharedContentTypes[v] = []string{v}
+	}
+}
+
+// EnableZeroAllocMode switches Echo to request handling that avoids per request allocations on the hot path:
+//
+// - global and pre middleware chains are composed once (when middleware is added) instead of on every request
+// - route level middleware chains of routes added after this call are composed once when route is added
+// - context store map is cleared and reused between requests instead of allocated
+// - DefaultJSONSerializer encodes responses with pooled encoders into buffers from `Echo#BufferPool` and writes
+// them with single write
+// - well known Content-Type header values are set without allocating new header value slice. These values are
+// shared between requests and must not be modified in place (use `Header().Set()` instead of indexing).
+//
+// Middlewares are called once with `next` handler that dispatches to the handler of the matched route, so
+// middleware must not keep per request state in the closure created by MiddlewareFunc.
+func (e *Echo) EnableZeroAllocMode() {
+	e.zeroAlloc = true
+	e.buildChain()
+}
+
+// buildChain composes middleware chain used by ServeHTTP in zero allocation mode.
+func (e *Echo) buildChain() {
+	if !e.zeroAlloc {
+		return
+	}
+	h := applyMiddleware(func(c Context) error {
+		return c.Handler()(c)
+	}, e.middleware...)
+	e.chain = applyMiddleware(func(c Context) error {
+		r := c.Request()
+		e.find(r, c)
+		return h(c)
+	}, e.premiddleware...)
+}
+
+func (c *context) setContentType(header map[string][]string, value string) {
+	if c.echo.zeroAlloc {
+		if v, ok := sharedContentTypes[value]; ok {
+			header[HeaderContentType] = v
+			return
+		}
+	}
+	header[HeaderContentType] = []string{value}
+}
+
+func serializePooledJSON(c Context, i interface{}, indent string) error {
+	pool := c.Echo().bufferPool()
+	e := jsonEncoderPool.Get().(*pooledJSONEncoder)
+	e.buf = pool.Get()
+	defer func() {
+		pool.Put(e.buf)
+		e.buf = nil
+		jsonEncoderPool.Put(e)
+	}()
+	e.enc.SetIndent("", indent)
+	if err := e.enc.Encode(i); err != nil {
+		return err
+	}
+	_, err := c.Response().Write(e.buf.Bytes())
+	return err
+}
@@ -0,0 +1,175 @@
+package echo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// discardResponseWriter reuses same header map between requests so allocations of the writer itself do not
+// show up in allocation counts.
+type discardResponseWriter struct {
+	header http.Header
+	code   int
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(code int) {
+	w.code = code
+}
+
+type zeroAllocUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newZeroAllocEcho() *Echo {
+	e := New()
+	e.EnableZeroAllocMode()
+	e.Pre(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			return next(c)
+		}
+	})
+	e.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			c.Set("user", "jon")
+			return next(c)
+		}
+	})
+	user := &zeroAllocUser{ID: 1, Name: "Jon Snow"}
+	e.GET("/users/:id", func(c Context) error {
+		return c.JSON(http.StatusOK, user)
+	}, func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			return next(c)
+		}
+	})
+	e.GET("/ping", func(c Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	return e
+}
+
+func TestEcho_EnableZeroAllocMode(t *testing.T) {
+	e := newZeroAllocEcho()
+	calls := []string{}
+	e.Pre(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			calls = append(calls, "pre")
+			return next(c)
+		}
+	})
+	e.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			calls = append(calls, "use:"+c.Path())
+			return next(c)
+		}
+	})
+	g := e.Group("/admin", func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			calls = append(calls, "group")
+			return next(c)
+		}
+	})
+	g.GET("/stats", func(c Context) error {
+		calls = append(calls, "handler:"+c.Get("user").(string))
+		return c.String(http.StatusOK, "stats")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1?pretty", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "{\n  \"id\": 1,\n  \"name\": \"Jon Snow\"\n}\n", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/stats", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, "stats", rec.Body.String())
+	assert.Equal(t, []string{"pre", "use:/users/:id", "pre", "use:/admin/stats", "group", "handler:jon"}, calls)
+
+	req = httptest.NewRequest(http.MethodGet, "/missing", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "{\"message\":\"Not Found\"}\n", rec.Body.String())
+}
+
+func TestEcho_EnableZeroAllocMode_storeReuse(t *testing.T) {
+	e := New()
+	e.EnableZeroAllocMode()
+	c := e.NewContext(nil, nil).(*context)
+	c.Set("a", 1)
+	c.Reset(nil, nil)
+	assert.Nil(t, c.Get("a"))
+	assert.NotNil(t, c.store)
+}
+
+func TestEcho_EnableZeroAllocMode_jsonError(t *testing.T) {
+	e := New()
+	e.EnableZeroAllocMode()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	err := c.JSON(http.StatusOK, map[string]interface{}{"ch": make(chan int)})
+	assert.Error(t, err)
+	assert.False(t, c.Response().Committed)
+}
+
+func TestEcho_EnableZeroAllocMode_allocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool does not keep items with race detector")
+	}
+	e := newZeroAllocEcho()
+	var testCases = []struct {
+		name string
+		path string
+	}{
+		{name: "json with path param", path: "/users/1"},
+		{name: "no content", path: "/ping"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			w := &discardResponseWriter{header: http.Header{}}
+			e.ServeHTTP(w, req) // warm up pools
+
+			allocs := testing.AllocsPerRun(100, func() {
+				e.ServeHTTP(w, req)
+			})
+			assert.Equal(t, float64(0), allocs)
+		})
+	}
+}
+
+func benchmarkServeHTTP(b *testing.B, e *Echo, path string) {
+	req := httptest.NewRequest(http.MethodGet, path, strings.NewReader(""))
+	w := &discardResponseWriter{header: http.Header{}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkServeHTTP_JSON(b *testing.B) {
+	e := newZeroAllocEcho()
+	e.zeroAlloc = false
+	e.chain = nil
+	benchmarkServeHTTP(b, e, "/users/1")
+}
+
+func BenchmarkServeHTTP_JSONZeroAlloc(b *testing.B) {
+	benchmarkServeHTTP(b, newZeroAllocEcho(), "/users/1")
+}